@@ -17,6 +17,7 @@ func (a Actor) Exports() []interface{} {
 	return []interface{}{
 		1: a.Constructor,
 		2: a.PubkeyAddress,
+		3: a.UniversalReceiverHook,
 	}
 }
 
@@ -34,16 +35,27 @@ type State struct {
 	Address addr.Address
 }
 
+// Loads the account state for mutation by f, committing it when f returns.
+func WithState(rt runtime.Runtime, f func(st *State)) {
+	var st State
+	rt.StateTransaction(&st, func() {
+		f(&st)
+	})
+}
+
+// Loads a read-only copy of the account state.
+func ReadState(rt runtime.Runtime) *State {
+	var st State
+	rt.StateReadonly(&st)
+	return &st
+}
+
 func (a Actor) Constructor(rt runtime.Runtime, address *addr.Address) *abi.EmptyValue {
 	// Account actors are created implicitly by sending a message to a pubkey-style address.
 	// This constructor is not invoked by the InitActor, but by the system.
 	rt.ValidateImmediateCallerIs(builtin.SystemActorAddr)
-	switch address.Protocol() {
-	case addr.SECP256K1:
-	case addr.BLS:
-		break // ok
-	default:
-		rt.Abortf(exitcode.ErrIllegalArgument, "address must use BLS or SECP protocol, got %v", address.Protocol())
+	if _, ok := builtin.SignatureTypeForProtocol(address.Protocol()); !ok {
+		rt.Abortf(exitcode.ErrIllegalArgument, "address must use a signature protocol, got %v", address.Protocol())
 	}
 	st := State{Address: *address}
 	rt.StateCreate(&st)
@@ -53,7 +65,13 @@ func (a Actor) Constructor(rt runtime.Runtime, address *addr.Address) *abi.Empty
 // Fetches the pubkey-type address from this actor.
 func (a Actor) PubkeyAddress(rt runtime.Runtime, _ *abi.EmptyValue) *addr.Address {
 	rt.ValidateImmediateCallerAcceptAny()
-	var st State
-	rt.StateReadonly(&st)
+	st := ReadState(rt)
 	return &st.Address
 }
+
+// Accepts a notification of a typed transfer from any caller, along with any value sent with it.
+// An account has no use for the payload, so accepts every transfer type, like a plain send.
+func (a Actor) UniversalReceiverHook(rt runtime.Runtime, _ *builtin.UniversalReceiverParams) *abi.EmptyValue {
+	rt.ValidateImmediateCallerAcceptAny()
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/exitcode"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -80,6 +81,29 @@ func TestAccountactor(t *testing.T) {
 	}
 }
 
+func TestUniversalReceiverHook(t *testing.T) {
+	actor := account.Actor{}
+	receiver := tutil.NewIDAddr(t, 100)
+	rt := mock.NewBuilder(receiver).WithCaller(builtin.SystemActorAddr, builtin.SystemActorCodeID).Build(t)
+
+	addr := tutil.NewBLSAddr(t, 1)
+	rt.ExpectValidateCallerAddr(builtin.SystemActorAddr)
+	rt.Call(actor.Constructor, &addr)
+	rt.Verify()
+
+	// Any caller may notify the account of any transfer type, with value attached.
+	rt.SetCaller(tutil.NewIDAddr(t, 101), builtin.VerifiedRegistryActorCodeID)
+	rt.SetReceived(abi.NewTokenAmount(100))
+	rt.SetBalance(abi.NewTokenAmount(100))
+	rt.ExpectValidateCallerAny()
+	rt.Call(actor.UniversalReceiverHook, &builtin.UniversalReceiverParams{
+		Type:    42,
+		Payload: []byte{0x1, 0x2},
+	})
+	rt.Verify()
+	checkState(t, rt)
+}
+
 func checkState(t *testing.T, rt *mock.Runtime) {
 	testAddress, err := address.NewIDAddress(1000)
 	require.NoError(t, err)
@@ -20,8 +20,8 @@ func CheckStateInvariants(st *State, idAddr address.Address) (*StateSummary, *bu
 	if id, err := address.IDFromAddress(idAddr); err != nil {
 		acc.Addf("error extracting actor ID from address: %v", err)
 	} else if id >= builtin.FirstNonSingletonActorId {
-		acc.Require(st.Address.Protocol() == address.BLS || st.Address.Protocol() == address.SECP256K1,
-			"actor address %v must be BLS or SECP256K1 protocol", st.Address)
+		_, ok := builtin.SignatureTypeForProtocol(st.Address.Protocol())
+		acc.Require(ok, "actor address %v must use a signature protocol", st.Address)
 	}
 
 	return accountSummary, acc
@@ -0,0 +1,85 @@
+package builtin
+
+import (
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/exitcode"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/runtime"
+)
+
+// The category of a burn of funds, recorded so that burns can be attributed to their source.
+type BurnReason int64
+
+const (
+	BurnReasonUnspecified BurnReason = iota
+	// Repayment of a miner's outstanding fee debt, accrued from penalties that could not be paid when they were incurred.
+	BurnReasonFeeDebt
+	// Penalties paid at the end of a miner's proving deadline: fees for continued faults, deposits of
+	// pre-commitments that expired without being proven, and any other outstanding fee debt repaid then.
+	BurnReasonProvingDeadline
+	// Penalty for a Window PoSt successfully disputed as invalid.
+	BurnReasonInvalidPoSt
+	// Fees for sectors terminated before their expiration.
+	BurnReasonTerminationFee
+	// Penalty for a consensus fault, less the reporter's reward.
+	BurnReasonConsensusFault
+	// Penalty deducted from a block reward, e.g. for including invalid messages.
+	BurnReasonBlockRewardPenalty
+	// Provider collateral slashed for a deal that was terminated or never activated.
+	BurnReasonDealSlash
+	// A block reward that could not be delivered to the miner that won it.
+	BurnReasonUndeliveredReward
+	// Network fee for verifying an aggregated proof of many sectors' seals.
+	BurnReasonAggregateFee
+)
+
+// Whether the reason is one of the defined burn reasons.
+func (r BurnReason) IsValid() bool {
+	return r >= BurnReasonUnspecified && r <= BurnReasonAggregateFee
+}
+
+func (r BurnReason) String() string {
+	switch r {
+	case BurnReasonFeeDebt:
+		return "fee debt"
+	case BurnReasonProvingDeadline:
+		return "proving deadline penalty"
+	case BurnReasonInvalidPoSt:
+		return "invalid PoSt penalty"
+	case BurnReasonTerminationFee:
+		return "termination fee"
+	case BurnReasonConsensusFault:
+		return "consensus fault penalty"
+	case BurnReasonBlockRewardPenalty:
+		return "block reward penalty"
+	case BurnReasonDealSlash:
+		return "deal slash"
+	case BurnReasonUndeliveredReward:
+		return "undelivered reward"
+	case BurnReasonAggregateFee:
+		return "aggregate fee"
+	default:
+		return "unspecified"
+	}
+}
+
+// Parameters for the reward actor's BurnFunds method.
+// The funds to burn are the value sent with the message.
+type BurnFundsParams struct {
+	Reason BurnReason
+}
+
+// Burns funds from the calling actor's balance by sending them to the reward actor, which records the
+// cumulative amount burnt for the reason before forwarding the funds to the burnt funds actor.
+// The funds pass through the reward actor because only it can update the burn record in its state;
+// sending directly to the burnt funds actor would leave the reason unrecorded on chain.
+// A non-positive amount is not sent.
+// Returns the exit code of the send, leaving the caller to decide whether a failure should abort.
+// The reward actor records its own burns directly and must not call this.
+func BurnWithReason(rt runtime.Runtime, amount abi.TokenAmount, reason BurnReason) exitcode.ExitCode {
+	if amount.LessThanEqual(big.Zero()) {
+		return exitcode.Ok
+	}
+	return rt.Send(RewardActorAddr, MethodsReward.BurnFunds, &BurnFundsParams{Reason: reason}, amount, &Discard{})
+}
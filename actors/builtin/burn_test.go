@@ -0,0 +1,85 @@
+package builtin_test
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v3/actors/runtime"
+	"github.com/filecoin-project/specs-actors/v3/support/mock"
+	tutil "github.com/filecoin-project/specs-actors/v3/support/testing"
+)
+
+func TestBurnWithReason(t *testing.T) {
+	receiver := tutil.NewIDAddr(t, 1000)
+	builder := mock.NewBuilder(receiver).WithBalance(abi.NewTokenAmount(1000), big.Zero())
+
+	// Invokes BurnWithReason from within an actor method, returning the exit code of the burn.
+	burn := func(rt *mock.Runtime, amount abi.TokenAmount, reason builtin.BurnReason) exitcode.ExitCode {
+		var code exitcode.ExitCode
+		rt.Call(func(rt runtime.Runtime, amount *abi.TokenAmount) *abi.EmptyValue {
+			code = builtin.BurnWithReason(rt, *amount, reason)
+			return nil
+		}, &amount)
+		rt.Verify()
+		return code
+	}
+
+	t.Run("sends funds to the reward actor with the reason", func(t *testing.T) {
+		rt := builder.Build(t)
+		amount := abi.NewTokenAmount(100)
+		rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.BurnFunds,
+			&builtin.BurnFundsParams{Reason: builtin.BurnReasonTerminationFee}, amount, nil, exitcode.Ok)
+
+		assert.Equal(t, exitcode.Ok, burn(rt, amount, builtin.BurnReasonTerminationFee))
+		assert.Equal(t, abi.NewTokenAmount(900), rt.Balance())
+	})
+
+	t.Run("does not send a non-positive amount", func(t *testing.T) {
+		rt := builder.Build(t)
+		assert.Equal(t, exitcode.Ok, burn(rt, big.Zero(), builtin.BurnReasonFeeDebt))
+		assert.Equal(t, exitcode.Ok, burn(rt, abi.NewTokenAmount(-1), builtin.BurnReasonFeeDebt))
+		assert.Equal(t, abi.NewTokenAmount(1000), rt.Balance())
+	})
+
+	t.Run("returns the exit code of a failed send", func(t *testing.T) {
+		rt := builder.Build(t)
+		amount := abi.NewTokenAmount(100)
+		rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.BurnFunds,
+			&builtin.BurnFundsParams{Reason: builtin.BurnReasonDealSlash}, amount, nil, exitcode.ErrForbidden)
+
+		assert.Equal(t, exitcode.ErrForbidden, burn(rt, amount, builtin.BurnReasonDealSlash))
+	})
+}
+
+func TestBurnReason(t *testing.T) {
+	t.Run("string", func(t *testing.T) {
+		for reason, expected := range map[builtin.BurnReason]string{ // nolint:nomaprange
+			builtin.BurnReasonUnspecified:        "unspecified",
+			builtin.BurnReasonFeeDebt:            "fee debt",
+			builtin.BurnReasonProvingDeadline:    "proving deadline penalty",
+			builtin.BurnReasonInvalidPoSt:        "invalid PoSt penalty",
+			builtin.BurnReasonTerminationFee:     "termination fee",
+			builtin.BurnReasonConsensusFault:     "consensus fault penalty",
+			builtin.BurnReasonBlockRewardPenalty: "block reward penalty",
+			builtin.BurnReasonDealSlash:          "deal slash",
+			builtin.BurnReasonUndeliveredReward:  "undelivered reward",
+			builtin.BurnReasonAggregateFee:       "aggregate fee",
+			builtin.BurnReason(-1):               "unspecified",
+			builtin.BurnReason(100):              "unspecified",
+		} {
+			assert.Equal(t, expected, reason.String())
+		}
+	})
+
+	t.Run("validity", func(t *testing.T) {
+		assert.True(t, builtin.BurnReasonUnspecified.IsValid())
+		assert.True(t, builtin.BurnReasonAggregateFee.IsValid())
+		assert.False(t, builtin.BurnReason(-1).IsValid())
+		assert.False(t, (builtin.BurnReasonAggregateFee + 1).IsValid())
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/filecoin-project/go-state-types/abi"
 
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v3/actors/runtime"
 )
 
 type State struct {
@@ -20,6 +21,21 @@ func ConstructState(entries []Entry) *State {
 	return &State{Entries: entries}
 }
 
+// Loads the cron state for mutation by f, committing it when f returns.
+func WithState(rt runtime.Runtime, f func(st *State)) {
+	var st State
+	rt.StateTransaction(&st, func() {
+		f(&st)
+	})
+}
+
+// Loads a read-only copy of the cron state.
+func ReadState(rt runtime.Runtime) *State {
+	var st State
+	rt.StateReadonly(&st)
+	return &st
+}
+
 // The default entries to install in the cron actor's state at genesis.
 func BuiltInEntries() []Entry {
 	return []Entry{
@@ -10,19 +10,18 @@ import (
 
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin/cron"
+	"github.com/filecoin-project/specs-actors/v3/support/harness"
 	"github.com/filecoin-project/specs-actors/v3/support/mock"
 	tutil "github.com/filecoin-project/specs-actors/v3/support/testing"
 )
 
 func TestExports(t *testing.T) {
-	mock.CheckActorExports(t, cron.Actor{})
+	newCronHarness(t).CheckExports(t, builtin.MethodsCron)
 }
 
 func TestConstructor(t *testing.T) {
-	actor := cronHarness{cron.Actor{}, t}
-
-	receiver := tutil.NewIDAddr(t, 100)
-	builder := mock.NewBuilder(receiver).WithCaller(builtin.SystemActorAddr, builtin.SystemActorCodeID)
+	actor := newCronHarness(t)
+	builder := actor.Builder()
 
 	t.Run("construct with empty entries", func(t *testing.T) {
 		rt := builder.Build(t)
@@ -61,10 +60,8 @@ func TestConstructor(t *testing.T) {
 }
 
 func TestEpochTick(t *testing.T) {
-	actor := cronHarness{cron.Actor{}, t}
-
-	receiver := tutil.NewIDAddr(t, 100)
-	builder := mock.NewBuilder(receiver).WithCaller(builtin.SystemActorAddr, builtin.SystemActorCodeID)
+	actor := newCronHarness(t)
+	builder := actor.Builder()
 
 	t.Run("epoch tick with empty entries", func(t *testing.T) {
 		rt := builder.Build(t)
@@ -100,29 +97,48 @@ func TestEpochTick(t *testing.T) {
 	})
 }
 
+func TestCallerValidation(t *testing.T) {
+	actor := newCronHarness(t)
+	actor.CheckCallerValidation(t, func(t *testing.T) *mock.Runtime {
+		rt := actor.Builder().Build(t)
+		actor.constructAndVerify(rt)
+		return rt
+	}, harness.CallerValidationCase{
+		Name:       "epoch tick by non-system caller",
+		Method:     builtin.MethodsCron.EpochTick,
+		Caller:     tutil.NewIDAddr(t, 1000),
+		CallerCode: builtin.AccountActorCodeID,
+		Expect: func(rt *mock.Runtime) {
+			rt.ExpectValidateCallerAddr(builtin.SystemActorAddr)
+		},
+	})
+}
+
 type cronHarness struct {
 	cron.Actor
-	t testing.TB
+	*harness.Harness
+}
+
+func newCronHarness(t testing.TB) *cronHarness {
+	h := &cronHarness{cron.Actor{}, harness.New(t, cron.Actor{}, tutil.NewIDAddr(t, 100))}
+	h.CheckInvariants = func(rt *mock.Runtime) *builtin.MessageAccumulator {
+		_, msgs := cron.CheckStateInvariants(h.LoadState(rt).(*cron.State), rt.AdtStore())
+		return msgs
+	}
+	return h
 }
 
 func (h *cronHarness) constructAndVerify(rt *mock.Runtime, entries ...cron.EntryParam) {
-	params := cron.ConstructorParams{Entries: entries}
-	rt.ExpectValidateCallerAddr(builtin.SystemActorAddr)
-	ret := rt.Call(h.Constructor, &params)
-	assert.Nil(h.t, ret)
-	rt.Verify()
+	h.ConstructAndVerify(rt, &cron.ConstructorParams{Entries: entries})
 }
 
 func (h *cronHarness) epochTickAndVerify(rt *mock.Runtime) {
 	rt.ExpectValidateCallerAddr(builtin.SystemActorAddr)
 	ret := rt.Call(h.EpochTick, nil)
-	assert.Nil(h.t, ret)
+	assert.Nil(h.T, ret)
 	rt.Verify()
 }
 
 func (h *cronHarness) checkState(rt *mock.Runtime) {
-	var st cron.State
-	rt.GetState(&st)
-	_, msgs := cron.CheckStateInvariants(&st, rt.AdtStore())
-	assert.True(h.t, msgs.IsEmpty())
+	h.CheckState(rt)
 }
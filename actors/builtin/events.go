@@ -0,0 +1,78 @@
+package builtin
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/filecoin-project/go-state-types/cbor"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	cbg "github.com/whyrusleeping/cbor-gen"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/runtime"
+)
+
+// Key of the entry identifying an event's type, which is the first entry of every event emitted by a builtin actor.
+const EventTypeKey = "$type"
+
+// Assembles the entries of an actor event.
+// Every event begins with an indexed entry naming its type, followed by entries added in order.
+type EventBuilder struct {
+	entries []runtime.EventEntry
+	err     error
+}
+
+func NewEventBuilder(eventType string) *EventBuilder {
+	eb := &EventBuilder{}
+	typ := eventTypeValue(eventType)
+	return eb.add(runtime.EventFlagIndexedAll, EventTypeKey, &typ)
+}
+
+// Appends an entry whose key and value are indexed.
+func (eb *EventBuilder) FieldIndexed(key string, value cbor.Marshaler) *EventBuilder {
+	return eb.add(runtime.EventFlagIndexedAll, key, value)
+}
+
+// Appends an entry that is not indexed.
+func (eb *EventBuilder) Field(key string, value cbor.Marshaler) *EventBuilder {
+	return eb.add(0, key, value)
+}
+
+// Returns the entries of the event, or the first error encountered serializing a value.
+func (eb *EventBuilder) Entries() ([]runtime.EventEntry, error) {
+	if eb.err != nil {
+		return nil, eb.err
+	}
+	return eb.entries, nil
+}
+
+// Emits the event, aborting if any value failed to serialize.
+func (eb *EventBuilder) Emit(rt runtime.Runtime) {
+	entries, err := eb.Entries()
+	RequireNoErr(rt, err, exitcode.ErrSerialization, "failed to serialize event")
+	rt.EmitEvent(entries)
+}
+
+func (eb *EventBuilder) add(flags runtime.EventFlags, key string, value cbor.Marshaler) *EventBuilder {
+	if eb.err != nil {
+		return eb
+	}
+	var buf bytes.Buffer
+	if err := value.MarshalCBOR(&buf); err != nil {
+		eb.err = xerrors.Errorf("failed to serialize event entry %s: %w", key, err)
+		return eb
+	}
+	eb.entries = append(eb.entries, runtime.EventEntry{Flags: flags, Key: key, Value: buf.Bytes()})
+	return eb
+}
+
+// A string serialized as a CBOR text string, for the value of an event's type entry.
+type eventTypeValue string
+
+func (v *eventTypeValue) MarshalCBOR(w io.Writer) error {
+	if err := cbg.WriteMajorTypeHeader(w, cbg.MajTextString, uint64(len(*v))); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, string(*v))
+	return err
+}
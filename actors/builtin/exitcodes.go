@@ -0,0 +1,89 @@
+package builtin
+
+import (
+	"fmt"
+
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/ipfs/go-cid"
+)
+
+// Each builtin actor allocates its actor-specific exit codes from a distinct range, so that a code identifies
+// both the failure and the actor that raised it. The ranges are fixed: codes are observable on chain, and
+// tooling may interpret them, so a code must never move once allocated.
+const ActorExitCodeRangeSize = 16
+
+// The first code of each builtin actor's range.
+const (
+	FirstMarketExitCode           = exitcode.FirstActorSpecificExitCode + iota*ActorExitCodeRangeSize // 32-47
+	FirstMinerExitCode                                                                                // 48-63
+	FirstPowerExitCode                                                                                // 64-79
+	FirstPaychExitCode                                                                                // 80-95
+	FirstVerifiedRegistryExitCode                                                                     // 96-111
+	FirstMultisigExitCode                                                                             // 112-127
+	FirstRewardExitCode                                                                               // 128-143
+	FirstInitExitCode                                                                                 // 144-159
+	FirstCronExitCode                                                                                 // 160-175
+	FirstAccountExitCode                                                                              // 176-191
+	FirstSystemExitCode                                                                               // 192-207
+)
+
+// A range of actor-specific exit codes, inclusive of both bounds.
+type ExitCodeRange struct {
+	First exitcode.ExitCode
+	Last  exitcode.ExitCode
+}
+
+// Returns the range of ActorExitCodeRangeSize codes beginning at first.
+func NewExitCodeRange(first exitcode.ExitCode) ExitCodeRange {
+	return ExitCodeRange{First: first, Last: first + ActorExitCodeRangeSize - 1}
+}
+
+func (r ExitCodeRange) Contains(code exitcode.ExitCode) bool {
+	return code >= r.First && code <= r.Last
+}
+
+// Returns the nth code of the range, panicking if the range has no such code.
+func (r ExitCodeRange) Code(n int64) exitcode.ExitCode {
+	code := r.First + exitcode.ExitCode(n)
+	if n < 0 || !r.Contains(code) {
+		panic(fmt.Sprintf("exit code %d out of range [%d, %d]", n, r.First, r.Last))
+	}
+	return code
+}
+
+func (r ExitCodeRange) Overlaps(other ExitCodeRange) bool {
+	return r.First <= other.Last && other.First <= r.Last
+}
+
+// The range of exit codes allocated to a builtin actor.
+type ActorExitCodeRange struct {
+	Code  cid.Cid
+	Range ExitCodeRange
+}
+
+// Lists the exit code range allocated to each builtin actor.
+func ActorExitCodeRanges() []ActorExitCodeRange {
+	return []ActorExitCodeRange{
+		{StorageMarketActorCodeID, NewExitCodeRange(FirstMarketExitCode)},
+		{StorageMinerActorCodeID, NewExitCodeRange(FirstMinerExitCode)},
+		{StoragePowerActorCodeID, NewExitCodeRange(FirstPowerExitCode)},
+		{PaymentChannelActorCodeID, NewExitCodeRange(FirstPaychExitCode)},
+		{VerifiedRegistryActorCodeID, NewExitCodeRange(FirstVerifiedRegistryExitCode)},
+		{MultisigActorCodeID, NewExitCodeRange(FirstMultisigExitCode)},
+		{RewardActorCodeID, NewExitCodeRange(FirstRewardExitCode)},
+		{InitActorCodeID, NewExitCodeRange(FirstInitExitCode)},
+		{CronActorCodeID, NewExitCodeRange(FirstCronExitCode)},
+		{AccountActorCodeID, NewExitCodeRange(FirstAccountExitCode)},
+		{SystemActorCodeID, NewExitCodeRange(FirstSystemExitCode)},
+	}
+}
+
+// Returns the builtin actor whose range contains an exit code, if any.
+func ActorForExitCode(code exitcode.ExitCode) (cid.Cid, bool) {
+	for _, r := range ActorExitCodeRanges() {
+		if r.Range.Contains(code) {
+			return r.Code, true
+		}
+	}
+	return cid.Undef, false
+}
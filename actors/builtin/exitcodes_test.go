@@ -0,0 +1,53 @@
+package builtin_test
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin/market"
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin/miner"
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin/paych"
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin/power"
+)
+
+func TestActorExitCodeRanges(t *testing.T) {
+	ranges := builtin.ActorExitCodeRanges()
+
+	t.Run("ranges are disjoint and actor specific", func(t *testing.T) {
+		for i, r := range ranges {
+			assert.True(t, builtin.IsBuiltinActor(r.Code), "range %d is not allocated to a builtin actor", i)
+			assert.True(t, r.Range.First >= exitcode.FirstActorSpecificExitCode, "range for %s overlaps common codes", builtin.ActorNameByCode(r.Code))
+			for _, other := range ranges[i+1:] {
+				assert.False(t, r.Range.Overlaps(other.Range), "ranges for %s and %s overlap",
+					builtin.ActorNameByCode(r.Code), builtin.ActorNameByCode(other.Code))
+			}
+		}
+	})
+
+	t.Run("actor codes lie in their actor's range", func(t *testing.T) {
+		for code, actor := range map[exitcode.ExitCode]string{ // nolint:nomaprange
+			market.ErrDealOutlivesSector:            "fil/3/storagemarket",
+			power.ErrTooManyProveCommits:            "fil/3/storagepower",
+			paych.ErrChannelStateUpdateAfterSettled: "fil/3/paymentchannel",
+		} {
+			owner, ok := builtin.ActorForExitCode(code)
+			assert.True(t, ok, "code %d is not allocated", code)
+			assert.Equal(t, actor, builtin.ActorNameByCode(owner), "code %d", code)
+		}
+
+		// Codes identifying broken invariants are not allocated from the ranges.
+		_, ok := builtin.ActorForExitCode(miner.ErrBalanceInvariantBroken)
+		assert.False(t, ok)
+	})
+
+	t.Run("range codes", func(t *testing.T) {
+		r := builtin.NewExitCodeRange(builtin.FirstMarketExitCode)
+		assert.Equal(t, exitcode.ExitCode(32), r.Code(0))
+		assert.Equal(t, exitcode.ExitCode(47), r.Code(15))
+		assert.Panics(t, func() { r.Code(16) })
+		assert.Panics(t, func() { r.Code(-1) })
+	})
+}
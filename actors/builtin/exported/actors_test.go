@@ -47,7 +47,7 @@ func TestKnownActors(t *testing.T) {
 		{paych.Actor{}, builtin.PaymentChannelActorCodeID, builtin.MethodsPaych},
 		{power.Actor{}, builtin.StoragePowerActorCodeID, builtin.MethodsPower},
 		{reward.Actor{}, builtin.RewardActorCodeID, builtin.MethodsReward},
-		{system.Actor{}, builtin.SystemActorCodeID, nil},
+		{system.Actor{}, builtin.SystemActorCodeID, builtin.MethodsSystem},
 		{verifreg.Actor{}, builtin.VerifiedRegistryActorCodeID, builtin.MethodsVerifiedRegistry},
 	}
 	require.Equal(t, len(builtins), len(actorInfos))
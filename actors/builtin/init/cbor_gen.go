@@ -13,7 +13,7 @@ import (
 
 var _ = xerrors.Errorf
 
-var lengthBufState = []byte{131}
+var lengthBufState = []byte{133}
 
 func (t *State) MarshalCBOR(w io.Writer) error {
 	if t == nil {
@@ -49,6 +49,19 @@ func (t *State) MarshalCBOR(w io.Writer) error {
 	if _, err := io.WriteString(w, string(t.NetworkName)); err != nil {
 		return err
 	}
+
+	// t.ReverseMap (cid.Cid) (struct)
+
+	if err := cbg.WriteCidBuf(scratch, w, t.ReverseMap); err != nil {
+		return xerrors.Errorf("failed to write cid field t.ReverseMap: %w", err)
+	}
+
+	// t.Tombstones (cid.Cid) (struct)
+
+	if err := cbg.WriteCidBuf(scratch, w, t.Tombstones); err != nil {
+		return xerrors.Errorf("failed to write cid field t.Tombstones: %w", err)
+	}
+
 	return nil
 }
 
@@ -66,7 +79,7 @@ func (t *State) UnmarshalCBOR(r io.Reader) error {
 		return fmt.Errorf("cbor input should be of type array")
 	}
 
-	if extra != 3 {
+	if extra != 5 {
 		return fmt.Errorf("cbor input had wrong number of fields")
 	}
 
@@ -106,5 +119,121 @@ func (t *State) UnmarshalCBOR(r io.Reader) error {
 
 		t.NetworkName = string(sval)
 	}
+	// t.ReverseMap (cid.Cid) (struct)
+
+	{
+
+		c, err := cbg.ReadCid(br)
+		if err != nil {
+			return xerrors.Errorf("failed to read cid field t.ReverseMap: %w", err)
+		}
+
+		t.ReverseMap = c
+
+	}
+	// t.Tombstones (cid.Cid) (struct)
+
+	{
+
+		c, err := cbg.ReadCid(br)
+		if err != nil {
+			return xerrors.Errorf("failed to read cid field t.Tombstones: %w", err)
+		}
+
+		t.Tombstones = c
+
+	}
+	return nil
+}
+
+var lengthBufLookupActorStatusReturn = []byte{130}
+
+func (t *LookupActorStatusReturn) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write(lengthBufLookupActorStatusReturn); err != nil {
+		return err
+	}
+
+	scratch := make([]byte, 9)
+
+	// t.Status (init.ActorStatus) (uint64)
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajUnsignedInt, uint64(t.Status)); err != nil {
+		return err
+	}
+
+	// t.DeletedEpoch (abi.ChainEpoch) (int64)
+	if t.DeletedEpoch >= 0 {
+		if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajUnsignedInt, uint64(t.DeletedEpoch)); err != nil {
+			return err
+		}
+	} else {
+		if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajNegativeInt, uint64(-t.DeletedEpoch-1)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *LookupActorStatusReturn) UnmarshalCBOR(r io.Reader) error {
+	*t = LookupActorStatusReturn{}
+
+	br := cbg.GetPeeker(r)
+	scratch := make([]byte, 8)
+
+	maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 2 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.Status (init.ActorStatus) (uint64)
+
+	{
+
+		maj, extra, err = cbg.CborReadHeaderBuf(br, scratch)
+		if err != nil {
+			return err
+		}
+		if maj != cbg.MajUnsignedInt {
+			return fmt.Errorf("wrong type for uint64 field")
+		}
+		t.Status = ActorStatus(extra)
+
+	}
+	// t.DeletedEpoch (abi.ChainEpoch) (int64)
+	{
+		maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+		var extraI int64
+		if err != nil {
+			return err
+		}
+		switch maj {
+		case cbg.MajUnsignedInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 positive overflow")
+			}
+		case cbg.MajNegativeInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 negative oveflow")
+			}
+			extraI = -1 - extraI
+		default:
+			return fmt.Errorf("wrong type for int64 field: %d", maj)
+		}
+
+		t.DeletedEpoch = abi.ChainEpoch(extraI)
+	}
 	return nil
 }
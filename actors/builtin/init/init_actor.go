@@ -21,6 +21,9 @@ func (a Actor) Exports() []interface{} {
 	return []interface{}{
 		builtin.MethodConstructor: a.Constructor,
 		2:                         a.Exec,
+		3:                         a.RecordTombstone,
+		4:                         a.LookupRobustAddress,
+		5:                         a.LookupActorStatus,
 	}
 }
 
@@ -77,9 +80,8 @@ func (a Actor) Exec(rt runtime.Runtime, params *ExecParams) *ExecReturn {
 
 	// Allocate an ID for this actor.
 	// Store mapping of pubkey or actor address to actor ID
-	var st State
 	var idAddr addr.Address
-	rt.StateTransaction(&st, func() {
+	WithState(rt, func(st *State) {
 		var err error
 		idAddr, err = st.MapAddressToNewID(adt.AsStore(rt), uniqueAddress)
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to allocate ID address")
@@ -95,6 +97,53 @@ func (a Actor) Exec(rt runtime.Runtime, params *ExecParams) *ExecReturn {
 	return &ExecReturn{IDAddress: idAddr, RobustAddress: uniqueAddress}
 }
 
+// Records that the calling actor is about to delete itself, so that its addresses continue to resolve
+// as deleted rather than unknown. Only actors that may delete themselves may call this.
+func (a Actor) RecordTombstone(rt runtime.Runtime, _ *abi.EmptyValue) *abi.EmptyValue {
+	rt.ValidateImmediateCallerType(builtin.PaymentChannelActorCodeID)
+	callerID, err := addr.IDFromAddress(rt.Caller())
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to extract ID from caller %v", rt.Caller())
+
+	WithState(rt, func(st *State) {
+		err := st.RecordTombstone(adt.AsStore(rt), abi.ActorID(callerID), rt.CurrEpoch())
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "failed to record tombstone for %v", rt.Caller())
+	})
+	return nil
+}
+
+// Returns the robust address for which an ID-address was allocated, including for deleted actors.
+func (a Actor) LookupRobustAddress(rt runtime.Runtime, params *addr.Address) *addr.Address {
+	rt.ValidateImmediateCallerAcceptAny()
+	id, err := addr.IDFromAddress(*params)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "address %v is not an ID-address", *params)
+
+	st := ReadState(rt)
+	robust, found, err := st.LookupRobustAddress(adt.AsStore(rt), abi.ActorID(id))
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to look up robust address for %v", *params)
+	if !found {
+		rt.Abortf(exitcode.ErrNotFound, "no robust address for %v", *params)
+	}
+	return &robust
+}
+
+type LookupActorStatusReturn struct {
+	Status       ActorStatus
+	DeletedEpoch abi.ChainEpoch // Epoch at which the actor was deleted, or zero if it is not deleted.
+}
+
+// Reports whether an ID or robust address was never allocated, belongs to a live actor, or belonged to
+// an actor that has been deleted.
+func (a Actor) LookupActorStatus(rt runtime.Runtime, params *addr.Address) *LookupActorStatusReturn {
+	rt.ValidateImmediateCallerAcceptAny()
+	st := ReadState(rt)
+	status, deletedEpoch, err := st.LookupActorStatus(adt.AsStore(rt), *params)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to look up status of %v", *params)
+	return &LookupActorStatusReturn{
+		Status:       status,
+		DeletedEpoch: deletedEpoch,
+	}
+}
+
 func canExec(callerCodeID cid.Cid, execCodeID cid.Cid) bool {
 	switch execCodeID {
 	case builtin.StorageMinerActorCodeID:
@@ -8,6 +8,7 @@ import (
 	xerrors "golang.org/x/xerrors"
 
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v3/actors/runtime"
 	"github.com/filecoin-project/specs-actors/v3/actors/util/adt"
 )
 
@@ -15,8 +16,22 @@ type State struct {
 	AddressMap  cid.Cid // HAMT[addr.Address]abi.ActorID
 	NextID      abi.ActorID
 	NetworkName string
+	ReverseMap  cid.Cid // HAMT[abi.ActorID]addr.Address, the robust address each mapped ID was allocated for
+	Tombstones  cid.Cid // HAMT[abi.ActorID]abi.ChainEpoch, the epoch at which a deleted actor was removed
 }
 
+// Existence of an actor address, as far as the init actor can tell.
+type ActorStatus uint64
+
+const (
+	// No actor was ever allocated the address.
+	ActorStatusUnknown ActorStatus = iota
+	// An actor was allocated the address and has not been recorded as deleted.
+	ActorStatusActive
+	// An actor was allocated the address and has since been deleted.
+	ActorStatusDeleted
+)
+
 func ConstructState(store adt.Store, networkName string) (*State, error) {
 	emptyAddressMapCid, err := adt.StoreEmptyMap(store, builtin.DefaultHamtBitwidth)
 	if err != nil {
@@ -27,9 +42,26 @@ func ConstructState(store adt.Store, networkName string) (*State, error) {
 		AddressMap:  emptyAddressMapCid,
 		NextID:      abi.ActorID(builtin.FirstNonSingletonActorId),
 		NetworkName: networkName,
+		ReverseMap:  emptyAddressMapCid,
+		Tombstones:  emptyAddressMapCid,
 	}, nil
 }
 
+// Loads the init actor state for mutation by f, committing it when f returns.
+func WithState(rt runtime.Runtime, f func(st *State)) {
+	var st State
+	rt.StateTransaction(&st, func() {
+		f(&st)
+	})
+}
+
+// Loads a read-only copy of the init actor state.
+func ReadState(rt runtime.Runtime) *State {
+	var st State
+	rt.StateReadonly(&st)
+	return &st
+}
+
 // ResolveAddress resolves an address to an ID-address, if possible.
 // If the provided address is an ID address, it is returned as-is.
 // This means that mapped ID-addresses (which should only appear as values, not keys) and
@@ -82,6 +114,91 @@ func (s *State) MapAddressToNewID(store adt.Store, address addr.Address) (addr.A
 	}
 	s.AddressMap = amr
 
+	rm, err := adt.AsMap(store, s.ReverseMap, builtin.DefaultHamtBitwidth)
+	if err != nil {
+		return addr.Undef, xerrors.Errorf("failed to load reverse address map: %w", err)
+	}
+	if err = rm.Put(abi.UIntKey(uint64(actorID)), &address); err != nil {
+		return addr.Undef, xerrors.Errorf("map address failed to store reverse entry: %w", err)
+	}
+	if s.ReverseMap, err = rm.Root(); err != nil {
+		return addr.Undef, xerrors.Errorf("failed to get reverse address map root: %w", err)
+	}
+
 	idAddr, err := addr.NewIDAddress(uint64(actorID))
 	return idAddr, err
 }
+
+// LookupRobustAddress returns the address for which an ID was allocated.
+// Entries are retained after the actor is deleted.
+// Returns an undefined address and `false` if the ID was not allocated by mapping an address,
+// as is the case for singleton actors.
+func (s *State) LookupRobustAddress(store adt.Store, id abi.ActorID) (addr.Address, bool, error) {
+	rm, err := adt.AsMap(store, s.ReverseMap, builtin.DefaultHamtBitwidth)
+	if err != nil {
+		return addr.Undef, false, xerrors.Errorf("failed to load reverse address map: %w", err)
+	}
+	var robust addr.Address
+	found, err := rm.Get(abi.UIntKey(uint64(id)), &robust)
+	if err != nil {
+		return addr.Undef, false, xerrors.Errorf("failed to get from reverse address map: %w", err)
+	}
+	if !found {
+		return addr.Undef, false, nil
+	}
+	return robust, true, nil
+}
+
+// Records that the actor with an ID has been deleted at an epoch.
+// Fails if the ID has not been allocated or a tombstone already exists for it.
+func (s *State) RecordTombstone(store adt.Store, id abi.ActorID, epoch abi.ChainEpoch) error {
+	if id >= s.NextID {
+		return xerrors.Errorf("actor ID %d has not been allocated", id)
+	}
+	tm, err := adt.AsMap(store, s.Tombstones, builtin.DefaultHamtBitwidth)
+	if err != nil {
+		return xerrors.Errorf("failed to load tombstones: %w", err)
+	}
+	deletedEpoch := cbg.CborInt(epoch)
+	if absent, err := tm.PutIfAbsent(abi.UIntKey(uint64(id)), &deletedEpoch); err != nil {
+		return xerrors.Errorf("failed to store tombstone for %d: %w", id, err)
+	} else if !absent {
+		return xerrors.Errorf("actor %d already has a tombstone", id)
+	}
+	if s.Tombstones, err = tm.Root(); err != nil {
+		return xerrors.Errorf("failed to get tombstones root: %w", err)
+	}
+	return nil
+}
+
+// LookupActorStatus reports whether an address (ID or robust) was ever allocated to an actor, and
+// whether that actor has since been deleted.
+// The epoch of deletion is returned for deleted actors, and zero otherwise.
+func (s *State) LookupActorStatus(store adt.Store, address addr.Address) (ActorStatus, abi.ChainEpoch, error) {
+	idAddr, found, err := s.ResolveAddress(store, address)
+	if err != nil {
+		return ActorStatusUnknown, 0, err
+	}
+	if !found {
+		return ActorStatusUnknown, 0, nil
+	}
+	id, err := addr.IDFromAddress(idAddr)
+	if err != nil {
+		return ActorStatusUnknown, 0, xerrors.Errorf("failed to extract ID from %v: %w", idAddr, err)
+	}
+	if abi.ActorID(id) >= s.NextID {
+		return ActorStatusUnknown, 0, nil
+	}
+
+	tm, err := adt.AsMap(store, s.Tombstones, builtin.DefaultHamtBitwidth)
+	if err != nil {
+		return ActorStatusUnknown, 0, xerrors.Errorf("failed to load tombstones: %w", err)
+	}
+	var deletedEpoch cbg.CborInt
+	if found, err := tm.Get(abi.UIntKey(id), &deletedEpoch); err != nil {
+		return ActorStatusUnknown, 0, xerrors.Errorf("failed to get tombstone for %d: %w", id, err)
+	} else if found {
+		return ActorStatusDeleted, abi.ChainEpoch(deletedEpoch), nil
+	}
+	return ActorStatusActive, 0, nil
+}
@@ -212,6 +212,92 @@ func TestExec(t *testing.T) {
 	})
 }
 
+func TestTombstones(t *testing.T) {
+	actor := initHarness{init_.Actor{}, t}
+
+	receiver := tutil.NewIDAddr(t, 1000)
+	anne := tutil.NewIDAddr(t, 1001)
+	builder := mock.NewBuilder(receiver).WithCaller(builtin.SystemActorAddr, builtin.SystemActorCodeID)
+
+	createPaych := func(rt *mock.Runtime) *init_.ExecReturn {
+		rt.SetCaller(anne, builtin.AccountActorCodeID)
+		uniqueAddr := tutil.NewActorAddr(t, "paych")
+		rt.SetNewActorAddress(uniqueAddr)
+		idAddr := tutil.NewIDAddr(t, 100)
+		rt.ExpectCreateActor(builtin.PaymentChannelActorCodeID, idAddr)
+		params := builtin.CBORBytes([]byte{'P', 'C'})
+		rt.ExpectSend(idAddr, builtin.MethodConstructor, params, big.Zero(), nil, exitcode.Ok)
+		return actor.execAndVerify(rt, builtin.PaymentChannelActorCodeID, params)
+	}
+
+	t.Run("unknown addresses", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+
+		ret := actor.lookupActorStatus(rt, tutil.NewActorAddr(t, "nobody"))
+		assert.Equal(t, init_.ActorStatusUnknown, ret.Status)
+		ret = actor.lookupActorStatus(rt, tutil.NewIDAddr(t, 100))
+		assert.Equal(t, init_.ActorStatusUnknown, ret.Status)
+
+		rt.ExpectValidateCallerAny()
+		rt.ExpectAbort(exitcode.ErrNotFound, func() {
+			idAddr := tutil.NewIDAddr(t, 100)
+			rt.Call(actor.LookupRobustAddress, &idAddr)
+		})
+		actor.checkState(rt)
+	})
+
+	t.Run("robust address resolves for live and deleted actors", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		execRet := createPaych(rt)
+
+		assert.Equal(t, execRet.RobustAddress, actor.lookupRobustAddress(rt, execRet.IDAddress))
+		for _, a := range []addr.Address{execRet.IDAddress, execRet.RobustAddress} {
+			ret := actor.lookupActorStatus(rt, a)
+			assert.Equal(t, init_.ActorStatusActive, ret.Status)
+			assert.Equal(t, abi.ChainEpoch(0), ret.DeletedEpoch)
+		}
+
+		rt.SetEpoch(42)
+		actor.recordTombstone(rt, execRet.IDAddress)
+
+		assert.Equal(t, execRet.RobustAddress, actor.lookupRobustAddress(rt, execRet.IDAddress))
+		for _, a := range []addr.Address{execRet.IDAddress, execRet.RobustAddress} {
+			ret := actor.lookupActorStatus(rt, a)
+			assert.Equal(t, init_.ActorStatusDeleted, ret.Status)
+			assert.Equal(t, abi.ChainEpoch(42), ret.DeletedEpoch)
+		}
+		actor.checkState(rt)
+	})
+
+	t.Run("cannot tombstone twice", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		execRet := createPaych(rt)
+		actor.recordTombstone(rt, execRet.IDAddress)
+
+		rt.SetCaller(execRet.IDAddress, builtin.PaymentChannelActorCodeID)
+		rt.ExpectValidateCallerType(builtin.PaymentChannelActorCodeID)
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			rt.Call(actor.RecordTombstone, nil)
+		})
+		actor.checkState(rt)
+	})
+
+	t.Run("only payment channels may record a tombstone", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+
+		rt.SetCaller(anne, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerType(builtin.PaymentChannelActorCodeID)
+		rt.ExpectAbort(exitcode.SysErrForbidden, func() {
+			rt.Call(actor.RecordTombstone, nil)
+		})
+		actor.checkState(rt)
+	})
+}
+
 type initHarness struct {
 	init_.Actor
 	t testing.TB
@@ -244,6 +330,28 @@ func (h *initHarness) constructAndVerify(rt *mock.Runtime) {
 	assert.Equal(h.t, "mock", st.NetworkName)
 }
 
+func (h *initHarness) recordTombstone(rt *mock.Runtime, deleted addr.Address) {
+	rt.SetCaller(deleted, builtin.PaymentChannelActorCodeID)
+	rt.ExpectValidateCallerType(builtin.PaymentChannelActorCodeID)
+	ret := rt.Call(h.RecordTombstone, nil)
+	assert.Nil(h.t, ret)
+	rt.Verify()
+}
+
+func (h *initHarness) lookupRobustAddress(rt *mock.Runtime, idAddr addr.Address) addr.Address {
+	rt.ExpectValidateCallerAny()
+	ret := rt.Call(h.LookupRobustAddress, &idAddr).(*addr.Address)
+	rt.Verify()
+	return *ret
+}
+
+func (h *initHarness) lookupActorStatus(rt *mock.Runtime, a addr.Address) *init_.LookupActorStatusReturn {
+	rt.ExpectValidateCallerAny()
+	ret := rt.Call(h.LookupActorStatus, &a).(*init_.LookupActorStatusReturn)
+	rt.Verify()
+	return ret
+}
+
 func (h *initHarness) execAndVerify(rt *mock.Runtime, codeID cid.Cid, constructorParams []byte) *init_.ExecReturn {
 	rt.ExpectValidateCallerAny()
 	ret := rt.Call(h.Exec, &init_.ExecParams{
@@ -10,8 +10,9 @@ import (
 )
 
 type StateSummary struct {
-	AddrIDs map[addr.Address]abi.ActorID
-	NextID  abi.ActorID
+	AddrIDs    map[addr.Address]abi.ActorID
+	NextID     abi.ActorID
+	Tombstones map[abi.ActorID]abi.ChainEpoch
 }
 
 // Checks internal invariants of init state.
@@ -55,5 +56,44 @@ func CheckStateInvariants(st *State, store adt.Store) (*StateSummary, *builtin.M
 		return nil
 	})
 	acc.RequireNoError(err, "error iterating address map")
+
+	reverseMap, err := adt.AsMap(store, st.ReverseMap, builtin.DefaultHamtBitwidth)
+	if err != nil {
+		acc.Addf("error loading reverse address map: %v", err)
+		return initSummary, acc
+	}
+	var robust addr.Address
+	reverseCount := 0
+	err = reverseMap.ForEach(&robust, func(key string) error {
+		actorId, err := abi.ParseUIntKey(key)
+		if err != nil {
+			return err
+		}
+		reverseCount++
+		expected, found := reverse[abi.ActorID(actorId)]
+		acc.Require(found, "reverse mapping for ID %d to %v has no forward mapping", actorId, robust)
+		acc.Require(!found || expected == robust, "reverse mapping for ID %d is %v, forward mapping is from %v", actorId, robust, expected)
+		return nil
+	})
+	acc.RequireNoError(err, "error iterating reverse address map")
+	acc.Require(reverseCount == len(reverse), "reverse address map has %d entries, address map has %d", reverseCount, len(reverse))
+
+	tombstones, err := adt.AsMap(store, st.Tombstones, builtin.DefaultHamtBitwidth)
+	if err != nil {
+		acc.Addf("error loading tombstones: %v", err)
+		return initSummary, acc
+	}
+	initSummary.Tombstones = map[abi.ActorID]abi.ChainEpoch{}
+	var deletedEpoch cbg.CborInt
+	err = tombstones.ForEach(&deletedEpoch, func(key string) error {
+		actorId, err := abi.ParseUIntKey(key)
+		if err != nil {
+			return err
+		}
+		acc.Require(abi.ActorID(actorId) < st.NextID, "tombstone for unallocated ID %d", actorId)
+		initSummary.Tombstones[abi.ActorID(actorId)] = abi.ChainEpoch(deletedEpoch)
+		return nil
+	})
+	acc.RequireNoError(err, "error iterating tombstones")
 	return initSummary, acc
 }
@@ -0,0 +1,105 @@
+package manifest
+
+import (
+	"context"
+	"sort"
+
+	"github.com/ipfs/go-cid"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/util/adt"
+)
+
+// An Index relates actor code CIDs to actor names across one or more versions of the actors,
+// so that a runtime holding several versions can identify the code of any builtin actor.
+// The zero value is not usable; construct with NewIndex.
+type Index struct {
+	byCode    map[cid.Cid]IndexEntry
+	byVersion map[uint64]map[string]cid.Cid
+}
+
+// The version and name of the actor identified by a code CID.
+type IndexEntry struct {
+	Version uint64
+	Name    string
+}
+
+func NewIndex() *Index {
+	return &Index{
+		byCode:    make(map[cid.Cid]IndexEntry),
+		byVersion: make(map[uint64]map[string]cid.Cid),
+	}
+}
+
+// Add records the actor codes of one version of the actors.
+// It is an error to add a version twice, or a code already recorded for a different actor or version.
+func (x *Index) Add(version uint64, codes map[string]cid.Cid) error {
+	if _, found := x.byVersion[version]; found {
+		return xerrors.Errorf("duplicate codes for actors version %d", version)
+	}
+	names := make([]string, 0, len(codes))
+	for name := range codes { //nolint:nomaprange
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		code := codes[name]
+		if !code.Defined() {
+			return xerrors.Errorf("undefined code for actor %s version %d", name, version)
+		}
+		if prev, found := x.byCode[code]; found {
+			return xerrors.Errorf("code %v for actor %s version %d already recorded for actor %s version %d",
+				code, name, version, prev.Name, prev.Version)
+		}
+	}
+
+	byName := make(map[string]cid.Cid, len(names))
+	for _, name := range names {
+		byName[name] = codes[name]
+		x.byCode[codes[name]] = IndexEntry{Version: version, Name: name}
+	}
+	x.byVersion[version] = byName
+	return nil
+}
+
+// AddManifest loads the manifest with the given root and records its actor codes under the manifest's version.
+func (x *Index) AddManifest(ctx context.Context, store adt.Store, root cid.Cid) error {
+	m, err := Load(ctx, store, root)
+	if err != nil {
+		return err
+	}
+	codes, err := m.Codes(ctx, store)
+	if err != nil {
+		return err
+	}
+	return x.Add(m.Version, codes)
+}
+
+// IsBuiltinActor returns true if the code belongs to an actor of any recorded version.
+func (x *Index) IsBuiltinActor(code cid.Cid) bool {
+	_, found := x.byCode[code]
+	return found
+}
+
+// ActorNameByCode returns the name and version of the actor with the given code, if recorded.
+func (x *Index) ActorNameByCode(code cid.Cid) (IndexEntry, bool) {
+	e, found := x.byCode[code]
+	return e, found
+}
+
+// CodeByName returns the code of the named actor in the given version of the actors, if recorded.
+func (x *Index) CodeByName(version uint64, name string) (cid.Cid, bool) {
+	code, found := x.byVersion[version][name]
+	return code, found
+}
+
+// Versions returns the recorded versions of the actors, in ascending order.
+func (x *Index) Versions() []uint64 {
+	versions := make([]uint64, 0, len(x.byVersion))
+	for v := range x.byVersion { //nolint:nomaprange
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+	return versions
+}
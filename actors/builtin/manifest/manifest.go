@@ -0,0 +1,132 @@
+package manifest
+
+import (
+	"context"
+	"sort"
+
+	"github.com/ipfs/go-cid"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v3/actors/util/adt"
+)
+
+// Names of the builtin actors, by which a manifest identifies the code for each actor type.
+// The names are stable across actor versions, so they relate the code CIDs of one version to the next.
+const (
+	SystemKey   = "system"
+	InitKey     = "init"
+	CronKey     = "cron"
+	AccountKey  = "account"
+	PowerKey    = "storagepower"
+	MinerKey    = "storageminer"
+	MarketKey   = "storagemarket"
+	PaychKey    = "paymentchannel"
+	MultisigKey = "multisig"
+	RewardKey   = "reward"
+	VerifregKey = "verifiedregistry"
+)
+
+// A Manifest records the code CID of each builtin actor for one version of the actors.
+// The entries are held in a separate block so that the manifest itself is small and cheap to load.
+type Manifest struct {
+	Version uint64  // Version of the actors described by the manifest
+	Data    cid.Cid // ManifestData
+}
+
+// ManifestData lists the actor codes of a manifest, ordered by name.
+type ManifestData struct {
+	Entries []ManifestEntry
+}
+
+type ManifestEntry struct {
+	Name string
+	Code cid.Cid
+}
+
+// BuiltinCodes returns the code CIDs of the actors in this repository, keyed by manifest name.
+func BuiltinCodes() map[string]cid.Cid {
+	return map[string]cid.Cid{
+		SystemKey:   builtin.SystemActorCodeID,
+		InitKey:     builtin.InitActorCodeID,
+		CronKey:     builtin.CronActorCodeID,
+		AccountKey:  builtin.AccountActorCodeID,
+		PowerKey:    builtin.StoragePowerActorCodeID,
+		MinerKey:    builtin.StorageMinerActorCodeID,
+		MarketKey:   builtin.StorageMarketActorCodeID,
+		PaychKey:    builtin.PaymentChannelActorCodeID,
+		MultisigKey: builtin.MultisigActorCodeID,
+		RewardKey:   builtin.RewardActorCodeID,
+		VerifregKey: builtin.VerifiedRegistryActorCodeID,
+	}
+}
+
+// Build stores a manifest of the given actor codes and returns the CID of its root.
+func Build(ctx context.Context, store adt.Store, version uint64, codes map[string]cid.Cid) (cid.Cid, error) {
+	var data ManifestData
+	for name, code := range codes { //nolint:nomaprange
+		if !code.Defined() {
+			return cid.Undef, xerrors.Errorf("undefined code for actor %s", name)
+		}
+		data.Entries = append(data.Entries, ManifestEntry{Name: name, Code: code})
+	}
+	sort.Slice(data.Entries, func(i, j int) bool {
+		return data.Entries[i].Name < data.Entries[j].Name
+	})
+
+	dataCid, err := store.Put(ctx, &data)
+	if err != nil {
+		return cid.Undef, xerrors.Errorf("failed to store manifest data: %w", err)
+	}
+	root, err := store.Put(ctx, &Manifest{Version: version, Data: dataCid})
+	if err != nil {
+		return cid.Undef, xerrors.Errorf("failed to store manifest: %w", err)
+	}
+	return root, nil
+}
+
+// Load reads the manifest with the given root.
+func Load(ctx context.Context, store adt.Store, root cid.Cid) (*Manifest, error) {
+	var m Manifest
+	if err := store.Get(ctx, root, &m); err != nil {
+		return nil, xerrors.Errorf("failed to load manifest %v: %w", root, err)
+	}
+	return &m, nil
+}
+
+// Codes reads the manifest's actor codes, keyed by name.
+func (m *Manifest) Codes(ctx context.Context, store adt.Store) (map[string]cid.Cid, error) {
+	var data ManifestData
+	if err := store.Get(ctx, m.Data, &data); err != nil {
+		return nil, xerrors.Errorf("failed to load manifest data %v: %w", m.Data, err)
+	}
+	codes := make(map[string]cid.Cid, len(data.Entries))
+	for _, e := range data.Entries {
+		if _, found := codes[e.Name]; found {
+			return nil, xerrors.Errorf("duplicate manifest entry for actor %s", e.Name)
+		}
+		codes[e.Name] = e.Code
+	}
+	return codes, nil
+}
+
+// CodeMapping relates the code of each actor in one set of codes to the code of the same-named actor in another,
+// e.g. from one version of the actors to the next.
+// It is an error for any actor in the first set to be missing from the second.
+func CodeMapping(from, to map[string]cid.Cid) (map[cid.Cid]cid.Cid, error) {
+	names := make([]string, 0, len(from))
+	for name := range from { //nolint:nomaprange
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	mapping := make(map[cid.Cid]cid.Cid, len(names))
+	for _, name := range names {
+		newCode, found := to[name]
+		if !found {
+			return nil, xerrors.Errorf("no code for actor %s in target manifest", name)
+		}
+		mapping[from[name]] = newCode
+	}
+	return mapping, nil
+}
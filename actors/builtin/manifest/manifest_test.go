@@ -0,0 +1,132 @@
+package manifest_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin/manifest"
+	"github.com/filecoin-project/specs-actors/v3/support/ipld"
+	tutil "github.com/filecoin-project/specs-actors/v3/support/testing"
+)
+
+func TestManifest(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("round trip", func(t *testing.T) {
+		store := ipld.NewADTStore(ctx)
+		root, err := manifest.Build(ctx, store, 3, manifest.BuiltinCodes())
+		require.NoError(t, err)
+
+		m, err := manifest.Load(ctx, store, root)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(3), m.Version)
+
+		codes, err := m.Codes(ctx, store)
+		require.NoError(t, err)
+		assert.Equal(t, manifest.BuiltinCodes(), codes)
+	})
+
+	t.Run("root is independent of map order", func(t *testing.T) {
+		store := ipld.NewADTStore(ctx)
+		root1, err := manifest.Build(ctx, store, 3, manifest.BuiltinCodes())
+		require.NoError(t, err)
+		root2, err := manifest.Build(ctx, store, 3, manifest.BuiltinCodes())
+		require.NoError(t, err)
+		assert.Equal(t, root1, root2)
+	})
+
+	t.Run("rejects undefined code", func(t *testing.T) {
+		store := ipld.NewADTStore(ctx)
+		_, err := manifest.Build(ctx, store, 3, map[string]cid.Cid{manifest.SystemKey: cid.Undef})
+		assert.Error(t, err)
+	})
+}
+
+func TestCodeMapping(t *testing.T) {
+	newSystem := tutil.MakeCID("system-v4", nil)
+	newCron := tutil.MakeCID("cron-v4", nil)
+	from := map[string]cid.Cid{
+		manifest.SystemKey: builtin.SystemActorCodeID,
+		manifest.CronKey:   builtin.CronActorCodeID,
+	}
+
+	t.Run("maps codes by name", func(t *testing.T) {
+		mapping, err := manifest.CodeMapping(from, map[string]cid.Cid{
+			manifest.SystemKey: newSystem,
+			manifest.CronKey:   newCron,
+			manifest.InitKey:   tutil.MakeCID("init-v4", nil),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, map[cid.Cid]cid.Cid{
+			builtin.SystemActorCodeID: newSystem,
+			builtin.CronActorCodeID:   newCron,
+		}, mapping)
+	})
+
+	t.Run("fails if an actor is missing", func(t *testing.T) {
+		_, err := manifest.CodeMapping(from, map[string]cid.Cid{manifest.SystemKey: newSystem})
+		assert.Error(t, err)
+	})
+}
+
+func TestIndex(t *testing.T) {
+	ctx := context.Background()
+	v4 := map[string]cid.Cid{
+		manifest.SystemKey: tutil.MakeCID("system-v4", nil),
+		manifest.CronKey:   tutil.MakeCID("cron-v4", nil),
+	}
+
+	t.Run("looks up codes across versions", func(t *testing.T) {
+		store := ipld.NewADTStore(ctx)
+		root, err := manifest.Build(ctx, store, 4, v4)
+		require.NoError(t, err)
+
+		idx := manifest.NewIndex()
+		require.NoError(t, idx.Add(3, manifest.BuiltinCodes()))
+		require.NoError(t, idx.AddManifest(ctx, store, root))
+		assert.Equal(t, []uint64{3, 4}, idx.Versions())
+
+		assert.True(t, idx.IsBuiltinActor(builtin.StorageMinerActorCodeID))
+		assert.True(t, idx.IsBuiltinActor(v4[manifest.CronKey]))
+		assert.False(t, idx.IsBuiltinActor(tutil.MakeCID("unknown", nil)))
+
+		e, found := idx.ActorNameByCode(builtin.StorageMinerActorCodeID)
+		require.True(t, found)
+		assert.Equal(t, manifest.IndexEntry{Version: 3, Name: manifest.MinerKey}, e)
+		e, found = idx.ActorNameByCode(v4[manifest.SystemKey])
+		require.True(t, found)
+		assert.Equal(t, manifest.IndexEntry{Version: 4, Name: manifest.SystemKey}, e)
+
+		code, found := idx.CodeByName(3, manifest.CronKey)
+		require.True(t, found)
+		assert.Equal(t, builtin.CronActorCodeID, code)
+		code, found = idx.CodeByName(4, manifest.CronKey)
+		require.True(t, found)
+		assert.Equal(t, v4[manifest.CronKey], code)
+		_, found = idx.CodeByName(4, manifest.MinerKey)
+		assert.False(t, found)
+		_, found = idx.CodeByName(5, manifest.CronKey)
+		assert.False(t, found)
+	})
+
+	t.Run("rejects duplicate versions and codes", func(t *testing.T) {
+		idx := manifest.NewIndex()
+		require.NoError(t, idx.Add(3, manifest.BuiltinCodes()))
+		assert.Error(t, idx.Add(3, v4))
+		assert.Error(t, idx.Add(4, map[string]cid.Cid{manifest.SystemKey: builtin.SystemActorCodeID}))
+
+		// A failed addition records nothing.
+		assert.Equal(t, []uint64{3}, idx.Versions())
+		require.NoError(t, idx.Add(4, v4))
+	})
+
+	t.Run("rejects undefined code", func(t *testing.T) {
+		idx := manifest.NewIndex()
+		assert.Error(t, idx.Add(4, map[string]cid.Cid{manifest.SystemKey: cid.Undef}))
+	})
+}
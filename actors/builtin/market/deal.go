@@ -1,6 +1,7 @@
 package market
 
 import (
+	"github.com/filecoin-project/go-state-types/abi"
 	market0 "github.com/filecoin-project/specs-actors/actors/builtin/market"
 )
 
@@ -50,3 +51,19 @@ type DealProposal = market0.DealProposal
 //	ClientSignature crypto.Signature
 //}
 type ClientDealProposal = market0.ClientDealProposal
+
+// The reason a deal terminated before completing its term.
+type DealTerminationReason uint64
+
+const (
+	// The deal was not activated in a sector by its start epoch and the activation grace period.
+	DealTerminationTimedOut DealTerminationReason = iota
+	// The sector containing the deal was terminated, or the deal was otherwise slashed.
+	DealTerminationSlashed
+)
+
+// Notification to a deal's client, through its universal receiver hook, that the deal terminated early.
+type DealTerminationNotification struct {
+	DealID abi.DealID
+	Reason DealTerminationReason
+}
@@ -0,0 +1,141 @@
+package market
+
+import (
+	addr "github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	cid "github.com/ipfs/go-cid"
+	cbg "github.com/whyrusleeping/cbor-gen"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v3/actors/util/adt"
+)
+
+// Bitwidth of the HAMT mapping escrow recipients to their contributions.
+const EscrowContributionsHamtBitwidth = builtin.DefaultHamtBitwidth
+
+// Records the cumulative amounts that payers have added to the escrow of other addresses with AddBalanceFor.
+// A HAMT maps each recipient to a balance table of contributions, keyed by payer.
+// Contributions are an attribution record only: they are not reduced when the recipient withdraws or spends its
+// escrow, and do not entitle the payer to any refund from the market.
+type EscrowContributions struct {
+	mp    *adt.Map
+	store adt.Store
+}
+
+// Interprets a store as a map of escrow contributions with root `r`.
+func AsEscrowContributions(s adt.Store, r cid.Cid) (*EscrowContributions, error) {
+	m, err := adt.AsMap(s, r, EscrowContributionsHamtBitwidth)
+	if err != nil {
+		return nil, err
+	}
+	return &EscrowContributions{mp: m, store: s}, nil
+}
+
+// Returns the root cid of the underlying HAMT.
+func (ec *EscrowContributions) Root() (cid.Cid, error) {
+	return ec.mp.Root()
+}
+
+// Adds a payer's contribution to the escrow of a recipient.
+func (ec *EscrowContributions) Add(recipient, payer addr.Address, amount abi.TokenAmount) error {
+	contributions, found, err := ec.get(recipient)
+	if err != nil {
+		return err
+	}
+	if !found {
+		emptyRoot, err := adt.StoreEmptyMap(ec.store, adt.BalanceTableBitwidth)
+		if err != nil {
+			return xerrors.Errorf("failed to create contributions for %v: %w", recipient, err)
+		}
+		if contributions, err = adt.AsBalanceTable(ec.store, emptyRoot); err != nil {
+			return xerrors.Errorf("failed to load contributions for %v: %w", recipient, err)
+		}
+	}
+
+	if err := contributions.Add(payer, amount); err != nil {
+		return xerrors.Errorf("failed to add contribution from %v to %v: %w", payer, recipient, err)
+	}
+
+	root, err := contributions.Root()
+	if err != nil {
+		return xerrors.Errorf("failed to flush contributions for %v: %w", recipient, err)
+	}
+	newRoot := cbg.CborCid(root)
+	return ec.mp.Put(abi.AddrKey(recipient), &newRoot)
+}
+
+// Returns the amount that a payer has contributed to the escrow of a recipient, which is zero if none.
+func (ec *EscrowContributions) Get(recipient, payer addr.Address) (abi.TokenAmount, error) {
+	contributions, found, err := ec.get(recipient)
+	if err != nil {
+		return abi.NewTokenAmount(0), err
+	}
+	if !found {
+		return abi.NewTokenAmount(0), nil
+	}
+	return contributions.Get(payer)
+}
+
+// Iterates the contributions to the escrow of a recipient, keyed by payer.
+func (ec *EscrowContributions) ForEach(recipient addr.Address, cb func(payer addr.Address, amount abi.TokenAmount) error) error {
+	contributions, found, err := ec.get(recipient)
+	if err != nil || !found {
+		return err
+	}
+	return contributions.ForEach(cb)
+}
+
+// Iterates the recipients for which contributions have been recorded.
+func (ec *EscrowContributions) ForEachRecipient(cb func(recipient addr.Address) error) error {
+	var root cbg.CborCid
+	return ec.mp.ForEach(&root, func(k string) error {
+		recipient, err := addr.NewFromBytes([]byte(k))
+		if err != nil {
+			return xerrors.Errorf("invalid recipient key: %w", err)
+		}
+		return cb(recipient)
+	})
+}
+
+func (ec *EscrowContributions) get(recipient addr.Address) (*adt.BalanceTable, bool, error) {
+	var root cbg.CborCid
+	found, err := ec.mp.Get(abi.AddrKey(recipient), &root)
+	if err != nil {
+		return nil, false, xerrors.Errorf("failed to load contributions for %v: %w", recipient, err)
+	}
+	if !found {
+		return nil, false, nil
+	}
+	contributions, err := adt.AsBalanceTable(ec.store, cid.Cid(root))
+	if err != nil {
+		return nil, false, xerrors.Errorf("failed to load contributions for %v: %w", recipient, err)
+	}
+	return contributions, true, nil
+}
+
+// Returns the cumulative amount that a payer has added to the escrow of a recipient with AddBalanceFor.
+func (st *State) EscrowContribution(store adt.Store, recipient, payer addr.Address) (abi.TokenAmount, error) {
+	contributions, err := AsEscrowContributions(store, st.EscrowContributions)
+	if err != nil {
+		return abi.NewTokenAmount(0), err
+	}
+	return contributions.Get(recipient, payer)
+}
+
+// Returns the cumulative amounts that other parties have added to the escrow of a recipient, indexed by payer.
+func (st *State) EscrowContributionsTo(store adt.Store, recipient addr.Address) (map[addr.Address]abi.TokenAmount, error) {
+	contributions, err := AsEscrowContributions(store, st.EscrowContributions)
+	if err != nil {
+		return nil, err
+	}
+	byPayer := make(map[addr.Address]abi.TokenAmount)
+	err = contributions.ForEach(recipient, func(payer addr.Address, amount abi.TokenAmount) error {
+		byPayer[payer] = amount
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return byPayer, nil
+}
@@ -0,0 +1,42 @@
+package market
+
+import (
+	addr "github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	cbg "github.com/whyrusleeping/cbor-gen"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+)
+
+// Types of the events emitted by the market actor.
+const (
+	EventDealPublished  = "deal-published"
+	EventDealActivated  = "deal-activated"
+	EventDealTerminated = "deal-terminated"
+	EventDealTimedOut   = "deal-timed-out"
+)
+
+// Emits an event of the given type for a deal, indexed by its ID and parties.
+func emitDealEvent(rt Runtime, eventType string, dealID abi.DealID, client, provider addr.Address) {
+	id := cbg.CborInt(dealID)
+	builtin.NewEventBuilder(eventType).
+		FieldIndexed("id", &id).
+		FieldIndexed("client", &client).
+		FieldIndexed("provider", &provider).
+		Emit(rt)
+}
+
+// Emits an event for a deal removed because it was not activated by the end of its activation grace period,
+// recording the client funds refunded and the provider collateral slashed.
+func emitDealTimedOutEvent(rt Runtime, dealID abi.DealID, deal *DealProposal, slashed abi.TokenAmount) {
+	id := cbg.CborInt(dealID)
+	refund := big.Add(deal.TotalStorageFee(), deal.ClientCollateral)
+	builtin.NewEventBuilder(EventDealTimedOut).
+		FieldIndexed("id", &id).
+		FieldIndexed("client", &deal.Client).
+		FieldIndexed("provider", &deal.Provider).
+		Field("client-refund", &refund).
+		Field("provider-slashed", &slashed).
+		Emit(rt)
+}
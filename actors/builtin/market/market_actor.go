@@ -6,6 +6,7 @@ import (
 	"sort"
 
 	addr "github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-bitfield"
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/big"
 	"github.com/filecoin-project/go-state-types/cbor"
@@ -23,6 +24,12 @@ import (
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin/verifreg"
 	"github.com/filecoin-project/specs-actors/v3/actors/runtime"
 	"github.com/filecoin-project/specs-actors/v3/actors/util/adt"
+	"github.com/filecoin-project/specs-actors/v3/actors/util/sizes"
+)
+
+const (
+	// A deal's term extends beyond the expiration of the sector in which it is to be activated.
+	ErrDealOutlivesSector = builtin.FirstMarketExitCode + iota
 )
 
 type Actor struct{}
@@ -40,6 +47,12 @@ func (a Actor) Exports() []interface{} {
 		7:                         a.OnMinerSectorsTerminate,
 		8:                         a.ComputeDataCommitment,
 		9:                         a.CronTick,
+		10:                        a.AddBalanceFor,
+		11:                        a.SettleDealPayments,
+		12:                        a.BatchActivateDeals,
+		13:                        a.MarketStats,
+		14:                        a.ReserveCollateral,
+		15:                        a.ReleaseCollateral,
 	}
 }
 
@@ -55,7 +68,19 @@ func (a Actor) State() cbor.Er {
 	return new(State)
 }
 
+// Limits on the parameters of exported methods, checked before a method is invoked.
+func (a Actor) ParamLimits() map[abi.MethodNum]builtin.ParamLimits {
+	return map[abi.MethodNum]builtin.ParamLimits{
+		builtin.MethodsMarket.PublishStorageDeals: {MaxLengths: []builtin.LengthLimit{{Field: "Deals", Max: BatchItemsMax}}},
+		builtin.MethodsMarket.SettleDealPayments:  {MaxLengths: []builtin.LengthLimit{{Field: "DealIDs", Max: BatchItemsMax}}},
+		builtin.MethodsMarket.BatchActivateDeals:  {MaxLengths: []builtin.LengthLimit{{Field: "Sectors", Max: BatchItemsMax}}},
+		builtin.MethodsMarket.ReserveCollateral:   {MaxLengths: []builtin.LengthLimit{{Field: "Reservations", Max: BatchItemsMax}}},
+		builtin.MethodsMarket.ReleaseCollateral:   {MaxLengths: []builtin.LengthLimit{{Field: "DealIDs", Max: BatchItemsMax}}},
+	}
+}
+
 var _ runtime.VMActor = Actor{}
+var _ builtin.ParamLimiter = Actor{}
 
 ////////////////////////////////////////////////////////////////////////////////
 // Actor methods
@@ -89,8 +114,7 @@ func (a Actor) WithdrawBalance(rt Runtime, params *WithdrawBalanceParams) *abi.E
 	rt.ValidateImmediateCallerIs(approvedCallers...)
 
 	amountExtracted := abi.NewTokenAmount(0)
-	var st State
-	rt.StateTransaction(&st, func() {
+	WithState(rt, func(st *State) {
 		msm, err := st.mutator(adt.AsStore(rt)).withEscrowTable(WritePermission).
 			withLockedTable(WritePermission).build()
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load state")
@@ -101,7 +125,7 @@ func (a Actor) WithdrawBalance(rt Runtime, params *WithdrawBalanceParams) *abi.E
 		minBalance, err := msm.lockedTable.Get(nominal)
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get locked balance")
 
-		ex, err := msm.escrowTable.SubtractWithMinimum(nominal, params.Amount, minBalance)
+		ex, err := msm.withdrawEscrow(nominal, params.Amount, minBalance)
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to subtract from escrow table")
 
 		err = msm.commitState()
@@ -125,13 +149,12 @@ func (a Actor) AddBalance(rt Runtime, providerOrClientAddress *addr.Address) *ab
 
 	nominal, _, _ := escrowAddress(rt, *providerOrClientAddress)
 
-	var st State
-	rt.StateTransaction(&st, func() {
+	WithState(rt, func(st *State) {
 		msm, err := st.mutator(adt.AsStore(rt)).withEscrowTable(WritePermission).
 			withLockedTable(WritePermission).build()
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load state")
 
-		err = msm.escrowTable.Add(nominal, msgValue)
+		err = msm.depositEscrow(nominal, msgValue)
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to add balance to escrow table")
 
 		err = msm.commitState()
@@ -140,15 +163,61 @@ func (a Actor) AddBalance(rt Runtime, providerOrClientAddress *addr.Address) *ab
 	return nil
 }
 
+// Deposits the received value into the balance held in escrow for another party, as AddBalance does, and
+// records the caller as a contributor to that escrow.
+// The record attributes funds to their payer for off-chain accounting (such as a deal broker settling refunds
+// with its clients); the market never returns funds to the payer.
+func (a Actor) AddBalanceFor(rt Runtime, providerOrClientAddress *addr.Address) *abi.EmptyValue {
+	msgValue := rt.ValueReceived()
+	builtin.RequireParam(rt, msgValue.GreaterThan(big.Zero()), "balance to add must be greater than zero")
+
+	rt.ValidateImmediateCallerType(builtin.CallerTypesSignable...)
+	payer := rt.Caller()
+
+	nominal, _, _ := escrowAddress(rt, *providerOrClientAddress)
+	builtin.RequireParam(rt, nominal != payer, "cannot add balance for self, use AddBalance")
+
+	WithState(rt, func(st *State) {
+		msm, err := st.mutator(adt.AsStore(rt)).withEscrowTable(WritePermission).
+			withEscrowContributions(WritePermission).build()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load state")
+
+		err = msm.depositEscrow(nominal, msgValue)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to add balance to escrow table")
+
+		err = msm.contributions.Add(nominal, payer, msgValue)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to record escrow contribution")
+
+		err = msm.commitState()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush state")
+	})
+	return nil
+}
+
+type MarketStatsReturn = MarketStats
+
+// Returns aggregate metrics of escrow, locked funds and active deals.
+func (a Actor) MarketStats(rt Runtime, _ *abi.EmptyValue) *MarketStatsReturn {
+	rt.ValidateImmediateCallerAcceptAny()
+	st := ReadState(rt)
+	return st.Stats()
+}
+
 //type PublishStorageDealsParams struct {
 //	Deals []ClientDealProposal
 //}
 type PublishStorageDealsParams = market0.PublishStorageDealsParams
 
-//type PublishStorageDealsReturn struct {
-//	IDs []abi.DealID
-//}
-type PublishStorageDealsReturn = market0.PublishStorageDealsReturn
+// Changed since v2:
+// - ProposalCIDs and ValidDeals added
+type PublishStorageDealsReturn struct {
+	IDs []abi.DealID
+	// CIDs of the accepted proposals (with client and provider addresses normalised to ID addresses),
+	// in the same order as IDs.
+	ProposalCIDs []cid.Cid
+	// Indices into the input deals of the proposals that were accepted.
+	ValidDeals bitfield.BitField
+}
 
 // Publish a new set of storage deals (not yet included in a sector).
 func (a Actor) PublishStorageDeals(rt Runtime, params *PublishStorageDealsParams) *PublishStorageDealsReturn {
@@ -186,51 +255,126 @@ func (a Actor) PublishStorageDeals(rt Runtime, params *PublishStorageDealsParams
 		rt.Abortf(exitcode.ErrForbidden, "caller %v is not worker or control address of provider %v", caller, provider)
 	}
 
-	resolvedAddrs := make(map[addr.Address]addr.Address, len(params.Deals))
 	baselinePower := requestCurrentBaselinePower(rt)
 	networkRawPower, networkQAPower := requestCurrentNetworkPower(rt)
 
-	var newDealIds []abi.DealID
-	var st State
-	rt.StateTransaction(&st, func() {
-		msm, err := st.mutator(adt.AsStore(rt)).withPendingProposals(WritePermission).
-			withDealProposals(WritePermission).withDealsByEpoch(WritePermission).withEscrowTable(WritePermission).
-			withLockedTable(WritePermission).build()
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load state")
+	// Invalid deals are dropped from the batch rather than failing the whole message, so that a single bad
+	// client proposal does not prevent a provider from publishing the rest of the batch.
+	// Failures attributable to the provider itself (e.g. insufficient provider collateral) abort the message.
+	var validDeals []ClientDealProposal
+	var validInputs []uint64
+	proposalCidLookup := make(map[cid.Cid]struct{}, len(params.Deals))
+	totalClientLockup := make(map[addr.Address]abi.TokenAmount)
+	totalProviderLockup := big.Zero()
+
+	st := ReadState(rt)
+	msm, err := st.mutator(adt.AsStore(rt)).withPendingProposals(ReadOnlyPermission).
+		withEscrowTable(ReadOnlyPermission).withLockedTable(ReadOnlyPermission).build()
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load state")
+
+	for di, deal := range params.Deals {
+		if err := validateDeal(rt, deal, networkRawPower, networkQAPower, baselinePower); err != nil {
+			rt.Log(rtt.INFO, "invalid deal %d: %s", di, err)
+			continue
+		}
 
-		// All storage dealProposals will be added in an atomic transaction; this operation will be unrolled if any of them fails.
-		for di, deal := range params.Deals {
-			validateDeal(rt, deal, networkRawPower, networkQAPower, baselinePower)
+		if deal.Proposal.Provider != provider && deal.Proposal.Provider != providerRaw {
+			rt.Log(rtt.INFO, "invalid deal %d: cannot publish deals from different providers at the same time", di)
+			continue
+		}
 
-			if deal.Proposal.Provider != provider && deal.Proposal.Provider != providerRaw {
-				rt.Abortf(exitcode.ErrIllegalArgument, "cannot publish deals from different providers at the same time")
-			}
+		client, ok := rt.ResolveAddress(deal.Proposal.Client)
+		if !ok {
+			rt.Log(rtt.INFO, "invalid deal %d: failed to resolve client address %v", di, deal.Proposal.Client)
+			continue
+		}
+		// Normalise provider and client addresses in the proposal stored on chain (after signature verification).
+		deal.Proposal.Provider = provider
+		deal.Proposal.Client = client
+
+		// Drop deals whose client cannot cover them, accounting for earlier deals in this batch.
+		clientLockup, ok := totalClientLockup[client]
+		if !ok {
+			clientLockup = big.Zero()
+		}
+		clientLockup = big.Add(clientLockup, deal.Proposal.ClientBalanceRequirement())
+		clientBalanceOk, err := msm.balanceCovered(client, clientLockup)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to check client balance coverage")
+		if !clientBalanceOk {
+			rt.Log(rtt.INFO, "invalid deal %d: insufficient client funds to cover proposal cost", di)
+			continue
+		}
 
-			client, ok := rt.ResolveAddress(deal.Proposal.Client)
-			if !ok {
-				rt.Abortf(exitcode.ErrNotFound, "failed to resolve client address %v", deal.Proposal.Client)
+		// Drop duplicates of deals already pending or earlier in this batch.
+		pcid, err := proposalCid(rt, &deal.Proposal)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "failed to take cid of proposal %d", di)
+		has, err := msm.pendingDeals.Has(abi.CidKey(pcid))
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to check for existence of deal proposal")
+		_, duplicateInMessage := proposalCidLookup[pcid]
+		if has || duplicateInMessage {
+			rt.Log(rtt.INFO, "invalid deal %d: cannot publish duplicate deal proposal %s", di, pcid)
+			continue
+		}
+
+		// Check VerifiedClient allowed cap and deduct PieceSize from cap.
+		// Either the DealSize is within the available DataCap of the VerifiedClient
+		// or the deal is dropped. We do not allow a deal that is partially verified.
+		if deal.Proposal.VerifiedDeal {
+			code := rt.Send(
+				builtin.VerifiedRegistryActorAddr,
+				builtin.MethodsVerifiedRegistry.UseBytes,
+				&verifreg.UseBytesParams{
+					Address:  client,
+					DealSize: big.NewIntUnsigned(uint64(deal.Proposal.PieceSize)),
+				},
+				abi.NewTokenAmount(0),
+				&builtin.Discard{},
+			)
+			if !code.IsSuccess() {
+				rt.Log(rtt.INFO, "invalid deal %d: failed to use verified datacap for client %v: %v", di, client, code)
+				continue
 			}
-			// Normalise provider and client addresses in the proposal stored on chain (after signature verification).
-			deal.Proposal.Provider = provider
-			resolvedAddrs[deal.Proposal.Client] = client
-			deal.Proposal.Client = client
+		}
+
+		// Check the provider's balance only after every check that drops the deal, so that a deal
+		// which would be dropped anyway cannot abort the whole message.
+		providerLockup := big.Add(totalProviderLockup, deal.Proposal.ProviderCollateral)
+		providerBalanceOk, err := msm.balanceCovered(provider, providerLockup)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to check provider balance coverage")
+		if !providerBalanceOk {
+			rt.Abortf(exitcode.ErrInsufficientFunds, "insufficient provider balance to cover deal %d", di)
+		}
+
+		totalClientLockup[client] = clientLockup
+		totalProviderLockup = providerLockup
+		proposalCidLookup[pcid] = struct{}{}
+		validDeals = append(validDeals, deal)
+		validInputs = append(validInputs, uint64(di))
+	}
+
+	if len(validDeals) == 0 {
+		rt.Abortf(exitcode.ErrIllegalArgument, "all deal proposals invalid")
+	}
 
+	var newDealIds []abi.DealID
+	var newDealCids []cid.Cid
+	WithState(rt, func(st *State) {
+		// Buffer writes so that only the final nodes of the many collections updated per deal are stored.
+		store := adt.AsCachedStore(rt)
+		msm, err := st.mutator(store).withPendingProposals(WritePermission).
+			withDealProposals(WritePermission).withDealsByEpoch(WritePermission).withEscrowTable(WritePermission).
+			withLockedTable(WritePermission).build()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load state")
+
+		// All valid storage dealProposals will be added in an atomic transaction.
+		for vi, deal := range validDeals {
 			err := msm.lockClientAndProviderBalances(&deal.Proposal)
 			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to lock balance")
 
 			id := msm.generateStorageDealID()
 
-			pcid, err := deal.Proposal.Cid()
-			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "failed to take cid of proposal %d", di)
-
-			has, err := msm.pendingDeals.Has(abi.CidKey(pcid))
-			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to check for existence of deal proposal")
-			if has {
-				rt.Abortf(exitcode.ErrIllegalArgument, "cannot publish duplicate deals")
-			}
-
-			err = msm.pendingDeals.Put(abi.CidKey(pcid))
-			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to set pending deal")
+			pcid, err := proposalCid(rt, &deal.Proposal)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "failed to take cid of proposal %d", validInputs[vi])
 
 			err = msm.dealProposals.Set(id, &deal.Proposal)
 			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to set deal")
@@ -244,35 +388,31 @@ func (a Actor) PublishStorageDeals(rt Runtime, params *PublishStorageDealsParams
 			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to set deal ops by epoch")
 
 			newDealIds = append(newDealIds, id)
+			newDealCids = append(newDealCids, pcid)
 		}
 
+		pendingKeys := make([]abi.Keyer, len(newDealCids))
+		for i, pcid := range newDealCids {
+			pendingKeys[i] = abi.CidKey(pcid)
+		}
+		err = msm.pendingDeals.AddMany(pendingKeys...)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to set pending deals")
+
 		err = msm.commitState()
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush state")
+		err = store.FlushLinks(st)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to write state")
 	})
 
-	for _, deal := range params.Deals {
-		// Check VerifiedClient allowed cap and deduct PieceSize from cap.
-		// Either the DealSize is within the available DataCap of the VerifiedClient
-		// or this message will fail. We do not allow a deal that is partially verified.
-		if deal.Proposal.VerifiedDeal {
-			resolvedClient, ok := resolvedAddrs[deal.Proposal.Client]
-			builtin.RequireParam(rt, ok, "could not get resolvedClient client address")
-
-			code := rt.Send(
-				builtin.VerifiedRegistryActorAddr,
-				builtin.MethodsVerifiedRegistry.UseBytes,
-				&verifreg.UseBytesParams{
-					Address:  resolvedClient,
-					DealSize: big.NewIntUnsigned(uint64(deal.Proposal.PieceSize)),
-				},
-				abi.NewTokenAmount(0),
-				&builtin.Discard{},
-			)
-			builtin.RequireSuccess(rt, code, "failed to add verified deal for client: %v", deal.Proposal.Client)
-		}
+	for i, id := range newDealIds {
+		emitDealEvent(rt, EventDealPublished, id, validDeals[i].Proposal.Client, validDeals[i].Proposal.Provider)
 	}
 
-	return &PublishStorageDealsReturn{IDs: newDealIds}
+	return &PublishStorageDealsReturn{
+		IDs:          newDealIds,
+		ProposalCIDs: newDealCids,
+		ValidDeals:   bitfield.NewFromSet(validInputs),
+	}
 }
 
 // Changed since v2:
@@ -310,8 +450,7 @@ func (a Actor) VerifyDealsForActivation(rt Runtime, params *VerifyDealsForActiva
 	minerAddr := rt.Caller()
 	currEpoch := rt.CurrEpoch()
 
-	var st State
-	rt.StateReadonly(&st)
+	st := ReadState(rt)
 	store := adt.AsStore(rt)
 
 	proposals, err := AsDealProposalArray(store, st.Proposals)
@@ -345,57 +484,288 @@ type ActivateDealsParams = market0.ActivateDealsParams
 
 // Verify that a given set of storage deals is valid for a sector currently being ProveCommitted,
 // update the market's internal state accordingly.
+// Each deal must end no later than the sector's expiration.
+// A single invalid deal fails the whole activation: the sector's deal weight and unsealed CID were
+// committed to with all of its deals, so it's not safe to activate the sector with only some of them.
 func (a Actor) ActivateDeals(rt Runtime, params *ActivateDealsParams) *abi.EmptyValue {
 	rt.ValidateImmediateCallerType(builtin.StorageMinerActorCodeID)
 	minerAddr := rt.Caller()
 	currEpoch := rt.CurrEpoch()
 
-	var st State
 	store := adt.AsStore(rt)
 
 	// Update deal dealStates.
-	rt.StateTransaction(&st, func() {
-		_, _, _, err := ValidateDealsForActivation(&st, store, params.DealIDs, minerAddr, params.SectorExpiry, currEpoch)
+	var activated []*DealProposal
+	WithState(rt, func(st *State) {
+		_, _, _, err := ValidateDealsForActivation(st, store, params.DealIDs, minerAddr, params.SectorExpiry, currEpoch)
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to validate dealProposals for activation")
 
 		msm, err := st.mutator(adt.AsStore(rt)).withDealStates(WritePermission).
-			withPendingProposals(ReadOnlyPermission).withDealProposals(ReadOnlyPermission).build()
+			withPendingProposals(ReadOnlyPermission).withDealProposals(ReadOnlyPermission).
+			withLockedTable(WritePermission).withCollateralReservations(WritePermission).build()
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load state")
 
-		for _, dealID := range params.DealIDs {
-			// This construction could be replaced with a single "update deal state" state method, possibly batched
-			// over all deal ids at once.
-			_, found, err := msm.dealStates.Get(dealID)
-			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get state for dealId %d", dealID)
-			if found {
-				rt.Abortf(exitcode.ErrIllegalArgument, "deal %d already included in another sector", dealID)
+		activated = activateDeals(rt, msm, params.DealIDs, currEpoch)
+
+		err = msm.commitState()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush state")
+	})
+
+	for i, proposal := range activated {
+		emitDealEvent(rt, EventDealActivated, params.DealIDs[i], proposal.Client, proposal.Provider)
+	}
+	return nil
+}
+
+// Sets the state of each deal to activated at the current epoch, returning the deals' proposals in order.
+// Each deal must be pending and not yet activated. Any collateral reserved against a deal is released.
+func activateDeals(rt Runtime, msm *marketStateMutation, dealIDs []abi.DealID, currEpoch abi.ChainEpoch) []*DealProposal {
+	activated := make([]*DealProposal, 0, len(dealIDs))
+	for _, dealID := range dealIDs {
+		// This construction could be replaced with a single "update deal state" state method, possibly batched
+		// over all deal ids at once.
+		_, found, err := msm.dealStates.Get(dealID)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get state for dealId %d", dealID)
+		if found {
+			rt.Abortf(exitcode.ErrIllegalArgument, "deal %d already included in another sector", dealID)
+		}
+
+		proposal, err := getDealProposal(msm.dealProposals, dealID)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get dealId %d", dealID)
+
+		propc, err := proposalCid(rt, proposal)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to calculate proposal CID")
+
+		has, err := msm.pendingDeals.Has(abi.CidKey(propc))
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get pending proposal %v", propc)
+
+		if !has {
+			rt.Abortf(exitcode.ErrIllegalState, "tried to activate deal that was not in the pending set (%s)", propc)
+		}
+
+		err = msm.dealStates.Set(dealID, &DealState{
+			SectorStartEpoch: currEpoch,
+			LastUpdatedEpoch: epochUndefined,
+			SlashEpoch:       epochUndefined,
+		})
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to set deal state %d", dealID)
+		msm.activeDeals++
+
+		// A deal activated after its start epoch, within the activation grace period, is paid only from activation.
+		// The storage fee locked for the epochs before activation is returned to the client.
+		if currEpoch > proposal.StartEpoch {
+			unpaid := big.Mul(big.NewInt(int64(currEpoch-proposal.StartEpoch)), proposal.StoragePricePerEpoch)
+			err = msm.unlockBalance(proposal.Client, unpaid, ClientStorageFee)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to unlock storage fee before activation of deal %d", dealID)
+		}
+
+		_, err = msm.releaseCollateral(proposal.Provider, dealID)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to release collateral reserved for deal %d", dealID)
+		activated = append(activated, proposal)
+	}
+	return activated
+}
+
+// Checks that deals are pending and not yet activated, as activateDeals requires.
+func validateDealsPending(rt Runtime, msm *marketStateMutation, dealIDs []abi.DealID) error {
+	for _, dealID := range dealIDs {
+		_, found, err := msm.dealStates.Get(dealID)
+		if err != nil {
+			return xerrors.Errorf("failed to get state for deal %d: %w", dealID, err)
+		}
+		if found {
+			return exitcode.ErrIllegalArgument.Wrapf("deal %d already included in another sector", dealID)
+		}
+
+		proposal, err := getDealProposal(msm.dealProposals, dealID)
+		if err != nil {
+			return xerrors.Errorf("failed to get deal %d: %w", dealID, err)
+		}
+		propc, err := proposalCid(rt, proposal)
+		if err != nil {
+			return xerrors.Errorf("failed to calculate proposal CID of deal %d: %w", dealID, err)
+		}
+		has, err := msm.pendingDeals.Has(abi.CidKey(propc))
+		if err != nil {
+			return xerrors.Errorf("failed to get pending proposal %v: %w", propc, err)
+		}
+		if !has {
+			return exitcode.ErrIllegalState.Wrapf("deal %d was not in the pending set (%s)", dealID, propc)
+		}
+	}
+	return nil
+}
+
+type BatchActivateDealsParams struct {
+	Sectors []SectorDeals
+}
+
+type BatchActivateDealsReturn struct {
+	Sectors []SectorDealActivation
+}
+
+type SectorDealActivation struct {
+	Activated          bool           // Whether the sector's deals were valid and have been activated.
+	DealSpace          uint64         // Total space in bytes of activated deals.
+	DealWeight         abi.DealWeight // Total space*time of activated deals.
+	VerifiedDealWeight abi.DealWeight // Total space*time of activated verified deals.
+	PieceCIDs          []cid.Cid      // Piece CIDs of the activated deals, in deal order.
+	// Pieces of the activated deals which are not verified deals, in deal order.
+	// Only these pieces may be claimed against verified clients' allocations of DataCap.
+	UnverifiedPieces []UnverifiedPiece
+}
+
+// The piece of an activated deal which is not a verified deal.
+type UnverifiedPiece struct {
+	Size       abi.PaddedPieceSize
+	PieceCID   cid.Cid
+	DealWeight abi.DealWeight // Space*time of the deal, included in its sector's DealWeight.
+}
+
+// Activates the deals for a number of sectors being ProveCommitted at once, as for ActivateDeals,
+// returning for each sector the weight and pieces of its deals so the miner need not request them separately.
+// A sector with an invalid deal is not activated, but does not prevent activation of the others in the batch.
+func (a Actor) BatchActivateDeals(rt Runtime, params *BatchActivateDealsParams) *BatchActivateDealsReturn {
+	rt.ValidateImmediateCallerType(builtin.StorageMinerActorCodeID)
+	minerAddr := rt.Caller()
+	currEpoch := rt.CurrEpoch()
+
+	var dealIDs []abi.DealID
+	var activated []*DealProposal
+	results := make([]SectorDealActivation, len(params.Sectors))
+	WithState(rt, func(st *State) {
+		msm, err := st.mutator(adt.AsStore(rt)).withDealStates(WritePermission).
+			withPendingProposals(ReadOnlyPermission).withDealProposals(ReadOnlyPermission).
+			withLockedTable(WritePermission).withCollateralReservations(WritePermission).build()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load state")
+
+		for i, sector := range params.Sectors {
+			dealWeight, verifiedWeight, dealSpace, err := validateAndComputeDealWeight(msm.dealProposals, sector.DealIDs, minerAddr, sector.SectorExpiry, currEpoch)
+			if err == nil {
+				// A deal included in an earlier sector of the batch is found already activated.
+				err = validateDealsPending(rt, msm, sector.DealIDs)
+			}
+			if err != nil {
+				rt.Log(rtt.INFO, "failed to activate deals for sector %d of batch: %s", i, err)
+				results[i] = SectorDealActivation{DealWeight: big.Zero(), VerifiedDealWeight: big.Zero()}
+				continue
+			}
+
+			proposals := activateDeals(rt, msm, sector.DealIDs, currEpoch)
+			pieceCIDs := make([]cid.Cid, len(proposals))
+			var unverifiedPieces []UnverifiedPiece
+			for j, proposal := range proposals {
+				pieceCIDs[j] = proposal.PieceCID
+				if !proposal.VerifiedDeal {
+					unverifiedPieces = append(unverifiedPieces, UnverifiedPiece{
+						Size:       proposal.PieceSize,
+						PieceCID:   proposal.PieceCID,
+						DealWeight: DealWeight(proposal, currEpoch),
+					})
+				}
 			}
 
-			proposal, err := getDealProposal(msm.dealProposals, dealID)
-			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get dealId %d", dealID)
+			results[i] = SectorDealActivation{
+				Activated:          true,
+				DealSpace:          dealSpace,
+				DealWeight:         dealWeight,
+				VerifiedDealWeight: verifiedWeight,
+				PieceCIDs:          pieceCIDs,
+				UnverifiedPieces:   unverifiedPieces,
+			}
+			dealIDs = append(dealIDs, sector.DealIDs...)
+			activated = append(activated, proposals...)
+		}
 
-			propc, err := proposal.Cid()
-			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to calculate proposal CID")
+		err = msm.commitState()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush state")
+	})
 
-			has, err := msm.pendingDeals.Has(abi.CidKey(propc))
-			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get pending proposal %v", propc)
+	for i, proposal := range activated {
+		emitDealEvent(rt, EventDealActivated, dealIDs[i], proposal.Client, proposal.Provider)
+	}
+	return &BatchActivateDealsReturn{Sectors: results}
+}
 
-			if !has {
-				rt.Abortf(exitcode.ErrIllegalState, "tried to activate deal that was not in the pending set (%s)", propc)
+type ReserveCollateralParams struct {
+	Reservations []CollateralReservation
+}
+
+type CollateralReservation struct {
+	DealID abi.DealID
+	Amount abi.TokenAmount
+}
+
+// Reserves amounts of the calling miner's escrow against deals it has pre-committed to sectors, so that the funds
+// cannot be withdrawn before the deals are activated.
+// Each deal must have been published with the caller as provider and not yet be activated, and may be reserved
+// against only once. A reservation is released when its deal is activated or times out, or by ReleaseCollateral.
+func (a Actor) ReserveCollateral(rt Runtime, params *ReserveCollateralParams) *abi.EmptyValue {
+	rt.ValidateImmediateCallerType(builtin.StorageMinerActorCodeID)
+	minerAddr := rt.Caller()
+
+	WithState(rt, func(st *State) {
+		msm, err := st.mutator(adt.AsStore(rt)).withDealProposals(ReadOnlyPermission).withDealStates(ReadOnlyPermission).
+			withEscrowTable(ReadOnlyPermission).withLockedTable(WritePermission).
+			withCollateralReservations(WritePermission).build()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load state")
+
+		for _, r := range params.Reservations {
+			proposal, err := getDealProposal(msm.dealProposals, r.DealID)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get deal %d", r.DealID)
+			if proposal.Provider != minerAddr {
+				rt.Abortf(exitcode.ErrForbidden, "caller %v is not the provider %v of deal %d", minerAddr, proposal.Provider, r.DealID)
 			}
 
-			err = msm.dealStates.Set(dealID, &DealState{
-				SectorStartEpoch: currEpoch,
-				LastUpdatedEpoch: epochUndefined,
-				SlashEpoch:       epochUndefined,
-			})
-			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to set deal state %d", dealID)
+			_, found, err := msm.dealStates.Get(r.DealID)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get state for deal %d", r.DealID)
+			if found {
+				rt.Abortf(exitcode.ErrIllegalArgument, "deal %d already activated", r.DealID)
+			}
+
+			err = msm.reserveCollateral(minerAddr, r.DealID, r.Amount)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to reserve collateral for deal %d", r.DealID)
 		}
 
 		err = msm.commitState()
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush state")
 	})
+	return nil
+}
 
+type ReleaseCollateralParams struct {
+	DealIDs []abi.DealID
+}
+
+// Releases the collateral reserved by the calling miner against deals, such as those of a pre-commitment that expired
+// without being proven. A deal that has no reservation, or no longer exists, is ignored.
+func (a Actor) ReleaseCollateral(rt Runtime, params *ReleaseCollateralParams) *abi.EmptyValue {
+	rt.ValidateImmediateCallerType(builtin.StorageMinerActorCodeID)
+	minerAddr := rt.Caller()
+
+	WithState(rt, func(st *State) {
+		msm, err := st.mutator(adt.AsStore(rt)).withDealProposals(ReadOnlyPermission).
+			withLockedTable(WritePermission).withCollateralReservations(WritePermission).build()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load state")
+
+		for _, dealID := range params.DealIDs {
+			// A deal's reservation is released when the deal times out and is deleted.
+			proposal, found, err := msm.dealProposals.Get(dealID)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get deal proposal %d", dealID)
+			if !found {
+				continue
+			}
+			if proposal.Provider != minerAddr {
+				rt.Abortf(exitcode.ErrForbidden, "caller %v is not the provider %v of deal %d", minerAddr, proposal.Provider, dealID)
+			}
+
+			_, err = msm.releaseCollateral(minerAddr, dealID)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to release collateral reserved for deal %d", dealID)
+		}
+
+		err = msm.commitState()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush state")
+	})
 	return nil
 }
 
@@ -408,12 +778,12 @@ type ComputeDataCommitmentParams = market0.ComputeDataCommitmentParams
 func (a Actor) ComputeDataCommitment(rt Runtime, params *ComputeDataCommitmentParams) *cbg.CborCid {
 	rt.ValidateImmediateCallerType(builtin.StorageMinerActorCodeID)
 
-	var st State
-	rt.StateReadonly(&st)
+	st := ReadState(rt)
 	proposals, err := AsDealProposalArray(adt.AsStore(rt), st.Proposals)
 	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deal dealProposals")
 
 	pieces := make([]abi.PieceInfo, 0)
+	pieceSizes := make([]abi.PaddedPieceSize, 0, len(params.DealIDs))
 	for _, dealID := range params.DealIDs {
 		deal, err := getDealProposal(proposals, dealID)
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get dealId %d", dealID)
@@ -422,6 +792,17 @@ func (a Actor) ComputeDataCommitment(rt Runtime, params *ComputeDataCommitmentPa
 			PieceCID: deal.PieceCID,
 			Size:     deal.PieceSize,
 		})
+		pieceSizes = append(pieceSizes, deal.PieceSize)
+	}
+
+	// The deals' pieces must be able to make up the sector's data, else no commitment computed from them
+	// could match the sector.
+	sectorSize, err := params.SectorType.SectorSize()
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "invalid sector type %d", params.SectorType)
+	fits, err := sizes.FitsInSector(sectorSize, pieceSizes)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "invalid deal piece sizes")
+	if !fits {
+		rt.Abortf(exitcode.ErrIllegalArgument, "deal pieces %v do not fit in sector of size %d", pieceSizes, sectorSize)
 	}
 
 	commd, err := rt.ComputeUnsealedSectorCID(params.SectorType, pieces)
@@ -445,8 +826,9 @@ func (a Actor) OnMinerSectorsTerminate(rt Runtime, params *OnMinerSectorsTermina
 	rt.ValidateImmediateCallerType(builtin.StorageMinerActorCodeID)
 	minerAddr := rt.Caller()
 
-	var st State
-	rt.StateTransaction(&st, func() {
+	var terminatedIDs []abi.DealID
+	var terminated []*DealProposal
+	WithState(rt, func(st *State) {
 		msm, err := st.mutator(adt.AsStore(rt)).withDealStates(WritePermission).
 			withDealProposals(ReadOnlyPermission).build()
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deal state")
@@ -484,27 +866,123 @@ func (a Actor) OnMinerSectorsTerminate(rt Runtime, params *OnMinerSectorsTermina
 
 			err = msm.dealStates.Set(dealID, state)
 			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to set deal state %v", dealID)
+			terminatedIDs = append(terminatedIDs, dealID)
+			terminated = append(terminated, deal)
 		}
 
 		err = msm.commitState()
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush state")
 	})
+
+	for i, deal := range terminated {
+		emitDealEvent(rt, EventDealTerminated, terminatedIDs[i], deal.Client, deal.Provider)
+	}
 	return nil
 }
 
+type SettleDealPaymentsParams struct {
+	DealIDs []abi.DealID
+}
+
+type SettleDealPaymentsReturn struct {
+	// Deals for which payment was settled up to the current epoch, in the order requested.
+	Settled []abi.DealID
+	// Total payment transferred from clients to providers.
+	TotalPayment abi.TokenAmount
+}
+
+// Transfers payment accrued by active deals since they were last updated, without waiting for the deals'
+// scheduled cron updates. Either party to a deal may settle it: the client, or the provider's owner or worker.
+// Deals that are not yet active, have not yet started, or have been slashed are skipped; slashed deals are
+// settled by cron along with their penalties. Settling does not change when cron next processes a deal,
+// and a deal is never paid twice for the same epoch.
+func (a Actor) SettleDealPayments(rt Runtime, params *SettleDealPaymentsParams) *SettleDealPaymentsReturn {
+	rt.ValidateImmediateCallerType(builtin.CallerTypesSignable...)
+	caller := rt.Caller()
+	currEpoch := rt.CurrEpoch()
+	store := adt.AsStore(rt)
+
+	// Check the caller is party to every deal before settling any of them.
+	st := ReadState(rt)
+	proposals, err := AsDealProposalArray(store, st.Proposals)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deal proposals")
+
+	authorizedProviders := make(map[addr.Address]bool)
+	for _, dealID := range params.DealIDs {
+		deal, err := getDealProposal(proposals, dealID)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get deal %d", dealID)
+		if deal.Client == caller {
+			continue
+		}
+
+		authorized, checked := authorizedProviders[deal.Provider]
+		if !checked {
+			ownerAddr, workerAddr, _ := builtin.RequestMinerControlAddrs(rt, deal.Provider)
+			authorized = caller == ownerAddr || caller == workerAddr
+			authorizedProviders[deal.Provider] = authorized
+		}
+		if !authorized {
+			rt.Abortf(exitcode.ErrForbidden, "caller %v is not a party to deal %d", caller, dealID)
+		}
+	}
+
+	ret := SettleDealPaymentsReturn{TotalPayment: big.Zero()}
+	WithState(rt, func(st *State) {
+		msm, err := st.mutator(store).withDealStates(WritePermission).withEscrowTable(WritePermission).
+			withLockedTable(WritePermission).withDealProposals(ReadOnlyPermission).
+			withPendingProposals(WritePermission).build()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load state")
+
+		for _, dealID := range params.DealIDs {
+			deal, err := getDealProposal(msm.dealProposals, dealID)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get deal %d", dealID)
+
+			state, found, err := msm.dealStates.Get(dealID)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get deal state %d", dealID)
+			if !found || state.SlashEpoch != epochUndefined || currEpoch <= deal.StartEpoch {
+				continue
+			}
+
+			// The first update to a deal removes it from the pending set, as its first cron update would.
+			if state.LastUpdatedEpoch == epochUndefined {
+				dcid, err := proposalCid(rt, deal)
+				builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to calculate CID for proposal %v", dealID)
+				err = msm.pendingDeals.Delete(abi.CidKey(dcid))
+				builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to delete pending proposal %v", dcid)
+			}
+
+			payment := msm.settleDealPayment(rt, state, deal, currEpoch)
+			ret.TotalPayment = big.Add(ret.TotalPayment, payment)
+
+			state.LastUpdatedEpoch = currEpoch
+			err = msm.dealStates.Set(dealID, state)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to set deal state %d", dealID)
+			ret.Settled = append(ret.Settled, dealID)
+		}
+
+		err = msm.commitState()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush state")
+	})
+	return &ret
+}
+
 func (a Actor) CronTick(rt Runtime, _ *abi.EmptyValue) *abi.EmptyValue {
 	rt.ValidateImmediateCallerIs(builtin.CronActorAddr)
 	amountSlashed := big.Zero()
 
 	var timedOutVerifiedDeals []*DealProposal
+	var timedOutIDs []abi.DealID
+	var timedOutDeals []*DealProposal
+	var timedOutSlashes []abi.TokenAmount
+	var terminations []dealTermination
 
-	var st State
-	rt.StateTransaction(&st, func() {
+	WithState(rt, func(st *State) {
 		updatesNeeded := make(map[abi.ChainEpoch][]abi.DealID)
 
 		msm, err := st.mutator(adt.AsStore(rt)).withDealStates(WritePermission).
 			withLockedTable(WritePermission).withEscrowTable(WritePermission).withDealsByEpoch(WritePermission).
-			withDealProposals(WritePermission).withPendingProposals(WritePermission).build()
+			withDealProposals(WritePermission).withPendingProposals(WritePermission).
+			withCollateralReservations(WritePermission).build()
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load state")
 
 		for i := st.LastCron + 1; i <= rt.CurrEpoch(); i++ {
@@ -512,25 +990,37 @@ func (a Actor) CronTick(rt Runtime, _ *abi.EmptyValue) *abi.EmptyValue {
 				deal, err := getDealProposal(msm.dealProposals, dealID)
 				builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get dealId %d", dealID)
 
-				dcid, err := deal.Cid()
+				dcid, err := proposalCid(rt, deal)
 				builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to calculate CID for proposal %v", dealID)
 
 				state, found, err := msm.dealStates.Get(dealID)
 				builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get deal state")
 
-				// deal has been published but not activated yet -> terminate it as it has timed out
+				// deal has been published but not activated yet -> terminate it if it has timed out
 				if !found {
 					// Not yet appeared in proven sector; check for timeout.
 					builtin.RequireState(rt, rt.CurrEpoch() >= deal.StartEpoch, "deal %d processed before start epoch %d",
 						dealID, deal.StartEpoch)
 
+					// The deal may still be activated within the grace period; check again once it ends.
+					if activationDeadline := deal.StartEpoch + DealActivationGracePeriod; rt.CurrEpoch() < activationDeadline {
+						updatesNeeded[activationDeadline] = append(updatesNeeded[activationDeadline], dealID)
+						return nil
+					}
+
 					slashed := msm.processDealInitTimedOut(rt, deal)
+					_, err = msm.releaseCollateral(deal.Provider, dealID)
+					builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to release collateral reserved for deal %d", dealID)
 					if !slashed.IsZero() {
 						amountSlashed = big.Add(amountSlashed, slashed)
 					}
 					if deal.VerifiedDeal {
 						timedOutVerifiedDeals = append(timedOutVerifiedDeals, deal)
 					}
+					timedOutIDs = append(timedOutIDs, dealID)
+					timedOutDeals = append(timedOutDeals, deal)
+					timedOutSlashes = append(timedOutSlashes, slashed)
+					terminations = append(terminations, dealTermination{deal.Client, dealID, DealTerminationTimedOut})
 
 					// we should not attempt to delete the DealState because it does NOT exist
 					if err := deleteDealProposalAndState(dealID, msm.dealStates, msm.dealProposals, true, false); err != nil {
@@ -557,6 +1047,10 @@ func (a Actor) CronTick(rt Runtime, _ *abi.EmptyValue) *abi.EmptyValue {
 					amountSlashed = big.Add(amountSlashed, slashAmount)
 					err := deleteDealProposalAndState(dealID, msm.dealStates, msm.dealProposals, true, true)
 					builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to delete deal proposal and states")
+					msm.activeDeals--
+					if state.SlashEpoch != epochUndefined {
+						terminations = append(terminations, dealTermination{deal.Client, dealID, DealTerminationSlashed})
+					}
 				} else {
 					builtin.RequireState(rt, nextEpoch > rt.CurrEpoch(), "continuing deal %d next epoch %d should be in future", dealID, nextEpoch)
 					builtin.RequireState(rt, slashAmount.IsZero(), "continuing deal %d should not be slashed", dealID)
@@ -598,6 +1092,10 @@ func (a Actor) CronTick(rt Runtime, _ *abi.EmptyValue) *abi.EmptyValue {
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush state")
 	})
 
+	for i, deal := range timedOutDeals {
+		emitDealTimedOutEvent(rt, timedOutIDs[i], deal, timedOutSlashes[i])
+	}
+
 	for _, d := range timedOutVerifiedDeals {
 		code := rt.Send(
 			builtin.VerifiedRegistryActorAddr,
@@ -616,14 +1114,56 @@ func (a Actor) CronTick(rt Runtime, _ *abi.EmptyValue) *abi.EmptyValue {
 		}
 	}
 
-	if !amountSlashed.IsZero() {
-		e := rt.Send(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, amountSlashed, &builtin.Discard{})
-		builtin.RequireSuccess(rt, e, "expected send to burnt funds actor to succeed")
+	for _, t := range terminations {
+		notifyDealTermination(rt, t)
 	}
 
+	e := builtin.BurnWithReason(rt, amountSlashed, builtin.BurnReasonDealSlash)
+	builtin.RequireSuccess(rt, e, "expected send to burnt funds actor to succeed")
+
 	return nil
 }
 
+// A deal that terminated before completing its term, of which the client is to be notified.
+type dealTermination struct {
+	client addr.Address
+	dealID abi.DealID
+	reason DealTerminationReason
+}
+
+// Notifies a deal's client of the deal's early termination through the client's universal receiver hook.
+// Only programmable clients are notified: an account has no use for the notification.
+// The deal is settled regardless of the notification, so a failure to deliver it is logged and otherwise ignored.
+func notifyDealTermination(rt Runtime, t dealTermination) {
+	code, found := rt.GetActorCodeCID(t.client)
+	if !found || code.Equals(builtin.AccountActorCodeID) {
+		return
+	}
+	method, ok := builtin.UniversalReceiverHookMethod(code)
+	if !ok {
+		return
+	}
+
+	var payload bytes.Buffer
+	err := (&DealTerminationNotification{DealID: t.dealID, Reason: t.reason}).MarshalCBOR(&payload)
+	builtin.RequireNoErr(rt, err, exitcode.ErrSerialization, "failed to serialize termination notification for deal %d", t.dealID)
+
+	sendCode := rt.Send(
+		t.client,
+		method,
+		&builtin.UniversalReceiverParams{
+			Type:    builtin.UniversalReceiverTypeDealTermination,
+			Payload: payload.Bytes(),
+		},
+		abi.NewTokenAmount(0),
+		&builtin.Discard{},
+	)
+	if !sendCode.IsSuccess() {
+		rt.Log(rtt.ERROR, "failed to notify client %v of termination of deal %d, reason %d, got code %v",
+			t.client, t.dealID, t.reason, sendCode)
+	}
+}
+
 func genRandNextEpoch(currEpoch abi.ChainEpoch, deal *DealProposal, rbF func(crypto.DomainSeparationTag, abi.ChainEpoch, []byte) abi.Randomness) (abi.ChainEpoch, error) {
 	buf := bytes.Buffer{}
 	if err := deal.MarshalCBOR(&buf); err != nil {
@@ -703,7 +1243,7 @@ func validateAndComputeDealWeight(proposals *DealArray, dealIDs []abi.DealID, mi
 
 		// Compute deal weight
 		totalDealSpace += uint64(proposal.PieceSize)
-		dealSpaceTime := DealWeight(proposal)
+		dealSpaceTime := DealWeight(proposal, sectorActivation)
 		if proposal.VerifiedDeal {
 			totalVerifiedSpaceTime = big.Add(totalVerifiedSpaceTime, dealSpaceTime)
 		} else {
@@ -717,66 +1257,36 @@ func validateDealCanActivate(proposal *DealProposal, minerAddr addr.Address, sec
 	if proposal.Provider != minerAddr {
 		return exitcode.ErrForbidden.Wrapf("proposal has provider %v, must be %v", proposal.Provider, minerAddr)
 	}
-	if sectorActivation > proposal.StartEpoch {
-		return exitcode.ErrIllegalArgument.Wrapf("proposal start epoch %d has already elapsed at %d", proposal.StartEpoch, sectorActivation)
+	return ValidateDealTermWithinSector(proposal.StartEpoch, proposal.EndEpoch, sectorActivation, sectorExpiration)
+}
+
+// Checks that a deal's term lies within the lifetime of the sector that is to hold it:
+// the sector must be activated no later than the end of the deal's activation grace period,
+// and the sector must last at least until the deal ends.
+// A deal that would outlive its sector fails with ErrDealOutlivesSector.
+// This depends only on its arguments, so a miner may check prospective deals before committing a sector.
+func ValidateDealTermWithinSector(dealStart, dealEnd, sectorActivation, sectorExpiration abi.ChainEpoch) error {
+	if sectorActivation > dealStart+DealActivationGracePeriod {
+		return exitcode.ErrIllegalArgument.Wrapf("proposal start epoch %d and activation grace period %d have already elapsed at %d",
+			dealStart, DealActivationGracePeriod, sectorActivation)
 	}
-	if proposal.EndEpoch > sectorExpiration {
-		return exitcode.ErrIllegalArgument.Wrapf("proposal expiration %d exceeds sector expiration %d", proposal.EndEpoch, sectorExpiration)
+	if dealEnd > sectorExpiration {
+		return ErrDealOutlivesSector.Wrapf("proposal expiration %d exceeds sector expiration %d", dealEnd, sectorExpiration)
 	}
 	return nil
 }
 
-func validateDeal(rt Runtime, deal ClientDealProposal, networkRawPower, networkQAPower, baselinePower abi.StoragePower) {
+func validateDeal(rt Runtime, deal ClientDealProposal, networkRawPower, networkQAPower, baselinePower abi.StoragePower) error {
 	if err := dealProposalIsInternallyValid(rt, deal); err != nil {
-		rt.Abortf(exitcode.ErrIllegalArgument, "Invalid deal proposal: %s", err)
-	}
-
-	proposal := deal.Proposal
-
-	if len(proposal.Label) > DealMaxLabelSize {
-		rt.Abortf(exitcode.ErrIllegalArgument, "deal label can be at most %d bytes, is %d", DealMaxLabelSize, len(proposal.Label))
-	}
-
-	if err := proposal.PieceSize.Validate(); err != nil {
-		rt.Abortf(exitcode.ErrIllegalArgument, "proposal piece size is invalid: %v", err)
-	}
-
-	if !proposal.PieceCID.Defined() {
-		rt.Abortf(exitcode.ErrIllegalArgument, "proposal PieceCID undefined")
+		return xerrors.Errorf("Invalid deal proposal: %w", err)
 	}
 
-	if proposal.PieceCID.Prefix() != PieceCIDPrefix {
-		rt.Abortf(exitcode.ErrIllegalArgument, "proposal PieceCID had wrong prefix")
-	}
-
-	if proposal.EndEpoch <= proposal.StartEpoch {
-		rt.Abortf(exitcode.ErrIllegalArgument, "proposal end before proposal start")
-	}
-
-	if rt.CurrEpoch() > proposal.StartEpoch {
-		rt.Abortf(exitcode.ErrIllegalArgument, "Deal start epoch has already elapsed.")
-	}
-
-	minDuration, maxDuration := DealDurationBounds(proposal.PieceSize)
-	if proposal.Duration() < minDuration || proposal.Duration() > maxDuration {
-		rt.Abortf(exitcode.ErrIllegalArgument, "Deal duration out of bounds.")
-	}
-
-	minPrice, maxPrice := DealPricePerEpochBounds(proposal.PieceSize, proposal.Duration())
-	if proposal.StoragePricePerEpoch.LessThan(minPrice) || proposal.StoragePricePerEpoch.GreaterThan(maxPrice) {
-		rt.Abortf(exitcode.ErrIllegalArgument, "Storage price out of bounds.")
-	}
-
-	minProviderCollateral, maxProviderCollateral := DealProviderCollateralBounds(proposal.PieceSize, proposal.VerifiedDeal,
-		networkRawPower, networkQAPower, baselinePower, rt.TotalFilCircSupply())
-	if proposal.ProviderCollateral.LessThan(minProviderCollateral) || proposal.ProviderCollateral.GreaterThan(maxProviderCollateral) {
-		rt.Abortf(exitcode.ErrIllegalArgument, "Provider collateral out of bounds.")
-	}
-
-	minClientCollateral, maxClientCollateral := DealClientCollateralBounds(proposal.PieceSize, proposal.Duration())
-	if proposal.ClientCollateral.LessThan(minClientCollateral) || proposal.ClientCollateral.GreaterThan(maxClientCollateral) {
-		rt.Abortf(exitcode.ErrIllegalArgument, "Client collateral out of bounds.")
-	}
+	return ValidateProposal(&deal.Proposal, rt.CurrEpoch(), ProposalPolicy{
+		NetworkRawPower:   networkRawPower,
+		NetworkQAPower:    networkQAPower,
+		BaselinePower:     baselinePower,
+		CirculatingSupply: rt.TotalFilCircSupply(),
+	})
 }
 
 //
@@ -806,6 +1316,15 @@ func escrowAddress(rt Runtime, address addr.Address) (nominal addr.Address, reci
 	return nominal, nominal, []addr.Address{nominal}
 }
 
+// Computes the CID of a deal proposal, which keys the proposal in the pending proposals set.
+func proposalCid(rt Runtime, proposal *DealProposal) (cid.Cid, error) {
+	buf := new(bytes.Buffer)
+	if err := proposal.MarshalCBOR(buf); err != nil {
+		return cid.Undef, err
+	}
+	return rt.ComputeCID(cid.DagCBOR, buf.Bytes())
+}
+
 func getDealProposal(proposals *DealArray, dealID abi.DealID) (*DealProposal, error) {
 	proposal, found, err := proposals.Get(dealID)
 	if err != nil {
@@ -8,6 +8,26 @@ import (
 	"golang.org/x/xerrors"
 )
 
+// Deposits funds into an address's escrow.
+func (m *marketStateMutation) depositEscrow(addr addr.Address, amount abi.TokenAmount) error {
+	if err := m.escrowTable.Add(addr, amount); err != nil {
+		return xerrors.Errorf("add to escrow: %w", err)
+	}
+	m.totalEscrow = big.Add(m.totalEscrow, amount)
+	return nil
+}
+
+// Withdraws up to an amount from an address's escrow, leaving at least a minimum balance.
+// Returns the amount withdrawn.
+func (m *marketStateMutation) withdrawEscrow(addr addr.Address, amount, minBalance abi.TokenAmount) (abi.TokenAmount, error) {
+	withdrawn, err := m.escrowTable.SubtractWithMinimum(addr, amount, minBalance)
+	if err != nil {
+		return big.Zero(), xerrors.Errorf("subtract from escrow: %w", err)
+	}
+	m.totalEscrow = big.Sub(m.totalEscrow, withdrawn)
+	return withdrawn, nil
+}
+
 func (m *marketStateMutation) lockClientAndProviderBalances(proposal *DealProposal) error {
 	if err := m.maybeLockBalance(proposal.Client, proposal.ClientBalanceRequirement()); err != nil {
 		return xerrors.Errorf("failed to lock client funds: %w", err)
@@ -39,6 +59,8 @@ func (m *marketStateMutation) unlockBalance(addr addr.Address, amount abi.TokenA
 		m.totalClientStorageFee = big.Sub(m.totalClientStorageFee, amount)
 	case ProviderCollateral:
 		m.totalProviderLockedCollateral = big.Sub(m.totalProviderLockedCollateral, amount)
+	case ProviderReservedCollateral:
+		m.totalProviderReservedCollateral = big.Sub(m.totalProviderReservedCollateral, amount)
 	}
 
 	return nil
@@ -69,6 +91,7 @@ func (m *marketStateMutation) slashBalance(addr addr.Address, amount abi.TokenAm
 	if err := m.escrowTable.MustSubtract(addr, amount); err != nil {
 		return xerrors.Errorf("subtract from escrow: %v", err)
 	}
+	m.totalEscrow = big.Sub(m.totalEscrow, amount)
 
 	return m.unlockBalance(addr, amount, reason)
 }
@@ -98,3 +121,57 @@ func (m *marketStateMutation) maybeLockBalance(addr addr.Address, amount abi.Tok
 	}
 	return nil
 }
+
+// Returns whether the escrow balance of addr covers its currently locked balance plus amount.
+func (m *marketStateMutation) balanceCovered(addr addr.Address, amount abi.TokenAmount) (bool, error) {
+	prevLocked, err := m.lockedTable.Get(addr)
+	if err != nil {
+		return false, xerrors.Errorf("failed to get locked balance: %w", err)
+	}
+	escrowBalance, err := m.escrowTable.Get(addr)
+	if err != nil {
+		return false, xerrors.Errorf("failed to get escrow balance: %w", err)
+	}
+	return big.Add(prevLocked, amount).LessThanEqual(escrowBalance), nil
+}
+
+// Locks an amount of a provider's escrow as a reservation against a deal.
+// A deal may have only one reservation.
+func (m *marketStateMutation) reserveCollateral(provider addr.Address, dealID abi.DealID, amount abi.TokenAmount) error {
+	if amount.LessThanEqual(big.Zero()) {
+		return exitcode.ErrIllegalArgument.Wrapf("non-positive reservation %v for deal %d", amount, dealID)
+	}
+	found, err := m.reservations.Has(abi.UIntKey(uint64(dealID)))
+	if err != nil {
+		return xerrors.Errorf("failed to check reservation for deal %d: %w", dealID, err)
+	}
+	if found {
+		return exitcode.ErrIllegalArgument.Wrapf("collateral already reserved for deal %d", dealID)
+	}
+	if err := m.maybeLockBalance(provider, amount); err != nil {
+		return xerrors.Errorf("failed to lock reservation for deal %d: %w", dealID, err)
+	}
+	m.totalProviderReservedCollateral = big.Add(m.totalProviderReservedCollateral, amount)
+
+	if err := m.reservations.Put(abi.UIntKey(uint64(dealID)), &amount); err != nil {
+		return xerrors.Errorf("failed to put reservation for deal %d: %w", dealID, err)
+	}
+	return nil
+}
+
+// Removes the reservation against a deal, if any, unlocking the reserved amount of the provider's escrow.
+// Returns the amount released, which is zero if the deal had no reservation.
+func (m *marketStateMutation) releaseCollateral(provider addr.Address, dealID abi.DealID) (abi.TokenAmount, error) {
+	var amount abi.TokenAmount
+	found, err := m.reservations.Pop(abi.UIntKey(uint64(dealID)), &amount)
+	if err != nil {
+		return big.Zero(), xerrors.Errorf("failed to remove reservation for deal %d: %w", dealID, err)
+	}
+	if !found {
+		return big.Zero(), nil
+	}
+	if err := m.unlockBalance(provider, amount, ProviderReservedCollateral); err != nil {
+		return big.Zero(), xerrors.Errorf("failed to unlock reservation for deal %d: %w", dealID, err)
+	}
+	return amount, nil
+}
@@ -28,12 +28,20 @@ const (
 	ClientCollateral BalanceLockingReason = iota
 	ClientStorageFee
 	ProviderCollateral
+	ProviderReservedCollateral
 )
 
 // Bitwidth of AMTs determined empirically from mutation patterns and projections of mainnet data.
 const ProposalsAmtBitwidth = 5
 const StatesAmtBitwidth = 6
 
+// Bitwidths of HAMTs holding pending proposals and deal ops by epoch (both the outer map and each epoch's set).
+const PendingProposalsHamtBitwidth = builtin.DefaultHamtBitwidth
+const DealOpsByEpochHamtBitwidth = builtin.DefaultHamtBitwidth
+
+// Bitwidth of the HAMT holding collateral reservations by deal ID.
+const CollateralReservationsHamtBitwidth = builtin.DefaultHamtBitwidth
+
 type State struct {
 	Proposals cid.Cid // AMT[DealID]DealProposal
 	States    cid.Cid // AMT[DealID]DealState
@@ -62,6 +70,22 @@ type State struct {
 	TotalProviderLockedCollateral abi.TokenAmount
 	// Total storage fee that is locked in escrow -> unlocked when payments are made
 	TotalClientStorageFee abi.TokenAmount
+
+	// Cumulative amounts added to the escrow of each address by other parties, indexed by recipient then payer.
+	EscrowContributions cid.Cid // HAMT[addr]BalanceTable
+
+	// Total amount held in escrow: the sum of the escrow table, maintained as it changes.
+	TotalEscrow abi.TokenAmount
+	// Number of deals activated in a sector whose state is not yet removed.
+	// A terminated deal is counted until cron processes its termination.
+	ActiveDeals uint64
+
+	// Provider escrow reserved by a miner against a deal it has pre-committed to a sector, indexed by deal ID.
+	// A reservation is locked so that it cannot be withdrawn, and is released when the deal is activated or times out,
+	// or when the miner releases it after the pre-commitment expires.
+	CollateralReservations cid.Cid // HAMT[DealID]TokenAmount
+	// Total provider escrow that is reserved -> unlocked when a reservation is released
+	TotalProviderReservedCollateral abi.TokenAmount
 }
 
 func ConstructState(store adt.Store) (*State, error) {
@@ -74,11 +98,11 @@ func ConstructState(store adt.Store) (*State, error) {
 		return nil, xerrors.Errorf("failed to create empty states array: %w", err)
 	}
 
-	emptyPendingProposalsMapCid, err := adt.StoreEmptyMap(store, builtin.DefaultHamtBitwidth)
+	emptyPendingProposalsMapCid, err := adt.StoreEmptyMap(store, PendingProposalsHamtBitwidth)
 	if err != nil {
 		return nil, xerrors.Errorf("failed to create empty map: %w", err)
 	}
-	emptyDealOpsHamtCid, err := StoreEmptySetMultimap(store, builtin.DefaultHamtBitwidth)
+	emptyDealOpsHamtCid, err := StoreEmptySetMultimap(store, DealOpsByEpochHamtBitwidth)
 	if err != nil {
 		return nil, xerrors.Errorf("failed to create empty multiset: %w", err)
 	}
@@ -86,6 +110,14 @@ func ConstructState(store adt.Store) (*State, error) {
 	if err != nil {
 		return nil, xerrors.Errorf("failed to create empty balance table: %w", err)
 	}
+	emptyContributionsMapCid, err := adt.StoreEmptyMap(store, EscrowContributionsHamtBitwidth)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create empty contributions map: %w", err)
+	}
+	emptyReservationsMapCid, err := adt.StoreEmptyMap(store, CollateralReservationsHamtBitwidth)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create empty reservations map: %w", err)
+	}
 
 	return &State{
 		Proposals:        emptyProposalsArrayCid,
@@ -100,9 +132,59 @@ func ConstructState(store adt.Store) (*State, error) {
 		TotalClientLockedCollateral:   abi.NewTokenAmount(0),
 		TotalProviderLockedCollateral: abi.NewTokenAmount(0),
 		TotalClientStorageFee:         abi.NewTokenAmount(0),
+		TotalEscrow:                   abi.NewTokenAmount(0),
+
+		EscrowContributions: emptyContributionsMapCid,
+
+		CollateralReservations:          emptyReservationsMapCid,
+		TotalProviderReservedCollateral: abi.NewTokenAmount(0),
 	}, nil
 }
 
+// Aggregate metrics of the market, maintained in state as deals and balances change.
+type MarketStats struct {
+	TotalEscrow abi.TokenAmount // Total held in escrow, both locked and unlocked.
+	TotalLocked abi.TokenAmount // Total of the escrow that is locked, the sum of the four totals below.
+	ActiveDeals uint64          // Deals activated in a sector and not yet expired, or terminated and processed by cron.
+
+	TotalClientLockedCollateral     abi.TokenAmount
+	TotalProviderLockedCollateral   abi.TokenAmount
+	TotalClientStorageFee           abi.TokenAmount
+	TotalProviderReservedCollateral abi.TokenAmount
+}
+
+// Returns the market's aggregate metrics, without traversing its deals or balance tables.
+func (st *State) Stats() *MarketStats {
+	return &MarketStats{
+		TotalEscrow:                     st.TotalEscrow,
+		TotalLocked:                     big.Sum(st.TotalClientLockedCollateral, st.TotalProviderLockedCollateral, st.TotalClientStorageFee, st.TotalProviderReservedCollateral),
+		ActiveDeals:                     st.ActiveDeals,
+		TotalClientLockedCollateral:     st.TotalClientLockedCollateral,
+		TotalProviderLockedCollateral:   st.TotalProviderLockedCollateral,
+		TotalClientStorageFee:           st.TotalClientStorageFee,
+		TotalProviderReservedCollateral: st.TotalProviderReservedCollateral,
+	}
+}
+
+// Loads a mutable copy of the market state and passes it to f, committing the state when f returns.
+// This is a typed wrapper around rt.StateTransaction, so the same restrictions apply:
+// f must not perform side effects (such as sends) nor open a nested transaction, and the
+// state object must not be retained or modified after f returns.
+func WithState(rt Runtime, f func(st *State)) {
+	var st State
+	rt.StateTransaction(&st, func() {
+		f(&st)
+	})
+}
+
+// Loads a read-only copy of the market state.
+// Any modification to the returned state will cause the invocation to abort.
+func ReadState(rt Runtime) *State {
+	var st State
+	rt.StateReadonly(&st)
+	return &st
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // Deal state operations
 ////////////////////////////////////////////////////////////////////////////////
@@ -130,10 +212,7 @@ func (m *marketStateMutation) updatePendingDealState(rt Runtime, state *DealStat
 		paymentEndEpoch = epoch
 	}
 
-	paymentStartEpoch := deal.StartEpoch
-	if everUpdated && state.LastUpdatedEpoch > paymentStartEpoch {
-		paymentStartEpoch = state.LastUpdatedEpoch
-	}
+	paymentStartEpoch := dealPaymentStartEpoch(deal, state)
 
 	numEpochsElapsed := paymentEndEpoch - paymentStartEpoch
 
@@ -181,6 +260,40 @@ func (m *marketStateMutation) updatePendingDealState(rt Runtime, state *DealStat
 	return amountSlashed, nextEpoch, false
 }
 
+// Returns the first epoch for which an active deal's payment is outstanding: the latest of its start,
+// its activation and its last update.
+func dealPaymentStartEpoch(deal *DealProposal, state *DealState) abi.ChainEpoch {
+	start := deal.StartEpoch
+	if state.SectorStartEpoch > start {
+		start = state.SectorStartEpoch
+	}
+	if state.LastUpdatedEpoch != epochUndefined && state.LastUpdatedEpoch > start {
+		start = state.LastUpdatedEpoch
+	}
+	return start
+}
+
+// Transfers the payment for an active, unslashed deal from the latest of its start, activation and last update,
+// up to the earlier of the given epoch and its end. The caller is responsible for recording the deal's update epoch.
+func (m *marketStateMutation) settleDealPayment(rt Runtime, state *DealState, deal *DealProposal, epoch abi.ChainEpoch) abi.TokenAmount {
+	builtin.RequireState(rt, state.SlashEpoch == epochUndefined, "cannot settle slashed deal")
+
+	paymentStartEpoch := dealPaymentStartEpoch(deal, state)
+	paymentEndEpoch := deal.EndEpoch
+	if epoch < paymentEndEpoch {
+		paymentEndEpoch = epoch
+	}
+	if paymentEndEpoch <= paymentStartEpoch {
+		return big.Zero()
+	}
+
+	payment := big.Mul(big.NewInt(int64(paymentEndEpoch-paymentStartEpoch)), deal.StoragePricePerEpoch)
+	err := m.transferBalance(deal.Client, deal.Provider, payment)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to transfer %v from %v to %v",
+		payment, deal.Client, deal.Provider)
+	return payment
+}
+
 // Deal start deadline elapsed without appearing in a proven sector.
 // Slash a portion of provider's collateral, and unlock remaining collaterals
 // for both provider and client.
@@ -282,9 +395,11 @@ type marketStateMutation struct {
 
 	statePermit MarketStateMutationPermission
 	dealStates  *DealMetaArray
+	activeDeals uint64
 
 	escrowPermit MarketStateMutationPermission
 	escrowTable  *adt.BalanceTable
+	totalEscrow  abi.TokenAmount
 
 	pendingPermit MarketStateMutationPermission
 	pendingDeals  *adt.Set
@@ -292,11 +407,18 @@ type marketStateMutation struct {
 	dpePermit    MarketStateMutationPermission
 	dealsByEpoch *SetMultimap
 
-	lockedPermit                  MarketStateMutationPermission
-	lockedTable                   *adt.BalanceTable
-	totalClientLockedCollateral   abi.TokenAmount
-	totalProviderLockedCollateral abi.TokenAmount
-	totalClientStorageFee         abi.TokenAmount
+	contributionsPermit MarketStateMutationPermission
+	contributions       *EscrowContributions
+
+	reservationsPermit MarketStateMutationPermission
+	reservations       *adt.Map
+
+	lockedPermit                    MarketStateMutationPermission
+	lockedTable                     *adt.BalanceTable
+	totalClientLockedCollateral     abi.TokenAmount
+	totalProviderLockedCollateral   abi.TokenAmount
+	totalClientStorageFee           abi.TokenAmount
+	totalProviderReservedCollateral abi.TokenAmount
 
 	nextDealId abi.DealID
 }
@@ -320,6 +442,7 @@ func (m *marketStateMutation) build() (*marketStateMutation, error) {
 			return nil, xerrors.Errorf("failed to load deal state: %w", err)
 		}
 		m.dealStates = states
+		m.activeDeals = m.st.ActiveDeals
 	}
 
 	if m.lockedPermit != Invalid {
@@ -331,6 +454,7 @@ func (m *marketStateMutation) build() (*marketStateMutation, error) {
 		m.totalClientLockedCollateral = m.st.TotalClientLockedCollateral.Copy()
 		m.totalClientStorageFee = m.st.TotalClientStorageFee.Copy()
 		m.totalProviderLockedCollateral = m.st.TotalProviderLockedCollateral.Copy()
+		m.totalProviderReservedCollateral = m.st.TotalProviderReservedCollateral.Copy()
 	}
 
 	if m.escrowPermit != Invalid {
@@ -339,10 +463,11 @@ func (m *marketStateMutation) build() (*marketStateMutation, error) {
 			return nil, xerrors.Errorf("failed to load escrow table: %w", err)
 		}
 		m.escrowTable = et
+		m.totalEscrow = m.st.TotalEscrow.Copy()
 	}
 
 	if m.pendingPermit != Invalid {
-		pending, err := adt.AsSet(m.store, m.st.PendingProposals, builtin.DefaultHamtBitwidth)
+		pending, err := adt.AsSet(m.store, m.st.PendingProposals, PendingProposalsHamtBitwidth)
 		if err != nil {
 			return nil, xerrors.Errorf("failed to load pending proposals: %w", err)
 		}
@@ -350,13 +475,29 @@ func (m *marketStateMutation) build() (*marketStateMutation, error) {
 	}
 
 	if m.dpePermit != Invalid {
-		dbe, err := AsSetMultimap(m.store, m.st.DealOpsByEpoch, builtin.DefaultHamtBitwidth, builtin.DefaultHamtBitwidth)
+		dbe, err := AsSetMultimap(m.store, m.st.DealOpsByEpoch, DealOpsByEpochHamtBitwidth, DealOpsByEpochHamtBitwidth)
 		if err != nil {
 			return nil, xerrors.Errorf("failed to load deals by epoch: %w", err)
 		}
 		m.dealsByEpoch = dbe
 	}
 
+	if m.contributionsPermit != Invalid {
+		contributions, err := AsEscrowContributions(m.store, m.st.EscrowContributions)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to load escrow contributions: %w", err)
+		}
+		m.contributions = contributions
+	}
+
+	if m.reservationsPermit != Invalid {
+		reservations, err := adt.AsMap(m.store, m.st.CollateralReservations, CollateralReservationsHamtBitwidth)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to load collateral reservations: %w", err)
+		}
+		m.reservations = reservations
+	}
+
 	m.nextDealId = m.st.NextID
 
 	return m, nil
@@ -392,6 +533,16 @@ func (m *marketStateMutation) withDealsByEpoch(permit MarketStateMutationPermiss
 	return m
 }
 
+func (m *marketStateMutation) withEscrowContributions(permit MarketStateMutationPermission) *marketStateMutation {
+	m.contributionsPermit = permit
+	return m
+}
+
+func (m *marketStateMutation) withCollateralReservations(permit MarketStateMutationPermission) *marketStateMutation {
+	m.reservationsPermit = permit
+	return m
+}
+
 func (m *marketStateMutation) commitState() error {
 	var err error
 	if m.proposalPermit == WritePermission {
@@ -404,6 +555,7 @@ func (m *marketStateMutation) commitState() error {
 		if m.st.States, err = m.dealStates.Root(); err != nil {
 			return xerrors.Errorf("failed to flush deal states: %w", err)
 		}
+		m.st.ActiveDeals = m.activeDeals
 	}
 
 	if m.lockedPermit == WritePermission {
@@ -413,12 +565,14 @@ func (m *marketStateMutation) commitState() error {
 		m.st.TotalClientLockedCollateral = m.totalClientLockedCollateral.Copy()
 		m.st.TotalProviderLockedCollateral = m.totalProviderLockedCollateral.Copy()
 		m.st.TotalClientStorageFee = m.totalClientStorageFee.Copy()
+		m.st.TotalProviderReservedCollateral = m.totalProviderReservedCollateral.Copy()
 	}
 
 	if m.escrowPermit == WritePermission {
 		if m.st.EscrowTable, err = m.escrowTable.Root(); err != nil {
 			return xerrors.Errorf("failed to flush escrow table: %w", err)
 		}
+		m.st.TotalEscrow = m.totalEscrow.Copy()
 	}
 
 	if m.pendingPermit == WritePermission {
@@ -433,6 +587,18 @@ func (m *marketStateMutation) commitState() error {
 		}
 	}
 
+	if m.contributionsPermit == WritePermission {
+		if m.st.EscrowContributions, err = m.contributions.Root(); err != nil {
+			return xerrors.Errorf("failed to flush escrow contributions: %w", err)
+		}
+	}
+
+	if m.reservationsPermit == WritePermission {
+		if m.st.CollateralReservations, err = m.reservations.Root(); err != nil {
+			return xerrors.Errorf("failed to flush collateral reservations: %w", err)
+		}
+	}
+
 	m.st.NextID = m.nextDealId
 	return nil
 }
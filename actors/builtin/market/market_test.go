@@ -22,6 +22,7 @@ import (
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin/power"
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin/reward"
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin/verifreg"
+	"github.com/filecoin-project/specs-actors/v3/actors/runtime"
 	"github.com/filecoin-project/specs-actors/v3/actors/util/adt"
 	"github.com/filecoin-project/specs-actors/v3/support/mock"
 	tutil "github.com/filecoin-project/specs-actors/v3/support/testing"
@@ -108,6 +109,7 @@ func TestMarketActor(t *testing.T) {
 		assert.Equal(t, abi.DealID(0), state.NextID)
 		assert.Equal(t, emptyMultiMap, state.DealOpsByEpoch)
 		assert.Equal(t, abi.ChainEpoch(-1), state.LastCron)
+		assert.Equal(t, emptyMap, state.EscrowContributions)
 	})
 
 	t.Run("AddBalance", func(t *testing.T) {
@@ -190,6 +192,23 @@ func TestMarketActor(t *testing.T) {
 			}
 		})
 
+		t.Run("balance added for pubkey address is held by ID address", func(t *testing.T) {
+			rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+			clientPubkey := tutil.NewBLSAddr(t, 900)
+			rt.AddIDAddress(clientPubkey, client)
+
+			rt.SetCaller(client, builtin.AccountActorCodeID)
+			rt.SetReceived(abi.NewTokenAmount(10))
+			rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+			rt.Call(actor.AddBalance, &clientPubkey)
+			rt.Verify()
+			rt.SetBalance(big.Add(rt.Balance(), abi.NewTokenAmount(10)))
+
+			actor.addParticipantFunds(rt, client, abi.NewTokenAmount(20))
+			assert.Equal(t, abi.NewTokenAmount(30), actor.getEscrowBalance(rt, client))
+			actor.checkState(rt)
+		})
+
 		t.Run("fail when balance is zero", func(t *testing.T) {
 			rt, actor := basicMarketSetup(t, owner, provider, worker, client)
 
@@ -205,6 +224,73 @@ func TestMarketActor(t *testing.T) {
 		})
 	})
 
+	t.Run("AddBalanceFor", func(t *testing.T) {
+		broker := tutil.NewIDAddr(t, 105)
+
+		addFor := func(rt *mock.Runtime, actor *marketActorTestHarness, payer, recipient address.Address, amount int64) {
+			rt.SetCaller(payer, builtin.AccountActorCodeID)
+			rt.SetReceived(abi.NewTokenAmount(amount))
+			rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+			rt.Call(actor.AddBalanceFor, &recipient)
+			rt.Verify()
+			rt.SetBalance(big.Add(rt.Balance(), abi.NewTokenAmount(amount)))
+		}
+
+		t.Run("records contributions by payer", func(t *testing.T) {
+			rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+
+			addFor(rt, actor, broker, client, 10)
+			addFor(rt, actor, broker, client, 20)
+			addFor(rt, actor, worker, client, 5)
+			assert.Equal(t, abi.NewTokenAmount(35), actor.getEscrowBalance(rt, client))
+
+			rt.GetState(&st)
+			contribution, err := st.EscrowContribution(rt.AdtStore(), client, broker)
+			require.NoError(t, err)
+			assert.Equal(t, abi.NewTokenAmount(30), contribution)
+
+			contributions, err := st.EscrowContributionsTo(rt.AdtStore(), client)
+			require.NoError(t, err)
+			assert.Equal(t, map[address.Address]abi.TokenAmount{
+				broker: abi.NewTokenAmount(30),
+				worker: abi.NewTokenAmount(5),
+			}, contributions)
+
+			// Nothing is attributed to the recipient's own deposits.
+			actor.addParticipantFunds(rt, client, abi.NewTokenAmount(100))
+			rt.GetState(&st)
+			contribution, err = st.EscrowContribution(rt.AdtStore(), client, client)
+			require.NoError(t, err)
+			assert.True(t, contribution.IsZero())
+			actor.checkState(rt)
+		})
+
+		t.Run("contributions are not reduced by withdrawal", func(t *testing.T) {
+			rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+			addFor(rt, actor, broker, client, 50)
+
+			actor.withdrawClientBalance(rt, client, abi.NewTokenAmount(50), abi.NewTokenAmount(50))
+			assert.True(t, actor.getEscrowBalance(rt, client).Equals(big.Zero()))
+
+			rt.GetState(&st)
+			contribution, err := st.EscrowContribution(rt.AdtStore(), client, broker)
+			require.NoError(t, err)
+			assert.Equal(t, abi.NewTokenAmount(50), contribution)
+			actor.checkState(rt)
+		})
+
+		t.Run("fails for the caller's own escrow", func(t *testing.T) {
+			rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+			rt.SetCaller(client, builtin.AccountActorCodeID)
+			rt.SetReceived(abi.NewTokenAmount(10))
+			rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+			rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+				rt.Call(actor.AddBalanceFor, &client)
+			})
+			actor.checkState(rt)
+		})
+	})
+
 	t.Run("WithdrawBalance", func(t *testing.T) {
 		startEpoch := abi.ChainEpoch(10)
 		endEpoch := startEpoch + 200*builtin.EpochsInDay
@@ -514,6 +600,11 @@ func TestPublishStorageDeals(t *testing.T) {
 		require.True(t, ok)
 		dealId := resp.IDs[0]
 
+		// the returned proposal CID is that of the proposal with resolved addresses
+		expectedCid, err := deal2.Cid()
+		require.NoError(t, err)
+		require.Equal(t, []cid.Cid{expectedCid}, resp.ProposalCIDs)
+
 		// assert that deal is persisted with the resolved addresses
 		prop := actor.getDealProposal(rt, dealId)
 		require.EqualValues(t, clientResolved, prop.Client)
@@ -667,6 +758,80 @@ func TestPublishStorageDeals(t *testing.T) {
 
 		actor.checkState(rt)
 	})
+
+	t.Run("datacap is used for each verified deal and not for unverified deals", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		client2 := tutil.NewIDAddr(t, 900)
+
+		deal1 := actor.generateDealAndAddFunds(rt, client, mAddr, startEpoch, endEpoch)
+		deal1.VerifiedDeal = true
+		deal2 := actor.generateDealAndAddFunds(rt, client, mAddr, startEpoch, endEpoch+1)
+		deal2.VerifiedDeal = true
+		deal2.PieceSize = abi.PaddedPieceSize(4096)
+		deal3 := actor.generateDealAndAddFunds(rt, client2, mAddr, startEpoch, endEpoch+2)
+		deal3.VerifiedDeal = true
+		// deal4 is not verified, so no datacap is used
+		deal4 := actor.generateDealAndAddFunds(rt, client, mAddr, startEpoch, endEpoch+3)
+
+		// the harness expects one UseBytes send for each verified deal, in deal order
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
+		dealIds := actor.publishDeals(rt, mAddr, publishDealReq{deal: deal1}, publishDealReq{deal: deal2},
+			publishDealReq{deal: deal3}, publishDealReq{deal: deal4})
+		require.Len(t, dealIds, 4)
+
+		actor.checkState(rt)
+	})
+
+	t.Run("drop deals with a different provider", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		deal1 := actor.generateDealAndAddFunds(rt, client, mAddr, startEpoch, endEpoch)
+		m2 := &minerAddrs{owner, worker, tutil.NewIDAddr(t, 1000), nil}
+
+		deal2 := actor.generateDealAndAddFunds(rt, client, m2, abi.ChainEpoch(1), endEpoch)
+
+		params := mkPublishStorageParams(deal1, deal2)
+
+		rt.ExpectValidateCallerType(builtin.AccountActorCodeID, builtin.MultisigActorCodeID)
+		rt.ExpectStaticSend(provider, builtin.MethodsMiner.ControlAddresses, nil, &miner.GetControlAddressesReturn{Worker: worker, Owner: owner}, 0)
+		expectQueryNetworkInfo(rt, actor)
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
+		rt.ExpectVerifySignature(crypto.Signature{}, deal1.Client, mustCbor(&deal1), nil)
+		rt.ExpectVerifySignature(crypto.Signature{}, deal2.Client, mustCbor(&deal2), nil)
+
+		actor.expectGetRandom(rt, &deal1, abi.ChainEpoch(100))
+
+		ret := rt.Call(actor.PublishStorageDeals, params).(*market.PublishStorageDealsReturn)
+		rt.Verify()
+		require.Len(t, ret.IDs, 1)
+		assertValidDeals(t, ret, 0)
+
+		actor.checkState(rt)
+	})
+
+	t.Run("duplicate deal is dropped before the provider balance is checked", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		// The provider's balance covers exactly one copy of the deal.
+		deal1 := actor.generateDealAndAddFunds(rt, client, mAddr, startEpoch, endEpoch)
+		actor.addParticipantFunds(rt, client, deal1.ClientBalanceRequirement())
+
+		params := mkPublishStorageParams(deal1, deal1)
+
+		rt.ExpectValidateCallerType(builtin.AccountActorCodeID, builtin.MultisigActorCodeID)
+		rt.ExpectStaticSend(provider, builtin.MethodsMiner.ControlAddresses, nil, &miner.GetControlAddressesReturn{Worker: worker, Owner: owner}, 0)
+		expectQueryNetworkInfo(rt, actor)
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
+		rt.ExpectVerifySignature(crypto.Signature{}, deal1.Client, mustCbor(&deal1), nil)
+		rt.ExpectVerifySignature(crypto.Signature{}, deal1.Client, mustCbor(&deal1), nil)
+
+		actor.expectGetRandom(rt, &deal1, abi.ChainEpoch(100))
+
+		ret := rt.Call(actor.PublishStorageDeals, params).(*market.PublishStorageDealsReturn)
+		rt.Verify()
+		require.Len(t, ret.IDs, 1)
+		assertValidDeals(t, ret, 0)
+
+		actor.checkState(rt)
+	})
 }
 
 func TestPublishStorageDealsFailures(t *testing.T) {
@@ -761,7 +926,7 @@ func TestPublishStorageDealsFailures(t *testing.T) {
 					a.addParticipantFunds(rt, client, big.Sub(d.ClientBalanceRequirement(), big.NewInt(1)))
 					a.addProviderFunds(rt, d.ProviderCollateral, mAddrs)
 				},
-				exitCode: exitcode.ErrInsufficientFunds,
+				exitCode: exitcode.ErrIllegalArgument,
 			},
 			"provider does not have enough balance for collateral": {
 				setup: func(rt *mock.Runtime, a *marketActorTestHarness, d *market.DealProposal) {
@@ -774,7 +939,7 @@ func TestPublishStorageDealsFailures(t *testing.T) {
 				setup: func(_ *mock.Runtime, a *marketActorTestHarness, d *market.DealProposal) {
 					d.Client = tutil.NewBLSAddr(t, 1)
 				},
-				exitCode: exitcode.ErrNotFound,
+				exitCode: exitcode.ErrIllegalArgument,
 			},
 			"signature is invalid": {
 				setup: func(_ *mock.Runtime, a *marketActorTestHarness, d *market.DealProposal) {
@@ -787,7 +952,7 @@ func TestPublishStorageDealsFailures(t *testing.T) {
 				setup: func(rt *mock.Runtime, a *marketActorTestHarness, d *market.DealProposal) {
 					a.addProviderFunds(rt, d.ProviderCollateral, mAddrs)
 				},
-				exitCode: exitcode.ErrInsufficientFunds,
+				exitCode: exitcode.ErrIllegalArgument,
 			},
 			"no entry for provider in locked  balance table": {
 				setup: func(rt *mock.Runtime, a *marketActorTestHarness, d *market.DealProposal) {
@@ -831,7 +996,7 @@ func TestPublishStorageDealsFailures(t *testing.T) {
 				params := mkPublishStorageParams(dealProposal)
 
 				rt.ExpectValidateCallerType(builtin.AccountActorCodeID, builtin.MultisigActorCodeID)
-				rt.ExpectSend(provider, builtin.MethodsMiner.ControlAddresses, nil, abi.NewTokenAmount(0), &miner.GetControlAddressesReturn{Worker: worker, Owner: owner}, 0)
+				rt.ExpectStaticSend(provider, builtin.MethodsMiner.ControlAddresses, nil, &miner.GetControlAddressesReturn{Worker: worker, Owner: owner}, 0)
 				expectQueryNetworkInfo(rt, actor)
 				rt.SetCaller(worker, builtin.AccountActorCodeID)
 				rt.ExpectVerifySignature(crypto.Signature{}, dealProposal.Client, mustCbor(&dealProposal), tc.signatureVerificationError)
@@ -858,11 +1023,11 @@ func TestPublishStorageDealsFailures(t *testing.T) {
 			params := mkPublishStorageParams(deal1)
 
 			rt.ExpectValidateCallerType(builtin.AccountActorCodeID, builtin.MultisigActorCodeID)
-			rt.ExpectSend(provider, builtin.MethodsMiner.ControlAddresses, nil, abi.NewTokenAmount(0), &miner.GetControlAddressesReturn{Worker: worker, Owner: owner}, 0)
+			rt.ExpectStaticSend(provider, builtin.MethodsMiner.ControlAddresses, nil, &miner.GetControlAddressesReturn{Worker: worker, Owner: owner}, 0)
 			expectQueryNetworkInfo(rt, actor)
 			rt.SetCaller(worker, builtin.AccountActorCodeID)
 			rt.ExpectVerifySignature(crypto.Signature{}, deal1.Client, mustCbor(&deal1), nil)
-			rt.ExpectAbort(exitcode.ErrInsufficientFunds, func() {
+			rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
 				rt.Call(actor.PublishStorageDeals, params)
 			})
 
@@ -880,7 +1045,7 @@ func TestPublishStorageDealsFailures(t *testing.T) {
 			params := mkPublishStorageParams(deal1)
 
 			rt.ExpectValidateCallerType(builtin.AccountActorCodeID, builtin.MultisigActorCodeID)
-			rt.ExpectSend(provider, builtin.MethodsMiner.ControlAddresses, nil, abi.NewTokenAmount(0), &miner.GetControlAddressesReturn{Worker: worker, Owner: owner}, 0)
+			rt.ExpectStaticSend(provider, builtin.MethodsMiner.ControlAddresses, nil, &miner.GetControlAddressesReturn{Worker: worker, Owner: owner}, 0)
 			expectQueryNetworkInfo(rt, actor)
 			rt.SetCaller(worker, builtin.AccountActorCodeID)
 			rt.ExpectVerifySignature(crypto.Signature{}, deal1.Client, mustCbor(&deal1), nil)
@@ -893,34 +1058,7 @@ func TestPublishStorageDealsFailures(t *testing.T) {
 		})
 	}
 
-	// fail when deals have different providers
 	{
-		t.Run("fail when deals have different providers", func(t *testing.T) {
-			rt, actor := basicMarketSetup(t, owner, provider, worker, client)
-			deal1 := actor.generateDealAndAddFunds(rt, client, mAddrs, startEpoch, endEpoch)
-			m2 := &minerAddrs{owner, worker, tutil.NewIDAddr(t, 1000), nil}
-
-			deal2 := actor.generateDealAndAddFunds(rt, client, m2, abi.ChainEpoch(1), endEpoch)
-
-			params := mkPublishStorageParams(deal1, deal2)
-
-			rt.ExpectValidateCallerType(builtin.AccountActorCodeID, builtin.MultisigActorCodeID)
-			rt.ExpectSend(provider, builtin.MethodsMiner.ControlAddresses, nil, abi.NewTokenAmount(0), &miner.GetControlAddressesReturn{Worker: worker, Owner: owner}, 0)
-			expectQueryNetworkInfo(rt, actor)
-			rt.SetCaller(worker, builtin.AccountActorCodeID)
-			rt.ExpectVerifySignature(crypto.Signature{}, deal1.Client, mustCbor(&deal1), nil)
-			rt.ExpectVerifySignature(crypto.Signature{}, deal2.Client, mustCbor(&deal2), nil)
-
-			actor.expectGetRandom(rt, &deal1, abi.ChainEpoch(100))
-
-			rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
-				rt.Call(actor.PublishStorageDeals, params)
-			})
-
-			rt.Verify()
-			actor.checkState(rt)
-		})
-
 		//  failures because of incorrect call params
 		t.Run("fail when caller is not of signable type", func(t *testing.T) {
 			rt, actor := basicMarketSetup(t, owner, provider, worker, client)
@@ -964,7 +1102,7 @@ func TestPublishStorageDealsFailures(t *testing.T) {
 			deal := generateDealProposal(client, provider, startEpoch, endEpoch)
 			params := mkPublishStorageParams(deal)
 			rt.ExpectValidateCallerType(builtin.AccountActorCodeID, builtin.MultisigActorCodeID)
-			rt.ExpectSend(provider, builtin.MethodsMiner.ControlAddresses, nil, abi.NewTokenAmount(0), &miner.GetControlAddressesReturn{Worker: tutil.NewIDAddr(t, 999), Owner: owner}, 0)
+			rt.ExpectStaticSend(provider, builtin.MethodsMiner.ControlAddresses, nil, &miner.GetControlAddressesReturn{Worker: tutil.NewIDAddr(t, 999), Owner: owner}, 0)
 			rt.SetCaller(worker, builtin.AccountActorCodeID)
 			rt.ExpectAbort(exitcode.ErrForbidden, func() {
 				rt.Call(actor.PublishStorageDeals, params)
@@ -975,6 +1113,100 @@ func TestPublishStorageDealsFailures(t *testing.T) {
 		})
 	}
 
+	t.Run("drop verified deal when verified registry rejects datacap use", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		deal1 := actor.generateDealAndAddFunds(rt, client, mAddrs, startEpoch, endEpoch)
+		deal1.VerifiedDeal = true
+		deal2 := actor.generateDealAndAddFunds(rt, client, mAddrs, startEpoch, endEpoch+1)
+		deal2.VerifiedDeal = true
+		params := mkPublishStorageParams(deal1, deal2)
+
+		rt.ExpectValidateCallerType(builtin.AccountActorCodeID, builtin.MultisigActorCodeID)
+		rt.ExpectStaticSend(provider, builtin.MethodsMiner.ControlAddresses, nil, &miner.GetControlAddressesReturn{Worker: worker, Owner: owner}, 0)
+		expectQueryNetworkInfo(rt, actor)
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
+		rt.ExpectVerifySignature(crypto.Signature{}, deal1.Client, mustCbor(&deal1), nil)
+		rt.ExpectVerifySignature(crypto.Signature{}, deal2.Client, mustCbor(&deal2), nil)
+		actor.expectGetRandom(rt, &deal1, abi.ChainEpoch(100))
+
+		// first deal's datacap is used, but the client has insufficient datacap for the second
+		rt.ExpectSend(builtin.VerifiedRegistryActorAddr, builtin.MethodsVerifiedRegistry.UseBytes, &verifreg.UseBytesParams{
+			Address:  client,
+			DealSize: big.NewIntUnsigned(uint64(deal1.PieceSize)),
+		}, abi.NewTokenAmount(0), nil, exitcode.Ok)
+		rt.ExpectSend(builtin.VerifiedRegistryActorAddr, builtin.MethodsVerifiedRegistry.UseBytes, &verifreg.UseBytesParams{
+			Address:  client,
+			DealSize: big.NewIntUnsigned(uint64(deal2.PieceSize)),
+		}, abi.NewTokenAmount(0), nil, exitcode.ErrIllegalArgument)
+
+		// only the second deal is dropped
+		ret := rt.Call(actor.PublishStorageDeals, params).(*market.PublishStorageDealsReturn)
+		rt.Verify()
+		require.Len(t, ret.IDs, 1)
+		assertValidDeals(t, ret, 0)
+
+		require.EqualValues(t, deal1.ClientBalanceRequirement(), actor.getLockedBalance(rt, client))
+		require.EqualValues(t, deal1.ProviderCollateral, actor.getLockedBalance(rt, provider))
+		actor.checkState(rt)
+	})
+
+	t.Run("drop invalid deals and publish the rest of the batch", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		client2 := tutil.NewIDAddr(t, 105)
+		rt.SetAddressActorType(client2, builtin.AccountActorCodeID)
+
+		// deal1 has an invalid signature, deal2's client is underfunded, deal3 is published
+		deal1 := actor.generateDealAndAddFunds(rt, client, mAddrs, startEpoch, endEpoch)
+		deal2 := generateDealProposal(client2, provider, startEpoch, endEpoch+1)
+		actor.addParticipantFunds(rt, client2, big.Sub(deal2.ClientBalanceRequirement(), big.NewInt(1)))
+		actor.addProviderFunds(rt, deal2.ProviderCollateral, mAddrs)
+		deal3 := actor.generateDealAndAddFunds(rt, client, mAddrs, startEpoch, endEpoch+2)
+		params := mkPublishStorageParams(deal1, deal2, deal3)
+
+		rt.ExpectValidateCallerType(builtin.AccountActorCodeID, builtin.MultisigActorCodeID)
+		rt.ExpectStaticSend(provider, builtin.MethodsMiner.ControlAddresses, nil, &miner.GetControlAddressesReturn{Worker: worker, Owner: owner}, 0)
+		expectQueryNetworkInfo(rt, actor)
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
+		rt.ExpectVerifySignature(crypto.Signature{}, deal1.Client, mustCbor(&deal1), errors.New("bad signature"))
+		rt.ExpectVerifySignature(crypto.Signature{}, deal2.Client, mustCbor(&deal2), nil)
+		rt.ExpectVerifySignature(crypto.Signature{}, deal3.Client, mustCbor(&deal3), nil)
+		actor.expectGetRandom(rt, &deal3, abi.ChainEpoch(100))
+
+		ret := rt.Call(actor.PublishStorageDeals, params).(*market.PublishStorageDealsReturn)
+		rt.Verify()
+		require.Len(t, ret.IDs, 1)
+		assertValidDeals(t, ret, 2)
+		expectedCid, err := deal3.Cid()
+		require.NoError(t, err)
+		require.Equal(t, []cid.Cid{expectedCid}, ret.ProposalCIDs)
+
+		require.EqualValues(t, deal3.ClientBalanceRequirement(), actor.getLockedBalance(rt, client))
+		require.EqualValues(t, big.Zero(), actor.getLockedBalance(rt, client2))
+		actor.checkState(rt)
+	})
+
+	t.Run("drop duplicate deals within a batch", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		deal1 := actor.generateDealAndAddFunds(rt, client, mAddrs, startEpoch, endEpoch)
+		actor.addParticipantFunds(rt, client, deal1.ClientBalanceRequirement())
+		actor.addProviderFunds(rt, deal1.ProviderCollateral, mAddrs)
+		params := mkPublishStorageParams(deal1, deal1)
+
+		rt.ExpectValidateCallerType(builtin.AccountActorCodeID, builtin.MultisigActorCodeID)
+		rt.ExpectStaticSend(provider, builtin.MethodsMiner.ControlAddresses, nil, &miner.GetControlAddressesReturn{Worker: worker, Owner: owner}, 0)
+		expectQueryNetworkInfo(rt, actor)
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
+		rt.ExpectVerifySignature(crypto.Signature{}, deal1.Client, mustCbor(&deal1), nil)
+		rt.ExpectVerifySignature(crypto.Signature{}, deal1.Client, mustCbor(&deal1), nil)
+		actor.expectGetRandom(rt, &deal1, abi.ChainEpoch(100))
+
+		ret := rt.Call(actor.PublishStorageDeals, params).(*market.PublishStorageDealsReturn)
+		rt.Verify()
+		require.Len(t, ret.IDs, 1)
+		assertValidDeals(t, ret, 0)
+		actor.checkState(rt)
+	})
+
 	t.Run("fails if provider is not a storage miner actor", func(t *testing.T) {
 		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
 
@@ -1039,6 +1271,137 @@ func TestActivateDeals(t *testing.T) {
 	})
 }
 
+func TestBatchActivateDeals(t *testing.T) {
+	owner := tutil.NewIDAddr(t, 101)
+	provider := tutil.NewIDAddr(t, 102)
+	worker := tutil.NewIDAddr(t, 103)
+	client := tutil.NewIDAddr(t, 104)
+	mAddrs := &minerAddrs{owner, worker, provider, nil}
+
+	startEpoch := abi.ChainEpoch(10)
+	endEpoch := startEpoch + 200*builtin.EpochsInDay
+	currentEpoch := abi.ChainEpoch(5)
+	sectorExpiry := endEpoch + 100
+
+	t.Run("activates deals grouped by sector and returns per-sector weights and pieces", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		rt.SetEpoch(currentEpoch)
+
+		dealId1 := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch, startEpoch)
+		dealId2 := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch+1, startEpoch)
+		dealId3 := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch+2, startEpoch)
+		d1 := actor.getDealProposal(rt, dealId1)
+		d2 := actor.getDealProposal(rt, dealId2)
+		d3 := actor.getDealProposal(rt, dealId3)
+
+		ret := actor.batchActivateDeals(rt, provider, []market.SectorDeals{
+			{SectorExpiry: sectorExpiry, DealIDs: []abi.DealID{dealId1, dealId2}},
+			{SectorExpiry: sectorExpiry, DealIDs: []abi.DealID{dealId3}},
+			{SectorExpiry: sectorExpiry, DealIDs: nil},
+		})
+		require.Len(t, ret.Sectors, 3)
+		for _, s := range ret.Sectors {
+			assert.True(t, s.Activated)
+		}
+
+		assert.Equal(t, uint64(d1.PieceSize+d2.PieceSize), ret.Sectors[0].DealSpace)
+		assert.Equal(t, big.Add(market.DealWeight(d1, rt.Epoch()), market.DealWeight(d2, rt.Epoch())), ret.Sectors[0].DealWeight)
+		assert.Equal(t, big.Zero(), ret.Sectors[0].VerifiedDealWeight)
+		assert.Equal(t, []cid.Cid{d1.PieceCID, d2.PieceCID}, ret.Sectors[0].PieceCIDs)
+		assert.Equal(t, []market.UnverifiedPiece{
+			{Size: d1.PieceSize, PieceCID: d1.PieceCID, DealWeight: market.DealWeight(d1, rt.Epoch())},
+			{Size: d2.PieceSize, PieceCID: d2.PieceCID, DealWeight: market.DealWeight(d2, rt.Epoch())},
+		}, ret.Sectors[0].UnverifiedPieces)
+
+		assert.Equal(t, uint64(d3.PieceSize), ret.Sectors[1].DealSpace)
+		assert.Equal(t, market.DealWeight(d3, rt.Epoch()), ret.Sectors[1].DealWeight)
+		assert.Equal(t, []cid.Cid{d3.PieceCID}, ret.Sectors[1].PieceCIDs)
+
+		assert.Equal(t, uint64(0), ret.Sectors[2].DealSpace)
+		assert.Equal(t, big.Zero(), ret.Sectors[2].DealWeight)
+		assert.Empty(t, ret.Sectors[2].PieceCIDs)
+
+		for _, id := range []abi.DealID{dealId1, dealId2, dealId3} {
+			assert.EqualValues(t, currentEpoch, actor.getDealState(rt, id).SectorStartEpoch)
+		}
+		actor.checkState(rt)
+	})
+
+	t.Run("pieces of verified deals are not returned as unverified", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		rt.SetEpoch(currentEpoch)
+
+		verified := actor.generateDealAndAddFunds(rt, client, mAddrs, startEpoch, endEpoch)
+		verified.VerifiedDeal = true
+		unverified := actor.generateDealAndAddFunds(rt, client, mAddrs, startEpoch, endEpoch+1)
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
+		dealIds := actor.publishDeals(rt, mAddrs, publishDealReq{deal: verified}, publishDealReq{deal: unverified})
+
+		ret := actor.batchActivateDeals(rt, provider, []market.SectorDeals{{SectorExpiry: sectorExpiry, DealIDs: dealIds}})
+		assert.Equal(t, []cid.Cid{verified.PieceCID, unverified.PieceCID}, ret.Sectors[0].PieceCIDs)
+		assert.Equal(t, []market.UnverifiedPiece{{
+			Size:       unverified.PieceSize,
+			PieceCID:   unverified.PieceCID,
+			DealWeight: market.DealWeight(&unverified, rt.Epoch()),
+		}}, ret.Sectors[0].UnverifiedPieces)
+		actor.checkState(rt)
+	})
+
+	t.Run("does not activate a sector whose deal is included in an earlier sector of the batch", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		rt.SetEpoch(currentEpoch)
+		dealId1 := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch, startEpoch)
+		dealId2 := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch+1, startEpoch)
+
+		ret := actor.batchActivateDeals(rt, provider, []market.SectorDeals{
+			{SectorExpiry: sectorExpiry, DealIDs: []abi.DealID{dealId1}},
+			{SectorExpiry: sectorExpiry, DealIDs: []abi.DealID{dealId2, dealId1}},
+		})
+		require.Len(t, ret.Sectors, 2)
+		assert.True(t, ret.Sectors[0].Activated)
+		assert.False(t, ret.Sectors[1].Activated)
+		assert.Empty(t, ret.Sectors[1].PieceCIDs)
+
+		assert.EqualValues(t, currentEpoch, actor.getDealState(rt, dealId1).SectorStartEpoch)
+		actor.assertDealsNotActivated(rt, currentEpoch, dealId2)
+		actor.checkState(rt)
+	})
+
+	t.Run("does not activate a sector whose deal expires after it", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		rt.SetEpoch(currentEpoch)
+		dealId1 := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch, startEpoch)
+		dealId2 := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch+1, startEpoch)
+
+		ret := actor.batchActivateDeals(rt, provider, []market.SectorDeals{
+			{SectorExpiry: endEpoch, DealIDs: []abi.DealID{dealId2}},
+			{SectorExpiry: sectorExpiry, DealIDs: []abi.DealID{dealId1}},
+		})
+		require.Len(t, ret.Sectors, 2)
+		assert.False(t, ret.Sectors[0].Activated)
+		assert.Equal(t, big.Zero(), ret.Sectors[0].DealWeight)
+		assert.True(t, ret.Sectors[1].Activated)
+
+		actor.assertDealsNotActivated(rt, currentEpoch, dealId2)
+		assert.EqualValues(t, currentEpoch, actor.getDealState(rt, dealId1).SectorStartEpoch)
+		actor.checkState(rt)
+	})
+
+	t.Run("too many sectors are rejected before dispatch", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		rt.SetEpoch(currentEpoch)
+
+		rt.SetCaller(provider, builtin.StorageMinerActorCodeID)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "Sectors length", func() {
+			rt.Call(actor.BatchActivateDeals, &market.BatchActivateDealsParams{
+				Sectors: make([]market.SectorDeals, market.BatchItemsMax+1),
+			})
+		})
+		rt.Verify()
+		actor.checkState(rt)
+	})
+}
+
 func TestActivateDealFailures(t *testing.T) {
 	owner := tutil.NewIDAddr(t, 101)
 	provider := tutil.NewIDAddr(t, 102)
@@ -1123,13 +1486,13 @@ func TestActivateDealFailures(t *testing.T) {
 
 	// deal has invalid params
 	{
-		t.Run("fail when current epoch greater than start epoch of deal", func(t *testing.T) {
+		t.Run("fail when current epoch greater than end of deal activation grace period", func(t *testing.T) {
 			rt, actor := basicMarketSetup(t, owner, provider, worker, client)
 			dealId := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch, startEpoch)
 
 			rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
 			rt.SetCaller(provider, builtin.StorageMinerActorCodeID)
-			rt.SetEpoch(startEpoch + 1)
+			rt.SetEpoch(startEpoch + market.DealActivationGracePeriod + 1)
 			rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
 				rt.Call(actor.ActivateDeals, mkActivateDealParams(sectorExpiry, dealId))
 			})
@@ -1144,7 +1507,7 @@ func TestActivateDealFailures(t *testing.T) {
 
 			rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
 			rt.SetCaller(provider, builtin.StorageMinerActorCodeID)
-			rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			rt.ExpectAbort(market.ErrDealOutlivesSector, func() {
 				rt.Call(actor.ActivateDeals, mkActivateDealParams(endEpoch-1, dealId))
 			})
 
@@ -1505,7 +1868,7 @@ func TestCronTick(t *testing.T) {
 		// cron tick will slash deal1 and make payment for deal2
 		current := abi.ChainEpoch(151)
 		rt.SetEpoch(current)
-		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, d1.ProviderCollateral, nil, exitcode.Ok)
+		rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.BurnFunds, &builtin.BurnFundsParams{Reason: builtin.BurnReasonDealSlash}, d1.ProviderCollateral, nil, exitcode.Ok)
 		actor.cronTick(rt)
 
 		actor.assertDealDeleted(rt, dealId1, d1)
@@ -1525,7 +1888,7 @@ func TestCronTick(t *testing.T) {
 		d2 := actor.generateDealAndAddFunds(rt, client, mAddrs, startEpoch, endEpoch)
 		params := mkPublishStorageParams(d2)
 		rt.ExpectValidateCallerType(builtin.AccountActorCodeID, builtin.MultisigActorCodeID)
-		rt.ExpectSend(provider, builtin.MethodsMiner.ControlAddresses, nil, abi.NewTokenAmount(0), &miner.GetControlAddressesReturn{Worker: worker, Owner: owner}, 0)
+		rt.ExpectStaticSend(provider, builtin.MethodsMiner.ControlAddresses, nil, &miner.GetControlAddressesReturn{Worker: worker, Owner: owner}, 0)
 		expectQueryNetworkInfo(rt, actor)
 		rt.SetCaller(worker, builtin.AccountActorCodeID)
 		rt.ExpectVerifySignature(crypto.Signature{}, d2.Client, mustCbor(&d2), nil)
@@ -1637,30 +2000,96 @@ func TestRandomCronEpochDuringPublish(t *testing.T) {
 		actor.checkState(rt)
 	})
 
-	t.Run("activation after deal start epoch but before it is processed fails", func(t *testing.T) {
+	t.Run("activation after deal start epoch within the grace period succeeds", func(t *testing.T) {
 		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
 		processEpoch := startEpoch + 5
 		dealId := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch, processEpoch)
+		d := actor.getDealProposal(rt, dealId)
 
-		// activate the deal after the start epoch
-		rt.SetEpoch(startEpoch + 1)
+		// cron processing before activation within the grace period neither slashes nor deletes the deal
+		rt.SetEpoch(processEpoch)
+		cLocked := actor.getLockedBalance(rt, client)
+		pLocked := actor.getLockedBalance(rt, provider)
+		actor.cronTick(rt)
+		assert.Equal(t, cLocked, actor.getLockedBalance(rt, client))
+		assert.Equal(t, pLocked, actor.getLockedBalance(rt, provider))
+		actor.getDealProposal(rt, dealId)
+
+		// activate the deal at the end of the grace period
+		activationEpoch := startEpoch + market.DealActivationGracePeriod
+		rt.SetEpoch(activationEpoch)
+		actor.activateDeals(rt, sectorExpiry, provider, activationEpoch, dealId)
+
+		// the client's storage fee for the epochs before activation is unlocked
+		unpaid := big.Mul(big.NewInt(int64(market.DealActivationGracePeriod)), d.StoragePricePerEpoch)
+		assert.Equal(t, big.Sub(cLocked, unpaid), actor.getLockedBalance(rt, client))
+
+		// the deal is processed at the end of the grace period, owing nothing for the epochs before activation
+		pay, slashed := actor.cronTickAndAssertBalances(rt, client, provider, activationEpoch, dealId)
+		require.EqualValues(t, big.Zero(), slashed)
+		require.EqualValues(t, big.Zero(), pay)
+
+		// later payments are due from the activation epoch
+		current := activationEpoch + market.DealUpdatesInterval
+		rt.SetEpoch(current)
+		pay, slashed = actor.cronTickAndAssertBalances(rt, client, provider, current, dealId)
+		require.EqualValues(t, big.Zero(), slashed)
+		require.EqualValues(t, big.Mul(big.NewInt(int64(market.DealUpdatesInterval)), d.StoragePricePerEpoch), pay)
+
+		actor.checkState(rt)
+	})
+
+	t.Run("activation after the grace period fails", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		processEpoch := startEpoch + 5
+		dealId := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch, processEpoch)
+
+		activationEpoch := startEpoch + market.DealActivationGracePeriod + 1
+		rt.SetEpoch(activationEpoch)
 
 		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
-			actor.activateDeals(rt, sectorExpiry, provider, startEpoch+1, dealId)
+			actor.activateDeals(rt, sectorExpiry, provider, activationEpoch, dealId)
 		})
 
 		actor.checkState(rt)
 	})
 
+	t.Run("late activation of a verified deal filling the sector weighs no more than the sector", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		deal := actor.generateDealAndAddFunds(rt, client, mAddrs, startEpoch, endEpoch)
+		deal.VerifiedDeal = true
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
+		dealIds := actor.publishDeals(rt, mAddrs, publishDealReq{deal: deal})
+
+		// The sector is activated late and expires with the deal, so holds it only from activation.
+		activationEpoch := startEpoch + market.DealActivationGracePeriod
+		rt.SetEpoch(activationEpoch)
+		ret := actor.batchActivateDeals(rt, provider, []market.SectorDeals{{SectorExpiry: endEpoch, DealIDs: dealIds}})
+		require.True(t, ret.Sectors[0].Activated)
+
+		sectorDuration := endEpoch - activationEpoch
+		sectorSpaceTime := big.Mul(big.NewInt(int64(sectorDuration)), big.NewIntUnsigned(uint64(deal.PieceSize)))
+		assert.Equal(t, sectorSpaceTime, ret.Sectors[0].VerifiedDealWeight)
+		assert.Equal(t, big.Zero(), ret.Sectors[0].DealWeight)
+
+		// Quality-adjusted power of a sector full of the deal is capped at the verified multiplier.
+		sectorSize := abi.SectorSize(deal.PieceSize)
+		power := miner.QAPowerForWeight(sectorSize, sectorDuration, ret.Sectors[0].DealWeight, ret.Sectors[0].VerifiedDealWeight)
+		maxPower := big.Div(big.Mul(big.NewIntUnsigned(uint64(sectorSize)), builtin.VerifiedDealWeightMultiplier), builtin.QualityBaseMultiplier)
+		assert.Equal(t, maxPower, power)
+
+		actor.checkState(rt)
+	})
+
 	t.Run("cron processing of deal after missed activation should fail and slash", func(t *testing.T) {
 		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
 		processEpoch := startEpoch + 5
 		dealId := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch, processEpoch)
 		d := actor.getDealProposal(rt, dealId)
 
-		rt.SetEpoch(processEpoch)
+		rt.SetEpoch(startEpoch + market.DealActivationGracePeriod)
 
-		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, d.ProviderCollateral, nil, exitcode.Ok)
+		rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.BurnFunds, &builtin.BurnFundsParams{Reason: builtin.BurnReasonDealSlash}, d.ProviderCollateral, nil, exitcode.Ok)
 		actor.cronTick(rt)
 
 		actor.assertDealDeleted(rt, dealId, d)
@@ -1724,13 +2153,19 @@ func TestLockedFundTrackingStates(t *testing.T) {
 
 	actor.assertLockedFundStates(rt, csf, plc, clc)
 
-	// make payment for p1 and p2, p3 times out as it has not been activated
+	// make payment for p1 and p2, p3 has not been activated but is within its activation grace period
 	curr = 51 // startEpoch + 1
 	rt.SetEpoch(curr)
-	rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, d3.ProviderCollateral, nil, exitcode.Ok)
 	actor.cronTick(rt)
 	payment := big.Product(big.NewInt(2), d1.StoragePricePerEpoch)
-	csf = big.Sub(big.Sub(csf, payment), d3.TotalStorageFee())
+	csf = big.Sub(csf, payment)
+	actor.assertLockedFundStates(rt, csf, plc, clc)
+
+	// p3 times out at the end of the grace period
+	rt.SetEpoch(startEpoch + market.DealActivationGracePeriod)
+	rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.BurnFunds, &builtin.BurnFundsParams{Reason: builtin.BurnReasonDealSlash}, d3.ProviderCollateral, nil, exitcode.Ok)
+	actor.cronTick(rt)
+	csf = big.Sub(csf, d3.TotalStorageFee())
 	plc = big.Sub(plc, d3.ProviderCollateral)
 	clc = big.Sub(clc, d3.ClientCollateral)
 	actor.assertLockedFundStates(rt, csf, plc, clc)
@@ -1759,13 +2194,175 @@ func TestLockedFundTrackingStates(t *testing.T) {
 	csf = big.Zero()
 	clc = big.Zero()
 	plc = big.Zero()
-	rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, d1.ProviderCollateral, nil, exitcode.Ok)
+	rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.BurnFunds, &builtin.BurnFundsParams{Reason: builtin.BurnReasonDealSlash}, d1.ProviderCollateral, nil, exitcode.Ok)
 	actor.cronTick(rt)
 	actor.assertLockedFundStates(rt, csf, plc, clc)
 
 	actor.checkState(rt)
 }
 
+func TestMarketStats(t *testing.T) {
+	owner := tutil.NewIDAddr(t, 101)
+	provider := tutil.NewIDAddr(t, 102)
+	worker := tutil.NewIDAddr(t, 103)
+	client := tutil.NewIDAddr(t, 104)
+	mAddrs := &minerAddrs{owner, worker, provider, nil}
+
+	startEpoch := abi.ChainEpoch(50)
+	endEpoch := startEpoch + 200*builtin.EpochsInDay
+	sectorExpiry := endEpoch + 100
+
+	rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+	stats := actor.marketStats(rt)
+	assert.Equal(t, big.Zero(), stats.TotalEscrow)
+	assert.Equal(t, big.Zero(), stats.TotalLocked)
+	assert.Equal(t, uint64(0), stats.ActiveDeals)
+
+	dealId := actor.publishAndActivateDeal(rt, client, mAddrs, startEpoch, endEpoch, 0, sectorExpiry, startEpoch)
+	d := actor.getDealProposal(rt, dealId)
+	stats = actor.marketStats(rt)
+	assert.Equal(t, big.Add(d.ClientBalanceRequirement(), d.ProviderCollateral), stats.TotalEscrow)
+	assert.Equal(t, stats.TotalEscrow, stats.TotalLocked)
+	assert.Equal(t, d.ProviderCollateral, stats.TotalProviderLockedCollateral)
+	assert.Equal(t, d.ClientCollateral, stats.TotalClientLockedCollateral)
+	assert.Equal(t, d.TotalStorageFee(), stats.TotalClientStorageFee)
+	assert.Equal(t, uint64(1), stats.ActiveDeals)
+
+	// Unlocked funds are held in escrow but not locked.
+	extra := abi.NewTokenAmount(1000)
+	actor.addParticipantFunds(rt, client, extra)
+	withExtra := actor.marketStats(rt)
+	assert.Equal(t, big.Add(stats.TotalEscrow, extra), withExtra.TotalEscrow)
+	assert.Equal(t, stats.TotalLocked, withExtra.TotalLocked)
+	actor.withdrawClientBalance(rt, client, extra, extra)
+	assert.Equal(t, stats.TotalEscrow, actor.marketStats(rt).TotalEscrow)
+
+	// Slashed provider collateral leaves escrow, and the terminated deal is no longer active once processed.
+	rt.SetEpoch(startEpoch + 1)
+	actor.terminateDeals(rt, provider, dealId)
+	assert.Equal(t, uint64(1), actor.marketStats(rt).ActiveDeals)
+	rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.BurnFunds, &builtin.BurnFundsParams{Reason: builtin.BurnReasonDealSlash}, d.ProviderCollateral, nil, exitcode.Ok)
+	actor.cronTick(rt)
+
+	stats = actor.marketStats(rt)
+	assert.Equal(t, d.ClientBalanceRequirement(), stats.TotalEscrow)
+	assert.Equal(t, big.Zero(), stats.TotalLocked)
+	assert.Equal(t, uint64(0), stats.ActiveDeals)
+	actor.checkState(rt)
+}
+
+func TestCollateralReservation(t *testing.T) {
+	owner := tutil.NewIDAddr(t, 101)
+	provider := tutil.NewIDAddr(t, 102)
+	worker := tutil.NewIDAddr(t, 103)
+	client := tutil.NewIDAddr(t, 104)
+	mAddrs := &minerAddrs{owner, worker, provider, nil}
+
+	startEpoch := abi.ChainEpoch(50)
+	endEpoch := startEpoch + 200*builtin.EpochsInDay
+	sectorExpiry := endEpoch + 100
+	reserved := abi.NewTokenAmount(1000)
+
+	t.Run("reserved escrow cannot be withdrawn and is released on activation", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch, startEpoch)
+		d := actor.getDealProposal(rt, dealId)
+		actor.addProviderFunds(rt, big.Mul(big.NewInt(2), reserved), mAddrs)
+
+		actor.reserveCollateral(rt, provider, market.CollateralReservation{DealID: dealId, Amount: reserved})
+		require.EqualValues(t, big.Add(d.ProviderCollateral, reserved), actor.getLockedBalance(rt, provider))
+		require.EqualValues(t, reserved, actor.getReservedCollateral(rt, dealId))
+		stats := actor.marketStats(rt)
+		assert.Equal(t, reserved, stats.TotalProviderReservedCollateral)
+		assert.Equal(t, big.Sum(d.ClientBalanceRequirement(), d.ProviderCollateral, reserved), stats.TotalLocked)
+		actor.checkState(rt)
+
+		// Only the unreserved remainder can be withdrawn.
+		actor.withdrawProviderBalance(rt, big.Mul(big.NewInt(2), reserved), reserved, mAddrs)
+		require.EqualValues(t, big.Add(d.ProviderCollateral, reserved), actor.getEscrowBalance(rt, provider))
+
+		actor.activateDeals(rt, sectorExpiry, provider, 0, dealId)
+		require.EqualValues(t, d.ProviderCollateral, actor.getLockedBalance(rt, provider))
+		require.EqualValues(t, big.Zero(), actor.getReservedCollateral(rt, dealId))
+		assert.Equal(t, big.Zero(), actor.marketStats(rt).TotalProviderReservedCollateral)
+		actor.withdrawProviderBalance(rt, reserved, reserved, mAddrs)
+		actor.checkState(rt)
+	})
+
+	t.Run("reservation is released when the deal times out", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch, startEpoch)
+		d := actor.getDealProposal(rt, dealId)
+		actor.addProviderFunds(rt, reserved, mAddrs)
+		actor.reserveCollateral(rt, provider, market.CollateralReservation{DealID: dealId, Amount: reserved})
+
+		rt.SetEpoch(startEpoch + market.DealActivationGracePeriod)
+		slashed := market.CollateralPenaltyForDealActivationMissed(d.ProviderCollateral)
+		rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.BurnFunds, &builtin.BurnFundsParams{Reason: builtin.BurnReasonDealSlash}, slashed, nil, exitcode.Ok)
+		actor.cronTick(rt)
+
+		actor.assertDealDeleted(rt, dealId, d)
+		require.EqualValues(t, big.Zero(), actor.getLockedBalance(rt, provider))
+		require.EqualValues(t, big.Zero(), actor.getReservedCollateral(rt, dealId))
+		actor.checkState(rt)
+	})
+
+	t.Run("miner releases reservations of an expired pre-commitment", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId1 := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch, startEpoch)
+		dealId2 := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch+1, startEpoch)
+		d1 := actor.getDealProposal(rt, dealId1)
+		d2 := actor.getDealProposal(rt, dealId2)
+		actor.addProviderFunds(rt, reserved, mAddrs)
+		actor.reserveCollateral(rt, provider, market.CollateralReservation{DealID: dealId1, Amount: reserved})
+
+		// A deal without a reservation is ignored.
+		actor.releaseCollateral(rt, provider, dealId1, dealId2)
+		require.EqualValues(t, big.Add(d1.ProviderCollateral, d2.ProviderCollateral), actor.getLockedBalance(rt, provider))
+		require.EqualValues(t, big.Zero(), actor.getReservedCollateral(rt, dealId1))
+
+		// The deal remains pending and may be reserved against again.
+		actor.reserveCollateral(rt, provider, market.CollateralReservation{DealID: dealId1, Amount: reserved})
+		actor.checkState(rt)
+	})
+
+	t.Run("fails to reserve collateral", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch, startEpoch)
+		actor.addProviderFunds(rt, reserved, mAddrs)
+
+		reserve := func(caller address.Address, r market.CollateralReservation) {
+			rt.SetCaller(caller, builtin.StorageMinerActorCodeID)
+			rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
+			rt.Call(actor.ReserveCollateral, &market.ReserveCollateralParams{Reservations: []market.CollateralReservation{r}})
+		}
+
+		rt.ExpectAbortContainsMessage(exitcode.ErrForbidden, "is not the provider", func() {
+			reserve(tutil.NewIDAddr(t, 109), market.CollateralReservation{DealID: dealId, Amount: reserved})
+		})
+		rt.ExpectAbortContainsMessage(exitcode.ErrNotFound, "no such deal", func() {
+			reserve(provider, market.CollateralReservation{DealID: dealId + 1, Amount: reserved})
+		})
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "non-positive reservation", func() {
+			reserve(provider, market.CollateralReservation{DealID: dealId, Amount: big.Zero()})
+		})
+		rt.ExpectAbortContainsMessage(exitcode.ErrInsufficientFunds, "insufficient balance", func() {
+			reserve(provider, market.CollateralReservation{DealID: dealId, Amount: big.Add(reserved, big.NewInt(1))})
+		})
+
+		actor.reserveCollateral(rt, provider, market.CollateralReservation{DealID: dealId, Amount: big.Div(reserved, big.NewInt(2))})
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "already reserved", func() {
+			reserve(provider, market.CollateralReservation{DealID: dealId, Amount: big.Div(reserved, big.NewInt(2))})
+		})
+
+		actor.activateDeals(rt, sectorExpiry, provider, 0, dealId)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "already activated", func() {
+			reserve(provider, market.CollateralReservation{DealID: dealId, Amount: big.NewInt(1)})
+		})
+		actor.checkState(rt)
+	})
+}
+
 func TestCronTickTimedoutDeals(t *testing.T) {
 	owner := tutil.NewIDAddr(t, 101)
 	provider := tutil.NewIDAddr(t, 102)
@@ -1784,9 +2381,9 @@ func TestCronTickTimedoutDeals(t *testing.T) {
 
 		cEscrow := actor.getEscrowBalance(rt, client)
 
-		// do a cron tick for it -> should time out and get slashed
-		rt.SetEpoch(startEpoch)
-		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, d.ProviderCollateral, nil, exitcode.Ok)
+		// do a cron tick for it at the end of the grace period -> should time out and get slashed
+		rt.SetEpoch(startEpoch + market.DealActivationGracePeriod)
+		rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.BurnFunds, &builtin.BurnFundsParams{Reason: builtin.BurnReasonDealSlash}, d.ProviderCollateral, nil, exitcode.Ok)
 		actor.cronTick(rt)
 
 		require.Equal(t, cEscrow, actor.getEscrowBalance(rt, client))
@@ -1799,7 +2396,45 @@ func TestCronTickTimedoutDeals(t *testing.T) {
 		actor.checkState(rt)
 	})
 
-	t.Run("publishing timed out deal again should work after cron tick as it should no longer be pending", func(t *testing.T) {
+	t.Run("unactivated deal stays locked through the grace period then times out with a refund event", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch, startEpoch)
+		d := actor.getDealProposal(rt, dealId)
+
+		cEscrow := actor.getEscrowBalance(rt, client)
+		cLocked := actor.getLockedBalance(rt, client)
+		pLocked := actor.getLockedBalance(rt, provider)
+		require.EqualValues(t, big.Add(d.TotalStorageFee(), d.ClientCollateral), cLocked)
+		require.EqualValues(t, d.ProviderCollateral, pLocked)
+
+		// published -> awaiting activation: a cron tick at the start epoch leaves the deal and its funds in place
+		rt.SetEpoch(startEpoch)
+		actor.cronTick(rt)
+		require.EqualValues(t, cLocked, actor.getLockedBalance(rt, client))
+		require.EqualValues(t, pLocked, actor.getLockedBalance(rt, provider))
+		require.Equal(t, d, actor.getDealProposal(rt, dealId))
+		actor.assertDealsNotActivated(rt, startEpoch, dealId)
+
+		// awaiting activation -> timed out: the client is refunded, the provider slashed and the deal deleted
+		rt.SetEpoch(startEpoch + market.DealActivationGracePeriod)
+		slashed := market.CollateralPenaltyForDealActivationMissed(d.ProviderCollateral)
+		rt.ExpectEmittedEvent(dealTimedOutEvent(t, dealId, d, cLocked, slashed))
+		rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.BurnFunds, &builtin.BurnFundsParams{Reason: builtin.BurnReasonDealSlash}, slashed, nil, exitcode.Ok)
+		actor.cronTick(rt)
+
+		require.EqualValues(t, cEscrow, actor.getEscrowBalance(rt, client))
+		require.EqualValues(t, big.Zero(), actor.getLockedBalance(rt, client))
+		require.EqualValues(t, big.Zero(), actor.getLockedBalance(rt, provider))
+		actor.assertDealDeleted(rt, dealId, d)
+
+		// timed out -> gone: later cron ticks have nothing to process
+		rt.SetEpoch(startEpoch + market.DealActivationGracePeriod + market.DealUpdatesInterval)
+		actor.cronTickNoChange(rt, client, provider)
+
+		actor.checkState(rt)
+	})
+
+	t.Run("timed out deal is no longer pending after cron tick", func(t *testing.T) {
 		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
 		dealId := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch, startEpoch)
 		d := actor.getDealProposal(rt, dealId)
@@ -1808,7 +2443,7 @@ func TestCronTickTimedoutDeals(t *testing.T) {
 		d2 := actor.generateDealAndAddFunds(rt, client, mAddrs, startEpoch, endEpoch)
 		params := mkPublishStorageParams(d2)
 		rt.ExpectValidateCallerType(builtin.AccountActorCodeID, builtin.MultisigActorCodeID)
-		rt.ExpectSend(provider, builtin.MethodsMiner.ControlAddresses, nil, abi.NewTokenAmount(0), &miner.GetControlAddressesReturn{Worker: worker, Owner: owner}, 0)
+		rt.ExpectStaticSend(provider, builtin.MethodsMiner.ControlAddresses, nil, &miner.GetControlAddressesReturn{Worker: worker, Owner: owner}, 0)
 		expectQueryNetworkInfo(rt, actor)
 		rt.SetCaller(worker, builtin.AccountActorCodeID)
 		rt.ExpectVerifySignature(crypto.Signature{}, d2.Client, mustCbor(&d2), nil)
@@ -1817,15 +2452,12 @@ func TestCronTickTimedoutDeals(t *testing.T) {
 		})
 		rt.Verify()
 
-		// do a cron tick for it -> should time out and get slashed
-		rt.SetEpoch(startEpoch)
-		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, d.ProviderCollateral, nil, exitcode.Ok)
+		// do a cron tick for it at the end of the grace period -> should time out and get slashed
+		rt.SetEpoch(startEpoch + market.DealActivationGracePeriod)
+		rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.BurnFunds, &builtin.BurnFundsParams{Reason: builtin.BurnReasonDealSlash}, d.ProviderCollateral, nil, exitcode.Ok)
 		actor.cronTick(rt)
 		actor.assertDealDeleted(rt, dealId, d)
 
-		// now publishing should work
-		actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch, startEpoch)
-
 		actor.checkState(rt)
 	})
 
@@ -1845,9 +2477,9 @@ func TestCronTickTimedoutDeals(t *testing.T) {
 		dealIds := actor.publishDeals(rt, mAddrs, publishDealReq{deal1, startEpoch},
 			publishDealReq{deal2, startEpoch}, publishDealReq{deal3, startEpoch})
 
-		// do a cron tick for it -> all should time out and get slashed
+		// do a cron tick for it at the end of the grace period -> all should time out and get slashed
 		// ONLY deal1 and deal2 should be sent to the Registry actor
-		rt.SetEpoch(startEpoch)
+		rt.SetEpoch(startEpoch + market.DealActivationGracePeriod)
 
 		// expected sends to the registry actor
 		param1 := &verifreg.RestoreBytesParams{
@@ -1865,7 +2497,7 @@ func TestCronTickTimedoutDeals(t *testing.T) {
 			abi.NewTokenAmount(0), nil, exitcode.Ok)
 
 		expectedBurn := big.Mul(big.NewInt(3), deal1.ProviderCollateral)
-		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, expectedBurn, nil, exitcode.Ok)
+		rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.BurnFunds, &builtin.BurnFundsParams{Reason: builtin.BurnReasonDealSlash}, expectedBurn, nil, exitcode.Ok)
 		actor.cronTick(rt)
 
 		// a second cron tick for the same epoch should not change anything
@@ -1878,6 +2510,106 @@ func TestCronTickTimedoutDeals(t *testing.T) {
 
 		actor.checkState(rt)
 	})
+
+	t.Run("timed out verified deal is cleaned up even if datacap cannot be restored", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		deal1 := actor.generateDealAndAddFunds(rt, client, mAddrs, startEpoch, endEpoch)
+		deal1.VerifiedDeal = true
+
+		rt.SetCaller(worker, builtin.AccountActorCodeID)
+		dealIds := actor.publishDeals(rt, mAddrs, publishDealReq{deal1, startEpoch})
+
+		rt.SetEpoch(startEpoch + market.DealActivationGracePeriod)
+		rt.ExpectSend(builtin.VerifiedRegistryActorAddr, builtin.MethodsVerifiedRegistry.RestoreBytes, &verifreg.RestoreBytesParams{
+			Address:  deal1.Client,
+			DealSize: big.NewIntUnsigned(uint64(deal1.PieceSize)),
+		}, abi.NewTokenAmount(0), nil, exitcode.ErrIllegalState)
+		rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.BurnFunds, &builtin.BurnFundsParams{Reason: builtin.BurnReasonDealSlash}, deal1.ProviderCollateral, nil, exitcode.Ok)
+		actor.cronTick(rt)
+
+		rt.ExpectLogsContain("failed to send RestoreBytes call to the VerifReg actor")
+		actor.assertAccountZero(rt, provider)
+		actor.assertDealDeleted(rt, dealIds[0], &deal1)
+
+		actor.checkState(rt)
+	})
+}
+
+func TestCronTickNotifiesDealTermination(t *testing.T) {
+	owner := tutil.NewIDAddr(t, 101)
+	provider := tutil.NewIDAddr(t, 102)
+	worker := tutil.NewIDAddr(t, 103)
+	client := tutil.NewIDAddr(t, 104)
+	mAddrs := &minerAddrs{owner, worker, provider, nil}
+
+	startEpoch := abi.ChainEpoch(50)
+	endEpoch := startEpoch + 200*builtin.EpochsInDay
+	sectorExpiry := endEpoch + 400
+
+	t.Run("multisig client is notified of a timed out deal", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch, startEpoch)
+		d := actor.getDealProposal(rt, dealId)
+		rt.SetAddressActorType(client, builtin.MultisigActorCodeID)
+
+		rt.SetEpoch(startEpoch + market.DealActivationGracePeriod)
+		expectDealTerminationNotification(rt, client, dealId, market.DealTerminationTimedOut, exitcode.Ok)
+		rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.BurnFunds, &builtin.BurnFundsParams{Reason: builtin.BurnReasonDealSlash}, d.ProviderCollateral, nil, exitcode.Ok)
+		actor.cronTick(rt)
+
+		actor.assertDealDeleted(rt, dealId, d)
+		actor.checkState(rt)
+	})
+
+	t.Run("multisig client is notified of a slashed deal", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId := actor.publishAndActivateDeal(rt, client, mAddrs, startEpoch, endEpoch, 0, sectorExpiry, startEpoch)
+		d := actor.getDealProposal(rt, dealId)
+		rt.SetAddressActorType(client, builtin.MultisigActorCodeID)
+
+		rt.SetEpoch(startEpoch + 1)
+		actor.terminateDeals(rt, provider, dealId)
+
+		rt.SetEpoch(startEpoch + 2)
+		expectDealTerminationNotification(rt, client, dealId, market.DealTerminationSlashed, exitcode.Ok)
+		rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.BurnFunds, &builtin.BurnFundsParams{Reason: builtin.BurnReasonDealSlash}, d.ProviderCollateral, nil, exitcode.Ok)
+		actor.cronTick(rt)
+
+		actor.assertDealDeleted(rt, dealId, d)
+		actor.checkState(rt)
+	})
+
+	t.Run("expired deal is not notified", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId := actor.publishAndActivateDeal(rt, client, mAddrs, startEpoch, endEpoch, 0, sectorExpiry, startEpoch)
+		d := actor.getDealProposal(rt, dealId)
+		rt.SetAddressActorType(client, builtin.MultisigActorCodeID)
+
+		rt.SetEpoch(endEpoch + market.DealUpdatesInterval)
+		actor.cronTick(rt)
+
+		actor.assertDealDeleted(rt, dealId, d)
+		actor.checkState(rt)
+	})
+
+	t.Run("deal is settled even if the client rejects the notification", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch, startEpoch)
+		d := actor.getDealProposal(rt, dealId)
+		cEscrow := actor.getEscrowBalance(rt, client)
+		rt.SetAddressActorType(client, builtin.MultisigActorCodeID)
+
+		rt.SetEpoch(startEpoch + market.DealActivationGracePeriod)
+		expectDealTerminationNotification(rt, client, dealId, market.DealTerminationTimedOut, exitcode.ErrForbidden)
+		rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.BurnFunds, &builtin.BurnFundsParams{Reason: builtin.BurnReasonDealSlash}, d.ProviderCollateral, nil, exitcode.Ok)
+		actor.cronTick(rt)
+
+		rt.ExpectLogsContain("failed to notify client")
+		require.Equal(t, cEscrow, actor.getEscrowBalance(rt, client))
+		require.Equal(t, big.Zero(), actor.getLockedBalance(rt, client))
+		actor.assertDealDeleted(rt, dealId, d)
+		actor.checkState(rt)
+	})
 }
 
 func TestCronTickDealExpiry(t *testing.T) {
@@ -2235,7 +2967,7 @@ func TestCronTickDealSlashing(t *testing.T) {
 		current = 300
 		rt.SetEpoch(current)
 		totalSlashed := big.Sum(d1.ProviderCollateral, d2.ProviderCollateral, d3.ProviderCollateral)
-		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, totalSlashed, nil, exitcode.Ok)
+		rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.BurnFunds, &builtin.BurnFundsParams{Reason: builtin.BurnReasonDealSlash}, totalSlashed, nil, exitcode.Ok)
 
 		actor.cronTick(rt)
 
@@ -2348,6 +3080,118 @@ func TestCronTickDealSlashing(t *testing.T) {
 	})
 }
 
+func TestSettleDealPayments(t *testing.T) {
+	owner := tutil.NewIDAddr(t, 101)
+	provider := tutil.NewIDAddr(t, 102)
+	worker := tutil.NewIDAddr(t, 103)
+	client := tutil.NewIDAddr(t, 104)
+	mAddrs := &minerAddrs{owner, worker, provider, nil}
+
+	startEpoch := abi.ChainEpoch(50)
+	endEpoch := startEpoch + 200*builtin.EpochsInDay
+	sectorExpiry := endEpoch + 400
+
+	t.Run("client settles accrued payment and cron pays only the remainder", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId := actor.publishAndActivateDeal(rt, client, mAddrs, startEpoch, endEpoch, 0, sectorExpiry, startEpoch)
+		d := actor.getDealProposal(rt, dealId)
+		cEscrow := actor.getEscrowBalance(rt, client)
+		pEscrow := actor.getEscrowBalance(rt, provider)
+
+		current := startEpoch + 100
+		rt.SetEpoch(current)
+		ret := actor.settleDealPayments(rt, client, dealId)
+		payment := big.Mul(big.NewInt(100), d.StoragePricePerEpoch)
+		assert.Equal(t, []abi.DealID{dealId}, ret.Settled)
+		assert.Equal(t, payment, ret.TotalPayment)
+		assert.Equal(t, big.Sub(cEscrow, payment), actor.getEscrowBalance(rt, client))
+		assert.Equal(t, big.Add(pEscrow, payment), actor.getEscrowBalance(rt, provider))
+		assert.Equal(t, current, actor.getDealState(rt, dealId).LastUpdatedEpoch)
+
+		// the deal's scheduled cron update pays nothing more for the settled epochs
+		pay, slashed := actor.cronTickAndAssertBalances(rt, client, provider, current, dealId)
+		assert.Equal(t, big.Zero(), pay)
+		assert.Equal(t, big.Zero(), slashed)
+
+		current2 := current + market.DealUpdatesInterval
+		rt.SetEpoch(current2)
+		pay, _ = actor.cronTickAndAssertBalances(rt, client, provider, current2, dealId)
+		assert.Equal(t, big.Mul(big.NewInt(int64(market.DealUpdatesInterval)), d.StoragePricePerEpoch), pay)
+		actor.checkState(rt)
+	})
+
+	t.Run("provider worker settles payment", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId := actor.publishAndActivateDeal(rt, client, mAddrs, startEpoch, endEpoch, 0, sectorExpiry, startEpoch)
+		d := actor.getDealProposal(rt, dealId)
+
+		rt.SetEpoch(startEpoch + 10)
+		expectGetControlAddresses(rt, provider, owner, worker)
+		ret := actor.settleDealPayments(rt, worker, dealId)
+		assert.Equal(t, []abi.DealID{dealId}, ret.Settled)
+		assert.Equal(t, big.Mul(big.NewInt(10), d.StoragePricePerEpoch), ret.TotalPayment)
+		actor.checkState(rt)
+	})
+
+	t.Run("settles no further than the deal end", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId := actor.publishAndActivateDeal(rt, client, mAddrs, startEpoch, endEpoch, 0, sectorExpiry, startEpoch)
+		d := actor.getDealProposal(rt, dealId)
+
+		rt.SetEpoch(endEpoch + 10)
+		ret := actor.settleDealPayments(rt, client, dealId)
+		assert.Equal(t, big.Mul(big.NewInt(int64(endEpoch-startEpoch)), d.StoragePricePerEpoch), ret.TotalPayment)
+
+		// cron expires the deal without further payment
+		cEscrow := actor.getEscrowBalance(rt, client)
+		actor.cronTick(rt)
+		actor.assertDealDeleted(rt, dealId, d)
+		assert.Equal(t, cEscrow, actor.getEscrowBalance(rt, client))
+		assert.Equal(t, big.Zero(), actor.getLockedBalance(rt, client))
+		assert.Equal(t, big.Zero(), actor.getLockedBalance(rt, provider))
+		actor.checkState(rt)
+	})
+
+	t.Run("skips deals not yet active or started", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		pending := actor.generateAndPublishDeal(rt, client, mAddrs, startEpoch, endEpoch, startEpoch)
+		future := actor.publishAndActivateDeal(rt, client, mAddrs, startEpoch+1, endEpoch, 0, sectorExpiry, startEpoch+1)
+
+		rt.SetEpoch(startEpoch)
+		ret := actor.settleDealPayments(rt, client, pending, future)
+		assert.Empty(t, ret.Settled)
+		assert.Equal(t, big.Zero(), ret.TotalPayment)
+		assert.Equal(t, abi.ChainEpoch(-1), actor.getDealState(rt, future).LastUpdatedEpoch)
+		actor.checkState(rt)
+	})
+
+	t.Run("fails if caller is not party to every deal", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId := actor.publishAndActivateDeal(rt, client, mAddrs, startEpoch, endEpoch, 0, sectorExpiry, startEpoch)
+		rt.SetEpoch(startEpoch + 10)
+
+		other := tutil.NewIDAddr(t, 105)
+		rt.SetCaller(other, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+		expectGetControlAddresses(rt, provider, owner, worker)
+		rt.ExpectAbort(exitcode.ErrForbidden, func() {
+			rt.Call(actor.SettleDealPayments, &market.SettleDealPaymentsParams{DealIDs: []abi.DealID{dealId}})
+		})
+		rt.Verify()
+		actor.checkState(rt)
+	})
+
+	t.Run("fails for unknown deal", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		rt.SetCaller(client, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+		rt.ExpectAbort(exitcode.ErrNotFound, func() {
+			rt.Call(actor.SettleDealPayments, &market.SettleDealPaymentsParams{DealIDs: []abi.DealID{42}})
+		})
+		rt.Verify()
+	})
+}
+
 func TestMarketActorDeals(t *testing.T) {
 	owner := tutil.NewIDAddr(t, 101)
 	provider := tutil.NewIDAddr(t, 102)
@@ -2377,7 +3221,7 @@ func TestMarketActorDeals(t *testing.T) {
 	// Second attempt at publishing the same deal should fail
 	{
 		rt.ExpectValidateCallerType(builtin.AccountActorCodeID, builtin.MultisigActorCodeID)
-		rt.ExpectSend(provider, builtin.MethodsMiner.ControlAddresses, nil, abi.NewTokenAmount(0), &miner.GetControlAddressesReturn{Worker: worker, Owner: owner}, 0)
+		rt.ExpectStaticSend(provider, builtin.MethodsMiner.ControlAddresses, nil, &miner.GetControlAddressesReturn{Worker: worker, Owner: owner}, 0)
 		expectQueryNetworkInfo(rt, actor)
 		rt.ExpectVerifySignature(crypto.Signature{}, client, mustCbor(&params.Deals[0].Proposal), nil)
 		rt.SetCaller(worker, builtin.AccountActorCodeID)
@@ -2430,7 +3274,7 @@ func TestMaxDealLabelSize(t *testing.T) {
 	// Label greater than max size should fail.
 	{
 		rt.ExpectValidateCallerType(builtin.AccountActorCodeID, builtin.MultisigActorCodeID)
-		rt.ExpectSend(provider, builtin.MethodsMiner.ControlAddresses, nil, abi.NewTokenAmount(0), &miner.GetControlAddressesReturn{Worker: worker, Owner: owner}, 0)
+		rt.ExpectStaticSend(provider, builtin.MethodsMiner.ControlAddresses, nil, &miner.GetControlAddressesReturn{Worker: worker, Owner: owner}, 0)
 		expectQueryNetworkInfo(rt, actor)
 		rt.ExpectVerifySignature(crypto.Signature{}, client, mustCbor(&params.Deals[0].Proposal), nil)
 		rt.SetCaller(worker, builtin.AccountActorCodeID)
@@ -2507,6 +3351,224 @@ func TestComputeDataCommitment(t *testing.T) {
 		})
 		actor.checkState(rt)
 	})
+
+	t.Run("fail when deal pieces do not fit in the sector", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId1 := actor.generateAndPublishDeal(rt, client, mAddrs, start, end, start)
+		dealId2 := actor.generateAndPublishDeal(rt, client, mAddrs, start, end+1, start)
+		// Each 2KiB piece fills a 2KiB sector alone.
+		param := &market.ComputeDataCommitmentParams{DealIDs: []abi.DealID{dealId1, dealId2}, SectorType: abi.RegisteredSealProof_StackedDrg2KiBV1}
+
+		rt.SetCaller(provider, builtin.StorageMinerActorCodeID)
+		rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "do not fit in sector", func() {
+			rt.Call(actor.ComputeDataCommitment, param)
+		})
+		actor.checkState(rt)
+	})
+
+	t.Run("fail when sector type is invalid", func(t *testing.T) {
+		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
+		dealId := actor.generateAndPublishDeal(rt, client, mAddrs, start, end, start)
+		param := &market.ComputeDataCommitmentParams{DealIDs: []abi.DealID{dealId}, SectorType: abi.RegisteredSealProof(-1)}
+
+		rt.SetCaller(provider, builtin.StorageMinerActorCodeID)
+		rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "invalid sector type", func() {
+			rt.Call(actor.ComputeDataCommitment, param)
+		})
+		actor.checkState(rt)
+	})
+}
+
+func TestValidateDealTermWithinSector(t *testing.T) {
+	start := abi.ChainEpoch(100)
+	end := start + 200*builtin.EpochsInDay
+
+	assert.NoError(t, market.ValidateDealTermWithinSector(start, end, start, end))
+	assert.NoError(t, market.ValidateDealTermWithinSector(start, end, start-1, end+1))
+
+	assert.NoError(t, market.ValidateDealTermWithinSector(start, end, start+market.DealActivationGracePeriod, end))
+
+	err := market.ValidateDealTermWithinSector(start, end, start+market.DealActivationGracePeriod+1, end)
+	assert.Equal(t, exitcode.ErrIllegalArgument, exitcode.Unwrap(err, exitcode.Ok))
+
+	err = market.ValidateDealTermWithinSector(start, end, start, end-1)
+	assert.Equal(t, market.ErrDealOutlivesSector, exitcode.Unwrap(err, exitcode.Ok))
+}
+
+func TestValidateProposal(t *testing.T) {
+	client := tutil.NewIDAddr(t, 101)
+	provider := tutil.NewIDAddr(t, 102)
+	epoch := abi.ChainEpoch(10)
+	start := epoch + 1
+	end := start + 200*builtin.EpochsInDay
+	policy := market.ProposalPolicy{
+		NetworkRawPower:   abi.NewStoragePower(1 << 50),
+		NetworkQAPower:    abi.NewStoragePower(1 << 50),
+		BaselinePower:     abi.NewStoragePower(1 << 50),
+		CirculatingSupply: big.Zero(),
+	}
+
+	t.Run("valid proposal", func(t *testing.T) {
+		proposal := generateDealProposal(client, provider, start, end)
+		assert.NoError(t, market.ValidateProposal(&proposal, epoch, policy))
+		// A deal may start at the current epoch.
+		assert.NoError(t, market.ValidateProposal(&proposal, start, policy))
+	})
+
+	for _, tc := range []struct {
+		name     string
+		mutate   func(p *market.DealProposal, policy *market.ProposalPolicy)
+		expected error
+	}{{
+		name: "label too long",
+		mutate: func(p *market.DealProposal, _ *market.ProposalPolicy) {
+			p.Label = string(make([]byte, market.DealMaxLabelSize+1))
+		},
+		expected: market.ErrProposalLabelTooLong,
+	}, {
+		name:     "piece size not a power of two",
+		mutate:   func(p *market.DealProposal, _ *market.ProposalPolicy) { p.PieceSize = 2047 },
+		expected: market.ErrProposalInvalidPieceSize,
+	}, {
+		name:     "undefined piece CID",
+		mutate:   func(p *market.DealProposal, _ *market.ProposalPolicy) { p.PieceCID = cid.Undef },
+		expected: market.ErrProposalInvalidPieceCID,
+	}, {
+		name:     "piece CID with wrong prefix",
+		mutate:   func(p *market.DealProposal, _ *market.ProposalPolicy) { p.PieceCID = tutil.MakeCID("1", nil) },
+		expected: market.ErrProposalInvalidPieceCID,
+	}, {
+		name:     "end before start",
+		mutate:   func(p *market.DealProposal, _ *market.ProposalPolicy) { p.EndEpoch = p.StartEpoch },
+		expected: market.ErrProposalEndBeforeStart,
+	}, {
+		name:     "start elapsed",
+		mutate:   func(p *market.DealProposal, _ *market.ProposalPolicy) { p.StartEpoch = epoch - 1 },
+		expected: market.ErrProposalStartElapsed,
+	}, {
+		name: "duration too short",
+		mutate: func(p *market.DealProposal, _ *market.ProposalPolicy) {
+			p.EndEpoch = p.StartEpoch + market.DealMinDuration - 1
+		},
+		expected: market.ErrProposalDurationOutOfBounds,
+	}, {
+		name: "duration too long",
+		mutate: func(p *market.DealProposal, _ *market.ProposalPolicy) {
+			p.EndEpoch = p.StartEpoch + market.DealMaxDuration + 1
+		},
+		expected: market.ErrProposalDurationOutOfBounds,
+	}, {
+		name: "negative price",
+		mutate: func(p *market.DealProposal, _ *market.ProposalPolicy) {
+			p.StoragePricePerEpoch = abi.NewTokenAmount(-1)
+		},
+		expected: market.ErrProposalPriceOutOfBounds,
+	}, {
+		name: "provider collateral below minimum",
+		mutate: func(p *market.DealProposal, policy *market.ProposalPolicy) {
+			policy.CirculatingSupply = builtin.TotalFilecoin
+			p.ProviderCollateral = big.Zero()
+		},
+		expected: market.ErrProposalProviderCollateralOutOfBounds,
+	}, {
+		name: "client collateral above maximum",
+		mutate: func(p *market.DealProposal, _ *market.ProposalPolicy) {
+			p.ClientCollateral = big.Add(builtin.TotalFilecoin, big.NewInt(1))
+		},
+		expected: market.ErrProposalClientCollateralOutOfBounds,
+	}} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			proposal := generateDealProposal(client, provider, start, end)
+			p := policy
+			tc.mutate(&proposal, &p)
+			err := market.ValidateProposal(&proposal, epoch, p)
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, tc.expected), "expected %v, got %v", tc.expected, err)
+		})
+	}
+}
+
+func TestDealBounds(t *testing.T) {
+	pieceSize := abi.PaddedPieceSize(1 << 40)
+	duration := abi.ChainEpoch(1000)
+	policy := market.ProposalPolicy{
+		NetworkRawPower:   abi.NewStoragePower(1 << 50),
+		NetworkQAPower:    abi.NewStoragePower(1 << 50),
+		BaselinePower:     abi.NewStoragePower(1 << 49),
+		CirculatingSupply: abi.NewTokenAmount(1 << 40),
+	}
+	// The deal's share of the larger of network raw power and baseline power.
+	normalizedSupply := abi.NewTokenAmount(1 << 30)
+
+	t.Run("default bounds permit free deals without client collateral", func(t *testing.T) {
+		minPrice, maxPrice := market.DealPriceBounds(pieceSize, duration, policy)
+		assert.True(t, minPrice.IsZero())
+		assert.Equal(t, builtin.TotalFilecoin, maxPrice)
+
+		providerMin, providerMax, clientMin, clientMax := market.DealCollateralBounds(pieceSize, false, duration, policy)
+		assert.Equal(t, big.Div(normalizedSupply, big.NewInt(100)), providerMin)
+		assert.Equal(t, builtin.TotalFilecoin, providerMax)
+		assert.Equal(t, big.Zero(), clientMin)
+		assert.Equal(t, builtin.TotalFilecoin, clientMax)
+	})
+
+	t.Run("bounds follow supply targets", func(t *testing.T) {
+		defer setSupplyTargets(builtin.BigFrac{Numerator: big.NewInt(1), Denominator: big.NewInt(10)},
+			builtin.BigFrac{Numerator: big.NewInt(1), Denominator: big.NewInt(1000)})()
+
+		// The total fee covers the target, rounding the price per epoch up.
+		minPrice, _ := market.DealPriceBounds(pieceSize, duration, policy)
+		minTotal := big.Div(normalizedSupply, big.NewInt(10))
+		assert.Equal(t, big.Div(big.Add(minTotal, big.NewInt(int64(duration)-1)), big.NewInt(int64(duration))), minPrice)
+		assert.True(t, big.Mul(minPrice, big.NewInt(int64(duration))).GreaterThanEqual(minTotal))
+
+		_, _, clientMin, _ := market.DealCollateralBounds(pieceSize, false, duration, policy)
+		assert.Equal(t, big.Div(normalizedSupply, big.NewInt(1000)), clientMin)
+
+		// Bounds scale with circulating supply.
+		policy := policy
+		policy.CirculatingSupply = big.Zero()
+		minPrice, _ = market.DealPriceBounds(pieceSize, duration, policy)
+		assert.True(t, minPrice.IsZero())
+	})
+
+	t.Run("proposal below minimum price or client collateral is rejected", func(t *testing.T) {
+		defer setSupplyTargets(builtin.BigFrac{Numerator: big.NewInt(1), Denominator: big.NewInt(1)},
+			builtin.BigFrac{Numerator: big.NewInt(1), Denominator: big.NewInt(1)})()
+		client := tutil.NewIDAddr(t, 101)
+		provider := tutil.NewIDAddr(t, 102)
+		start := abi.ChainEpoch(100)
+		proposal := generateDealProposal(client, provider, start, start+200*builtin.EpochsInDay)
+		policy := policy
+		policy.CirculatingSupply = builtin.TotalFilecoin
+
+		err := market.ValidateProposal(&proposal, 0, policy)
+		assert.True(t, errors.Is(err, market.ErrProposalPriceOutOfBounds), "unexpected error %v", err)
+
+		minPrice, _ := market.DealPriceBounds(proposal.PieceSize, proposal.Duration(), policy)
+		providerMin, _, clientMin, _ := market.DealCollateralBounds(proposal.PieceSize, false, proposal.Duration(), policy)
+		proposal.StoragePricePerEpoch = minPrice
+		proposal.ProviderCollateral = providerMin
+		err = market.ValidateProposal(&proposal, 0, policy)
+		assert.True(t, errors.Is(err, market.ErrProposalClientCollateralOutOfBounds), "unexpected error %v", err)
+
+		proposal.ClientCollateral = clientMin
+		assert.NoError(t, market.ValidateProposal(&proposal, 0, policy))
+	})
+}
+
+// Sets the deal price and client collateral supply targets, returning a function that restores them.
+func setSupplyTargets(price, clientCollateral builtin.BigFrac) func() {
+	prevPrice, prevClient := market.DealMinPriceSupplyTarget, market.ClientCollateralSupplyTarget
+	market.DealMinPriceSupplyTarget = price
+	market.ClientCollateralSupplyTarget = clientCollateral
+	return func() {
+		market.DealMinPriceSupplyTarget = prevPrice
+		market.ClientCollateralSupplyTarget = prevClient
+	}
 }
 
 func TestVerifyDealsForActivation(t *testing.T) {
@@ -2529,7 +3591,7 @@ func TestVerifyDealsForActivation(t *testing.T) {
 			DealIDs:      []abi.DealID{dealId},
 		}})
 		require.EqualValues(t, big.Zero(), resp.Sectors[0].VerifiedDealWeight)
-		require.EqualValues(t, market.DealWeight(d), resp.Sectors[0].DealWeight)
+		require.EqualValues(t, market.DealWeight(d, rt.Epoch()), resp.Sectors[0].DealWeight)
 
 		actor.checkState(rt)
 	})
@@ -2545,7 +3607,7 @@ func TestVerifyDealsForActivation(t *testing.T) {
 			SectorExpiry: sectorExpiry,
 			DealIDs:      dealIds,
 		}})
-		require.EqualValues(t, market.DealWeight(&deal), resp.Sectors[0].VerifiedDealWeight)
+		require.EqualValues(t, market.DealWeight(&deal, rt.Epoch()), resp.Sectors[0].VerifiedDealWeight)
 		require.EqualValues(t, big.Zero(), resp.Sectors[0].DealWeight)
 
 		actor.checkState(rt)
@@ -2572,8 +3634,8 @@ func TestVerifyDealsForActivation(t *testing.T) {
 			DealIDs:      dealIds,
 		}})
 
-		verifiedWeight := big.Add(market.DealWeight(&vd1), market.DealWeight(&vd2))
-		nvweight := big.Add(market.DealWeight(&d1), market.DealWeight(&d2))
+		verifiedWeight := big.Add(market.DealWeight(&vd1, rt.Epoch()), market.DealWeight(&vd2, rt.Epoch()))
+		nvweight := big.Add(market.DealWeight(&d1, rt.Epoch()), market.DealWeight(&d2, rt.Epoch()))
 		require.EqualValues(t, verifiedWeight, resp.Sectors[0].VerifiedDealWeight)
 		require.EqualValues(t, nvweight, resp.Sectors[0].DealWeight)
 
@@ -2627,11 +3689,11 @@ func TestVerifyDealsForActivation(t *testing.T) {
 		actor.checkState(rt)
 	})
 
-	t.Run("fail when current epoch is greater than proposal start epoch", func(t *testing.T) {
+	t.Run("fail when current epoch is greater than end of proposal activation grace period", func(t *testing.T) {
 		rt, actor := basicMarketSetup(t, owner, provider, worker, client)
 		dealId := actor.generateAndPublishDeal(rt, client, mAddrs, start, end, start)
 
-		rt.SetEpoch(start + 1)
+		rt.SetEpoch(start + market.DealActivationGracePeriod + 1)
 		param := &market.VerifyDealsForActivationParams{Sectors: []market.SectorDeals{{
 			SectorExpiry: sectorExpiry,
 			DealIDs:      []abi.DealID{dealId},
@@ -2654,7 +3716,7 @@ func TestVerifyDealsForActivation(t *testing.T) {
 		}}}
 		rt.SetCaller(provider, builtin.StorageMinerActorCodeID)
 		rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
-		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+		rt.ExpectAbort(market.ErrDealOutlivesSector, func() {
 			rt.Call(actor.VerifyDealsForActivation, param)
 		})
 		actor.checkState(rt)
@@ -2707,6 +3769,21 @@ func (h *marketActorTestHarness) verifyDealsForActivation(rt *mock.Runtime, prov
 	return val
 }
 
+func (h *marketActorTestHarness) batchActivateDeals(rt *mock.Runtime, provider address.Address,
+	sectorDeals []market.SectorDeals) *market.BatchActivateDealsReturn {
+	param := &market.BatchActivateDealsParams{Sectors: sectorDeals}
+	rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
+	rt.SetCaller(provider, builtin.StorageMinerActorCodeID)
+
+	ret := rt.Call(h.BatchActivateDeals, param)
+	rt.Verify()
+
+	val, ok := ret.(*market.BatchActivateDealsReturn)
+	require.True(h.t, ok)
+	require.NotNil(h.t, val)
+	return val
+}
+
 type minerAddrs struct {
 	owner    address.Address
 	worker   address.Address
@@ -2811,7 +3888,7 @@ func (h *marketActorTestHarness) cronTickAndAssertBalances(rt *mock.Runtime, cli
 	// end epoch for payment calc
 	paymentEnd := d.EndEpoch
 	if s.SlashEpoch != -1 {
-		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, d.ProviderCollateral, nil, exitcode.Ok)
+		rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.BurnFunds, &builtin.BurnFundsParams{Reason: builtin.BurnReasonDealSlash}, d.ProviderCollateral, nil, exitcode.Ok)
 		amountSlashed = d.ProviderCollateral
 
 		if s.SlashEpoch < d.StartEpoch {
@@ -2825,6 +3902,9 @@ func (h *marketActorTestHarness) cronTickAndAssertBalances(rt *mock.Runtime, cli
 
 	// start epoch for payment calc
 	paymentStart := d.StartEpoch
+	if s.SectorStartEpoch > paymentStart {
+		paymentStart = s.SectorStartEpoch
+	}
 	if s.LastUpdatedEpoch != -1 {
 		paymentStart = s.LastUpdatedEpoch
 	}
@@ -2854,6 +3934,21 @@ func (h *marketActorTestHarness) cronTickAndAssertBalances(rt *mock.Runtime, cli
 	return
 }
 
+func (h *marketActorTestHarness) settleDealPayments(rt *mock.Runtime, caller address.Address, dealIDs ...abi.DealID) *market.SettleDealPaymentsReturn {
+	rt.SetCaller(caller, builtin.AccountActorCodeID)
+	rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+	ret := rt.Call(h.SettleDealPayments, &market.SettleDealPaymentsParams{DealIDs: dealIDs}).(*market.SettleDealPaymentsReturn)
+	rt.Verify()
+	return ret
+}
+
+func (h *marketActorTestHarness) marketStats(rt *mock.Runtime) *market.MarketStatsReturn {
+	rt.ExpectValidateCallerAny()
+	ret := rt.Call(h.MarketStats, nil).(*market.MarketStatsReturn)
+	rt.Verify()
+	return ret
+}
+
 func (h *marketActorTestHarness) cronTick(rt *mock.Runtime) {
 	rt.ExpectValidateCallerAddr(builtin.CronActorAddr)
 	rt.SetCaller(builtin.CronActorAddr, builtin.CronActorCodeID)
@@ -2884,11 +3979,10 @@ func (h *marketActorTestHarness) publishDeals(rt *mock.Runtime, minerAddrs *mine
 	}
 
 	rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
-	rt.ExpectSend(
+	rt.ExpectStaticSend(
 		minerAddrs.provider,
 		builtin.MethodsMiner.ControlAddresses,
 		nil,
-		big.Zero(),
 		&miner.GetControlAddressesReturn{Owner: minerAddrs.owner, Worker: minerAddrs.worker, ControlAddrs: minerAddrs.control},
 		exitcode.Ok,
 	)
@@ -2917,11 +4011,20 @@ func (h *marketActorTestHarness) publishDeals(rt *mock.Runtime, minerAddrs *mine
 	}
 
 	ret := rt.Call(h.PublishStorageDeals, &params)
+	events := rt.EmittedEvents()
 	rt.Verify()
 
 	resp, ok := ret.(*market.PublishStorageDealsReturn)
 	require.True(h.t, ok, "unexpected type returned from call to PublishStorageDeals")
 	require.Len(h.t, resp.IDs, len(publishDealReqs))
+	require.Len(h.t, events, len(publishDealReqs))
+	for i, id := range resp.IDs {
+		require.Equal(h.t, dealEvent(h.t, market.EventDealPublished, id, &publishDealReqs[i].deal), events[i])
+	}
+	require.Len(h.t, resp.ProposalCIDs, len(publishDealReqs))
+	validCount, err := resp.ValidDeals.Count()
+	require.NoError(h.t, err)
+	require.EqualValues(h.t, len(publishDealReqs), validCount)
 
 	// assert state after publishing the deals
 	dealIds := resp.IDs
@@ -2929,6 +4032,13 @@ func (h *marketActorTestHarness) publishDeals(rt *mock.Runtime, minerAddrs *mine
 		expected := publishDealReqs[i].deal
 		p := h.getDealProposal(rt, deaId)
 
+		valid, err := resp.ValidDeals.IsSet(uint64(i))
+		require.NoError(h.t, err)
+		require.True(h.t, valid)
+		pcid, err := p.Cid()
+		require.NoError(h.t, err)
+		require.Equal(h.t, pcid, resp.ProposalCIDs[i])
+
 		require.Equal(h.t, expected.StartEpoch, p.StartEpoch)
 		require.Equal(h.t, expected.EndEpoch, p.EndEpoch)
 		require.Equal(h.t, expected.PieceCID, p.PieceCID)
@@ -2976,6 +4086,38 @@ func (h *marketActorTestHarness) activateDeals(rt *mock.Runtime, sectorExpiry ab
 	}
 }
 
+func (h *marketActorTestHarness) reserveCollateral(rt *mock.Runtime, provider address.Address, reservations ...market.CollateralReservation) {
+	rt.SetCaller(provider, builtin.StorageMinerActorCodeID)
+	rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
+
+	ret := rt.Call(h.ReserveCollateral, &market.ReserveCollateralParams{Reservations: reservations})
+	rt.Verify()
+	require.Nil(h.t, ret)
+}
+
+func (h *marketActorTestHarness) releaseCollateral(rt *mock.Runtime, provider address.Address, dealIDs ...abi.DealID) {
+	rt.SetCaller(provider, builtin.StorageMinerActorCodeID)
+	rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
+
+	ret := rt.Call(h.ReleaseCollateral, &market.ReleaseCollateralParams{DealIDs: dealIDs})
+	rt.Verify()
+	require.Nil(h.t, ret)
+}
+
+// Returns the collateral reserved against a deal, which is zero if none.
+func (h *marketActorTestHarness) getReservedCollateral(rt *mock.Runtime, dealID abi.DealID) abi.TokenAmount {
+	var st market.State
+	rt.GetState(&st)
+
+	reservations, err := adt.AsMap(adt.AsStore(rt), st.CollateralReservations, market.CollateralReservationsHamtBitwidth)
+	require.NoError(h.t, err)
+
+	amount := big.Zero()
+	_, err = reservations.Get(abi.UIntKey(uint64(dealID)), &amount)
+	require.NoError(h.t, err)
+	return amount
+}
+
 func (h *marketActorTestHarness) getDealProposal(rt *mock.Runtime, dealID abi.DealID) *market.DealProposal {
 	var st market.State
 	rt.GetState(&st)
@@ -3236,11 +4378,10 @@ func mkTerminateDealParams(epoch abi.ChainEpoch, dealIds ...abi.DealID) *market.
 
 func expectGetControlAddresses(rt *mock.Runtime, provider address.Address, owner, worker address.Address, controls ...address.Address) {
 	result := &miner.GetControlAddressesReturn{Owner: owner, Worker: worker, ControlAddrs: controls}
-	rt.ExpectSend(
+	rt.ExpectStaticSend(
 		provider,
 		builtin.MethodsMiner.ControlAddresses,
 		nil,
-		big.Zero(),
 		result,
 		exitcode.Ok,
 	)
@@ -3271,3 +4412,42 @@ func expectQueryNetworkInfo(rt *mock.Runtime, h *marketActorTestHarness) {
 		exitcode.Ok,
 	)
 }
+
+func assertValidDeals(t *testing.T, ret *market.PublishStorageDealsReturn, expected ...uint64) {
+	valid, err := ret.ValidDeals.All(uint64(len(expected)) + 1)
+	require.NoError(t, err)
+	require.Equal(t, expected, valid)
+}
+
+// Builds the entries of the event the market emits for a deal.
+func dealEvent(t testing.TB, eventType string, id abi.DealID, deal *market.DealProposal) []runtime.EventEntry {
+	dealID := cbg.CborInt(id)
+	entries, err := builtin.NewEventBuilder(eventType).
+		FieldIndexed("id", &dealID).
+		FieldIndexed("client", &deal.Client).
+		FieldIndexed("provider", &deal.Provider).
+		Entries()
+	require.NoError(t, err)
+	return entries
+}
+
+// Builds the entries of the event the market emits for a deal which timed out before activation.
+func expectDealTerminationNotification(rt *mock.Runtime, client address.Address, dealID abi.DealID, reason market.DealTerminationReason, code exitcode.ExitCode) {
+	rt.ExpectSend(client, builtin.MethodsMultisig.UniversalReceiverHook, &builtin.UniversalReceiverParams{
+		Type:    builtin.UniversalReceiverTypeDealTermination,
+		Payload: mustCbor(&market.DealTerminationNotification{DealID: dealID, Reason: reason}),
+	}, big.Zero(), nil, code)
+}
+
+func dealTimedOutEvent(t *testing.T, id abi.DealID, deal *market.DealProposal, refund, slashed abi.TokenAmount) []runtime.EventEntry {
+	dealID := cbg.CborInt(id)
+	entries, err := builtin.NewEventBuilder(market.EventDealTimedOut).
+		FieldIndexed("id", &dealID).
+		FieldIndexed("client", &deal.Client).
+		FieldIndexed("provider", &deal.Provider).
+		Field("client-refund", &refund).
+		Field("provider-slashed", &slashed).
+		Entries()
+	require.NoError(t, err)
+	return entries
+}
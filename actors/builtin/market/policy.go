@@ -23,37 +23,88 @@ var DealMinDuration = abi.ChainEpoch(180 * builtin.EpochsInDay) // PARAM_SPEC
 // Maximum deal duration
 var DealMaxDuration = abi.ChainEpoch(540 * builtin.EpochsInDay) // PARAM_SPEC
 
+// Period after a deal's start epoch during which it may still be activated.
+// A deal not activated by the end of this period times out and the provider's collateral is slashed.
+// A deal activated within this period is paid and weighted from its activation epoch; the client's storage fee
+// for the epochs of its term that elapsed before activation is unlocked.
+var DealActivationGracePeriod = abi.ChainEpoch(4 * builtin.EpochsInHour) // PARAM_SPEC
+
 // DealMaxLabelSize is the maximum size of a deal label.
 const DealMaxLabelSize = 256
 
+// Maximum number of deals, sectors or reservations in the parameters of a single invocation of a batch method.
+// This matches the number of sectors a miner may address in a single message.
+const BatchItemsMax = 10_000 // PARAM_SPEC
+
 // Bounds (inclusive) on deal duration
 func DealDurationBounds(_ abi.PaddedPieceSize) (min abi.ChainEpoch, max abi.ChainEpoch) {
 	return DealMinDuration, DealMaxDuration
 }
 
-func DealPricePerEpochBounds(_ abi.PaddedPieceSize, _ abi.ChainEpoch) (min abi.TokenAmount, max abi.TokenAmount) {
-	return abi.NewTokenAmount(0), builtin.TotalFilecoin
+// The fraction of normalized circulating supply that must be covered by the total storage fee of a deal.
+// Zero permits free deals.
+var DealMinPriceSupplyTarget = builtin.BigFrac{
+	Numerator:   big.Zero(), // PARAM_SPEC
+	Denominator: big.NewInt(1),
+}
+
+// The fraction of normalized circulating supply that must be covered by client collateral in a deal.
+var ClientCollateralSupplyTarget = builtin.BigFrac{
+	Numerator:   big.Zero(), // PARAM_SPEC
+	Denominator: big.NewInt(1),
+}
+
+// Bounds (inclusive) on the storage price per epoch of a deal, given the network conditions at which it is published.
+// The minimum price spreads DealMinPriceSupplyTarget of the deal's normalized circulating supply over its duration.
+func DealPriceBounds(pieceSize abi.PaddedPieceSize, duration abi.ChainEpoch, conditions ProposalPolicy) (min, max abi.TokenAmount) {
+	minTotal := supplyTargetForDeal(DealMinPriceSupplyTarget, pieceSize, conditions)
+	if duration <= 0 {
+		return minTotal, builtin.TotalFilecoin
+	}
+	// Round up so that the total fee covers the target.
+	durationInt := big.NewInt(int64(duration))
+	minPrice := big.Div(big.Sub(big.Add(minTotal, durationInt), big.NewInt(1)), durationInt)
+	return minPrice, builtin.TotalFilecoin
+}
+
+// Bounds (inclusive) on the provider and client collateral of a deal, given the network conditions at which it is published.
+func DealCollateralBounds(pieceSize abi.PaddedPieceSize, verified bool, duration abi.ChainEpoch, conditions ProposalPolicy) (
+	providerMin, providerMax, clientMin, clientMax abi.TokenAmount) {
+	providerMin, providerMax = DealProviderCollateralBounds(pieceSize, verified, conditions.NetworkRawPower,
+		conditions.NetworkQAPower, conditions.BaselinePower, conditions.CirculatingSupply)
+	clientMin, clientMax = DealClientCollateralBounds(pieceSize, duration, conditions)
+	return
 }
 
 func DealProviderCollateralBounds(pieceSize abi.PaddedPieceSize, verified bool, networkRawPower, networkQAPower, baselinePower abi.StoragePower,
 	networkCirculatingSupply abi.TokenAmount) (min, max abi.TokenAmount) {
-	// minimumProviderCollateral = ProviderCollateralSupplyTarget * normalizedCirculatingSupply
+	minCollateral := supplyTargetForDeal(ProviderCollateralSupplyTarget, pieceSize, ProposalPolicy{
+		NetworkRawPower:   networkRawPower,
+		NetworkQAPower:    networkQAPower,
+		BaselinePower:     baselinePower,
+		CirculatingSupply: networkCirculatingSupply,
+	})
+	return minCollateral, builtin.TotalFilecoin
+}
+
+func DealClientCollateralBounds(pieceSize abi.PaddedPieceSize, _ abi.ChainEpoch, conditions ProposalPolicy) (min abi.TokenAmount, max abi.TokenAmount) {
+	return supplyTargetForDeal(ClientCollateralSupplyTarget, pieceSize, conditions), builtin.TotalFilecoin
+}
+
+// Computes a target fraction of the normalized circulating supply for a deal.
+func supplyTargetForDeal(target builtin.BigFrac, pieceSize abi.PaddedPieceSize, conditions ProposalPolicy) abi.TokenAmount {
+	// supplyTarget = target * normalizedCirculatingSupply
 	// normalizedCirculatingSupply = networkCirculatingSupply * dealPowerShare
 	// dealPowerShare = dealRawPower / max(BaselinePower(t), NetworkRawPower(t), dealRawPower)
 
-	lockTargetNum := big.Mul(ProviderCollateralSupplyTarget.Numerator, networkCirculatingSupply)
-	lockTargetDenom := ProviderCollateralSupplyTarget.Denominator
+	lockTargetNum := big.Mul(target.Numerator, conditions.CirculatingSupply)
+	lockTargetDenom := target.Denominator
 	powerShareNum := big.NewIntUnsigned(uint64(pieceSize))
-	powerShareDenom := big.Max(big.Max(networkRawPower, baselinePower), powerShareNum)
+	powerShareDenom := big.Max(big.Max(conditions.NetworkRawPower, conditions.BaselinePower), powerShareNum)
 
 	num := big.Mul(lockTargetNum, powerShareNum)
 	denom := big.Mul(lockTargetDenom, powerShareDenom)
-	minCollateral := big.Div(num, denom)
-	return minCollateral, builtin.TotalFilecoin
-}
-
-func DealClientCollateralBounds(_ abi.PaddedPieceSize, _ abi.ChainEpoch) (min abi.TokenAmount, max abi.TokenAmount) {
-	return abi.NewTokenAmount(0), builtin.TotalFilecoin
+	return big.Div(num, denom)
 }
 
 // Penalty to provider deal collateral if the deadline expires before sector commitment.
@@ -61,9 +112,16 @@ func CollateralPenaltyForDealActivationMissed(providerCollateral abi.TokenAmount
 	return providerCollateral
 }
 
-// Computes the weight for a deal proposal, which is a function of its size and duration.
-func DealWeight(proposal *DealProposal) abi.DealWeight {
-	dealDuration := big.NewInt(int64(proposal.Duration()))
+// Computes the weight for a deal proposal activated in a sector at some epoch, which is a function of its size
+// and the duration for which the sector holds it: from the later of its start and activation, to its end.
+// A deal activated late, within its activation grace period, thus weighs no more than the sector's remaining
+// spacetime after activation.
+func DealWeight(proposal *DealProposal, activation abi.ChainEpoch) abi.DealWeight {
+	start := proposal.StartEpoch
+	if activation > start {
+		start = activation
+	}
+	dealDuration := big.NewInt(int64(proposal.EndEpoch - start))
 	dealSize := big.NewIntUnsigned(uint64(proposal.PieceSize))
 	dealSpaceTime := big.Mul(dealDuration, dealSize)
 	return dealSpaceTime
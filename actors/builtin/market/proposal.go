@@ -0,0 +1,81 @@
+package market
+
+import (
+	"github.com/filecoin-project/go-state-types/abi"
+	"golang.org/x/xerrors"
+)
+
+// Errors identifying the rule that a deal proposal violates.
+// Errors returned from ValidateProposal wrap exactly one of these, and may be tested with xerrors.Is.
+var (
+	ErrProposalLabelTooLong                  = xerrors.New("deal label too long")
+	ErrProposalInvalidPieceSize              = xerrors.New("invalid piece size")
+	ErrProposalInvalidPieceCID               = xerrors.New("invalid piece CID")
+	ErrProposalEndBeforeStart                = xerrors.New("proposal ends before it starts")
+	ErrProposalStartElapsed                  = xerrors.New("deal start epoch has already elapsed")
+	ErrProposalDurationOutOfBounds           = xerrors.New("deal duration out of bounds")
+	ErrProposalPriceOutOfBounds              = xerrors.New("storage price out of bounds")
+	ErrProposalProviderCollateralOutOfBounds = xerrors.New("provider collateral out of bounds")
+	ErrProposalClientCollateralOutOfBounds   = xerrors.New("client collateral out of bounds")
+)
+
+// Network conditions against which the price and collateral bounds of a deal proposal are evaluated.
+// The market actor takes these from the power and reward actors at the time a deal is published.
+type ProposalPolicy struct {
+	NetworkRawPower   abi.StoragePower
+	NetworkQAPower    abi.StoragePower
+	BaselinePower     abi.StoragePower
+	CirculatingSupply abi.TokenAmount
+}
+
+// Checks that a deal proposal satisfies every rule the market actor applies when it is published at an epoch,
+// other than the client's signature and the parties' balances.
+// This depends only on its arguments, so a client may check a proposal before signing it.
+func ValidateProposal(proposal *DealProposal, epoch abi.ChainEpoch, policy ProposalPolicy) error {
+	if len(proposal.Label) > DealMaxLabelSize {
+		return xerrors.Errorf("label of %d bytes exceeds %d: %w", len(proposal.Label), DealMaxLabelSize, ErrProposalLabelTooLong)
+	}
+
+	if err := proposal.PieceSize.Validate(); err != nil {
+		return xerrors.Errorf("%s: %w", err, ErrProposalInvalidPieceSize)
+	}
+
+	if !proposal.PieceCID.Defined() {
+		return xerrors.Errorf("piece CID undefined: %w", ErrProposalInvalidPieceCID)
+	}
+
+	if proposal.PieceCID.Prefix() != PieceCIDPrefix {
+		return xerrors.Errorf("piece CID had wrong prefix: %w", ErrProposalInvalidPieceCID)
+	}
+
+	if proposal.EndEpoch <= proposal.StartEpoch {
+		return xerrors.Errorf("start %d, end %d: %w", proposal.StartEpoch, proposal.EndEpoch, ErrProposalEndBeforeStart)
+	}
+
+	if epoch > proposal.StartEpoch {
+		return xerrors.Errorf("start %d, current epoch %d: %w", proposal.StartEpoch, epoch, ErrProposalStartElapsed)
+	}
+
+	minDuration, maxDuration := DealDurationBounds(proposal.PieceSize)
+	if proposal.Duration() < minDuration || proposal.Duration() > maxDuration {
+		return xerrors.Errorf("duration %d not in [%d, %d]: %w", proposal.Duration(), minDuration, maxDuration, ErrProposalDurationOutOfBounds)
+	}
+
+	minPrice, maxPrice := DealPriceBounds(proposal.PieceSize, proposal.Duration(), policy)
+	if proposal.StoragePricePerEpoch.LessThan(minPrice) || proposal.StoragePricePerEpoch.GreaterThan(maxPrice) {
+		return xerrors.Errorf("price %v not in [%v, %v]: %w", proposal.StoragePricePerEpoch, minPrice, maxPrice, ErrProposalPriceOutOfBounds)
+	}
+
+	minProviderCollateral, maxProviderCollateral, minClientCollateral, maxClientCollateral :=
+		DealCollateralBounds(proposal.PieceSize, proposal.VerifiedDeal, proposal.Duration(), policy)
+	if proposal.ProviderCollateral.LessThan(minProviderCollateral) || proposal.ProviderCollateral.GreaterThan(maxProviderCollateral) {
+		return xerrors.Errorf("provider collateral %v not in [%v, %v]: %w",
+			proposal.ProviderCollateral, minProviderCollateral, maxProviderCollateral, ErrProposalProviderCollateralOutOfBounds)
+	}
+
+	if proposal.ClientCollateral.LessThan(minClientCollateral) || proposal.ClientCollateral.GreaterThan(maxClientCollateral) {
+		return xerrors.Errorf("client collateral %v not in [%v, %v]: %w",
+			proposal.ClientCollateral, minClientCollateral, maxClientCollateral, ErrProposalClientCollateralOutOfBounds)
+	}
+	return nil
+}
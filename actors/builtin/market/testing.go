@@ -50,6 +50,10 @@ func CheckStateInvariants(st *State, store adt.Store, balance abi.TokenAmount, c
 		st.TotalClientStorageFee.GreaterThanEqual(big.Zero()),
 		"negative total client storage fee: %v", st.TotalClientLockedCollateral)
 
+	acc.Require(
+		st.TotalProviderReservedCollateral.GreaterThanEqual(big.Zero()),
+		"negative total provider reserved collateral: %v", st.TotalProviderReservedCollateral)
+
 	//
 	// Proposals
 	//
@@ -143,6 +147,8 @@ func CheckStateInvariants(st *State, store adt.Store, balance abi.TokenAmount, c
 			return nil
 		})
 		acc.RequireNoError(err, "error iterating deal states")
+		acc.Require(dealStateCount == st.ActiveDeals, "deal state count, %d, not equal to recorded active deals, %d",
+			dealStateCount, st.ActiveDeals)
 	}
 
 	//
@@ -150,10 +156,10 @@ func CheckStateInvariants(st *State, store adt.Store, balance abi.TokenAmount, c
 	//
 
 	pendingProposalCount := uint64(0)
-	if pendingProposals, err := adt.AsMap(store, st.PendingProposals, builtin.DefaultHamtBitwidth); err != nil {
+	if pendingProposals, err := adt.AsSet(store, st.PendingProposals, PendingProposalsHamtBitwidth); err != nil {
 		acc.Addf("error loading pending proposals: %v", err)
 	} else {
-		err = pendingProposals.ForEach(nil, func(key string) error {
+		err = pendingProposals.ForEach(func(key string) error {
 			proposalCID, err := cid.Parse([]byte(key))
 			if err != nil {
 				return err
@@ -200,17 +206,20 @@ func CheckStateInvariants(st *State, store adt.Store, balance abi.TokenAmount, c
 		})
 		acc.RequireNoError(err, "error iterating locked table")
 
-		// lockTable total should be sum of client and provider locked plus client storage fee
-		expectedLockTotal := big.Sum(st.TotalProviderLockedCollateral, st.TotalClientLockedCollateral, st.TotalClientStorageFee)
+		// lockTable total should be sum of client and provider locked plus client storage fee and provider reservations
+		expectedLockTotal := big.Sum(st.TotalProviderLockedCollateral, st.TotalClientLockedCollateral, st.TotalClientStorageFee,
+			st.TotalProviderReservedCollateral)
 		acc.Require(lockedTotal.Equals(expectedLockTotal),
-			"locked total, %s, does not sum to provider locked, %s, client locked, %s, and client storage fee, %s",
-			lockedTotal, st.TotalProviderLockedCollateral, st.TotalClientLockedCollateral, st.TotalClientStorageFee)
+			"locked total, %s, does not sum to provider locked, %s, client locked, %s, client storage fee, %s, and provider reserved, %s",
+			lockedTotal, st.TotalProviderLockedCollateral, st.TotalClientLockedCollateral, st.TotalClientStorageFee,
+			st.TotalProviderReservedCollateral)
 
 		// assert escrow <= actor balance
 		// lockTable item <= escrow item and escrowTotal <= balance implies lockTable total <= balance
 		escrowTotal, err := escrowTable.Total()
 		acc.RequireNoError(err, "error calculating escrow total")
 		acc.Require(escrowTotal.LessThanEqual(balance), "escrow total, %v, greater than actor balance, %v", escrowTotal, balance)
+		acc.Require(escrowTotal.Equals(st.TotalEscrow), "escrow total, %v, not equal to recorded total escrow, %v", escrowTotal, st.TotalEscrow)
 		acc.Require(escrowTotal.GreaterThanEqual(totalProposalCollateral), "escrow total, %v, less than sum of proposal collateral, %v", escrowTotal, totalProposalCollateral)
 	}
 
@@ -220,7 +229,7 @@ func CheckStateInvariants(st *State, store adt.Store, balance abi.TokenAmount, c
 
 	dealOpEpochCount := uint64(0)
 	dealOpCount := uint64(0)
-	if dealOps, err := AsSetMultimap(store, st.DealOpsByEpoch, builtin.DefaultHamtBitwidth, builtin.DefaultHamtBitwidth); err != nil {
+	if dealOps, err := AsSetMultimap(store, st.DealOpsByEpoch, DealOpsByEpochHamtBitwidth, DealOpsByEpochHamtBitwidth); err != nil {
 		acc.Addf("error loading deal ops: %v", err)
 	} else {
 		// get into internals just to iterate through full data structure
@@ -245,6 +254,54 @@ func CheckStateInvariants(st *State, store adt.Store, balance abi.TokenAmount, c
 
 	acc.Require(len(expectedDealOps) == 0, "missing deal ops for proposals: %v", expectedDealOps)
 
+	//
+	// Escrow Contributions
+	//
+
+	if contributions, err := AsEscrowContributions(store, st.EscrowContributions); err != nil {
+		acc.Addf("error loading escrow contributions: %v", err)
+	} else {
+		err = contributions.ForEachRecipient(func(recipient address.Address) error {
+			acc.Require(recipient.Protocol() == address.ID, "escrow contribution recipient %v is not an ID address", recipient)
+			return contributions.ForEach(recipient, func(payer address.Address, amount abi.TokenAmount) error {
+				acc.Require(payer.Protocol() == address.ID, "escrow contribution payer %v is not an ID address", payer)
+				acc.Require(payer != recipient, "escrow contribution from %v to itself", payer)
+				acc.Require(amount.GreaterThan(big.Zero()), "escrow contribution from %v to %v is not positive: %v", payer, recipient, amount)
+				return nil
+			})
+		})
+		acc.RequireNoError(err, "error iterating escrow contributions")
+	}
+
+	//
+	// Collateral Reservations
+	//
+
+	if reservations, err := adt.AsMap(store, st.CollateralReservations, CollateralReservationsHamtBitwidth); err != nil {
+		acc.Addf("error loading collateral reservations: %v", err)
+	} else {
+		reservedTotal := big.Zero()
+		var amount abi.TokenAmount
+		err = reservations.ForEach(&amount, func(key string) error {
+			dealID, err := abi.ParseUIntKey(key)
+			if err != nil {
+				return errors.Wrapf(err, "collateral reservation has key that is not an int: %s", key)
+			}
+			reservedTotal = big.Add(reservedTotal, amount)
+			acc.Require(amount.GreaterThan(big.Zero()), "collateral reservation for deal %d is not positive: %v", dealID, amount)
+
+			deal, found := proposalStats[abi.DealID(dealID)]
+			acc.Require(found, "collateral reservation for deal %d with missing proposal", dealID)
+			if found {
+				acc.Require(deal.SectorStartEpoch == epochUndefined, "collateral reservation for activated deal %d", dealID)
+			}
+			return nil
+		})
+		acc.RequireNoError(err, "error iterating collateral reservations")
+		acc.Require(reservedTotal.Equals(st.TotalProviderReservedCollateral),
+			"collateral reservations total, %v, not equal to recorded total reserved, %v", reservedTotal, st.TotalProviderReservedCollateral)
+	}
+
 	return &StateSummary{
 		Deals:                proposalStats,
 		PendingProposalCount: pendingProposalCount,
@@ -10,15 +10,24 @@ const (
 	MethodConstructor = builtin0.MethodConstructor
 )
 
-var MethodsAccount = struct {
+var MethodsSystem = struct {
 	Constructor   abi.MethodNum
-	PubkeyAddress abi.MethodNum
+	UpgradeActors abi.MethodNum
 }{MethodConstructor, 2}
 
+var MethodsAccount = struct {
+	Constructor           abi.MethodNum
+	PubkeyAddress         abi.MethodNum
+	UniversalReceiverHook abi.MethodNum
+}{MethodConstructor, 2, 3}
+
 var MethodsInit = struct {
-	Constructor abi.MethodNum
-	Exec        abi.MethodNum
-}{MethodConstructor, 2}
+	Constructor         abi.MethodNum
+	Exec                abi.MethodNum
+	RecordTombstone     abi.MethodNum
+	LookupRobustAddress abi.MethodNum
+	LookupActorStatus   abi.MethodNum
+}{MethodConstructor, 2, 3, 4, 5}
 
 var MethodsCron = struct {
 	Constructor abi.MethodNum
@@ -30,7 +39,8 @@ var MethodsReward = struct {
 	AwardBlockReward abi.MethodNum
 	ThisEpochReward  abi.MethodNum
 	UpdateNetworkKPI abi.MethodNum
-}{MethodConstructor, 2, 3, 4}
+	BurnFunds        abi.MethodNum
+}{MethodConstructor, 2, 3, 4, 5}
 
 var MethodsMultisig = struct {
 	Constructor                 abi.MethodNum
@@ -42,14 +52,17 @@ var MethodsMultisig = struct {
 	SwapSigner                  abi.MethodNum
 	ChangeNumApprovalsThreshold abi.MethodNum
 	LockBalance                 abi.MethodNum
-}{MethodConstructor, 2, 3, 4, 5, 6, 7, 8, 9}
+	UniversalReceiverHook       abi.MethodNum
+}{MethodConstructor, 2, 3, 4, 5, 6, 7, 8, 9, 10}
 
 var MethodsPaych = struct {
 	Constructor        abi.MethodNum
 	UpdateChannelState abi.MethodNum
 	Settle             abi.MethodNum
 	Collect            abi.MethodNum
-}{MethodConstructor, 2, 3, 4}
+	AuthorizeSettler   abi.MethodNum
+	RevokeSettler      abi.MethodNum
+}{MethodConstructor, 2, 3, 4, 5, 6}
 
 var MethodsMarket = struct {
 	Constructor              abi.MethodNum
@@ -61,7 +74,13 @@ var MethodsMarket = struct {
 	OnMinerSectorsTerminate  abi.MethodNum
 	ComputeDataCommitment    abi.MethodNum
 	CronTick                 abi.MethodNum
-}{MethodConstructor, 2, 3, 4, 5, 6, 7, 8, 9}
+	AddBalanceFor            abi.MethodNum
+	SettleDealPayments       abi.MethodNum
+	BatchActivateDeals       abi.MethodNum
+	MarketStats              abi.MethodNum
+	ReserveCollateral        abi.MethodNum
+	ReleaseCollateral        abi.MethodNum
+}{MethodConstructor, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
 
 var MethodsPower = struct {
 	Constructor              abi.MethodNum
@@ -73,7 +92,9 @@ var MethodsPower = struct {
 	Deprecated1              abi.MethodNum
 	SubmitPoRepForBulkVerify abi.MethodNum
 	CurrentTotalPower        abi.MethodNum
-}{MethodConstructor, 2, 3, 4, 5, 6, 7, 8, 9}
+	NetworkPowerStats        abi.MethodNum
+	UpdateClaimedPowerBatch  abi.MethodNum
+}{MethodConstructor, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11}
 
 var MethodsMiner = struct {
 	Constructor              abi.MethodNum
@@ -100,13 +121,29 @@ var MethodsMiner = struct {
 	RepayDebt                abi.MethodNum
 	ChangeOwnerAddress       abi.MethodNum
 	DisputeWindowedPoSt      abi.MethodNum
-}{MethodConstructor, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24}
+	ProveReplicaUpdates      abi.MethodNum
+	ChangeBeneficiary        abi.MethodNum
+	GetBeneficiary           abi.MethodNum
+	ProveCommitAggregate     abi.MethodNum
+	GetVestingFunds          abi.MethodNum
+	DeadlineInfo             abi.MethodNum
+	EstimateFaultFee         abi.MethodNum
+	GetSectorInfo            abi.MethodNum
+}{MethodConstructor, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30, 31, 32}
 
 var MethodsVerifiedRegistry = struct {
-	Constructor       abi.MethodNum
-	AddVerifier       abi.MethodNum
-	RemoveVerifier    abi.MethodNum
-	AddVerifiedClient abi.MethodNum
-	UseBytes          abi.MethodNum
-	RestoreBytes      abi.MethodNum
-}{MethodConstructor, 2, 3, 4, 5, 6}
+	Constructor                 abi.MethodNum
+	AddVerifier                 abi.MethodNum
+	RemoveVerifier              abi.MethodNum
+	AddVerifiedClient           abi.MethodNum
+	UseBytes                    abi.MethodNum
+	RestoreBytes                abi.MethodNum
+	RemoveVerifiedClientDataCap abi.MethodNum
+	CreateAllocations           abi.MethodNum
+	ClaimAllocations            abi.MethodNum
+	ExtendClaimTerms            abi.MethodNum
+	RemoveExpiredAllocations    abi.MethodNum
+	RemoveExpiredClaims         abi.MethodNum
+	AddVerifierAllowance        abi.MethodNum
+	ReduceVerifierAllowance     abi.MethodNum
+}{MethodConstructor, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14}
@@ -410,7 +410,7 @@ func TestDeadlines(t *testing.T) {
 			0: bf(6),
 		}, sectorSize, quantSpec)
 		require.Error(t, err)
-		require.Contains(t, err.Error(), "can only terminate live sectors")
+		require.Contains(t, err.Error(), "not all sectors are assigned to the partition")
 	})
 
 	t.Run("fails to terminate missing partition", func(t *testing.T) {
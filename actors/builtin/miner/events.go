@@ -0,0 +1,51 @@
+package miner
+
+import (
+	"github.com/filecoin-project/go-bitfield"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/ipfs/go-cid"
+	cbg "github.com/whyrusleeping/cbor-gen"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+)
+
+// Types of the events emitted by the miner actor.
+const (
+	EventSectorPrecommitted = "sector-precommitted"
+	EventSectorActivated    = "sector-activated"
+	EventSectorTerminated   = "sector-terminated"
+)
+
+// Emits an event of the given type for a sector, indexed by its number.
+func emitSectorEvent(rt Runtime, eventType string, sectorNo abi.SectorNumber) {
+	sector := cbg.CborInt(sectorNo)
+	builtin.NewEventBuilder(eventType).
+		FieldIndexed("sector", &sector).
+		Emit(rt)
+}
+
+// Emits an activation event for a sector, with an indexed entry for the piece of each of its deals.
+func emitSectorActivated(rt Runtime, sectorNo abi.SectorNumber, pieces []cid.Cid) {
+	sector := cbg.CborInt(sectorNo)
+	eb := builtin.NewEventBuilder(EventSectorActivated).
+		FieldIndexed("sector", &sector)
+	for _, piece := range pieces {
+		pieceCID := cbg.CborCid(piece)
+		eb.FieldIndexed("piece-cid", &pieceCID)
+	}
+	eb.Emit(rt)
+}
+
+// Emits a termination event for each sector in a set of declarations.
+func emitSectorsTerminated(rt Runtime, terminated DeadlineSectorMap) {
+	err := terminated.ForEach(func(_ uint64, pm PartitionSectorMap) error {
+		return pm.ForEach(func(_ uint64, sectorNos bitfield.BitField) error {
+			return sectorNos.ForEach(func(sectorNo uint64) error {
+				emitSectorEvent(rt, EventSectorTerminated, abi.SectorNumber(sectorNo))
+				return nil
+			})
+		})
+	})
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to emit sector termination events")
+}
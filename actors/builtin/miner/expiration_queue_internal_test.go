@@ -10,7 +10,7 @@ import (
 )
 
 func TestExpirations(t *testing.T) {
-	quant := QuantSpec{unit: 10, offset: 3}
+	quant := NewQuantSpec(10, 3)
 	sectors := []*SectorOnChainInfo{
 		testSector(7, 1, 0, 0, 0),
 		testSector(8, 2, 0, 0, 0),
@@ -25,6 +25,7 @@ import (
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin/market"
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin/power"
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin/reward"
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin/verifreg"
 	"github.com/filecoin-project/specs-actors/v3/actors/runtime"
 	"github.com/filecoin-project/specs-actors/v3/actors/runtime/proof"
 	. "github.com/filecoin-project/specs-actors/v3/actors/util"
@@ -35,9 +36,9 @@ import (
 type Runtime = runtime.Runtime
 
 const (
-	// The first 1000 actor-specific codes are left open for user error, i.e. things that might
-	// actually happen without programming error in the actor code.
-	//ErrToBeDetermined = exitcode.FirstActorSpecificExitCode + iota
+	// Codes for user error, i.e. things that might actually happen without programming error in the
+	// actor code, are allocated from the miner's range of builtin exit codes.
+	//ErrToBeDetermined = builtin.FirstMinerExitCode + iota
 
 	// The following errors are particular cases of illegal state.
 	// They're not expected to ever happen, but if they do, distinguished codes can help us
@@ -73,6 +74,14 @@ func (a Actor) Exports() []interface{} {
 		22:                        a.RepayDebt,
 		23:                        a.ChangeOwnerAddress,
 		24:                        a.DisputeWindowedPoSt,
+		25:                        a.ProveReplicaUpdates,
+		26:                        a.ChangeBeneficiary,
+		27:                        a.GetBeneficiary,
+		28:                        a.ProveCommitAggregate,
+		29:                        a.GetVestingFunds,
+		30:                        a.DeadlineInfo,
+		31:                        a.EstimateFaultFee,
+		32:                        a.GetSectorInfo,
 	}
 }
 
@@ -84,7 +93,18 @@ func (a Actor) State() cbor.Er {
 	return new(State)
 }
 
+// Limits on the parameters of exported methods, checked before a method is invoked.
+func (a Actor) ParamLimits() map[abi.MethodNum]builtin.ParamLimits {
+	return map[abi.MethodNum]builtin.ParamLimits{
+		builtin.MethodsMiner.ExtendSectorExpiration: {MaxLengths: []builtin.LengthLimit{{Field: "Extensions", Max: DeclarationsMax}}},
+		builtin.MethodsMiner.TerminateSectors:       {MaxLengths: []builtin.LengthLimit{{Field: "Terminations", Max: DeclarationsMax}}},
+		builtin.MethodsMiner.DeclareFaults:          {MaxLengths: []builtin.LengthLimit{{Field: "Faults", Max: DeclarationsMax}}},
+		builtin.MethodsMiner.DeclareFaultsRecovered: {MaxLengths: []builtin.LengthLimit{{Field: "Recoveries", Max: DeclarationsMax}}},
+	}
+}
+
 var _ runtime.VMActor = Actor{}
+var _ builtin.ParamLimiter = Actor{}
 
 /////////////////
 // Constructor //
@@ -146,9 +166,8 @@ type GetControlAddressesReturn = miner2.GetControlAddressesReturn
 
 func (a Actor) ControlAddresses(rt Runtime, _ *abi.EmptyValue) *GetControlAddressesReturn {
 	rt.ValidateImmediateCallerAcceptAny()
-	var st State
-	rt.StateReadonly(&st)
-	info := getMinerInfo(rt, &st)
+	st := ReadState(rt)
+	info := getMinerInfo(rt, st)
 	return &GetControlAddressesReturn{
 		Owner:        info.Owner,
 		Worker:       info.Worker,
@@ -176,9 +195,8 @@ func (a Actor) ChangeWorkerAddress(rt Runtime, params *ChangeWorkerAddressParams
 		controlAddrs = append(controlAddrs, resolved)
 	}
 
-	var st State
-	rt.StateTransaction(&st, func() {
-		info := getMinerInfo(rt, &st)
+	WithState(rt, func(st *State) {
+		info := getMinerInfo(rt, st)
 
 		// Only the Owner is allowed to change the newWorker and control addresses.
 		rt.ValidateImmediateCallerIs(info.Owner)
@@ -203,14 +221,13 @@ func (a Actor) ChangeWorkerAddress(rt Runtime, params *ChangeWorkerAddressParams
 
 // Triggers a worker address change if a change has been requested and its effective epoch has arrived.
 func (a Actor) ConfirmUpdateWorkerKey(rt Runtime, params *abi.EmptyValue) *abi.EmptyValue {
-	var st State
-	rt.StateTransaction(&st, func() {
-		info := getMinerInfo(rt, &st)
+	WithState(rt, func(st *State) {
+		info := getMinerInfo(rt, st)
 
 		// Only the Owner is allowed to change the newWorker.
 		rt.ValidateImmediateCallerIs(info.Owner)
 
-		processPendingWorker(info, rt, &st)
+		processPendingWorker(info, rt, st)
 	})
 
 	return nil
@@ -225,12 +242,14 @@ func (a Actor) ChangeOwnerAddress(rt Runtime, newAddress *addr.Address) *abi.Emp
 	if newAddress.Empty() {
 		rt.Abortf(exitcode.ErrIllegalArgument, "empty address")
 	}
-	if newAddress.Protocol() != addr.ID {
-		rt.Abortf(exitcode.ErrIllegalArgument, "owner address must be an ID address")
+	// Key the proposal by ID address, so a proposal and its confirmation match whichever form each names.
+	newOwner, ok := rt.ResolveAddress(*newAddress)
+	if !ok {
+		rt.Abortf(exitcode.ErrIllegalArgument, "unable to resolve owner address %v", *newAddress)
 	}
-	var st State
-	rt.StateTransaction(&st, func() {
-		info := getMinerInfo(rt, &st)
+	newAddress = &newOwner
+	WithState(rt, func(st *State) {
+		info := getMinerInfo(rt, st)
 		if rt.Caller() == info.Owner || info.PendingOwnerAddress == nil {
 			// Propose new address.
 			rt.ValidateImmediateCallerIs(info.Owner)
@@ -243,6 +262,12 @@ func (a Actor) ChangeOwnerAddress(rt Runtime, newAddress *addr.Address) *abi.Emp
 				rt.Abortf(exitcode.ErrIllegalArgument, "expected confirmation of %v, got %v",
 					info.PendingOwnerAddress, newAddress)
 			}
+			// A beneficiary left at the default of the owner follows the owner.
+			if info.Beneficiary == info.Owner {
+				info.Beneficiary = *info.PendingOwnerAddress
+			}
+			// Any pending beneficiary change was proposed by the old owner.
+			info.PendingBeneficiaryTerm = nil
 			info.Owner = *info.PendingOwnerAddress
 		}
 
@@ -257,6 +282,233 @@ func (a Actor) ChangeOwnerAddress(rt Runtime, newAddress *addr.Address) *abi.Emp
 	return nil
 }
 
+type ChangeBeneficiaryParams struct {
+	NewBeneficiary addr.Address
+	NewQuota       abi.TokenAmount
+	NewExpiration  abi.ChainEpoch
+}
+
+// Proposes or approves a change of beneficiary address and term.
+// If invoked by the owner, proposes a new beneficiary and term, replacing any existing proposal.
+// Proposing the owner as beneficiary requires a zero quota and expiration, and restores unlimited withdrawals.
+// If invoked by the current or the proposed beneficiary, with the same proposal, records that party's approval.
+// The change takes effect once approved by both the current beneficiary and the nominee.
+// The current beneficiary's approval is not required if its term is used up or expired.
+func (a Actor) ChangeBeneficiary(rt Runtime, params *ChangeBeneficiaryParams) *abi.EmptyValue {
+	newBeneficiary, ok := rt.ResolveAddress(params.NewBeneficiary)
+	if !ok {
+		rt.Abortf(exitcode.ErrIllegalArgument, "unable to resolve address %v", params.NewBeneficiary)
+	}
+	if params.NewQuota.LessThan(big.Zero()) {
+		rt.Abortf(exitcode.ErrIllegalArgument, "beneficiary quota %v must not be negative", params.NewQuota)
+	}
+
+	WithState(rt, func(st *State) {
+		info := getMinerInfo(rt, st)
+		caller := rt.Caller()
+		if caller == info.Owner || info.PendingBeneficiaryTerm == nil {
+			// Propose a new beneficiary and term.
+			rt.ValidateImmediateCallerIs(info.Owner)
+			if newBeneficiary == info.Owner {
+				if !params.NewQuota.IsZero() || params.NewExpiration != 0 {
+					rt.Abortf(exitcode.ErrIllegalArgument, "owner beneficiary must have zero quota and expiration, got %v, %d",
+						params.NewQuota, params.NewExpiration)
+				}
+			} else {
+				if !params.NewQuota.GreaterThan(big.Zero()) {
+					rt.Abortf(exitcode.ErrIllegalArgument, "beneficiary quota %v must be positive", params.NewQuota)
+				}
+				if params.NewExpiration <= rt.CurrEpoch() {
+					rt.Abortf(exitcode.ErrIllegalArgument, "beneficiary expiration %d must be after current epoch %d",
+						params.NewExpiration, rt.CurrEpoch())
+				}
+			}
+			info.PendingBeneficiaryTerm = &PendingBeneficiaryChange{
+				NewBeneficiary: newBeneficiary,
+				NewQuota:       params.NewQuota,
+				NewExpiration:  params.NewExpiration,
+			}
+		} else {
+			// Approve the pending proposal.
+			pending := info.PendingBeneficiaryTerm
+			rt.ValidateImmediateCallerIs(info.Beneficiary, pending.NewBeneficiary)
+			if newBeneficiary != pending.NewBeneficiary || !params.NewQuota.Equals(pending.NewQuota) ||
+				params.NewExpiration != pending.NewExpiration {
+				rt.Abortf(exitcode.ErrIllegalArgument, "expected approval of beneficiary %v, quota %v, expiration %d, got %v, %v, %d",
+					pending.NewBeneficiary, pending.NewQuota, pending.NewExpiration, newBeneficiary, params.NewQuota, params.NewExpiration)
+			}
+		}
+
+		// The owner as beneficiary has a zero quota, so never needs to approve its replacement.
+		pending := info.PendingBeneficiaryTerm
+		if caller == info.Beneficiary || info.BeneficiaryTerm.Available(rt.CurrEpoch()).Equals(big.Zero()) {
+			pending.ApprovedByBeneficiary = true
+		}
+		if caller == pending.NewBeneficiary {
+			pending.ApprovedByNominee = true
+		}
+
+		if pending.ApprovedByBeneficiary && pending.ApprovedByNominee {
+			usedQuota := big.Zero()
+			if pending.NewBeneficiary == info.Beneficiary {
+				// Extending or changing the term of the same beneficiary doesn't reset what it has withdrawn.
+				usedQuota = info.BeneficiaryTerm.UsedQuota
+			}
+			info.Beneficiary = pending.NewBeneficiary
+			info.BeneficiaryTerm = BeneficiaryTerm{
+				Quota:      pending.NewQuota,
+				UsedQuota:  usedQuota,
+				Expiration: pending.NewExpiration,
+			}
+			info.PendingBeneficiaryTerm = nil
+		}
+
+		err := st.SaveInfo(adt.AsStore(rt), info)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to save miner info")
+	})
+	return nil
+}
+
+type ActiveBeneficiary struct {
+	Beneficiary addr.Address
+	Term        BeneficiaryTerm
+}
+
+type GetBeneficiaryReturn struct {
+	Active   ActiveBeneficiary
+	Proposed *PendingBeneficiaryChange
+}
+
+// Returns the current beneficiary and term, and any pending proposal to change them.
+func (a Actor) GetBeneficiary(rt Runtime, _ *abi.EmptyValue) *GetBeneficiaryReturn {
+	rt.ValidateImmediateCallerAcceptAny()
+	st := ReadState(rt)
+	info := getMinerInfo(rt, st)
+	return &GetBeneficiaryReturn{
+		Active: ActiveBeneficiary{
+			Beneficiary: info.Beneficiary,
+			Term:        info.BeneficiaryTerm,
+		},
+		Proposed: info.PendingBeneficiaryTerm,
+	}
+}
+
+type GetVestingFundsReturn struct {
+	VestingFunds []VestingFund // Amounts yet to vest, in increasing epoch order
+	LockedFunds  abi.TokenAmount
+}
+
+// Returns the miner's vesting table of locked rewards, with the total amount locked.
+// Amounts scheduled at epochs already passed remain locked until released by the miner's next cron event,
+// or by a withdrawal.
+func (a Actor) GetVestingFunds(rt Runtime, _ *abi.EmptyValue) *GetVestingFundsReturn {
+	rt.ValidateImmediateCallerAcceptAny()
+	st := ReadState(rt)
+	vestingFunds, err := st.LoadVestingFunds(adt.AsStore(rt))
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load vesting funds")
+	return &GetVestingFundsReturn{
+		VestingFunds: vestingFunds.Funds,
+		LockedFunds:  st.LockedFunds,
+	}
+}
+
+type DeadlineInfoParams struct {
+	Deadline uint64
+}
+
+type DeadlineInfoReturn = DeadlineStatus
+
+// Returns the proving status of a deadline: its partitions and sectors, faults and recoveries,
+// and the timing of its current or next challenge window.
+func (a Actor) DeadlineInfo(rt Runtime, params *DeadlineInfoParams) *DeadlineInfoReturn {
+	rt.ValidateImmediateCallerAcceptAny()
+	st := ReadState(rt)
+	status, err := st.DeadlineStatus(adt.AsStore(rt), params.Deadline, rt.CurrEpoch())
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to compute status of deadline %d", params.Deadline)
+	return status
+}
+
+type GetSectorInfoParams struct {
+	SectorNumber abi.SectorNumber
+}
+
+type GetSectorInfoReturn = SectorStatus
+
+// Returns the sealed commitment, lifetime, deals and pledge of a proven sector, and its deadline and partition.
+// Aborts with ErrNotFound if the sector has not been proven, or has expired or been terminated and removed.
+func (a Actor) GetSectorInfo(rt Runtime, params *GetSectorInfoParams) *GetSectorInfoReturn {
+	rt.ValidateImmediateCallerAcceptAny()
+	st := ReadState(rt)
+	status, found, err := st.SectorStatus(adt.AsStore(rt), params.SectorNumber)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load sector %d", params.SectorNumber)
+	if !found {
+		rt.Abortf(exitcode.ErrNotFound, "no sector %d", params.SectorNumber)
+	}
+	return status
+}
+
+type EstimateFaultFeeParams = FaultDeclaration
+
+type EstimateFaultFeeReturn struct {
+	// Power of the sectors that would newly become faulty.
+	FaultyPower PowerPair
+	// Penalty charged when the deadline next closes if the faults are declared now.
+	DeclaredFaultFee abi.TokenAmount
+	// Penalty charged when the deadline next closes if the faults are instead detected by a missed PoSt.
+	DetectedFaultFee abi.TokenAmount
+	// Penalty charged at each subsequent close of the deadline while the sectors remain faulty.
+	ContinuedFaultFee abi.TokenAmount
+}
+
+// Estimates the penalties that a fault declaration for sectors in one partition would incur, using the
+// current smoothed reward and power estimates. Sectors already faulty or terminated incur no additional fee.
+// The declaration is validated as DeclareFaults would, but no state is changed.
+func (a Actor) EstimateFaultFee(rt Runtime, params *EstimateFaultFeeParams) *EstimateFaultFeeReturn {
+	rt.ValidateImmediateCallerAcceptAny()
+	epochReward := requestCurrentEpochBlockReward(rt)
+	pwrTotal := requestCurrentTotalPower(rt)
+
+	store := adt.AsStore(rt)
+	st := ReadState(rt)
+	info := getMinerInfo(rt, st)
+
+	targetDeadline, err := declarationDeadlineInfo(st.ProvingPeriodStart, params.Deadline, rt.CurrEpoch())
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "invalid fault declaration deadline %d", params.Deadline)
+	err = validateFRDeclarationDeadline(targetDeadline)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "failed fault declaration at deadline %d", params.Deadline)
+
+	deadlines, err := st.LoadDeadlines(store)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deadlines")
+	deadline, err := deadlines.LoadDeadline(store, params.Deadline)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deadline %d", params.Deadline)
+	partition, err := deadline.LoadPartition(store, params.Partition)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load partition %d", params.Partition)
+	err = validatePartitionContainsSectors(partition, params.Sectors)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "failed fault declaration")
+
+	// Mirror Partition.RecordFaults: terminated and already-faulty sectors are ignored.
+	newFaults, err := bitfield.SubtractBitField(params.Sectors, partition.Terminated)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to subtract terminations from faults")
+	newFaults, err = bitfield.SubtractBitField(newFaults, partition.Faults)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to subtract existing faults from faults")
+
+	sectors, err := LoadSectors(store, st.Sectors)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load sectors array")
+	newFaultSectors, err := sectors.Load(newFaults)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load fault sectors")
+	faultyPower := PowerForSectors(info.SectorSize, newFaultSectors)
+
+	// Declared faults are already faulty when the deadline closes, so pay the continued fault fee then.
+	// Faults detected by a missed PoSt pay nothing at that deadline (see handleProvingDeadline).
+	fee := PledgePenaltyForContinuedFault(epochReward.ThisEpochRewardSmoothed, pwrTotal.QualityAdjPowerSmoothed, faultyPower.QA)
+	return &EstimateFaultFeeReturn{
+		FaultyPower:       faultyPower,
+		DeclaredFaultFee:  fee,
+		DetectedFaultFee:  big.Zero(),
+		ContinuedFaultFee: fee,
+	}
+}
+
 //type ChangePeerIDParams struct {
 //	NewID abi.PeerID
 //}
@@ -265,9 +517,8 @@ type ChangePeerIDParams = miner0.ChangePeerIDParams
 func (a Actor) ChangePeerID(rt Runtime, params *ChangePeerIDParams) *abi.EmptyValue {
 	checkPeerInfo(rt, params.NewID, nil)
 
-	var st State
-	rt.StateTransaction(&st, func() {
-		info := getMinerInfo(rt, &st)
+	WithState(rt, func(st *State) {
+		info := getMinerInfo(rt, st)
 
 		rt.ValidateImmediateCallerIs(append(info.ControlAddresses, info.Owner, info.Worker)...)
 
@@ -286,9 +537,8 @@ type ChangeMultiaddrsParams = miner0.ChangeMultiaddrsParams
 func (a Actor) ChangeMultiaddrs(rt Runtime, params *ChangeMultiaddrsParams) *abi.EmptyValue {
 	checkPeerInfo(rt, nil, params.NewMultiaddrs)
 
-	var st State
-	rt.StateTransaction(&st, func() {
-		info := getMinerInfo(rt, &st)
+	WithState(rt, func(st *State) {
+		info := getMinerInfo(rt, st)
 
 		rt.ValidateImmediateCallerIs(append(info.ControlAddresses, info.Owner, info.Worker)...)
 
@@ -333,7 +583,6 @@ type SubmitWindowedPoStParams = miner0.SubmitWindowedPoStParams
 func (a Actor) SubmitWindowedPoSt(rt Runtime, params *SubmitWindowedPoStParams) *abi.EmptyValue {
 	currEpoch := rt.CurrEpoch()
 	store := adt.AsStore(rt)
-	var st State
 
 	if params.Deadline >= WPoStPeriodDeadlines {
 		rt.Abortf(exitcode.ErrIllegalArgument, "invalid deadline %d of %d", params.Deadline, WPoStPeriodDeadlines)
@@ -347,8 +596,8 @@ func (a Actor) SubmitWindowedPoSt(rt Runtime, params *SubmitWindowedPoStParams)
 
 	var postResult *PoStResult
 	var info *MinerInfo
-	rt.StateTransaction(&st, func() {
-		info = getMinerInfo(rt, &st)
+	WithState(rt, func(st *State) {
+		info = getMinerInfo(rt, st)
 		maxProofSize, err := info.WindowPoStProofType.ProofSize()
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to determine max window post proof size")
 
@@ -374,10 +623,14 @@ func (a Actor) SubmitWindowedPoSt(rt Runtime, params *SubmitWindowedPoStParams)
 		}
 
 		// Validate that the miner didn't try to prove too many partitions at once.
-		submissionPartitionLimit := loadPartitionsSectorsMax(info.WindowPoStPartitionSectors)
+		submissionPartitionLimit := min64(loadPartitionsSectorsMax(info.WindowPoStPartitionSectors), PoStedPartitionsMax)
 		if uint64(len(params.Partitions)) > submissionPartitionLimit {
 			rt.Abortf(exitcode.ErrIllegalArgument, "too many partitions %d, limit %d", len(params.Partitions), submissionPartitionLimit)
 		}
+		for _, partition := range params.Partitions {
+			err := IsValidForChain(partition.Skipped)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "invalid skipped sectors for partition %d", partition.Index)
+		}
 
 		currDeadline := st.DeadlineInfo(currEpoch)
 		// Check that the miner state indicates that the current proving deadline has started.
@@ -470,7 +723,7 @@ func (a Actor) SubmitWindowedPoSt(rt Runtime, params *SubmitWindowedPoStParams)
 	// https://github.com/filecoin-project/specs-actors/issues/414
 	requestUpdatePower(rt, postResult.PowerDelta)
 
-	rt.StateReadonly(&st)
+	st := ReadState(rt)
 	err := st.CheckBalanceInvariants(rt.CurrentBalance())
 	builtin.RequireNoErr(rt, err, ErrBalanceInvariantBroken, "balance invariants broken")
 
@@ -504,13 +757,12 @@ func (a Actor) DisputeWindowedPoSt(rt Runtime, params *DisputeWindowedPoStParams
 	toReward := abi.NewTokenAmount(0)
 	pledgeDelta := abi.NewTokenAmount(0)
 	powerDelta := NewPowerPairZero()
-	var st State
-	rt.StateTransaction(&st, func() {
+	WithState(rt, func(st *State) {
 		if !deadlineAvailableForOptimisticPoStDispute(st.ProvingPeriodStart, params.Deadline, currEpoch) {
 			rt.Abortf(exitcode.ErrForbidden, "can only dispute window posts during the dispute window (%d epochs after the challenge window closes)", WPoStDisputeWindow)
 		}
 
-		info := getMinerInfo(rt, &st)
+		info := getMinerInfo(rt, st)
 		penalisedPower := NewPowerPairZero()
 		store := adt.AsStore(rt)
 
@@ -621,9 +873,9 @@ func (a Actor) DisputeWindowedPoSt(rt Runtime, params *DisputeWindowedPoStParams
 			toBurn = big.Add(toBurn, toReward)
 		}
 	}
-	burnFunds(rt, toBurn)
+	burnFunds(rt, toBurn, builtin.BurnReasonInvalidPoSt)
 	notifyPledgeChanged(rt, pledgeDelta)
-	rt.StateReadonly(&st)
+	st := ReadState(rt)
 
 	err := st.CheckBalanceInvariants(rt.CurrentBalance())
 	builtin.RequireNoErr(rt, err, ErrBalanceInvariantBroken, "balance invariants broken")
@@ -634,20 +886,10 @@ func (a Actor) DisputeWindowedPoSt(rt Runtime, params *DisputeWindowedPoStParams
 // Sector Commitment //
 ///////////////////////
 
-//type SectorPreCommitInfo struct {
-//	SealProof       abi.RegisteredSealProof
-//	SectorNumber    abi.SectorNumber
-//	SealedCID       cid.Cid `checked:"true"` // CommR
-//	SealRandEpoch   abi.ChainEpoch
-//	DealIDs         []abi.DealID
-//	Expiration      abi.ChainEpoch
-//	ReplaceCapacity bool // Whether to replace a "committed capacity" no-deal sector (requires non-empty DealIDs)
-//	// The committed capacity sector to replace, and it's deadline/partition location
-//	ReplaceSectorDeadline  uint64
-//	ReplaceSectorPartition uint64
-//	ReplaceSectorNumber    abi.SectorNumber
-//}
-type PreCommitSectorParams = miner0.SectorPreCommitInfo
+// Changed since v2:
+// - AllocationClaims added
+// - CollateralReservations added
+type PreCommitSectorParams = SectorPreCommitInfo
 
 // Proposals must be posted on chain via sma.PublishStorageDeals before PreCommitSector.
 // Optimization: PreCommitSector could contain a list of deals that are not published yet.
@@ -688,6 +930,21 @@ func (a Actor) PreCommitSector(rt Runtime, params *PreCommitSectorParams) *abi.E
 	if params.ReplaceSectorNumber > abi.MaxSectorNumber {
 		rt.Abortf(exitcode.ErrIllegalArgument, "invalid sector number %d", params.ReplaceSectorNumber)
 	}
+	validateAllocationClaims(rt, params.AllocationClaims, len(params.DealIDs))
+	sectorDeals := make(map[abi.DealID]struct{}, len(params.DealIDs))
+	for _, dealID := range params.DealIDs {
+		sectorDeals[dealID] = struct{}{}
+	}
+	reservedDeals := make(map[abi.DealID]struct{}, len(params.CollateralReservations))
+	for _, reservation := range params.CollateralReservations {
+		if _, ok := sectorDeals[reservation.DealID]; !ok {
+			rt.Abortf(exitcode.ErrIllegalArgument, "collateral reserved against deal %d not in sector", reservation.DealID)
+		}
+		if _, dup := reservedDeals[reservation.DealID]; dup {
+			rt.Abortf(exitcode.ErrIllegalArgument, "collateral reserved against deal %d more than once", reservation.DealID)
+		}
+		reservedDeals[reservation.DealID] = struct{}{}
+	}
 
 	// gather information from other actors
 
@@ -703,21 +960,21 @@ func (a Actor) PreCommitSector(rt Runtime, params *PreCommitSectorParams) *abi.E
 		rt.Abortf(exitcode.ErrIllegalState, "deal weight request returned no records")
 	}
 	dealWeight := dealWeights.Sectors[0]
+	requestReserveCollateral(rt, params.CollateralReservations)
 
 	store := adt.AsStore(rt)
-	var st State
 	var err error
 	newlyVested := big.Zero()
 	feeToBurn := abi.NewTokenAmount(0)
-	rt.StateTransaction(&st, func() {
+	WithState(rt, func(st *State) {
 		// available balance already accounts for fee debt so it is correct to call
 		// this before RepayDebts. We would have to
 		// subtract fee debt explicitly if we called this after.
 		availableBalance, err := st.GetAvailableBalance(rt.CurrentBalance())
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to calculate available balance")
-		feeToBurn = RepayDebtsOrAbort(rt, &st)
+		feeToBurn = RepayDebtsOrAbort(rt, st)
 
-		info := getMinerInfo(rt, &st)
+		info := getMinerInfo(rt, st)
 		rt.ValidateImmediateCallerIs(append(info.ControlAddresses, info.Owner, info.Worker)...)
 
 		if ConsensusFaultActive(info, rt.CurrEpoch()) {
@@ -727,11 +984,11 @@ func (a Actor) PreCommitSector(rt Runtime, params *PreCommitSectorParams) *abi.E
 		// From network version 7, the pre-commit seal type must have the same Window PoSt proof type as the miner,
 		// rather than be exactly the same seal type.
 		// This permits a transition window from V1 to V1_1 seal types (which share Window PoSt proof type).
-		sectorWPoStProof, err := params.SealProof.RegisteredWindowPoStProof()
+		sealProofInfo, err := power.LookupSealProof(params.SealProof)
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "failed to lookup Window PoSt proof type for sector seal proof %d", params.SealProof)
-		if sectorWPoStProof != info.WindowPoStProofType {
+		if sealProofInfo.WindowPoStProof != info.WindowPoStProofType {
 			rt.Abortf(exitcode.ErrIllegalArgument, "sector Window PoSt proof type %d must match miner Window PoSt proof type %d (seal proof type %d)",
-				sectorWPoStProof, info.WindowPoStProofType, params.SealProof)
+				sealProofInfo.WindowPoStProof, info.WindowPoStProofType, params.SealProof)
 		}
 
 		dealCountMax := SectorDealsMax(info.SectorSize)
@@ -755,7 +1012,7 @@ func (a Actor) PreCommitSector(rt Runtime, params *PreCommitSectorParams) *abi.E
 		}
 
 		if params.ReplaceCapacity {
-			validateReplaceSector(rt, &st, store, params)
+			validateReplaceSector(rt, st, store, params)
 		}
 
 		duration := params.Expiration - rt.CurrEpoch()
@@ -769,7 +1026,7 @@ func (a Actor) PreCommitSector(rt Runtime, params *PreCommitSectorParams) *abi.E
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to add pre-commit deposit %v", depositReq)
 
 		if err := st.PutPrecommittedSector(store, &SectorPreCommitOnChainInfo{
-			Info:               SectorPreCommitInfo(*params),
+			Info:               *params,
 			PreCommitDeposit:   depositReq,
 			PreCommitEpoch:     rt.CurrEpoch(),
 			DealWeight:         dealWeight.DealWeight,
@@ -791,12 +1048,13 @@ func (a Actor) PreCommitSector(rt Runtime, params *PreCommitSectorParams) *abi.E
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to add pre-commit expiry to queue")
 	})
 
-	burnFunds(rt, feeToBurn)
-	rt.StateReadonly(&st)
+	burnFunds(rt, feeToBurn, builtin.BurnReasonFeeDebt)
+	st := ReadState(rt)
 	err = st.CheckBalanceInvariants(rt.CurrentBalance())
 	builtin.RequireNoErr(rt, err, ErrBalanceInvariantBroken, "balance invariants broken")
 
 	notifyPledgeChanged(rt, newlyVested.Neg())
+	emitSectorEvent(rt, EventSectorPrecommitted, params.SectorNumber)
 
 	return nil
 }
@@ -820,8 +1078,7 @@ func (a Actor) ProveCommitSector(rt Runtime, params *ProveCommitSectorParams) *a
 	store := adt.AsStore(rt)
 	sectorNo := params.SectorNumber
 
-	var st State
-	rt.StateReadonly(&st)
+	st := ReadState(rt)
 
 	precommit, found, err := st.GetPrecommittedSector(store, sectorNo)
 	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load pre-committed sector %v", sectorNo)
@@ -866,6 +1123,116 @@ func (a Actor) ProveCommitSector(rt Runtime, params *ProveCommitSectorParams) *a
 	return nil
 }
 
+type ProveCommitAggregateParams struct {
+	SectorNumbers  bitfield.BitField
+	AggregateProof []byte
+}
+
+// Proves the seals of a batch of pre-committed sectors with a single aggregated proof, activating them immediately.
+// Unlike ProveCommitSector, verification is not deferred to the power actor's cron, and a network fee
+// for the aggregate is burnt.
+// Sectors whose proofs are late are verified as part of the aggregate but not activated.
+func (a Actor) ProveCommitAggregate(rt Runtime, params *ProveCommitAggregateParams) *abi.EmptyValue {
+	aggSectorsCount, err := params.SectorNumbers.Count()
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "failed to count aggregated sectors")
+	if aggSectorsCount > MaxAggregatedSectors {
+		rt.Abortf(exitcode.ErrIllegalArgument, "too many sectors addressed, addressed %d want <= %d", aggSectorsCount, MaxAggregatedSectors)
+	} else if aggSectorsCount < MinAggregatedSectors {
+		rt.Abortf(exitcode.ErrIllegalArgument, "too few sectors addressed, addressed %d want >= %d", aggSectorsCount, MinAggregatedSectors)
+	}
+	if uint64(len(params.AggregateProof)) > MaxAggregateProofSize {
+		rt.Abortf(exitcode.ErrIllegalArgument, "sector prove-commit proof of size %d exceeds max size of %d",
+			len(params.AggregateProof), MaxAggregateProofSize)
+	}
+
+	store := adt.AsStore(rt)
+	st := ReadState(rt)
+	info := getMinerInfo(rt, st)
+	rt.ValidateImmediateCallerIs(append(info.ControlAddresses, info.Owner, info.Worker)...)
+
+	var sectorNos []abi.SectorNumber
+	err = params.SectorNumbers.ForEach(func(sectorNo uint64) error {
+		sectorNos = append(sectorNos, abi.SectorNumber(sectorNo))
+		return nil
+	})
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "failed to enumerate aggregated sectors")
+
+	precommits, err := st.FindPrecommittedSectors(store, sectorNos...)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load pre-committed sectors")
+	if len(precommits) != len(sectorNos) {
+		rt.Abortf(exitcode.ErrNotFound, "found %d of %d aggregated pre-committed sectors", len(precommits), len(sectorNos))
+	}
+
+	sealProof := precommits[0].Info.SealProof
+	var precommitsToConfirm []*SectorPreCommitOnChainInfo
+	infos := make([]proof.AggregateSealVerifyInfo, len(precommits))
+	for i, precommit := range precommits {
+		if precommit.Info.SealProof != sealProof {
+			rt.Abortf(exitcode.ErrIllegalArgument, "aggregate contains mismatched seal proofs %d and %d",
+				sealProof, precommit.Info.SealProof)
+		}
+
+		msd, ok := MaxProveCommitDuration[precommit.Info.SealProof]
+		if !ok {
+			rt.Abortf(exitcode.ErrIllegalState, "no max seal duration for proof type: %d", precommit.Info.SealProof)
+		}
+		proveCommitDue := precommit.PreCommitEpoch + msd
+		if rt.CurrEpoch() > proveCommitDue {
+			rt.Log(rtt.WARN, "skipping commitment for sector %d, too late at %d, due %d",
+				precommit.Info.SectorNumber, rt.CurrEpoch(), proveCommitDue)
+		} else {
+			precommitsToConfirm = append(precommitsToConfirm, precommit)
+		}
+
+		svi := getVerifyInfo(rt, &SealVerifyStuff{
+			SealedCID:           precommit.Info.SealedCID,
+			InteractiveEpoch:    precommit.PreCommitEpoch + PreCommitChallengeDelay,
+			SealRandEpoch:       precommit.Info.SealRandEpoch,
+			DealIDs:             precommit.Info.DealIDs,
+			SectorNumber:        precommit.Info.SectorNumber,
+			RegisteredSealProof: precommit.Info.SealProof,
+		})
+		infos[i] = proof.AggregateSealVerifyInfo{
+			Number:                svi.Number,
+			Randomness:            svi.Randomness,
+			InteractiveRandomness: svi.InteractiveRandomness,
+			SealedCID:             svi.SealedCID,
+			UnsealedCID:           svi.UnsealedCID,
+		}
+	}
+
+	minerActorID, err := addr.IDFromAddress(rt.Receiver())
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "runtime provided non-ID receiver address %v", rt.Receiver())
+	err = rt.VerifyAggregateSeals(proof.AggregateSealVerifyProofAndInfos{
+		Miner:          abi.ActorID(minerActorID),
+		SealProof:      sealProof,
+		AggregateProof: proof.RegisteredAggregationProof_SnarkPackV1,
+		Proof:          params.AggregateProof,
+		Infos:          infos,
+	})
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "aggregate seal verify failed")
+
+	if len(precommitsToConfirm) == 0 {
+		rt.Abortf(exitcode.ErrIllegalArgument, "all aggregated commitments are too late")
+	}
+	confirmSectorProofsValid(rt, precommitsToConfirm)
+
+	aggregateFee := AggregateNetworkFee(len(precommitsToConfirm), rt.BaseFee())
+	st = ReadState(rt)
+	unlockedBalance, err := st.GetUnlockedBalance(rt.CurrentBalance())
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to calculate unlocked balance")
+	if unlockedBalance.LessThan(aggregateFee) {
+		rt.Abortf(exitcode.ErrInsufficientFunds, "remaining unlocked funds after prove-commit (%s) are insufficient to pay aggregation fee of %s",
+			unlockedBalance, aggregateFee)
+	}
+	burnFunds(rt, aggregateFee, builtin.BurnReasonAggregateFee)
+
+	st = ReadState(rt)
+	err = st.CheckBalanceInvariants(rt.CurrentBalance())
+	builtin.RequireNoErr(rt, err, ErrBalanceInvariantBroken, "balance invariants broken")
+	return nil
+}
+
 func (a Actor) ConfirmSectorProofsValid(rt Runtime, params *builtin.ConfirmSectorProofsParams) *abi.EmptyValue {
 	rt.ValidateImmediateCallerIs(builtin.StoragePowerActorAddr)
 
@@ -877,13 +1244,28 @@ func (a Actor) ConfirmSectorProofsValid(rt Runtime, params *builtin.ConfirmSecto
 		)
 	}
 
+	st := ReadState(rt)
+
+	// This skips missing pre-commits.
+	precommittedSectors, err := st.FindPrecommittedSectors(adt.AsStore(rt), params.Sectors...)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load pre-committed sectors")
+
+	confirmSectorProofsValid(rt, precommittedSectors)
+	return nil
+}
+
+// Activates new sectors for pre-commitments whose seal proofs have been verified.
+// Pre-commitments with deals that fail to activate are dropped, aborting if none remain.
+// The allocations claimed by the remaining pre-commitments must then be claimed successfully, or the whole
+// activation fails.
+func confirmSectorProofsValid(rt Runtime, precommittedSectors []*SectorPreCommitOnChainInfo) {
 	// get network stats from other actors
 	rewardStats := requestCurrentEpochBlockReward(rt)
 	pwrTotal := requestCurrentTotalPower(rt)
 	circulatingSupply := rt.TotalFilCircSupply()
 
 	// 1. Activate deals, skipping pre-commits with invalid deals.
-	//    - calls the market actor.
+	//    - calls the market actor once for all pre-commits.
 	// 2. Reschedule replacement sector expiration.
 	//    - loads and saves sectors
 	//    - loads and saves deadlines/partitions
@@ -894,46 +1276,36 @@ func (a Actor) ConfirmSectorProofsValid(rt Runtime, params *builtin.ConfirmSecto
 	// Ideally, we'd combine some of these operations, but at least we have
 	// a constant number of them.
 
-	var st State
-	rt.StateReadonly(&st)
+	st := ReadState(rt)
 	store := adt.AsStore(rt)
-	info := getMinerInfo(rt, &st)
+	info := getMinerInfo(rt, st)
 
 	//
 	// Activate storage deals.
 	//
 
-	// This skips missing pre-commits.
-	precommittedSectors, err := st.FindPrecommittedSectors(store, params.Sectors...)
-	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load pre-committed sectors")
+	sectorDeals := make([]market.SectorDeals, len(precommittedSectors))
+	for i, precommit := range precommittedSectors {
+		sectorDeals[i] = market.SectorDeals{
+			SectorExpiry: precommit.Info.Expiration,
+			DealIDs:      precommit.Info.DealIDs,
+		}
+	}
+	activations := requestActivateDeals(rt, sectorDeals)
 
 	// Committed-capacity sectors licensed for early removal by new sectors being proven.
 	replaceSectors := make(DeadlineSectorMap)
-	// Pre-commits for new sectors.
+	// Pre-commits for new sectors, and the results of activating their deals.
 	var preCommits []*SectorPreCommitOnChainInfo
-	for _, precommit := range precommittedSectors {
-		if len(precommit.Info.DealIDs) > 0 {
-			// Check (and activate) storage deals associated to sector. Abort if checks failed.
-			// TODO: we should batch these calls...
-			// https://github.com/filecoin-project/specs-actors/issues/474
-			code := rt.Send(
-				builtin.StorageMarketActorAddr,
-				builtin.MethodsMarket.ActivateDeals,
-				&market.ActivateDealsParams{
-					DealIDs:      precommit.Info.DealIDs,
-					SectorExpiry: precommit.Info.Expiration,
-				},
-				abi.NewTokenAmount(0),
-				&builtin.Discard{},
-			)
-
-			if code != exitcode.Ok {
-				rt.Log(rtt.INFO, "failed to activate deals on sector %d, dropping from prove commit set", precommit.Info.SectorNumber)
-				continue
-			}
+	var dealActivations []market.SectorDealActivation
+	for i, precommit := range precommittedSectors {
+		if !activations[i].Activated {
+			rt.Log(rtt.INFO, "failed to activate deals on sector %d, dropping from prove commit set", precommit.Info.SectorNumber)
+			continue
 		}
 
 		preCommits = append(preCommits, precommit)
+		dealActivations = append(dealActivations, activations[i])
 
 		if precommit.Info.ReplaceCapacity {
 			err := replaceSectors.AddValues(
@@ -950,11 +1322,23 @@ func (a Actor) ConfirmSectorProofsValid(rt Runtime, params *builtin.ConfirmSecto
 		rt.Abortf(exitcode.ErrIllegalArgument, "all prove commits failed to validate")
 	}
 
+	// Claim the verified allocations of the new sectors' pieces.
+	sectorClaims := make([]sectorAllocationClaims, len(preCommits))
+	for i, precommit := range preCommits {
+		sectorClaims[i] = sectorAllocationClaims{
+			sector:     precommit.Info.SectorNumber,
+			expiration: precommit.Info.Expiration,
+			claims:     precommit.Info.AllocationClaims,
+		}
+	}
+	claimedWeight := requestClaimAllocations(rt, sectorClaims, dealActivations)
+
 	totalPledge := big.Zero()
 	depositToUnlock := big.Zero()
 	newSectors := make([]*SectorOnChainInfo, 0)
+	var newSectorPieces [][]cid.Cid
 	newlyVested := big.Zero()
-	rt.StateTransaction(&st, func() {
+	WithState(rt, func(st *State) {
 		// Schedule expiration for replaced sectors to the end of their next deadline window.
 		// They can't be removed right now because we want to challenge them immediately before termination.
 		replaced, err := st.RescheduleSectorExpirations(store, rt.CurrEpoch(), info.SectorSize, replaceSectors)
@@ -962,7 +1346,7 @@ func (a Actor) ConfirmSectorProofsValid(rt Runtime, params *builtin.ConfirmSecto
 		replacedBySectorNumber := asMapBySectorNumber(replaced)
 
 		newSectorNos := make([]abi.SectorNumber, 0, len(preCommits))
-		for _, precommit := range preCommits {
+		for i, precommit := range preCommits {
 			// compute initial pledge
 			activation := rt.CurrEpoch()
 			duration := precommit.Info.Expiration - activation
@@ -973,7 +1357,8 @@ func (a Actor) ConfirmSectorProofsValid(rt Runtime, params *builtin.ConfirmSecto
 				continue
 			}
 
-			pwr := QAPowerForWeight(info.SectorSize, duration, precommit.DealWeight, precommit.VerifiedDealWeight)
+			dealWeight, verifiedDealWeight := claimedDealWeights(dealActivations[i], claimedWeight[i])
+			pwr := QAPowerForWeight(info.SectorSize, duration, dealWeight, verifiedDealWeight)
 			dayReward := ExpectedRewardForPower(rewardStats.ThisEpochRewardSmoothed, pwrTotal.QualityAdjPowerSmoothed, pwr, builtin.EpochsInDay)
 			// The storage pledge is recorded for use in computing the penalty if this sector is terminated
 			// before its declared expiration.
@@ -994,8 +1379,8 @@ func (a Actor) ConfirmSectorProofsValid(rt Runtime, params *builtin.ConfirmSecto
 				DealIDs:               precommit.Info.DealIDs,
 				Expiration:            precommit.Info.Expiration,
 				Activation:            activation,
-				DealWeight:            precommit.DealWeight,
-				VerifiedDealWeight:    precommit.VerifiedDealWeight,
+				DealWeight:            dealWeight,
+				VerifiedDealWeight:    verifiedDealWeight,
 				InitialPledge:         initialPledge,
 				ExpectedDayReward:     dayReward,
 				ExpectedStoragePledge: storagePledge,
@@ -1005,6 +1390,7 @@ func (a Actor) ConfirmSectorProofsValid(rt Runtime, params *builtin.ConfirmSecto
 
 			depositToUnlock = big.Add(depositToUnlock, precommit.PreCommitDeposit)
 			newSectors = append(newSectors, &newSectorInfo)
+			newSectorPieces = append(newSectorPieces, dealActivations[i].PieceCIDs)
 			newSectorNos = append(newSectorNos, newSectorInfo.SectorNumber)
 			totalPledge = big.Add(totalPledge, initialPledge)
 		}
@@ -1036,7 +1422,9 @@ func (a Actor) ConfirmSectorProofsValid(rt Runtime, params *builtin.ConfirmSecto
 
 	// Request pledge update for activated sector.
 	notifyPledgeChanged(rt, big.Sub(totalPledge, newlyVested))
-	return nil
+	for i, sector := range newSectors {
+		emitSectorActivated(rt, sector.SectorNumber, newSectorPieces[i])
+	}
 }
 
 //type CheckSectorProvenParams struct {
@@ -1051,8 +1439,7 @@ func (a Actor) CheckSectorProven(rt Runtime, params *CheckSectorProvenParams) *a
 		rt.Abortf(exitcode.ErrIllegalArgument, "sector number out of range")
 	}
 
-	var st State
-	rt.StateReadonly(&st)
+	st := ReadState(rt)
 	store := adt.AsStore(rt)
 	sectorNo := params.SectorNumber
 
@@ -1118,9 +1505,8 @@ func (a Actor) ExtendSectorExpiration(rt Runtime, params *ExtendSectorExpiration
 	powerDelta := NewPowerPairZero()
 	pledgeDelta := big.Zero()
 	store := adt.AsStore(rt)
-	var st State
-	rt.StateTransaction(&st, func() {
-		info := getMinerInfo(rt, &st)
+	WithState(rt, func(st *State) {
+		info := getMinerInfo(rt, st)
 
 		rt.ValidateImmediateCallerIs(append(info.ControlAddresses, info.Owner, info.Worker)...)
 
@@ -1247,6 +1633,228 @@ func (a Actor) ExtendSectorExpiration(rt Runtime, params *ExtendSectorExpiration
 	return nil
 }
 
+type ReplicaUpdate struct {
+	SectorNumber       abi.SectorNumber
+	Deadline           uint64
+	Partition          uint64
+	NewSealedSectorCID cid.Cid `checked:"true"` // CommR
+	Deals              []abi.DealID
+	ReplicaProof       []byte
+	AllocationClaims   []AllocationClaim // Verified allocations claimed by pieces of the new deals
+}
+
+type ProveReplicaUpdatesParams struct {
+	Updates []ReplicaUpdate
+}
+
+// Injects deal data into committed-capacity sectors without re-sealing them.
+// Each sector's replica is re-encoded off-chain to hold the new deals' data, which is proven by a replica update proof.
+// The updated sector keeps its expiration, but is re-activated at the current epoch with its new sealed CID and deals,
+// and its power and initial pledge are recomputed for the new deal weight.
+// As at prove-commit, the new deals' pieces may claim verified allocations for the sector's remaining lifetime.
+// The sectors must be active (proven, not faulty or terminated) and must not have any deals.
+func (a Actor) ProveReplicaUpdates(rt Runtime, params *ProveReplicaUpdatesParams) *abi.EmptyValue {
+	if len(params.Updates) == 0 {
+		rt.Abortf(exitcode.ErrIllegalArgument, "no replica updates")
+	}
+	if len(params.Updates) > ProveReplicaUpdatesMaxSize {
+		rt.Abortf(exitcode.ErrIllegalArgument, "too many replica updates %d, max %d", len(params.Updates), ProveReplicaUpdatesMaxSize)
+	}
+
+	currEpoch := rt.CurrEpoch()
+	store := adt.AsStore(rt)
+	st := ReadState(rt)
+	info := getMinerInfo(rt, st)
+
+	rt.ValidateImmediateCallerIs(append(info.ControlAddresses, info.Owner, info.Worker)...)
+
+	sectors, err := LoadSectors(store, st.Sectors)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load sectors array")
+
+	// Validate all updates before activating any deals.
+	oldSectors := make([]*SectorOnChainInfo, len(params.Updates))
+	seen := map[abi.SectorNumber]struct{}{}
+	for i, update := range params.Updates {
+		if _, dup := seen[update.SectorNumber]; dup {
+			rt.Abortf(exitcode.ErrIllegalArgument, "duplicate replica update for sector %d", update.SectorNumber)
+		}
+		seen[update.SectorNumber] = struct{}{}
+
+		if update.Deadline >= WPoStPeriodDeadlines {
+			rt.Abortf(exitcode.ErrIllegalArgument, "deadline %d not in range 0..%d", update.Deadline, WPoStPeriodDeadlines)
+		}
+		// Updating a sector changes its power, so the deadline must not be being proven.
+		if !deadlineIsMutable(st.ProvingPeriodStart, update.Deadline, currEpoch) {
+			rt.Abortf(exitcode.ErrForbidden, "cannot update sector %d in immutable deadline %d", update.SectorNumber, update.Deadline)
+		}
+		if !update.NewSealedSectorCID.Defined() {
+			rt.Abortf(exitcode.ErrIllegalArgument, "new sealed CID undefined for sector %d", update.SectorNumber)
+		}
+		if update.NewSealedSectorCID.Prefix() != SealedCIDPrefix {
+			rt.Abortf(exitcode.ErrIllegalArgument, "new sealed CID had wrong prefix for sector %d", update.SectorNumber)
+		}
+		if len(update.Deals) == 0 {
+			rt.Abortf(exitcode.ErrIllegalArgument, "replica update for sector %d has no deals", update.SectorNumber)
+		}
+		validateAllocationClaims(rt, update.AllocationClaims, len(update.Deals))
+
+		sector, found, err := sectors.Get(update.SectorNumber)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load sector %d", update.SectorNumber)
+		if !found {
+			rt.Abortf(exitcode.ErrNotFound, "no such sector %d", update.SectorNumber)
+		}
+		if len(sector.DealIDs) > 0 {
+			rt.Abortf(exitcode.ErrIllegalArgument, "cannot update sector %d which already has deals", update.SectorNumber)
+		}
+		if sector.Expiration <= currEpoch {
+			rt.Abortf(exitcode.ErrForbidden, "cannot update expired sector %d, expired at %d, now %d",
+				update.SectorNumber, sector.Expiration, currEpoch)
+		}
+
+		err = st.CheckSectorHealth(store, update.Deadline, update.Partition, update.SectorNumber)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "cannot update sector %d", update.SectorNumber)
+
+		oldSectors[i] = sector
+	}
+
+	// Activate the new deals against each sector's remaining lifetime, computing their weights.
+	// Any update failing below aborts the message, reverting the activations.
+	sectorDeals := make([]market.SectorDeals, len(params.Updates))
+	for i, update := range params.Updates {
+		sectorDeals[i] = market.SectorDeals{
+			SectorExpiry: oldSectors[i].Expiration,
+			DealIDs:      update.Deals,
+		}
+	}
+	activations := requestActivateDeals(rt, sectorDeals)
+
+	for i, update := range params.Updates {
+		if !activations[i].Activated {
+			rt.Abortf(exitcode.ErrIllegalArgument, "failed to activate deals for sector %d", update.SectorNumber)
+		}
+		if activations[i].DealSpace > uint64(info.SectorSize) {
+			rt.Abortf(exitcode.ErrIllegalArgument, "deals too large to fit in sector %d > %d", activations[i].DealSpace, info.SectorSize)
+		}
+
+		unsealedCID := requestUnsealedSectorCID(rt, oldSectors[i].SealProof, update.Deals)
+		err := rt.VerifyReplicaUpdate(proof.ReplicaUpdateInfo{
+			SealProof:            oldSectors[i].SealProof,
+			OldSealedSectorCID:   oldSectors[i].SealedCID,
+			NewSealedSectorCID:   update.NewSealedSectorCID,
+			NewUnsealedSectorCID: unsealedCID,
+			Proof:                update.ReplicaProof,
+		})
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "failed to verify replica update for sector %d", update.SectorNumber)
+	}
+
+	// Claim the verified allocations of the new deals' pieces.
+	sectorClaims := make([]sectorAllocationClaims, len(params.Updates))
+	for i, update := range params.Updates {
+		sectorClaims[i] = sectorAllocationClaims{
+			sector:     update.SectorNumber,
+			expiration: oldSectors[i].Expiration,
+			claims:     update.AllocationClaims,
+		}
+	}
+	claimedWeight := requestClaimAllocations(rt, sectorClaims, activations)
+
+	// get network stats from other actors
+	rewardStats := requestCurrentEpochBlockReward(rt)
+	pwrTotal := requestCurrentTotalPower(rt)
+	circulatingSupply := rt.TotalFilCircSupply()
+
+	var powerDeltas []PowerPair
+	pledgeDelta := big.Zero()
+	WithState(rt, func(st *State) {
+		deadlines, err := st.LoadDeadlines(store)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deadlines")
+
+		sectors, err := LoadSectors(store, st.Sectors)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load sectors array")
+
+		for i, update := range params.Updates {
+			oldSector := oldSectors[i]
+			duration := oldSector.Expiration - currEpoch
+
+			dealWeight, verifiedDealWeight := claimedDealWeights(activations[i], claimedWeight[i])
+			pwr := QAPowerForWeight(info.SectorSize, duration, dealWeight, verifiedDealWeight)
+			dayReward := ExpectedRewardForPower(rewardStats.ThisEpochRewardSmoothed, pwrTotal.QualityAdjPowerSmoothed, pwr, builtin.EpochsInDay)
+			storagePledge := ExpectedRewardForPower(rewardStats.ThisEpochRewardSmoothed, pwrTotal.QualityAdjPowerSmoothed, pwr, InitialPledgeProjectionPeriod)
+			initialPledge := InitialPledgeForPower(pwr, rewardStats.ThisEpochBaselinePower, rewardStats.ThisEpochRewardSmoothed,
+				pwrTotal.QualityAdjPowerSmoothed, circulatingSupply)
+
+			// As for a replaced committed-capacity sector, the pledge is lower-bounded by the sector's existing pledge,
+			// and the sector's age and reward rate are recorded for termination fee calculations.
+			newSector := *oldSector
+			newSector.SealedCID = update.NewSealedSectorCID
+			newSector.DealIDs = update.Deals
+			newSector.Activation = currEpoch
+			newSector.DealWeight = dealWeight
+			newSector.VerifiedDealWeight = verifiedDealWeight
+			newSector.InitialPledge = big.Max(initialPledge, oldSector.InitialPledge)
+			newSector.ExpectedDayReward = dayReward
+			newSector.ExpectedStoragePledge = storagePledge
+			newSector.ReplacedSectorAge = maxEpoch(0, currEpoch-oldSector.Activation)
+			newSector.ReplacedDayReward = oldSector.ExpectedDayReward
+
+			err = sectors.Store(&newSector)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to update sector %d", update.SectorNumber)
+
+			deadline, err := deadlines.LoadDeadline(store, update.Deadline)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deadline %d", update.Deadline)
+			partitions, err := deadline.PartitionsArray(store)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load partitions for deadline %d", update.Deadline)
+
+			var partition Partition
+			found, err := partitions.Get(update.Partition, &partition)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load deadline %d partition %d", update.Deadline, update.Partition)
+			if !found {
+				rt.Abortf(exitcode.ErrNotFound, "no such deadline %d partition %d", update.Deadline, update.Partition)
+			}
+
+			quant := st.QuantSpecForDeadline(update.Deadline)
+			partitionPowerDelta, partitionPledgeDelta, err := partition.ReplaceSectors(store,
+				[]*SectorOnChainInfo{oldSector}, []*SectorOnChainInfo{&newSector}, info.SectorSize, quant)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "failed to replace sector %d at deadline %d partition %d",
+				update.SectorNumber, update.Deadline, update.Partition)
+
+			if !partitionPowerDelta.IsZero() {
+				powerDeltas = append(powerDeltas, partitionPowerDelta)
+			}
+			pledgeDelta = big.Add(pledgeDelta, partitionPledgeDelta)
+
+			err = partitions.Set(update.Partition, &partition)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to save deadline %d partition %d", update.Deadline, update.Partition)
+
+			deadline.Partitions, err = partitions.Root()
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to save partitions for deadline %d", update.Deadline)
+
+			err = deadlines.UpdateDeadline(store, update.Deadline, deadline)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to save deadline %d", update.Deadline)
+		}
+
+		st.Sectors, err = sectors.Root()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to save sectors")
+
+		err = st.SaveDeadlines(store, deadlines)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to save deadlines")
+
+		unlockedBalance, err := st.GetUnlockedBalance(rt.CurrentBalance())
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to calculate unlocked balance")
+		if unlockedBalance.LessThan(pledgeDelta) {
+			rt.Abortf(exitcode.ErrInsufficientFunds, "insufficient funds for additional initial pledge requirement %s, available: %s", pledgeDelta, unlockedBalance)
+		}
+
+		err = st.AddInitialPledge(pledgeDelta)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to add initial pledge %v", pledgeDelta)
+		err = st.CheckBalanceInvariants(rt.CurrentBalance())
+		builtin.RequireNoErr(rt, err, ErrBalanceInvariantBroken, "balance invariants broken")
+	})
+
+	requestUpdatePowerAndPledge(rt, powerDeltas, pledgeDelta)
+	return nil
+}
+
 //type TerminateSectorsParams struct {
 //	Terminations []TerminationDeclaration
 //}
@@ -1290,32 +1898,21 @@ func (a Actor) TerminateSectors(rt Runtime, params *TerminateSectorsParams) *Ter
 	// Note: this cannot terminate pre-committed but un-proven sectors.
 	// They must be allowed to expire (and deposit burnt).
 
-	if len(params.Terminations) > DeclarationsMax {
-		rt.Abortf(exitcode.ErrIllegalArgument,
-			"too many declarations when terminating sectors: %d > %d",
-			len(params.Terminations), DeclarationsMax,
-		)
-	}
-
-	toProcess := make(DeadlineSectorMap)
-	for _, term := range params.Terminations {
-		err := toProcess.Add(term.Deadline, term.Partition, term.Sectors)
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument,
-			"failed to process deadline %d, partition %d", term.Deadline, term.Partition,
-		)
+	selections := make([]SectorSelection, len(params.Terminations))
+	for i, term := range params.Terminations {
+		selections[i] = SectorSelection(term)
 	}
-	err := toProcess.Check(AddressedPartitionsMax, AddressedSectorsMax)
-	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "cannot process requested parameters")
+	toProcess, err := SelectSectors(selections)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "invalid termination declarations")
 
 	var hadEarlyTerminations bool
-	var st State
 	store := adt.AsStore(rt)
 	currEpoch := rt.CurrEpoch()
 	powerDelta := NewPowerPairZero()
-	rt.StateTransaction(&st, func() {
-		hadEarlyTerminations = havePendingEarlyTerminations(rt, &st)
+	WithState(rt, func(st *State) {
+		hadEarlyTerminations = havePendingEarlyTerminations(rt, st)
 
-		info := getMinerInfo(rt, &st)
+		info := getMinerInfo(rt, st)
 		rt.ValidateImmediateCallerIs(append(info.ControlAddresses, info.Owner, info.Worker)...)
 
 		deadlines, err := st.LoadDeadlines(adt.AsStore(rt))
@@ -1367,11 +1964,12 @@ func (a Actor) TerminateSectors(rt Runtime, params *TerminateSectorsParams) *Ter
 		scheduleEarlyTerminationWork(rt)
 	}
 
-	rt.StateReadonly(&st)
+	st := ReadState(rt)
 	err = st.CheckBalanceInvariants(rt.CurrentBalance())
 	builtin.RequireNoErr(rt, err, ErrBalanceInvariantBroken, "balance invariants broken")
 
 	requestUpdatePower(rt, powerDelta)
+	emitSectorsTerminated(rt, toProcess)
 	return &TerminateSectorsReturn{Done: !more}
 }
 
@@ -1395,28 +1993,18 @@ type DeclareFaultsParams = miner0.DeclareFaultsParams
 type FaultDeclaration = miner0.FaultDeclaration
 
 func (a Actor) DeclareFaults(rt Runtime, params *DeclareFaultsParams) *abi.EmptyValue {
-	if len(params.Faults) > DeclarationsMax {
-		rt.Abortf(exitcode.ErrIllegalArgument,
-			"too many fault declarations for a single message: %d > %d",
-			len(params.Faults), DeclarationsMax,
-		)
-	}
-
-	toProcess := make(DeadlineSectorMap)
-	for _, term := range params.Faults {
-		err := toProcess.Add(term.Deadline, term.Partition, term.Sectors)
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument,
-			"failed to process deadline %d, partition %d", term.Deadline, term.Partition,
-		)
+	selections := make([]SectorSelection, len(params.Faults))
+	for i, decl := range params.Faults {
+		selections[i] = SectorSelection(decl)
 	}
-	err := toProcess.Check(AddressedPartitionsMax, AddressedSectorsMax)
-	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "cannot process requested parameters")
+	toProcess, err := SelectSectors(selections)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "invalid fault declarations")
 
 	store := adt.AsStore(rt)
-	var st State
 	powerDelta := NewPowerPairZero()
-	rt.StateTransaction(&st, func() {
-		info := getMinerInfo(rt, &st)
+	lateFaultPower := NewPowerPairZero()
+	WithState(rt, func(st *State) {
+		info := getMinerInfo(rt, st)
 		rt.ValidateImmediateCallerIs(append(info.ControlAddresses, info.Owner, info.Worker)...)
 
 		deadlines, err := st.LoadDeadlines(store)
@@ -1429,7 +2017,7 @@ func (a Actor) DeclareFaults(rt Runtime, params *DeclareFaultsParams) *abi.Empty
 			targetDeadline, err := declarationDeadlineInfo(st.ProvingPeriodStart, dlIdx, rt.CurrEpoch())
 			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "invalid fault declaration deadline %d", dlIdx)
 
-			err = validateFRDeclarationDeadline(targetDeadline)
+			late, err := validateFaultDeclarationDeadline(targetDeadline)
 			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "failed fault declaration at deadline %d", dlIdx)
 
 			deadline, err := deadlines.LoadDeadline(store, dlIdx)
@@ -1443,6 +2031,9 @@ func (a Actor) DeclareFaults(rt Runtime, params *DeclareFaultsParams) *abi.Empty
 			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to store deadline %d partitions", dlIdx)
 
 			powerDelta = powerDelta.Add(deadlinePowerDelta)
+			if late {
+				lateFaultPower = lateFaultPower.Sub(deadlinePowerDelta)
+			}
 			return nil
 		})
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to iterate deadlines")
@@ -1457,10 +2048,40 @@ func (a Actor) DeclareFaults(rt Runtime, params *DeclareFaultsParams) *abi.Empty
 	// https://github.com/filecoin-project/specs-actors/issues/414
 	requestUpdatePower(rt, powerDelta)
 
-	// Payment of penalty for declared faults is deferred to the deadline cron.
+	// Payment of penalty for faults declared in time is deferred to the deadline cron.
+	// Late faults pay a reduced penalty now, in place of the penalty for detection when the deadline closes.
+	if !lateFaultPower.IsZero() {
+		penalizeLateFaults(rt, lateFaultPower)
+	}
 	return nil
 }
 
+// Charges the penalty for faults declared within a deadline's self-report window, after the fault declaration cutoff.
+func penalizeLateFaults(rt Runtime, lateFaultPower PowerPair) {
+	epochReward := requestCurrentEpochBlockReward(rt)
+	pwrTotal := requestCurrentTotalPower(rt)
+
+	penaltyTotal := big.Zero()
+	pledgeDelta := big.Zero()
+	WithState(rt, func(st *State) {
+		penaltyTarget := PledgePenaltyForLateDeclaredFault(
+			epochReward.ThisEpochRewardSmoothed,
+			pwrTotal.QualityAdjPowerSmoothed,
+			lateFaultPower.QA,
+		)
+		err := st.ApplyPenalty(penaltyTarget)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to apply penalty")
+
+		penaltyFromVesting, penaltyFromBalance, err := st.RepayPartialDebtInPriorityOrder(adt.AsStore(rt), rt.CurrEpoch(), rt.CurrentBalance())
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to pay debt")
+		penaltyTotal = big.Add(penaltyFromVesting, penaltyFromBalance)
+		pledgeDelta = penaltyFromVesting.Neg()
+	})
+
+	burnFunds(rt, penaltyTotal, builtin.BurnReasonProvingDeadline)
+	notifyPledgeChanged(rt, pledgeDelta)
+}
+
 //type DeclareFaultsRecoveredParams struct {
 //	Recoveries []RecoveryDeclaration
 //}
@@ -1477,32 +2098,21 @@ type DeclareFaultsRecoveredParams = miner0.DeclareFaultsRecoveredParams
 type RecoveryDeclaration = miner0.RecoveryDeclaration
 
 func (a Actor) DeclareFaultsRecovered(rt Runtime, params *DeclareFaultsRecoveredParams) *abi.EmptyValue {
-	if len(params.Recoveries) > DeclarationsMax {
-		rt.Abortf(exitcode.ErrIllegalArgument,
-			"too many recovery declarations for a single message: %d > %d",
-			len(params.Recoveries), DeclarationsMax,
-		)
-	}
-
-	toProcess := make(DeadlineSectorMap)
-	for _, term := range params.Recoveries {
-		err := toProcess.Add(term.Deadline, term.Partition, term.Sectors)
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument,
-			"failed to process deadline %d, partition %d", term.Deadline, term.Partition,
-		)
+	selections := make([]SectorSelection, len(params.Recoveries))
+	for i, decl := range params.Recoveries {
+		selections[i] = SectorSelection(decl)
 	}
-	err := toProcess.Check(AddressedPartitionsMax, AddressedSectorsMax)
-	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "cannot process requested parameters")
+	toProcess, err := SelectSectors(selections)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "invalid recovery declarations")
 
 	store := adt.AsStore(rt)
-	var st State
 	feeToBurn := abi.NewTokenAmount(0)
-	rt.StateTransaction(&st, func() {
+	WithState(rt, func(st *State) {
 		// Verify unlocked funds cover both InitialPledgeRequirement and FeeDebt
 		// and repay fee debt now.
-		feeToBurn = RepayDebtsOrAbort(rt, &st)
+		feeToBurn = RepayDebtsOrAbort(rt, st)
 
-		info := getMinerInfo(rt, &st)
+		info := getMinerInfo(rt, st)
 		rt.ValidateImmediateCallerIs(append(info.ControlAddresses, info.Owner, info.Worker)...)
 		if ConsensusFaultActive(info, rt.CurrEpoch()) {
 			rt.Abortf(exitcode.ErrForbidden, "recovery not allowed during active consensus fault")
@@ -1536,8 +2146,8 @@ func (a Actor) DeclareFaultsRecovered(rt Runtime, params *DeclareFaultsRecovered
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to save deadlines")
 	})
 
-	burnFunds(rt, feeToBurn)
-	rt.StateReadonly(&st)
+	burnFunds(rt, feeToBurn, builtin.BurnReasonFeeDebt)
+	st := ReadState(rt)
 	err = st.CheckBalanceInvariants(rt.CurrentBalance())
 	builtin.RequireNoErr(rt, err, ErrBalanceInvariantBroken, "balance invariants broken")
 
@@ -1566,13 +2176,14 @@ func (a Actor) CompactPartitions(rt Runtime, params *CompactPartitionsParams) *a
 		rt.Abortf(exitcode.ErrIllegalArgument, "invalid deadline %v", params.Deadline)
 	}
 
+	err := IsValidForChain(params.Partitions)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "invalid partitions bitfield")
 	partitionCount, err := params.Partitions.Count()
 	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "failed to parse partitions bitfield")
 
 	store := adt.AsStore(rt)
-	var st State
-	rt.StateTransaction(&st, func() {
-		info := getMinerInfo(rt, &st)
+	WithState(rt, func(st *State) {
+		info := getMinerInfo(rt, st)
 		rt.ValidateImmediateCallerIs(append(info.ControlAddresses, info.Owner, info.Worker)...)
 
 		if !deadlineAvailableForCompaction(st.ProvingPeriodStart, params.Deadline, rt.CurrEpoch()) {
@@ -1633,6 +2244,8 @@ type CompactSectorNumbersParams = miner0.CompactSectorNumbersParams
 // For example, if sectors 1-99 and 101-200 have been allocated, sector number
 // 99 can be masked out to collapse these two ranges into one.
 func (a Actor) CompactSectorNumbers(rt Runtime, params *CompactSectorNumbersParams) *abi.EmptyValue {
+	err := IsValidForChain(params.MaskSectorNumbers)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "invalid mask bitfield")
 	lastSectorNo, err := params.MaskSectorNumbers.Last()
 	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "invalid mask bitfield")
 	if lastSectorNo > abi.MaxSectorNumber {
@@ -1640,9 +2253,8 @@ func (a Actor) CompactSectorNumbers(rt Runtime, params *CompactSectorNumbersPara
 	}
 
 	store := adt.AsStore(rt)
-	var st State
-	rt.StateTransaction(&st, func() {
-		info := getMinerInfo(rt, &st)
+	WithState(rt, func(st *State) {
+		info := getMinerInfo(rt, st)
 		rt.ValidateImmediateCallerIs(append(info.ControlAddresses, info.Owner, info.Worker)...)
 
 		err := st.MaskSectorNumbers(store, params.MaskSectorNumbers)
@@ -1665,10 +2277,9 @@ func (a Actor) ApplyRewards(rt Runtime, params *builtin.ApplyRewardParams) *abi.
 		rt.Abortf(exitcode.ErrIllegalArgument, "cannot penalize a negative amount of funds")
 	}
 
-	var st State
 	pledgeDeltaTotal := big.Zero()
 	toBurn := big.Zero()
-	rt.StateTransaction(&st, func() {
+	WithState(rt, func(st *State) {
 		var err error
 		store := adt.AsStore(rt)
 		rt.ValidateImmediateCallerIs(builtin.RewardActorAddr)
@@ -1701,8 +2312,8 @@ func (a Actor) ApplyRewards(rt Runtime, params *builtin.ApplyRewardParams) *abi.
 	})
 
 	notifyPledgeChanged(rt, pledgeDeltaTotal)
-	burnFunds(rt, toBurn)
-	rt.StateReadonly(&st)
+	burnFunds(rt, toBurn, builtin.BurnReasonBlockRewardPenalty)
+	st := ReadState(rt)
 	err := st.CheckBalanceInvariants(rt.CurrentBalance())
 	builtin.RequireNoErr(rt, err, ErrBalanceInvariantBroken, "balance invariants broken")
 
@@ -1740,7 +2351,6 @@ func (a Actor) ReportConsensusFault(rt Runtime, params *ReportConsensusFaultPara
 
 	// Penalize miner consensus fault fee
 	// Give a portion of this to the reporter as reward
-	var st State
 	rewardStats := requestCurrentEpochBlockReward(rt)
 	// The policy amounts we should burn and send to reporter
 	// These may differ from actual funds send when miner goes into fee debt
@@ -1751,8 +2361,8 @@ func (a Actor) ReportConsensusFault(rt Runtime, params *ReportConsensusFaultPara
 	// The amounts actually sent to burnt funds and reporter
 	burnAmount := big.Zero()
 	rewardAmount := big.Zero()
-	rt.StateTransaction(&st, func() {
-		info := getMinerInfo(rt, &st)
+	WithState(rt, func(st *State) {
+		info := getMinerInfo(rt, st)
 
 		// verify miner hasn't already been faulted
 		if fault.Epoch < info.ConsensusFaultElapsed {
@@ -1781,10 +2391,10 @@ func (a Actor) ReportConsensusFault(rt Runtime, params *ReportConsensusFaultPara
 	if !code.IsSuccess() {
 		rt.Log(rtt.ERROR, "failed to send reward")
 	}
-	burnFunds(rt, burnAmount)
+	burnFunds(rt, burnAmount, builtin.BurnReasonConsensusFault)
 	notifyPledgeChanged(rt, pledgeDelta)
 
-	rt.StateReadonly(&st)
+	st := ReadState(rt)
 	err = st.CheckBalanceInvariants(rt.CurrentBalance())
 	builtin.RequireNoErr(rt, err, ErrBalanceInvariantBroken, "balance invariants broken")
 
@@ -1796,8 +2406,9 @@ func (a Actor) ReportConsensusFault(rt Runtime, params *ReportConsensusFaultPara
 //}
 type WithdrawBalanceParams = miner0.WithdrawBalanceParams
 
+// Withdraws available balance to the beneficiary, at the request of the owner or the beneficiary.
+// A beneficiary other than the owner may receive no more than the remaining quota of its unexpired term.
 func (a Actor) WithdrawBalance(rt Runtime, params *WithdrawBalanceParams) *abi.EmptyValue {
-	var st State
 	if params.AmountRequested.LessThan(big.Zero()) {
 		rt.Abortf(exitcode.ErrIllegalArgument, "negative fund requested for withdrawal: %s", params.AmountRequested)
 	}
@@ -1805,12 +2416,13 @@ func (a Actor) WithdrawBalance(rt Runtime, params *WithdrawBalanceParams) *abi.E
 	newlyVested := big.Zero()
 	feeToBurn := big.Zero()
 	availableBalance := big.Zero()
-	rt.StateTransaction(&st, func() {
+	amountWithdrawn := big.Zero()
+	WithState(rt, func(st *State) {
 		var err error
-		info = getMinerInfo(rt, &st)
-		// Only the owner is allowed to withdraw the balance as it belongs to/is controlled by the owner
+		info = getMinerInfo(rt, st)
+		// Only the owner or beneficiary is allowed to withdraw the balance as it belongs to/is controlled by the owner
 		// and not the worker.
-		rt.ValidateImmediateCallerIs(info.Owner)
+		rt.ValidateImmediateCallerIs(info.Owner, info.Beneficiary)
 
 		// Ensure we don't have any pending terminations.
 		if count, err := st.EarlyTerminations.Count(); err != nil {
@@ -1835,23 +2447,34 @@ func (a Actor) WithdrawBalance(rt Runtime, params *WithdrawBalanceParams) *abi.E
 
 		// Verify unlocked funds cover both InitialPledgeRequirement and FeeDebt
 		// and repay fee debt now.
-		feeToBurn = RepayDebtsOrAbort(rt, &st)
+		feeToBurn = RepayDebtsOrAbort(rt, st)
+
+		amountWithdrawn = big.Min(availableBalance, params.AmountRequested)
+		if info.Beneficiary != info.Owner {
+			// Limit withdrawal to the beneficiary's remaining quota, and record its use.
+			amountWithdrawn = big.Min(amountWithdrawn, info.BeneficiaryTerm.Available(rt.CurrEpoch()))
+			if amountWithdrawn.GreaterThan(big.Zero()) {
+				info.BeneficiaryTerm.UsedQuota = big.Add(info.BeneficiaryTerm.UsedQuota, amountWithdrawn)
+				err = st.SaveInfo(adt.AsStore(rt), info)
+				builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to save miner info")
+			}
+		}
 	})
 
-	amountWithdrawn := big.Min(availableBalance, params.AmountRequested)
 	builtin.RequireState(rt, amountWithdrawn.GreaterThanEqual(big.Zero()), "negative amount to withdraw: %v", amountWithdrawn)
 	builtin.RequireState(rt, amountWithdrawn.LessThanEqual(availableBalance), "amount to withdraw %v < available %v", amountWithdrawn, availableBalance)
 
 	if amountWithdrawn.GreaterThan(abi.NewTokenAmount(0)) {
-		code := rt.Send(info.Owner, builtin.MethodSend, nil, amountWithdrawn, &builtin.Discard{})
+		code := rt.Send(info.Beneficiary, builtin.MethodSend, nil, amountWithdrawn, &builtin.Discard{})
 		builtin.RequireSuccess(rt, code, "failed to withdraw balance")
 	}
 
-	burnFunds(rt, feeToBurn)
+	burnFunds(rt, feeToBurn, builtin.BurnReasonFeeDebt)
 
 	pledgeDelta := newlyVested.Neg()
 	notifyPledgeChanged(rt, pledgeDelta)
 
+	st := ReadState(rt)
 	err := st.CheckBalanceInvariants(rt.CurrentBalance())
 	builtin.RequireNoErr(rt, err, ErrBalanceInvariantBroken, "balance invariants broken")
 
@@ -1859,11 +2482,10 @@ func (a Actor) WithdrawBalance(rt Runtime, params *WithdrawBalanceParams) *abi.E
 }
 
 func (a Actor) RepayDebt(rt Runtime, _ *abi.EmptyValue) *abi.EmptyValue {
-	var st State
 	var fromVesting, fromBalance abi.TokenAmount
-	rt.StateTransaction(&st, func() {
+	WithState(rt, func(st *State) {
 		var err error
-		info := getMinerInfo(rt, &st)
+		info := getMinerInfo(rt, st)
 		rt.ValidateImmediateCallerIs(append(info.ControlAddresses, info.Owner, info.Worker)...)
 
 		// Repay as much fee debt as possible.
@@ -1872,7 +2494,8 @@ func (a Actor) RepayDebt(rt Runtime, _ *abi.EmptyValue) *abi.EmptyValue {
 	})
 
 	notifyPledgeChanged(rt, fromVesting.Neg())
-	burnFunds(rt, big.Sum(fromVesting, fromBalance))
+	burnFunds(rt, big.Sum(fromVesting, fromBalance), builtin.BurnReasonFeeDebt)
+	st := ReadState(rt)
 	err := st.CheckBalanceInvariants(rt.CurrentBalance())
 	builtin.RequireNoErr(rt, err, ErrBalanceInvariantBroken, "balance invariants broken")
 
@@ -1907,8 +2530,7 @@ func (a Actor) OnDeferredCronEvent(rt Runtime, payload *CronEventPayload) *abi.E
 		}
 	}
 
-	var st State
-	rt.StateReadonly(&st)
+	st := ReadState(rt)
 	err := st.CheckBalanceInvariants(rt.CurrentBalance())
 	builtin.RequireNoErr(rt, err, ErrBalanceInvariantBroken, "balance invariants broken")
 	return nil
@@ -1934,8 +2556,7 @@ func processEarlyTerminations(rt Runtime) (more bool) {
 		pledgeDelta      = big.Zero()
 	)
 
-	var st State
-	rt.StateTransaction(&st, func() {
+	WithState(rt, func(st *State) {
 		var err error
 		result, more, err = st.PopEarlyTerminations(store, AddressedPartitionsMax, AddressedSectorsMax)
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to pop early terminations")
@@ -1947,7 +2568,7 @@ func processEarlyTerminations(rt Runtime) (more bool) {
 			return
 		}
 
-		info := getMinerInfo(rt, &st)
+		info := getMinerInfo(rt, st)
 
 		sectors, err := LoadSectors(store, st.Sectors)
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load sectors array")
@@ -1995,7 +2616,7 @@ func processEarlyTerminations(rt Runtime) (more bool) {
 	}
 
 	// Burn penalty.
-	burnFunds(rt, penalty)
+	burnFunds(rt, penalty, builtin.BurnReasonTerminationFee)
 
 	// Return pledge.
 	notifyPledgeChanged(rt, pledgeDelta)
@@ -2022,9 +2643,9 @@ func handleProvingDeadline(rt Runtime) {
 	powerDeltaTotal := NewPowerPairZero()
 	penaltyTotal := abi.NewTokenAmount(0)
 	pledgeDeltaTotal := abi.NewTokenAmount(0)
+	var expiredReservedDeals []abi.DealID
 
-	var st State
-	rt.StateTransaction(&st, func() {
+	WithState(rt, func(st *State) {
 		{
 			// Vest locked funds.
 			// This happens first so that any subsequent penalties are taken
@@ -2036,13 +2657,14 @@ func handleProvingDeadline(rt Runtime) {
 
 		{
 			// Process pending worker change if any
-			info := getMinerInfo(rt, &st)
-			processPendingWorker(info, rt, &st)
+			info := getMinerInfo(rt, st)
+			processPendingWorker(info, rt, st)
 		}
 
 		{
-			depositToBurn, err := st.ExpirePreCommits(store, currEpoch)
+			depositToBurn, reservedDeals, err := st.ExpirePreCommits(store, currEpoch, MaxPreCommitExpiriesPerDeadline)
 			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to expire pre-committed sectors")
+			expiredReservedDeals = reservedDeals
 
 			err = st.ApplyPenalty(depositToBurn)
 			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to apply penalty")
@@ -2050,18 +2672,26 @@ func handleProvingDeadline(rt Runtime) {
 
 		// Record whether or not we _had_ early terminations in the queue before this method.
 		// That way, don't re-schedule a cron callback if one is already scheduled.
-		hadEarlyTerminations = havePendingEarlyTerminations(rt, &st)
+		hadEarlyTerminations = havePendingEarlyTerminations(rt, st)
 
 		{
 			result, err := st.AdvanceDeadline(store, currEpoch)
 			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to advance deadline")
 
-			// Faults detected by this missed PoSt pay no penalty, but sectors that were already faulty
-			// and remain faulty through this deadline pay the fault fee.
-			penaltyTarget := PledgePenaltyForContinuedFault(
-				epochReward.ThisEpochRewardSmoothed,
-				pwrTotal.QualityAdjPowerSmoothed,
-				result.PreviouslyFaultyPower.QA,
+			// All sectors faulty through this deadline pay the fault fee, however the fault became known.
+			// Faults detected by this missed PoSt, including failed recoveries, also pay the detected fault penalty.
+			// Faults declared late, within the self-report window, paid a fraction of it when declared.
+			penaltyTarget := big.Add(
+				PledgePenaltyForContinuedFault(
+					epochReward.ThisEpochRewardSmoothed,
+					pwrTotal.QualityAdjPowerSmoothed,
+					result.TotalFaultyPower.QA,
+				),
+				PledgePenaltyForDetectedFault(
+					epochReward.ThisEpochRewardSmoothed,
+					pwrTotal.QualityAdjPowerSmoothed,
+					result.DetectedFaultyPower.QA,
+				),
 			)
 
 			powerDeltaTotal = powerDeltaTotal.Add(result.PowerDelta)
@@ -2079,17 +2709,23 @@ func handleProvingDeadline(rt Runtime) {
 
 	// Remove power for new faults, and burn penalties.
 	requestUpdatePower(rt, powerDeltaTotal)
-	burnFunds(rt, penaltyTotal)
+	burnFunds(rt, penaltyTotal, builtin.BurnReasonProvingDeadline)
 	notifyPledgeChanged(rt, pledgeDeltaTotal)
 
+	// Release the escrow reserved against deals of expired pre-commitments.
+	requestReleaseCollateral(rt, expiredReservedDeals)
+
 	// Schedule cron callback for next deadline's last epoch.
+	// If this callback ran late, that epoch may already have passed, in which case the power actor
+	// invokes it at the next opportunity.
+	st := ReadState(rt)
 	newDlInfo := st.DeadlineInfo(currEpoch)
 	enrollCronEvent(rt, newDlInfo.Last(), &CronEventPayload{
 		EventType: CronEventProvingDeadline,
 	})
 
 	// Record whether or not we _have_ early terminations now.
-	hasEarlyTerminations := havePendingEarlyTerminations(rt, &st)
+	hasEarlyTerminations := havePendingEarlyTerminations(rt, st)
 
 	// If we didn't have pending early terminations before, but we do now,
 	// handle them at the next epoch.
@@ -2145,13 +2781,13 @@ func validateReplaceSector(rt Runtime, st *State, store adt.Store, params *PreCo
 	// From network version 7, the new sector's seal type must have the same Window PoSt proof type as the one
 	// being replaced, rather than be exactly the same seal type.
 	// This permits replacing sectors with V1 seal types with V1_1 seal types.
-	replaceWPoStProof, err := replaceSector.SealProof.RegisteredWindowPoStProof()
+	replaceProofInfo, err := power.LookupSealProof(replaceSector.SealProof)
 	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to lookup Window PoSt proof type for sector seal proof %d", replaceSector.SealProof)
-	newWPoStProof, err := params.SealProof.RegisteredWindowPoStProof()
+	newProofInfo, err := power.LookupSealProof(params.SealProof)
 	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "failed to lookup Window PoSt proof type for new seal proof %d", params.SealProof)
-	if newWPoStProof != replaceWPoStProof {
+	if newProofInfo.WindowPoStProof != replaceProofInfo.WindowPoStProof {
 		rt.Abortf(exitcode.ErrIllegalArgument, "new sector window PoSt proof type %d must match replaced proof type %d (seal proof type %d)",
-			newWPoStProof, replaceWPoStProof, params.SealProof)
+			newProofInfo.WindowPoStProof, replaceProofInfo.WindowPoStProof, params.SealProof)
 	}
 	if params.Expiration < replaceSector.Expiration {
 		rt.Abortf(exitcode.ErrIllegalArgument, "cannot replace sector %v expiration %v with sooner expiration %v",
@@ -2198,6 +2834,25 @@ func requestUpdatePower(rt Runtime, delta PowerPair) {
 	builtin.RequireSuccess(rt, code, "failed to update power with %v", delta)
 }
 
+// Updates claimed power by a number of deltas, e.g. one per sector, and total pledge with a single message.
+func requestUpdatePowerAndPledge(rt Runtime, deltas []PowerPair, pledgeDelta abi.TokenAmount) {
+	if len(deltas) == 0 && pledgeDelta.IsZero() {
+		return
+	}
+	params := power.UpdateClaimedPowerBatchParams{
+		Deltas:      make([]power.UpdateClaimedPowerParams, len(deltas)),
+		PledgeDelta: pledgeDelta,
+	}
+	for i, delta := range deltas {
+		params.Deltas[i] = power.UpdateClaimedPowerParams{
+			RawByteDelta:         delta.Raw,
+			QualityAdjustedDelta: delta.QA,
+		}
+	}
+	code := rt.Send(builtin.StoragePowerActorAddr, builtin.MethodsPower.UpdateClaimedPowerBatch, &params, big.Zero(), &builtin.Discard{})
+	builtin.RequireSuccess(rt, code, "failed to update power and pledge")
+}
+
 func requestTerminateDeals(rt Runtime, epoch abi.ChainEpoch, dealIDs []abi.DealID) {
 	for len(dealIDs) > 0 {
 		size := min64(cbg.MaxLength, uint64(len(dealIDs)))
@@ -2216,6 +2871,41 @@ func requestTerminateDeals(rt Runtime, epoch abi.ChainEpoch, dealIDs []abi.DealI
 	}
 }
 
+// Reserves amounts of the miner's market escrow against deals being pre-committed, so they cannot be
+// withdrawn before the deals are activated.
+func requestReserveCollateral(rt Runtime, reservations []market.CollateralReservation) {
+	if len(reservations) == 0 {
+		return
+	}
+	code := rt.Send(
+		builtin.StorageMarketActorAddr,
+		builtin.MethodsMarket.ReserveCollateral,
+		&market.ReserveCollateralParams{Reservations: reservations},
+		abi.NewTokenAmount(0),
+		&builtin.Discard{},
+	)
+	builtin.RequireSuccess(rt, code, "failed to reserve collateral, exit code %v", code)
+}
+
+// Releases the market escrow reserved against deals of pre-commitments that will not be activated.
+// Failure is logged rather than aborting, since a deal's reservation is released anyway when the deal times out.
+func requestReleaseCollateral(rt Runtime, dealIDs []abi.DealID) {
+	for len(dealIDs) > 0 {
+		size := min64(market.BatchItemsMax, uint64(len(dealIDs)))
+		code := rt.Send(
+			builtin.StorageMarketActorAddr,
+			builtin.MethodsMarket.ReleaseCollateral,
+			&market.ReleaseCollateralParams{DealIDs: dealIDs[:size]},
+			abi.NewTokenAmount(0),
+			&builtin.Discard{},
+		)
+		if !code.IsSuccess() {
+			rt.Log(rtt.ERROR, "failed to release collateral reserved for %d deals: %v", size, code)
+		}
+		dealIDs = dealIDs[size:]
+	}
+}
+
 func scheduleEarlyTerminationWork(rt Runtime) {
 	enrollCronEvent(rt, rt.CurrEpoch()+1, &CronEventPayload{
 		EventType: CronEventProcessEarlyTerminations,
@@ -2363,6 +3053,137 @@ func requestDealWeights(rt Runtime, sectors []market.SectorDeals) *market.Verify
 	return &dealWeights
 }
 
+// Activates the deals of a number of sectors with a single call to the market actor, returning the result for each
+// sector in order. Sectors without deals are activated without being sent.
+// If the call fails, none of the sectors with deals are activated.
+func requestActivateDeals(rt Runtime, sectors []market.SectorDeals) []market.SectorDealActivation {
+	activations := make([]market.SectorDealActivation, len(sectors))
+	var batch []market.SectorDeals
+	var batchIndexes []int
+	for i, sector := range sectors {
+		activations[i] = market.SectorDealActivation{
+			Activated:          len(sector.DealIDs) == 0,
+			DealWeight:         big.Zero(),
+			VerifiedDealWeight: big.Zero(),
+		}
+		if len(sector.DealIDs) > 0 {
+			batch = append(batch, sector)
+			batchIndexes = append(batchIndexes, i)
+		}
+	}
+	if len(batch) == 0 {
+		return activations
+	}
+
+	var ret market.BatchActivateDealsReturn
+	code := rt.Send(
+		builtin.StorageMarketActorAddr,
+		builtin.MethodsMarket.BatchActivateDeals,
+		&market.BatchActivateDealsParams{Sectors: batch},
+		abi.NewTokenAmount(0),
+		&ret,
+	)
+	if code != exitcode.Ok {
+		rt.Log(rtt.WARN, "failed to activate deals for %d sectors: %v", len(batch), code)
+		return activations
+	}
+	if len(ret.Sectors) != len(batch) {
+		rt.Abortf(exitcode.ErrIllegalState, "deal activation returned %d records, expected %d", len(ret.Sectors), len(batch))
+	}
+	for j, i := range batchIndexes {
+		activations[i] = ret.Sectors[j]
+	}
+	return activations
+}
+
+// Checks that a sector's allocation claims are well formed, with no more claims than the sector has deals.
+func validateAllocationClaims(rt Runtime, claims []AllocationClaim, dealCount int) {
+	if len(claims) > dealCount {
+		rt.Abortf(exitcode.ErrIllegalArgument, "too many allocation claims %d for %d deals", len(claims), dealCount)
+	}
+	claimedAllocations := make(map[verifreg.AllocationId]struct{}, len(claims))
+	for _, claim := range claims {
+		if _, dup := claimedAllocations[claim.AllocationID]; dup {
+			rt.Abortf(exitcode.ErrIllegalArgument, "allocation %d claimed more than once", claim.AllocationID)
+		}
+		claimedAllocations[claim.AllocationID] = struct{}{}
+		if !claim.Data.Defined() {
+			rt.Abortf(exitcode.ErrIllegalArgument, "allocation %d claimed with undefined data CID", claim.AllocationID)
+		}
+	}
+}
+
+// The allocation claims of a sector being activated, either by prove-commit or by a replica update.
+type sectorAllocationClaims struct {
+	sector     abi.SectorNumber
+	expiration abi.ChainEpoch
+	claims     []AllocationClaim
+}
+
+// Claims the allocations of DataCap for the pieces of sectors being activated with a single call to the
+// verified registry actor, returning the weight of the deals claimed by each sector.
+// Each claimed piece must be the piece of a distinct one of the sector's activated deals which is not a verified deal.
+// Verified deals have already used their client's DataCap, so the space of their pieces cannot be claimed again.
+func requestClaimAllocations(rt Runtime, sectors []sectorAllocationClaims, activations []market.SectorDealActivation) []abi.DealWeight {
+	claimedWeight := make([]abi.DealWeight, len(sectors))
+	totalClaimedSpace := big.Zero()
+	var claims []verifreg.SectorAllocationClaim
+	for i, sector := range sectors {
+		// Weights of the unclaimed unverified deals for each piece, by CID and size, in deal order.
+		unclaimed := make(map[abi.PieceInfo][]abi.DealWeight, len(activations[i].UnverifiedPieces))
+		for _, piece := range activations[i].UnverifiedPieces {
+			info := abi.PieceInfo{Size: piece.Size, PieceCID: piece.PieceCID}
+			unclaimed[info] = append(unclaimed[info], piece.DealWeight)
+		}
+
+		claimedWeight[i] = big.Zero()
+		for _, claim := range sector.claims {
+			piece := abi.PieceInfo{Size: claim.Size, PieceCID: claim.Data}
+			weights := unclaimed[piece]
+			if len(weights) == 0 {
+				rt.Abortf(exitcode.ErrIllegalArgument, "sector %d claims allocation %d for %v of size %d, which is not the piece of any of its unclaimed unverified deals",
+					sector.sector, claim.AllocationID, claim.Data, claim.Size)
+			}
+			unclaimed[piece] = weights[1:]
+			claims = append(claims, verifreg.SectorAllocationClaim{
+				AllocationId: claim.AllocationID,
+				Data:         claim.Data,
+				Size:         claim.Size,
+				Sector:       sector.sector,
+				SectorExpiry: sector.expiration,
+			})
+			claimedWeight[i] = big.Add(claimedWeight[i], weights[0])
+			totalClaimedSpace = big.Add(totalClaimedSpace, big.NewIntUnsigned(uint64(claim.Size)))
+		}
+	}
+	if len(claims) == 0 {
+		return claimedWeight
+	}
+
+	var ret verifreg.ClaimAllocationsReturn
+	code := rt.Send(
+		builtin.VerifiedRegistryActorAddr,
+		builtin.MethodsVerifiedRegistry.ClaimAllocations,
+		&verifreg.ClaimAllocationsParams{Sectors: claims},
+		abi.NewTokenAmount(0),
+		&ret,
+	)
+	builtin.RequireSuccess(rt, code, "failed to claim allocations")
+	// The registry requires the size of each claim to match its allocation, so sizes account for all the space claimed.
+	if !ret.ClaimedSpace.Equals(totalClaimedSpace) {
+		rt.Abortf(exitcode.ErrIllegalState, "claimed space %v does not match the %v claimed by sectors", ret.ClaimedSpace, totalClaimedSpace)
+	}
+	return claimedWeight
+}
+
+// Computes a sector's deal weights once deals have been claimed against verified allocations.
+// The weight of each claimed deal is moved from the sector's deal weight to its verified deal weight.
+func claimedDealWeights(activation market.SectorDealActivation, claimedWeight abi.DealWeight) (dealWeight, verifiedDealWeight abi.DealWeight) {
+	dealWeight = big.Sub(activation.DealWeight, claimedWeight)
+	verifiedDealWeight = big.Add(activation.VerifiedDealWeight, claimedWeight)
+	return dealWeight, verifiedDealWeight
+}
+
 // Requests the current epoch target block reward from the reward actor.
 // return value includes reward, smoothed estimate of reward, and baseline power
 func requestCurrentEpochBlockReward(rt Runtime) reward.ThisEpochRewardReturn {
@@ -2412,9 +3233,8 @@ func resolveWorkerAddress(rt Runtime, raw addr.Address) addr.Address {
 	}
 
 	if raw.Protocol() != addr.BLS {
-		var pubkey addr.Address
-		code := rt.Send(resolved, builtin.MethodsAccount.PubkeyAddress, nil, big.Zero(), &pubkey)
-		builtin.RequireSuccess(rt, code, "failed to fetch account pubkey from %v", resolved)
+		pubkey, err := builtin.RequestRobustAddress(rt, resolved)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to fetch account pubkey from %v", resolved)
 		if pubkey.Protocol() != addr.BLS {
 			rt.Abortf(exitcode.ErrIllegalArgument, "worker account %v must have BLS pubkey, was %v", resolved, pubkey.Protocol())
 		}
@@ -2422,11 +3242,9 @@ func resolveWorkerAddress(rt Runtime, raw addr.Address) addr.Address {
 	return resolved
 }
 
-func burnFunds(rt Runtime, amt abi.TokenAmount) {
-	if amt.GreaterThan(big.Zero()) {
-		code := rt.Send(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, amt, &builtin.Discard{})
-		builtin.RequireSuccess(rt, code, "failed to burn funds")
-	}
+func burnFunds(rt Runtime, amt abi.TokenAmount, reason builtin.BurnReason) {
+	code := builtin.BurnWithReason(rt, amt, reason)
+	builtin.RequireSuccess(rt, code, "failed to burn funds")
 }
 
 func notifyPledgeChanged(rt Runtime, pledgeDelta abi.TokenAmount) {
@@ -2540,6 +3358,18 @@ func validateFRDeclarationDeadline(deadline *dline.Info) error {
 	return nil
 }
 
+// Checks that a fault declaration at a specific deadline is no later than the end of the deadline's self-report
+// window, and returns whether it is late, i.e. after the deadline's fault declaration cutoff.
+func validateFaultDeclarationDeadline(deadline *dline.Info) (late bool, err error) {
+	if !deadline.FaultCutoffPassed() {
+		return false, nil
+	}
+	if deadline.CurrentEpoch >= deadline.Open+FaultSelfReportWindow {
+		return true, fmt.Errorf("fault declaration at %v after self-report window of %d epochs", deadline, FaultSelfReportWindow)
+	}
+	return true, nil
+}
+
 // Validates that a partition contains the given sectors.
 func validatePartitionContainsSectors(partition *Partition, sectors bitfield.BitField) error {
 	// Check that the declared sectors are actually assigned to the partition.
@@ -16,6 +16,8 @@ import (
 	xerrors "golang.org/x/xerrors"
 
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin/market"
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin/verifreg"
 	"github.com/filecoin-project/specs-actors/v3/actors/util/adt"
 )
 
@@ -81,6 +83,9 @@ type State struct {
 const PrecommitExpiryAmtBitwidth = 6
 const SectorsAmtBitwidth = 5
 
+// Bitwidth of the HAMT of pre-committed sectors.
+const PrecommittedSectorsHamtBitwidth = builtin.DefaultHamtBitwidth
+
 type MinerInfo struct {
 	// Account that owns this miner.
 	// - Income and returned collateral are paid to this address.
@@ -122,6 +127,16 @@ type MinerInfo struct {
 	// A proposed new owner account for this miner.
 	// Must be confirmed by a message from the pending address itself.
 	PendingOwnerAddress *addr.Address
+
+	// Account receiving funds withdrawn from this miner, up to the quota in the beneficiary term.
+	// Defaults to the owner, in which case the term places no limit on withdrawals.
+	Beneficiary addr.Address // Must be an ID-address.
+
+	// The limits on withdrawals to a beneficiary other than the owner.
+	BeneficiaryTerm BeneficiaryTerm
+
+	// A proposed change of beneficiary or term, which takes effect once approved by all affected parties.
+	PendingBeneficiaryTerm *PendingBeneficiaryChange
 }
 
 type WorkerKeyChange struct {
@@ -129,6 +144,41 @@ type WorkerKeyChange struct {
 	EffectiveAt abi.ChainEpoch
 }
 
+type BeneficiaryTerm struct {
+	// The total amount the beneficiary may withdraw.
+	Quota abi.TokenAmount
+	// The amount the beneficiary has withdrawn so far.
+	UsedQuota abi.TokenAmount
+	// The epoch at which the beneficiary's right to withdraw ends.
+	Expiration abi.ChainEpoch
+}
+
+// Whether the beneficiary has withdrawn its entire quota.
+func (t *BeneficiaryTerm) IsUsedUp() bool {
+	return t.UsedQuota.GreaterThanEqual(t.Quota)
+}
+
+// Whether the term has expired as of the given epoch.
+func (t *BeneficiaryTerm) IsExpired(currEpoch abi.ChainEpoch) bool {
+	return t.Expiration <= currEpoch
+}
+
+// The amount the beneficiary may still withdraw as of the given epoch.
+func (t *BeneficiaryTerm) Available(currEpoch abi.ChainEpoch) abi.TokenAmount {
+	if t.IsExpired(currEpoch) {
+		return big.Zero()
+	}
+	return big.Max(big.Sub(t.Quota, t.UsedQuota), big.Zero())
+}
+
+type PendingBeneficiaryChange struct {
+	NewBeneficiary        addr.Address // Must be an ID address
+	NewQuota              abi.TokenAmount
+	NewExpiration         abi.ChainEpoch
+	ApprovedByBeneficiary bool // Approved by the current beneficiary
+	ApprovedByNominee     bool // Approved by the new beneficiary
+}
+
 // Information provided by a miner when pre-committing a sector.
 type SectorPreCommitInfo struct {
 	SealProof       abi.RegisteredSealProof
@@ -142,6 +192,17 @@ type SectorPreCommitInfo struct {
 	ReplaceSectorDeadline  uint64
 	ReplaceSectorPartition uint64
 	ReplaceSectorNumber    abi.SectorNumber
+	// Pieces of the sector's deals to be claimed against verified clients' allocations of DataCap at activation
+	AllocationClaims []AllocationClaim
+	// Amounts of the miner's market escrow to reserve against the sector's deals until they are activated
+	CollateralReservations []market.CollateralReservation
+}
+
+// A piece of a sector's data that claims a verified client's allocation of DataCap when the sector is activated.
+type AllocationClaim struct {
+	AllocationID verifreg.AllocationId
+	Data         cid.Cid             `checked:"true"` // Piece CID, checked to be the piece of one of the sector's deals
+	Size         abi.PaddedPieceSize // Padded size of the piece, which must match the allocation
 }
 
 // Information stored on-chain for a pre-committed sector.
@@ -171,7 +232,7 @@ type SectorOnChainInfo struct {
 }
 
 func ConstructState(store adt.Store, infoCid cid.Cid, periodStart abi.ChainEpoch, deadlineIndex uint64) (*State, error) {
-	emptyPrecommitMapCid, err := adt.StoreEmptyMap(store, builtin.DefaultHamtBitwidth)
+	emptyPrecommitMapCid, err := adt.StoreEmptyMap(store, PrecommittedSectorsHamtBitwidth)
 	if err != nil {
 		return nil, xerrors.Errorf("failed to construct empty map: %w", err)
 	}
@@ -229,6 +290,23 @@ func ConstructState(store adt.Store, infoCid cid.Cid, periodStart abi.ChainEpoch
 	}, nil
 }
 
+// Loads a mutable copy of the miner state and passes it to f, committing the state when f returns.
+// As with rt.StateTransaction, f must not send messages or open a nested transaction, and the state
+// must not be retained after f returns.
+func WithState(rt Runtime, f func(st *State)) {
+	var st State
+	rt.StateTransaction(&st, func() {
+		f(&st)
+	})
+}
+
+// Loads a read-only copy of the miner state.
+func ReadState(rt Runtime) *State {
+	var st State
+	rt.StateReadonly(&st)
+	return &st
+}
+
 func ConstructMinerInfo(owner, worker addr.Address, controlAddrs []addr.Address, pid []byte, multiAddrs [][]byte,
 	windowPoStProofType abi.RegisteredPoStProof) (*MinerInfo, error) {
 	sectorSize, err := windowPoStProofType.SectorSize()
@@ -253,6 +331,13 @@ func ConstructMinerInfo(owner, worker addr.Address, controlAddrs []addr.Address,
 		WindowPoStPartitionSectors: partitionSectors,
 		ConsensusFaultElapsed:      abi.ChainEpoch(-1),
 		PendingOwnerAddress:        nil,
+		Beneficiary:                owner,
+		BeneficiaryTerm: BeneficiaryTerm{
+			Quota:      big.Zero(),
+			UsedQuota:  big.Zero(),
+			Expiration: 0,
+		},
+		PendingBeneficiaryTerm: nil,
 	}, nil
 }
 
@@ -339,7 +424,7 @@ func (st *State) MaskSectorNumbers(store adt.Store, sectorNos bitfield.BitField)
 
 // Stores a pre-committed sector info, failing if the sector number is already present.
 func (st *State) PutPrecommittedSector(store adt.Store, info *SectorPreCommitOnChainInfo) error {
-	precommitted, err := adt.AsMap(store, st.PreCommittedSectors, builtin.DefaultHamtBitwidth)
+	precommitted, err := adt.AsMap(store, st.PreCommittedSectors, PrecommittedSectorsHamtBitwidth)
 	if err != nil {
 		return err
 	}
@@ -354,7 +439,7 @@ func (st *State) PutPrecommittedSector(store adt.Store, info *SectorPreCommitOnC
 }
 
 func (st *State) GetPrecommittedSector(store adt.Store, sectorNo abi.SectorNumber) (*SectorPreCommitOnChainInfo, bool, error) {
-	precommitted, err := adt.AsMap(store, st.PreCommittedSectors, builtin.DefaultHamtBitwidth)
+	precommitted, err := adt.AsMap(store, st.PreCommittedSectors, PrecommittedSectorsHamtBitwidth)
 	if err != nil {
 		return nil, false, err
 	}
@@ -370,7 +455,7 @@ func (st *State) GetPrecommittedSector(store adt.Store, sectorNo abi.SectorNumbe
 // This method gets and returns the requested pre-committed sectors, skipping
 // missing sectors.
 func (st *State) FindPrecommittedSectors(store adt.Store, sectorNos ...abi.SectorNumber) ([]*SectorPreCommitOnChainInfo, error) {
-	precommitted, err := adt.AsMap(store, st.PreCommittedSectors, builtin.DefaultHamtBitwidth)
+	precommitted, err := adt.AsMap(store, st.PreCommittedSectors, PrecommittedSectorsHamtBitwidth)
 	if err != nil {
 		return nil, err
 	}
@@ -394,7 +479,7 @@ func (st *State) FindPrecommittedSectors(store adt.Store, sectorNos ...abi.Secto
 }
 
 func (st *State) DeletePrecommittedSectors(store adt.Store, sectorNos ...abi.SectorNumber) error {
-	precommitted, err := adt.AsMap(store, st.PreCommittedSectors, builtin.DefaultHamtBitwidth)
+	precommitted, err := adt.AsMap(store, st.PreCommittedSectors, PrecommittedSectorsHamtBitwidth)
 	if err != nil {
 		return err
 	}
@@ -732,6 +817,118 @@ func (st *State) SaveDeadlines(store adt.Store, deadlines *Deadlines) error {
 	return nil
 }
 
+// The proving status of a deadline, with the timing of its next challenge window.
+type DeadlineStatus struct {
+	Partitions uint64
+	// Partitions proven so far in the deadline's challenge window, if the window is open, or zero.
+	PartitionsPoSted  uint64
+	LiveSectors       uint64 // Non-terminated sectors, including faulty ones.
+	TotalSectors      uint64 // All sectors, including terminated ones.
+	FaultySectors     uint64
+	RecoveringSectors uint64 // Faulty sectors declared as recovering, a subset of the faulty sectors.
+	FaultyPower       PowerPair
+	// The current challenge window if it is open, or else the next one.
+	ChallengeOpen  abi.ChainEpoch // First epoch of the window.
+	ChallengeClose abi.ChainEpoch // First epoch after the window.
+	Challenge      abi.ChainEpoch // Epoch of the window's challenge randomness.
+	FaultCutoff    abi.ChainEpoch // First epoch at which faults or recoveries may no longer be declared for the window.
+}
+
+// Computes the proving status of a deadline at an epoch.
+// Sector counts reflect the state as of the last processing of the miner's cron, as do the partitions proven.
+func (st *State) DeadlineStatus(store adt.Store, dlIdx uint64, currEpoch abi.ChainEpoch) (*DeadlineStatus, error) {
+	deadlines, err := st.LoadDeadlines(store)
+	if err != nil {
+		return nil, err
+	}
+	dl, err := deadlines.LoadDeadline(store, dlIdx)
+	if err != nil {
+		return nil, err
+	}
+	partitions, err := dl.PartitionsArray(store)
+	if err != nil {
+		return nil, err
+	}
+	posted, err := dl.PartitionsPoSted.Count()
+	if err != nil {
+		return nil, xerrors.Errorf("failed to count posted partitions of deadline %d: %w", dlIdx, err)
+	}
+
+	dlInfo := NewDeadlineInfo(st.ProvingPeriodStart, dlIdx, currEpoch).NextNotElapsed()
+	status := &DeadlineStatus{
+		Partitions:     partitions.Length(),
+		LiveSectors:    dl.LiveSectors,
+		TotalSectors:   dl.TotalSectors,
+		FaultyPower:    dl.FaultyPower,
+		ChallengeOpen:  dlInfo.Open,
+		ChallengeClose: dlInfo.Close,
+		Challenge:      dlInfo.Challenge,
+		FaultCutoff:    dlInfo.FaultCutoff,
+	}
+	if dlInfo.IsOpen() {
+		status.PartitionsPoSted = posted
+	}
+
+	var partition Partition
+	if err := partitions.ForEach(&partition, func(partIdx int64) error {
+		faulty, err := partition.Faults.Count()
+		if err != nil {
+			return xerrors.Errorf("failed to count faults of partition %d: %w", partIdx, err)
+		}
+		recovering, err := partition.Recoveries.Count()
+		if err != nil {
+			return xerrors.Errorf("failed to count recoveries of partition %d: %w", partIdx, err)
+		}
+		status.FaultySectors += faulty
+		status.RecoveringSectors += recovering
+		return nil
+	}); err != nil {
+		return nil, xerrors.Errorf("failed to iterate partitions of deadline %d: %w", dlIdx, err)
+	}
+	return status, nil
+}
+
+// The commitment, lifetime, deals and pledge of a sector, with its location among the miner's deadlines.
+type SectorStatus struct {
+	SectorNumber       abi.SectorNumber
+	SealProof          abi.RegisteredSealProof
+	SealedCID          cid.Cid // CommR
+	DealIDs            []abi.DealID
+	Activation         abi.ChainEpoch // Epoch during which the sector proof was accepted
+	Expiration         abi.ChainEpoch // Epoch during which the sector expires
+	DealWeight         abi.DealWeight
+	VerifiedDealWeight abi.DealWeight
+	InitialPledge      abi.TokenAmount
+	Deadline           uint64
+	Partition          uint64
+}
+
+// Returns the status of a sector, or false if the sector is not found.
+// Only proven sectors are found; a sector is removed once it has expired or its termination been processed.
+func (st *State) SectorStatus(store adt.Store, sectorNo abi.SectorNumber) (*SectorStatus, bool, error) {
+	sector, found, err := st.GetSector(store, sectorNo)
+	if err != nil || !found {
+		return nil, found, err
+	}
+	dlIdx, partIdx, err := st.FindSector(store, sectorNo)
+	if err != nil {
+		return nil, false, xerrors.Errorf("failed to find sector %d: %w", sectorNo, err)
+	}
+	return &SectorStatus{
+		SectorNumber:       sector.SectorNumber,
+		SealProof:          sector.SealProof,
+		SealedCID:          sector.SealedCID,
+		DealIDs:            sector.DealIDs,
+		Activation:         sector.Activation,
+		Expiration:         sector.Expiration,
+		DealWeight:         sector.DealWeight,
+		VerifiedDealWeight: sector.VerifiedDealWeight,
+		InitialPledge:      sector.InitialPledge,
+		Deadline:           dlIdx,
+		Partition:          partIdx,
+	}, true, nil
+}
+
 // LoadVestingFunds loads the vesting funds table from the store
 func (st *State) LoadVestingFunds(store adt.Store) (*VestingFunds, error) {
 	var funds VestingFunds
@@ -1008,24 +1205,48 @@ func (st *State) AddPreCommitExpiry(store adt.Store, expireEpoch abi.ChainEpoch,
 	return nil
 }
 
-func (st *State) ExpirePreCommits(store adt.Store, currEpoch abi.ChainEpoch) (depositToBurn abi.TokenAmount, err error) {
+// Removes pre-committed sectors whose expiration has passed, returning their deposits to be burnt and the deals
+// against which they reserved collateral, to be released.
+// At most maxSectors are removed. Any further expired pre-commitments remain queued, for removal by a later call.
+func (st *State) ExpirePreCommits(store adt.Store, currEpoch abi.ChainEpoch, maxSectors uint64) (depositToBurn abi.TokenAmount, reservedDeals []abi.DealID, err error) {
 	depositToBurn = abi.NewTokenAmount(0)
 
 	// expire pre-committed sectors
 	expiryQ, err := LoadBitfieldQueue(store, st.PreCommittedSectorsExpiry, st.QuantSpecEveryDeadline(), PrecommitExpiryAmtBitwidth)
 	if err != nil {
-		return depositToBurn, xerrors.Errorf("failed to load sector expiry queue: %w", err)
+		return depositToBurn, nil, xerrors.Errorf("failed to load sector expiry queue: %w", err)
 	}
 
 	sectors, modified, err := expiryQ.PopUntil(currEpoch)
 	if err != nil {
-		return depositToBurn, xerrors.Errorf("failed to pop expired sectors: %w", err)
+		return depositToBurn, nil, xerrors.Errorf("failed to pop expired sectors: %w", err)
+	}
+
+	count, err := sectors.Count()
+	if err != nil {
+		return depositToBurn, nil, xerrors.Errorf("failed to count expired sectors: %w", err)
+	}
+	if count > maxSectors {
+		// Process the lowest-numbered sectors now, and return the rest to the queue at the current epoch
+		// so they're popped first next time.
+		batch, err := sectors.Slice(0, maxSectors)
+		if err != nil {
+			return depositToBurn, nil, xerrors.Errorf("failed to slice expired sectors: %w", err)
+		}
+		rest, err := bitfield.SubtractBitField(sectors, batch)
+		if err != nil {
+			return depositToBurn, nil, xerrors.Errorf("failed to subtract expired sectors: %w", err)
+		}
+		if err = expiryQ.AddToQueue(currEpoch, rest); err != nil {
+			return depositToBurn, nil, xerrors.Errorf("failed to re-queue expired sectors: %w", err)
+		}
+		sectors = batch
 	}
 
 	if modified {
 		st.PreCommittedSectorsExpiry, err = expiryQ.Root()
 		if err != nil {
-			return depositToBurn, xerrors.Errorf("failed to save expiry queue: %w", err)
+			return depositToBurn, nil, xerrors.Errorf("failed to save expiry queue: %w", err)
 		}
 	}
 
@@ -1046,25 +1267,29 @@ func (st *State) ExpirePreCommits(store adt.Store, currEpoch abi.ChainEpoch) (de
 
 		// increment deposit to burn
 		depositToBurn = big.Add(depositToBurn, sector.PreCommitDeposit)
+
+		for _, reservation := range sector.Info.CollateralReservations {
+			reservedDeals = append(reservedDeals, reservation.DealID)
+		}
 		return nil
 	}); err != nil {
-		return big.Zero(), xerrors.Errorf("failed to check pre-commit expiries: %w", err)
+		return big.Zero(), nil, xerrors.Errorf("failed to check pre-commit expiries: %w", err)
 	}
 
 	// Actually delete it.
 	if len(precommitsToDelete) > 0 {
 		if err := st.DeletePrecommittedSectors(store, precommitsToDelete...); err != nil {
-			return big.Zero(), fmt.Errorf("failed to delete pre-commits: %w", err)
+			return big.Zero(), nil, fmt.Errorf("failed to delete pre-commits: %w", err)
 		}
 	}
 
 	st.PreCommitDeposits = big.Sub(st.PreCommitDeposits, depositToBurn)
 	if st.PreCommitDeposits.LessThan(big.Zero()) {
-		return big.Zero(), xerrors.Errorf("pre-commit expiry caused negative deposits: %v", st.PreCommitDeposits)
+		return big.Zero(), nil, xerrors.Errorf("pre-commit expiry caused negative deposits: %v", st.PreCommitDeposits)
 	}
 
 	// This deposit was locked separately to pledge collateral so there's no pledge change here.
-	return depositToBurn, nil
+	return depositToBurn, reservedDeals, nil
 }
 
 type AdvanceDeadlineResult struct {
@@ -1089,9 +1314,10 @@ func (st *State) AdvanceDeadline(store adt.Store, currEpoch abi.ChainEpoch) (*Ad
 	detectedFaultyPower := NewPowerPairZero()
 
 	// Note: Use dlInfo.Last() rather than rt.CurrEpoch unless certain
-	// of the desired semantics. In the past, this method would sometimes be
-	// invoked late due to skipped blocks. This is no longer the case, but
-	// we still use dlInfo.Last().
+	// of the desired semantics. This method may be invoked after the deadline's
+	// last epoch when the power actor defers cron callbacks beyond its per-epoch
+	// budget. The deadline processed is always the one recorded in state, so a
+	// late callback handles it as if it had run on time.
 	dlInfo := st.DeadlineInfo(currEpoch)
 
 	// Return early if the proving period hasn't started. While actors v2
@@ -15,6 +15,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin/market"
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin/miner"
 	"github.com/filecoin-project/specs-actors/v3/actors/util/adt"
 	"github.com/filecoin-project/specs-actors/v3/support/ipld"
@@ -625,6 +626,85 @@ func TestAddPreCommitExpiry(t *testing.T) {
 	})
 }
 
+func TestExpirePreCommits(t *testing.T) {
+	expiry := abi.ChainEpoch(100)
+	setup := func(t *testing.T) *stateHarness {
+		harness := constructStateHarness(t, abi.ChainEpoch(0))
+		for i := abi.SectorNumber(1); i <= 5; i++ {
+			harness.putPreCommit(newSectorPreCommitOnChainInfo(i, tutils.MakeCID(fmt.Sprint(i), &miner.SealedCIDPrefix), abi.NewTokenAmount(int64(i)), abi.ChainEpoch(1)))
+			require.NoError(t, harness.s.AddPreCommitExpiry(harness.store, expiry, i))
+		}
+		harness.s.PreCommitDeposits = abi.NewTokenAmount(15)
+		return harness
+	}
+
+	t.Run("nothing expires before the expiry epoch", func(t *testing.T) {
+		harness := setup(t)
+		burnt, _, err := harness.s.ExpirePreCommits(harness.store, expiry-1, 10)
+		require.NoError(t, err)
+		assert.Equal(t, big.Zero(), burnt)
+		assert.True(t, harness.hasPreCommit(1))
+	})
+
+	t.Run("expires all pre-commits within the bound", func(t *testing.T) {
+		harness := setup(t)
+		epoch := harness.s.QuantSpecEveryDeadline().QuantizeUp(expiry)
+		burnt, _, err := harness.s.ExpirePreCommits(harness.store, epoch, 10)
+		require.NoError(t, err)
+		assert.Equal(t, abi.NewTokenAmount(15), burnt)
+		assert.True(t, harness.s.PreCommitDeposits.IsZero())
+		for i := abi.SectorNumber(1); i <= 5; i++ {
+			assert.False(t, harness.hasPreCommit(i))
+		}
+	})
+
+	t.Run("expires pre-commits in bounded batches", func(t *testing.T) {
+		harness := setup(t)
+		epoch := harness.s.QuantSpecEveryDeadline().QuantizeUp(expiry)
+
+		burnt, _, err := harness.s.ExpirePreCommits(harness.store, epoch, 2)
+		require.NoError(t, err)
+		assert.Equal(t, abi.NewTokenAmount(1+2), burnt)
+		assert.False(t, harness.hasPreCommit(1))
+		assert.False(t, harness.hasPreCommit(2))
+		assert.True(t, harness.hasPreCommit(3))
+
+		// The remainder is processed by subsequent calls, lowest sector numbers first.
+		nextEpoch := epoch + miner.WPoStChallengeWindow
+		burnt, _, err = harness.s.ExpirePreCommits(harness.store, nextEpoch, 2)
+		require.NoError(t, err)
+		assert.Equal(t, abi.NewTokenAmount(3+4), burnt)
+		assert.True(t, harness.hasPreCommit(5))
+
+		burnt, _, err = harness.s.ExpirePreCommits(harness.store, nextEpoch+miner.WPoStChallengeWindow, 2)
+		require.NoError(t, err)
+		assert.Equal(t, abi.NewTokenAmount(5), burnt)
+		assert.False(t, harness.hasPreCommit(5))
+		assert.True(t, harness.s.PreCommitDeposits.IsZero())
+
+		queue, err := miner.LoadBitfieldQueue(harness.store, harness.s.PreCommittedSectorsExpiry, harness.s.QuantSpecEveryDeadline(), miner.PrecommitExpiryAmtBitwidth)
+		require.NoError(t, err)
+		assert.EqualValues(t, 0, queue.Length())
+	})
+
+	t.Run("returns deals with collateral reserved by expired pre-commits", func(t *testing.T) {
+		harness := constructStateHarness(t, abi.ChainEpoch(0))
+		precommit := newSectorPreCommitOnChainInfo(1, tutils.MakeCID("1", &miner.SealedCIDPrefix), abi.NewTokenAmount(1), abi.ChainEpoch(1))
+		precommit.Info.DealIDs = []abi.DealID{10, 11, 12}
+		precommit.Info.CollateralReservations = []market.CollateralReservation{
+			{DealID: 10, Amount: abi.NewTokenAmount(100)},
+			{DealID: 12, Amount: abi.NewTokenAmount(100)},
+		}
+		harness.putPreCommit(precommit)
+		require.NoError(t, harness.s.AddPreCommitExpiry(harness.store, expiry, 1))
+		harness.s.PreCommitDeposits = abi.NewTokenAmount(1)
+
+		_, reservedDeals, err := harness.s.ExpirePreCommits(harness.store, harness.s.QuantSpecEveryDeadline().QuantizeUp(expiry), 10)
+		require.NoError(t, err)
+		assert.Equal(t, []abi.DealID{10, 12}, reservedDeals)
+	})
+}
+
 func TestSectorAssignment(t *testing.T) {
 	partitionSectors, err := builtin.SealProofWindowPoStPartitionSectors(abi.RegisteredSealProof_StackedDrg32GiBV1_1)
 	require.NoError(t, err)
@@ -913,6 +993,7 @@ func constructStateHarness(t *testing.T, periodBoundary abi.ChainEpoch) *stateHa
 		WindowPoStProofType:        testWindowPoStProofType,
 		SectorSize:                 sectorSize,
 		WindowPoStPartitionSectors: partitionSectors,
+		Beneficiary:                owner,
 	}
 	infoCid, err := store.Put(context.Background(), &info)
 	require.NoError(t, err)
@@ -27,6 +27,7 @@ import (
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin/miner"
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin/power"
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin/reward"
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin/verifreg"
 	"github.com/filecoin-project/specs-actors/v3/actors/runtime"
 	"github.com/filecoin-project/specs-actors/v3/actors/runtime/proof"
 	"github.com/filecoin-project/specs-actors/v3/actors/util/adt"
@@ -56,7 +57,9 @@ func init() {
 	}
 
 	// permit 2KiB sectors in tests
-	miner.PreCommitSealProofTypesV8[abi.RegisteredSealProof_StackedDrg2KiBV1_1] = struct{}{}
+	if err := power.EnableSealProof(abi.RegisteredSealProof_StackedDrg2KiBV1_1, network.Version0, network.VersionMax); err != nil {
+		panic(err)
+	}
 }
 
 func TestExports(t *testing.T) {
@@ -595,6 +598,46 @@ func TestCommitments(t *testing.T) {
 		actor.checkState(rt)
 	})
 
+	t.Run("collateral reserved at pre-commit is released when the pre-commitment expires", func(t *testing.T) {
+		actor := newHarness(t, periodOffset)
+		rt := builderForHarness(actor).
+			WithBalance(bigBalance, big.Zero()).
+			Build(t)
+		precommitEpoch := periodOffset + 1
+		rt.SetEpoch(precommitEpoch)
+		actor.constructAndVerify(rt)
+		dlInfo := actor.deadline(rt)
+
+		sectorNo := abi.SectorNumber(100)
+		expiration := dlInfo.PeriodEnd() + defaultSectorExpiration*miner.WPoStProvingPeriod
+		params := actor.makePreCommit(sectorNo, precommitEpoch-1, expiration, []abi.DealID{1, 2, 3})
+		params.CollateralReservations = []market.CollateralReservation{
+			{DealID: 1, Amount: abi.NewTokenAmount(1000)},
+			{DealID: 3, Amount: abi.NewTokenAmount(2000)},
+		}
+		precommit := actor.preCommitSector(rt, params, preCommitConf{})
+		assert.Equal(t, params.CollateralReservations, precommit.Info.CollateralReservations)
+
+		// The pre-commitment is never proven, so expires at the deadline following its expiry bound.
+		st := getState(rt)
+		expiryBound := precommitEpoch + miner.MaxProveCommitDuration[precommit.Info.SealProof] + 1
+		expiryEpoch := st.QuantSpecEveryDeadline().QuantizeUp(expiryBound)
+		for dlInfo.Last() < expiryEpoch {
+			dlInfo = advanceDeadline(rt, actor, &cronConfig{})
+		}
+		advanceDeadline(rt, actor, &cronConfig{
+			expiredPreCommitDeposit: precommit.PreCommitDeposit,
+			penaltyFromUnlocked:     precommit.PreCommitDeposit,
+			releasedCollateralDeals: []abi.DealID{1, 3},
+		})
+
+		st = getState(rt)
+		_, found, err := st.GetPrecommittedSector(rt.AdtStore(), sectorNo)
+		require.NoError(t, err)
+		assert.False(t, found)
+		actor.checkState(rt)
+	})
+
 	t.Run("precommit pays back fee debt", func(t *testing.T) {
 		actor := newHarness(t, periodOffset)
 		rt := builderForHarness(actor).
@@ -647,6 +690,40 @@ func TestCommitments(t *testing.T) {
 		})
 		rt.Reset()
 
+		// More allocation claims than deals
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "too many allocation claims", func() {
+			pc := actor.makePreCommit(102, challengeEpoch, expiration, nil)
+			pc.AllocationClaims = []miner.AllocationClaim{{AllocationID: 1, Data: tutil.MakeCID("piece", &market.PieceCIDPrefix), Size: 1 << 10}}
+			actor.preCommitSector(rt, pc, preCommitConf{})
+		})
+		rt.Reset()
+
+		// Allocation claimed twice
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "claimed more than once", func() {
+			pc := actor.makePreCommit(102, challengeEpoch, expiration, []abi.DealID{1, 2})
+			claim := miner.AllocationClaim{AllocationID: 1, Data: tutil.MakeCID("piece", &market.PieceCIDPrefix), Size: 1 << 10}
+			pc.AllocationClaims = []miner.AllocationClaim{claim, claim}
+			actor.preCommitSector(rt, pc, preCommitConf{})
+		})
+		rt.Reset()
+
+		// Collateral reserved against a deal not in the sector
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "not in sector", func() {
+			pc := actor.makePreCommit(102, challengeEpoch, expiration, []abi.DealID{1})
+			pc.CollateralReservations = []market.CollateralReservation{{DealID: 2, Amount: abi.NewTokenAmount(1)}}
+			actor.preCommitSector(rt, pc, preCommitConf{})
+		})
+		rt.Reset()
+
+		// Collateral reserved twice against a deal
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "more than once", func() {
+			pc := actor.makePreCommit(102, challengeEpoch, expiration, []abi.DealID{1})
+			reservation := market.CollateralReservation{DealID: 1, Amount: abi.NewTokenAmount(1)}
+			pc.CollateralReservations = []market.CollateralReservation{reservation, reservation}
+			actor.preCommitSector(rt, pc, preCommitConf{})
+		})
+		rt.Reset()
+
 		// Bad sealed CID
 		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "sealed CID had wrong prefix", func() {
 			pc := actor.makePreCommit(102, challengeEpoch, deadline.PeriodEnd(), nil)
@@ -797,13 +874,10 @@ func TestCommitments(t *testing.T) {
 		// Set the right epoch for all following tests
 		rt.SetEpoch(precommitEpoch + miner.PreCommitChallengeDelay + 1)
 
-		// Invalid deals (market ActivateDeals aborts)
-		verifyDealsExit := map[abi.SectorNumber]exitcode.ExitCode{
-			precommit.Info.SectorNumber: exitcode.ErrIllegalArgument,
-		}
+		// Invalid deals (market does not activate the sector's deals)
 		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
 			actor.proveCommitSectorAndConfirm(rt, precommit, makeProveCommit(sectorNo), proveCommitConf{
-				verifyDealsExit: verifyDealsExit,
+				dealActivationFailures: map[abi.SectorNumber]struct{}{precommit.Info.SectorNumber: {}},
 			})
 		})
 		rt.Reset()
@@ -1096,8 +1170,14 @@ func TestCCUpgrade(t *testing.T) {
 		lostPower := actor.powerPairForSectors(bothSectors[:1]).Neg() // new sector not active yet.
 		faultExpiration := miner.QuantSpecForDeadline(dlInfo).QuantizeUp(dlInfo.NextNotElapsed().Last() + miner.FaultMaxAge)
 
+		continuedPenalty := actor.continuedFaultPenalty(bothSectors)
+		detectedPenalty := actor.detectedFaultPenalty(bothSectors)
+
 		advanceDeadline(rt, actor, &cronConfig{
 			detectedFaultsPowerDelta:  &lostPower,
+			continuedFaultsPenalty:    continuedPenalty,
+			detectedFaultsPenalty:     detectedPenalty,
+			penaltyFromUnlocked:       big.Add(continuedPenalty, detectedPenalty),
 			expiredSectorsPledgeDelta: oldSector.InitialPledge.Neg(),
 		})
 
@@ -1502,7 +1582,7 @@ func TestCCUpgrade(t *testing.T) {
 			Build(t)
 		actor.constructAndVerify(rt)
 
-		oldSector, _ := actor.commitProveAndUpgradeSector(rt, 100, 200, defaultSectorExpiration, []abi.DealID{1})
+		oldSector, newSector := actor.commitProveAndUpgradeSector(rt, 100, 200, defaultSectorExpiration, []abi.DealID{1})
 
 		st := getState(rt)
 		dlIdx, _, err := st.FindSector(rt.AdtStore(), oldSector.SectorNumber)
@@ -1518,14 +1598,19 @@ func TestCCUpgrade(t *testing.T) {
 
 		// do not PoSt
 		// expect old sector to lose power (new sector hasn't added any yet)
-		// both sectors are detected faulty for the first time, with no penalty.
+		// both sectors are detected faulty for the first time, paying the detected fault penalty.
 		oldQAPower := miner.QAPowerForSector(actor.sectorSize, oldSector)
 		expectedPowerDelta := miner.NewPowerPair(big.NewInt(int64(actor.sectorSize)), oldQAPower).Neg()
+		continuedPenalty := actor.continuedFaultPenalty([]*miner.SectorOnChainInfo{oldSector, newSector})
+		detectedPenalty := actor.detectedFaultPenalty([]*miner.SectorOnChainInfo{oldSector, newSector})
 
 		// At cron, expect both sectors to be treated as undeclared faults.
 		// The replaced sector will expire anyway, so its pledge will be removed.
 		actor.onDeadlineCron(rt, &cronConfig{
 			expiredSectorsPowerDelta:  &expectedPowerDelta,
+			continuedFaultsPenalty:    continuedPenalty,
+			detectedFaultsPenalty:     detectedPenalty,
+			penaltyFromUnlocked:       big.Add(continuedPenalty, detectedPenalty),
 			expiredSectorsPledgeDelta: oldSector.InitialPledge.Neg(),
 			expectedEnrollment:        rt.Epoch() + miner.WPoStChallengeWindow,
 		})
@@ -1803,14 +1888,19 @@ func TestCCUpgrade(t *testing.T) {
 		// Roll forward to the beginning of the next iteration of this deadline
 		advanceToEpochWithCron(rt, actor, dlInfo.NextNotElapsed().Open)
 
-		// Fail to submit PoSt. This means that both sectors will be detected faulty (no penalty).
+		// Fail to submit PoSt. This means that both sectors will be detected faulty.
 		// Expect the old sector to be marked as terminated.
 		allSectors := []*miner.SectorOnChainInfo{oldSector, newSector1, newSector2}
 		lostPower := actor.powerPairForSectors(allSectors[:1]).Neg() // new sectors not active yet.
 		faultExpiration := miner.QuantSpecForDeadline(dlInfo).QuantizeUp(dlInfo.NextNotElapsed().Last() + miner.FaultMaxAge)
+		continuedPenalty := actor.continuedFaultPenalty(allSectors)
+		detectedPenalty := actor.detectedFaultPenalty(allSectors)
 
 		advanceDeadline(rt, actor, &cronConfig{
 			detectedFaultsPowerDelta:  &lostPower,
+			continuedFaultsPenalty:    continuedPenalty,
+			detectedFaultsPenalty:     detectedPenalty,
+			penaltyFromUnlocked:       big.Add(continuedPenalty, detectedPenalty),
 			expiredSectorsPledgeDelta: oldSector.InitialPledge.Neg(),
 		})
 
@@ -1847,6 +1937,226 @@ func TestCCUpgrade(t *testing.T) {
 	})
 }
 
+func TestProveReplicaUpdates(t *testing.T) {
+	periodOffset := abi.ChainEpoch(100)
+	actor := newHarness(t, periodOffset)
+	builder := builderForHarness(actor).
+		WithEpoch(abi.ChainEpoch(1)).
+		WithBalance(bigBalance, big.Zero())
+
+	// Commits a committed-capacity sector and proves it once to activate it.
+	commitCCSector := func(t *testing.T, rt *mock.Runtime) (*miner.SectorOnChainInfo, miner.ReplicaUpdate) {
+		actor.constructAndVerify(rt)
+		sector := actor.commitAndProveSectors(rt, 1, defaultSectorExpiration, nil)[0]
+		advanceAndSubmitPoSts(rt, actor, sector)
+
+		st := getState(rt)
+		dlIdx, pIdx, err := st.FindSector(rt.AdtStore(), sector.SectorNumber)
+		require.NoError(t, err)
+		return sector, miner.ReplicaUpdate{
+			SectorNumber:       sector.SectorNumber,
+			Deadline:           dlIdx,
+			Partition:          pIdx,
+			NewSealedSectorCID: tutil.MakeCID("replica", &miner.SealedCIDPrefix),
+			Deals:              []abi.DealID{1, 2},
+			ReplicaProof:       []byte("replica proof"),
+		}
+	}
+
+	t.Run("injects verified deals into a committed-capacity sector", func(t *testing.T) {
+		rt := builder.Build(t)
+		oldSector, update := commitCCSector(t, rt)
+
+		duration := oldSector.Expiration - rt.Epoch()
+		verifiedWeight := big.Mul(big.NewIntUnsigned(uint64(actor.sectorSize)), big.NewInt(int64(duration)))
+		actor.proveReplicaUpdate(rt, update, replicaUpdateConf{
+			dealSpace:          actor.sectorSize,
+			dealWeight:         big.Zero(),
+			verifiedDealWeight: verifiedWeight,
+		})
+
+		newSector := actor.getSector(rt, oldSector.SectorNumber)
+		assert.Equal(t, update.NewSealedSectorCID, newSector.SealedCID)
+		assert.Equal(t, update.Deals, newSector.DealIDs)
+		assert.Equal(t, rt.Epoch(), newSector.Activation)
+		assert.Equal(t, oldSector.Expiration, newSector.Expiration)
+		assert.Equal(t, verifiedWeight, newSector.VerifiedDealWeight)
+		assert.Equal(t, rt.Epoch()-oldSector.Activation, newSector.ReplacedSectorAge)
+		assert.Equal(t, oldSector.ExpectedDayReward, newSector.ReplacedDayReward)
+		assert.True(t, newSector.InitialPledge.GreaterThan(oldSector.InitialPledge))
+
+		// The sector's power is updated in its partition.
+		_, partition := actor.getDeadlineAndPartition(rt, update.Deadline, update.Partition)
+		assert.Equal(t, miner.PowerForSector(actor.sectorSize, newSector), partition.LivePower)
+
+		st := getState(rt)
+		assert.Equal(t, newSector.InitialPledge, st.InitialPledge)
+		actor.checkState(rt)
+	})
+
+	t.Run("claims allocations for the pieces of unverified deals", func(t *testing.T) {
+		rt := builder.Build(t)
+		oldSector, update := commitCCSector(t, rt)
+		update.AllocationClaims = []miner.AllocationClaim{{
+			AllocationID: 1,
+			Data:         tutil.MakeCID("piece", &market.PieceCIDPrefix),
+			Size:         abi.PaddedPieceSize(actor.sectorSize),
+		}}
+
+		// The unverified deal's weight is moved to verified weight by the claim.
+		duration := oldSector.Expiration - rt.Epoch()
+		dealWeight := big.Mul(big.NewIntUnsigned(uint64(actor.sectorSize)), big.NewInt(int64(duration)))
+		actor.proveReplicaUpdate(rt, update, replicaUpdateConf{
+			dealSpace:          actor.sectorSize,
+			dealWeight:         dealWeight,
+			verifiedDealWeight: big.Zero(),
+		})
+
+		newSector := actor.getSector(rt, oldSector.SectorNumber)
+		assert.True(t, newSector.DealWeight.IsZero())
+		assert.Equal(t, dealWeight, newSector.VerifiedDealWeight)
+		actor.checkState(rt)
+	})
+
+	t.Run("rejects sector that already has deals", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		sector := actor.commitAndProveSectors(rt, 1, defaultSectorExpiration, [][]abi.DealID{{10}})[0]
+		advanceAndSubmitPoSts(rt, actor, sector)
+
+		st := getState(rt)
+		dlIdx, pIdx, err := st.FindSector(rt.AdtStore(), sector.SectorNumber)
+		require.NoError(t, err)
+
+		rt.SetCaller(actor.worker, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerAddr(append(actor.controlAddrs, actor.owner, actor.worker)...)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "already has deals", func() {
+			rt.Call(actor.a.ProveReplicaUpdates, &miner.ProveReplicaUpdatesParams{Updates: []miner.ReplicaUpdate{{
+				SectorNumber:       sector.SectorNumber,
+				Deadline:           dlIdx,
+				Partition:          pIdx,
+				NewSealedSectorCID: tutil.MakeCID("replica", &miner.SealedCIDPrefix),
+				Deals:              []abi.DealID{1},
+				ReplicaProof:       []byte("replica proof"),
+			}}})
+		})
+		actor.checkState(rt)
+	})
+
+	t.Run("rejects invalid replica update proof", func(t *testing.T) {
+		rt := builder.Build(t)
+		oldSector, update := commitCCSector(t, rt)
+
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "failed to verify replica update", func() {
+			actor.proveReplicaUpdate(rt, update, replicaUpdateConf{
+				dealSpace:          actor.sectorSize,
+				dealWeight:         big.Zero(),
+				verifiedDealWeight: big.Zero(),
+				verificationError:  fmt.Errorf("invalid replica update"),
+			})
+		})
+		rt.Reset()
+
+		// The sector is unchanged.
+		assert.Equal(t, oldSector, actor.getSector(rt, oldSector.SectorNumber))
+		actor.checkState(rt)
+	})
+}
+
+func TestProveCommitAggregate(t *testing.T) {
+	periodOffset := abi.ChainEpoch(100)
+	actor := newHarness(t, periodOffset)
+	builder := builderForHarness(actor).
+		WithEpoch(abi.ChainEpoch(1)).
+		WithBalance(bigBalance, big.Zero())
+
+	// Pre-commits n sectors and advances to the first epoch at which they may be proven.
+	preCommitSectors := func(rt *mock.Runtime, n int) []*miner.SectorPreCommitOnChainInfo {
+		actor.constructAndVerify(rt)
+		precommitEpoch := rt.Epoch()
+		dlInfo := actor.deadline(rt)
+		expiration := dlInfo.PeriodEnd() + defaultSectorExpiration*miner.WPoStProvingPeriod
+
+		var precommits []*miner.SectorPreCommitOnChainInfo
+		for i := 0; i < n; i++ {
+			params := actor.makePreCommit(abi.SectorNumber(100+i), precommitEpoch-1, expiration, nil)
+			precommits = append(precommits, actor.preCommitSector(rt, params, preCommitConf{}))
+		}
+		advanceToEpochWithCron(rt, actor, precommitEpoch+miner.PreCommitChallengeDelay+1)
+		return precommits
+	}
+
+	t.Run("activates aggregated sectors and burns the network fee", func(t *testing.T) {
+		rt := builder.Build(t)
+		precommits := preCommitSectors(rt, miner.MinAggregatedSectors)
+
+		actor.proveCommitAggregateSector(rt, proveCommitConf{}, precommits, []byte("aggregate proof"))
+
+		st := getState(rt)
+		assert.Equal(t, big.Zero(), st.PreCommitDeposits)
+		for _, precommit := range precommits {
+			sector := actor.getSector(rt, precommit.Info.SectorNumber)
+			assert.Equal(t, rt.Epoch(), sector.Activation)
+			assert.Equal(t, precommit.Info.SealedCID, sector.SealedCID)
+		}
+		actor.checkState(rt)
+	})
+
+	t.Run("network fee is priced from the base fee above the batch balancer", func(t *testing.T) {
+		rt := builder.Build(t)
+		precommits := preCommitSectors(rt, miner.MinAggregatedSectors)
+		rt.SetBaseFee(big.Mul(miner.BatchBalancer, big.NewInt(3)))
+		assert.True(t, miner.AggregateNetworkFee(len(precommits), rt.BaseFee()).GreaterThan(
+			miner.AggregateNetworkFee(len(precommits), big.Zero())))
+
+		actor.proveCommitAggregateSector(rt, proveCommitConf{}, precommits, []byte("aggregate proof"))
+		actor.checkState(rt)
+	})
+
+	t.Run("rejects too few sectors", func(t *testing.T) {
+		rt := builder.Build(t)
+		preCommitSectors(rt, miner.MinAggregatedSectors-1)
+
+		rt.SetCaller(actor.worker, builtin.AccountActorCodeID)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "too few sectors", func() {
+			rt.Call(actor.a.ProveCommitAggregate, &miner.ProveCommitAggregateParams{
+				SectorNumbers:  bitfield.NewFromSet([]uint64{100, 101, 102}),
+				AggregateProof: []byte("aggregate proof"),
+			})
+		})
+		rt.Reset()
+	})
+
+	t.Run("rejects oversized proof", func(t *testing.T) {
+		rt := builder.Build(t)
+		preCommitSectors(rt, miner.MinAggregatedSectors)
+
+		rt.SetCaller(actor.worker, builtin.AccountActorCodeID)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "exceeds max size", func() {
+			rt.Call(actor.a.ProveCommitAggregate, &miner.ProveCommitAggregateParams{
+				SectorNumbers:  bitfield.NewFromSet([]uint64{100, 101, 102, 103}),
+				AggregateProof: make([]byte, miner.MaxAggregateProofSize+1),
+			})
+		})
+		rt.Reset()
+	})
+
+	t.Run("rejects sectors that were not pre-committed", func(t *testing.T) {
+		rt := builder.Build(t)
+		preCommitSectors(rt, miner.MinAggregatedSectors)
+
+		rt.SetCaller(actor.worker, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerAddr(append(actor.controlAddrs, actor.owner, actor.worker)...)
+		rt.ExpectAbortContainsMessage(exitcode.ErrNotFound, "pre-committed sectors", func() {
+			rt.Call(actor.a.ProveCommitAggregate, &miner.ProveCommitAggregateParams{
+				SectorNumbers:  bitfield.NewFromSet([]uint64{100, 101, 102, 200}),
+				AggregateProof: []byte("aggregate proof"),
+			})
+		})
+		rt.Verify()
+	})
+}
+
 func TestWindowPost(t *testing.T) {
 	periodOffset := abi.ChainEpoch(100)
 	actor := newHarness(t, periodOffset)
@@ -2059,6 +2369,97 @@ func TestWindowPost(t *testing.T) {
 		actor.checkState(rt)
 	})
 
+	t.Run("healthy and faulty partitions proven in one submission", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		store := rt.AdtStore()
+		// Commit enough sectors to fill two partitions in every eligible deadline, overflowing to a third partition.
+		sectorsToCommit := ((miner.WPoStPeriodDeadlines - 2) * actor.partitionSize * 2) + 1
+		sectors := actor.commitAndProveSectors(rt, int(sectorsToCommit), defaultSectorExpiration, nil)
+		actor.applyRewards(rt, bigRewards, big.Zero())
+
+		st := getState(rt)
+		dlIdx, pIdx, err := st.FindSector(store, sectors[len(sectors)-1].SectorNumber)
+		require.NoError(t, err)
+		require.Equal(t, uint64(2), dlIdx)
+		require.Equal(t, uint64(2), pIdx)
+
+		// Collect the sectors of each partition in the deadline.
+		deadline := actor.getDeadline(rt, dlIdx)
+		partitionSectors := make([][]*miner.SectorOnChainInfo, 3)
+		var dlSectors []*miner.SectorOnChainInfo
+		for p := range partitionSectors {
+			partition := actor.getPartition(rt, deadline, uint64(p))
+			for _, sector := range sectors {
+				set, err := partition.Sectors.IsSet(uint64(sector.SectorNumber))
+				require.NoError(t, err)
+				if set {
+					partitionSectors[p] = append(partitionSectors[p], sector)
+				}
+			}
+			dlSectors = append(dlSectors, partitionSectors[p]...)
+		}
+
+		dlinfo := actor.deadline(rt)
+		for dlinfo.Index != dlIdx {
+			dlinfo = advanceDeadline(rt, actor, &cronConfig{})
+		}
+
+		// Prove all three partitions at once, skipping a sector of the middle one.
+		skipped := partitionSectors[1][0]
+		partitions := []miner.PoStPartition{
+			{Index: 0, Skipped: bitfield.New()},
+			{Index: 1, Skipped: bf(uint64(skipped.SectorNumber))},
+			{Index: 2, Skipped: bitfield.New()},
+		}
+		proven := make([]*miner.SectorOnChainInfo, 0, len(dlSectors)-1)
+		for _, sector := range dlSectors {
+			if sector != skipped {
+				proven = append(proven, sector)
+			}
+		}
+		actor.submitWindowPoSt(rt, dlinfo, partitions, dlSectors, &poStConfig{
+			expectedPowerDelta: miner.PowerForSectors(actor.sectorSize, proven),
+		})
+
+		// Each partition is recorded as proven, with the fault recorded only in its own partition.
+		deadline = actor.getDeadline(rt, dlIdx)
+		assertBitfieldEquals(t, deadline.PartitionsPoSted, 0, 1, 2)
+		assertBitfieldEquals(t, actor.getPartition(rt, deadline, 0).Faults)
+		assertBitfieldEquals(t, actor.getPartition(rt, deadline, 1).Faults, uint64(skipped.SectorNumber))
+		assertBitfieldEquals(t, actor.getPartition(rt, deadline, 2).Faults)
+
+		// The skipped sector pays the continued fault fee at the end of the deadline.
+		advanceDeadline(rt, actor, &cronConfig{
+			continuedFaultsPenalty: actor.continuedFaultPenalty([]*miner.SectorOnChainInfo{skipped}),
+		})
+		actor.checkState(rt)
+	})
+
+	t.Run("rejects a submission proving too many partitions", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+
+		partitions := make([]miner.PoStPartition, miner.PoStedPartitionsMax+1)
+		for i := range partitions {
+			partitions[i] = miner.PoStPartition{Index: uint64(i), Skipped: bitfield.New()}
+		}
+		params := miner.SubmitWindowedPoStParams{
+			Deadline:         0,
+			Partitions:       partitions,
+			Proofs:           makePoStProofs(actor.windowPostProofType),
+			ChainCommitEpoch: rt.Epoch() - 1,
+			ChainCommitRand:  abi.Randomness("chaincommitment"),
+		}
+
+		rt.SetCaller(actor.worker, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerAddr(append(actor.controlAddrs, actor.owner, actor.worker)...)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "too many partitions", func() {
+			rt.Call(actor.a.SubmitWindowedPoSt, &params)
+		})
+		rt.Reset()
+	})
+
 	t.Run("successful recoveries recover power", func(t *testing.T) {
 		rt := builder.Build(t)
 
@@ -2181,12 +2582,13 @@ func TestWindowPost(t *testing.T) {
 		})
 		rt.Reset()
 
-		// The second sector is detected faulty but pays nothing yet.
-		// Expect ongoing fault penalty for only the first, continuing-faulty sector.
+		// The second sector is detected faulty, paying the detected fault penalty.
+		// Expect the fault fee for both sectors, which are faulty through the deadline.
 		pwrDelta = miner.PowerForSectors(actor.sectorSize, infos[1:2]).Neg()
-		faultFee = actor.continuedFaultPenalty(infos[:1])
+		faultFee = actor.continuedFaultPenalty(infos)
 		advanceDeadline(rt, actor, &cronConfig{
 			detectedFaultsPowerDelta: &pwrDelta,
+			detectedFaultsPenalty:    actor.detectedFaultPenalty(infos[1:2]),
 			continuedFaultsPenalty:   faultFee,
 		})
 		actor.checkState(rt)
@@ -2223,8 +2625,11 @@ func TestWindowPost(t *testing.T) {
 		})
 		rt.Reset()
 
-		// These sectors are detected faulty and pay no penalty this time.
-		advanceDeadline(rt, actor, &cronConfig{continuedFaultsPenalty: big.Zero()})
+		// These sectors are detected faulty, paying the fault fee and the detected fault penalty.
+		advanceDeadline(rt, actor, &cronConfig{
+			continuedFaultsPenalty: actor.continuedFaultPenalty(infos),
+			detectedFaultsPenalty:  actor.detectedFaultPenalty(infos),
+		})
 		actor.checkState(rt)
 	})
 
@@ -2430,6 +2835,39 @@ func TestWindowPost(t *testing.T) {
 		rt.Verify()
 	})
 
+	t.Run("can't dispute a proof that was not submitted", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		store := rt.AdtStore()
+		sector := actor.commitAndProveSectors(rt, 1, defaultSectorExpiration, nil)[0]
+
+		st := getState(rt)
+		dlIdx, _, err := st.FindSector(store, sector.SectorNumber)
+		require.NoError(t, err)
+
+		nextDl := miner.NewDeadlineInfo(st.ProvingPeriodStart, dlIdx, rt.Epoch()).
+			NextNotElapsed()
+
+		// a single proof is submitted, at index 0
+		advanceAndSubmitPoSts(rt, actor, sector)
+		rt.SetEpoch(nextDl.Close)
+
+		params := miner.DisputeWindowedPoStParams{
+			Deadline:  dlIdx,
+			PoStIndex: 1,
+		}
+
+		rt.SetCaller(actor.worker, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+		expectQueryNetworkInfo(rt, actor)
+
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "proof 1 not found", func() {
+			rt.Call(actor.a.DisputeWindowedPoSt, &params)
+		})
+		rt.Verify()
+		actor.checkState(rt)
+	})
+
 	t.Run("can dispute test after proving period changes", func(t *testing.T) {
 		rt := builder.Build(t)
 		actor.constructAndVerify(rt)
@@ -2555,36 +2993,211 @@ func TestProveCommit(t *testing.T) {
 		actor.proveCommitSector(rt, preCommitB, makeProveCommit(sectorNoB))
 
 		conf := proveCommitConf{
-			verifyDealsExit: map[abi.SectorNumber]exitcode.ExitCode{
-				sectorNoA: exitcode.ErrIllegalArgument,
-			},
+			dealActivationFailures: map[abi.SectorNumber]struct{}{sectorNoA: {}},
 		}
 		actor.confirmSectorProofsValid(rt, conf, preCommitA, preCommitB)
 		actor.checkState(rt)
 	})
-}
-
-func TestDeadlineCron(t *testing.T) {
-	periodOffset := abi.ChainEpoch(100)
-	actor := newHarness(t, periodOffset)
-	builder := builderForHarness(actor).
-		WithBalance(bigBalance, big.Zero())
 
-	t.Run("empty periods", func(t *testing.T) {
+	t.Run("claims verified allocations for the pieces of activated sectors", func(t *testing.T) {
 		rt := builder.Build(t)
 		actor.constructAndVerify(rt)
-		st := getState(rt)
-		assert.Equal(t, periodOffset-miner.WPoStProvingPeriod, st.ProvingPeriodStart)
 
-		// crons before proving period do nothing
-		secondCronEpoch := periodOffset + miner.WPoStProvingPeriod - 1
-		dlinfo := actor.deadline(rt)
-		for dlinfo.Close < secondCronEpoch {
-			dlinfo = advanceDeadline(rt, actor, &cronConfig{})
-		}
+		expiration := defaultSectorExpiration*miner.WPoStProvingPeriod + periodOffset - 1
+		precommitEpoch := rt.Epoch() + 1
+		rt.SetEpoch(precommitEpoch)
+		sectorNo := actor.nextSectorNo
+		params := actor.makePreCommit(sectorNo, rt.Epoch()-1, expiration, []abi.DealID{1})
+		params.AllocationClaims = []miner.AllocationClaim{{
+			AllocationID: 1,
+			Data:         tutil.MakeCID("piece", &market.PieceCIDPrefix),
+			Size:         abi.PaddedPieceSize(actor.sectorSize),
+		}}
+		// The market weights the whole sector as unverified deal space.
+		dealWeight := big.Mul(big.NewIntUnsigned(uint64(actor.sectorSize)), big.NewInt(int64(expiration-precommitEpoch)))
+		precommit := actor.preCommitSector(rt, params, preCommitConf{
+			dealWeight: dealWeight,
+			dealSpace:  actor.sectorSize,
+		})
+		assert.Equal(t, params.AllocationClaims, precommit.Info.AllocationClaims)
 
-		// The proving period start isn't changed, because the period hadn't started yet.
-		st = getState(rt)
+		proveCommitEpoch := precommitEpoch + miner.PreCommitChallengeDelay + 1
+		rt.SetEpoch(proveCommitEpoch)
+		actor.proveCommitSectorAndConfirm(rt, precommit, makeProveCommit(sectorNo), proveCommitConf{})
+
+		// The claimed deal's weight from activation is verified, leaving only the excess weight unverified.
+		claimedWeight := big.Mul(big.NewIntUnsigned(uint64(actor.sectorSize)), big.NewInt(int64(expiration-proveCommitEpoch)))
+		sector := actor.getSector(rt, sectorNo)
+		assert.Equal(t, claimedWeight, sector.VerifiedDealWeight)
+		assert.Equal(t, big.Sub(dealWeight, claimedWeight), sector.DealWeight)
+		actor.checkState(rt)
+	})
+
+	t.Run("verifies only the weight of claimed deals shorter than the sector", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+
+		expiration := defaultSectorExpiration*miner.WPoStProvingPeriod + periodOffset - 1
+		precommitEpoch := rt.Epoch() + 1
+		rt.SetEpoch(precommitEpoch)
+		proveCommitEpoch := precommitEpoch + miner.PreCommitChallengeDelay + 1
+		sectorNo := actor.nextSectorNo
+
+		// Two unverified deals fill half the sector each, for terms ending well before the sector expires.
+		size := abi.PaddedPieceSize(actor.sectorSize / 2)
+		claimedPiece := tutil.MakeCID("claimed", &market.PieceCIDPrefix)
+		unclaimedPiece := tutil.MakeCID("unclaimed", &market.PieceCIDPrefix)
+		weightUntil := func(end abi.ChainEpoch) abi.DealWeight {
+			return big.Mul(big.NewIntUnsigned(uint64(size)), big.NewInt(int64(end-proveCommitEpoch)))
+		}
+		claimedDealWeight := weightUntil(proveCommitEpoch + (expiration-proveCommitEpoch)/4)
+		unclaimedDealWeight := weightUntil(proveCommitEpoch + (expiration-proveCommitEpoch)/2)
+		dealWeight := big.Add(claimedDealWeight, unclaimedDealWeight)
+
+		params := actor.makePreCommit(sectorNo, rt.Epoch()-1, expiration, []abi.DealID{1, 2})
+		params.AllocationClaims = []miner.AllocationClaim{{AllocationID: 1, Data: claimedPiece, Size: size}}
+		precommit := actor.preCommitSector(rt, params, preCommitConf{
+			dealWeight: dealWeight,
+			dealSpace:  actor.sectorSize,
+		})
+
+		rt.SetEpoch(proveCommitEpoch)
+		actor.proveCommitSectorAndConfirm(rt, precommit, makeProveCommit(sectorNo), proveCommitConf{
+			unverifiedPieces: map[abi.SectorNumber][]market.UnverifiedPiece{sectorNo: {
+				{Size: size, PieceCID: claimedPiece, DealWeight: claimedDealWeight},
+				{Size: size, PieceCID: unclaimedPiece, DealWeight: unclaimedDealWeight},
+			}},
+		})
+
+		// Only the claimed deal's own weight is verified. The unclaimed deal keeps all of its weight.
+		sector := actor.getSector(rt, sectorNo)
+		assert.Equal(t, claimedDealWeight, sector.VerifiedDealWeight)
+		assert.Equal(t, unclaimedDealWeight, sector.DealWeight)
+		actor.checkState(rt)
+	})
+
+	// Expects activation of a sector's deals, returning the given result, to be followed by an abort of the
+	// sector's confirmation for claiming an allocation of a piece that cannot be claimed.
+	expectClaimRejected := func(rt *mock.Runtime, precommit *miner.SectorPreCommitOnChainInfo, activation market.SectorDealActivation) {
+		expectQueryNetworkInfo(rt, actor)
+		rt.ExpectSend(builtin.StorageMarketActorAddr, builtin.MethodsMarket.BatchActivateDeals,
+			&market.BatchActivateDealsParams{Sectors: []market.SectorDeals{{
+				SectorExpiry: precommit.Info.Expiration,
+				DealIDs:      precommit.Info.DealIDs,
+			}}}, big.Zero(),
+			&market.BatchActivateDealsReturn{Sectors: []market.SectorDealActivation{activation}}, exitcode.Ok)
+		rt.SetCaller(builtin.StoragePowerActorAddr, builtin.StoragePowerActorCodeID)
+		rt.ExpectValidateCallerAddr(builtin.StoragePowerActorAddr)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "not the piece of any of its unclaimed unverified deals", func() {
+			rt.Call(actor.a.ConfirmSectorProofsValid, &builtin.ConfirmSectorProofsParams{Sectors: []abi.SectorNumber{precommit.Info.SectorNumber}})
+		})
+		rt.Reset()
+	}
+
+	preCommitWithClaim := func(rt *mock.Runtime, piece cid.Cid) *miner.SectorPreCommitOnChainInfo {
+		expiration := defaultSectorExpiration*miner.WPoStProvingPeriod + periodOffset - 1
+		precommitEpoch := rt.Epoch() + 1
+		rt.SetEpoch(precommitEpoch)
+		sectorNo := actor.nextSectorNo
+		params := actor.makePreCommit(sectorNo, rt.Epoch()-1, expiration, []abi.DealID{1})
+		params.AllocationClaims = []miner.AllocationClaim{{
+			AllocationID: 1,
+			Data:         piece,
+			Size:         abi.PaddedPieceSize(actor.sectorSize),
+		}}
+		precommit := actor.preCommitSector(rt, params, preCommitConf{dealSpace: actor.sectorSize})
+
+		rt.SetEpoch(precommitEpoch + miner.PreCommitChallengeDelay + 1)
+		actor.proveCommitSector(rt, precommit, makeProveCommit(sectorNo))
+		return precommit
+	}
+
+	t.Run("rejects allocation claim for a piece of no activated deal", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		precommit := preCommitWithClaim(rt, tutil.MakeCID("piece", &market.PieceCIDPrefix))
+
+		// The activated deal has a different piece from the one claimed.
+		otherPiece := tutil.MakeCID("other piece", &market.PieceCIDPrefix)
+		expectClaimRejected(rt, precommit, market.SectorDealActivation{
+			Activated:          true,
+			DealSpace:          uint64(actor.sectorSize),
+			DealWeight:         big.Zero(),
+			VerifiedDealWeight: big.Zero(),
+			PieceCIDs:          []cid.Cid{otherPiece},
+			UnverifiedPieces:   []market.UnverifiedPiece{{Size: abi.PaddedPieceSize(actor.sectorSize), PieceCID: otherPiece, DealWeight: big.Zero()}},
+		})
+	})
+
+	t.Run("rejects allocation claim for the piece of a verified deal", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		piece := tutil.MakeCID("piece", &market.PieceCIDPrefix)
+		precommit := preCommitWithClaim(rt, piece)
+
+		// The piece's deal is verified, so its space is already weighted as verified.
+		duration := precommit.Info.Expiration - rt.Epoch()
+		expectClaimRejected(rt, precommit, market.SectorDealActivation{
+			Activated:          true,
+			DealSpace:          uint64(actor.sectorSize),
+			DealWeight:         big.Zero(),
+			VerifiedDealWeight: big.Mul(big.NewIntUnsigned(uint64(actor.sectorSize)), big.NewInt(int64(duration))),
+			PieceCIDs:          []cid.Cid{piece},
+		})
+	})
+
+	t.Run("rejects claims of more allocations than there are unverified deals for a piece", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		piece := tutil.MakeCID("piece", &market.PieceCIDPrefix)
+		expiration := defaultSectorExpiration*miner.WPoStProvingPeriod + periodOffset - 1
+		precommitEpoch := rt.Epoch() + 1
+		rt.SetEpoch(precommitEpoch)
+		sectorNo := actor.nextSectorNo
+		size := abi.PaddedPieceSize(actor.sectorSize / 2)
+		params := actor.makePreCommit(sectorNo, rt.Epoch()-1, expiration, []abi.DealID{1, 2})
+		params.AllocationClaims = []miner.AllocationClaim{
+			{AllocationID: 1, Data: piece, Size: size},
+			{AllocationID: 2, Data: piece, Size: size},
+		}
+		precommit := actor.preCommitSector(rt, params, preCommitConf{dealSpace: actor.sectorSize})
+		rt.SetEpoch(precommitEpoch + miner.PreCommitChallengeDelay + 1)
+		actor.proveCommitSector(rt, precommit, makeProveCommit(sectorNo))
+
+		// Two deals have the piece, but only one of them is unverified.
+		duration := expiration - rt.Epoch()
+		expectClaimRejected(rt, precommit, market.SectorDealActivation{
+			Activated:          true,
+			DealSpace:          uint64(actor.sectorSize),
+			DealWeight:         big.Mul(big.NewIntUnsigned(uint64(size)), big.NewInt(int64(duration))),
+			VerifiedDealWeight: big.Mul(big.NewIntUnsigned(uint64(size)), big.NewInt(int64(duration))),
+			PieceCIDs:          []cid.Cid{piece, piece},
+			UnverifiedPieces:   []market.UnverifiedPiece{{Size: size, PieceCID: piece, DealWeight: big.Mul(big.NewIntUnsigned(uint64(size)), big.NewInt(int64(duration)))}},
+		})
+	})
+}
+
+func TestDeadlineCron(t *testing.T) {
+	periodOffset := abi.ChainEpoch(100)
+	actor := newHarness(t, periodOffset)
+	builder := builderForHarness(actor).
+		WithBalance(bigBalance, big.Zero())
+
+	t.Run("empty periods", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		st := getState(rt)
+		assert.Equal(t, periodOffset-miner.WPoStProvingPeriod, st.ProvingPeriodStart)
+
+		// crons before proving period do nothing
+		secondCronEpoch := periodOffset + miner.WPoStProvingPeriod - 1
+		dlinfo := actor.deadline(rt)
+		for dlinfo.Close < secondCronEpoch {
+			dlinfo = advanceDeadline(rt, actor, &cronConfig{})
+		}
+
+		// The proving period start isn't changed, because the period hadn't started yet.
+		st = getState(rt)
 		assert.Equal(t, periodOffset, st.ProvingPeriodStart)
 
 		// next cron moves proving period forward and enrolls for next cron
@@ -2597,6 +3210,44 @@ func TestDeadlineCron(t *testing.T) {
 		actor.checkState(rt)
 	})
 
+	t.Run("late callback processes the deadline recorded in state", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+
+		sectors := actor.commitAndProveSectors(rt, 1, defaultSectorExpiration, nil)
+		// advance cron to activate power.
+		advanceAndSubmitPoSts(rt, actor, sectors...)
+		activePower := miner.PowerForSectors(actor.sectorSize, sectors)
+
+		st := getState(rt)
+		dlIdx, _, err := st.FindSector(rt.AdtStore(), sectors[0].SectorNumber)
+		require.NoError(t, err)
+
+		dlinfo := actor.deadline(rt)
+		for dlinfo.Index != dlIdx {
+			dlinfo = advanceDeadline(rt, actor, &cronConfig{})
+		}
+
+		// The power actor defers the callback past the deadline's last epoch, e.g. when its per-epoch
+		// cron budget is exhausted. The missed PoSt is still detected for the recorded deadline, and the
+		// next callback is enrolled for the end of the following deadline rather than relative to now.
+		rt.SetEpoch(dlinfo.Last() + 10)
+		powerDelta := activePower.Neg()
+		actor.onDeadlineCron(rt, &cronConfig{
+			expectedEnrollment:       dlinfo.Last() + miner.WPoStChallengeWindow,
+			detectedFaultsPowerDelta: &powerDelta,
+			continuedFaultsPenalty:   actor.continuedFaultPenalty(sectors),
+			detectedFaultsPenalty:    actor.detectedFaultPenalty(sectors),
+			penaltyFromUnlocked:      big.Add(actor.continuedFaultPenalty(sectors), actor.detectedFaultPenalty(sectors)),
+		})
+
+		st = getState(rt)
+		assert.Equal(t, (dlIdx+1)%miner.WPoStPeriodDeadlines, st.CurrentDeadline)
+		deadline := actor.getDeadline(rt, dlIdx)
+		assert.True(t, activePower.Equals(deadline.FaultyPower))
+		actor.checkState(rt)
+	})
+
 	t.Run("sector expires", func(t *testing.T) {
 		rt := builder.Build(t)
 		actor.constructAndVerify(rt)
@@ -2622,11 +3273,15 @@ func TestDeadlineCron(t *testing.T) {
 		// Advance to expiration epoch and expect expiration during cron
 		rt.SetEpoch(expiration)
 		powerDelta := activePower.Neg()
-		// because we skip forward in state the sector is detected faulty, no penalty
+		// because we skip forward in state the sector is detected faulty, paying the fault fee and
+		// the detected fault penalty
 		advanceDeadline(rt, actor, &cronConfig{
 			expectedEnrollment:        rt.Epoch() + miner.WPoStChallengeWindow,
 			expiredSectorsPowerDelta:  &powerDelta,
 			expiredSectorsPledgeDelta: initialPledge.Neg(),
+			continuedFaultsPenalty:    actor.continuedFaultPenalty(sectors),
+			detectedFaultsPenalty:     actor.detectedFaultPenalty(sectors),
+			penaltyFromUnlocked:       big.Add(actor.continuedFaultPenalty(sectors), actor.detectedFaultPenalty(sectors)),
 		})
 		actor.checkState(rt)
 	})
@@ -2665,8 +3320,8 @@ func TestDeadlineCron(t *testing.T) {
 		// Miner balance = IP, debt repayment covered by unlocked funds
 		rt.SetBalance(st.InitialPledge)
 
-		// because we skip forward in state and don't check post, there's no penalty.
-		// this is the first time the sector is detected faulty
+		// because we skip forward in state and don't check post, the sector is detected faulty for the first time.
+		// the detected fault penalty adds to the fee debt, which the balance can't cover in full
 		advanceDeadline(rt, actor, &cronConfig{
 			expectedEnrollment:        rt.Epoch() + miner.WPoStChallengeWindow,
 			expiredSectorsPowerDelta:  &powerDelta,
@@ -2708,6 +3363,8 @@ func TestDeadlineCron(t *testing.T) {
 		activePowerDelta := activePower.Neg()
 		advanceDeadline(rt, actor, &cronConfig{
 			detectedFaultsPowerDelta: &activePowerDelta,
+			continuedFaultsPenalty:   actor.continuedFaultPenalty(allSectors),
+			detectedFaultsPenalty:    actor.detectedFaultPenalty(allSectors),
 		})
 
 		// expect faulty power to be added to state
@@ -2730,8 +3387,10 @@ func TestDeadlineCron(t *testing.T) {
 		ongoingPwr := miner.PowerForSectors(actor.sectorSize, allSectors)
 		ongoingPenalty := miner.PledgePenaltyForContinuedFault(actor.epochRewardSmooth, actor.epochQAPowerSmooth, ongoingPwr.QA)
 
+		// Failed recoveries are also charged as detected faults
 		advanceDeadline(rt, actor, &cronConfig{
 			continuedFaultsPenalty: ongoingPenalty,
+			detectedFaultsPenalty:  actor.detectedFaultPenalty(allSectors[1:]),
 		})
 
 		// recorded faulty power is unchanged
@@ -2767,7 +3426,7 @@ func TestDeadlineCron(t *testing.T) {
 		// advance clock well past the end of next period (into next deadline period) without calling cron
 		rt.SetEpoch(dlinfo.Last() + miner.WPoStChallengeWindow + 5)
 
-		// run cron and expect all sectors to be detected as faults (no penalty)
+		// run cron and expect all sectors to be detected as faults
 		pwr := miner.PowerForSectors(actor.sectorSize, allSectors)
 
 		// power for sectors is removed
@@ -2779,6 +3438,8 @@ func TestDeadlineCron(t *testing.T) {
 		actor.onDeadlineCron(rt, &cronConfig{
 			expectedEnrollment:       nextCron,
 			detectedFaultsPowerDelta: &powerDeltaClaim,
+			continuedFaultsPenalty:   actor.continuedFaultPenalty(allSectors),
+			detectedFaultsPenalty:    actor.detectedFaultPenalty(allSectors),
 		})
 		actor.checkState(rt)
 	})
@@ -2830,6 +3491,295 @@ func TestDeclareFaults(t *testing.T) {
 		})
 		actor.checkState(rt)
 	})
+
+	// Sets up a proven sector and advances to the opening of its deadline's challenge window in the following
+	// proving period, after the fault declaration cutoff.
+	setupOpenDeadline := func(t *testing.T) (*mock.Runtime, []*miner.SectorOnChainInfo, *dline.Info) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		allSectors := actor.commitAndProveSectors(rt, 1, defaultSectorExpiration, nil)
+
+		// add lots of funds so penalties come from vesting funds
+		actor.applyRewards(rt, bigRewards, big.Zero())
+
+		st := getState(rt)
+		dlIdx, _, err := st.FindSector(rt.AdtStore(), allSectors[0].SectorNumber)
+		require.NoError(t, err)
+
+		advanceAndSubmitPoSts(rt, actor, allSectors...)
+		dlinfo := actor.deadline(rt)
+		for dlinfo.Index != dlIdx {
+			dlinfo = advanceDeadline(rt, actor, &cronConfig{})
+		}
+		require.True(t, dlinfo.FaultCutoffPassed())
+		return rt, allSectors, dlinfo
+	}
+
+	t.Run("late fault declaration pays reduced penalty when declared", func(t *testing.T) {
+		rt, allSectors, dlinfo := setupOpenDeadline(t)
+		pwr := miner.PowerForSectors(actor.sectorSize, allSectors)
+
+		latePenalty := miner.PledgePenaltyForLateDeclaredFault(actor.epochRewardSmooth, actor.epochQAPowerSmooth, pwr.QA)
+		detectedPenalty := miner.PledgePenaltyForDetectedFault(actor.epochRewardSmooth, actor.epochQAPowerSmooth, pwr.QA)
+		assert.True(t, latePenalty.GreaterThan(big.Zero()))
+		assert.Equal(t, big.Div(detectedPenalty, big.NewInt(2)), latePenalty)
+
+		// Declare the fault within the self-report window, paying the late penalty immediately.
+		rt.SetEpoch(dlinfo.Open + miner.FaultSelfReportWindow - 1)
+		actor.declareLateFaults(rt, allSectors...)
+
+		dl := actor.getDeadline(rt, dlinfo.Index)
+		assert.True(t, pwr.Equals(dl.FaultyPower))
+
+		// The fault is not detected when the deadline closes, but pays the ongoing fault fee like any fault.
+		ongoingPenalty := miner.PledgePenaltyForContinuedFault(actor.epochRewardSmooth, actor.epochQAPowerSmooth, pwr.QA)
+		advanceDeadline(rt, actor, &cronConfig{
+			continuedFaultsPenalty: ongoingPenalty,
+		})
+		actor.checkState(rt)
+	})
+
+	t.Run("undeclared fault pays full penalty when detected", func(t *testing.T) {
+		rt, allSectors, _ := setupOpenDeadline(t)
+		pwr := miner.PowerForSectors(actor.sectorSize, allSectors)
+
+		// The deadline closes without a PoSt.
+		powerDelta := pwr.Neg()
+		detectedPenalty := miner.PledgePenaltyForDetectedFault(actor.epochRewardSmooth, actor.epochQAPowerSmooth, pwr.QA)
+		assert.True(t, detectedPenalty.GreaterThan(big.Zero()))
+		ongoingPenalty := miner.PledgePenaltyForContinuedFault(actor.epochRewardSmooth, actor.epochQAPowerSmooth, pwr.QA)
+		advanceDeadline(rt, actor, &cronConfig{
+			detectedFaultsPowerDelta: &powerDelta,
+			continuedFaultsPenalty:   ongoingPenalty,
+			detectedFaultsPenalty:    detectedPenalty,
+		})
+		actor.checkState(rt)
+	})
+
+	t.Run("late fault declaration costs less than detection", func(t *testing.T) {
+		// Declare the fault late in one runtime, and leave it to be detected in another.
+		rt, allSectors, dlinfo := setupOpenDeadline(t)
+		pwr := miner.PowerForSectors(actor.sectorSize, allSectors)
+		balanceBefore := rt.Balance()
+		rt.SetEpoch(dlinfo.Open + miner.FaultSelfReportWindow - 1)
+		actor.declareLateFaults(rt, allSectors...)
+		advanceDeadline(rt, actor, &cronConfig{
+			continuedFaultsPenalty: actor.continuedFaultPenalty(allSectors),
+		})
+		lateCost := big.Sub(balanceBefore, rt.Balance())
+
+		rt, _, _ = setupOpenDeadline(t)
+		balanceBefore = rt.Balance()
+		powerDelta := pwr.Neg()
+		advanceDeadline(rt, actor, &cronConfig{
+			detectedFaultsPowerDelta: &powerDelta,
+			continuedFaultsPenalty:   actor.continuedFaultPenalty(allSectors),
+			detectedFaultsPenalty:    actor.detectedFaultPenalty(allSectors),
+		})
+		detectedCost := big.Sub(balanceBefore, rt.Balance())
+
+		latePenalty := miner.PledgePenaltyForLateDeclaredFault(actor.epochRewardSmooth, actor.epochQAPowerSmooth, pwr.QA)
+		assert.Equal(t, big.Add(latePenalty, actor.continuedFaultPenalty(allSectors)), lateCost)
+		assert.Equal(t, big.Add(actor.detectedFaultPenalty(allSectors), actor.continuedFaultPenalty(allSectors)), detectedCost)
+		assert.True(t, lateCost.GreaterThan(big.Zero()))
+		assert.True(t, lateCost.LessThan(detectedCost), "late declaration cost %v not less than detection cost %v", lateCost, detectedCost)
+		actor.checkState(rt)
+	})
+
+	t.Run("fault declaration after self-report window fails", func(t *testing.T) {
+		rt, allSectors, dlinfo := setupOpenDeadline(t)
+
+		rt.SetEpoch(dlinfo.Open + miner.FaultSelfReportWindow)
+		rt.SetCaller(actor.worker, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerAddr(append(actor.controlAddrs, actor.owner, actor.worker)...)
+		params := makeFaultParamsFromFaultingSectors(t, getState(rt), rt.AdtStore(), allSectors)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "self-report window", func() {
+			rt.Call(actor.a.DeclareFaults, params)
+		})
+		rt.Reset()
+		actor.checkState(rt)
+	})
+
+	t.Run("too many declarations are rejected before dispatch", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+
+		params := &miner.DeclareFaultsParams{Faults: make([]miner.FaultDeclaration, miner.DeclarationsMax+1)}
+		rt.SetCaller(actor.worker, builtin.AccountActorCodeID)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "Faults length", func() {
+			rt.Call(actor.a.DeclareFaults, params)
+		})
+		rt.Verify()
+	})
+}
+
+func TestDeadlineInfo(t *testing.T) {
+	periodOffset := abi.ChainEpoch(100)
+	actor := newHarness(t, periodOffset)
+	builder := builderForHarness(actor).
+		WithBalance(bigBalance, big.Zero())
+
+	t.Run("reports sectors, faults and recoveries", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		oneSector := actor.commitAndProveSectors(rt, 1, defaultSectorExpiration, nil)
+		pwr := miner.PowerForSectors(actor.sectorSize, oneSector)
+		advanceAndSubmitPoSts(rt, actor, oneSector...)
+
+		st := getState(rt)
+		dlIdx, pIdx, err := st.FindSector(rt.AdtStore(), oneSector[0].SectorNumber)
+		require.NoError(t, err)
+
+		status := actor.deadlineInfo(rt, dlIdx)
+		assert.Equal(t, uint64(1), status.Partitions)
+		assert.Equal(t, uint64(1), status.LiveSectors)
+		assert.Equal(t, uint64(1), status.TotalSectors)
+		assert.Equal(t, uint64(0), status.FaultySectors)
+		assert.Equal(t, uint64(0), status.RecoveringSectors)
+		assert.True(t, status.FaultyPower.IsZero())
+
+		// The next challenge window is in the future, since the deadline was just proven.
+		dlInfo := miner.NewDeadlineInfo(st.ProvingPeriodStart, dlIdx, rt.Epoch()).NextNotElapsed()
+		assert.Greater(t, int64(status.ChallengeOpen), int64(rt.Epoch()))
+		assert.Equal(t, dlInfo.Open, status.ChallengeOpen)
+		assert.Equal(t, dlInfo.Close, status.ChallengeClose)
+		assert.Equal(t, dlInfo.Challenge, status.Challenge)
+		assert.Equal(t, dlInfo.FaultCutoff, status.FaultCutoff)
+		assert.Equal(t, uint64(0), status.PartitionsPoSted)
+
+		actor.declareFaults(rt, oneSector...)
+		status = actor.deadlineInfo(rt, dlIdx)
+		assert.Equal(t, uint64(1), status.FaultySectors)
+		assert.Equal(t, uint64(0), status.RecoveringSectors)
+		assert.True(t, pwr.Equals(status.FaultyPower))
+
+		actor.declareRecoveries(rt, dlIdx, pIdx, bf(uint64(oneSector[0].SectorNumber)), big.Zero())
+		status = actor.deadlineInfo(rt, dlIdx)
+		assert.Equal(t, uint64(1), status.FaultySectors)
+		assert.Equal(t, uint64(1), status.RecoveringSectors)
+
+		// Other deadlines are empty.
+		status = actor.deadlineInfo(rt, (dlIdx+1)%miner.WPoStPeriodDeadlines)
+		assert.Equal(t, uint64(0), status.Partitions)
+		assert.Equal(t, uint64(0), status.LiveSectors)
+		actor.checkState(rt)
+	})
+
+	t.Run("rejects invalid deadline", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+
+		rt.ExpectValidateCallerAny()
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "invalid deadline", func() {
+			rt.Call(actor.a.DeadlineInfo, &miner.DeadlineInfoParams{Deadline: miner.WPoStPeriodDeadlines})
+		})
+		rt.Verify()
+	})
+}
+
+func TestGetSectorInfo(t *testing.T) {
+	periodOffset := abi.ChainEpoch(100)
+	actor := newHarness(t, periodOffset)
+	builder := builderForHarness(actor).
+		WithBalance(bigBalance, big.Zero())
+
+	t.Run("reports a proven sector with its deals and location", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		sector := actor.commitAndProveSectors(rt, 1, defaultSectorExpiration, [][]abi.DealID{{10}})[0]
+
+		st := getState(rt)
+		dlIdx, pIdx, err := st.FindSector(rt.AdtStore(), sector.SectorNumber)
+		require.NoError(t, err)
+
+		info := actor.getSectorInfo(rt, sector.SectorNumber)
+		assert.Equal(t, sector.SectorNumber, info.SectorNumber)
+		assert.Equal(t, sector.SealProof, info.SealProof)
+		assert.Equal(t, sector.SealedCID, info.SealedCID)
+		assert.Equal(t, []abi.DealID{10}, info.DealIDs)
+		assert.Equal(t, sector.Activation, info.Activation)
+		assert.Equal(t, sector.Expiration, info.Expiration)
+		assert.Equal(t, sector.DealWeight, info.DealWeight)
+		assert.Equal(t, sector.VerifiedDealWeight, info.VerifiedDealWeight)
+		assert.Equal(t, sector.InitialPledge, info.InitialPledge)
+		assert.Equal(t, dlIdx, info.Deadline)
+		assert.Equal(t, pIdx, info.Partition)
+		actor.checkState(rt)
+	})
+
+	t.Run("rejects unknown sector", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		sector := actor.commitAndProveSectors(rt, 1, defaultSectorExpiration, nil)[0]
+
+		rt.ExpectValidateCallerAny()
+		rt.ExpectAbortContainsMessage(exitcode.ErrNotFound, "no sector", func() {
+			rt.Call(actor.a.GetSectorInfo, &miner.GetSectorInfoParams{SectorNumber: sector.SectorNumber + 1})
+		})
+		rt.Verify()
+	})
+}
+
+func TestEstimateFaultFee(t *testing.T) {
+	periodOffset := abi.ChainEpoch(100)
+	actor := newHarness(t, periodOffset)
+	builder := builderForHarness(actor).
+		WithBalance(bigBalance, big.Zero())
+
+	t.Run("estimates fee without changing state", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		allSectors := actor.commitAndProveSectors(rt, 2, defaultSectorExpiration, nil)
+		advanceAndSubmitPoSts(rt, actor, allSectors...)
+
+		st := getState(rt)
+		dlIdx, pIdx, err := st.FindSector(rt.AdtStore(), allSectors[0].SectorNumber)
+		require.NoError(t, err)
+		sectorNos := bf(uint64(allSectors[0].SectorNumber), uint64(allSectors[1].SectorNumber))
+
+		pwr := miner.PowerForSectors(actor.sectorSize, allSectors)
+		expectedFee := miner.PledgePenaltyForContinuedFault(actor.epochRewardSmooth, actor.epochQAPowerSmooth, pwr.QA)
+
+		ret := actor.estimateFaultFee(rt, dlIdx, pIdx, sectorNos)
+		assert.True(t, pwr.Equals(ret.FaultyPower))
+		assert.Equal(t, expectedFee, ret.DeclaredFaultFee)
+		assert.Equal(t, big.Zero(), ret.DetectedFaultFee)
+		assert.Equal(t, expectedFee, ret.ContinuedFaultFee)
+
+		// Nothing was recorded.
+		dl := actor.getDeadline(rt, dlIdx)
+		assert.True(t, dl.FaultyPower.IsZero())
+
+		// Sectors already faulty incur no further fee.
+		actor.declareFaults(rt, allSectors[0])
+		onePwr := miner.PowerForSectors(actor.sectorSize, allSectors[1:])
+		ret = actor.estimateFaultFee(rt, dlIdx, pIdx, sectorNos)
+		assert.True(t, onePwr.Equals(ret.FaultyPower))
+		assert.Equal(t, miner.PledgePenaltyForContinuedFault(actor.epochRewardSmooth, actor.epochQAPowerSmooth, onePwr.QA), ret.DeclaredFaultFee)
+		actor.checkState(rt)
+	})
+
+	t.Run("rejects sectors not in partition", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		allSectors := actor.commitAndProveSectors(rt, 1, defaultSectorExpiration, nil)
+		advanceAndSubmitPoSts(rt, actor, allSectors...)
+
+		st := getState(rt)
+		dlIdx, pIdx, err := st.FindSector(rt.AdtStore(), allSectors[0].SectorNumber)
+		require.NoError(t, err)
+
+		rt.ExpectValidateCallerAny()
+		expectQueryNetworkInfo(rt, actor)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "not all sectors are assigned to the partition", func() {
+			rt.Call(actor.a.EstimateFaultFee, &miner.EstimateFaultFeeParams{
+				Deadline:  dlIdx,
+				Partition: pIdx,
+				Sectors:   bf(uint64(allSectors[0].SectorNumber) + 1),
+			})
+		})
+		rt.Verify()
+	})
 }
 
 func TestDeclareRecoveries(t *testing.T) {
@@ -3440,7 +4390,7 @@ func TestWithdrawBalance(t *testing.T) {
 		actor.constructAndVerify(rt)
 
 		// withdraw 1% of balance
-		actor.withdrawFunds(rt, onePercentBalance, onePercentBalance, big.Zero())
+		actor.withdrawFunds(rt, actor.owner, onePercentBalance, onePercentBalance, big.Zero())
 		actor.checkState(rt)
 	})
 
@@ -3452,7 +4402,7 @@ func TestWithdrawBalance(t *testing.T) {
 		st.FeeDebt = big.Add(rt.Balance(), abi.NewTokenAmount(1e18))
 		rt.ReplaceState(st)
 		rt.ExpectAbortContainsMessage(exitcode.ErrInsufficientFunds, "unlocked balance can not repay fee debt", func() {
-			actor.withdrawFunds(rt, onePercentBalance, onePercentBalance, big.Zero())
+			actor.withdrawFunds(rt, actor.owner, onePercentBalance, onePercentBalance, big.Zero())
 		})
 		actor.checkState(rt)
 	})
@@ -3468,9 +4418,51 @@ func TestWithdrawBalance(t *testing.T) {
 
 		requested := rt.Balance()
 		expectedWithdraw := big.Sub(requested, feeDebt)
-		actor.withdrawFunds(rt, requested, expectedWithdraw, feeDebt)
+		actor.withdrawFunds(rt, actor.owner, requested, expectedWithdraw, feeDebt)
+		actor.checkState(rt)
+	})
+
+	t.Run("withdrawals to a beneficiary are limited by its quota", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		beneficiary := tutil.NewIDAddr(t, 1001)
+		actor.changeBeneficiary(rt, actor.owner, beneficiary, onePercentBalance, 1000)
+		actor.changeBeneficiary(rt, beneficiary, beneficiary, onePercentBalance, 1000)
+
+		// The owner's withdrawal is paid to the beneficiary.
+		half := big.Div(onePercentBalance, big.NewInt(2))
+		actor.withdrawFunds(rt, actor.owner, half, half, big.Zero())
+
+		// The beneficiary receives only the rest of its quota.
+		actor.withdrawFunds(rt, beneficiary, onePercentBalance, big.Sub(onePercentBalance, half), big.Zero())
+		assert.Equal(t, onePercentBalance, actor.getInfo(rt).BeneficiaryTerm.UsedQuota)
+
+		// Nothing more once the quota is used up.
+		actor.withdrawFunds(rt, beneficiary, onePercentBalance, big.Zero(), big.Zero())
+		actor.checkState(rt)
+	})
+
+	t.Run("expired beneficiary receives nothing", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		beneficiary := tutil.NewIDAddr(t, 1001)
+		actor.changeBeneficiary(rt, actor.owner, beneficiary, onePercentBalance, 1000)
+		actor.changeBeneficiary(rt, beneficiary, beneficiary, onePercentBalance, 1000)
+
+		rt.SetEpoch(1000)
+		actor.withdrawFunds(rt, beneficiary, onePercentBalance, big.Zero(), big.Zero())
+		assert.True(t, actor.getInfo(rt).BeneficiaryTerm.UsedQuota.IsZero())
 		actor.checkState(rt)
 	})
+
+	t.Run("only owner or beneficiary can withdraw", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		rt.ExpectAbort(exitcode.SysErrForbidden, func() {
+			actor.withdrawFunds(rt, actor.worker, onePercentBalance, onePercentBalance, big.Zero())
+		})
+		rt.Reset()
+	})
 }
 
 func TestRepayDebts(t *testing.T) {
@@ -4223,6 +5215,25 @@ func TestChangeOwnerAddress(t *testing.T) {
 		}
 	})
 
+	t.Run("robust address resolves to ID address", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		newPubkey := tutil.NewBLSAddr(t, 1001)
+		rt.AddIDAddress(newPubkey, newAddr)
+
+		// propose by pubkey address, confirm by ID address
+		rt.SetCaller(actor.owner, builtin.MultisigActorCodeID)
+		actor.changeOwnerAddress(rt, newPubkey)
+		info := actor.getInfo(rt)
+		assert.Equal(t, newAddr, *info.PendingOwnerAddress)
+
+		rt.SetCaller(newAddr, builtin.MultisigActorCodeID)
+		actor.changeOwnerAddress(rt, newAddr)
+		info = actor.getInfo(rt)
+		assert.Equal(t, newAddr, info.Owner)
+		assert.Nil(t, info.PendingOwnerAddress)
+	})
+
 	t.Run("withdraw proposal", func(t *testing.T) {
 		rt := builder.Build(t)
 		actor.constructAndVerify(rt)
@@ -4248,88 +5259,277 @@ func TestChangeOwnerAddress(t *testing.T) {
 		rt := builder.Build(t)
 		actor.constructAndVerify(rt)
 
-		rt.SetCaller(actor.worker, builtin.AccountActorCodeID)
-		rt.ExpectAbort(exitcode.SysErrForbidden, func() {
-			actor.changeOwnerAddress(rt, newAddr)
-		})
-		rt.SetCaller(otherAddr, builtin.MultisigActorCodeID)
-		rt.ExpectAbort(exitcode.SysErrForbidden, func() {
-			actor.changeOwnerAddress(rt, newAddr)
-		})
+		rt.SetCaller(actor.worker, builtin.AccountActorCodeID)
+		rt.ExpectAbort(exitcode.SysErrForbidden, func() {
+			actor.changeOwnerAddress(rt, newAddr)
+		})
+		rt.SetCaller(otherAddr, builtin.MultisigActorCodeID)
+		rt.ExpectAbort(exitcode.SysErrForbidden, func() {
+			actor.changeOwnerAddress(rt, newAddr)
+		})
+	})
+
+	t.Run("only owner can change proposal", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+
+		// Make a proposal
+		rt.SetCaller(actor.owner, builtin.MultisigActorCodeID)
+		actor.changeOwnerAddress(rt, newAddr)
+
+		rt.SetCaller(actor.worker, builtin.AccountActorCodeID)
+		rt.ExpectAbort(exitcode.SysErrForbidden, func() {
+			actor.changeOwnerAddress(rt, otherAddr)
+		})
+		rt.SetCaller(otherAddr, builtin.MultisigActorCodeID)
+		rt.ExpectAbort(exitcode.SysErrForbidden, func() {
+			actor.changeOwnerAddress(rt, otherAddr)
+		})
+
+		// Owner can change it
+		rt.SetCaller(actor.owner, builtin.MultisigActorCodeID)
+		actor.changeOwnerAddress(rt, otherAddr)
+		info := actor.getInfo(rt)
+		assert.Equal(t, actor.owner, info.Owner)
+		assert.Equal(t, otherAddr, *info.PendingOwnerAddress)
+
+	})
+
+	t.Run("only nominee can confirm", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+
+		// Make a proposal
+		rt.SetCaller(actor.owner, builtin.MultisigActorCodeID)
+		actor.changeOwnerAddress(rt, newAddr)
+
+		// Owner re-proposing same address doesn't confirm it.
+		actor.changeOwnerAddress(rt, newAddr)
+		info := actor.getInfo(rt)
+		assert.Equal(t, actor.owner, info.Owner)
+		assert.Equal(t, newAddr, *info.PendingOwnerAddress) // Still staged
+
+		rt.SetCaller(actor.worker, builtin.AccountActorCodeID)
+		rt.ExpectAbort(exitcode.SysErrForbidden, func() {
+			actor.changeOwnerAddress(rt, otherAddr)
+		})
+		rt.SetCaller(otherAddr, builtin.MultisigActorCodeID)
+		rt.ExpectAbort(exitcode.SysErrForbidden, func() {
+			actor.changeOwnerAddress(rt, otherAddr)
+		})
+
+		// New addr can confirm itself
+		rt.SetCaller(newAddr, builtin.MultisigActorCodeID)
+		actor.changeOwnerAddress(rt, newAddr)
+		info = actor.getInfo(rt)
+		assert.Equal(t, newAddr, info.Owner)
+		assert.Nil(t, info.PendingOwnerAddress)
+	})
+
+	t.Run("nominee must confirm self explicitly", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+
+		// Make a proposal
+		rt.SetCaller(actor.owner, builtin.MultisigActorCodeID)
+		actor.changeOwnerAddress(rt, newAddr)
+
+		rt.SetCaller(newAddr, builtin.MultisigActorCodeID)
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			actor.changeOwnerAddress(rt, actor.owner) // Not own address
+		})
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			actor.changeOwnerAddress(rt, otherAddr) // Not own address
+		})
+	})
+
+	t.Run("previous owner loses control", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+
+		rt.SetCaller(actor.owner, builtin.MultisigActorCodeID)
+		actor.changeOwnerAddress(rt, newAddr)
+		rt.SetCaller(newAddr, builtin.MultisigActorCodeID)
+		actor.changeOwnerAddress(rt, newAddr)
+
+		// The old owner can no longer propose a change.
+		rt.SetCaller(actor.owner, builtin.MultisigActorCodeID)
+		rt.ExpectValidateCallerAddr(newAddr)
+		rt.ExpectAbort(exitcode.SysErrForbidden, func() {
+			rt.Call(actor.a.ChangeOwnerAddress, &otherAddr)
+		})
+		rt.Verify()
+
+		// The new owner can.
+		rt.SetCaller(newAddr, builtin.MultisigActorCodeID)
+		rt.ExpectValidateCallerAddr(newAddr)
+		rt.Call(actor.a.ChangeOwnerAddress, &otherAddr)
+		rt.Verify()
+		info := actor.getInfo(rt)
+		assert.Equal(t, newAddr, info.Owner)
+		assert.Equal(t, otherAddr, *info.PendingOwnerAddress)
+	})
+}
+
+func TestChangeBeneficiary(t *testing.T) {
+	actor := newHarness(t, 0)
+	builder := builderForHarness(actor).
+		WithBalance(bigBalance, big.Zero())
+	first := tutil.NewIDAddr(t, 1001)
+	second := tutil.NewIDAddr(t, 1002)
+	quota := abi.NewTokenAmount(1e18)
+	expiration := abi.ChainEpoch(1000)
+
+	t.Run("beneficiary is owner by default", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+
+		ret := actor.getBeneficiary(rt)
+		assert.Equal(t, actor.owner, ret.Active.Beneficiary)
+		assert.True(t, ret.Active.Term.Quota.IsZero())
+		assert.Nil(t, ret.Proposed)
+	})
+
+	t.Run("nominee confirms owner proposal", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+
+		actor.changeBeneficiary(rt, actor.owner, first, quota, expiration)
+		ret := actor.getBeneficiary(rt)
+		assert.Equal(t, actor.owner, ret.Active.Beneficiary)
+		require.NotNil(t, ret.Proposed)
+		assert.True(t, ret.Proposed.ApprovedByBeneficiary) // the owner's term has nothing to protect
+		assert.False(t, ret.Proposed.ApprovedByNominee)
+
+		actor.changeBeneficiary(rt, first, first, quota, expiration)
+		ret = actor.getBeneficiary(rt)
+		assert.Equal(t, first, ret.Active.Beneficiary)
+		assert.Equal(t, quota, ret.Active.Term.Quota)
+		assert.True(t, ret.Active.Term.UsedQuota.IsZero())
+		assert.Equal(t, expiration, ret.Active.Term.Expiration)
+		assert.Nil(t, ret.Proposed)
+		actor.checkState(rt)
 	})
 
-	t.Run("only owner can change proposal", func(t *testing.T) {
+	t.Run("current beneficiary must approve change", func(t *testing.T) {
 		rt := builder.Build(t)
 		actor.constructAndVerify(rt)
+		actor.changeBeneficiary(rt, actor.owner, first, quota, expiration)
+		actor.changeBeneficiary(rt, first, first, quota, expiration)
+
+		actor.changeBeneficiary(rt, actor.owner, second, quota, expiration)
+		actor.changeBeneficiary(rt, second, second, quota, expiration)
+		ret := actor.getBeneficiary(rt)
+		assert.Equal(t, first, ret.Active.Beneficiary)
+		require.NotNil(t, ret.Proposed)
+		assert.False(t, ret.Proposed.ApprovedByBeneficiary)
+		assert.True(t, ret.Proposed.ApprovedByNominee)
+
+		actor.changeBeneficiary(rt, first, second, quota, expiration)
+		ret = actor.getBeneficiary(rt)
+		assert.Equal(t, second, ret.Active.Beneficiary)
+		assert.Nil(t, ret.Proposed)
+		actor.checkState(rt)
+	})
 
-		// Make a proposal
-		rt.SetCaller(actor.owner, builtin.MultisigActorCodeID)
-		actor.changeOwnerAddress(rt, newAddr)
+	t.Run("expired beneficiary need not approve change", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		actor.changeBeneficiary(rt, actor.owner, first, quota, expiration)
+		actor.changeBeneficiary(rt, first, first, quota, expiration)
 
-		rt.SetCaller(actor.worker, builtin.AccountActorCodeID)
-		rt.ExpectAbort(exitcode.SysErrForbidden, func() {
-			actor.changeOwnerAddress(rt, otherAddr)
-		})
-		rt.SetCaller(otherAddr, builtin.MultisigActorCodeID)
-		rt.ExpectAbort(exitcode.SysErrForbidden, func() {
-			actor.changeOwnerAddress(rt, otherAddr)
-		})
+		rt.SetEpoch(expiration)
+		actor.changeBeneficiary(rt, actor.owner, second, quota, expiration+1000)
+		actor.changeBeneficiary(rt, second, second, quota, expiration+1000)
+		ret := actor.getBeneficiary(rt)
+		assert.Equal(t, second, ret.Active.Beneficiary)
+		actor.checkState(rt)
+	})
 
-		// Owner can change it
-		rt.SetCaller(actor.owner, builtin.MultisigActorCodeID)
-		actor.changeOwnerAddress(rt, otherAddr)
-		info := actor.getInfo(rt)
-		assert.Equal(t, actor.owner, info.Owner)
-		assert.Equal(t, otherAddr, *info.PendingOwnerAddress)
+	t.Run("change of term for the same beneficiary retains used quota", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		actor.changeBeneficiary(rt, actor.owner, first, quota, expiration)
+		actor.changeBeneficiary(rt, first, first, quota, expiration)
+		withdrawn := big.Div(quota, big.NewInt(2))
+		actor.withdrawFunds(rt, first, withdrawn, withdrawn, big.Zero())
+
+		newQuota := big.Mul(quota, big.NewInt(2))
+		actor.changeBeneficiary(rt, actor.owner, first, newQuota, expiration)
+		actor.changeBeneficiary(rt, first, first, newQuota, expiration)
+		ret := actor.getBeneficiary(rt)
+		assert.Equal(t, newQuota, ret.Active.Term.Quota)
+		assert.Equal(t, withdrawn, ret.Active.Term.UsedQuota)
+		actor.checkState(rt)
+	})
 
+	t.Run("owner reclaims beneficiary with approval", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		actor.changeBeneficiary(rt, actor.owner, first, quota, expiration)
+		actor.changeBeneficiary(rt, first, first, quota, expiration)
+
+		actor.changeBeneficiary(rt, actor.owner, actor.owner, big.Zero(), 0)
+		assert.Equal(t, first, actor.getBeneficiary(rt).Active.Beneficiary)
+		actor.changeBeneficiary(rt, first, actor.owner, big.Zero(), 0)
+		ret := actor.getBeneficiary(rt)
+		assert.Equal(t, actor.owner, ret.Active.Beneficiary)
+		assert.Nil(t, ret.Proposed)
+		actor.checkState(rt)
 	})
 
-	t.Run("only nominee can confirm", func(t *testing.T) {
+	t.Run("invalid proposals are rejected", func(t *testing.T) {
 		rt := builder.Build(t)
 		actor.constructAndVerify(rt)
 
-		// Make a proposal
-		rt.SetCaller(actor.owner, builtin.MultisigActorCodeID)
-		actor.changeOwnerAddress(rt, newAddr)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "owner beneficiary must have zero quota", func() {
+			actor.changeBeneficiary(rt, actor.owner, actor.owner, quota, 0)
+		})
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "must be positive", func() {
+			actor.changeBeneficiary(rt, actor.owner, first, big.Zero(), expiration)
+		})
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "must be after current epoch", func() {
+			actor.changeBeneficiary(rt, actor.owner, first, quota, rt.Epoch())
+		})
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			actor.changeBeneficiary(rt, actor.owner, tutil.NewBLSAddr(t, 1), quota, expiration)
+		})
+		rt.Reset()
+	})
 
-		// Owner re-proposing same address doesn't confirm it.
-		actor.changeOwnerAddress(rt, newAddr)
-		info := actor.getInfo(rt)
-		assert.Equal(t, actor.owner, info.Owner)
-		assert.Equal(t, newAddr, *info.PendingOwnerAddress) // Still staged
+	t.Run("only parties to the change may approve it", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
 
-		rt.SetCaller(actor.worker, builtin.AccountActorCodeID)
+		// No proposal to approve.
 		rt.ExpectAbort(exitcode.SysErrForbidden, func() {
-			actor.changeOwnerAddress(rt, otherAddr)
+			actor.changeBeneficiary(rt, first, first, quota, expiration)
 		})
-		rt.SetCaller(otherAddr, builtin.MultisigActorCodeID)
+
+		actor.changeBeneficiary(rt, actor.owner, first, quota, expiration)
 		rt.ExpectAbort(exitcode.SysErrForbidden, func() {
-			actor.changeOwnerAddress(rt, otherAddr)
+			actor.changeBeneficiary(rt, second, first, quota, expiration)
 		})
-
-		// New addr can confirm itself
-		rt.SetCaller(newAddr, builtin.MultisigActorCodeID)
-		actor.changeOwnerAddress(rt, newAddr)
-		info = actor.getInfo(rt)
-		assert.Equal(t, newAddr, info.Owner)
-		assert.Nil(t, info.PendingOwnerAddress)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "expected approval", func() {
+			actor.changeBeneficiary(rt, first, first, big.Add(quota, big.NewInt(1)), expiration)
+		})
+		assert.Equal(t, actor.owner, actor.getBeneficiary(rt).Active.Beneficiary)
 	})
 
-	t.Run("nominee must confirm self explicitly", func(t *testing.T) {
+	t.Run("owner change moves default beneficiary and cancels pending change", func(t *testing.T) {
 		rt := builder.Build(t)
 		actor.constructAndVerify(rt)
+		actor.changeBeneficiary(rt, actor.owner, first, quota, expiration)
 
-		// Make a proposal
 		rt.SetCaller(actor.owner, builtin.MultisigActorCodeID)
-		actor.changeOwnerAddress(rt, newAddr)
+		actor.changeOwnerAddress(rt, second)
+		rt.SetCaller(second, builtin.MultisigActorCodeID)
+		actor.changeOwnerAddress(rt, second)
 
-		rt.SetCaller(newAddr, builtin.MultisigActorCodeID)
-		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
-			actor.changeOwnerAddress(rt, actor.owner) // Not own address
-		})
-		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
-			actor.changeOwnerAddress(rt, otherAddr) // Not own address
-		})
+		ret := actor.getBeneficiary(rt)
+		assert.Equal(t, second, ret.Active.Beneficiary)
+		assert.Nil(t, ret.Proposed)
+		actor.checkState(rt)
 	})
 }
 
@@ -4529,6 +5729,30 @@ func TestApplyRewards(t *testing.T) {
 		actor.checkState(rt)
 	})
 
+	t.Run("vesting table is queryable", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+
+		ret := actor.getVestingFunds(rt)
+		assert.Empty(t, ret.VestingFunds)
+		assert.Equal(t, big.Zero(), ret.LockedFunds)
+
+		actor.applyRewards(rt, abi.NewTokenAmount(600000), big.Zero())
+		st := getState(rt)
+		vestingFunds, err := st.LoadVestingFunds(adt.AsStore(rt))
+		require.NoError(t, err)
+
+		ret = actor.getVestingFunds(rt)
+		assert.Equal(t, vestingFunds.Funds, ret.VestingFunds)
+		assert.Equal(t, st.LockedFunds, ret.LockedFunds)
+
+		sum := big.Zero()
+		for _, vf := range ret.VestingFunds {
+			sum = big.Add(sum, vf.Amount)
+		}
+		assert.Equal(t, ret.LockedFunds, sum)
+	})
+
 	t.Run("penalty is burnt", func(t *testing.T) {
 		rt := builder.Build(t)
 		actor.constructAndVerify(rt)
@@ -4568,7 +5792,7 @@ func TestApplyRewards(t *testing.T) {
 
 		// burn initial balance + reward = 2*amt
 		expectBurnt := big.Mul(big.NewInt(2), amt)
-		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, expectBurnt, nil, exitcode.Ok)
+		rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.BurnFunds, &builtin.BurnFundsParams{Reason: builtin.BurnReasonBlockRewardPenalty}, expectBurnt, nil, exitcode.Ok)
 
 		rt.Call(actor.a.ApplyRewards, &builtin.ApplyRewardParams{Reward: reward, Penalty: penalty})
 		rt.Verify()
@@ -4624,7 +5848,7 @@ func TestApplyRewards(t *testing.T) {
 		)
 
 		expectBurnt := st.FeeDebt
-		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, expectBurnt, nil, exitcode.Ok)
+		rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.BurnFunds, &builtin.BurnFundsParams{Reason: builtin.BurnReasonBlockRewardPenalty}, expectBurnt, nil, exitcode.Ok)
 
 		rt.Call(actor.a.ApplyRewards, &builtin.ApplyRewardParams{Reward: reward, Penalty: penalty})
 		rt.Verify()
@@ -4734,6 +5958,42 @@ func TestCompactSectorNumbers(t *testing.T) {
 		actor.checkState(rt)
 	})
 
+	t.Run("masking unused numbers collapses allocated ranges", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+
+		allocatedRuns := func() int {
+			st := getState(rt)
+			var allocated bitfield.BitField
+			require.NoError(t, rt.AdtStore().Get(rt.Context(), st.AllocatedSectors, &allocated))
+			it, err := allocated.RunIterator()
+			require.NoError(t, err)
+			runs := 0
+			for it.HasNext() {
+				r, err := it.NextRun()
+				require.NoError(t, err)
+				if r.Val {
+					runs++
+				}
+			}
+			return runs
+		}
+
+		// Two sparse allocations, as left by abandoned pre-commits.
+		actor.compactSectorNumbers(rt, bf(0, 1, 2))
+		actor.compactSectorNumbers(rt, bf(6, 7))
+		assert.Equal(t, 2, allocatedRuns())
+
+		// Masking the gap leaves a single range.
+		actor.compactSectorNumbers(rt, bf(3, 4, 5))
+		assert.Equal(t, 1, allocatedRuns())
+
+		// Masking numbers already allocated changes nothing.
+		actor.compactSectorNumbers(rt, bf(2, 3))
+		assert.Equal(t, 1, allocatedRuns())
+		actor.checkState(rt)
+	})
+
 	t.Run("compacting no sector numbers aborts", func(t *testing.T) {
 		rt := builder.Build(t)
 		actor.constructAndVerify(rt)
@@ -5035,6 +6295,62 @@ func (h *actorHarness) changeOwnerAddress(rt *mock.Runtime, newAddr addr.Address
 	rt.Verify()
 }
 
+func (h *actorHarness) changeBeneficiary(rt *mock.Runtime, caller, beneficiary addr.Address, quota abi.TokenAmount, expiration abi.ChainEpoch) {
+	info := h.getInfo(rt)
+	rt.SetCaller(caller, builtin.AccountActorCodeID)
+	if caller == h.owner || info.PendingBeneficiaryTerm == nil {
+		rt.ExpectValidateCallerAddr(h.owner)
+	} else {
+		rt.ExpectValidateCallerAddr(info.Beneficiary, info.PendingBeneficiaryTerm.NewBeneficiary)
+	}
+	rt.Call(h.a.ChangeBeneficiary, &miner.ChangeBeneficiaryParams{
+		NewBeneficiary: beneficiary,
+		NewQuota:       quota,
+		NewExpiration:  expiration,
+	})
+	rt.Verify()
+}
+
+func (h *actorHarness) getVestingFunds(rt *mock.Runtime) *miner.GetVestingFundsReturn {
+	rt.ExpectValidateCallerAny()
+	ret := rt.Call(h.a.GetVestingFunds, nil).(*miner.GetVestingFundsReturn)
+	rt.Verify()
+	return ret
+}
+
+func (h *actorHarness) deadlineInfo(rt *mock.Runtime, dlIdx uint64) *miner.DeadlineInfoReturn {
+	rt.ExpectValidateCallerAny()
+	ret := rt.Call(h.a.DeadlineInfo, &miner.DeadlineInfoParams{Deadline: dlIdx}).(*miner.DeadlineInfoReturn)
+	rt.Verify()
+	return ret
+}
+
+func (h *actorHarness) getSectorInfo(rt *mock.Runtime, sectorNo abi.SectorNumber) *miner.GetSectorInfoReturn {
+	rt.ExpectValidateCallerAny()
+	ret := rt.Call(h.a.GetSectorInfo, &miner.GetSectorInfoParams{SectorNumber: sectorNo}).(*miner.GetSectorInfoReturn)
+	rt.Verify()
+	return ret
+}
+
+func (h *actorHarness) estimateFaultFee(rt *mock.Runtime, dlIdx, pIdx uint64, sectors bitfield.BitField) *miner.EstimateFaultFeeReturn {
+	rt.ExpectValidateCallerAny()
+	expectQueryNetworkInfo(rt, h)
+	ret := rt.Call(h.a.EstimateFaultFee, &miner.EstimateFaultFeeParams{
+		Deadline:  dlIdx,
+		Partition: pIdx,
+		Sectors:   sectors,
+	}).(*miner.EstimateFaultFeeReturn)
+	rt.Verify()
+	return ret
+}
+
+func (h *actorHarness) getBeneficiary(rt *mock.Runtime) *miner.GetBeneficiaryReturn {
+	rt.ExpectValidateCallerAny()
+	ret := rt.Call(h.a.GetBeneficiary, nil).(*miner.GetBeneficiaryReturn)
+	rt.Verify()
+	return ret
+}
+
 func (h *actorHarness) checkSectorProven(rt *mock.Runtime, sectorNum abi.SectorNumber) {
 	param := &miner.CheckSectorProvenParams{SectorNumber: sectorNum}
 
@@ -5120,6 +6436,10 @@ func (h *actorHarness) preCommitSector(rt *mock.Runtime, params *miner.PreCommit
 		}
 		rt.ExpectSend(builtin.StorageMarketActorAddr, builtin.MethodsMarket.VerifyDealsForActivation, &vdParams, big.Zero(), &vdReturn, exitcode.Ok)
 	}
+	if len(params.CollateralReservations) > 0 {
+		rt.ExpectSend(builtin.StorageMarketActorAddr, builtin.MethodsMarket.ReserveCollateral,
+			&market.ReserveCollateralParams{Reservations: params.CollateralReservations}, big.Zero(), nil, exitcode.Ok)
+	}
 	st := getState(rt)
 
 	if conf.pledgeDelta != nil {
@@ -5134,7 +6454,7 @@ func (h *actorHarness) preCommitSector(rt *mock.Runtime, params *miner.PreCommit
 	}
 
 	if st.FeeDebt.GreaterThan(big.Zero()) {
-		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, st.FeeDebt, nil, exitcode.Ok)
+		rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.BurnFunds, &builtin.BurnFundsParams{Reason: builtin.BurnReasonFeeDebt}, st.FeeDebt, nil, exitcode.Ok)
 	}
 
 	rt.Call(h.a.PreCommitSector, params)
@@ -5145,8 +6465,11 @@ func (h *actorHarness) preCommitSector(rt *mock.Runtime, params *miner.PreCommit
 // Options for proveCommitSector behaviour.
 // Default zero values should let everything be ok.
 type proveCommitConf struct {
-	verifyDealsExit    map[abi.SectorNumber]exitcode.ExitCode
-	vestingPledgeDelta *abi.TokenAmount
+	dealActivationFailures map[abi.SectorNumber]struct{}
+	vestingPledgeDelta     *abi.TokenAmount
+	// Pieces of the unverified deals activated for each sector. By default, the pieces claimed against
+	// allocations, each of a deal lasting the sector's remaining lifetime.
+	unverifiedPieces map[abi.SectorNumber][]market.UnverifiedPiece
 }
 
 func (h *actorHarness) proveCommitSector(rt *mock.Runtime, precommit *miner.SectorPreCommitOnChainInfo, params *miner.ProveCommitSectorParams) {
@@ -5196,29 +6519,88 @@ func (h *actorHarness) proveCommitSector(rt *mock.Runtime, precommit *miner.Sect
 }
 
 func (h *actorHarness) confirmSectorProofsValid(rt *mock.Runtime, conf proveCommitConf, precommits ...*miner.SectorPreCommitOnChainInfo) {
+	allSectorNumbers := h.expectConfirmSectorProofsValid(rt, conf, precommits...)
+	rt.SetCaller(builtin.StoragePowerActorAddr, builtin.StoragePowerActorCodeID)
+	rt.ExpectValidateCallerAddr(builtin.StoragePowerActorAddr)
+	rt.Call(h.a.ConfirmSectorProofsValid, &builtin.ConfirmSectorProofsParams{Sectors: allSectorNumbers})
+	rt.Verify()
+}
+
+// Sets up the expectations for activating proven pre-commitments, returning their sector numbers.
+func (h *actorHarness) expectConfirmSectorProofsValid(rt *mock.Runtime, conf proveCommitConf, precommits ...*miner.SectorPreCommitOnChainInfo) []abi.SectorNumber {
 	// expect calls to get network stats
 	expectQueryNetworkInfo(rt, h)
 
 	// Prepare for and receive call to ConfirmSectorProofsValid.
 	var validPrecommits []*miner.SectorPreCommitOnChainInfo
 	var allSectorNumbers []abi.SectorNumber
+	var sectorDeals []market.SectorDeals
+	var activations []market.SectorDealActivation
+	claimedWeights := map[abi.SectorNumber]abi.DealWeight{}
 	for _, precommit := range precommits {
 		allSectorNumbers = append(allSectorNumbers, precommit.Info.SectorNumber)
+		if len(precommit.Info.DealIDs) == 0 {
+			validPrecommits = append(validPrecommits, precommit)
+			continue
+		}
+
+		// The deals of all pre-commits are activated in a single batch.
+		sectorDeals = append(sectorDeals, market.SectorDeals{
+			SectorExpiry: precommit.Info.Expiration,
+			DealIDs:      precommit.Info.DealIDs,
+		})
+		if _, failed := conf.dealActivationFailures[precommit.Info.SectorNumber]; failed {
+			activations = append(activations, market.SectorDealActivation{
+				DealWeight:         big.Zero(),
+				VerifiedDealWeight: big.Zero(),
+			})
+			continue
+		}
+		// The pieces of the sector's unverified deals include those claimed against allocations.
+		pieces, ok := conf.unverifiedPieces[precommit.Info.SectorNumber]
+		if !ok {
+			pieces = claimedPieces(precommit.Info.AllocationClaims, precommit.Info.Expiration-rt.Epoch())
+		}
+		activation := market.SectorDealActivation{
+			Activated:          true,
+			DealWeight:         precommit.DealWeight,
+			VerifiedDealWeight: precommit.VerifiedDealWeight,
+			UnverifiedPieces:   pieces,
+		}
+		for _, piece := range pieces {
+			activation.DealSpace += uint64(piece.Size)
+			activation.PieceCIDs = append(activation.PieceCIDs, piece.PieceCID)
+		}
+		activations = append(activations, activation)
+		claimedWeights[precommit.Info.SectorNumber] = claimedDealWeight(precommit.Info.AllocationClaims, pieces)
 		validPrecommits = append(validPrecommits, precommit)
-		if len(precommit.Info.DealIDs) > 0 {
-			vdParams := market.ActivateDealsParams{
-				DealIDs:      precommit.Info.DealIDs,
+	}
+	if len(sectorDeals) > 0 {
+		rt.ExpectSend(builtin.StorageMarketActorAddr, builtin.MethodsMarket.BatchActivateDeals,
+			&market.BatchActivateDealsParams{Sectors: sectorDeals}, big.Zero(),
+			&market.BatchActivateDealsReturn{Sectors: activations}, exitcode.Ok)
+	}
+
+	// The allocations claimed by all activated sectors are claimed in a single batch.
+	var claims []verifreg.SectorAllocationClaim
+	claimedSpace := big.Zero()
+	for _, precommit := range validPrecommits {
+		for _, claim := range precommit.Info.AllocationClaims {
+			claims = append(claims, verifreg.SectorAllocationClaim{
+				AllocationId: claim.AllocationID,
+				Data:         claim.Data,
+				Size:         claim.Size,
+				Sector:       precommit.Info.SectorNumber,
 				SectorExpiry: precommit.Info.Expiration,
-			}
-			exit, found := conf.verifyDealsExit[precommit.Info.SectorNumber]
-			if found {
-				validPrecommits = validPrecommits[:len(validPrecommits)-1] // pop
-			} else {
-				exit = exitcode.Ok
-			}
-			rt.ExpectSend(builtin.StorageMarketActorAddr, builtin.MethodsMarket.ActivateDeals, &vdParams, big.Zero(), nil, exit)
+			})
+			claimedSpace = big.Add(claimedSpace, big.NewIntUnsigned(uint64(claim.Size)))
 		}
 	}
+	if len(claims) > 0 {
+		rt.ExpectSend(builtin.VerifiedRegistryActorAddr, builtin.MethodsVerifiedRegistry.ClaimAllocations,
+			&verifreg.ClaimAllocationsParams{Sectors: claims}, big.Zero(),
+			&verifreg.ClaimAllocationsReturn{ClaimedSpace: claimedSpace}, exitcode.Ok)
+	}
 
 	// expected pledge is the sum of initial pledges
 	if len(validPrecommits) > 0 {
@@ -5231,7 +6613,13 @@ func (h *actorHarness) confirmSectorProofsValid(rt *mock.Runtime, conf proveComm
 
 			duration := precommit.Info.Expiration - rt.Epoch()
 			if duration >= miner.MinSectorExpiration {
-				qaPowerDelta := miner.QAPowerForWeight(h.sectorSize, duration, precommitOnChain.DealWeight, precommitOnChain.VerifiedDealWeight)
+				claimedWeight, ok := claimedWeights[precommit.Info.SectorNumber]
+				if !ok {
+					claimedWeight = big.Zero()
+				}
+				dealWeight := big.Sub(precommitOnChain.DealWeight, claimedWeight)
+				verifiedDealWeight := big.Add(precommitOnChain.VerifiedDealWeight, claimedWeight)
+				qaPowerDelta := miner.QAPowerForWeight(h.sectorSize, duration, dealWeight, verifiedDealWeight)
 				expectQAPower = big.Add(expectQAPower, qaPowerDelta)
 				expectRawPower = big.Add(expectRawPower, big.NewIntUnsigned(uint64(h.sectorSize)))
 				pledge := miner.InitialPledgeForPower(qaPowerDelta, h.baselinePower, h.epochRewardSmooth,
@@ -5255,10 +6643,91 @@ func (h *actorHarness) confirmSectorProofsValid(rt *mock.Runtime, conf proveComm
 			rt.ExpectSend(builtin.StoragePowerActorAddr, builtin.MethodsPower.UpdatePledgeTotal, &expectPledge, big.Zero(), nil, exitcode.Ok)
 		}
 	}
+	return allSectorNumbers
+}
 
-	rt.SetCaller(builtin.StoragePowerActorAddr, builtin.StoragePowerActorCodeID)
-	rt.ExpectValidateCallerAddr(builtin.StoragePowerActorAddr)
-	rt.Call(h.a.ConfirmSectorProofsValid, &builtin.ConfirmSectorProofsParams{Sectors: allSectorNumbers})
+// Returns the pieces of unverified deals claimed against allocations, each of a deal lasting some duration.
+func claimedPieces(claims []miner.AllocationClaim, duration abi.ChainEpoch) []market.UnverifiedPiece {
+	var pieces []market.UnverifiedPiece
+	for _, claim := range claims {
+		pieces = append(pieces, market.UnverifiedPiece{
+			Size:       claim.Size,
+			PieceCID:   claim.Data,
+			DealWeight: big.Mul(big.NewIntUnsigned(uint64(claim.Size)), big.NewInt(int64(duration))),
+		})
+	}
+	return pieces
+}
+
+// Sums the weights of the deals claimed against allocations, each claim taking the first unclaimed piece of the
+// same CID and size.
+func claimedDealWeight(claims []miner.AllocationClaim, pieces []market.UnverifiedPiece) abi.DealWeight {
+	claimed := make([]bool, len(pieces))
+	weight := big.Zero()
+	for _, claim := range claims {
+		for i, piece := range pieces {
+			if !claimed[i] && piece.Size == claim.Size && piece.PieceCID.Equals(claim.Data) {
+				claimed[i] = true
+				weight = big.Add(weight, piece.DealWeight)
+				break
+			}
+		}
+	}
+	return weight
+}
+
+// Proves a batch of pre-commitments with an aggregate proof, expecting all of them to be activated.
+func (h *actorHarness) proveCommitAggregateSector(rt *mock.Runtime, conf proveCommitConf, precommits []*miner.SectorPreCommitOnChainInfo, aggregateProof []byte) {
+	commd := cbg.CborCid(tutil.MakeCID("commd", &market.PieceCIDPrefix))
+	sealRand := abi.SealRandomness([]byte{1, 2, 3, 4})
+	sealIntRand := abi.InteractiveSealRandomness([]byte{5, 6, 7, 8})
+
+	var sectorNos []uint64
+	var infos []proof.AggregateSealVerifyInfo
+	for _, precommit := range precommits {
+		sectorNos = append(sectorNos, uint64(precommit.Info.SectorNumber))
+		cdcParams := market.ComputeDataCommitmentParams{
+			DealIDs:    precommit.Info.DealIDs,
+			SectorType: precommit.Info.SealProof,
+		}
+		rt.ExpectSend(builtin.StorageMarketActorAddr, builtin.MethodsMarket.ComputeDataCommitment, &cdcParams, big.Zero(), &commd, exitcode.Ok)
+
+		var buf bytes.Buffer
+		receiver := rt.Receiver()
+		require.NoError(h.t, receiver.MarshalCBOR(&buf))
+		interactiveEpoch := precommit.PreCommitEpoch + miner.PreCommitChallengeDelay
+		rt.ExpectGetRandomnessTickets(crypto.DomainSeparationTag_SealRandomness, precommit.Info.SealRandEpoch, buf.Bytes(), abi.Randomness(sealRand))
+		rt.ExpectGetRandomnessBeacon(crypto.DomainSeparationTag_InteractiveSealChallengeSeed, interactiveEpoch, buf.Bytes(), abi.Randomness(sealIntRand))
+
+		infos = append(infos, proof.AggregateSealVerifyInfo{
+			Number:                precommit.Info.SectorNumber,
+			Randomness:            sealRand,
+			InteractiveRandomness: sealIntRand,
+			SealedCID:             precommit.Info.SealedCID,
+			UnsealedCID:           cid.Cid(commd),
+		})
+	}
+
+	actorID, err := addr.IDFromAddress(h.receiver)
+	require.NoError(h.t, err)
+	rt.ExpectAggregateVerifySeals(proof.AggregateSealVerifyProofAndInfos{
+		Miner:          abi.ActorID(actorID),
+		SealProof:      h.sealProofType,
+		AggregateProof: proof.RegisteredAggregationProof_SnarkPackV1,
+		Proof:          aggregateProof,
+		Infos:          infos,
+	}, nil)
+
+	h.expectConfirmSectorProofsValid(rt, conf, precommits...)
+	expectFee := miner.AggregateNetworkFee(len(precommits), rt.BaseFee())
+	rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.BurnFunds, &builtin.BurnFundsParams{Reason: builtin.BurnReasonAggregateFee}, expectFee, nil, exitcode.Ok)
+
+	rt.SetCaller(h.worker, builtin.AccountActorCodeID)
+	rt.ExpectValidateCallerAddr(append(h.controlAddrs, h.owner, h.worker)...)
+	rt.Call(h.a.ProveCommitAggregate, &miner.ProveCommitAggregateParams{
+		SectorNumbers:  bitfield.NewFromSet(sectorNos),
+		AggregateProof: aggregateProof,
+	})
 	rt.Verify()
 }
 
@@ -5490,7 +6959,7 @@ func (h *actorHarness) disputeWindowPoSt(rt *mock.Runtime, deadline *dline.Info,
 		}
 		// expect penalty
 		if !expectSuccess.expectedPenalty.IsZero() {
-			rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, expectSuccess.expectedPenalty, nil, exitcode.Ok)
+			rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.BurnFunds, &builtin.BurnFundsParams{Reason: builtin.BurnReasonInvalidPoSt}, expectSuccess.expectedPenalty, nil, exitcode.Ok)
 		}
 		// expect pledge update
 		if !expectSuccess.expectedPledgeDelta.IsZero() {
@@ -5627,6 +7096,16 @@ func (h *actorHarness) submitWindowPoStRaw(rt *mock.Runtime, deadline *dline.Inf
 }
 
 func (h *actorHarness) declareFaults(rt *mock.Runtime, faultSectorInfos ...*miner.SectorOnChainInfo) miner.PowerPair {
+	return h.declareFaultsWithLateness(rt, false, faultSectorInfos...)
+}
+
+// Declares faults after their deadline's fault declaration cutoff, expecting the late fault penalty
+// to be paid from vesting funds.
+func (h *actorHarness) declareLateFaults(rt *mock.Runtime, faultSectorInfos ...*miner.SectorOnChainInfo) miner.PowerPair {
+	return h.declareFaultsWithLateness(rt, true, faultSectorInfos...)
+}
+
+func (h *actorHarness) declareFaultsWithLateness(rt *mock.Runtime, late bool, faultSectorInfos ...*miner.SectorOnChainInfo) miner.PowerPair {
 	rt.SetCaller(h.worker, builtin.AccountActorCodeID)
 	rt.ExpectValidateCallerAddr(append(h.controlAddrs, h.owner, h.worker)...)
 
@@ -5650,6 +7129,14 @@ func (h *actorHarness) declareFaults(rt *mock.Runtime, faultSectorInfos ...*mine
 		exitcode.Ok,
 	)
 
+	if late {
+		expectQueryNetworkInfo(rt, h)
+		penalty := miner.PledgePenaltyForLateDeclaredFault(h.epochRewardSmooth, h.epochQAPowerSmooth, expectedQADelta.Neg())
+		rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.BurnFunds, &builtin.BurnFundsParams{Reason: builtin.BurnReasonProvingDeadline}, penalty, nil, exitcode.Ok)
+		pledgeDelta := penalty.Neg()
+		rt.ExpectSend(builtin.StoragePowerActorAddr, builtin.MethodsPower.UpdatePledgeTotal, &pledgeDelta, big.Zero(), nil, exitcode.Ok)
+	}
+
 	// Calculate params from faulted sector infos
 	st := getState(rt)
 	params := makeFaultParamsFromFaultingSectors(h.t, st, rt.AdtStore(), faultSectorInfos)
@@ -5664,7 +7151,7 @@ func (h *actorHarness) declareRecoveries(rt *mock.Runtime, deadlineIdx uint64, p
 	rt.ExpectValidateCallerAddr(append(h.controlAddrs, h.owner, h.worker)...)
 
 	if expectedDebtRepaid.GreaterThan(big.Zero()) {
-		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, expectedDebtRepaid, nil, exitcode.Ok)
+		rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.BurnFunds, &builtin.BurnFundsParams{Reason: builtin.BurnReasonFeeDebt}, expectedDebtRepaid, nil, exitcode.Ok)
 	}
 
 	// Calculate params from faulted sector infos
@@ -5712,6 +7199,113 @@ func (h *actorHarness) extendSectors(rt *mock.Runtime, params *miner.ExtendSecto
 	rt.Verify()
 }
 
+// Options for proveReplicaUpdate behaviour.
+type replicaUpdateConf struct {
+	dealSpace          abi.SectorSize
+	dealWeight         abi.DealWeight
+	verifiedDealWeight abi.DealWeight
+	// Pieces of the unverified deals activated. By default, the pieces claimed against allocations,
+	// each of a deal lasting the sector's remaining lifetime.
+	unverifiedPieces  []market.UnverifiedPiece
+	verificationError error
+}
+
+func (h *actorHarness) proveReplicaUpdate(rt *mock.Runtime, update miner.ReplicaUpdate, conf replicaUpdateConf) {
+	rt.SetCaller(h.worker, builtin.AccountActorCodeID)
+	rt.ExpectValidateCallerAddr(append(h.controlAddrs, h.owner, h.worker)...)
+
+	oldSector := h.getSector(rt, update.SectorNumber)
+	unverifiedPieces := conf.unverifiedPieces
+	if unverifiedPieces == nil {
+		unverifiedPieces = claimedPieces(update.AllocationClaims, oldSector.Expiration-rt.Epoch())
+	}
+	if len(update.Deals) > 0 {
+		adParams := market.BatchActivateDealsParams{
+			Sectors: []market.SectorDeals{{
+				SectorExpiry: oldSector.Expiration,
+				DealIDs:      update.Deals,
+			}},
+		}
+		activation := market.SectorDealActivation{
+			Activated:          true,
+			DealSpace:          uint64(conf.dealSpace),
+			DealWeight:         conf.dealWeight,
+			VerifiedDealWeight: conf.verifiedDealWeight,
+		}
+		// The pieces of the sector's unverified deals include those claimed against allocations.
+		activation.UnverifiedPieces = unverifiedPieces
+		adReturn := market.BatchActivateDealsReturn{
+			Sectors: []market.SectorDealActivation{activation},
+		}
+		rt.ExpectSend(builtin.StorageMarketActorAddr, builtin.MethodsMarket.BatchActivateDeals, &adParams, big.Zero(), &adReturn, exitcode.Ok)
+	}
+
+	commd := cbg.CborCid(tutil.MakeCID("commd", &market.PieceCIDPrefix))
+	cdcParams := market.ComputeDataCommitmentParams{
+		DealIDs:    update.Deals,
+		SectorType: oldSector.SealProof,
+	}
+	rt.ExpectSend(builtin.StorageMarketActorAddr, builtin.MethodsMarket.ComputeDataCommitment, &cdcParams, big.Zero(), &commd, exitcode.Ok)
+	rt.ExpectVerifyReplicaUpdate(proof.ReplicaUpdateInfo{
+		SealProof:            oldSector.SealProof,
+		OldSealedSectorCID:   oldSector.SealedCID,
+		NewSealedSectorCID:   update.NewSealedSectorCID,
+		NewUnsealedSectorCID: cid.Cid(commd),
+		Proof:                update.ReplicaProof,
+	}, conf.verificationError)
+
+	if conf.verificationError == nil {
+		var claims []verifreg.SectorAllocationClaim
+		claimedSpace := big.Zero()
+		for _, claim := range update.AllocationClaims {
+			claims = append(claims, verifreg.SectorAllocationClaim{
+				AllocationId: claim.AllocationID,
+				Data:         claim.Data,
+				Size:         claim.Size,
+				Sector:       update.SectorNumber,
+				SectorExpiry: oldSector.Expiration,
+			})
+			claimedSpace = big.Add(claimedSpace, big.NewIntUnsigned(uint64(claim.Size)))
+		}
+		if len(claims) > 0 {
+			rt.ExpectSend(builtin.VerifiedRegistryActorAddr, builtin.MethodsVerifiedRegistry.ClaimAllocations,
+				&verifreg.ClaimAllocationsParams{Sectors: claims}, big.Zero(),
+				&verifreg.ClaimAllocationsReturn{ClaimedSpace: claimedSpace}, exitcode.Ok)
+		}
+
+		expectQueryNetworkInfo(rt, h)
+
+		duration := oldSector.Expiration - rt.Epoch()
+		claimedWeight := claimedDealWeight(update.AllocationClaims, unverifiedPieces)
+		dealWeight := big.Sub(conf.dealWeight, claimedWeight)
+		verifiedDealWeight := big.Add(conf.verifiedDealWeight, claimedWeight)
+		oldQAPower := miner.QAPowerForSector(h.sectorSize, oldSector)
+		newQAPower := miner.QAPowerForWeight(h.sectorSize, duration, dealWeight, verifiedDealWeight)
+		qaDelta := big.Sub(newQAPower, oldQAPower)
+		newPledge := miner.InitialPledgeForPower(newQAPower, h.baselinePower, h.epochRewardSmooth,
+			h.epochQAPowerSmooth, rt.TotalFilCircSupply())
+		pledgeDelta := big.Sub(big.Max(newPledge, oldSector.InitialPledge), oldSector.InitialPledge)
+
+		// Power and pledge are updated together, with one power delta per sector.
+		batch := power.UpdateClaimedPowerBatchParams{
+			Deltas:      []power.UpdateClaimedPowerParams{},
+			PledgeDelta: pledgeDelta,
+		}
+		if !qaDelta.IsZero() {
+			batch.Deltas = append(batch.Deltas, power.UpdateClaimedPowerParams{
+				RawByteDelta:         big.Zero(),
+				QualityAdjustedDelta: qaDelta,
+			})
+		}
+		if len(batch.Deltas) > 0 || !pledgeDelta.IsZero() {
+			rt.ExpectSend(builtin.StoragePowerActorAddr, builtin.MethodsPower.UpdateClaimedPowerBatch, &batch, big.Zero(), nil, exitcode.Ok)
+		}
+	}
+
+	rt.Call(h.a.ProveReplicaUpdates, &miner.ProveReplicaUpdatesParams{Updates: []miner.ReplicaUpdate{update}})
+	rt.Verify()
+}
+
 func (h *actorHarness) terminateSectors(rt *mock.Runtime, sectors bitfield.BitField, expectedFee abi.TokenAmount) (miner.PowerPair, abi.TokenAmount) {
 	rt.SetCaller(h.worker, builtin.AccountActorCodeID)
 	rt.ExpectValidateCallerAddr(append(h.controlAddrs, h.owner, h.worker)...)
@@ -5732,7 +7326,7 @@ func (h *actorHarness) terminateSectors(rt *mock.Runtime, sectors bitfield.BitFi
 	pledgeDelta := big.Zero()
 	var sectorPower miner.PowerPair
 	if big.Zero().LessThan(expectedFee) {
-		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, expectedFee, nil, exitcode.Ok)
+		rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.BurnFunds, &builtin.BurnFundsParams{Reason: builtin.BurnReasonTerminationFee}, expectedFee, nil, exitcode.Ok)
 		pledgeDelta = big.Sum(pledgeDelta, expectedFee.Neg())
 	}
 	// notify change to initial pledge
@@ -5817,7 +7411,7 @@ func (h *actorHarness) reportConsensusFault(rt *mock.Runtime, from addr.Address,
 
 	// pay fault fee
 	toBurn := big.Sub(penaltyTotal, rwd)
-	rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, toBurn, nil, exitcode.Ok)
+	rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.BurnFunds, &builtin.BurnFundsParams{Reason: builtin.BurnReasonConsensusFault}, toBurn, nil, exitcode.Ok)
 
 	rt.Call(h.a.ReportConsensusFault, params)
 	rt.Verify()
@@ -5847,7 +7441,7 @@ func (h *actorHarness) applyRewards(rt *mock.Runtime, amt, penalty abi.TokenAmou
 	)
 
 	if penalty.GreaterThan(big.Zero()) {
-		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, penalty, nil, exitcode.Ok)
+		rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.BurnFunds, &builtin.BurnFundsParams{Reason: builtin.BurnReasonBlockRewardPenalty}, penalty, nil, exitcode.Ok)
 	}
 
 	rt.Call(h.a.ApplyRewards, &builtin.ApplyRewardParams{Reward: amt, Penalty: penalty})
@@ -5860,8 +7454,11 @@ type cronConfig struct {
 	expiredSectorsPowerDelta  *miner.PowerPair
 	expiredSectorsPledgeDelta abi.TokenAmount
 	continuedFaultsPenalty    abi.TokenAmount // Expected amount burnt to pay continued fault penalties.
+	detectedFaultsPenalty     abi.TokenAmount // Expected amount burnt to pay detected fault penalties.
 	repaidFeeDebt             abi.TokenAmount // Expected amount burnt to repay fee debt.
 	penaltyFromUnlocked       abi.TokenAmount // Expected reduction in unlocked balance from penalties exceeding vesting funds.
+	expiredPreCommitDeposit   abi.TokenAmount // Expected amount burnt for deposits of expired pre-commitments.
+	releasedCollateralDeals   []abi.DealID    // Expected deals whose reserved collateral is released as their pre-commitments expire.
 }
 
 func (h *actorHarness) onDeadlineCron(rt *mock.Runtime, config *cronConfig) {
@@ -5906,11 +7503,17 @@ func (h *actorHarness) onDeadlineCron(rt *mock.Runtime, config *cronConfig) {
 	if !config.continuedFaultsPenalty.NilOrZero() {
 		penaltyTotal = big.Add(penaltyTotal, config.continuedFaultsPenalty)
 	}
+	if !config.detectedFaultsPenalty.NilOrZero() {
+		penaltyTotal = big.Add(penaltyTotal, config.detectedFaultsPenalty)
+	}
 	if !config.repaidFeeDebt.NilOrZero() {
 		penaltyTotal = big.Add(penaltyTotal, config.repaidFeeDebt)
 	}
+	if !config.expiredPreCommitDeposit.NilOrZero() {
+		penaltyTotal = big.Add(penaltyTotal, config.expiredPreCommitDeposit)
+	}
 	if !penaltyTotal.IsZero() {
-		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, penaltyTotal, nil, exitcode.Ok)
+		rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.BurnFunds, &builtin.BurnFundsParams{Reason: builtin.BurnReasonProvingDeadline}, penaltyTotal, nil, exitcode.Ok)
 		penaltyFromVesting := penaltyTotal
 		// Outstanding fee debt is only repaid from unlocked balance, not vesting funds.
 		if !config.repaidFeeDebt.NilOrZero() {
@@ -5933,6 +7536,11 @@ func (h *actorHarness) onDeadlineCron(rt *mock.Runtime, config *cronConfig) {
 		rt.ExpectSend(builtin.StoragePowerActorAddr, builtin.MethodsPower.UpdatePledgeTotal, &pledgeDelta, big.Zero(), nil, exitcode.Ok)
 	}
 
+	if len(config.releasedCollateralDeals) > 0 {
+		rt.ExpectSend(builtin.StorageMarketActorAddr, builtin.MethodsMarket.ReleaseCollateral,
+			&market.ReleaseCollateralParams{DealIDs: config.releasedCollateralDeals}, big.Zero(), nil, exitcode.Ok)
+	}
+
 	// Re-enrollment for next period.
 	rt.ExpectSend(builtin.StoragePowerActorAddr, builtin.MethodsPower.EnrollCronEvent,
 		makeDeadlineCronEventParams(h.t, config.expectedEnrollment), big.Zero(), nil, exitcode.Ok)
@@ -5944,13 +7552,16 @@ func (h *actorHarness) onDeadlineCron(rt *mock.Runtime, config *cronConfig) {
 	rt.Verify()
 }
 
-func (h *actorHarness) withdrawFunds(rt *mock.Runtime, amountRequested, amountWithdrawn, expectedDebtRepaid abi.TokenAmount) {
-	rt.SetCaller(h.owner, builtin.AccountActorCodeID)
-	rt.ExpectValidateCallerAddr(h.owner)
+func (h *actorHarness) withdrawFunds(rt *mock.Runtime, caller addr.Address, amountRequested, amountWithdrawn, expectedDebtRepaid abi.TokenAmount) {
+	info := h.getInfo(rt)
+	rt.SetCaller(caller, builtin.AccountActorCodeID)
+	rt.ExpectValidateCallerAddr(h.owner, info.Beneficiary)
 
-	rt.ExpectSend(h.owner, builtin.MethodSend, nil, amountWithdrawn, nil, exitcode.Ok)
+	if amountWithdrawn.GreaterThan(big.Zero()) {
+		rt.ExpectSend(info.Beneficiary, builtin.MethodSend, nil, amountWithdrawn, nil, exitcode.Ok)
+	}
 	if expectedDebtRepaid.GreaterThan(big.Zero()) {
-		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, expectedDebtRepaid, nil, exitcode.Ok)
+		rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.BurnFunds, &builtin.BurnFundsParams{Reason: builtin.BurnReasonFeeDebt}, expectedDebtRepaid, nil, exitcode.Ok)
 	}
 	rt.Call(h.a.WithdrawBalance, &miner.WithdrawBalanceParams{
 		AmountRequested: amountRequested,
@@ -5972,7 +7583,7 @@ func (h *actorHarness) repayDebt(rt *mock.Runtime, value, expectedRepayedFromVes
 
 	totalRepaid := big.Sum(expectedRepayedFromVest, expectedRepaidFromBalance)
 	if totalRepaid.GreaterThan((big.Zero())) {
-		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, totalRepaid, nil, exitcode.Ok)
+		rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.BurnFunds, &builtin.BurnFundsParams{Reason: builtin.BurnReasonFeeDebt}, totalRepaid, nil, exitcode.Ok)
 	}
 	rt.Call(h.a.RepayDebt, nil)
 
@@ -5994,6 +7605,11 @@ func (h *actorHarness) continuedFaultPenalty(sectors []*miner.SectorOnChainInfo)
 	return miner.PledgePenaltyForContinuedFault(h.epochRewardSmooth, h.epochQAPowerSmooth, qa)
 }
 
+func (h *actorHarness) detectedFaultPenalty(sectors []*miner.SectorOnChainInfo) abi.TokenAmount {
+	_, qa := powerForSectors(h.sectorSize, sectors)
+	return miner.PledgePenaltyForDetectedFault(h.epochRewardSmooth, h.epochQAPowerSmooth, qa)
+}
+
 func (h *actorHarness) powerPairForSectors(sectors []*miner.SectorOnChainInfo) miner.PowerPair {
 	rawPower, qaPower := powerForSectors(h.sectorSize, sectors)
 	return miner.NewPowerPair(rawPower, qaPower)
@@ -6,31 +6,17 @@ import (
 	"github.com/filecoin-project/go-state-types/exitcode"
 
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
-	"github.com/filecoin-project/specs-actors/v3/actors/util/math"
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin/power"
 	"github.com/filecoin-project/specs-actors/v3/actors/util/smoothing"
 )
 
-// Projection period of expected sector block reward for deposit required to pre-commit a sector.
-// This deposit is lost if the pre-commitment is not timely followed up by a commitment proof.
-var PreCommitDepositFactor = 20 // PARAM_SPEC
-var PreCommitDepositProjectionPeriod = abi.ChainEpoch(PreCommitDepositFactor) * builtin.EpochsInDay
-
-// Projection period of expected sector block rewards for storage pledge required to commit a sector.
-// This pledge is lost if a sector is terminated before its full committed lifetime.
-var InitialPledgeFactor = 20 // PARAM_SPEC
-var InitialPledgeProjectionPeriod = abi.ChainEpoch(InitialPledgeFactor) * builtin.EpochsInDay
-
-// Cap on initial pledge requirement for sectors.
-// The target is 1 FIL (10**18 attoFIL) per 32GiB.
-// This does not divide evenly, so the result is fractionally smaller.
-var InitialPledgeMaxPerByte = big.Div(big.NewInt(1e18), big.NewInt(32<<30))
-
-// Multiplier of share of circulating money supply for consensus pledge required to commit a sector.
-// This pledge is lost if a sector is terminated before its full committed lifetime.
-var InitialPledgeLockTarget = builtin.BigFrac{
-	Numerator:   big.NewInt(3), // PARAM_SPEC
-	Denominator: big.NewInt(10),
-}
+// Collateral parameters, defined by the power actor's pledge formulas.
+var PreCommitDepositFactor = power.PreCommitDepositFactor
+var PreCommitDepositProjectionPeriod = power.PreCommitDepositProjectionPeriod
+var InitialPledgeFactor = power.InitialPledgeFactor
+var InitialPledgeProjectionPeriod = power.InitialPledgeProjectionPeriod
+var InitialPledgeMaxPerByte = power.InitialPledgeMaxPerByte
+var InitialPledgeLockTarget = power.InitialPledgeLockTarget
 
 // Projection period of expected daily sector block reward penalised when a fault is continued after initial detection.
 // This guarantees that a miner pays back at least the expected block reward earned since the last successful PoSt.
@@ -46,6 +32,18 @@ var TerminationPenaltyLowerBoundProjectionPeriod = abi.ChainEpoch((builtin.Epoch
 // FF + 2BR
 var InvalidWindowPoStProjectionPeriod = abi.ChainEpoch(ContinuedFaultProjectionPeriod + 2*builtin.EpochsInDay) // PARAM_SPEC
 
+// Projection period of expected sector block reward penalised when a fault is detected by a missed Window PoSt,
+// in addition to the continued fault fee paid by every sector faulty through the deadline.
+// This makes an undetected fault more costly than one declared by the miner, even late.
+// DF = BR(t, DetectedFaultProjectionPeriod)
+var DetectedFaultProjectionPeriod = abi.ChainEpoch(builtin.EpochsInDay) // PARAM_SPEC
+
+// Fraction of the detected fault penalty paid for a fault declared late, within the deadline's self-report window.
+var LateDeclaredFaultFactor = builtin.BigFrac{ // PARAM_SPEC
+	Numerator:   big.NewInt(1),
+	Denominator: big.NewInt(2),
+}
+
 // Fraction of assumed block reward penalized when a sector is terminated.
 var TerminationRewardFactor = builtin.BigFrac{ // PARAM_SPEC
 	Numerator:   big.NewInt(1),
@@ -67,20 +65,23 @@ var BaseRewardForDisputedWindowPoSt = big.Mul(big.NewInt(4), builtin.TokenPrecis
 // Base penalty for a successful disputed window post proof.
 var BasePenaltyForDisputedWindowPoSt = big.Mul(big.NewInt(20), builtin.TokenPrecision) // PARAM_SPEC
 
+// Gas estimated to be used verifying a single sector's seal proof with ProveCommitSector.
+const EstimatedSingleProveCommitGasUsage = 49299973
+
+// Fraction of the gas cost of verifying proofs individually that is charged as a network fee when verifying
+// them in aggregate.
+var BatchDiscount = builtin.BigFrac{ // PARAM_SPEC
+	Numerator:   big.NewInt(1),
+	Denominator: big.NewInt(20),
+}
+
+// Minimum gas price at which the aggregate network fee is charged.
+var BatchBalancer = big.Mul(big.NewInt(5), big.NewInt(1e9)) // PARAM_SPEC
+
 // The projected block reward a sector would earn over some period.
-// Also known as "BR(t)".
-// BR(t) = ProjectedRewardFraction(t) * SectorQualityAdjustedPower
-// ProjectedRewardFraction(t) is the sum of estimated reward over estimated total power
-// over all epochs in the projection period [t t+projectionDuration]
+// See power.ExpectedRewardForPower.
 func ExpectedRewardForPower(rewardEstimate, networkQAPowerEstimate smoothing.FilterEstimate, qaSectorPower abi.StoragePower, projectionDuration abi.ChainEpoch) abi.TokenAmount {
-	networkQAPowerSmoothed := networkQAPowerEstimate.Estimate()
-	if networkQAPowerSmoothed.IsZero() {
-		return rewardEstimate.Estimate()
-	}
-	expectedRewardForProvingPeriod := smoothing.ExtrapolatedCumSumOfRatio(projectionDuration, 0, rewardEstimate, networkQAPowerEstimate)
-	br128 := big.Mul(qaSectorPower, expectedRewardForProvingPeriod) // Q.0 * Q.128 => Q.128
-	br := big.Rsh(br128, math.Precision128)
-	return big.Max(br, big.Zero()) // negative BR is clamped at 0
+	return power.ExpectedRewardForPower(rewardEstimate, networkQAPowerEstimate, qaSectorPower, projectionDuration)
 }
 
 // The penalty for a sector continuing faulty for another proving period.
@@ -90,6 +91,19 @@ func PledgePenaltyForContinuedFault(rewardEstimate, networkQAPowerEstimate smoot
 	return ExpectedRewardForPower(rewardEstimate, networkQAPowerEstimate, qaSectorPower, ContinuedFaultProjectionPeriod)
 }
 
+// The penalty for a sector newly faulty when detected by a missed Window PoSt, or failing to recover.
+// Also known as "DF(t)"
+func PledgePenaltyForDetectedFault(rewardEstimate, networkQAPowerEstimate smoothing.FilterEstimate, qaSectorPower abi.StoragePower) abi.TokenAmount {
+	return ExpectedRewardForPower(rewardEstimate, networkQAPowerEstimate, qaSectorPower, DetectedFaultProjectionPeriod)
+}
+
+// The penalty for a sector declared faulty after its deadline's fault declaration cutoff.
+// It is a fraction of the penalty the fault would incur if instead detected.
+func PledgePenaltyForLateDeclaredFault(rewardEstimate, networkQAPowerEstimate smoothing.FilterEstimate, qaSectorPower abi.StoragePower) abi.TokenAmount {
+	detected := PledgePenaltyForDetectedFault(rewardEstimate, networkQAPowerEstimate, qaSectorPower)
+	return big.Div(big.Mul(detected, LateDeclaredFaultFactor.Numerator), LateDeclaredFaultFactor.Denominator)
+}
+
 // Lower bound on the penalty for a terminating sector.
 // It is a projection of the expected reward earned by the sector.
 // Also known as "SP(t)"
@@ -135,37 +149,15 @@ func PledgePenaltyForInvalidWindowPoSt(rewardEstimate, networkQAPowerEstimate sm
 }
 
 // Computes the PreCommit deposit given sector qa weight and current network conditions.
-// PreCommit Deposit = BR(PreCommitDepositProjectionPeriod)
+// See power.PreCommitDepositForPower.
 func PreCommitDepositForPower(rewardEstimate, networkQAPowerEstimate smoothing.FilterEstimate, qaSectorPower abi.StoragePower) abi.TokenAmount {
-	return ExpectedRewardForPower(rewardEstimate, networkQAPowerEstimate, qaSectorPower, PreCommitDepositProjectionPeriod)
-}
-
-// Computes the pledge requirement for committing new quality-adjusted power to the network, given the current
-// network total and baseline power, per-epoch  reward, and circulating token supply.
-// The pledge comprises two parts:
-// - storage pledge, aka IP base: a multiple of the reward expected to be earned by newly-committed power
-// - consensus pledge, aka additional IP: a pro-rata fraction of the circulating money supply
-//
-// IP = IPBase(t) + AdditionalIP(t)
-// IPBase(t) = BR(t, InitialPledgeProjectionPeriod)
-// AdditionalIP(t) = LockTarget(t)*PledgeShare(t)
-// LockTarget = (LockTargetFactorNum / LockTargetFactorDenom) * FILCirculatingSupply(t)
-// PledgeShare(t) = sectorQAPower / max(BaselinePower(t), NetworkQAPower(t))
-func InitialPledgeForPower(qaPower, baselinePower abi.StoragePower, rewardEstimate, networkQAPowerEstimate smoothing.FilterEstimate, circulatingSupply abi.TokenAmount) abi.TokenAmount {
-	ipBase := ExpectedRewardForPower(rewardEstimate, networkQAPowerEstimate, qaPower, InitialPledgeProjectionPeriod)
-
-	lockTargetNum := big.Mul(InitialPledgeLockTarget.Numerator, circulatingSupply)
-	lockTargetDenom := InitialPledgeLockTarget.Denominator
-	pledgeShareNum := qaPower
-	networkQAPower := networkQAPowerEstimate.Estimate()
-	pledgeShareDenom := big.Max(big.Max(networkQAPower, baselinePower), qaPower) // use qaPower in case others are 0
-	additionalIPNum := big.Mul(lockTargetNum, pledgeShareNum)
-	additionalIPDenom := big.Mul(lockTargetDenom, pledgeShareDenom)
-	additionalIP := big.Div(additionalIPNum, additionalIPDenom)
+	return power.PreCommitDepositForPower(rewardEstimate, networkQAPowerEstimate, qaSectorPower)
+}
 
-	nominalPledge := big.Add(ipBase, additionalIP)
-	spaceRacePledgeCap := big.Mul(InitialPledgeMaxPerByte, qaPower)
-	return big.Min(nominalPledge, spaceRacePledgeCap)
+// Computes the pledge requirement for committing new quality-adjusted power to the network.
+// See power.InitialPledgeForPower.
+func InitialPledgeForPower(qaPower, baselinePower abi.StoragePower, rewardEstimate, networkQAPowerEstimate smoothing.FilterEstimate, circulatingSupply abi.TokenAmount) abi.TokenAmount {
+	return power.InitialPledgeForPower(qaPower, baselinePower, rewardEstimate, networkQAPowerEstimate, circulatingSupply)
 }
 
 // Repays all fee debt and then verifies that the miner has amount needed to cover
@@ -196,3 +188,12 @@ func LockedRewardFromReward(reward abi.TokenAmount) (abi.TokenAmount, *VestSpec)
 	lockAmount := big.Div(big.Mul(reward, LockedRewardFactorNum), LockedRewardFactorDenom)
 	return lockAmount, &RewardVestingSpec
 }
+
+// The network fee for verifying an aggregated proof of the seals of a number of sectors.
+// The fee is a discounted fraction of the gas the individual proofs would have used, priced at the
+// base fee but no less than the BatchBalancer.
+func AggregateNetworkFee(aggregateSize int, baseFee abi.TokenAmount) abi.TokenAmount {
+	effectiveGasFee := big.Max(baseFee, BatchBalancer)
+	gasFee := big.Mul(effectiveGasFee, big.NewInt(EstimatedSingleProveCommitGasUsage*int64(aggregateSize)))
+	return big.Div(big.Mul(gasFee, BatchDiscount.Numerator), BatchDiscount.Denominator)
+}
@@ -540,6 +540,10 @@ func (p *Partition) recordEarlyTermination(store adt.Store, epoch abi.ChainEpoch
 func (p *Partition) TerminateSectors(
 	store adt.Store, sectors Sectors, epoch abi.ChainEpoch, sectorNos bitfield.BitField,
 	ssize abi.SectorSize, quant QuantSpec) (*ExpirationSet, error) {
+	if err := validatePartitionContainsSectors(p, sectorNos); err != nil {
+		return nil, xc.ErrIllegalArgument.Wrapf("failed termination: %w", err)
+	}
+
 	liveSectors, err := p.LiveSectors()
 	if err != nil {
 		return nil, err
@@ -464,7 +464,7 @@ func TestPartitions(t *testing.T) {
 		terminations := bf(99)
 		terminationEpoch := abi.ChainEpoch(3)
 		_, err := partition.TerminateSectors(store, sectorArr, terminationEpoch, terminations, sectorSize, quantSpec)
-		require.EqualError(t, err, "can only terminate live sectors")
+		require.EqualError(t, err, "failed termination: not all sectors are assigned to the partition")
 	})
 
 	t.Run("terminate already terminated sector", func(t *testing.T) {
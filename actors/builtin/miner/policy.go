@@ -10,6 +10,7 @@ import (
 	mh "github.com/multiformats/go-multihash"
 
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin/power"
 )
 
 // The period over which a miner's active sectors are expected to be proven via WindowPoSt.
@@ -84,6 +85,27 @@ const DeclarationsMax = AddressedPartitionsMax
 // This limits the amount of state to be read in a single message execution.
 const AddressedSectorsMax = 10_000 // PARAM_SPEC
 
+// The maximum number of partitions that may be proven in a single Window PoSt submission, by one proof covering
+// them all. A miner proves a deadline with more partitions in several submissions.
+const PoStedPartitionsMax = 3 // PARAM_SPEC
+
+// The maximum number of expired pre-commitments removed, and their deposits burnt, by a single proving deadline
+// cron callback. Expirations beyond this remain queued and are processed by the callbacks of subsequent deadlines.
+var MaxPreCommitExpiriesPerDeadline = uint64(AddressedSectorsMax) // PARAM_SPEC
+
+// Maximum number of sectors that may be updated in a single ProveReplicaUpdates message.
+const ProveReplicaUpdatesMaxSize = 256 // PARAM_SPEC
+
+// Bounds on the number of sectors whose seal proofs may be aggregated in a single ProveCommitAggregate message.
+// Below the minimum, individual proofs are cheaper to verify than an aggregate.
+const (
+	MinAggregatedSectors = 4   // PARAM_SPEC
+	MaxAggregatedSectors = 819 // PARAM_SPEC
+)
+
+// Maximum size in bytes of an aggregated seal proof, attained at MaxAggregatedSectors.
+const MaxAggregateProofSize = 81960 // PARAM_SPEC
+
 // Libp2p peer info limits.
 const (
 	// MaxPeerIDLength is the maximum length allowed for any on-chain peer ID.
@@ -115,35 +137,30 @@ var SealedCIDPrefix = cid.Prefix{
 	MhLength: 32,
 }
 
-// List of proof types which may be used when creating a new miner actor or pre-committing a new sector.
-// This is mutable to allow configuration of testing and development networks.
-var PreCommitSealProofTypesV0 = map[abi.RegisteredSealProof]struct{}{
-	abi.RegisteredSealProof_StackedDrg32GiBV1: {},
-	abi.RegisteredSealProof_StackedDrg64GiBV1: {},
-}
-var PreCommitSealProofTypesV7 = map[abi.RegisteredSealProof]struct{}{
-	abi.RegisteredSealProof_StackedDrg32GiBV1:   {},
-	abi.RegisteredSealProof_StackedDrg64GiBV1:   {},
-	abi.RegisteredSealProof_StackedDrg32GiBV1_1: {},
-	abi.RegisteredSealProof_StackedDrg64GiBV1_1: {},
-}
-
-// From network version 8, sectors sealed with the V1 seal proof types cannot be committed.
-var PreCommitSealProofTypesV8 = map[abi.RegisteredSealProof]struct{}{
-	abi.RegisteredSealProof_StackedDrg32GiBV1_1: {},
-	abi.RegisteredSealProof_StackedDrg64GiBV1_1: {},
+// List of proof types which may be used when creating a new miner actor or pre-committing a new sector,
+// before network version 7, at version 7, and from version 8 respectively.
+//
+// Deprecated: these are copies of the power actor's seal proof registry, taken when the package is initialised.
+// Modifying them has no effect. Use CanPreCommitSealProof to check a proof type, and configure the registry
+// (power.SealProofTypes, power.EnableSealProof) to change the supported types.
+var PreCommitSealProofTypesV0 = sealProofTypesAt(network.Version0)
+var PreCommitSealProofTypesV7 = sealProofTypesAt(network.Version7)
+var PreCommitSealProofTypesV8 = sealProofTypesAt(network.Version8)
+
+func sealProofTypesAt(nv network.Version) map[abi.RegisteredSealProof]struct{} {
+	types := map[abi.RegisteredSealProof]struct{}{}
+	for p := range power.SealProofTypes { //nolint:nomaprange
+		if power.CanUseSealProof(p, nv) {
+			types[p] = struct{}{}
+		}
+	}
+	return types
 }
 
 // Checks whether a seal proof type is supported for new miners and sectors.
+// Supported proof types are configured in the power actor's seal proof registry.
 func CanPreCommitSealProof(s abi.RegisteredSealProof, nv network.Version) bool {
-	_, ok := PreCommitSealProofTypesV0[s]
-	if nv >= network.Version7 {
-		_, ok = PreCommitSealProofTypesV7[s]
-	}
-	if nv >= network.Version8 {
-		_, ok = PreCommitSealProofTypesV8[s]
-	}
-	return ok
+	return power.CanUseSealProof(s, nv)
 }
 
 // List of proof types for which sector lifetime may be extended.
@@ -200,6 +217,14 @@ const WPoStChallengeLookback = abi.ChainEpoch(20) // PARAM_SPEC
 // This guarantees that a miner is not likely to successfully fork the chain and declare a fault after seeing the challenges.
 const FaultDeclarationCutoff = WPoStChallengeLookback + 50 // PARAM_SPEC
 
+// Period after a deadline's challenge window opens during which faults may still be declared for that deadline.
+// A fault declared after the deadline's FaultDeclarationCutoff, but before this window ends, is "late".
+// Late faults pay a reduced penalty, rather than the full penalty for faults detected by a missed Window PoSt
+// when the deadline closes, so that an operator who reports an outage is penalised less than one who doesn't.
+var FaultSelfReportWindow = WPoStChallengeWindow / faultSelfReportWindowDivisor // PARAM_SPEC
+
+const faultSelfReportWindowDivisor = 2
+
 // The maximum age of a fault before the sector is terminated.
 // This bounds the time a miner can lose client's data before sacrificing pledge and deal collateral.
 var FaultMaxAge = WPoStProvingPeriod * 14 // PARAM_SPEC
@@ -144,3 +144,22 @@ func assertEqual(t *testing.T, a, b big.Int) {
 		assert.Equal(t, a, b)
 	}
 }
+
+func TestDeprecatedPreCommitSealProofTypes(t *testing.T) {
+	// The deprecated sets are copies of the power actor's registry when the package was initialised,
+	// before tests enabled any small sector sizes.
+	assert.Equal(t, map[abi.RegisteredSealProof]struct{}{
+		abi.RegisteredSealProof_StackedDrg32GiBV1: {},
+		abi.RegisteredSealProof_StackedDrg64GiBV1: {},
+	}, miner.PreCommitSealProofTypesV0)
+	assert.Equal(t, map[abi.RegisteredSealProof]struct{}{
+		abi.RegisteredSealProof_StackedDrg32GiBV1:   {},
+		abi.RegisteredSealProof_StackedDrg64GiBV1:   {},
+		abi.RegisteredSealProof_StackedDrg32GiBV1_1: {},
+		abi.RegisteredSealProof_StackedDrg64GiBV1_1: {},
+	}, miner.PreCommitSealProofTypesV7)
+	assert.Equal(t, map[abi.RegisteredSealProof]struct{}{
+		abi.RegisteredSealProof_StackedDrg32GiBV1_1: {},
+		abi.RegisteredSealProof_StackedDrg64GiBV1_1: {},
+	}, miner.PreCommitSealProofTypesV8)
+}
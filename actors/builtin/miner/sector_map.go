@@ -6,8 +6,42 @@ import (
 
 	"github.com/filecoin-project/go-bitfield"
 	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/util"
 )
 
+// SectorSelection addresses a set of sectors within one partition of a deadline.
+// Termination, fault and recovery declarations all select sectors in this form.
+type SectorSelection struct {
+	Deadline  uint64
+	Partition uint64
+	Sectors   bitfield.BitField
+}
+
+// SelectSectors collects sector selections into a DeadlineSectorMap, merging selections that address the same
+// partition. It checks the number of selections, the deadline indices, that every bitfield decodes, and that the
+// selection addresses no more than AddressedPartitionsMax partitions and AddressedSectorsMax sectors.
+// Membership of the selected sectors in their partitions can only be checked against state, when each
+// partition is loaded.
+func SelectSectors(selections []SectorSelection) (DeadlineSectorMap, error) {
+	if uint64(len(selections)) > DeclarationsMax {
+		return nil, xerrors.Errorf("too many declarations %d, max %d", len(selections), DeclarationsMax)
+	}
+	dm := make(DeadlineSectorMap)
+	for _, sel := range selections {
+		if err := util.IsValidForChain(sel.Sectors); err != nil {
+			return nil, xerrors.Errorf("invalid sectors at deadline %d, partition %d: %w", sel.Deadline, sel.Partition, err)
+		}
+		if err := dm.Add(sel.Deadline, sel.Partition, sel.Sectors); err != nil {
+			return nil, xerrors.Errorf("failed to select sectors at deadline %d, partition %d: %w", sel.Deadline, sel.Partition, err)
+		}
+	}
+	if err := dm.Check(AddressedPartitionsMax, AddressedSectorsMax); err != nil {
+		return nil, err
+	}
+	return dm, nil
+}
+
 // Maps deadlines to partition maps.
 type DeadlineSectorMap map[uint64]PartitionSectorMap
 
@@ -161,3 +161,49 @@ func TestPartitionSectorMapSorted(t *testing.T) {
 		return pms[i] < pms[j]
 	}))
 }
+
+func TestSelectSectors(t *testing.T) {
+	t.Run("merges selections of the same partition", func(t *testing.T) {
+		dm, err := miner.SelectSectors([]miner.SectorSelection{
+			{Deadline: 1, Partition: 0, Sectors: bf(1, 2)},
+			{Deadline: 1, Partition: 0, Sectors: bf(3)},
+			{Deadline: 2, Partition: 1, Sectors: bf(4)},
+		})
+		require.NoError(t, err)
+		assertBitfieldEquals(t, dm[1][0], 1, 2, 3)
+		assertBitfieldEquals(t, dm[2][1], 4)
+
+		partitions, sectors, err := dm.Count()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(2), partitions)
+		assert.Equal(t, uint64(4), sectors)
+	})
+
+	t.Run("rejects an invalid deadline", func(t *testing.T) {
+		_, err := miner.SelectSectors([]miner.SectorSelection{
+			{Deadline: miner.WPoStPeriodDeadlines, Partition: 0, Sectors: bf(1)},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid deadline")
+	})
+
+	t.Run("rejects too many declarations", func(t *testing.T) {
+		selections := make([]miner.SectorSelection, miner.DeclarationsMax+1)
+		for i := range selections {
+			selections[i] = miner.SectorSelection{Deadline: 0, Partition: uint64(i), Sectors: bf(uint64(i))}
+		}
+		_, err := miner.SelectSectors(selections)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "too many declarations")
+	})
+
+	t.Run("rejects too many sectors", func(t *testing.T) {
+		sectorNos := make([]uint64, miner.AddressedSectorsMax+1)
+		for i := range sectorNos {
+			sectorNos[i] = uint64(i)
+		}
+		_, err := miner.SelectSectors([]miner.SectorSelection{{Deadline: 0, Partition: 0, Sectors: bitfield.NewFromSet(sectorNos)}})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "too many sectors")
+	})
+}
@@ -701,6 +701,19 @@ func CheckMinerInfo(info *MinerInfo, acc *builtin.MessageAccumulator) {
 			"pending owner address %v is same as existing owner %v", info.PendingOwnerAddress, info.Owner)
 	}
 
+	acc.Require(info.Beneficiary.Protocol() == addr.ID, "beneficiary address %v is not an ID address", info.Beneficiary)
+	if info.Beneficiary == info.Owner {
+		acc.Require(info.BeneficiaryTerm.Quota.IsZero() && info.BeneficiaryTerm.Expiration == 0,
+			"owner beneficiary has non-zero term %v", info.BeneficiaryTerm)
+	}
+	acc.Require(info.BeneficiaryTerm.UsedQuota.GreaterThanEqual(big.Zero()),
+		"beneficiary used quota %v is negative", info.BeneficiaryTerm.UsedQuota)
+
+	if info.PendingBeneficiaryTerm != nil {
+		acc.Require(info.PendingBeneficiaryTerm.NewBeneficiary.Protocol() == addr.ID,
+			"pending beneficiary address %v is not an ID address", info.PendingBeneficiaryTerm.NewBeneficiary)
+	}
+
 	windowPoStProofInfo, found := abi.PoStProofInfos[info.WindowPoStProofType]
 	acc.Require(found, "miner has unrecognized Window PoSt proof type %d", info.WindowPoStProofType)
 	if found {
@@ -770,7 +783,7 @@ func CheckPreCommits(st *State, store adt.Store, allocatedSectors map[uint64]boo
 	}
 
 	precommitTotal := big.Zero()
-	if precommitted, err := adt.AsMap(store, st.PreCommittedSectors, builtin.DefaultHamtBitwidth); err != nil {
+	if precommitted, err := adt.AsMap(store, st.PreCommittedSectors, PrecommittedSectorsHamtBitwidth); err != nil {
 		acc.Addf("error loading precommitted sectors: %v", err)
 	} else {
 		var precommit SectorPreCommitOnChainInfo
@@ -44,11 +44,12 @@ func (v *VestingFunds) addLockedFunds(currEpoch abi.ChainEpoch, vestingSum abi.T
 	}
 
 	// Quantization is aligned with when regular cron will be invoked, in the last epoch of deadlines.
+	quant := NewQuantSpec(spec.Quantization, provingPeriodStart)
 	vestBegin := currEpoch + spec.InitialDelay // Nothing unlocks here, this is just the start of the clock.
 	vestPeriod := big.NewInt(int64(spec.VestPeriod))
 	vestedSoFar := big.Zero()
 	for e := vestBegin + spec.StepDuration; vestedSoFar.LessThan(vestingSum); e += spec.StepDuration {
-		vestEpoch := quantizeUp(e, spec.Quantization, provingPeriodStart)
+		vestEpoch := quant.QuantizeUp(e)
 		elapsed := vestEpoch - vestBegin
 
 		targetVest := big.Zero() //nolint:ineffassign
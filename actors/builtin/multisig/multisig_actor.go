@@ -60,6 +60,7 @@ func (a Actor) Exports() []interface{} {
 		7:                         a.SwapSigner,
 		8:                         a.ChangeNumApprovalsThreshold,
 		9:                         a.LockBalance,
+		10:                        a.UniversalReceiverHook,
 	}
 }
 
@@ -166,9 +167,8 @@ func (a Actor) Propose(rt runtime.Runtime, params *ProposeParams) *ProposeReturn
 	}
 
 	var txnID TxnID
-	var st State
 	var txn *Transaction
-	rt.StateTransaction(&st, func() {
+	WithState(rt, func(st *State) {
 		if !st.IsSigner(proposer) {
 			rt.Abortf(exitcode.ErrForbidden, "%s is not a signer", proposer)
 		}
@@ -260,8 +260,7 @@ func (a Actor) Cancel(rt runtime.Runtime, params *TxnIDParams) *abi.EmptyValue {
 	rt.ValidateImmediateCallerType(builtin.CallerTypesSignable...)
 	callerAddr := rt.Caller()
 
-	var st State
-	rt.StateTransaction(&st, func() {
+	WithState(rt, func(st *State) {
 		callerIsSigner := st.IsSigner(callerAddr)
 		if !callerIsSigner {
 			rt.Abortf(exitcode.ErrForbidden, "%s is not a signer", callerAddr)
@@ -307,8 +306,7 @@ func (a Actor) AddSigner(rt runtime.Runtime, params *AddSignerParams) *abi.Empty
 	resolvedNewSigner, err := builtin.ResolveToIDAddr(rt, params.Signer)
 	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to resolve address %v", params.Signer)
 
-	var st State
-	rt.StateTransaction(&st, func() {
+	WithState(rt, func(st *State) {
 		if len(st.Signers) >= SignersMax {
 			rt.Abortf(exitcode.ErrForbidden, "cannot add more than %d signers", SignersMax)
 		}
@@ -338,8 +336,7 @@ func (a Actor) RemoveSigner(rt runtime.Runtime, params *RemoveSignerParams) *abi
 	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to resolve address %v", params.Signer)
 
 	store := adt.AsStore(rt)
-	var st State
-	rt.StateTransaction(&st, func() {
+	WithState(rt, func(st *State) {
 		if !st.IsSigner(resolvedOldSigner) {
 			rt.Abortf(exitcode.ErrForbidden, "%s is not a signer", resolvedOldSigner)
 		}
@@ -396,8 +393,7 @@ func (a Actor) SwapSigner(rt runtime.Runtime, params *SwapSignerParams) *abi.Emp
 	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to resolve to address %v", params.To)
 
 	store := adt.AsStore(rt)
-	var st State
-	rt.StateTransaction(&st, func() {
+	WithState(rt, func(st *State) {
 		if !st.IsSigner(fromResolved) {
 			rt.Abortf(exitcode.ErrForbidden, "from addr %s is not a signer", fromResolved)
 		}
@@ -431,8 +427,7 @@ func (a Actor) ChangeNumApprovalsThreshold(rt runtime.Runtime, params *ChangeNum
 	// Can only be called by the multisig wallet itself.
 	rt.ValidateImmediateCallerIs(rt.Receiver())
 
-	var st State
-	rt.StateTransaction(&st, func() {
+	WithState(rt, func(st *State) {
 		if params.NewThreshold == 0 || params.NewThreshold > uint64(len(st.Signers)) {
 			rt.Abortf(exitcode.ErrIllegalArgument, "New threshold value not supported")
 		}
@@ -462,8 +457,7 @@ func (a Actor) LockBalance(rt runtime.Runtime, params *LockBalanceParams) *abi.E
 		rt.Abortf(exitcode.ErrIllegalArgument, "amount to lock must be positive")
 	}
 
-	var st State
-	rt.StateTransaction(&st, func() {
+	WithState(rt, func(st *State) {
 		if st.UnlockDuration != 0 {
 			rt.Abortf(exitcode.ErrForbidden, "modification of unlock disallowed")
 		}
@@ -472,6 +466,13 @@ func (a Actor) LockBalance(rt runtime.Runtime, params *LockBalanceParams) *abi.E
 	return nil
 }
 
+// Accepts a notification of a typed transfer from any caller, along with any value sent with it.
+// The wallet accepts every transfer type, like a plain send; signers may act on the transfer through proposals.
+func (a Actor) UniversalReceiverHook(rt runtime.Runtime, _ *builtin.UniversalReceiverParams) *abi.EmptyValue {
+	rt.ValidateImmediateCallerAcceptAny()
+	return nil
+}
+
 func (a Actor) approveTransaction(rt runtime.Runtime, txnID TxnID, txn *Transaction) (bool, []byte, exitcode.ExitCode) {
 	caller := rt.Caller()
 
@@ -8,6 +8,7 @@ import (
 	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v3/actors/runtime"
 	"github.com/filecoin-project/specs-actors/v3/actors/util/adt"
 )
 
@@ -24,6 +25,21 @@ type State struct {
 	PendingTxns cid.Cid // HAMT[TxnID]Transaction
 }
 
+// Loads the multisig state for mutation by f, committing it when f returns.
+func WithState(rt runtime.Runtime, f func(st *State)) {
+	var st State
+	rt.StateTransaction(&st, func() {
+		f(&st)
+	})
+}
+
+// Loads a read-only copy of the multisig state.
+func ReadState(rt runtime.Runtime) *State {
+	var st State
+	rt.StateReadonly(&st)
+	return &st
+}
+
 // Tests whether an address is in the list of signers.
 func (st *State) IsSigner(address address.Address) bool {
 	for _, signer := range st.Signers {
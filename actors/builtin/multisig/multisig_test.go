@@ -1917,6 +1917,37 @@ func TestLockBalance(t *testing.T) {
 	})
 }
 
+func TestUniversalReceiverHook(t *testing.T) {
+	actor := msActorHarness{multisig.Actor{}, t}
+	receiver := tutil.NewIDAddr(t, 100)
+	anne := tutil.NewIDAddr(t, 101)
+	sender := tutil.NewIDAddr(t, 102)
+
+	rt := mock.NewBuilder(receiver).
+		WithCaller(builtin.InitActorAddr, builtin.InitActorCodeID).
+		WithHasher(blake2b.Sum256).
+		Build(t)
+	actor.constructAndVerify(rt, 1, 0, 0, anne)
+
+	// A non-signer may notify the wallet of a transfer, and the attached value is accepted into its balance.
+	value := abi.NewTokenAmount(100)
+	rt.SetCaller(sender, builtin.StorageMarketActorCodeID)
+	rt.SetReceived(value)
+	rt.SetBalance(value)
+	rt.ExpectValidateCallerAny()
+	rt.Call(actor.a.UniversalReceiverHook, &builtin.UniversalReceiverParams{
+		Type:    1,
+		Payload: []byte("payload"),
+	})
+	rt.Verify()
+
+	// The received value can be spent by the signers.
+	rt.SetCaller(anne, builtin.AccountActorCodeID)
+	rt.ExpectSend(sender, builtin.MethodSend, nil, value, nil, exitcode.Ok)
+	actor.proposeOK(rt, sender, value, builtin.MethodSend, nil, nil)
+	actor.checkState(rt)
+}
+
 //
 // Helper methods for calling multisig actor methods
 //
@@ -0,0 +1,93 @@
+package builtin
+
+import (
+	"bytes"
+	"reflect"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/cbor"
+	"github.com/filecoin-project/go-state-types/exitcode"
+)
+
+// Limits on the parameters of an exported actor method, checked by ValidateParams before the method is invoked.
+type ParamLimits struct {
+	// Maximum size in bytes of the CBOR-encoded parameters, or zero for no limit.
+	MaxEncodedSize int
+	// Maximum lengths of slice, byte string or string fields of the parameters struct.
+	MaxLengths []LengthLimit
+}
+
+// Limit on the length of a field of a method's parameters struct, identified by name.
+type LengthLimit struct {
+	Field string
+	Max   int
+}
+
+// Implemented by actors which declare limits on the parameters of their exported methods,
+// keyed by method number.
+type ParamLimiter interface {
+	ParamLimits() map[abi.MethodNum]ParamLimits
+}
+
+var typeOfEmptyValue = reflect.TypeOf(abi.Empty)
+
+// Checks the parameters of an invocation of an actor method before the method is dispatched.
+// Parameters must not be a nil pointer unless the method takes no parameters (*abi.EmptyValue),
+// failing with ErrIllegalArgument as methods checking for nil parameters themselves do.
+// If the actor is a ParamLimiter, parameters must also satisfy the limits it declares for the method.
+// The returned error carries the exit code with which the invocation should abort.
+// Methods remain responsible for any checks which a runtime without this layer relies on.
+func ValidateParams(actor interface{}, method abi.MethodNum, params interface{}) error {
+	v := reflect.ValueOf(params)
+	if !v.IsValid() || (v.Kind() == reflect.Ptr && v.IsNil()) {
+		if v.IsValid() && v.Type() == typeOfEmptyValue {
+			return nil
+		}
+		return exitcode.ErrIllegalArgument.Wrapf("method %d parameters are nil", method)
+	}
+
+	limiter, ok := actor.(ParamLimiter)
+	if !ok {
+		return nil
+	}
+	limits, ok := limiter.ParamLimits()[method]
+	if !ok {
+		return nil
+	}
+
+	if limits.MaxEncodedSize > 0 {
+		m, ok := params.(cbor.Marshaler)
+		if !ok {
+			return exitcode.ErrSerialization.Wrapf("method %d parameters %T are not CBOR-marshalable", method, params)
+		}
+		var buf bytes.Buffer
+		if err := m.MarshalCBOR(&buf); err != nil {
+			return exitcode.ErrSerialization.Wrapf("failed to marshal method %d parameters: %w", method, err)
+		}
+		if buf.Len() > limits.MaxEncodedSize {
+			return exitcode.ErrIllegalArgument.Wrapf("method %d parameters size %d exceeds maximum %d",
+				method, buf.Len(), limits.MaxEncodedSize)
+		}
+	}
+
+	if len(limits.MaxLengths) > 0 {
+		s := reflect.Indirect(v)
+		if s.Kind() != reflect.Struct {
+			return exitcode.SysErrorIllegalActor.Wrapf("method %d declares field limits on non-struct parameters %T", method, params)
+		}
+		for _, l := range limits.MaxLengths {
+			f := s.FieldByName(l.Field)
+			switch f.Kind() {
+			case reflect.Slice, reflect.String:
+			default:
+				return exitcode.SysErrorIllegalActor.Wrapf("method %d declares length limit on field %s of %T without length",
+					method, l.Field, params)
+			}
+			if f.Len() > l.Max {
+				return exitcode.ErrIllegalArgument.Wrapf("method %d parameter %s length %d exceeds maximum %d",
+					method, l.Field, f.Len(), l.Max)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package builtin_test
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+)
+
+type limitedActor struct{}
+
+func (limitedActor) ParamLimits() map[abi.MethodNum]builtin.ParamLimits {
+	return map[abi.MethodNum]builtin.ParamLimits{
+		2: {MaxEncodedSize: 8},
+		3: {MaxLengths: []builtin.LengthLimit{{Field: "Sectors", Max: 2}}},
+		4: {MaxLengths: []builtin.LengthLimit{{Field: "Missing", Max: 2}}},
+	}
+}
+
+func TestValidateParams(t *testing.T) {
+	code := func(err error) exitcode.ExitCode {
+		return exitcode.Unwrap(err, exitcode.Ok)
+	}
+	few := &builtin.ConfirmSectorProofsParams{Sectors: []abi.SectorNumber{1, 2}}
+	many := &builtin.ConfirmSectorProofsParams{Sectors: []abi.SectorNumber{1000, 1001, 1002}}
+
+	t.Run("nil parameters", func(t *testing.T) {
+		err := builtin.ValidateParams(limitedActor{}, 5, (*builtin.ConfirmSectorProofsParams)(nil))
+		assert.Equal(t, exitcode.ErrIllegalArgument, code(err))
+
+		// Methods without parameters take a nil empty value.
+		assert.NoError(t, builtin.ValidateParams(limitedActor{}, 5, (*abi.EmptyValue)(nil)))
+		assert.NoError(t, builtin.ValidateParams(limitedActor{}, 5, abi.Empty))
+	})
+
+	t.Run("undeclared methods and actors are unlimited", func(t *testing.T) {
+		assert.NoError(t, builtin.ValidateParams(limitedActor{}, 5, many))
+		assert.NoError(t, builtin.ValidateParams(struct{}{}, 2, many))
+	})
+
+	t.Run("encoded size", func(t *testing.T) {
+		assert.NoError(t, builtin.ValidateParams(limitedActor{}, 2, few))
+		err := builtin.ValidateParams(limitedActor{}, 2, many)
+		assert.Equal(t, exitcode.ErrIllegalArgument, code(err))
+		assert.Contains(t, err.Error(), "size")
+	})
+
+	t.Run("field length", func(t *testing.T) {
+		assert.NoError(t, builtin.ValidateParams(limitedActor{}, 3, few))
+		err := builtin.ValidateParams(limitedActor{}, 3, many)
+		assert.Equal(t, exitcode.ErrIllegalArgument, code(err))
+		assert.Contains(t, err.Error(), "Sectors length 3 exceeds maximum 2")
+	})
+
+	t.Run("limit on unknown field", func(t *testing.T) {
+		err := builtin.ValidateParams(limitedActor{}, 4, few)
+		assert.Equal(t, exitcode.SysErrorIllegalActor, code(err))
+	})
+}
@@ -13,7 +13,7 @@ import (
 
 var _ = xerrors.Errorf
 
-var lengthBufState = []byte{134}
+var lengthBufState = []byte{136}
 
 func (t *State) MarshalCBOR(w io.Writer) error {
 	if t == nil {
@@ -63,12 +63,36 @@ func (t *State) MarshalCBOR(w io.Writer) error {
 		}
 	}
 
+	// t.SettleDelay (abi.ChainEpoch) (int64)
+	if t.SettleDelay >= 0 {
+		if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajUnsignedInt, uint64(t.SettleDelay)); err != nil {
+			return err
+		}
+	} else {
+		if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajNegativeInt, uint64(-t.SettleDelay-1)); err != nil {
+			return err
+		}
+	}
+
 	// t.LaneStates (cid.Cid) (struct)
 
 	if err := cbg.WriteCidBuf(scratch, w, t.LaneStates); err != nil {
 		return xerrors.Errorf("failed to write cid field t.LaneStates: %w", err)
 	}
 
+	// t.Settlers ([]paych.Settler) (slice)
+	if len(t.Settlers) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.Settlers was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajArray, uint64(len(t.Settlers))); err != nil {
+		return err
+	}
+	for _, v := range t.Settlers {
+		if err := v.MarshalCBOR(w); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -86,7 +110,7 @@ func (t *State) UnmarshalCBOR(r io.Reader) error {
 		return fmt.Errorf("cbor input should be of type array")
 	}
 
-	if extra != 6 {
+	if extra != 8 {
 		return fmt.Errorf("cbor input had wrong number of fields")
 	}
 
@@ -167,6 +191,31 @@ func (t *State) UnmarshalCBOR(r io.Reader) error {
 
 		t.MinSettleHeight = abi.ChainEpoch(extraI)
 	}
+	// t.SettleDelay (abi.ChainEpoch) (int64)
+	{
+		maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+		var extraI int64
+		if err != nil {
+			return err
+		}
+		switch maj {
+		case cbg.MajUnsignedInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 positive overflow")
+			}
+		case cbg.MajNegativeInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 negative oveflow")
+			}
+			extraI = -1 - extraI
+		default:
+			return fmt.Errorf("wrong type for int64 field: %d", maj)
+		}
+
+		t.SettleDelay = abi.ChainEpoch(extraI)
+	}
 	// t.LaneStates (cid.Cid) (struct)
 
 	{
@@ -179,6 +228,35 @@ func (t *State) UnmarshalCBOR(r io.Reader) error {
 		t.LaneStates = c
 
 	}
+	// t.Settlers ([]paych.Settler) (slice)
+
+	maj, extra, err = cbg.CborReadHeaderBuf(br, scratch)
+	if err != nil {
+		return err
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("t.Settlers: array too large (%d)", extra)
+	}
+
+	if maj != cbg.MajArray {
+		return fmt.Errorf("expected cbor array")
+	}
+
+	if extra > 0 {
+		t.Settlers = make([]Settler, extra)
+	}
+
+	for i := 0; i < int(extra); i++ {
+
+		var v Settler
+		if err := v.UnmarshalCBOR(br); err != nil {
+			return err
+		}
+
+		t.Settlers[i] = v
+	}
+
 	return nil
 }
 
@@ -252,3 +330,165 @@ func (t *LaneState) UnmarshalCBOR(r io.Reader) error {
 	}
 	return nil
 }
+
+var lengthBufSettler = []byte{130}
+
+func (t *Settler) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write(lengthBufSettler); err != nil {
+		return err
+	}
+
+	// t.Address (address.Address) (struct)
+	if err := t.Address.MarshalCBOR(w); err != nil {
+		return err
+	}
+
+	// t.Party (address.Address) (struct)
+	if err := t.Party.MarshalCBOR(w); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *Settler) UnmarshalCBOR(r io.Reader) error {
+	*t = Settler{}
+
+	br := cbg.GetPeeker(r)
+	scratch := make([]byte, 8)
+
+	maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 2 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.Address (address.Address) (struct)
+
+	{
+
+		if err := t.Address.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.Address: %w", err)
+		}
+
+	}
+	// t.Party (address.Address) (struct)
+
+	{
+
+		if err := t.Party.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.Party: %w", err)
+		}
+
+	}
+	return nil
+}
+
+var lengthBufConstructorParams = []byte{131}
+
+func (t *ConstructorParams) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write(lengthBufConstructorParams); err != nil {
+		return err
+	}
+
+	scratch := make([]byte, 9)
+
+	// t.From (address.Address) (struct)
+	if err := t.From.MarshalCBOR(w); err != nil {
+		return err
+	}
+
+	// t.To (address.Address) (struct)
+	if err := t.To.MarshalCBOR(w); err != nil {
+		return err
+	}
+
+	// t.SettleDelay (abi.ChainEpoch) (int64)
+	if t.SettleDelay >= 0 {
+		if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajUnsignedInt, uint64(t.SettleDelay)); err != nil {
+			return err
+		}
+	} else {
+		if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajNegativeInt, uint64(-t.SettleDelay-1)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *ConstructorParams) UnmarshalCBOR(r io.Reader) error {
+	*t = ConstructorParams{}
+
+	br := cbg.GetPeeker(r)
+	scratch := make([]byte, 8)
+
+	maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 3 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.From (address.Address) (struct)
+
+	{
+
+		if err := t.From.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.From: %w", err)
+		}
+
+	}
+	// t.To (address.Address) (struct)
+
+	{
+
+		if err := t.To.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.To: %w", err)
+		}
+
+	}
+	// t.SettleDelay (abi.ChainEpoch) (int64)
+	{
+		maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+		var extraI int64
+		if err != nil {
+			return err
+		}
+		switch maj {
+		case cbg.MajUnsignedInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 positive overflow")
+			}
+		case cbg.MajNegativeInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 negative oveflow")
+			}
+			extraI = -1 - extraI
+		default:
+			return fmt.Errorf("wrong type for int64 field: %d", maj)
+		}
+
+		t.SettleDelay = abi.ChainEpoch(extraI)
+	}
+	return nil
+}
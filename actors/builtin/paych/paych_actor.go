@@ -19,7 +19,7 @@ import (
 )
 
 const (
-	ErrChannelStateUpdateAfterSettled = exitcode.FirstActorSpecificExitCode + iota
+	ErrChannelStateUpdateAfterSettled = builtin.FirstPaychExitCode + iota
 )
 
 type Actor struct{}
@@ -30,6 +30,8 @@ func (a Actor) Exports() []interface{} {
 		2:                         a.UpdateChannelState,
 		3:                         a.Settle,
 		4:                         a.Collect,
+		5:                         a.AuthorizeSettler,
+		6:                         a.RevokeSettler,
 	}
 }
 
@@ -43,11 +45,13 @@ func (a Actor) State() cbor.Er {
 
 var _ runtime.VMActor = Actor{}
 
-//type ConstructorParams struct {
-//	From addr.Address // Payer
-//	To   addr.Address // Payee
-//}
-type ConstructorParams = paych0.ConstructorParams
+type ConstructorParams struct {
+	From addr.Address // Payer
+	To   addr.Address // Payee
+	// Epochs between settlement and collection of the channel, within [MinSettleDelay, MaxSettleDelay].
+	// Zero selects the default SettleDelay.
+	SettleDelay abi.ChainEpoch
+}
 
 // Constructor creates a payment channel actor. See State for meaning of params.
 func (pca *Actor) Constructor(rt runtime.Runtime, params *ConstructorParams) *abi.EmptyValue {
@@ -61,12 +65,19 @@ func (pca *Actor) Constructor(rt runtime.Runtime, params *ConstructorParams) *ab
 	from, err := pca.resolveAccount(rt, params.From)
 	builtin.RequireNoErr(rt, err, exitcode.Unwrap(err, exitcode.ErrIllegalState), "failed to resolve from address: %s", params.From)
 
+	settleDelay := params.SettleDelay
+	if settleDelay == 0 {
+		settleDelay = SettleDelay
+	}
+	builtin.RequireParam(rt, settleDelay >= MinSettleDelay && settleDelay <= MaxSettleDelay,
+		"settle delay %d out of range [%d, %d]", settleDelay, MinSettleDelay, MaxSettleDelay)
+
 	emptyArr, err := adt.MakeEmptyArray(adt.AsStore(rt), LaneStatesAmtBitwidth)
 	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to create empty array")
 	emptyArrCid, err := emptyArr.Root()
 	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to persist empty array")
 
-	st := ConstructState(from, to, emptyArrCid)
+	st := ConstructState(from, to, settleDelay, emptyArrCid)
 	rt.StateCreate(st)
 
 	return nil
@@ -151,13 +162,13 @@ type ModVerifyParams = paych0.ModVerifyParams
 type Merge = paych0.Merge
 
 func (pca Actor) UpdateChannelState(rt runtime.Runtime, params *UpdateChannelStateParams) *abi.EmptyValue {
-	var st State
-	rt.StateReadonly(&st)
+	st := ReadState(rt)
 
-	// both parties must sign voucher: one who submits it, the other explicitly signs it
-	rt.ValidateImmediateCallerIs(st.From, st.To)
+	// both parties must sign voucher: one who submits it (or a settler on its behalf), the other explicitly signs it
+	rt.ValidateImmediateCallerIs(st.PartiesAndSettlers()...)
+	party, _ := st.PartyFor(rt.Caller())
 	var signer addr.Address
-	if rt.Caller() == st.From {
+	if party == st.From {
 		signer = st.To
 	} else {
 		signer = st.From
@@ -222,7 +233,7 @@ func (pca Actor) UpdateChannelState(rt runtime.Runtime, params *UpdateChannelSta
 		builtin.RequireSuccess(rt, code, "spend voucher verification failed")
 	}
 
-	rt.StateTransaction(&st, func() {
+	WithState(rt, func(st *State) {
 		laneFound := true
 
 		lstates, err := adt.AsArray(adt.AsStore(rt), st.LaneStates, LaneStatesAmtBitwidth)
@@ -311,15 +322,14 @@ func (pca Actor) UpdateChannelState(rt runtime.Runtime, params *UpdateChannelSta
 }
 
 func (pca Actor) Settle(rt runtime.Runtime, _ *abi.EmptyValue) *abi.EmptyValue {
-	var st State
-	rt.StateTransaction(&st, func() {
-		rt.ValidateImmediateCallerIs(st.From, st.To)
+	WithState(rt, func(st *State) {
+		rt.ValidateImmediateCallerIs(st.PartiesAndSettlers()...)
 
 		if st.SettlingAt != 0 {
 			rt.Abortf(exitcode.ErrIllegalState, "channel already settling")
 		}
 
-		st.SettlingAt = rt.CurrEpoch() + SettleDelay
+		st.SettlingAt = rt.CurrEpoch() + st.SettleDelay
 		if st.SettlingAt < st.MinSettleHeight {
 			st.SettlingAt = st.MinSettleHeight
 		}
@@ -328,8 +338,7 @@ func (pca Actor) Settle(rt runtime.Runtime, _ *abi.EmptyValue) *abi.EmptyValue {
 }
 
 func (pca Actor) Collect(rt runtime.Runtime, _ *abi.EmptyValue) *abi.EmptyValue {
-	var st State
-	rt.StateReadonly(&st)
+	st := ReadState(rt)
 	rt.ValidateImmediateCallerIs(st.From, st.To)
 
 	if st.SettlingAt == 0 || rt.CurrEpoch() < st.SettlingAt {
@@ -346,12 +355,72 @@ func (pca Actor) Collect(rt runtime.Runtime, _ *abi.EmptyValue) *abi.EmptyValue
 	)
 	builtin.RequireSuccess(rt, codeTo, "Failed to send funds to `To`")
 
+	// leave a tombstone so that the channel's addresses resolve as deleted rather than unknown.
+	code := rt.Send(
+		builtin.InitActorAddr,
+		builtin.MethodsInit.RecordTombstone,
+		nil,
+		big.Zero(),
+		&builtin.Discard{},
+	)
+	builtin.RequireSuccess(rt, code, "failed to record tombstone")
+
 	// the remaining balance will be returned to "From" upon deletion.
 	rt.DeleteActor(st.From)
 
 	return nil
 }
 
+// Authorizes a third party, such as a watchtower, to submit vouchers and settle the channel on behalf of the caller.
+// This permits a party that goes offline to have newer vouchers submitted before the channel settles.
+// A settler may act for only one party, and may not collect the channel.
+func (pca Actor) AuthorizeSettler(rt runtime.Runtime, settler *addr.Address) *abi.EmptyValue {
+	st := ReadState(rt)
+	rt.ValidateImmediateCallerIs(st.From, st.To)
+	party := rt.Caller()
+	settlerID, err := builtin.ResolveToIDAddr(rt, *settler)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "failed to resolve settler address %v", *settler)
+
+	WithState(rt, func(st *State) {
+		if existing, ok := st.PartyFor(settlerID); ok {
+			if existing == settlerID {
+				rt.Abortf(exitcode.ErrIllegalArgument, "channel party %v cannot be a settler", settlerID)
+			}
+			rt.Abortf(exitcode.ErrIllegalArgument, "settler %v already authorized by %v", settlerID, existing)
+		}
+		if len(st.Settlers) >= MaxSettlers {
+			rt.Abortf(exitcode.ErrForbidden, "channel already has the maximum of %d settlers", MaxSettlers)
+		}
+		st.Settlers = append(st.Settlers, Settler{Address: settlerID, Party: party})
+	})
+	return nil
+}
+
+// Revokes the authorization of a settler previously authorized by the caller.
+func (pca Actor) RevokeSettler(rt runtime.Runtime, settler *addr.Address) *abi.EmptyValue {
+	st := ReadState(rt)
+	rt.ValidateImmediateCallerIs(st.From, st.To)
+	settlerID, found := rt.ResolveAddress(*settler)
+	if !found {
+		rt.Abortf(exitcode.ErrNotFound, "settler %v not authorized", *settler)
+	}
+
+	WithState(rt, func(st *State) {
+		for i, s := range st.Settlers {
+			if s.Address != settlerID {
+				continue
+			}
+			if s.Party != rt.Caller() {
+				rt.Abortf(exitcode.ErrForbidden, "settler %v was authorized by %v", settlerID, s.Party)
+			}
+			st.Settlers = append(st.Settlers[:i], st.Settlers[i+1:]...)
+			return
+		}
+		rt.Abortf(exitcode.ErrNotFound, "settler %v not authorized", settlerID)
+	})
+	return nil
+}
+
 // Returns the insertion index for a lane ID, with the matching lane state if found, or nil.
 func findLane(rt runtime.Runtime, ls *adt.Array, id uint64) *LaneState {
 	if id > MaxLane {
@@ -5,6 +5,8 @@ import (
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/big"
 	"github.com/ipfs/go-cid"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/runtime"
 )
 
 // A given payment channel actor is established by From
@@ -23,9 +25,20 @@ type State struct {
 	SettlingAt abi.ChainEpoch
 	// Height before which the channel `ToSend` cannot be collected
 	MinSettleHeight abi.ChainEpoch
+	// Epochs between `Settle()` and the channel becoming collectable
+	SettleDelay abi.ChainEpoch
 
 	// Collections of lane states for the channel, maintained in ID order.
 	LaneStates cid.Cid // AMT<LaneState>
+
+	// Third parties (e.g. watchtowers) authorized to update and settle the channel on behalf of a party.
+	Settlers []Settler
+}
+
+// A settler may submit vouchers and settle the channel as if it were the party that authorized it.
+type Settler struct {
+	Address addr.Address // ID address of the settler
+	Party   addr.Address // The party on whose behalf the settler acts, either From or To
 }
 
 // The Lane state tracks the latest (highest) voucher nonce used to merge the lane
@@ -37,13 +50,51 @@ type LaneState struct {
 
 const LaneStatesAmtBitwidth = 3
 
-func ConstructState(from addr.Address, to addr.Address, emptyArrCid cid.Cid) *State {
+func ConstructState(from addr.Address, to addr.Address, settleDelay abi.ChainEpoch, emptyArrCid cid.Cid) *State {
 	return &State{
 		From:            from,
 		To:              to,
 		ToSend:          big.Zero(),
 		SettlingAt:      0,
 		MinSettleHeight: 0,
+		SettleDelay:     settleDelay,
 		LaneStates:      emptyArrCid,
 	}
 }
+
+// Returns the party on whose behalf an address may act, which is the address itself if it is a party.
+func (st *State) PartyFor(a addr.Address) (addr.Address, bool) {
+	if a == st.From || a == st.To {
+		return a, true
+	}
+	for _, s := range st.Settlers {
+		if s.Address == a {
+			return s.Party, true
+		}
+	}
+	return addr.Undef, false
+}
+
+// Returns the addresses permitted to act for the channel parties, including the parties themselves.
+func (st *State) PartiesAndSettlers() []addr.Address {
+	addrs := []addr.Address{st.From, st.To}
+	for _, s := range st.Settlers {
+		addrs = append(addrs, s.Address)
+	}
+	return addrs
+}
+
+// Loads the channel state for mutation by f, committing it when f returns.
+func WithState(rt runtime.Runtime, f func(st *State)) {
+	var st State
+	rt.StateTransaction(&st, func() {
+		f(&st)
+	})
+}
+
+// Loads a read-only copy of the channel state.
+func ReadState(rt runtime.Runtime) *State {
+	var st State
+	rt.StateReadonly(&st)
+	return &st
+}
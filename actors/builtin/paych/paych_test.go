@@ -145,6 +145,36 @@ func TestPaymentChannelActor_Constructor(t *testing.T) {
 			rt.Call(actor.Constructor, &ConstructorParams{To: paychAddr})
 		})
 	})
+
+	t.Run("constructs with custom settle delay", func(t *testing.T) {
+		rt := mock.NewBuilder(paychAddr).
+			WithCaller(callerAddr, builtin.InitActorCodeID).
+			WithActorType(payerAddr, builtin.AccountActorCodeID).
+			WithActorType(payeeAddr, builtin.AccountActorCodeID).
+			Build(t)
+		rt.ExpectValidateCallerType(builtin.InitActorCodeID)
+		rt.Call(actor.Constructor, &ConstructorParams{From: payerAddr, To: payeeAddr, SettleDelay: MaxSettleDelay})
+		rt.Verify()
+
+		var st State
+		rt.GetState(&st)
+		assert.EqualValues(t, MaxSettleDelay, st.SettleDelay)
+		actor.checkState(rt)
+	})
+
+	for _, delay := range []abi.ChainEpoch{-1, MinSettleDelay - 1, MaxSettleDelay + 1} {
+		t.Run(fmt.Sprintf("fails with settle delay %d out of range", delay), func(t *testing.T) {
+			rt := mock.NewBuilder(paychAddr).
+				WithCaller(callerAddr, builtin.InitActorCodeID).
+				WithActorType(payerAddr, builtin.AccountActorCodeID).
+				WithActorType(payeeAddr, builtin.AccountActorCodeID).
+				Build(t)
+			rt.ExpectValidateCallerType(builtin.InitActorCodeID)
+			rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+				rt.Call(actor.Constructor, &ConstructorParams{From: payerAddr, To: payeeAddr, SettleDelay: delay})
+			})
+		})
+	}
 }
 
 func TestPaymentChannelActor_CreateLane(t *testing.T) {
@@ -356,6 +386,7 @@ func TestActor_UpdateChannelStateRedeem(t *testing.T) {
 			ToSend:          newVoucherAmt,
 			SettlingAt:      st1.SettlingAt,
 			MinSettleHeight: st1.MinSettleHeight,
+			SettleDelay:     st1.SettleDelay,
 			LaneStates:      constructLaneStateAMT(t, rt, []*LaneState{&expLs}),
 		}
 		verifyState(t, rt, 1, expState)
@@ -724,6 +755,25 @@ func TestActor_Settle(t *testing.T) {
 		actor.checkState(rt)
 	})
 
+	t.Run("Settle uses the channel's settle delay", func(t *testing.T) {
+		rt, actor, _ := requireCreateChannelWithLanes(t, 1)
+		rt.SetEpoch(ep)
+		var st State
+		rt.GetState(&st)
+
+		// Extend the delay beyond the default, as if the channel had been constructed with it.
+		delay := SettleDelay + abi.ChainEpoch(builtin.EpochsInDay)
+		rt.ReplaceState(&State{From: st.From, To: st.To, ToSend: st.ToSend, SettleDelay: delay, LaneStates: st.LaneStates})
+
+		rt.SetCaller(st.From, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerAddr(st.From, st.To)
+		rt.Call(actor.Settle, nil)
+
+		rt.GetState(&st)
+		assert.Equal(t, ep+delay, st.SettlingAt)
+		actor.checkState(rt)
+	})
+
 	t.Run("settle fails if called twice: channel already settling", func(t *testing.T) {
 		rt, actor, _ := requireCreateChannelWithLanes(t, 1)
 		rt.SetEpoch(ep)
@@ -814,6 +864,7 @@ func TestActor_Collect(t *testing.T) {
 		rt.SetEpoch(st.SettlingAt + 1)
 
 		rt.ExpectSend(st.To, builtin.MethodSend, nil, st.ToSend, nil, exitcode.Ok)
+		rt.ExpectSend(builtin.InitActorAddr, builtin.MethodsInit.RecordTombstone, nil, big.Zero(), nil, exitcode.Ok)
 
 		// Collect.
 		rt.SetCaller(st.From, builtin.AccountActorCodeID)
@@ -863,6 +914,118 @@ func TestActor_Collect(t *testing.T) {
 	}
 }
 
+func TestActor_Settlers(t *testing.T) {
+	watchtower := tutil.NewIDAddr(t, 200)
+
+	t.Run("settler submits vouchers and settles on behalf of the payee", func(t *testing.T) {
+		rt, actor, sv := requireCreateChannelWithLanes(t, 1)
+		actor.authorizeSettler(rt, actor.payee, watchtower)
+
+		var st State
+		rt.GetState(&st)
+		assert.Equal(t, []Settler{{Address: watchtower, Party: actor.payee}}, st.Settlers)
+		parties := []addr.Address{actor.payer, actor.payee, watchtower}
+
+		// The voucher must be signed by the payer, as if submitted by the payee.
+		ucp := &UpdateChannelStateParams{Sv: *sv}
+		ucp.Sv.Amount = big.NewInt(9)
+		rt.SetCaller(watchtower, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerAddr(parties...)
+		rt.ExpectVerifySignature(*ucp.Sv.Signature, actor.payer, voucherBytes(t, &ucp.Sv), nil)
+		rt.Call(actor.UpdateChannelState, ucp)
+		rt.Verify()
+
+		rt.ExpectValidateCallerAddr(parties...)
+		rt.Call(actor.Settle, nil)
+		rt.Verify()
+
+		rt.GetState(&st)
+		assert.Equal(t, big.NewInt(9), st.ToSend)
+		assert.Equal(t, rt.Epoch()+SettleDelay, st.SettlingAt)
+
+		// A settler cannot collect.
+		rt.SetEpoch(st.SettlingAt)
+		rt.ExpectValidateCallerAddr(st.From, st.To)
+		rt.ExpectAbort(exitcode.SysErrForbidden, func() {
+			rt.Call(actor.Collect, nil)
+		})
+		actor.checkState(rt)
+	})
+
+	t.Run("revoked settler cannot settle", func(t *testing.T) {
+		rt, actor, _ := requireCreateChannelWithLanes(t, 1)
+		actor.authorizeSettler(rt, actor.payer, watchtower)
+
+		rt.SetCaller(actor.payer, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerAddr(actor.payer, actor.payee)
+		rt.Call(actor.RevokeSettler, &watchtower)
+		rt.Verify()
+
+		var st State
+		rt.GetState(&st)
+		assert.Empty(t, st.Settlers)
+
+		rt.SetCaller(watchtower, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerAddr(actor.payer, actor.payee)
+		rt.ExpectAbort(exitcode.SysErrForbidden, func() {
+			rt.Call(actor.Settle, nil)
+		})
+		actor.checkState(rt)
+	})
+
+	t.Run("only the authorizing party may revoke a settler", func(t *testing.T) {
+		rt, actor, _ := requireCreateChannelWithLanes(t, 1)
+		actor.authorizeSettler(rt, actor.payer, watchtower)
+
+		rt.SetCaller(actor.payee, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerAddr(actor.payer, actor.payee)
+		rt.ExpectAbortContainsMessage(exitcode.ErrForbidden, "was authorized by", func() {
+			rt.Call(actor.RevokeSettler, &watchtower)
+		})
+
+		other := tutil.NewIDAddr(t, 201)
+		rt.ExpectValidateCallerAddr(actor.payer, actor.payee)
+		rt.ExpectAbortContainsMessage(exitcode.ErrNotFound, "not authorized", func() {
+			rt.Call(actor.RevokeSettler, &other)
+		})
+		actor.checkState(rt)
+	})
+
+	t.Run("settler acts for only one party", func(t *testing.T) {
+		rt, actor, _ := requireCreateChannelWithLanes(t, 1)
+		actor.authorizeSettler(rt, actor.payer, watchtower)
+
+		for _, caller := range []addr.Address{actor.payer, actor.payee} {
+			rt.SetCaller(caller, builtin.AccountActorCodeID)
+			rt.ExpectValidateCallerAddr(actor.payer, actor.payee)
+			rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "already authorized", func() {
+				rt.Call(actor.AuthorizeSettler, &watchtower)
+			})
+		}
+
+		rt.SetCaller(actor.payer, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerAddr(actor.payer, actor.payee)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "cannot be a settler", func() {
+			rt.Call(actor.AuthorizeSettler, &actor.payee)
+		})
+		actor.checkState(rt)
+	})
+
+	t.Run("settlers are limited", func(t *testing.T) {
+		rt, actor, _ := requireCreateChannelWithLanes(t, 1)
+		for i := 0; i < MaxSettlers; i++ {
+			actor.authorizeSettler(rt, actor.payer, tutil.NewIDAddr(t, uint64(300+i)))
+		}
+
+		rt.SetCaller(actor.payer, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerAddr(actor.payer, actor.payee)
+		rt.ExpectAbortContainsMessage(exitcode.ErrForbidden, "maximum", func() {
+			rt.Call(actor.AuthorizeSettler, &watchtower)
+		})
+		actor.checkState(rt)
+	})
+}
+
 type pcActorHarness struct {
 	Actor
 	t testing.TB
@@ -951,6 +1114,16 @@ func (h *pcActorHarness) constructAndVerify(t *testing.T, rt *mock.Runtime, send
 	verifyInitialState(t, rt, senderId, receiverId)
 }
 
+func (h *pcActorHarness) authorizeSettler(rt *mock.Runtime, party, settler addr.Address) {
+	var st State
+	rt.GetState(&st)
+	rt.SetCaller(party, builtin.AccountActorCodeID)
+	rt.ExpectValidateCallerAddr(st.From, st.To)
+	ret := rt.Call(h.AuthorizeSettler, &settler)
+	assert.Nil(h.t, ret)
+	rt.Verify()
+}
+
 func (h *pcActorHarness) checkState(rt *mock.Runtime) {
 	var st State
 	rt.GetState(&st)
@@ -963,7 +1136,7 @@ func verifyInitialState(t *testing.T, rt *mock.Runtime, sender, receiver addr.Ad
 	rt.GetState(&st)
 	emptyArray, err := adt.StoreEmptyArray(adt.AsStore(rt), LaneStatesAmtBitwidth)
 	require.NoError(t, err)
-	expectedState := State{From: sender, To: receiver, ToSend: abi.NewTokenAmount(0), LaneStates: emptyArray}
+	expectedState := State{From: sender, To: receiver, ToSend: abi.NewTokenAmount(0), SettleDelay: SettleDelay, LaneStates: emptyArray}
 	verifyState(t, rt, -1, expectedState)
 }
 
@@ -974,6 +1147,7 @@ func verifyState(t *testing.T, rt *mock.Runtime, expLanes int, expectedState Sta
 	assert.Equal(t, expectedState.From, st.From)
 	assert.Equal(t, expectedState.MinSettleHeight, st.MinSettleHeight)
 	assert.Equal(t, expectedState.SettlingAt, st.SettlingAt)
+	assert.Equal(t, expectedState.SettleDelay, st.SettleDelay)
 	assert.Equal(t, expectedState.ToSend, st.ToSend)
 	if expLanes >= 0 {
 		assertLaneStatesLength(t, rt, st.LaneStates, expLanes)
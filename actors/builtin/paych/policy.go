@@ -9,7 +9,17 @@ import (
 // Maximum number of lanes in a channel.
 const MaxLane = math.MaxInt64
 
+// Default delay between a channel being settled and the earliest epoch at which it may be collected,
+// during which the payee may still redeem outstanding vouchers.
 const SettleDelay = builtin.EpochsInHour * 12
 
+// Bounds on the settlement delay with which a channel may be constructed.
+// The default is also the minimum, so a channel cannot shorten the payee's window to redeem vouchers.
+const MinSettleDelay = SettleDelay
+const MaxSettleDelay = builtin.EpochsInDay * 30
+
 // Maximum size of a secret that can be submitted with a payment channel update (in bytes).
 const MaxSecretSize = 256
+
+// Maximum number of settlers that may be authorized for a channel, across both parties.
+const MaxSettlers = 10
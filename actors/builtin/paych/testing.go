@@ -21,9 +21,22 @@ func CheckStateInvariants(st *State, store adt.Store, balance abi.TokenAmount) (
 
 	acc.Require(st.From.Protocol() == address.ID, "from address is not ID address %v", st.From)
 	acc.Require(st.To.Protocol() == address.ID, "to address is not ID address %v", st.To)
+	acc.Require(st.SettleDelay >= MinSettleDelay && st.SettleDelay <= MaxSettleDelay,
+		"settle delay %d out of range [%d, %d]", st.SettleDelay, MinSettleDelay, MaxSettleDelay)
 	acc.Require(st.SettlingAt >= st.MinSettleHeight,
 		"channel is setting at epoch %d before min settle height %d", st.SettlingAt, st.MinSettleHeight)
 
+	settlers := map[address.Address]struct{}{}
+	for _, s := range st.Settlers {
+		acc.Require(s.Address.Protocol() == address.ID, "settler address is not ID address %v", s.Address)
+		acc.Require(s.Party == st.From || s.Party == st.To, "settler %v acts for non-party %v", s.Address, s.Party)
+		acc.Require(s.Address != st.From && s.Address != st.To, "settler %v is a channel party", s.Address)
+		_, dup := settlers[s.Address]
+		acc.Require(!dup, "duplicate settler %v", s.Address)
+		settlers[s.Address] = struct{}{}
+	}
+	acc.Require(len(st.Settlers) <= MaxSettlers, "channel has %d settlers, max %d", len(st.Settlers), MaxSettlers)
+
 	if lanes, err := adt.AsArray(store, st.LaneStates, LaneStatesAmtBitwidth); err != nil {
 		acc.Addf("error loading lanes: %v", err)
 	} else {
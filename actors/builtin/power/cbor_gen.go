@@ -8,6 +8,7 @@ import (
 
 	address "github.com/filecoin-project/go-address"
 	abi "github.com/filecoin-project/go-state-types/abi"
+	power "github.com/filecoin-project/specs-actors/actors/builtin/power"
 	cbg "github.com/whyrusleeping/cbor-gen"
 	xerrors "golang.org/x/xerrors"
 )
@@ -832,6 +833,263 @@ func (t *CurrentTotalPowerReturn) UnmarshalCBOR(r io.Reader) error {
 	return nil
 }
 
+var lengthBufNetworkPowerStatsReturn = []byte{134}
+
+func (t *NetworkPowerStatsReturn) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write(lengthBufNetworkPowerStatsReturn); err != nil {
+		return err
+	}
+
+	scratch := make([]byte, 9)
+
+	// t.MinerCount (int64) (int64)
+	if t.MinerCount >= 0 {
+		if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajUnsignedInt, uint64(t.MinerCount)); err != nil {
+			return err
+		}
+	} else {
+		if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajNegativeInt, uint64(-t.MinerCount-1)); err != nil {
+			return err
+		}
+	}
+
+	// t.MinerAboveMinPowerCount (int64) (int64)
+	if t.MinerAboveMinPowerCount >= 0 {
+		if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajUnsignedInt, uint64(t.MinerAboveMinPowerCount)); err != nil {
+			return err
+		}
+	} else {
+		if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajNegativeInt, uint64(-t.MinerAboveMinPowerCount-1)); err != nil {
+			return err
+		}
+	}
+
+	// t.RawBytePowerAboveMin (big.Int) (struct)
+	if err := t.RawBytePowerAboveMin.MarshalCBOR(w); err != nil {
+		return err
+	}
+
+	// t.QualityAdjPowerAboveMin (big.Int) (struct)
+	if err := t.QualityAdjPowerAboveMin.MarshalCBOR(w); err != nil {
+		return err
+	}
+
+	// t.RawBytesCommitted (big.Int) (struct)
+	if err := t.RawBytesCommitted.MarshalCBOR(w); err != nil {
+		return err
+	}
+
+	// t.QABytesCommitted (big.Int) (struct)
+	if err := t.QABytesCommitted.MarshalCBOR(w); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *NetworkPowerStatsReturn) UnmarshalCBOR(r io.Reader) error {
+	*t = NetworkPowerStatsReturn{}
+
+	br := cbg.GetPeeker(r)
+	scratch := make([]byte, 8)
+
+	maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 6 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.MinerCount (int64) (int64)
+	{
+		maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+		var extraI int64
+		if err != nil {
+			return err
+		}
+		switch maj {
+		case cbg.MajUnsignedInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 positive overflow")
+			}
+		case cbg.MajNegativeInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 negative oveflow")
+			}
+			extraI = -1 - extraI
+		default:
+			return fmt.Errorf("wrong type for int64 field: %d", maj)
+		}
+
+		t.MinerCount = int64(extraI)
+	}
+	// t.MinerAboveMinPowerCount (int64) (int64)
+	{
+		maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+		var extraI int64
+		if err != nil {
+			return err
+		}
+		switch maj {
+		case cbg.MajUnsignedInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 positive overflow")
+			}
+		case cbg.MajNegativeInt:
+			extraI = int64(extra)
+			if extraI < 0 {
+				return fmt.Errorf("int64 negative oveflow")
+			}
+			extraI = -1 - extraI
+		default:
+			return fmt.Errorf("wrong type for int64 field: %d", maj)
+		}
+
+		t.MinerAboveMinPowerCount = int64(extraI)
+	}
+	// t.RawBytePowerAboveMin (big.Int) (struct)
+
+	{
+
+		if err := t.RawBytePowerAboveMin.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.RawBytePowerAboveMin: %w", err)
+		}
+
+	}
+	// t.QualityAdjPowerAboveMin (big.Int) (struct)
+
+	{
+
+		if err := t.QualityAdjPowerAboveMin.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.QualityAdjPowerAboveMin: %w", err)
+		}
+
+	}
+	// t.RawBytesCommitted (big.Int) (struct)
+
+	{
+
+		if err := t.RawBytesCommitted.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.RawBytesCommitted: %w", err)
+		}
+
+	}
+	// t.QABytesCommitted (big.Int) (struct)
+
+	{
+
+		if err := t.QABytesCommitted.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.QABytesCommitted: %w", err)
+		}
+
+	}
+	return nil
+}
+
+var lengthBufUpdateClaimedPowerBatchParams = []byte{130}
+
+func (t *UpdateClaimedPowerBatchParams) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write(lengthBufUpdateClaimedPowerBatchParams); err != nil {
+		return err
+	}
+
+	scratch := make([]byte, 9)
+
+	// t.Deltas ([]power.UpdateClaimedPowerParams) (slice)
+	if len(t.Deltas) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.Deltas was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajArray, uint64(len(t.Deltas))); err != nil {
+		return err
+	}
+	for _, v := range t.Deltas {
+		if err := v.MarshalCBOR(w); err != nil {
+			return err
+		}
+	}
+
+	// t.PledgeDelta (big.Int) (struct)
+	if err := t.PledgeDelta.MarshalCBOR(w); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *UpdateClaimedPowerBatchParams) UnmarshalCBOR(r io.Reader) error {
+	*t = UpdateClaimedPowerBatchParams{}
+
+	br := cbg.GetPeeker(r)
+	scratch := make([]byte, 8)
+
+	maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 2 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.Deltas ([]power.UpdateClaimedPowerParams) (slice)
+
+	maj, extra, err = cbg.CborReadHeaderBuf(br, scratch)
+	if err != nil {
+		return err
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("t.Deltas: array too large (%d)", extra)
+	}
+
+	if maj != cbg.MajArray {
+		return fmt.Errorf("expected cbor array")
+	}
+
+	if extra > 0 {
+		t.Deltas = make([]power.UpdateClaimedPowerParams, extra)
+	}
+
+	for i := 0; i < int(extra); i++ {
+
+		var v power.UpdateClaimedPowerParams
+		if err := v.UnmarshalCBOR(br); err != nil {
+			return err
+		}
+
+		t.Deltas[i] = v
+	}
+
+	// t.PledgeDelta (big.Int) (struct)
+
+	{
+
+		if err := t.PledgeDelta.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.PledgeDelta: %w", err)
+		}
+
+	}
+	return nil
+}
+
 var lengthBufMinerConstructorParams = []byte{134}
 
 func (t *MinerConstructorParams) MarshalCBOR(w io.Writer) error {
@@ -0,0 +1,119 @@
+package power
+
+import (
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/ipfs/go-cid"
+	cbg "github.com/whyrusleeping/cbor-gen"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/util/adt"
+)
+
+// Wrapper for working with an AMT[ChainEpoch]AMT[CronEvent] functioning as a queue of miner cron events,
+// bucketed by the epoch at which they are due.
+// Events within an epoch are kept in the order in which they were enrolled.
+type CronQueue struct {
+	*adt.Array
+	store adt.Store
+}
+
+func LoadCronQueue(store adt.Store, root cid.Cid) (CronQueue, error) {
+	arr, err := adt.AsArray(store, root, CronQueueEpochAmtBitwidth)
+	if err != nil {
+		return CronQueue{}, xerrors.Errorf("failed to load cron queue %v: %w", root, err)
+	}
+	return CronQueue{arr, store}, nil
+}
+
+// Appends an event to the queue entry for an epoch.
+func (q CronQueue) AddToQueue(epoch abi.ChainEpoch, event *CronEvent) error {
+	events, err := q.loadEpoch(epoch)
+	if err != nil {
+		return err
+	}
+	if err := events.AppendContinuous(event); err != nil {
+		return xerrors.Errorf("failed to append cron event at epoch %v: %w", epoch, err)
+	}
+	return q.storeEpoch(epoch, events)
+}
+
+// Returns the events queued for an epoch, in enrollment order.
+func (q CronQueue) LoadEpoch(epoch abi.ChainEpoch) ([]CronEvent, error) {
+	events, err := q.loadEpoch(epoch)
+	if err != nil {
+		return nil, err
+	}
+	var out []CronEvent
+	var ev CronEvent
+	if err := events.ForEach(&ev, func(i int64) error {
+		out = append(out, ev)
+		return nil
+	}); err != nil {
+		return nil, xerrors.Errorf("failed to iterate cron events at epoch %v: %w", epoch, err)
+	}
+	return out, nil
+}
+
+// Replaces the events queued for an epoch. An empty list removes the epoch's entry.
+func (q CronQueue) SetEpoch(epoch abi.ChainEpoch, events []CronEvent) error {
+	if len(events) == 0 {
+		if _, err := q.Array.TryDelete(uint64(epoch)); err != nil {
+			return xerrors.Errorf("failed to remove cron events at epoch %v: %w", epoch, err)
+		}
+		return nil
+	}
+	arr, err := adt.MakeEmptyArray(q.store, CronQueueAmtBitwidth)
+	if err != nil {
+		return xerrors.Errorf("failed to create cron events array: %w", err)
+	}
+	for i := range events {
+		if err := arr.AppendContinuous(&events[i]); err != nil {
+			return xerrors.Errorf("failed to append cron event at epoch %v: %w", epoch, err)
+		}
+	}
+	return q.storeEpoch(epoch, arr)
+}
+
+// Iterates the queue in epoch order, passing the events of each non-empty epoch.
+func (q CronQueue) ForEach(cb func(epoch abi.ChainEpoch, events *adt.Array) error) error {
+	var root cbg.CborCid
+	return q.Array.ForEach(&root, func(i int64) error {
+		events, err := adt.AsArray(q.store, cid.Cid(root), CronQueueAmtBitwidth)
+		if err != nil {
+			return xerrors.Errorf("failed to load cron events at epoch %v: %w", i, err)
+		}
+		return cb(abi.ChainEpoch(i), events)
+	})
+}
+
+func (q CronQueue) loadEpoch(epoch abi.ChainEpoch) (*adt.Array, error) {
+	var root cbg.CborCid
+	found, err := q.Array.Get(uint64(epoch), &root)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to lookup cron queue epoch %v: %w", epoch, err)
+	}
+	if !found {
+		events, err := adt.MakeEmptyArray(q.store, CronQueueAmtBitwidth)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to create cron events array: %w", err)
+		}
+		return events, nil
+	}
+	events, err := adt.AsArray(q.store, cid.Cid(root), CronQueueAmtBitwidth)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to load cron events at epoch %v: %w", epoch, err)
+	}
+	return events, nil
+}
+
+func (q CronQueue) storeEpoch(epoch abi.ChainEpoch, events *adt.Array) error {
+	root, err := events.Root()
+	if err != nil {
+		return xerrors.Errorf("failed to flush cron events at epoch %v: %w", epoch, err)
+	}
+	value := cbg.CborCid(root)
+	if err := q.Array.Set(uint64(epoch), &value); err != nil {
+		return xerrors.Errorf("failed to set cron queue epoch %v: %w", epoch, err)
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package power
+
+import (
+	addr "github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+)
+
+// Types of the events emitted by the power actor.
+const (
+	EventClaimUpdated = "claim-updated"
+)
+
+// Emits an event recording a change to a miner's claimed power.
+func emitClaimUpdated(rt Runtime, miner addr.Address, rawDelta, qaDelta abi.StoragePower) {
+	builtin.NewEventBuilder(EventClaimUpdated).
+		FieldIndexed("miner", &miner).
+		Field("raw-delta", &rawDelta).
+		Field("qa-delta", &qaDelta).
+		Emit(rt)
+}
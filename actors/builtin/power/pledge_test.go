@@ -0,0 +1,80 @@
+package power_test
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin/power"
+	"github.com/filecoin-project/specs-actors/v3/actors/util/smoothing"
+)
+
+func TestInitialPledgeForPower(t *testing.T) {
+	sectorPower := abi.NewStoragePower(32 << 30)
+	epochReward := big.Mul(big.NewInt(20), big.NewInt(1e18))
+	circSupply := big.Mul(big.NewInt(1e9), big.NewInt(1e18))
+
+	// Golden values, computed independently from the formulas.
+	testCases := []struct {
+		name         string
+		qaPower      abi.StoragePower
+		baseline     abi.StoragePower
+		rewardEst    smoothing.FilterEstimate
+		networkPower abi.StoragePower
+		pledge       string
+		precommitDep string
+	}{{
+		name:         "network power above baseline",
+		qaPower:      sectorPower,
+		baseline:     big.Lsh(big.NewInt(5), 60),
+		rewardEst:    smoothing.TestingConstantEstimate(epochReward),
+		networkPower: big.Lsh(big.NewInt(10), 60),
+		pledge:       "897502899169921875",
+		precommitDep: "3433227539062500",
+	}, {
+		name:         "baseline above network power",
+		qaPower:      sectorPower,
+		baseline:     big.Lsh(big.NewInt(20), 60),
+		rewardEst:    smoothing.TestingConstantEstimate(epochReward),
+		networkPower: big.Lsh(big.NewInt(10), 60),
+		pledge:       "450468063354492187",
+		precommitDep: "3433227539062500",
+	}, {
+		name:         "growing reward",
+		qaPower:      abi.NewStoragePower(64 << 30),
+		baseline:     big.Lsh(big.NewInt(5), 60),
+		rewardEst:    smoothing.TestingEstimate(epochReward, big.NewInt(1e12)),
+		networkPower: big.Lsh(big.NewInt(10), 60),
+		pledge:       "1795015686035156250",
+		precommitDep: "6876342773437500",
+	}, {
+		name:         "capped per byte",
+		qaPower:      sectorPower,
+		baseline:     abi.NewStoragePower(1 << 40),
+		rewardEst:    smoothing.TestingConstantEstimate(epochReward),
+		networkPower: abi.NewStoragePower(1 << 40),
+		pledge:       "999999984306749440",
+		precommitDep: "36000000000000000000000",
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			powerEst := smoothing.TestingConstantEstimate(tc.networkPower)
+			pledge := power.InitialPledgeForPower(tc.qaPower, tc.baseline, tc.rewardEst, powerEst, circSupply)
+			assert.Equal(t, tc.pledge, pledge.String())
+			deposit := power.PreCommitDepositForPower(tc.rewardEst, powerEst, tc.qaPower)
+			assert.Equal(t, tc.precommitDep, deposit.String())
+		})
+	}
+
+	t.Run("sector takes the whole pledge share of an empty network", func(t *testing.T) {
+		// With no network or baseline power the sector's own power is the share denominator,
+		// so the consensus pledge is the entire lock target and the pledge is capped.
+		powerEst := smoothing.TestingConstantEstimate(big.Zero())
+		rewardEst := smoothing.TestingConstantEstimate(big.Zero())
+		pledge := power.InitialPledgeForPower(sectorPower, big.Zero(), rewardEst, powerEst, circSupply)
+		assert.Equal(t, big.Mul(power.InitialPledgeMaxPerByte, sectorPower), pledge)
+	})
+}
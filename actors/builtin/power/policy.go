@@ -11,3 +11,12 @@ const ConsensusMinerMinMiners = 4 // PARAM_SPEC
 // Onboarding 1EiB/year requires at least 32 prove-commits per epoch.
 const MaxMinerProveCommitsPerEpoch = 200 // PARAM_SPEC
 
+// Maximum number of deferred cron events dequeued in a single cron tick.
+//
+// This bounds the number of miner callbacks, and so the gas, in the cron call path.
+// Events beyond this bound remain queued, in order, and are processed in subsequent ticks.
+const MaxCronEventsPerEpoch = 500 // PARAM_SPEC
+
+// Maximum number of claimed power deltas in a single UpdateClaimedPowerBatch message.
+// This matches the number of sectors a miner may address in a single message, each contributing one delta.
+const ClaimedPowerDeltasMax = 10_000 // PARAM_SPEC
@@ -25,7 +25,7 @@ type Runtime = runtime.Runtime
 type SectorTermination int64
 
 const (
-	ErrTooManyProveCommits = exitcode.FirstActorSpecificExitCode + iota
+	ErrTooManyProveCommits = builtin.FirstPowerExitCode + iota
 )
 
 type Actor struct{}
@@ -41,6 +41,8 @@ func (a Actor) Exports() []interface{} {
 		7:                         nil, // deprecated
 		8:                         a.SubmitPoRepForBulkVerify,
 		9:                         a.CurrentTotalPower,
+		10:                        a.NetworkPowerStats,
+		11:                        a.UpdateClaimedPowerBatch,
 	}
 }
 
@@ -56,7 +58,15 @@ func (a Actor) State() cbor.Er {
 	return new(State)
 }
 
+// Limits on the parameters of exported methods, checked before a method is invoked.
+func (a Actor) ParamLimits() map[abi.MethodNum]builtin.ParamLimits {
+	return map[abi.MethodNum]builtin.ParamLimits{
+		builtin.MethodsPower.UpdateClaimedPowerBatch: {MaxLengths: []builtin.LengthLimit{{Field: "Deltas", Max: ClaimedPowerDeltasMax}}},
+	}
+}
+
 var _ runtime.VMActor = Actor{}
+var _ builtin.ParamLimiter = Actor{}
 
 // Storage miner actor constructor params are defined here so the power actor can send them to the init actor
 // to instantiate miners.
@@ -102,6 +112,9 @@ type CreateMinerReturn = power0.CreateMinerReturn
 
 func (a Actor) CreateMiner(rt Runtime, params *CreateMinerParams) *CreateMinerReturn {
 	rt.ValidateImmediateCallerType(builtin.CallerTypesSignable...)
+	if nv := rt.NetworkVersion(); !CanUseWindowPoStProof(params.WindowPoStProofType, nv) {
+		rt.Abortf(exitcode.ErrIllegalArgument, "unsupported Window PoSt proof type %v at network version %v", params.WindowPoStProofType, nv)
+	}
 
 	ctorParams := MinerConstructorParams{
 		OwnerAddr:  params.Owner,
@@ -127,9 +140,8 @@ func (a Actor) CreateMiner(rt Runtime, params *CreateMinerParams) *CreateMinerRe
 	)
 	builtin.RequireSuccess(rt, code, "failed to init new actor")
 
-	var st State
-	rt.StateTransaction(&st, func() {
-		claims, err := adt.AsMap(adt.AsStore(rt), st.Claims, builtin.DefaultHamtBitwidth)
+	WithState(rt, func(st *State) {
+		claims, err := adt.AsMap(adt.AsStore(rt), st.Claims, ClaimsHamtBitwidth)
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load claims")
 
 		err = setClaim(claims, addresses.IDAddress, &Claim{params.WindowPoStProofType, abi.NewStoragePower(0), abi.NewStoragePower(0)})
@@ -161,9 +173,8 @@ type UpdateClaimedPowerParams = power0.UpdateClaimedPowerParams
 func (a Actor) UpdateClaimedPower(rt Runtime, params *UpdateClaimedPowerParams) *abi.EmptyValue {
 	rt.ValidateImmediateCallerType(builtin.StorageMinerActorCodeID)
 	minerAddr := rt.Caller()
-	var st State
-	rt.StateTransaction(&st, func() {
-		claims, err := adt.AsMap(adt.AsStore(rt), st.Claims, builtin.DefaultHamtBitwidth)
+	WithState(rt, func(st *State) {
+		claims, err := adt.AsMap(adt.AsStore(rt), st.Claims, ClaimsHamtBitwidth)
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load claims")
 
 		err = st.addToClaim(claims, minerAddr, params.RawByteDelta, params.QualityAdjustedDelta)
@@ -172,6 +183,50 @@ func (a Actor) UpdateClaimedPower(rt Runtime, params *UpdateClaimedPowerParams)
 		st.Claims, err = claims.Root()
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush claims")
 	})
+	emitClaimUpdated(rt, minerAddr, params.RawByteDelta, params.QualityAdjustedDelta)
+	return nil
+}
+
+type UpdateClaimedPowerBatchParams struct {
+	Deltas      []UpdateClaimedPowerParams // Power deltas, e.g. one per sector, which may be of either sign.
+	PledgeDelta abi.TokenAmount
+}
+
+// Applies the net of a batch of claimed power deltas and a pledge delta for the calling actor at once,
+// so that a miner operating on many sectors sends a single message rather than one per sector or quantity.
+// May only be invoked by a miner actor.
+func (a Actor) UpdateClaimedPowerBatch(rt Runtime, params *UpdateClaimedPowerBatchParams) *abi.EmptyValue {
+	rt.ValidateImmediateCallerType(builtin.StorageMinerActorCodeID)
+	minerAddr := rt.Caller()
+
+	rawDelta := big.Zero()
+	qaDelta := big.Zero()
+	for _, delta := range params.Deltas {
+		rawDelta = big.Add(rawDelta, delta.RawByteDelta)
+		qaDelta = big.Add(qaDelta, delta.QualityAdjustedDelta)
+	}
+
+	WithState(rt, func(st *State) {
+		claims, err := adt.AsMap(adt.AsStore(rt), st.Claims, ClaimsHamtBitwidth)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load claims")
+
+		if !rawDelta.IsZero() || !qaDelta.IsZero() {
+			err = st.addToClaim(claims, minerAddr, rawDelta, qaDelta)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to update power raw %s, qa %s", rawDelta, qaDelta)
+
+			st.Claims, err = claims.Root()
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush claims")
+		}
+
+		if !params.PledgeDelta.IsZero() {
+			validateMinerHasClaim(rt, *st, minerAddr)
+			st.addPledgeTotal(params.PledgeDelta)
+			builtin.RequireState(rt, st.TotalPledgeCollateral.GreaterThanEqual(big.Zero()), "negative total pledge collateral %v", st.TotalPledgeCollateral)
+		}
+	})
+	if !rawDelta.IsZero() || !qaDelta.IsZero() {
+		emitClaimUpdated(rt, minerAddr, rawDelta, qaDelta)
+	}
 	return nil
 }
 
@@ -194,9 +249,8 @@ func (a Actor) EnrollCronEvent(rt Runtime, params *EnrollCronEventParams) *abi.E
 		rt.Abortf(exitcode.ErrIllegalArgument, "cron event epoch %d cannot be less than zero", params.EventEpoch)
 	}
 
-	var st State
-	rt.StateTransaction(&st, func() {
-		events, err := adt.AsMultimap(adt.AsStore(rt), st.CronEventQueue, CronQueueHamtBitwidth, CronQueueAmtBitwidth)
+	WithState(rt, func(st *State) {
+		events, err := LoadCronQueue(adt.AsStore(rt), st.CronEventQueue)
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load cron events")
 
 		err = st.appendCronEvent(events, params.EventEpoch, &minerEvent)
@@ -215,12 +269,13 @@ func (a Actor) OnEpochTickEnd(rt Runtime, _ *abi.EmptyValue) *abi.EmptyValue {
 	a.processBatchProofVerifies(rt)
 	a.processDeferredCronEvents(rt)
 
-	var st State
-	rt.StateTransaction(&st, func() {
+	var rawBytePower abi.StoragePower
+	WithState(rt, func(st *State) {
 		// update next epoch's power and pledge values
 		// this must come before the next epoch's rewards are calculated
 		// so that next epoch reward reflects power added this epoch
-		rawBytePower, qaPower := CurrentTotalPower(&st)
+		var qaPower abi.StoragePower
+		rawBytePower, qaPower = CurrentTotalPower(st)
 		st.ThisEpochPledgeCollateral = st.TotalPledgeCollateral
 		st.ThisEpochQualityAdjPower = qaPower
 		st.ThisEpochRawBytePower = rawBytePower
@@ -232,7 +287,7 @@ func (a Actor) OnEpochTickEnd(rt Runtime, _ *abi.EmptyValue) *abi.EmptyValue {
 	code := rt.Send(
 		builtin.RewardActorAddr,
 		builtin.MethodsReward.UpdateNetworkKPI,
-		&st.ThisEpochRawBytePower,
+		&rawBytePower,
 		abi.NewTokenAmount(0),
 		&builtin.Discard{},
 	)
@@ -243,9 +298,8 @@ func (a Actor) OnEpochTickEnd(rt Runtime, _ *abi.EmptyValue) *abi.EmptyValue {
 
 func (a Actor) UpdatePledgeTotal(rt Runtime, pledgeDelta *abi.TokenAmount) *abi.EmptyValue {
 	rt.ValidateImmediateCallerType(builtin.StorageMinerActorCodeID)
-	var st State
-	rt.StateTransaction(&st, func() {
-		validateMinerHasClaim(rt, st, rt.Caller())
+	WithState(rt, func(st *State) {
+		validateMinerHasClaim(rt, *st, rt.Caller())
 		st.addPledgeTotal(*pledgeDelta)
 		builtin.RequireState(rt, st.TotalPledgeCollateral.GreaterThanEqual(big.Zero()), "negative total pledge collateral %v", st.TotalPledgeCollateral)
 	})
@@ -261,18 +315,17 @@ func (a Actor) SubmitPoRepForBulkVerify(rt Runtime, sealInfo *proof.SealVerifyIn
 
 	minerAddr := rt.Caller()
 
-	var st State
-	rt.StateTransaction(&st, func() {
-		validateMinerHasClaim(rt, st, minerAddr)
+	WithState(rt, func(st *State) {
+		validateMinerHasClaim(rt, *st, minerAddr)
 
 		store := adt.AsStore(rt)
 		var mmap *adt.Multimap
 		var err error
 		if st.ProofValidationBatch == nil {
-			mmap, err = adt.MakeEmptyMultimap(store, builtin.DefaultHamtBitwidth, ProofValidationBatchAmtBitwidth)
+			mmap, err = adt.MakeEmptyMultimap(store, ProofValidationBatchHamtBitwidth, ProofValidationBatchAmtBitwidth)
 			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to create empty proof validation set")
 		} else {
-			mmap, err = adt.AsMultimap(adt.AsStore(rt), *st.ProofValidationBatch, builtin.DefaultHamtBitwidth, ProofValidationBatchAmtBitwidth)
+			mmap, err = adt.AsMultimap(adt.AsStore(rt), *st.ProofValidationBatch, ProofValidationBatchHamtBitwidth, ProofValidationBatchAmtBitwidth)
 			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load proof batch set")
 		}
 
@@ -310,8 +363,7 @@ type CurrentTotalPowerReturn struct {
 // of an epoch.
 func (a Actor) CurrentTotalPower(rt Runtime, _ *abi.EmptyValue) *CurrentTotalPowerReturn {
 	rt.ValidateImmediateCallerAcceptAny()
-	var st State
-	rt.StateReadonly(&st)
+	st := ReadState(rt)
 
 	return &CurrentTotalPowerReturn{
 		RawBytePower:            st.ThisEpochRawBytePower,
@@ -321,12 +373,40 @@ func (a Actor) CurrentTotalPower(rt Runtime, _ *abi.EmptyValue) *CurrentTotalPow
 	}
 }
 
+type NetworkPowerStatsReturn struct {
+	MinerCount              int64
+	MinerAboveMinPowerCount int64
+	// Power of miners meeting the consensus minimum, which is the power used in election
+	// once at least ConsensusMinerMinMiners miners meet it.
+	RawBytePowerAboveMin    abi.StoragePower
+	QualityAdjPowerAboveMin abi.StoragePower
+	// Power committed by all miners, including those below the consensus minimum.
+	RawBytesCommitted abi.StoragePower
+	QABytesCommitted  abi.StoragePower
+}
+
+// Returns the miner counts and power totals both above the consensus minimum power and overall.
+// Unlike CurrentTotalPower, these reflect all changes made so far in the current epoch.
+func (a Actor) NetworkPowerStats(rt Runtime, _ *abi.EmptyValue) *NetworkPowerStatsReturn {
+	rt.ValidateImmediateCallerAcceptAny()
+	st := ReadState(rt)
+
+	return &NetworkPowerStatsReturn{
+		MinerCount:              st.MinerCount,
+		MinerAboveMinPowerCount: st.MinerAboveMinPowerCount,
+		RawBytePowerAboveMin:    st.TotalRawBytePower,
+		QualityAdjPowerAboveMin: st.TotalQualityAdjPower,
+		RawBytesCommitted:       st.TotalBytesCommitted,
+		QABytesCommitted:        st.TotalQABytesCommitted,
+	}
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // Method utility functions
 ////////////////////////////////////////////////////////////////////////////////
 
 func validateMinerHasClaim(rt Runtime, st State, minerAddr addr.Address) {
-	claims, err := adt.AsMap(adt.AsStore(rt), st.Claims, builtin.DefaultHamtBitwidth)
+	claims, err := adt.AsMap(adt.AsStore(rt), st.Claims, ClaimsHamtBitwidth)
 	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load claims")
 
 	found, err := claims.Has(abi.AddrKey(minerAddr))
@@ -337,20 +417,18 @@ func validateMinerHasClaim(rt Runtime, st State, minerAddr addr.Address) {
 }
 
 func (a Actor) processBatchProofVerifies(rt Runtime) {
-	var st State
-
 	var miners []addr.Address
 	verifies := make(map[addr.Address][]proof.SealVerifyInfo)
 
-	rt.StateTransaction(&st, func() {
+	WithState(rt, func(st *State) {
 		store := adt.AsStore(rt)
 		if st.ProofValidationBatch == nil {
 			return
 		}
-		mmap, err := adt.AsMultimap(store, *st.ProofValidationBatch, builtin.DefaultHamtBitwidth, ProofValidationBatchAmtBitwidth)
+		mmap, err := adt.AsMultimap(store, *st.ProofValidationBatch, ProofValidationBatchHamtBitwidth, ProofValidationBatchAmtBitwidth)
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load proofs validation batch")
 
-		claims, err := adt.AsMap(adt.AsStore(rt), st.Claims, builtin.DefaultHamtBitwidth)
+		claims, err := adt.AsMap(adt.AsStore(rt), st.Claims, ClaimsHamtBitwidth)
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load claims")
 
 		err = mmap.ForAll(func(k string, arr *adt.Array) error {
@@ -423,23 +501,41 @@ func (a Actor) processBatchProofVerifies(rt Runtime) {
 	}
 }
 
+// Dequeues cron events due up to the current epoch, at most MaxCronEventsPerEpoch of them, and invokes each
+// miner's callback. A miner whose callback fails is flagged by removal of its claim, without affecting others.
 func (a Actor) processDeferredCronEvents(rt Runtime) {
 	rtEpoch := rt.CurrEpoch()
 
 	var cronEvents []CronEvent
-	var st State
-	rt.StateTransaction(&st, func() {
-		events, err := adt.AsMultimap(adt.AsStore(rt), st.CronEventQueue, CronQueueHamtBitwidth, CronQueueAmtBitwidth)
+	WithState(rt, func(st *State) {
+		events, err := LoadCronQueue(adt.AsStore(rt), st.CronEventQueue)
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load cron events")
 
-		claims, err := adt.AsMap(adt.AsStore(rt), st.Claims, builtin.DefaultHamtBitwidth)
+		claims, err := adt.AsMap(adt.AsStore(rt), st.Claims, ClaimsHamtBitwidth)
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load claims")
 
+		nextCronEpoch := rtEpoch + 1
+		dequeued := 0
 		for epoch := st.FirstCronEpoch; epoch <= rtEpoch; epoch++ {
-			epochEvents, err := loadCronEvents(events, epoch)
+			if dequeued >= MaxCronEventsPerEpoch {
+				// Leave this and later epochs queued for the next tick.
+				nextCronEpoch = epoch
+				break
+			}
+
+			epochEvents, err := events.LoadEpoch(epoch)
 			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load cron events at %v", epoch)
+			if len(epochEvents) == 0 {
+				continue
+			}
+
+			batch := epochEvents
+			if remaining := MaxCronEventsPerEpoch - dequeued; len(batch) > remaining {
+				batch = batch[:remaining]
+			}
+			dequeued += len(batch)
 
-			for _, evt := range epochEvents {
+			for _, evt := range batch {
 				// refuse to process proofs for miner with no claim
 				found, err := claims.Has(abi.AddrKey(evt.MinerAddr))
 				builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to look up claim")
@@ -450,13 +546,17 @@ func (a Actor) processDeferredCronEvents(rt Runtime) {
 				cronEvents = append(cronEvents, evt)
 			}
 
-			if len(epochEvents) > 0 {
-				err = events.RemoveAll(epochKey(epoch))
-				builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to clear cron events at %v", epoch)
+			// Events that didn't fit in this tick's batch remain queued, preserving their order.
+			err = events.SetEpoch(epoch, epochEvents[len(batch):])
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to update cron events at %v", epoch)
+
+			if len(batch) < len(epochEvents) {
+				nextCronEpoch = epoch
+				break
 			}
 		}
 
-		st.FirstCronEpoch = rtEpoch + 1
+		st.FirstCronEpoch = nextCronEpoch
 
 		st.CronEventQueue, err = events.Root()
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush events")
@@ -470,10 +570,9 @@ func (a Actor) processDeferredCronEvents(rt Runtime) {
 			abi.NewTokenAmount(0),
 			&builtin.Discard{},
 		)
-		// If a callback fails, this actor continues to invoke other callbacks
-		// and persists state removing the failed event from the event queue. It won't be tried again.
-		// Failures are unexpected here but will result in removal of miner power
-		// A log message would really help here.
+		// If a callback fails (including by the callee aborting or panicking), this actor continues to invoke
+		// other callbacks and persists state removing the failed event from the event queue. It won't be tried again.
+		// Failures are unexpected here but will result in removal of miner power.
 		if code != exitcode.Ok {
 			rt.Log(rtt.WARN, "OnDeferredCronEvent failed for miner %s: exitcode %d", event.MinerAddr, code)
 			failedMinerCrons = append(failedMinerCrons, event.MinerAddr)
@@ -481,8 +580,8 @@ func (a Actor) processDeferredCronEvents(rt Runtime) {
 	}
 
 	if len(failedMinerCrons) > 0 {
-		rt.StateTransaction(&st, func() {
-			claims, err := adt.AsMap(adt.AsStore(rt), st.Claims, builtin.DefaultHamtBitwidth)
+		WithState(rt, func(st *State) {
+			claims, err := adt.AsMap(adt.AsStore(rt), st.Claims, ClaimsHamtBitwidth)
 			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load claims")
 
 			// Remove miner claim and leave miner frozen
@@ -2,7 +2,6 @@ package power
 
 import (
 	"fmt"
-	"reflect"
 
 	addr "github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/abi"
@@ -23,9 +22,8 @@ var InitialQAPowerEstimatePosition = big.Mul(big.NewInt(750_000), big.NewInt(1<<
 // max chain throughput in bytes per epoch = 120 ProveCommits / epoch = 3,840 GiB
 var InitialQAPowerEstimateVelocity = big.Mul(big.NewInt(3_840), big.NewInt(1<<30))
 
-// Bitwidth of CronEventQueue HAMT determined empirically from mutation
-// patterns and projections of mainnet data.
-const CronQueueHamtBitwidth = 6
+// Bitwidth of the CronEventQueue AMT keyed by epoch.
+const CronQueueEpochAmtBitwidth = 6
 
 // Bitwidth of CronEventQueue AMT determined empirically from mutation
 // patterns and projections of mainnet data.
@@ -35,6 +33,12 @@ const CronQueueAmtBitwidth = 6
 // pattersn and projections of mainnet data.
 const ProofValidationBatchAmtBitwidth = 4
 
+// Bitwidth of the HAMT of miner claims.
+const ClaimsHamtBitwidth = builtin.DefaultHamtBitwidth
+
+// Bitwidth of the ProofValidationBatch HAMT keyed by miner.
+const ProofValidationBatchHamtBitwidth = builtin.DefaultHamtBitwidth
+
 type State struct {
 	TotalRawBytePower abi.StoragePower
 	// TotalBytesCommitted includes claims from miners below min power threshold
@@ -56,7 +60,7 @@ type State struct {
 	MinerAboveMinPowerCount int64
 
 	// A queue of events to be triggered by cron, indexed by epoch.
-	CronEventQueue cid.Cid // Multimap, (HAMT[ChainEpoch]AMT[CronEvent])
+	CronEventQueue cid.Cid // CronQueue, AMT[ChainEpoch]AMT[CronEvent]
 
 	// First epoch in which a cron task may be stored.
 	// Cron will iterate every epoch between this and the current epoch inclusively to find tasks to execute.
@@ -85,13 +89,13 @@ type CronEvent struct {
 }
 
 func ConstructState(store adt.Store) (*State, error) {
-	emptyClaimsMapCid, err := adt.StoreEmptyMap(store, builtin.DefaultHamtBitwidth)
+	emptyClaimsMapCid, err := adt.StoreEmptyMap(store, ClaimsHamtBitwidth)
 	if err != nil {
 		return nil, xerrors.Errorf("failed to create empty map: %w", err)
 	}
-	emptyCronQueueMMapCid, err := adt.StoreEmptyMultimap(store, CronQueueHamtBitwidth, CronQueueAmtBitwidth)
+	emptyCronQueueCid, err := adt.StoreEmptyArray(store, CronQueueEpochAmtBitwidth)
 	if err != nil {
-		return nil, xerrors.Errorf("failed to create empty multimap: %w", err)
+		return nil, xerrors.Errorf("failed to create empty array: %w", err)
 	}
 
 	return &State{
@@ -105,19 +109,35 @@ func ConstructState(store adt.Store) (*State, error) {
 		ThisEpochPledgeCollateral: abi.NewTokenAmount(0),
 		ThisEpochQAPowerSmoothed:  smoothing.NewEstimate(InitialQAPowerEstimatePosition, InitialQAPowerEstimateVelocity),
 		FirstCronEpoch:            0,
-		CronEventQueue:            emptyCronQueueMMapCid,
+		CronEventQueue:            emptyCronQueueCid,
 		Claims:                    emptyClaimsMapCid,
 		MinerCount:                0,
 		MinerAboveMinPowerCount:   0,
 	}, nil
 }
 
+// Loads a mutable copy of the power state and passes it to f, committing the state when f returns.
+// This is a typed wrapper around rt.StateTransaction and carries the same restrictions.
+func WithState(rt Runtime, f func(st *State)) {
+	var st State
+	rt.StateTransaction(&st, func() {
+		f(&st)
+	})
+}
+
+// Loads a read-only copy of the power state.
+func ReadState(rt Runtime) *State {
+	var st State
+	rt.StateReadonly(&st)
+	return &st
+}
+
 // MinerNominalPowerMeetsConsensusMinimum is used to validate Election PoSt
 // winners outside the chain state. If the miner has over a threshold of power
 // the miner meets the minimum.  If the network is a below a threshold of
 // miners and has power > zero the miner meets the minimum.
 func (st *State) MinerNominalPowerMeetsConsensusMinimum(s adt.Store, miner addr.Address) (bool, error) { //nolint:deadcode,unused
-	claims, err := adt.AsMap(s, st.Claims, builtin.DefaultHamtBitwidth)
+	claims, err := adt.AsMap(s, st.Claims, ClaimsHamtBitwidth)
 	if err != nil {
 		return false, xerrors.Errorf("failed to load claims: %w", err)
 	}
@@ -152,7 +172,7 @@ func (st *State) MinerNominalPowerMeetsConsensusMinimum(s adt.Store, miner addr.
 
 // Parameters may be negative to subtract.
 func (st *State) AddToClaim(s adt.Store, miner addr.Address, power abi.StoragePower, qapower abi.StoragePower) error {
-	claims, err := adt.AsMap(s, st.Claims, builtin.DefaultHamtBitwidth)
+	claims, err := adt.AsMap(s, st.Claims, ClaimsHamtBitwidth)
 	if err != nil {
 		return xerrors.Errorf("failed to load claims: %w", err)
 	}
@@ -170,7 +190,7 @@ func (st *State) AddToClaim(s adt.Store, miner addr.Address, power abi.StoragePo
 }
 
 func (st *State) GetClaim(s adt.Store, a addr.Address) (*Claim, bool, error) {
-	claims, err := adt.AsMap(s, st.Claims, builtin.DefaultHamtBitwidth)
+	claims, err := adt.AsMap(s, st.Claims, ClaimsHamtBitwidth)
 	if err != nil {
 		return nil, false, xerrors.Errorf("failed to load claims: %w", err)
 	}
@@ -281,13 +301,13 @@ func (st *State) addPledgeTotal(amount abi.TokenAmount) {
 	st.TotalPledgeCollateral = big.Add(st.TotalPledgeCollateral, amount)
 }
 
-func (st *State) appendCronEvent(events *adt.Multimap, epoch abi.ChainEpoch, event *CronEvent) error {
+func (st *State) appendCronEvent(events CronQueue, epoch abi.ChainEpoch, event *CronEvent) error {
 	// if event is in past, alter FirstCronEpoch so it will be found.
 	if epoch < st.FirstCronEpoch {
 		st.FirstCronEpoch = epoch
 	}
 
-	if err := events.Add(epochKey(epoch), event); err != nil {
+	if err := events.AddToQueue(epoch, event); err != nil {
 		return xerrors.Errorf("failed to store cron event at epoch %v for miner %v: %w", epoch, event, err)
 	}
 
@@ -299,16 +319,6 @@ func (st *State) updateSmoothedEstimate(delta abi.ChainEpoch) {
 	st.ThisEpochQAPowerSmoothed = filterQAPower.NextEstimate(st.ThisEpochQualityAdjPower, delta)
 }
 
-func loadCronEvents(mmap *adt.Multimap, epoch abi.ChainEpoch) ([]CronEvent, error) {
-	var events []CronEvent
-	var ev CronEvent
-	err := mmap.ForEach(epochKey(epoch), &ev, func(i int64) error {
-		events = append(events, ev)
-		return nil
-	})
-	return events, err
-}
-
 func setClaim(claims *adt.Map, a addr.Address, claim *Claim) error {
 	if claim.RawBytePower.LessThan(big.Zero()) {
 		return xerrors.Errorf("negative claim raw power %v", claim.RawBytePower)
@@ -330,16 +340,3 @@ func CurrentTotalPower(st *State) (abi.StoragePower, abi.StoragePower) {
 	}
 	return st.TotalRawBytePower, st.TotalQualityAdjPower
 }
-
-func epochKey(e abi.ChainEpoch) abi.Keyer {
-	return abi.IntKey(int64(e))
-}
-
-func init() {
-	// Check that ChainEpoch is indeed a signed integer to confirm that epochKey is making the right interpretation.
-	var e abi.ChainEpoch
-	if reflect.TypeOf(e).Kind() != reflect.Int64 {
-		panic("incorrect chain epoch encoding")
-	}
-
-}
@@ -27,10 +27,57 @@ import (
 	tutil "github.com/filecoin-project/specs-actors/v3/support/testing"
 )
 
+func init() {
+	// permit 2KiB sectors in tests
+	if err := power.EnableSealProof(abi.RegisteredSealProof_StackedDrg2KiBV1_1, network.Version0, network.VersionMax); err != nil {
+		panic(err)
+	}
+}
+
 func TestExports(t *testing.T) {
 	mock.CheckActorExports(t, power.Actor{})
 }
 
+func TestSealProofTypes(t *testing.T) {
+	t.Run("registry matches proof type properties", func(t *testing.T) {
+		for p, info := range power.SealProofTypes {
+			sectorSize, err := p.SectorSize()
+			require.NoError(t, err)
+			assert.Equal(t, sectorSize, info.SectorSize, "seal proof %d", p)
+			windowPoSt, err := p.RegisteredWindowPoStProof()
+			require.NoError(t, err)
+			assert.Equal(t, windowPoSt, info.WindowPoStProof, "seal proof %d", p)
+			winningPoSt, err := p.RegisteredWinningPoStProof()
+			require.NoError(t, err)
+			assert.Equal(t, winningPoSt, info.WinningPoStProof, "seal proof %d", p)
+		}
+	})
+
+	t.Run("availability by network version", func(t *testing.T) {
+		assert.True(t, power.CanUseSealProof(abi.RegisteredSealProof_StackedDrg32GiBV1, network.Version7))
+		assert.False(t, power.CanUseSealProof(abi.RegisteredSealProof_StackedDrg32GiBV1, network.Version8))
+		assert.False(t, power.CanUseSealProof(abi.RegisteredSealProof_StackedDrg32GiBV1_1, network.Version6))
+		assert.True(t, power.CanUseSealProof(abi.RegisteredSealProof_StackedDrg32GiBV1_1, network.VersionMax))
+		assert.False(t, power.CanUseSealProof(abi.RegisteredSealProof_StackedDrg512MiBV1_1, network.VersionMax))
+
+		// A Window PoSt proof type is available while any seal proof type using it is.
+		assert.True(t, power.CanUseWindowPoStProof(abi.RegisteredPoStProof_StackedDrgWindow32GiBV1, network.Version6))
+		assert.True(t, power.CanUseWindowPoStProof(abi.RegisteredPoStProof_StackedDrgWindow32GiBV1, network.VersionMax))
+		assert.False(t, power.CanUseWindowPoStProof(abi.RegisteredPoStProof_StackedDrgWindow512MiBV1, network.VersionMax))
+		assert.False(t, power.CanUseWindowPoStProof(abi.RegisteredPoStProof_StackedDrgWinning32GiBV1, network.VersionMax))
+
+		// Small sector sizes are registered, but unavailable unless enabled.
+		_, err := power.LookupSealProof(abi.RegisteredSealProof_StackedDrg512MiBV1_1)
+		assert.NoError(t, err)
+		assert.False(t, power.CanUseSealProof(abi.RegisteredSealProof_StackedDrg8MiBV1_1, network.Version0))
+		assert.True(t, power.CanUseSealProof(abi.RegisteredSealProof_StackedDrg2KiBV1_1, network.Version0)) // enabled by init
+
+		_, err = power.LookupSealProof(abi.RegisteredSealProof(100))
+		assert.Error(t, err)
+		assert.Error(t, power.EnableSealProof(abi.RegisteredSealProof(100), network.Version0, network.VersionMax))
+	})
+}
+
 func TestConstruction(t *testing.T) {
 	actor := newHarness(t)
 	owner := tutil.NewIDAddr(t, 101)
@@ -71,7 +118,7 @@ func TestConstruction(t *testing.T) {
 		assert.True(t, found)
 		assert.Equal(t, power.Claim{abi.RegisteredPoStProof_StackedDrgWindow32GiBV1, big.Zero(), big.Zero()}, actualClaim) // miner has not proven anything
 
-		verifyEmptyMap(t, rt, st.CronEventQueue)
+		verifyEmptyCronQueue(t, rt, st.CronEventQueue)
 		actor.checkState(rt)
 	})
 }
@@ -93,6 +140,23 @@ func TestCreateMinerFailures(t *testing.T) {
 		rt.Verify()
 	})
 
+	t.Run("fails with unsupported Window PoSt proof type", func(t *testing.T) {
+		rt, ac := basicPowerSetup(t)
+
+		rt.SetCaller(owner, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerType(builtin.AccountActorCodeID, builtin.MultisigActorCodeID)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "unsupported Window PoSt proof type", func() {
+			rt.Call(ac.CreateMiner, &power.CreateMinerParams{
+				Owner:               owner,
+				Worker:              owner,
+				WindowPoStProofType: abi.RegisteredPoStProof_StackedDrgWindow512MiBV1,
+				Peer:                peer,
+				Multiaddrs:          mAddr,
+			})
+		})
+		rt.Verify()
+	})
+
 	t.Run("fails if send to Init Actor fails", func(t *testing.T) {
 		rt, ac := basicPowerSetup(t)
 
@@ -387,6 +451,30 @@ func TestPowerAndPledgeAccounting(t *testing.T) {
 		actor.checkState(rt)
 	})
 
+	t.Run("network power stats report power above minimum and committed separately", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+
+		actor.createMinerBasic(rt, owner, owner, miner1)
+		actor.createMinerBasic(rt, owner, owner, miner2)
+		actor.createMinerBasic(rt, owner, owner, miner3)
+
+		actor.updateClaimedPower(rt, miner1, smallPowerUnit, mul(smallPowerUnit, 10))
+		actor.updateClaimedPower(rt, miner2, powerUnit, mul(powerUnit, 10))
+		actor.updateClaimedPower(rt, miner3, powerUnit, mul(powerUnit, 10))
+
+		// Below-minimum miner1 contributes to committed power only.
+		stats := actor.networkPowerStats(rt)
+		assert.Equal(t, int64(3), stats.MinerCount)
+		assert.Equal(t, int64(2), stats.MinerAboveMinPowerCount)
+		assert.Equal(t, mul(powerUnit, 2), stats.RawBytePowerAboveMin)
+		assert.Equal(t, mul(powerUnit, 20), stats.QualityAdjPowerAboveMin)
+		committed := big.Add(smallPowerUnit, mul(powerUnit, 2))
+		assert.Equal(t, committed, stats.RawBytesCommitted)
+		assert.Equal(t, mul(committed, 10), stats.QABytesCommitted)
+		actor.checkState(rt)
+	})
+
 	t.Run("all of one miner's power disappears when that miner dips below min power threshold", func(t *testing.T) {
 		// Setup four miners above threshold
 		rt := builder.Build(t)
@@ -545,6 +633,110 @@ func TestUpdatePledgeTotal(t *testing.T) {
 	})
 }
 
+func TestUpdateClaimedPowerBatch(t *testing.T) {
+	actor := newHarness(t)
+	owner := tutil.NewIDAddr(t, 101)
+	miner := tutil.NewIDAddr(t, 111)
+	builder := mock.NewBuilder(builtin.StoragePowerActorAddr).
+		WithCaller(builtin.SystemActorAddr, builtin.SystemActorCodeID)
+
+	delta := func(raw, qa int64) power.UpdateClaimedPowerParams {
+		return power.UpdateClaimedPowerParams{RawByteDelta: big.NewInt(raw), QualityAdjustedDelta: big.NewInt(qa)}
+	}
+
+	t.Run("applies the net of mixed power deltas and the pledge delta", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		actor.createMinerBasic(rt, owner, owner, miner)
+
+		actor.updateClaimedPowerBatch(rt, miner, &power.UpdateClaimedPowerBatchParams{
+			Deltas:      []power.UpdateClaimedPowerParams{delta(100, 200), delta(-40, -80), delta(0, 10)},
+			PledgeDelta: abi.NewTokenAmount(1e6),
+		})
+		claim := actor.getClaim(rt, miner)
+		assert.Equal(t, big.NewInt(60), claim.RawBytePower)
+		assert.Equal(t, big.NewInt(130), claim.QualityAdjPower)
+		st := getState(rt)
+		assert.Equal(t, big.NewInt(60), st.TotalBytesCommitted)
+		assert.Equal(t, big.NewInt(130), st.TotalQABytesCommitted)
+		actor.expectTotalPledgeEager(rt, abi.NewTokenAmount(1e6))
+
+		// Deltas netting to zero leave the claim unchanged, while the pledge is still updated.
+		actor.updateClaimedPowerBatch(rt, miner, &power.UpdateClaimedPowerBatchParams{
+			Deltas:      []power.UpdateClaimedPowerParams{delta(-60, -130), delta(60, 130)},
+			PledgeDelta: abi.NewTokenAmount(-4e5),
+		})
+		claim = actor.getClaim(rt, miner)
+		assert.Equal(t, big.NewInt(60), claim.RawBytePower)
+		assert.Equal(t, big.NewInt(130), claim.QualityAdjPower)
+		actor.expectTotalPledgeEager(rt, abi.NewTokenAmount(6e5))
+
+		actor.updateClaimedPowerBatch(rt, miner, &power.UpdateClaimedPowerBatchParams{
+			Deltas:      []power.UpdateClaimedPowerParams{delta(-60, -130)},
+			PledgeDelta: big.Zero(),
+		})
+		claim = actor.getClaim(rt, miner)
+		assert.True(t, claim.RawBytePower.IsZero())
+		assert.True(t, claim.QualityAdjPower.IsZero())
+		actor.checkState(rt)
+	})
+
+	t.Run("fails if caller is not a StorageMinerActor", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		rt.SetCaller(miner, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
+		rt.ExpectAbort(exitcode.SysErrForbidden, func() {
+			rt.Call(actor.UpdateClaimedPowerBatch, &power.UpdateClaimedPowerBatchParams{
+				Deltas:      []power.UpdateClaimedPowerParams{delta(1, 1)},
+				PledgeDelta: big.Zero(),
+			})
+		})
+		rt.Verify()
+	})
+
+	t.Run("too many deltas are rejected before dispatch", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		actor.createMinerBasic(rt, owner, owner, miner)
+
+		rt.SetCaller(miner, builtin.StorageMinerActorCodeID)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "Deltas length", func() {
+			rt.Call(actor.UpdateClaimedPowerBatch, &power.UpdateClaimedPowerBatchParams{
+				Deltas:      make([]power.UpdateClaimedPowerParams, power.ClaimedPowerDeltasMax+1),
+				PledgeDelta: big.Zero(),
+			})
+		})
+		rt.Verify()
+		actor.checkState(rt)
+	})
+
+	t.Run("fails if miner has no claim", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+		actor.createMinerBasic(rt, owner, owner, miner)
+		actor.deleteClaim(rt, miner)
+
+		rt.SetCaller(miner, builtin.StorageMinerActorCodeID)
+		rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
+		rt.ExpectAbortContainsMessage(exitcode.ErrNotFound, "no claim", func() {
+			rt.Call(actor.UpdateClaimedPowerBatch, &power.UpdateClaimedPowerBatchParams{
+				Deltas:      []power.UpdateClaimedPowerParams{delta(1, 1)},
+				PledgeDelta: big.Zero(),
+			})
+		})
+		rt.Verify()
+
+		rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
+		rt.ExpectAbortContainsMessage(exitcode.ErrForbidden, "unknown miner", func() {
+			rt.Call(actor.UpdateClaimedPowerBatch, &power.UpdateClaimedPowerBatchParams{
+				PledgeDelta: abi.NewTokenAmount(1),
+			})
+		})
+		rt.Verify()
+	})
+}
+
 func TestCron(t *testing.T) {
 	actor := newHarness(t)
 	miner1 := tutil.NewIDAddr(t, 101)
@@ -665,15 +857,12 @@ func TestCron(t *testing.T) {
 		// assert used cron events are cleaned up
 		st := getState(rt)
 
-		mmap, err := adt.AsMultimap(rt.AdtStore(), st.CronEventQueue, power.CronQueueHamtBitwidth, power.CronQueueAmtBitwidth)
+		queue, err := power.LoadCronQueue(rt.AdtStore(), st.CronEventQueue)
 		require.NoError(t, err)
 
-		var ev power.CronEvent
-		err = mmap.ForEach(abi.IntKey(int64(2)), &ev, func(i int64) error {
-			t.Errorf("Unexpected bitfield at epoch %d", i)
-			return nil
-		})
+		evts, err := queue.LoadEpoch(2)
 		require.NoError(t, err)
+		assert.Empty(t, evts)
 		actor.checkState(rt)
 	})
 
@@ -786,6 +975,98 @@ func TestCron(t *testing.T) {
 		rt.Verify()
 		actor.checkState(rt)
 	})
+
+	t.Run("failing callee is skipped and flagged without blocking other miners", func(t *testing.T) {
+		miner3 := tutil.NewIDAddr(t, 104)
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+
+		rt.SetEpoch(1)
+		actor.createMinerBasic(rt, owner, owner, miner1)
+		actor.createMinerBasic(rt, owner, owner, miner2)
+		actor.createMinerBasic(rt, owner, owner, miner3)
+
+		actor.enrollCronEvent(rt, miner1, 2, []byte{0x1})
+		actor.enrollCronEvent(rt, miner2, 2, []byte{0x2})
+		actor.enrollCronEvent(rt, miner3, 3, []byte{0x3})
+
+		expectedPower := big.NewInt(0)
+		rt.SetEpoch(3)
+		rt.ExpectValidateCallerAddr(builtin.CronActorAddr)
+		rt.ExpectBatchVerifySeals(nil, nil, nil)
+
+		// The middle callee fails with a system error exit code.
+		rt.ExpectSend(miner1, builtin.MethodsMiner.OnDeferredCronEvent, builtin.CBORBytes([]byte{0x1}), big.Zero(), nil, exitcode.Ok)
+		rt.ExpectSend(miner2, builtin.MethodsMiner.OnDeferredCronEvent, builtin.CBORBytes([]byte{0x2}), big.Zero(), nil, exitcode.SysErrorIllegalActor)
+		rt.ExpectSend(miner3, builtin.MethodsMiner.OnDeferredCronEvent, builtin.CBORBytes([]byte{0x3}), big.Zero(), nil, exitcode.Ok)
+		rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.UpdateNetworkKPI, &expectedPower, big.Zero(), nil, exitcode.Ok)
+		rt.SetCaller(builtin.CronActorAddr, builtin.CronActorCodeID)
+		rt.Call(actor.Actor.OnEpochTickEnd, nil)
+		rt.Verify()
+
+		rt.ExpectLogsContain("OnDeferredCronEvent failed for miner t0102")
+
+		// Only the failing miner is flagged by removal of its claim.
+		st := getState(rt)
+		for _, m := range []addr.Address{miner1, miner3} {
+			_, found, err := st.GetClaim(rt.AdtStore(), m)
+			require.NoError(t, err)
+			assert.True(t, found)
+		}
+		_, found, err := st.GetClaim(rt.AdtStore(), miner2)
+		require.NoError(t, err)
+		assert.False(t, found)
+		assert.Equal(t, int64(2), st.MinerCount)
+		assert.Equal(t, abi.ChainEpoch(4), st.FirstCronEpoch)
+		actor.checkState(rt)
+	})
+
+	t.Run("processes at most a bounded batch of events per tick", func(t *testing.T) {
+		rt := builder.Build(t)
+		actor.constructAndVerify(rt)
+
+		rt.SetEpoch(1)
+		actor.createMinerBasic(rt, owner, owner, miner1)
+		actor.createMinerBasic(rt, owner, owner, miner2)
+
+		// Fill epoch 2 beyond the bound with miner 1's events, the last of which doesn't fit in the first batch.
+		for i := 0; i < power.MaxCronEventsPerEpoch+1; i++ {
+			actor.enrollCronEvent(rt, miner1, 2, payload(i))
+		}
+		actor.enrollCronEvent(rt, miner2, 3, []byte{0x2})
+
+		expectedPower := big.NewInt(0)
+		rt.SetEpoch(3)
+		rt.ExpectValidateCallerAddr(builtin.CronActorAddr)
+		rt.ExpectBatchVerifySeals(nil, nil, nil)
+		for i := 0; i < power.MaxCronEventsPerEpoch; i++ {
+			rt.ExpectSend(miner1, builtin.MethodsMiner.OnDeferredCronEvent, builtin.CBORBytes(payload(i)), big.Zero(), nil, exitcode.Ok)
+		}
+		rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.UpdateNetworkKPI, &expectedPower, big.Zero(), nil, exitcode.Ok)
+		rt.SetCaller(builtin.CronActorAddr, builtin.CronActorCodeID)
+		rt.Call(actor.Actor.OnEpochTickEnd, nil)
+		rt.Verify()
+
+		// The remainder stays queued from the partially processed epoch.
+		st := getState(rt)
+		assert.Equal(t, abi.ChainEpoch(2), st.FirstCronEpoch)
+		actor.checkState(rt)
+
+		// The next tick picks up the remainder, in order, followed by later epochs.
+		rt.SetEpoch(4)
+		rt.ExpectValidateCallerAddr(builtin.CronActorAddr)
+		rt.ExpectBatchVerifySeals(nil, nil, nil)
+		rt.ExpectSend(miner1, builtin.MethodsMiner.OnDeferredCronEvent, builtin.CBORBytes(payload(power.MaxCronEventsPerEpoch)), big.Zero(), nil, exitcode.Ok)
+		rt.ExpectSend(miner2, builtin.MethodsMiner.OnDeferredCronEvent, builtin.CBORBytes([]byte{0x2}), big.Zero(), nil, exitcode.Ok)
+		rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.UpdateNetworkKPI, &expectedPower, big.Zero(), nil, exitcode.Ok)
+		rt.SetCaller(builtin.CronActorAddr, builtin.CronActorCodeID)
+		rt.Call(actor.Actor.OnEpochTickEnd, nil)
+		rt.Verify()
+
+		st = getState(rt)
+		assert.Equal(t, abi.ChainEpoch(5), st.FirstCronEpoch)
+		actor.checkState(rt)
+	})
 }
 
 func TestSubmitPoRepForBulkVerify(t *testing.T) {
@@ -1081,6 +1362,12 @@ func verifyEmptyMap(t testing.TB, rt *mock.Runtime, cid cid.Cid) {
 	assert.Empty(t, keys)
 }
 
+func verifyEmptyCronQueue(t testing.TB, rt *mock.Runtime, root cid.Cid) {
+	queue, err := power.LoadCronQueue(adt.AsStore(rt), root)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), queue.Length())
+}
+
 type spActorHarness struct {
 	power.Actor
 	t                *testing.T
@@ -1120,7 +1407,7 @@ func (h *spActorHarness) constructAndVerify(rt *mock.Runtime) {
 	assert.Equal(h.t, int64(0), st.MinerAboveMinPowerCount)
 
 	verifyEmptyMap(h.t, rt, st.Claims)
-	verifyEmptyMap(h.t, rt, st.CronEventQueue)
+	verifyEmptyCronQueue(h.t, rt, st.CronEventQueue)
 }
 
 type confirmedSectorSend struct {
@@ -1222,20 +1509,12 @@ func (h *spActorHarness) getEnrolledCronTicks(rt *mock.Runtime, epoch abi.ChainE
 	var st power.State
 	rt.GetState(&st)
 
-	events, err := adt.AsMultimap(adt.AsStore(rt), st.CronEventQueue, power.CronQueueHamtBitwidth, power.CronQueueAmtBitwidth)
+	events, err := power.LoadCronQueue(adt.AsStore(rt), st.CronEventQueue)
 	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load cron events")
 
-	evts, found, err := events.Get(abi.IntKey(int64(epoch)))
-	require.NoError(h.t, err)
-	require.True(h.t, found)
-
-	cronEvt := &power.CronEvent{}
-	var cronEvents []power.CronEvent
-	err = evts.ForEach(cronEvt, func(i int64) error {
-		cronEvents = append(cronEvents, *cronEvt)
-		return nil
-	})
+	cronEvents, err := events.LoadEpoch(epoch)
 	require.NoError(h.t, err)
+	require.NotEmpty(h.t, cronEvents)
 
 	return cronEvents
 }
@@ -1298,6 +1577,13 @@ func (h *spActorHarness) updatePledgeTotal(rt *mock.Runtime, miner addr.Address,
 	require.EqualValues(h.t, big.Add(prev, delta), new)
 }
 
+func (h *spActorHarness) updateClaimedPowerBatch(rt *mock.Runtime, miner addr.Address, params *power.UpdateClaimedPowerBatchParams) {
+	rt.SetCaller(miner, builtin.StorageMinerActorCodeID)
+	rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
+	rt.Call(h.UpdateClaimedPowerBatch, params)
+	rt.Verify()
+}
+
 func (h *spActorHarness) currentPowerTotal(rt *mock.Runtime) *power.CurrentTotalPowerReturn {
 	rt.ExpectValidateCallerAny()
 	ret := rt.Call(h.CurrentTotalPower, nil).(*power.CurrentTotalPowerReturn)
@@ -1305,6 +1591,13 @@ func (h *spActorHarness) currentPowerTotal(rt *mock.Runtime) *power.CurrentTotal
 	return ret
 }
 
+func (h *spActorHarness) networkPowerStats(rt *mock.Runtime) *power.NetworkPowerStatsReturn {
+	rt.ExpectValidateCallerAny()
+	ret := rt.Call(h.NetworkPowerStats, nil).(*power.NetworkPowerStatsReturn)
+	rt.Verify()
+	return ret
+}
+
 func (h *spActorHarness) enrollCronEvent(rt *mock.Runtime, miner addr.Address, epoch abi.ChainEpoch, payload []byte) {
 	rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
 	rt.SetCaller(miner, builtin.StorageMinerActorCodeID)
@@ -1382,3 +1675,8 @@ func batchVerifyDefaultOutput(vis map[addr.Address][]proof.SealVerifyInfo) map[a
 	}
 	return out
 }
+
+// A distinct cron callback payload for each index.
+func payload(i int) []byte {
+	return []byte{byte(i >> 8), byte(i)}
+}
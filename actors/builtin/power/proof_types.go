@@ -0,0 +1,141 @@
+package power
+
+import (
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/network"
+	"golang.org/x/xerrors"
+)
+
+// Properties of a seal proof type, and the network versions in which it may be used by new miners and sectors.
+type SealProofInfo struct {
+	SectorSize       abi.SectorSize
+	WindowPoStProof  abi.RegisteredPoStProof
+	WinningPoStProof abi.RegisteredPoStProof
+	// Inclusive range of network versions in which new miners may be created, and new sectors pre-committed,
+	// with the proof type.
+	FirstVersion network.Version
+	LastVersion  network.Version
+}
+
+// Checks whether new miners and sectors may use the proof type at a network version.
+func (i *SealProofInfo) AvailableAt(nv network.Version) bool {
+	return i.FirstVersion <= nv && nv <= i.LastVersion
+}
+
+// Registry of the seal proof types recognised by the power and miner actors.
+// This is mutable to allow configuration of testing and development networks, e.g. to permit small sectors.
+var SealProofTypes = map[abi.RegisteredSealProof]*SealProofInfo{
+	// From network version 8, sectors sealed with the V1 seal proof types cannot be committed.
+	abi.RegisteredSealProof_StackedDrg32GiBV1: {
+		SectorSize:       32 << 30,
+		WindowPoStProof:  abi.RegisteredPoStProof_StackedDrgWindow32GiBV1,
+		WinningPoStProof: abi.RegisteredPoStProof_StackedDrgWinning32GiBV1,
+		FirstVersion:     network.Version0,
+		LastVersion:      network.Version7,
+	},
+	abi.RegisteredSealProof_StackedDrg64GiBV1: {
+		SectorSize:       64 << 30,
+		WindowPoStProof:  abi.RegisteredPoStProof_StackedDrgWindow64GiBV1,
+		WinningPoStProof: abi.RegisteredPoStProof_StackedDrgWinning64GiBV1,
+		FirstVersion:     network.Version0,
+		LastVersion:      network.Version7,
+	},
+
+	abi.RegisteredSealProof_StackedDrg32GiBV1_1: {
+		SectorSize:       32 << 30,
+		WindowPoStProof:  abi.RegisteredPoStProof_StackedDrgWindow32GiBV1,
+		WinningPoStProof: abi.RegisteredPoStProof_StackedDrgWinning32GiBV1,
+		FirstVersion:     network.Version7,
+		LastVersion:      network.VersionMax,
+	},
+	abi.RegisteredSealProof_StackedDrg64GiBV1_1: {
+		SectorSize:       64 << 30,
+		WindowPoStProof:  abi.RegisteredPoStProof_StackedDrgWindow64GiBV1,
+		WinningPoStProof: abi.RegisteredPoStProof_StackedDrgWinning64GiBV1,
+		FirstVersion:     network.Version7,
+		LastVersion:      network.VersionMax,
+	},
+
+	// The small sector sizes are registered but unavailable, with an empty range of network versions.
+	// Testing and development networks may enable them with EnableSealProof.
+	abi.RegisteredSealProof_StackedDrg2KiBV1: {
+		SectorSize:       2 << 10,
+		WindowPoStProof:  abi.RegisteredPoStProof_StackedDrgWindow2KiBV1,
+		WinningPoStProof: abi.RegisteredPoStProof_StackedDrgWinning2KiBV1,
+		FirstVersion:     network.VersionMax,
+		LastVersion:      network.Version0,
+	},
+	abi.RegisteredSealProof_StackedDrg8MiBV1: {
+		SectorSize:       8 << 20,
+		WindowPoStProof:  abi.RegisteredPoStProof_StackedDrgWindow8MiBV1,
+		WinningPoStProof: abi.RegisteredPoStProof_StackedDrgWinning8MiBV1,
+		FirstVersion:     network.VersionMax,
+		LastVersion:      network.Version0,
+	},
+	abi.RegisteredSealProof_StackedDrg512MiBV1: {
+		SectorSize:       512 << 20,
+		WindowPoStProof:  abi.RegisteredPoStProof_StackedDrgWindow512MiBV1,
+		WinningPoStProof: abi.RegisteredPoStProof_StackedDrgWinning512MiBV1,
+		FirstVersion:     network.VersionMax,
+		LastVersion:      network.Version0,
+	},
+	abi.RegisteredSealProof_StackedDrg2KiBV1_1: {
+		SectorSize:       2 << 10,
+		WindowPoStProof:  abi.RegisteredPoStProof_StackedDrgWindow2KiBV1,
+		WinningPoStProof: abi.RegisteredPoStProof_StackedDrgWinning2KiBV1,
+		FirstVersion:     network.VersionMax,
+		LastVersion:      network.Version0,
+	},
+	abi.RegisteredSealProof_StackedDrg8MiBV1_1: {
+		SectorSize:       8 << 20,
+		WindowPoStProof:  abi.RegisteredPoStProof_StackedDrgWindow8MiBV1,
+		WinningPoStProof: abi.RegisteredPoStProof_StackedDrgWinning8MiBV1,
+		FirstVersion:     network.VersionMax,
+		LastVersion:      network.Version0,
+	},
+	abi.RegisteredSealProof_StackedDrg512MiBV1_1: {
+		SectorSize:       512 << 20,
+		WindowPoStProof:  abi.RegisteredPoStProof_StackedDrgWindow512MiBV1,
+		WinningPoStProof: abi.RegisteredPoStProof_StackedDrgWinning512MiBV1,
+		FirstVersion:     network.VersionMax,
+		LastVersion:      network.Version0,
+	},
+}
+
+// Returns the registered properties of a seal proof type.
+func LookupSealProof(p abi.RegisteredSealProof) (*SealProofInfo, error) {
+	info, ok := SealProofTypes[p]
+	if !ok {
+		return nil, xerrors.Errorf("unsupported seal proof type %d", p)
+	}
+	return info, nil
+}
+
+// Permits new miners and sectors to use a registered seal proof type from network version first to last inclusive.
+// This is intended for configuration of testing and development networks, e.g. to permit small sectors.
+func EnableSealProof(p abi.RegisteredSealProof, first, last network.Version) error {
+	info, err := LookupSealProof(p)
+	if err != nil {
+		return err
+	}
+	info.FirstVersion = first
+	info.LastVersion = last
+	return nil
+}
+
+// Checks whether a seal proof type may be used by new sectors at a network version.
+func CanUseSealProof(p abi.RegisteredSealProof, nv network.Version) bool {
+	info, ok := SealProofTypes[p]
+	return ok && info.AvailableAt(nv)
+}
+
+// Checks whether a new miner may use a Window PoSt proof type at a network version,
+// i.e. whether some seal proof type for that Window PoSt proof type is available.
+func CanUseWindowPoStProof(p abi.RegisteredPoStProof, nv network.Version) bool {
+	for _, info := range SealProofTypes { //nolint:nomaprange
+		if info.WindowPoStProof == p && info.AvailableAt(nv) {
+			return true
+		}
+	}
+	return false
+}
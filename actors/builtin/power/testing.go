@@ -57,27 +57,21 @@ func CheckStateInvariants(st *State, store adt.Store) (*StateSummary, *builtin.M
 
 func CheckCronInvariants(st *State, store adt.Store, acc *builtin.MessageAccumulator) CronEventsByAddress {
 	byAddress := make(CronEventsByAddress)
-	queue, err := adt.AsMultimap(store, st.CronEventQueue, CronQueueHamtBitwidth, CronQueueAmtBitwidth)
+	queue, err := LoadCronQueue(store, st.CronEventQueue)
 	if err != nil {
 		acc.Addf("error loading cron event queue: %v", err)
 		// Bail here.
 		return byAddress
 	}
 
-	err = queue.ForAll(func(ekey string, arr *adt.Array) error {
-		epoch, err := abi.ParseIntKey(ekey)
-		acc.Require(err == nil, "non-int key in cron array")
-		if err != nil {
-			return nil // error noted above
-		}
-
-		acc.Require(abi.ChainEpoch(epoch) >= st.FirstCronEpoch, "cron event at epoch %d before FirstCronEpoch %d",
+	err = queue.ForEach(func(epoch abi.ChainEpoch, arr *adt.Array) error {
+		acc.Require(epoch >= st.FirstCronEpoch, "cron event at epoch %d before FirstCronEpoch %d",
 			epoch, st.FirstCronEpoch)
 
 		var event CronEvent
 		return arr.ForEach(&event, func(i int64) error {
 			byAddress[event.MinerAddr] = append(byAddress[event.MinerAddr], MinerCronEvent{
-				Epoch:   abi.ChainEpoch(epoch),
+				Epoch:   epoch,
 				Payload: event.CallbackPayload,
 			})
 
@@ -90,7 +84,7 @@ func CheckCronInvariants(st *State, store adt.Store, acc *builtin.MessageAccumul
 
 func CheckClaimInvariants(st *State, store adt.Store, acc *builtin.MessageAccumulator) ClaimsByAddress {
 	byAddress := make(ClaimsByAddress)
-	claims, err := adt.AsMap(store, st.Claims, builtin.DefaultHamtBitwidth)
+	claims, err := adt.AsMap(store, st.Claims, ClaimsHamtBitwidth)
 	if err != nil {
 		acc.Addf("error loading power claims: %v", err)
 		// Bail here
@@ -152,7 +146,7 @@ func CheckProofValidationInvariants(st *State, store adt.Store, claims ClaimsByA
 	}
 
 	proofs := make(ProofsByAddress)
-	if queue, err := adt.AsMultimap(store, *st.ProofValidationBatch, builtin.DefaultHamtBitwidth, ProofValidationBatchAmtBitwidth); err != nil {
+	if queue, err := adt.AsMultimap(store, *st.ProofValidationBatch, ProofValidationBatchHamtBitwidth, ProofValidationBatchAmtBitwidth); err != nil {
 		acc.Addf("error loading proof validation queue: %v", err)
 	} else {
 		err = queue.ForAll(func(key string, arr *adt.Array) error {
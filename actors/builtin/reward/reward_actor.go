@@ -25,6 +25,7 @@ func (a Actor) Exports() []interface{} {
 		2:                         a.AwardBlockReward,
 		3:                         a.ThisEpochReward,
 		4:                         a.UpdateNetworkKPI,
+		5:                         a.BurnFunds,
 	}
 }
 
@@ -70,8 +71,12 @@ type AwardBlockRewardParams = reward0.AwardBlockRewardParams
 // - the epoch block reward, computed and paid from the reward actor's balance,
 // - the block gas reward, expected to be transferred to the reward actor with this invocation.
 //
-// The reward is reduced before the residual is credited to the block producer, by:
-// - a penalty amount, provided as a parameter, which is burnt,
+// The penalty, provided as a parameter and scaled up by PenaltyMultiplier, is applied before the reward:
+// - as much of the penalty as the reward can cover is burnt directly from the reward,
+// - the net reward is sent to the block producer, which locks it for vesting,
+// - any penalty exceeding the reward is charged to the block producer along with the net reward,
+//   to be paid from its balance or recorded as fee debt.
+// If the net reward cannot be delivered, it is burnt instead.
 func (a Actor) AwardBlockReward(rt runtime.Runtime, params *AwardBlockRewardParams) *abi.EmptyValue {
 	rt.ValidateImmediateCallerIs(builtin.SystemActorAddr)
 	priorBalance := rt.CurrentBalance()
@@ -96,8 +101,7 @@ func (a Actor) AwardBlockReward(rt runtime.Runtime, params *AwardBlockRewardPara
 	// The miner penalty is scaled up by a factor of PenaltyMultiplier
 	penalty := big.Mul(big.NewInt(PenaltyMultiplier), params.Penalty)
 	totalReward := big.Zero()
-	var st State
-	rt.StateTransaction(&st, func() {
+	WithState(rt, func(st *State) {
 		blockReward := big.Mul(st.ThisEpochReward, big.NewInt(params.WinCount))
 		blockReward = big.Div(blockReward, big.NewInt(builtin.ExpectedLeadersPerEpoch))
 		totalReward = big.Add(blockReward, params.GasReward)
@@ -115,15 +119,30 @@ func (a Actor) AwardBlockReward(rt runtime.Runtime, params *AwardBlockRewardPara
 
 	builtin.RequireState(rt, totalReward.LessThanEqual(priorBalance), "reward %v exceeds balance %v", totalReward, priorBalance)
 
+	// Pay the penalty out of the reward first.
+	penaltyFromReward := big.Min(penalty, totalReward)
+	netReward := big.Sub(totalReward, penaltyFromReward)
+	penaltyRemaining := big.Sub(penalty, penaltyFromReward)
+	if penaltyFromReward.GreaterThan(big.Zero()) {
+		code := burnFunds(rt, penaltyFromReward, builtin.BurnReasonBlockRewardPenalty)
+		if !code.IsSuccess() {
+			rt.Log(rtt.ERROR, "failed to burn penalty %v from reward, code: %v", penaltyFromReward, code)
+		}
+	}
+
+	if netReward.IsZero() && penaltyRemaining.IsZero() {
+		return nil
+	}
+
 	// if this fails, we can assume the miner is responsible and avoid failing here.
 	rewardParams := builtin.ApplyRewardParams{
-		Reward:  totalReward,
-		Penalty: penalty,
+		Reward:  netReward,
+		Penalty: penaltyRemaining,
 	}
-	code := rt.Send(minerAddr, builtin.MethodsMiner.ApplyRewards, &rewardParams, totalReward, &builtin.Discard{})
+	code := rt.Send(minerAddr, builtin.MethodsMiner.ApplyRewards, &rewardParams, netReward, &builtin.Discard{})
 	if !code.IsSuccess() {
-		rt.Log(rtt.ERROR, "failed to send ApplyRewards call to the miner actor with funds: %v, code: %v", totalReward, code)
-		code := rt.Send(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, totalReward, &builtin.Discard{})
+		rt.Log(rtt.ERROR, "failed to send ApplyRewards call to the miner actor with funds: %v, code: %v", netReward, code)
+		code := burnFunds(rt, netReward, builtin.BurnReasonUndeliveredReward)
 		if !code.IsSuccess() {
 			rt.Log(rtt.ERROR, "failed to send unsent reward to the burnt funds actor, code: %v", code)
 		}
@@ -145,8 +164,7 @@ type ThisEpochRewardReturn struct {
 func (a Actor) ThisEpochReward(rt runtime.Runtime, _ *abi.EmptyValue) *ThisEpochRewardReturn {
 	rt.ValidateImmediateCallerAcceptAny()
 
-	var st State
-	rt.StateReadonly(&st)
+	st := ReadState(rt)
 	return &ThisEpochRewardReturn{
 		ThisEpochRewardSmoothed: st.ThisEpochRewardSmoothed,
 		ThisEpochBaselinePower:  st.ThisEpochBaselinePower,
@@ -162,8 +180,7 @@ func (a Actor) UpdateNetworkKPI(rt runtime.Runtime, currRealizedPower *abi.Stora
 		rt.Abortf(exitcode.ErrIllegalArgument, "argument should not be nil")
 	}
 
-	var st State
-	rt.StateTransaction(&st, func() {
+	WithState(rt, func(st *State) {
 		prev := st.Epoch
 		// if there were null runs catch up the computation until
 		// st.Epoch == rt.CurrEpoch()
@@ -178,3 +195,29 @@ func (a Actor) UpdateNetworkKPI(rt runtime.Runtime, currRealizedPower *abi.Stora
 	})
 	return nil
 }
+
+// Burns the funds sent with this message, adding them to the cumulative amount burnt for the given reason.
+// Miner and market actors burn funds through this method (via builtin.BurnWithReason) so that the
+// source of every burn can be attributed from chain state.
+func (a Actor) BurnFunds(rt runtime.Runtime, params *builtin.BurnFundsParams) *abi.EmptyValue {
+	rt.ValidateImmediateCallerType(builtin.StorageMinerActorCodeID, builtin.StorageMarketActorCodeID)
+	if !params.Reason.IsValid() {
+		rt.Abortf(exitcode.ErrIllegalArgument, "invalid burn reason %d", params.Reason)
+	}
+
+	code := burnFunds(rt, rt.ValueReceived(), params.Reason)
+	builtin.RequireSuccess(rt, code, "failed to burn funds")
+	return nil
+}
+
+// Sends funds from this actor's balance to the burnt funds actor and, if that succeeds,
+// records them against the burn reason.
+func burnFunds(rt runtime.Runtime, amount abi.TokenAmount, reason builtin.BurnReason) exitcode.ExitCode {
+	code := rt.Send(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, amount, &builtin.Discard{})
+	if code.IsSuccess() {
+		WithState(rt, func(st *State) {
+			st.recordBurn(reason, amount)
+		})
+	}
+	return code
+}
@@ -4,6 +4,8 @@ import (
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/big"
 
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v3/actors/runtime"
 	"github.com/filecoin-project/specs-actors/v3/actors/util/smoothing"
 )
 
@@ -62,6 +64,10 @@ type State struct {
 	// into a code constant in a subsequent upgrade.
 	SimpleTotal   abi.TokenAmount
 	BaselineTotal abi.TokenAmount
+
+	// CumulativeBurns tracks the total FIL burnt through the reward actor, indexed by builtin.BurnReason.
+	// Reasons beyond the end of the list have burnt nothing.
+	CumulativeBurns []abi.TokenAmount
 }
 
 func ConstructState(currRealizedPower abi.StoragePower) *State {
@@ -87,6 +93,21 @@ func ConstructState(currRealizedPower abi.StoragePower) *State {
 	return st
 }
 
+// Loads the reward state for mutation by f, committing it when f returns.
+func WithState(rt runtime.Runtime, f func(st *State)) {
+	var st State
+	rt.StateTransaction(&st, func() {
+		f(&st)
+	})
+}
+
+// Loads a read-only copy of the reward state.
+func ReadState(rt runtime.Runtime) *State {
+	var st State
+	rt.StateReadonly(&st)
+	return &st
+}
+
 // Takes in current realized power and updates internal state
 // Used for update of internal state during null rounds
 func (st *State) updateToNextEpoch(currRealizedPower abi.StoragePower) {
@@ -112,6 +133,21 @@ func (st *State) updateToNextEpochWithReward(currRealizedPower abi.StoragePower)
 	st.ThisEpochReward = computeReward(st.Epoch, prevRewardTheta, currRewardTheta, st.SimpleTotal, st.BaselineTotal)
 }
 
+// Returns the total FIL burnt for a reason.
+func (st *State) CumulativeBurn(reason builtin.BurnReason) abi.TokenAmount {
+	if reason < 0 || int(reason) >= len(st.CumulativeBurns) {
+		return big.Zero()
+	}
+	return st.CumulativeBurns[reason]
+}
+
+func (st *State) recordBurn(reason builtin.BurnReason, amount abi.TokenAmount) {
+	for int(reason) >= len(st.CumulativeBurns) {
+		st.CumulativeBurns = append(st.CumulativeBurns, big.Zero())
+	}
+	st.CumulativeBurns[reason] = big.Add(st.CumulativeBurns[reason], amount)
+}
+
 func (st *State) updateSmoothedEstimates(delta abi.ChainEpoch) {
 	filterReward := smoothing.LoadFilter(st.ThisEpochRewardSmoothed, smoothing.DefaultAlpha, smoothing.DefaultBeta)
 	st.ThisEpochRewardSmoothed = filterReward.NextEstimate(st.ThisEpochReward, delta)
@@ -7,6 +7,7 @@ import (
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/big"
 	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/ipfs/go-cid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -51,6 +52,15 @@ func TestConstructor(t *testing.T) {
 
 		assert.NotEqual(t, big.Zero(), st.ThisEpochReward)
 	})
+	t.Run("rejects nil power", func(t *testing.T) {
+		rt := mock.NewBuilder(builtin.RewardActorAddr).
+			WithCaller(builtin.SystemActorAddr, builtin.SystemActorCodeID).
+			Build(t)
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			rt.Call(actor.Constructor, (*abi.StoragePower)(nil))
+		})
+		rt.Verify()
+	})
 	t.Run("construct with more power than baseline", func(t *testing.T) {
 		rt := mock.NewBuilder(builtin.RewardActorAddr).
 			WithCaller(builtin.SystemActorAddr, builtin.SystemActorCodeID).
@@ -144,13 +154,26 @@ func TestAwardBlockReward(t *testing.T) {
 
 		// Total reward is a huge number, upon writing ~1e18, so 300 should be way less
 		smallReward := abi.NewTokenAmount(300)
-		penalty := abi.NewTokenAmount(100)
 		rt.SetBalance(smallReward)
-		rt.ExpectValidateCallerAddr(builtin.SystemActorAddr)
 
-		minerPenalty := big.Mul(big.NewInt(reward.PenaltyMultiplier), penalty)
-		expectedParams := builtin.ApplyRewardParams{Reward: smallReward, Penalty: minerPenalty}
-		rt.ExpectSend(winner, builtin.MethodsMiner.ApplyRewards, &expectedParams, smallReward, nil, 0)
+		actor.awardBlockReward(rt, winner, big.Zero(), big.Zero(), 1, smallReward)
+	})
+
+	t.Run("penalty is paid from reward before paying miner", func(t *testing.T) {
+		rt := builder.Build(t)
+		startRealizedPower := abi.NewStoragePower(1)
+		actor.constructAndVerify(rt, &startRealizedPower)
+		st := getState(rt)
+		st.ThisEpochReward = abi.NewTokenAmount(5000)
+		rt.ReplaceState(st)
+		rt.SetBalance(abi.NewTokenAmount(1e18))
+
+		// Reward of 1000 covers the scaled penalty of 300.
+		penalty := abi.NewTokenAmount(100)
+		rt.ExpectValidateCallerAddr(builtin.SystemActorAddr)
+		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, big.NewInt(300), nil, exitcode.Ok)
+		expectedParams := builtin.ApplyRewardParams{Reward: big.NewInt(700), Penalty: big.Zero()}
+		rt.ExpectSend(winner, builtin.MethodsMiner.ApplyRewards, &expectedParams, big.NewInt(700), nil, exitcode.Ok)
 		rt.Call(actor.AwardBlockReward, &reward.AwardBlockRewardParams{
 			Miner:     winner,
 			Penalty:   penalty,
@@ -158,6 +181,68 @@ func TestAwardBlockReward(t *testing.T) {
 			WinCount:  1,
 		})
 		rt.Verify()
+		assert.Equal(t, big.NewInt(300), getState(rt).CumulativeBurn(builtin.BurnReasonBlockRewardPenalty))
+	})
+
+	t.Run("miner is not paid when penalty equals reward", func(t *testing.T) {
+		rt := builder.Build(t)
+		startRealizedPower := abi.NewStoragePower(1)
+		actor.constructAndVerify(rt, &startRealizedPower)
+		rt.SetBalance(abi.NewTokenAmount(300))
+
+		// The reward is limited to the balance of 300, all of which pays the scaled penalty.
+		rt.ExpectValidateCallerAddr(builtin.SystemActorAddr)
+		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, big.NewInt(300), nil, exitcode.Ok)
+		rt.Call(actor.AwardBlockReward, &reward.AwardBlockRewardParams{
+			Miner:     winner,
+			Penalty:   abi.NewTokenAmount(100),
+			GasReward: big.Zero(),
+			WinCount:  1,
+		})
+		rt.Verify()
+	})
+
+	t.Run("penalty exceeding reward is charged to miner", func(t *testing.T) {
+		rt := builder.Build(t)
+		startRealizedPower := abi.NewStoragePower(1)
+		actor.constructAndVerify(rt, &startRealizedPower)
+		rt.SetBalance(abi.NewTokenAmount(200))
+
+		rt.ExpectValidateCallerAddr(builtin.SystemActorAddr)
+		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, big.NewInt(200), nil, exitcode.Ok)
+		expectedParams := builtin.ApplyRewardParams{Reward: big.Zero(), Penalty: big.NewInt(100)}
+		rt.ExpectSend(winner, builtin.MethodsMiner.ApplyRewards, &expectedParams, big.Zero(), nil, exitcode.Ok)
+		rt.Call(actor.AwardBlockReward, &reward.AwardBlockRewardParams{
+			Miner:     winner,
+			Penalty:   abi.NewTokenAmount(100),
+			GasReward: big.Zero(),
+			WinCount:  1,
+		})
+		rt.Verify()
+	})
+
+	t.Run("gas reward pays penalty before block reward", func(t *testing.T) {
+		rt := builder.Build(t)
+		startRealizedPower := abi.NewStoragePower(1)
+		actor.constructAndVerify(rt, &startRealizedPower)
+		st := getState(rt)
+		st.ThisEpochReward = abi.NewTokenAmount(0)
+		rt.ReplaceState(st)
+		rt.SetBalance(abi.NewTokenAmount(1000))
+
+		// No block reward, so the penalty is paid from the gas reward.
+		rt.ExpectValidateCallerAddr(builtin.SystemActorAddr)
+		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, big.NewInt(300), nil, exitcode.Ok)
+		expectedParams := builtin.ApplyRewardParams{Reward: big.NewInt(700), Penalty: big.Zero()}
+		rt.ExpectSend(winner, builtin.MethodsMiner.ApplyRewards, &expectedParams, big.NewInt(700), nil, exitcode.Ok)
+		rt.Call(actor.AwardBlockReward, &reward.AwardBlockRewardParams{
+			Miner:     winner,
+			Penalty:   abi.NewTokenAmount(100),
+			GasReward: big.NewInt(1000),
+			WinCount:  1,
+		})
+		rt.Verify()
+		assert.Equal(t, big.Zero(), getState(rt).TotalStoragePowerReward)
 	})
 
 	t.Run("TotalStoragePowerReward tracks correctly", func(t *testing.T) {
@@ -213,6 +298,59 @@ func TestAwardBlockReward(t *testing.T) {
 		})
 
 		rt.Verify()
+		assert.Equal(t, expectedReward, getState(rt).CumulativeBurn(builtin.BurnReasonUndeliveredReward))
+	})
+}
+
+func TestBurnFunds(t *testing.T) {
+	actor := rewardHarness{reward.Actor{}, t}
+	builder := mock.NewBuilder(builtin.RewardActorAddr).
+		WithCaller(builtin.SystemActorAddr, builtin.SystemActorCodeID)
+	miner := tutil.NewIDAddr(t, 1000)
+
+	t.Run("burns are recorded cumulatively by reason", func(t *testing.T) {
+		rt := builder.Build(t)
+		power := abi.NewStoragePower(1 << 50)
+		actor.constructAndVerify(rt, &power)
+
+		actor.burnFunds(rt, miner, builtin.StorageMinerActorCodeID, abi.NewTokenAmount(100), builtin.BurnReasonFeeDebt)
+		actor.burnFunds(rt, miner, builtin.StorageMinerActorCodeID, abi.NewTokenAmount(50), builtin.BurnReasonFeeDebt)
+		actor.burnFunds(rt, builtin.StorageMarketActorAddr, builtin.StorageMarketActorCodeID, abi.NewTokenAmount(30), builtin.BurnReasonDealSlash)
+
+		st := getState(rt)
+		assert.Equal(t, abi.NewTokenAmount(150), st.CumulativeBurn(builtin.BurnReasonFeeDebt))
+		assert.Equal(t, abi.NewTokenAmount(30), st.CumulativeBurn(builtin.BurnReasonDealSlash))
+		assert.Equal(t, big.Zero(), st.CumulativeBurn(builtin.BurnReasonTerminationFee))
+		assert.Equal(t, big.Zero(), st.CumulativeBurn(builtin.BurnReasonAggregateFee+1))
+	})
+
+	t.Run("fails for an invalid reason", func(t *testing.T) {
+		rt := builder.Build(t)
+		power := abi.NewStoragePower(1 << 50)
+		actor.constructAndVerify(rt, &power)
+
+		rt.SetCaller(miner, builtin.StorageMinerActorCodeID)
+		rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID, builtin.StorageMarketActorCodeID)
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "invalid burn reason", func() {
+			rt.Call(actor.BurnFunds, &builtin.BurnFundsParams{Reason: builtin.BurnReasonAggregateFee + 1})
+		})
+	})
+
+	t.Run("fails if the burn cannot be sent", func(t *testing.T) {
+		rt := builder.Build(t)
+		power := abi.NewStoragePower(1 << 50)
+		actor.constructAndVerify(rt, &power)
+
+		amount := abi.NewTokenAmount(100)
+		rt.SetCaller(miner, builtin.StorageMinerActorCodeID)
+		rt.SetBalance(amount)
+		rt.SetReceived(amount)
+		rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID, builtin.StorageMarketActorCodeID)
+		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, amount, nil, exitcode.SysErrInsufficientFunds)
+		rt.ExpectAbort(exitcode.SysErrInsufficientFunds, func() {
+			rt.Call(actor.BurnFunds, &builtin.BurnFundsParams{Reason: builtin.BurnReasonFeeDebt})
+		})
+		assert.Equal(t, big.Zero(), getState(rt).CumulativeBurn(builtin.BurnReasonFeeDebt))
 	})
 }
 
@@ -231,6 +369,28 @@ func TestThisEpochReward(t *testing.T) {
 		require.EqualValues(t, st.ThisEpochBaselinePower, resp.ThisEpochBaselinePower)
 		require.EqualValues(t, st.ThisEpochRewardSmoothed, resp.ThisEpochRewardSmoothed)
 	})
+
+	t.Run("miner fetches reward and baseline updated by network KPI", func(t *testing.T) {
+		actor := rewardHarness{reward.Actor{}, t}
+		builder := mock.NewBuilder(builtin.RewardActorAddr).
+			WithCaller(builtin.SystemActorAddr, builtin.SystemActorCodeID)
+		rt := builder.Build(t)
+		power := abi.NewStoragePower(1 << 50)
+		actor.constructAndVerify(rt, &power)
+		before := actor.thisEpochReward(rt)
+
+		rt.SetEpoch(abi.ChainEpoch(1))
+		actor.updateNetworkKPI(rt, &power)
+
+		rt.SetCaller(tutil.NewIDAddr(t, 1000), builtin.StorageMinerActorCodeID)
+		resp := actor.thisEpochReward(rt)
+		st := getState(rt)
+
+		require.EqualValues(t, st.ThisEpochBaselinePower, resp.ThisEpochBaselinePower)
+		require.EqualValues(t, st.ThisEpochRewardSmoothed, resp.ThisEpochRewardSmoothed)
+		assert.True(t, resp.ThisEpochBaselinePower.GreaterThan(before.ThisEpochBaselinePower))
+		assert.NotEqual(t, before.ThisEpochRewardSmoothed, resp.ThisEpochRewardSmoothed)
+	})
 }
 
 func TestSuccessiveKPIUpdates(t *testing.T) {
@@ -275,10 +435,15 @@ func (h *rewardHarness) updateNetworkKPI(rt *mock.Runtime, currRawPower *abi.Sto
 
 func (h *rewardHarness) awardBlockReward(rt *mock.Runtime, miner address.Address, penalty, gasReward abi.TokenAmount, winCount int64, expectedPayment abi.TokenAmount) {
 	rt.ExpectValidateCallerAddr(builtin.SystemActorAddr)
-	// expect penalty multiplier
+	// expect penalty multiplier, with the penalty paid from the payment first
 	minerPenalty := big.Mul(big.NewInt(reward.PenaltyMultiplier), penalty)
-	expectedParams := builtin.ApplyRewardParams{Reward: expectedPayment, Penalty: minerPenalty}
-	rt.ExpectSend(miner, builtin.MethodsMiner.ApplyRewards, &expectedParams, expectedPayment, nil, 0)
+	penaltyFromPayment := big.Min(minerPenalty, expectedPayment)
+	if penaltyFromPayment.GreaterThan(big.Zero()) {
+		rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, penaltyFromPayment, nil, exitcode.Ok)
+	}
+	netPayment := big.Sub(expectedPayment, penaltyFromPayment)
+	expectedParams := builtin.ApplyRewardParams{Reward: netPayment, Penalty: big.Sub(minerPenalty, penaltyFromPayment)}
+	rt.ExpectSend(miner, builtin.MethodsMiner.ApplyRewards, &expectedParams, netPayment, nil, 0)
 
 	rt.Call(h.AwardBlockReward, &reward.AwardBlockRewardParams{
 		Miner:     miner,
@@ -289,6 +454,18 @@ func (h *rewardHarness) awardBlockReward(rt *mock.Runtime, miner address.Address
 	rt.Verify()
 }
 
+func (h *rewardHarness) burnFunds(rt *mock.Runtime, caller address.Address, callerCode cid.Cid, amount abi.TokenAmount, reason builtin.BurnReason) {
+	rt.SetCaller(caller, callerCode)
+	rt.SetBalance(amount)
+	rt.SetReceived(amount)
+	rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID, builtin.StorageMarketActorCodeID)
+	rt.ExpectSend(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, amount, nil, exitcode.Ok)
+
+	ret := rt.Call(h.BurnFunds, &builtin.BurnFundsParams{Reason: reason})
+	assert.Nil(h.t, ret)
+	rt.Verify()
+}
+
 func (h *rewardHarness) thisEpochReward(rt *mock.Runtime) *reward.ThisEpochRewardReturn {
 	rt.ExpectValidateCallerAny()
 
@@ -25,5 +25,9 @@ func CheckStateInvariants(st *State, store adt.Store, priorEpoch abi.ChainEpoch,
 	acc.Require(st.CumsumRealized.GreaterThanEqual(big.Zero()), "cumsum realized < 0")
 	acc.Require(st.EffectiveBaselinePower.LessThanEqual(st.ThisEpochBaselinePower), "effective baseline power > baseline power")
 
+	for reason, burnt := range st.CumulativeBurns {
+		acc.Require(burnt.GreaterThanEqual(big.Zero()), "negative cumulative burn %v for %s", burnt, builtin.BurnReason(reason))
+	}
+
 	return &StateSummary{}, acc
 }
@@ -11,6 +11,7 @@ import (
 	"github.com/filecoin-project/go-state-types/exitcode"
 	builtin0 "github.com/filecoin-project/specs-actors/actors/builtin"
 	builtin2 "github.com/filecoin-project/specs-actors/v2/actors/builtin"
+	"github.com/ipfs/go-cid"
 
 	"github.com/filecoin-project/specs-actors/v3/actors/runtime"
 )
@@ -76,9 +77,10 @@ func RequireNoErr(rt runtime.Runtime, err error, defaultExitCode exitcode.ExitCo
 	}
 }
 
+// Queries a miner's control addresses with a static send, which cannot modify the miner's state.
 func RequestMinerControlAddrs(rt runtime.Runtime, minerAddr addr.Address) (ownerAddr addr.Address, workerAddr addr.Address, controlAddrs []addr.Address) {
 	var addrs MinerAddrs
-	code := rt.Send(minerAddr, MethodsMiner.ControlAddresses, nil, abi.NewTokenAmount(0), &addrs)
+	code := rt.StaticSend(minerAddr, MethodsMiner.ControlAddresses, nil, &addrs)
 	RequireSuccess(rt, code, "failed fetching control addresses")
 
 	return addrs.Owner, addrs.Worker, addrs.ControlAddrs
@@ -91,6 +93,36 @@ type MinerAddrs struct {
 	ControlAddrs []addr.Address
 }
 
+// Identifies the kind of transfer announced to a recipient through its universal receiver hook,
+// e.g. a transfer of some token, which determines how the payload is to be interpreted.
+type UniversalReceiverType uint64
+
+// Parameters for the universal receiver hook, by which an actor notifies a recipient of a typed transfer.
+// Any value sent along with the notification is credited to the recipient like a plain send.
+type UniversalReceiverParams struct {
+	Type    UniversalReceiverType
+	Payload []byte
+}
+
+const (
+	_ UniversalReceiverType = iota
+	// Notifies a storage deal client that one of its deals terminated before completing its term.
+	// The payload is a market.DealTerminationNotification.
+	UniversalReceiverTypeDealTermination
+)
+
+// Returns the number of the universal receiver hook method of the built-in actor with some code, if it has one.
+func UniversalReceiverHookMethod(code cid.Cid) (abi.MethodNum, bool) {
+	switch code {
+	case AccountActorCodeID:
+		return MethodsAccount.UniversalReceiverHook, true
+	case MultisigActorCodeID:
+		return MethodsMultisig.UniversalReceiverHook, true
+	default:
+		return 0, false
+	}
+}
+
 // Note: we could move this alias back to the mutually-importing packages that use it, now that they
 // can instead both alias the v2 version.
 //type ConfirmSectorProofsParams struct {
@@ -122,6 +154,30 @@ func ResolveToIDAddr(rt runtime.Runtime, address addr.Address) (addr.Address, er
 	return idAddr, nil
 }
 
+// RequestRobustAddress returns a robust (non-ID) address for the given address, which remains valid across chain
+// re-orgs that might re-assign ID addresses. A non-ID address is returned as-is.
+// The robust address of an ID address is known only for account actors, for which it is the public key address.
+func RequestRobustAddress(rt runtime.Runtime, address addr.Address) (addr.Address, error) {
+	if address.Protocol() != addr.ID {
+		return address, nil
+	}
+
+	codeID, found := rt.GetActorCodeCID(address)
+	if !found {
+		return address, exitcode.ErrNotFound.Wrapf("no actor at address %v", address)
+	}
+	if codeID != AccountActorCodeID {
+		return address, exitcode.ErrIllegalArgument.Wrapf("no robust address known for %v actor at %v", ActorNameByCode(codeID), address)
+	}
+
+	var pubkey addr.Address
+	code := rt.Send(address, MethodsAccount.PubkeyAddress, nil, abi.NewTokenAmount(0), &pubkey)
+	if !code.IsSuccess() {
+		return address, code.Wrapf("failed to fetch pubkey address from %v", address)
+	}
+	return pubkey, nil
+}
+
 // Note: we could move this alias back to the mutually-importing packages that use it, now that they
 // can instead both alias the v2 version.
 // type ApplyRewardParams struct {
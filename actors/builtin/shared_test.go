@@ -0,0 +1,65 @@
+package builtin_test
+
+import (
+	"testing"
+
+	addr "github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v3/actors/runtime"
+	"github.com/filecoin-project/specs-actors/v3/support/mock"
+	tutil "github.com/filecoin-project/specs-actors/v3/support/testing"
+)
+
+func TestRequestRobustAddress(t *testing.T) {
+	receiver := tutil.NewIDAddr(t, 1000)
+	account := tutil.NewIDAddr(t, 1001)
+	pubkey := tutil.NewBLSAddr(t, 1)
+	builder := mock.NewBuilder(receiver).
+		WithActorType(account, builtin.AccountActorCodeID)
+
+	// Invokes RequestRobustAddress from within an actor method.
+	request := func(rt *mock.Runtime, a addr.Address) (addr.Address, error) {
+		var robust addr.Address
+		var err error
+		rt.Call(func(rt runtime.Runtime, a *addr.Address) *abi.EmptyValue {
+			robust, err = builtin.RequestRobustAddress(rt, *a)
+			return nil
+		}, &a)
+		rt.Verify()
+		return robust, err
+	}
+
+	t.Run("returns robust address unchanged", func(t *testing.T) {
+		rt := builder.Build(t)
+		robust, err := request(rt, pubkey)
+		require.NoError(t, err)
+		assert.Equal(t, pubkey, robust)
+	})
+
+	t.Run("fetches pubkey address of account", func(t *testing.T) {
+		rt := builder.Build(t)
+		rt.ExpectSend(account, builtin.MethodsAccount.PubkeyAddress, nil, big.Zero(), &pubkey, exitcode.Ok)
+		robust, err := request(rt, account)
+		require.NoError(t, err)
+		assert.Equal(t, pubkey, robust)
+	})
+
+	t.Run("fails for non-account actor", func(t *testing.T) {
+		multisig := tutil.NewIDAddr(t, 1002)
+		rt := builder.WithActorType(multisig, builtin.MultisigActorCodeID).Build(t)
+		_, err := request(rt, multisig)
+		assert.Equal(t, exitcode.ErrIllegalArgument, exitcode.Unwrap(err, exitcode.Ok))
+	})
+
+	t.Run("fails for missing actor", func(t *testing.T) {
+		rt := builder.Build(t)
+		_, err := request(rt, tutil.NewIDAddr(t, 1003))
+		assert.Equal(t, exitcode.ErrNotFound, exitcode.Unwrap(err, exitcode.Ok))
+	})
+}
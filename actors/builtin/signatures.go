@@ -0,0 +1,52 @@
+package builtin
+
+import (
+	"fmt"
+
+	addr "github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/crypto"
+)
+
+// A SignatureScheme describes a type of signature that actors accept, and the addresses of its signers.
+// Signatures are verified by the VM's signature verification syscall, keyed by signature type, so a new scheme
+// also requires VM support for its verification.
+type SignatureScheme struct {
+	Name string
+	// The protocol of the public-key addresses of signers under this scheme.
+	// An account actor may be constructed for an address of this protocol.
+	Protocol addr.Protocol
+}
+
+var signatureSchemes = map[crypto.SigType]SignatureScheme{}
+var signatureTypesByProtocol = map[addr.Protocol]crypto.SigType{}
+
+func init() {
+	RegisterSignatureScheme(crypto.SigTypeSecp256k1, SignatureScheme{Name: "secp256k1", Protocol: addr.SECP256K1})
+	RegisterSignatureScheme(crypto.SigTypeBLS, SignatureScheme{Name: "bls", Protocol: addr.BLS})
+}
+
+// Registers a signature scheme for a signature type.
+// Each signature type and signer address protocol may be registered only once.
+// Registration is not synchronized, so should happen during initialization (e.g. in an init function).
+func RegisterSignatureScheme(sigType crypto.SigType, scheme SignatureScheme) {
+	if existing, ok := signatureSchemes[sigType]; ok {
+		panic(fmt.Sprintf("signature type %d already registered as %s", sigType, existing.Name))
+	}
+	if existing, ok := signatureTypesByProtocol[scheme.Protocol]; ok {
+		panic(fmt.Sprintf("address protocol %d already registered for signature type %d", scheme.Protocol, existing))
+	}
+	signatureSchemes[sigType] = scheme
+	signatureTypesByProtocol[scheme.Protocol] = sigType
+}
+
+// Returns the scheme registered for a signature type, and whether there is one.
+func SignatureSchemeOf(sigType crypto.SigType) (SignatureScheme, bool) {
+	scheme, ok := signatureSchemes[sigType]
+	return scheme, ok
+}
+
+// Returns the signature type of signers identified by addresses of a protocol, and whether there is one.
+func SignatureTypeForProtocol(protocol addr.Protocol) (crypto.SigType, bool) {
+	sigType, ok := signatureTypesByProtocol[protocol]
+	return sigType, ok
+}
@@ -0,0 +1,41 @@
+package builtin
+
+import (
+	"testing"
+
+	addr "github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignatureSchemes(t *testing.T) {
+	t.Run("builtin schemes", func(t *testing.T) {
+		scheme, ok := SignatureSchemeOf(crypto.SigTypeBLS)
+		assert.True(t, ok)
+		assert.Equal(t, addr.BLS, scheme.Protocol)
+
+		sigType, ok := SignatureTypeForProtocol(addr.SECP256K1)
+		assert.True(t, ok)
+		assert.Equal(t, crypto.SigTypeSecp256k1, sigType)
+	})
+
+	t.Run("non-signature protocols", func(t *testing.T) {
+		_, ok := SignatureTypeForProtocol(addr.ID)
+		assert.False(t, ok)
+		_, ok = SignatureTypeForProtocol(addr.Actor)
+		assert.False(t, ok)
+		_, ok = SignatureSchemeOf(crypto.SigTypeUnknown)
+		assert.False(t, ok)
+	})
+
+	t.Run("duplicate registration", func(t *testing.T) {
+		assert.Panics(t, func() {
+			RegisterSignatureScheme(crypto.SigTypeBLS, SignatureScheme{Name: "other", Protocol: addr.Protocol(100)})
+		})
+		assert.Panics(t, func() {
+			RegisterSignatureScheme(crypto.SigType(100), SignatureScheme{Name: "other", Protocol: addr.BLS})
+		})
+		_, ok := SignatureTypeForProtocol(addr.Protocol(100))
+		assert.False(t, ok)
+	})
+}
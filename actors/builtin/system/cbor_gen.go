@@ -46,3 +46,58 @@ func (t *State) UnmarshalCBOR(r io.Reader) error {
 
 	return nil
 }
+
+var lengthBufUpgradeActorsParams = []byte{129}
+
+func (t *UpgradeActorsParams) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write(lengthBufUpgradeActorsParams); err != nil {
+		return err
+	}
+
+	scratch := make([]byte, 9)
+
+	// t.Manifest (cid.Cid) (struct)
+
+	if err := cbg.WriteCidBuf(scratch, w, t.Manifest); err != nil {
+		return xerrors.Errorf("failed to write cid field t.Manifest: %w", err)
+	}
+
+	return nil
+}
+
+func (t *UpgradeActorsParams) UnmarshalCBOR(r io.Reader) error {
+	*t = UpgradeActorsParams{}
+
+	br := cbg.GetPeeker(r)
+	scratch := make([]byte, 8)
+
+	maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 1 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.Manifest (cid.Cid) (struct)
+
+	{
+
+		c, err := cbg.ReadCid(br)
+		if err != nil {
+			return xerrors.Errorf("failed to read cid field t.Manifest: %w", err)
+		}
+
+		t.Manifest = c
+
+	}
+	return nil
+}
@@ -3,17 +3,21 @@ package system
 import (
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/cbor"
+	"github.com/filecoin-project/go-state-types/exitcode"
 	"github.com/ipfs/go-cid"
 
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin/manifest"
 	"github.com/filecoin-project/specs-actors/v3/actors/runtime"
+	"github.com/filecoin-project/specs-actors/v3/actors/util/adt"
 )
 
 type Actor struct{}
 
 func (a Actor) Exports() []interface{} {
 	return []interface{}{
-		builtin.MethodConstructor: a.Constructor,
+		1: a.Constructor,
+		2: a.UpgradeActors,
 	}
 }
 
@@ -38,4 +42,27 @@ func (a Actor) Constructor(rt runtime.Runtime, _ *abi.EmptyValue) *abi.EmptyValu
 	return nil
 }
 
+type UpgradeActorsParams struct {
+	Manifest cid.Cid `checked:"true"` // Root of the manifest listing the code for each actor after the upgrade, checked by loading it.
+}
+
+// Replaces the code of every builtin actor in the state tree with the code of the same-named actor in
+// the new manifest. This is invoked implicitly by the VM at a network upgrade epoch.
+// Every actor of this version must have a successor in the manifest, which must already be in the store.
+func (a Actor) UpgradeActors(rt runtime.Runtime, params *UpgradeActorsParams) *abi.EmptyValue {
+	rt.ValidateImmediateCallerIs(builtin.SystemActorAddr)
+
+	store := adt.AsStore(rt)
+	m, err := manifest.Load(store.Context(), store, params.Manifest)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "failed to load manifest")
+	codes, err := m.Codes(store.Context(), store)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "failed to load manifest codes")
+
+	mapping, err := manifest.CodeMapping(manifest.BuiltinCodes(), codes)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "manifest is incomplete")
+
+	rt.ReplaceActorCodes(mapping)
+	return nil
+}
+
 type State struct{}
@@ -3,11 +3,15 @@ package system_test
 import (
 	"testing"
 
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/ipfs/go-cid"
 	"github.com/stretchr/testify/require"
 
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin/manifest"
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin/system"
 	"github.com/filecoin-project/specs-actors/v3/support/mock"
+	tutil "github.com/filecoin-project/specs-actors/v3/support/testing"
 )
 
 func TestExports(t *testing.T) {
@@ -28,3 +32,70 @@ func TestConstruction(t *testing.T) {
 
 	require.Equal(t, system.State{}, st)
 }
+
+func TestUpgradeActors(t *testing.T) {
+	a := system.Actor{}
+	builder := mock.NewBuilder(builtin.SystemActorAddr)
+
+	// Codes for a hypothetical next version of every builtin actor.
+	nextCodes := func() map[string]cid.Cid {
+		codes := map[string]cid.Cid{}
+		for name := range manifest.BuiltinCodes() { //nolint:nomaprange
+			codes[name] = tutil.MakeCID("fil/4/"+name, nil)
+		}
+		return codes
+	}
+
+	t.Run("replaces codes from manifest", func(t *testing.T) {
+		rt := builder.Build(t)
+		codes := nextCodes()
+		root, err := manifest.Build(rt.Context(), rt.AdtStore(), 4, codes)
+		require.NoError(t, err)
+
+		expected := map[cid.Cid]cid.Cid{}
+		for name, code := range manifest.BuiltinCodes() { //nolint:nomaprange
+			expected[code] = codes[name]
+		}
+
+		rt.SetCaller(builtin.SystemActorAddr, builtin.SystemActorCodeID)
+		rt.ExpectValidateCallerAddr(builtin.SystemActorAddr)
+		rt.ExpectReplaceActorCodes(expected)
+		rt.Call(a.UpgradeActors, &system.UpgradeActorsParams{Manifest: root})
+		rt.Verify()
+	})
+
+	t.Run("fails if manifest lacks an actor", func(t *testing.T) {
+		rt := builder.Build(t)
+		codes := nextCodes()
+		delete(codes, manifest.MinerKey)
+		root, err := manifest.Build(rt.Context(), rt.AdtStore(), 4, codes)
+		require.NoError(t, err)
+
+		rt.SetCaller(builtin.SystemActorAddr, builtin.SystemActorCodeID)
+		rt.ExpectValidateCallerAddr(builtin.SystemActorAddr)
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			rt.Call(a.UpgradeActors, &system.UpgradeActorsParams{Manifest: root})
+		})
+		rt.Verify()
+	})
+
+	t.Run("fails if manifest is not in store", func(t *testing.T) {
+		rt := builder.Build(t)
+		rt.SetCaller(builtin.SystemActorAddr, builtin.SystemActorCodeID)
+		rt.ExpectValidateCallerAddr(builtin.SystemActorAddr)
+		rt.ExpectAbort(exitcode.ErrNotFound, func() {
+			rt.Call(a.UpgradeActors, &system.UpgradeActorsParams{Manifest: tutil.MakeCID("missing", nil)})
+		})
+		rt.Verify()
+	})
+
+	t.Run("rejects other callers", func(t *testing.T) {
+		rt := builder.Build(t)
+		rt.SetCaller(builtin.InitActorAddr, builtin.InitActorCodeID)
+		rt.ExpectValidateCallerAddr(builtin.SystemActorAddr)
+		rt.ExpectAbort(exitcode.SysErrForbidden, func() {
+			rt.Call(a.UpgradeActors, &system.UpgradeActorsParams{Manifest: tutil.MakeCID("any", nil)})
+		})
+		rt.Verify()
+	})
+}
@@ -10,8 +10,10 @@ import (
 )
 
 type StateSummary struct {
-	Verifiers map[addr.Address]DataCap
-	Clients   map[addr.Address]DataCap
+	Verifiers   map[addr.Address]DataCap
+	Clients     map[addr.Address]DataCap
+	Allocations map[AllocationId]Allocation
+	Claims      map[ClaimId]Claim
 }
 
 // Checks internal invariants of verified registry state.
@@ -65,8 +67,66 @@ func CheckStateInvariants(st *State, store adt.Store) (*StateSummary, *builtin.M
 	}
 	// No need to iterate all clients; any overlap must have been one of all verifiers.
 
+	// Check removal proposal ids
+	if proposalIDs, err := adt.AsMap(store, st.RemoveDataCapProposalIDs, builtin.DefaultHamtBitwidth); err != nil {
+		acc.Addf("error loading removal proposal ids: %v", err)
+	} else {
+		var id RmDcProposalID
+		err = proposalIDs.ForEach(&id, func(key string) error {
+			acc.Require(id.ProposalID > 0, "removal proposal id for key %x is zero", key)
+			return nil
+		})
+		acc.RequireNoError(err, "error iterating removal proposal ids")
+	}
+
+	// Check allocations
+	allAllocations := map[AllocationId]Allocation{}
+	if allocations, err := adt.AsMap(store, st.Allocations, builtin.DefaultHamtBitwidth); err != nil {
+		acc.Addf("error loading allocations: %v", err)
+	} else {
+		var alloc Allocation
+		err = allocations.ForEach(&alloc, func(key string) error {
+			id, err := abi.ParseUIntKey(key)
+			if err != nil {
+				return err
+			}
+			acc.Require(AllocationId(id) < st.NextAllocationId, "allocation id %d not less than next id %d", id, st.NextAllocationId)
+			acc.Require(alloc.Client.Protocol() == addr.ID, "allocation %d client %v should have ID protocol", id, alloc.Client)
+			acc.Require(alloc.Provider.Protocol() == addr.ID, "allocation %d provider %v should have ID protocol", id, alloc.Provider)
+			acc.Require(alloc.TermMin <= alloc.TermMax, "allocation %d term min %d exceeds term max %d", id, alloc.TermMin, alloc.TermMax)
+			allAllocations[AllocationId(id)] = alloc
+			return nil
+		})
+		acc.RequireNoError(err, "error iterating allocations")
+	}
+
+	// Check claims
+	allClaims := map[ClaimId]Claim{}
+	if claims, err := adt.AsMap(store, st.Claims, builtin.DefaultHamtBitwidth); err != nil {
+		acc.Addf("error loading claims: %v", err)
+	} else {
+		var claim Claim
+		err = claims.ForEach(&claim, func(key string) error {
+			id, err := abi.ParseUIntKey(key)
+			if err != nil {
+				return err
+			}
+			acc.Require(id < uint64(st.NextAllocationId), "claim id %d not less than next allocation id %d", id, st.NextAllocationId)
+			acc.Require(claim.Client.Protocol() == addr.ID, "claim %d client %v should have ID protocol", id, claim.Client)
+			acc.Require(claim.Provider.Protocol() == addr.ID, "claim %d provider %v should have ID protocol", id, claim.Provider)
+			acc.Require(claim.TermMin <= claim.TermMax, "claim %d term min %d exceeds term max %d", id, claim.TermMin, claim.TermMax)
+			_, allocated := allAllocations[AllocationId(id)]
+			acc.Require(!allocated, "claim %d is also an allocation", id)
+			allClaims[ClaimId(id)] = claim
+			return nil
+		})
+		acc.RequireNoError(err, "error iterating claims")
+	}
+
 	return &StateSummary{
-		Verifiers: allVerifiers,
-		Clients:   allClients,
+		Verifiers:   allVerifiers,
+		Clients:     allClients,
+		Allocations: allAllocations,
+		Claims:      allClaims,
 	}, acc
 }
@@ -1,13 +1,17 @@
 package verifreg
 
 import (
+	"bytes"
+
 	addr "github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/cbor"
+	"github.com/filecoin-project/go-state-types/crypto"
 	"github.com/ipfs/go-cid"
 
 	"github.com/filecoin-project/go-state-types/big"
 	"github.com/filecoin-project/go-state-types/exitcode"
+	market0 "github.com/filecoin-project/specs-actors/actors/builtin/market"
 	verifreg0 "github.com/filecoin-project/specs-actors/actors/builtin/verifreg"
 
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
@@ -25,6 +29,14 @@ func (a Actor) Exports() []interface{} {
 		4:                         a.AddVerifiedClient,
 		5:                         a.UseBytes,
 		6:                         a.RestoreBytes,
+		7:                         a.RemoveVerifiedClientDataCap,
+		8:                         a.CreateAllocations,
+		9:                         a.ClaimAllocations,
+		10:                        a.ExtendClaimTerms,
+		11:                        a.RemoveExpiredAllocations,
+		12:                        a.RemoveExpiredClaims,
+		13:                        a.AddVerifierAllowance,
+		14:                        a.ReduceVerifierAllowance,
 	}
 }
 
@@ -73,14 +85,13 @@ func (a Actor) AddVerifier(rt runtime.Runtime, params *AddVerifierParams) *abi.E
 	verifier, err := builtin.ResolveToIDAddr(rt, params.Address)
 	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to resolve verifier address %v to ID address", params.Address)
 
-	var st State
-	rt.StateReadonly(&st)
+	st := ReadState(rt)
 	rt.ValidateImmediateCallerIs(st.RootKey)
 
 	if verifier == st.RootKey {
 		rt.Abortf(exitcode.ErrIllegalArgument, "Rootkey cannot be added as verifier")
 	}
-	rt.StateTransaction(&st, func() {
+	WithState(rt, func(st *State) {
 		verifiers, err := adt.AsMap(adt.AsStore(rt), st.Verifiers, builtin.DefaultHamtBitwidth)
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load verifiers")
 
@@ -108,11 +119,10 @@ func (a Actor) RemoveVerifier(rt runtime.Runtime, verifierAddr *addr.Address) *a
 	verifier, err := builtin.ResolveToIDAddr(rt, *verifierAddr)
 	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to resolve verifier address %v to ID address", *verifierAddr)
 
-	var st State
-	rt.StateReadonly(&st)
+	st := ReadState(rt)
 	rt.ValidateImmediateCallerIs(st.RootKey)
 
-	rt.StateTransaction(&st, func() {
+	WithState(rt, func(st *State) {
 		verifiers, err := adt.AsMap(adt.AsStore(rt), st.Verifiers, builtin.DefaultHamtBitwidth)
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load verifiers")
 
@@ -127,6 +137,49 @@ func (a Actor) RemoveVerifier(rt runtime.Runtime, verifierAddr *addr.Address) *a
 	return nil
 }
 
+type AddVerifierAllowanceParams struct {
+	Address  addr.Address
+	Increase DataCap
+}
+
+// Increases the remaining allowance of an existing verifier.
+// Unlike removing and re-adding the verifier, this retains the verifier's other state.
+func (a Actor) AddVerifierAllowance(rt runtime.Runtime, params *AddVerifierAllowanceParams) *abi.EmptyValue {
+	st := ReadState(rt)
+	rt.ValidateImmediateCallerIs(st.RootKey)
+
+	builtin.RequireParam(rt, params.Increase.GreaterThan(big.Zero()), "allowance increase %v must be positive", params.Increase)
+	verifier, ok := rt.ResolveAddress(params.Address)
+	if !ok {
+		rt.Abortf(exitcode.ErrNotFound, "no such verifier %v", params.Address)
+	}
+
+	adjustVerifierAllowance(rt, verifier, params.Increase)
+	return nil
+}
+
+type ReduceVerifierAllowanceParams struct {
+	Address   addr.Address
+	Reduction DataCap
+}
+
+// Reduces the remaining allowance of an existing verifier.
+// Allowance already granted to verified clients is not affected, so the reduction may not exceed
+// the verifier's remaining allowance.
+func (a Actor) ReduceVerifierAllowance(rt runtime.Runtime, params *ReduceVerifierAllowanceParams) *abi.EmptyValue {
+	st := ReadState(rt)
+	rt.ValidateImmediateCallerIs(st.RootKey)
+
+	builtin.RequireParam(rt, params.Reduction.GreaterThan(big.Zero()), "allowance reduction %v must be positive", params.Reduction)
+	verifier, ok := rt.ResolveAddress(params.Address)
+	if !ok {
+		rt.Abortf(exitcode.ErrNotFound, "no such verifier %v", params.Address)
+	}
+
+	adjustVerifierAllowance(rt, verifier, params.Reduction.Neg())
+	return nil
+}
+
 //type AddVerifiedClientParams struct {
 //	Address   addr.Address
 //	Allowance DataCap
@@ -144,13 +197,12 @@ func (a Actor) AddVerifiedClient(rt runtime.Runtime, params *AddVerifiedClientPa
 	client, err := builtin.ResolveToIDAddr(rt, params.Address)
 	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to resolve verified client address %v", params.Address)
 
-	var st State
-	rt.StateReadonly(&st)
+	st := ReadState(rt)
 	if st.RootKey == client {
 		rt.Abortf(exitcode.ErrIllegalArgument, "Rootkey cannot be added as a verified client")
 	}
 
-	rt.StateTransaction(&st, func() {
+	WithState(rt, func(st *State) {
 		verifiers, err := adt.AsMap(adt.AsStore(rt), st.Verifiers, builtin.DefaultHamtBitwidth)
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load verifiers")
 
@@ -224,36 +276,11 @@ func (a Actor) UseBytes(rt runtime.Runtime, params *UseBytesParams) *abi.EmptyVa
 		rt.Abortf(exitcode.ErrIllegalArgument, "VerifiedDealSize: %d below minimum in UseBytes", params.DealSize)
 	}
 
-	var st State
-	rt.StateTransaction(&st, func() {
+	WithState(rt, func(st *State) {
 		verifiedClients, err := adt.AsMap(adt.AsStore(rt), st.VerifiedClients, builtin.DefaultHamtBitwidth)
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load verified clients")
 
-		var vcCap DataCap
-		found, err := verifiedClients.Get(abi.AddrKey(client), &vcCap)
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get verified client %v", client)
-		if !found {
-			rt.Abortf(exitcode.ErrNotFound, "no such verified client %v", client)
-		}
-		builtin.RequireState(rt, vcCap.GreaterThanEqual(big.Zero()), "negative cap for client %v: %v", client, vcCap)
-
-		if params.DealSize.GreaterThan(vcCap) {
-			rt.Abortf(exitcode.ErrIllegalArgument, "DealSize %d exceeds allowable cap: %d for VerifiedClient %v", params.DealSize, vcCap, client)
-		}
-
-		newVcCap := big.Sub(vcCap, params.DealSize)
-		if newVcCap.LessThan(MinVerifiedDealSize) {
-			// Delete entry if remaining DataCap is less than MinVerifiedDealSize.
-			// Will be restored later if the deal did not get activated with a ProvenSector.
-			//
-			// NOTE: Technically, client could lose up to MinVerifiedDealSize worth of DataCap.
-			// See: https://github.com/filecoin-project/specs-actors/issues/727
-			err = verifiedClients.Delete(abi.AddrKey(client))
-			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to delete verified client %v", client)
-		} else {
-			err = verifiedClients.Put(abi.AddrKey(client), &newVcCap)
-			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to update verified client %v with %v", client, newVcCap)
-		}
+		useClientCap(rt, verifiedClients, client, params.DealSize)
 
 		st.VerifiedClients, err = verifiedClients.Root()
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush verified clients")
@@ -280,40 +307,551 @@ func (a Actor) RestoreBytes(rt runtime.Runtime, params *RestoreBytesParams) *abi
 	client, err := builtin.ResolveToIDAddr(rt, params.Address)
 	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to resolve verified client addr %v", params.Address)
 
-	var st State
-	rt.StateReadonly(&st)
+	st := ReadState(rt)
 	if st.RootKey == client {
 		rt.Abortf(exitcode.ErrIllegalArgument, "Cannot restore allowance for Rootkey")
 	}
 
-	rt.StateTransaction(&st, func() {
+	WithState(rt, func(st *State) {
 		verifiedClients, err := adt.AsMap(adt.AsStore(rt), st.VerifiedClients, builtin.DefaultHamtBitwidth)
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load verified clients")
 
 		verifiers, err := adt.AsMap(adt.AsStore(rt), st.Verifiers, builtin.DefaultHamtBitwidth)
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load verifiers")
 
-		// validate we are NOT attempting to do this for a verifier
-		found, err := verifiers.Get(abi.AddrKey(client), nil)
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed tp get verifier")
-		if found {
-			rt.Abortf(exitcode.ErrIllegalArgument, "cannot restore allowance for a verifier")
+		restoreClientCap(rt, verifiers, verifiedClients, client, params.DealSize)
+
+		st.VerifiedClients, err = verifiedClients.Root()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load verifiers")
+	})
+
+	return nil
+}
+
+// Prefix of the message signed by a verifier to approve the removal of a client's DataCap.
+const SignatureDomainSeparation_RemoveDataCap = "fil_removedatacap:"
+
+// Number of distinct verifiers who must sign a proposal to remove a client's DataCap.
+const RemoveDataCapVerifierThreshold = 2
+
+type RemoveDataCapProposal struct {
+	VerifiedClient    addr.Address
+	DataCapAmount     DataCap
+	RemovalProposalID RmDcProposalID
+}
+
+type RemoveDataCapRequest struct {
+	Verifier          addr.Address
+	VerifierSignature crypto.Signature
+}
+
+type RemoveDataCapParams struct {
+	VerifiedClientToRemove addr.Address
+	DataCapAmountToRemove  DataCap
+	VerifierRequests       []RemoveDataCapRequest
+}
+
+type RemoveDataCapReturn struct {
+	VerifiedClient addr.Address
+	DataCapRemoved DataCap
+}
+
+// Removes up to the requested amount of DataCap from a verified client, as decided by governance.
+// The root key must send the message, carrying signatures from at least RemoveDataCapVerifierThreshold
+// distinct verifiers over a RemoveDataCapProposal for the client. Each proposal embeds the signing verifier's
+// next proposal ID for the client, which is consumed here so that signatures cannot be replayed.
+func (a Actor) RemoveVerifiedClientDataCap(rt runtime.Runtime, params *RemoveDataCapParams) *RemoveDataCapReturn {
+	st := ReadState(rt)
+	rt.ValidateImmediateCallerIs(st.RootKey)
+
+	client, err := builtin.ResolveToIDAddr(rt, params.VerifiedClientToRemove)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to resolve client address %v to ID address", params.VerifiedClientToRemove)
+
+	builtin.RequireParam(rt, params.DataCapAmountToRemove.GreaterThan(big.Zero()), "amount to remove %v must be positive", params.DataCapAmountToRemove)
+	builtin.RequireParam(rt, len(params.VerifierRequests) >= RemoveDataCapVerifierThreshold,
+		"removal requires %d verifier signatures, got %d", RemoveDataCapVerifierThreshold, len(params.VerifierRequests))
+
+	verifiers := make([]addr.Address, 0, len(params.VerifierRequests))
+	for _, req := range params.VerifierRequests {
+		verifier, err := builtin.ResolveToIDAddr(rt, req.Verifier)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to resolve verifier address %v to ID address", req.Verifier)
+		for _, seen := range verifiers {
+			builtin.RequireParam(rt, seen != verifier, "duplicate request from verifier %v", verifier)
 		}
+		verifiers = append(verifiers, verifier)
+	}
+
+	var removed DataCap
+	WithState(rt, func(st *State) {
+		verifierMap, err := adt.AsMap(adt.AsStore(rt), st.Verifiers, builtin.DefaultHamtBitwidth)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load verifiers")
+
+		verifiedClients, err := adt.AsMap(adt.AsStore(rt), st.VerifiedClients, builtin.DefaultHamtBitwidth)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load verified clients")
+
+		proposalIDs, err := adt.AsMap(adt.AsStore(rt), st.RemoveDataCapProposalIDs, builtin.DefaultHamtBitwidth)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load datacap removal proposal ids")
 
-		var vcCap DataCap
-		found, err = verifiedClients.Get(abi.AddrKey(client), &vcCap)
+		var clientCap DataCap
+		found, err := verifiedClients.Get(abi.AddrKey(client), &clientCap)
 		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get verified client %v", client)
 		if !found {
-			vcCap = big.Zero()
+			rt.Abortf(exitcode.ErrNotFound, "%v is not a verified client", client)
 		}
 
-		newVcCap := big.Add(vcCap, params.DealSize)
-		err = verifiedClients.Put(abi.AddrKey(client), &newVcCap)
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to put verified client %v with %v", client, newVcCap)
+		for _, verifier := range verifiers {
+			found, err := verifierMap.Get(abi.AddrKey(verifier), nil)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get verifier %v", verifier)
+			if !found {
+				rt.Abortf(exitcode.ErrNotFound, "%v is not a verifier", verifier)
+			}
+		}
+		for i, verifier := range verifiers {
+			useProposalID(rt, proposalIDs, verifier, client, params.DataCapAmountToRemove, params.VerifierRequests[i].VerifierSignature)
+		}
+
+		removed = big.Min(clientCap, params.DataCapAmountToRemove)
+		newCap := big.Sub(clientCap, removed)
+		if newCap.LessThan(MinVerifiedDealSize) {
+			// As in UseBytes, a client whose remaining DataCap can't fund a verified deal is removed.
+			err = verifiedClients.Delete(abi.AddrKey(client))
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to delete verified client %v", client)
+		} else {
+			err = verifiedClients.Put(abi.AddrKey(client), &newCap)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to update verified client %v with %v", client, newCap)
+		}
 
 		st.VerifiedClients, err = verifiedClients.Root()
-		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load verifiers")
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush verified clients")
+
+		st.RemoveDataCapProposalIDs, err = proposalIDs.Root()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush datacap removal proposal ids")
+	})
+
+	return &RemoveDataCapReturn{
+		VerifiedClient: client,
+		DataCapRemoved: removed,
+	}
+}
+
+type AllocationRequest struct {
+	Provider   addr.Address        // The provider (miner actor) which may claim the allocation
+	Data       cid.Cid             `checked:"true"` // Piece CID of the data to be stored, checked in validateAllocation
+	Size       abi.PaddedPieceSize // Size of the data
+	TermMin    abi.ChainEpoch      // Minimum duration for which the provider must commit to store the data
+	TermMax    abi.ChainEpoch      // Maximum duration for which the data may be stored as verified
+	Expiration abi.ChainEpoch      // Epoch after which the allocation can no longer be claimed
+}
+
+type CreateAllocationsParams struct {
+	Allocations []AllocationRequest
+}
+
+type CreateAllocationsReturn struct {
+	AllocationIds []AllocationId
+}
+
+// Allocates the calling verified client's DataCap to specific pieces of data, each to be claimed by a designated
+// provider when it commits a sector holding the data.
+// The DataCap for all the allocations is deducted from the client's allowance immediately, and returned to the client
+// if an allocation expires unclaimed.
+func (a Actor) CreateAllocations(rt runtime.Runtime, params *CreateAllocationsParams) *CreateAllocationsReturn {
+	rt.ValidateImmediateCallerType(builtin.CallerTypesSignable...)
+	client := rt.Caller()
+	builtin.RequireParam(rt, len(params.Allocations) > 0, "no allocations requested")
+
+	allocs := make([]Allocation, len(params.Allocations))
+	total := big.Zero()
+	for i, req := range params.Allocations {
+		provider, err := builtin.ResolveToIDAddr(rt, req.Provider)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to resolve provider address %v", req.Provider)
+		code, ok := rt.GetActorCodeCID(provider)
+		builtin.RequireParam(rt, ok && code.Equals(builtin.StorageMinerActorCodeID), "provider %v is not a miner", provider)
+
+		allocs[i] = Allocation{
+			Client:     client,
+			Provider:   provider,
+			Data:       req.Data,
+			Size:       req.Size,
+			TermMin:    req.TermMin,
+			TermMax:    req.TermMax,
+			Expiration: req.Expiration,
+		}
+		validateAllocation(rt, &allocs[i])
+		total = big.Add(total, abi.NewStoragePower(int64(req.Size)))
+	}
+
+	ids := make([]AllocationId, len(allocs))
+	WithState(rt, func(st *State) {
+		verifiedClients, err := adt.AsMap(adt.AsStore(rt), st.VerifiedClients, builtin.DefaultHamtBitwidth)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load verified clients")
+
+		allocations, err := adt.AsMap(adt.AsStore(rt), st.Allocations, builtin.DefaultHamtBitwidth)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load allocations")
+
+		useClientCap(rt, verifiedClients, client, total)
+
+		for i := range allocs {
+			ids[i] = st.NextAllocationId
+			st.NextAllocationId++
+			err = allocations.Put(ids[i], &allocs[i])
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to put allocation %d", ids[i])
+		}
+
+		st.VerifiedClients, err = verifiedClients.Root()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush verified clients")
+
+		st.Allocations, err = allocations.Root()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush allocations")
+	})
+
+	return &CreateAllocationsReturn{AllocationIds: ids}
+}
+
+// Checks the terms of a new allocation against policy.
+func validateAllocation(rt runtime.Runtime, alloc *Allocation) {
+	builtin.RequireParam(rt, alloc.Data.Defined(), "undefined data CID for allocation")
+	builtin.RequireParam(rt, alloc.Data.Prefix() == market0.PieceCIDPrefix, "data CID %v is not a piece CID", alloc.Data)
+	builtin.RequireParam(rt, abi.NewStoragePower(int64(alloc.Size)).GreaterThanEqual(MinVerifiedDealSize),
+		"allocation size %d below minimum %v", alloc.Size, MinVerifiedDealSize)
+	builtin.RequireParam(rt, alloc.TermMin >= MinimumVerifiedAllocationTerm,
+		"allocation term min %d below limit %d", alloc.TermMin, MinimumVerifiedAllocationTerm)
+	builtin.RequireParam(rt, alloc.TermMax <= MaximumVerifiedAllocationTerm,
+		"allocation term max %d above limit %d", alloc.TermMax, MaximumVerifiedAllocationTerm)
+	builtin.RequireParam(rt, alloc.TermMin <= alloc.TermMax,
+		"allocation term min %d exceeds term max %d", alloc.TermMin, alloc.TermMax)
+	builtin.RequireParam(rt, alloc.Expiration > rt.CurrEpoch(),
+		"allocation expiration %d must be after current epoch %d", alloc.Expiration, rt.CurrEpoch())
+	builtin.RequireParam(rt, alloc.Expiration <= rt.CurrEpoch()+MaximumVerifiedAllocationExpiration,
+		"allocation expiration %d exceeds maximum %d", alloc.Expiration, rt.CurrEpoch()+MaximumVerifiedAllocationExpiration)
+}
+
+type SectorAllocationClaim struct {
+	AllocationId AllocationId
+	Data         cid.Cid             `checked:"true"` // Piece CID of the data committed to the sector, which must match the allocation
+	Size         abi.PaddedPieceSize // Size of the data committed to the sector, which must match the allocation
+	Sector       abi.SectorNumber
+	SectorExpiry abi.ChainEpoch
+}
+
+type ClaimAllocationsParams struct {
+	Sectors []SectorAllocationClaim
+}
+
+type ClaimAllocationsReturn struct {
+	ClaimedSpace abi.StoragePower // Total size of the claimed data
+}
+
+// Called by a miner actor when activating sectors holding allocated data, converting each allocation to a claim
+// with the same identifier. The sector must be committed for at least the allocation's minimum term, and not beyond
+// its maximum term.
+func (a Actor) ClaimAllocations(rt runtime.Runtime, params *ClaimAllocationsParams) *ClaimAllocationsReturn {
+	rt.ValidateImmediateCallerType(builtin.StorageMinerActorCodeID)
+	provider := rt.Caller()
+	builtin.RequireParam(rt, len(params.Sectors) > 0, "no allocations to claim")
+
+	claimedSpace := big.Zero()
+	WithState(rt, func(st *State) {
+		allocations, err := adt.AsMap(adt.AsStore(rt), st.Allocations, builtin.DefaultHamtBitwidth)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load allocations")
+
+		claims, err := adt.AsMap(adt.AsStore(rt), st.Claims, builtin.DefaultHamtBitwidth)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load claims")
+
+		for _, sc := range params.Sectors {
+			builtin.RequireParam(rt, sc.Sector <= abi.MaxSectorNumber, "sector number %d out of range", sc.Sector)
+			builtin.RequireParam(rt, sc.SectorExpiry > rt.CurrEpoch(),
+				"sector %d expiration %d must be after current epoch %d", sc.Sector, sc.SectorExpiry, rt.CurrEpoch())
+
+			var alloc Allocation
+			found, err := allocations.Get(sc.AllocationId, &alloc)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get allocation %d", sc.AllocationId)
+			if !found {
+				rt.Abortf(exitcode.ErrNotFound, "no such allocation %d", sc.AllocationId)
+			}
+
+			if alloc.Provider != provider {
+				rt.Abortf(exitcode.ErrForbidden, "allocation %d is for provider %v, not %v", sc.AllocationId, alloc.Provider, provider)
+			}
+			builtin.RequireParam(rt, sc.Data.Equals(alloc.Data), "data %v does not match allocation %d data %v", sc.Data, sc.AllocationId, alloc.Data)
+			builtin.RequireParam(rt, sc.Size == alloc.Size, "size %d does not match allocation %d size %d", sc.Size, sc.AllocationId, alloc.Size)
+			builtin.RequireParam(rt, rt.CurrEpoch() <= alloc.Expiration, "allocation %d expired at %d", sc.AllocationId, alloc.Expiration)
+			builtin.RequireParam(rt, sc.SectorExpiry >= rt.CurrEpoch()+alloc.TermMin,
+				"sector %d expiration %d before allocation %d term min %d", sc.Sector, sc.SectorExpiry, sc.AllocationId, alloc.TermMin)
+			builtin.RequireParam(rt, sc.SectorExpiry <= rt.CurrEpoch()+alloc.TermMax,
+				"sector %d expiration %d after allocation %d term max %d", sc.Sector, sc.SectorExpiry, sc.AllocationId, alloc.TermMax)
+
+			err = allocations.Delete(sc.AllocationId)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to delete allocation %d", sc.AllocationId)
+
+			claim := Claim{
+				Provider:  provider,
+				Client:    alloc.Client,
+				Data:      alloc.Data,
+				Size:      alloc.Size,
+				TermMin:   alloc.TermMin,
+				TermMax:   alloc.TermMax,
+				TermStart: rt.CurrEpoch(),
+				Sector:    sc.Sector,
+			}
+			err = claims.Put(ClaimId(sc.AllocationId), &claim)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to put claim %d", sc.AllocationId)
+			claimedSpace = big.Add(claimedSpace, abi.NewStoragePower(int64(alloc.Size)))
+		}
+
+		st.Allocations, err = allocations.Root()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush allocations")
+
+		st.Claims, err = claims.Root()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush claims")
+	})
+
+	return &ClaimAllocationsReturn{ClaimedSpace: claimedSpace}
+}
+
+type ClaimTerm struct {
+	ClaimId ClaimId
+	TermMax abi.ChainEpoch // New maximum term, from the claim's start
+}
+
+type ExtendClaimTermsParams struct {
+	Terms []ClaimTerm
+}
+
+// Extends the maximum terms of claims of the calling client's allocations, up to the policy maximum.
+// Claims which have reached the end of their term cannot be extended.
+func (a Actor) ExtendClaimTerms(rt runtime.Runtime, params *ExtendClaimTermsParams) *abi.EmptyValue {
+	rt.ValidateImmediateCallerType(builtin.CallerTypesSignable...)
+	client := rt.Caller()
+	builtin.RequireParam(rt, len(params.Terms) > 0, "no claim terms to extend")
+
+	WithState(rt, func(st *State) {
+		claims, err := adt.AsMap(adt.AsStore(rt), st.Claims, builtin.DefaultHamtBitwidth)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load claims")
+
+		for _, term := range params.Terms {
+			var claim Claim
+			found, err := claims.Get(term.ClaimId, &claim)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get claim %d", term.ClaimId)
+			if !found {
+				rt.Abortf(exitcode.ErrNotFound, "no such claim %d", term.ClaimId)
+			}
+
+			if claim.Client != client {
+				rt.Abortf(exitcode.ErrForbidden, "claim %d is for client %v, not %v", term.ClaimId, claim.Client, client)
+			}
+			builtin.RequireParam(rt, term.TermMax >= claim.TermMax,
+				"new term max %d for claim %d is less than current %d", term.TermMax, term.ClaimId, claim.TermMax)
+			builtin.RequireParam(rt, term.TermMax <= MaximumVerifiedAllocationTerm,
+				"new term max %d for claim %d above limit %d", term.TermMax, term.ClaimId, MaximumVerifiedAllocationTerm)
+			builtin.RequireParam(rt, claim.TermEnd() >= rt.CurrEpoch(), "claim %d expired at %d", term.ClaimId, claim.TermEnd())
+
+			claim.TermMax = term.TermMax
+			err = claims.Put(term.ClaimId, &claim)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to put claim %d", term.ClaimId)
+		}
+
+		st.Claims, err = claims.Root()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush claims")
 	})
 
 	return nil
 }
+
+type RemoveExpiredAllocationsParams struct {
+	AllocationIds []AllocationId
+}
+
+type RemoveExpiredAllocationsReturn struct {
+	Removed          []AllocationId // The allocations removed, a subset of those requested
+	DataCapRecovered DataCap        // DataCap returned to the allocations' clients
+}
+
+// Removes allocations which expired unclaimed, returning their DataCap to the clients which made them.
+// Allocations which are not found or have not expired are skipped.
+// May be called by anyone.
+func (a Actor) RemoveExpiredAllocations(rt runtime.Runtime, params *RemoveExpiredAllocationsParams) *RemoveExpiredAllocationsReturn {
+	rt.ValidateImmediateCallerAcceptAny()
+
+	ret := RemoveExpiredAllocationsReturn{DataCapRecovered: big.Zero()}
+	WithState(rt, func(st *State) {
+		allocations, err := adt.AsMap(adt.AsStore(rt), st.Allocations, builtin.DefaultHamtBitwidth)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load allocations")
+
+		verifiers, err := adt.AsMap(adt.AsStore(rt), st.Verifiers, builtin.DefaultHamtBitwidth)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load verifiers")
+
+		verifiedClients, err := adt.AsMap(adt.AsStore(rt), st.VerifiedClients, builtin.DefaultHamtBitwidth)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load verified clients")
+
+		for _, id := range params.AllocationIds {
+			var alloc Allocation
+			found, err := allocations.Get(id, &alloc)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get allocation %d", id)
+			if !found || alloc.Expiration >= rt.CurrEpoch() {
+				continue
+			}
+
+			err = allocations.Delete(id)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to delete allocation %d", id)
+
+			size := abi.NewStoragePower(int64(alloc.Size))
+			restoreClientCap(rt, verifiers, verifiedClients, alloc.Client, size)
+			ret.Removed = append(ret.Removed, id)
+			ret.DataCapRecovered = big.Add(ret.DataCapRecovered, size)
+		}
+
+		st.Allocations, err = allocations.Root()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush allocations")
+
+		st.VerifiedClients, err = verifiedClients.Root()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush verified clients")
+	})
+
+	return &ret
+}
+
+type RemoveExpiredClaimsParams struct {
+	ClaimIds []ClaimId
+}
+
+type RemoveExpiredClaimsReturn struct {
+	Removed []ClaimId // The claims removed, a subset of those requested
+}
+
+// Removes claims which have passed the end of their maximum term.
+// Claims which are not found or have not reached the end of their term are skipped.
+// May be called by anyone.
+func (a Actor) RemoveExpiredClaims(rt runtime.Runtime, params *RemoveExpiredClaimsParams) *RemoveExpiredClaimsReturn {
+	rt.ValidateImmediateCallerAcceptAny()
+
+	var ret RemoveExpiredClaimsReturn
+	WithState(rt, func(st *State) {
+		claims, err := adt.AsMap(adt.AsStore(rt), st.Claims, builtin.DefaultHamtBitwidth)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load claims")
+
+		for _, id := range params.ClaimIds {
+			var claim Claim
+			found, err := claims.Get(id, &claim)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get claim %d", id)
+			if !found || claim.TermEnd() >= rt.CurrEpoch() {
+				continue
+			}
+
+			err = claims.Delete(id)
+			builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to delete claim %d", id)
+			ret.Removed = append(ret.Removed, id)
+		}
+
+		st.Claims, err = claims.Root()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush claims")
+	})
+
+	return &ret
+}
+
+// Verifies a verifier's signature over a removal proposal carrying its current proposal ID for the client,
+// then increments that ID.
+func useProposalID(rt runtime.Runtime, proposalIDs *adt.Map, verifier, client addr.Address, amount DataCap, sig crypto.Signature) {
+	key := NewAddrPairKey(verifier, client)
+	var id RmDcProposalID
+	_, err := proposalIDs.Get(key, &id)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get removal proposal id for verifier %v and client %v", verifier, client)
+
+	proposal := RemoveDataCapProposal{
+		VerifiedClient:    client,
+		DataCapAmount:     amount,
+		RemovalProposalID: id,
+	}
+	buf := bytes.Buffer{}
+	buf.WriteString(SignatureDomainSeparation_RemoveDataCap)
+	err = proposal.MarshalCBOR(&buf)
+	builtin.RequireNoErr(rt, err, exitcode.ErrSerialization, "failed to serialize removal proposal")
+
+	err = rt.VerifySignature(sig, verifier, buf.Bytes())
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "invalid signature on removal proposal from verifier %v", verifier)
+
+	next := RmDcProposalID{ProposalID: id.ProposalID + 1}
+	err = proposalIDs.Put(key, &next)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to update removal proposal id for verifier %v and client %v", verifier, client)
+}
+
+// Deducts DataCap from a verified client's allowance.
+// Do not allow partial use (the amount must not exceed the client's cap).
+// Deletes the client if its remaining DataCap is smaller than the minimum verified deal size.
+func useClientCap(rt runtime.Runtime, verifiedClients *adt.Map, client addr.Address, amount DataCap) {
+	var vcCap DataCap
+	found, err := verifiedClients.Get(abi.AddrKey(client), &vcCap)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get verified client %v", client)
+	if !found {
+		rt.Abortf(exitcode.ErrNotFound, "no such verified client %v", client)
+	}
+	builtin.RequireState(rt, vcCap.GreaterThanEqual(big.Zero()), "negative cap for client %v: %v", client, vcCap)
+
+	if amount.GreaterThan(vcCap) {
+		rt.Abortf(exitcode.ErrIllegalArgument, "DealSize %d exceeds allowable cap: %d for VerifiedClient %v", amount, vcCap, client)
+	}
+
+	newVcCap := big.Sub(vcCap, amount)
+	if newVcCap.LessThan(MinVerifiedDealSize) {
+		// Delete entry if remaining DataCap is less than MinVerifiedDealSize.
+		// Will be restored later if the deal did not get activated with a ProvenSector.
+		//
+		// NOTE: Technically, client could lose up to MinVerifiedDealSize worth of DataCap.
+		// See: https://github.com/filecoin-project/specs-actors/issues/727
+		err = verifiedClients.Delete(abi.AddrKey(client))
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to delete verified client %v", client)
+	} else {
+		err = verifiedClients.Put(abi.AddrKey(client), &newVcCap)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to update verified client %v with %v", client, newVcCap)
+	}
+}
+
+// Returns DataCap to a verified client, creating a new entry if the client has been deleted.
+// The client must not be a verifier.
+func restoreClientCap(rt runtime.Runtime, verifiers, verifiedClients *adt.Map, client addr.Address, amount DataCap) {
+	// validate we are NOT attempting to do this for a verifier
+	found, err := verifiers.Get(abi.AddrKey(client), nil)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed tp get verifier")
+	if found {
+		rt.Abortf(exitcode.ErrIllegalArgument, "cannot restore allowance for a verifier")
+	}
+
+	var vcCap DataCap
+	found, err = verifiedClients.Get(abi.AddrKey(client), &vcCap)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get verified client %v", client)
+	if !found {
+		vcCap = big.Zero()
+	}
+
+	newVcCap := big.Add(vcCap, amount)
+	err = verifiedClients.Put(abi.AddrKey(client), &newVcCap)
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to put verified client %v with %v", client, newVcCap)
+}
+
+// Adds delta, which may be negative, to the remaining allowance of an existing verifier.
+func adjustVerifierAllowance(rt runtime.Runtime, verifier addr.Address, delta DataCap) {
+	WithState(rt, func(st *State) {
+		verifiers, err := adt.AsMap(adt.AsStore(rt), st.Verifiers, builtin.DefaultHamtBitwidth)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to load verifiers")
+
+		var allowance DataCap
+		found, err := verifiers.Get(abi.AddrKey(verifier), &allowance)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to get verifier %v", verifier)
+		if !found {
+			rt.Abortf(exitcode.ErrNotFound, "no such verifier %v", verifier)
+		}
+
+		newAllowance := big.Add(allowance, delta)
+		if newAllowance.LessThan(big.Zero()) {
+			rt.Abortf(exitcode.ErrIllegalArgument, "reduction %v exceeds verifier %v remaining allowance %v",
+				delta.Neg(), verifier, allowance)
+		}
+
+		err = verifiers.Put(abi.AddrKey(verifier), &newAllowance)
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to update verifier %v", verifier)
+
+		st.Verifiers, err = verifiers.Root()
+		builtin.RequireNoErr(rt, err, exitcode.ErrIllegalState, "failed to flush verifiers")
+	})
+}
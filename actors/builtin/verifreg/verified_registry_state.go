@@ -7,6 +7,7 @@ import (
 	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v3/actors/runtime"
 	"github.com/filecoin-project/specs-actors/v3/actors/util/adt"
 )
 
@@ -25,10 +26,91 @@ type State struct {
 
 	// VerifiedClients can add VerifiedClientData, up to DataCap.
 	VerifiedClients cid.Cid // HAMT[addr.Address]DataCap
+
+	// RemoveDataCapProposalIDs keeps the counters of the datacap removal proposal a verifier has submitted for a
+	// specific client. Unique proposal ids ensure that removal proposals cannot be replayed.
+	RemoveDataCapProposalIDs cid.Cid // HAMT[AddrPairKey]RmDcProposalID
+
+	// Allocations of verified clients' DataCap to specific pieces of data, awaiting a claim by the designated provider.
+	Allocations cid.Cid // HAMT[AllocationId]Allocation
+
+	// Identifier to be assigned to the next allocation. A claim takes the identifier of the allocation it claims.
+	NextAllocationId AllocationId
+
+	// Claims of allocations by providers, recording the verified data each has committed to store.
+	Claims cid.Cid // HAMT[ClaimId]Claim
 }
 
 var MinVerifiedDealSize = abi.NewStoragePower(1 << 20)
 
+// Bounds on the minimum and maximum terms of an allocation, i.e. the duration for which the provider claiming it
+// must store the data.
+var MinimumVerifiedAllocationTerm = abi.ChainEpoch(180 * builtin.EpochsInDay)
+var MaximumVerifiedAllocationTerm = abi.ChainEpoch(5 * builtin.EpochsInYear)
+
+// Maximum number of epochs after its creation by which an allocation must be claimed.
+var MaximumVerifiedAllocationExpiration = abi.ChainEpoch(60 * builtin.EpochsInDay)
+
+type AllocationId uint64
+type ClaimId uint64
+
+func (id AllocationId) Key() string {
+	return abi.UIntKey(uint64(id)).Key()
+}
+
+func (id ClaimId) Key() string {
+	return abi.UIntKey(uint64(id)).Key()
+}
+
+// An Allocation binds DataCap from a verified client to a piece of data, to be stored by a specific provider.
+// The DataCap is deducted from the client when the allocation is created, and returned if it expires unclaimed.
+type Allocation struct {
+	Client     addr.Address        // The verified client which created the allocation
+	Provider   addr.Address        // The provider (miner actor) which may claim the allocation
+	Data       cid.Cid             // Piece CID of the allocated data
+	Size       abi.PaddedPieceSize // Size of the allocated data
+	TermMin    abi.ChainEpoch      // Minimum duration for which the provider must commit to store the data
+	TermMax    abi.ChainEpoch      // Maximum duration for which the data may be stored as verified
+	Expiration abi.ChainEpoch      // Epoch after which the allocation can no longer be claimed
+}
+
+// A Claim records a provider's commitment of a sector to store the data of an allocation it claimed.
+type Claim struct {
+	Provider  addr.Address
+	Client    addr.Address
+	Data      cid.Cid
+	Size      abi.PaddedPieceSize
+	TermMin   abi.ChainEpoch   // Minimum duration for which the data must be stored, from the claim's start
+	TermMax   abi.ChainEpoch   // Maximum duration for which the data may be stored as verified, from the claim's start
+	TermStart abi.ChainEpoch   // Epoch at which the claim was made
+	Sector    abi.SectorNumber // Sector holding the data
+}
+
+// The epoch after which a claim's data is no longer verified.
+func (c *Claim) TermEnd() abi.ChainEpoch {
+	return c.TermStart + c.TermMax
+}
+
+// A removal proposal ID is a nonce scoped to a (verifier, client) pair. It is incremented each time the
+// verifier's signature on a removal proposal for that client is consumed.
+type RmDcProposalID struct {
+	ProposalID uint64
+}
+
+// AddrPairKey is the key of a (verifier, client) pair in the RemoveDataCapProposalIDs map.
+type AddrPairKey struct {
+	First  addr.Address
+	Second addr.Address
+}
+
+func NewAddrPairKey(first addr.Address, second addr.Address) AddrPairKey {
+	return AddrPairKey{First: first, Second: second}
+}
+
+func (k AddrPairKey) Key() string {
+	return string(append(k.First.Bytes(), k.Second.Bytes()...))
+}
+
 // rootKeyAddress comes from genesis.
 func ConstructState(store adt.Store, rootKeyAddress addr.Address) (*State, error) {
 	emptyMapCid, err := adt.StoreEmptyMap(store, builtin.DefaultHamtBitwidth)
@@ -37,8 +119,27 @@ func ConstructState(store adt.Store, rootKeyAddress addr.Address) (*State, error
 	}
 
 	return &State{
-		RootKey:         rootKeyAddress,
-		Verifiers:       emptyMapCid,
-		VerifiedClients: emptyMapCid,
+		RootKey:                  rootKeyAddress,
+		Verifiers:                emptyMapCid,
+		VerifiedClients:          emptyMapCid,
+		RemoveDataCapProposalIDs: emptyMapCid,
+		Allocations:              emptyMapCid,
+		NextAllocationId:         0,
+		Claims:                   emptyMapCid,
 	}, nil
 }
+
+// Typed wrapper around rt.StateTransaction for the verified registry state.
+func WithState(rt runtime.Runtime, f func(st *State)) {
+	var st State
+	rt.StateTransaction(&st, func() {
+		f(&st)
+	})
+}
+
+// Typed wrapper around rt.StateReadonly for the verified registry state.
+func ReadState(rt runtime.Runtime) *State {
+	var st State
+	rt.StateReadonly(&st)
+	return &st
+}
@@ -1,17 +1,21 @@
 package verifreg_test
 
 import (
+	"bytes"
 	"strings"
 	"testing"
 
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/crypto"
 	"github.com/filecoin-project/go-state-types/exitcode"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin/market"
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin/verifreg"
 	"github.com/filecoin-project/specs-actors/v3/actors/util/adt"
 	"github.com/filecoin-project/specs-actors/v3/support/mock"
@@ -41,6 +45,10 @@ func TestConstruction(t *testing.T) {
 		state := actor.state(rt)
 		assert.Equal(t, emptyMap, state.VerifiedClients)
 		assert.Equal(t, emptyMap, state.Verifiers)
+		assert.Equal(t, emptyMap, state.RemoveDataCapProposalIDs)
+		assert.Equal(t, emptyMap, state.Allocations)
+		assert.Equal(t, emptyMap, state.Claims)
+		assert.Equal(t, verifreg.AllocationId(0), state.NextAllocationId)
 		assert.Equal(t, raddr, state.RootKey)
 		actor.checkState(rt)
 	})
@@ -220,6 +228,93 @@ func TestRemoveVerifier(t *testing.T) {
 	})
 }
 
+func TestAdjustVerifierAllowance(t *testing.T) {
+	root := tutil.NewIDAddr(t, 101)
+	va := tutil.NewIDAddr(t, 201)
+	client := tutil.NewIDAddr(t, 301)
+	allowance := big.Mul(verifreg.MinVerifiedDealSize, big.NewInt(4))
+
+	t.Run("adds to a verifier's allowance", func(t *testing.T) {
+		rt, ac := basicVerifRegSetup(t, root)
+		ac.addNewVerifier(rt, va, allowance)
+
+		ac.addVerifierAllowance(rt, va, verifreg.MinVerifiedDealSize)
+		assert.EqualValues(t, big.Add(allowance, verifreg.MinVerifiedDealSize), ac.getVerifierCap(rt, va))
+		ac.checkState(rt)
+	})
+
+	t.Run("reduces a verifier's remaining allowance", func(t *testing.T) {
+		rt, ac := basicVerifRegSetup(t, root)
+		ac.addNewVerifier(rt, va, allowance)
+		ac.addVerifiedClient(rt, va, client, verifreg.MinVerifiedDealSize)
+		remaining := big.Sub(allowance, verifreg.MinVerifiedDealSize)
+
+		ac.reduceVerifierAllowance(rt, va, verifreg.MinVerifiedDealSize)
+		assert.EqualValues(t, big.Sub(remaining, verifreg.MinVerifiedDealSize), ac.getVerifierCap(rt, va))
+
+		// The whole remaining allowance may be removed, leaving the verifier in place.
+		ac.reduceVerifierAllowance(rt, va, big.Sub(remaining, verifreg.MinVerifiedDealSize))
+		assert.EqualValues(t, big.Zero(), ac.getVerifierCap(rt, va))
+
+		// The client's allowance is unaffected.
+		assert.EqualValues(t, verifreg.MinVerifiedDealSize, ac.getClientCap(rt, client))
+		ac.checkState(rt)
+	})
+
+	t.Run("fails to reduce more than the remaining allowance", func(t *testing.T) {
+		rt, ac := basicVerifRegSetup(t, root)
+		ac.addNewVerifier(rt, va, allowance)
+		// Allowance granted to the client has already been spent.
+		ac.addVerifiedClient(rt, va, client, verifreg.MinVerifiedDealSize)
+
+		rt.ExpectAbortContainsMessage(exitcode.ErrIllegalArgument, "exceeds verifier", func() {
+			ac.reduceVerifierAllowance(rt, va, allowance)
+		})
+		ac.checkState(rt)
+	})
+
+	t.Run("fails when caller is not the root key", func(t *testing.T) {
+		rt, ac := basicVerifRegSetup(t, root)
+		ac.addNewVerifier(rt, va, allowance)
+
+		rt.ExpectValidateCallerAddr(ac.rootkey)
+		rt.SetCaller(va, builtin.AccountActorCodeID)
+		rt.ExpectAbort(exitcode.SysErrForbidden, func() {
+			rt.Call(ac.AddVerifierAllowance, &verifreg.AddVerifierAllowanceParams{Address: va, Increase: allowance})
+		})
+		rt.ExpectValidateCallerAddr(ac.rootkey)
+		rt.ExpectAbort(exitcode.SysErrForbidden, func() {
+			rt.Call(ac.ReduceVerifierAllowance, &verifreg.ReduceVerifierAllowanceParams{Address: va, Reduction: allowance})
+		})
+		ac.checkState(rt)
+	})
+
+	t.Run("fails when verifier does not exist", func(t *testing.T) {
+		rt, ac := basicVerifRegSetup(t, root)
+
+		rt.ExpectAbort(exitcode.ErrNotFound, func() {
+			ac.addVerifierAllowance(rt, va, allowance)
+		})
+		rt.ExpectAbort(exitcode.ErrNotFound, func() {
+			ac.reduceVerifierAllowance(rt, va, allowance)
+		})
+		ac.checkState(rt)
+	})
+
+	t.Run("fails with non-positive amounts", func(t *testing.T) {
+		rt, ac := basicVerifRegSetup(t, root)
+		ac.addNewVerifier(rt, va, allowance)
+
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			ac.addVerifierAllowance(rt, va, big.Zero())
+		})
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			ac.reduceVerifierAllowance(rt, va, big.NewInt(-1))
+		})
+		ac.checkState(rt)
+	})
+}
+
 func TestAddVerifiedClient(t *testing.T) {
 	root := tutil.NewIDAddr(t, 101)
 	clientAddr := tutil.NewIDAddr(t, 201)
@@ -767,6 +862,339 @@ func TestRestoreBytes(t *testing.T) {
 	})
 }
 
+func TestRemoveVerifiedClientDataCap(t *testing.T) {
+	root := tutil.NewIDAddr(t, 101)
+	clientAddr := tutil.NewIDAddr(t, 201)
+	verifierAddr := tutil.NewIDAddr(t, 301)
+	verifierAddr2 := tutil.NewIDAddr(t, 302)
+	verifierAddr3 := tutil.NewIDAddr(t, 303)
+	vallow := big.Mul(verifreg.MinVerifiedDealSize, big.NewInt(10))
+	clientCap := big.Mul(verifreg.MinVerifiedDealSize, big.NewInt(5))
+
+	setup := func(t *testing.T) (*mock.Runtime, *verifRegActorTestHarness) {
+		rt, ac := basicVerifRegSetup(t, root)
+		ac.generateAndAddVerifierAndVerifiedClient(rt, verifierAddr, clientAddr, vallow, clientCap)
+		ac.addVerifier(rt, verifierAddr2, vallow)
+		ac.addVerifier(rt, verifierAddr3, vallow)
+		return rt, ac
+	}
+
+	t.Run("removes part of a client's datacap", func(t *testing.T) {
+		rt, ac := setup(t)
+		amount := verifreg.MinVerifiedDealSize
+		ret := ac.removeDataCap(rt, clientAddr, amount, []address.Address{verifierAddr, verifierAddr2}, nil)
+		assert.Equal(t, clientAddr, ret.VerifiedClient)
+		assert.EqualValues(t, amount, ret.DataCapRemoved)
+		assert.EqualValues(t, big.Sub(clientCap, amount), ac.getClientCap(rt, clientAddr))
+		assert.Equal(t, uint64(1), ac.getRemovalProposalID(rt, verifierAddr, clientAddr))
+		assert.Equal(t, uint64(1), ac.getRemovalProposalID(rt, verifierAddr2, clientAddr))
+		assert.Equal(t, uint64(0), ac.getRemovalProposalID(rt, verifierAddr3, clientAddr))
+		ac.checkState(rt)
+	})
+
+	t.Run("removes the client when its remaining datacap is below the minimum", func(t *testing.T) {
+		rt, ac := setup(t)
+		amount := big.Mul(clientCap, big.NewInt(2))
+		ret := ac.removeDataCap(rt, clientAddr, amount, []address.Address{verifierAddr, verifierAddr2}, nil)
+		assert.EqualValues(t, clientCap, ret.DataCapRemoved)
+		ac.assertClientRemoved(rt, clientAddr)
+		ac.checkState(rt)
+	})
+
+	t.Run("signatures cannot be replayed", func(t *testing.T) {
+		rt, ac := setup(t)
+		amount := verifreg.MinVerifiedDealSize
+		verifiers := []address.Address{verifierAddr, verifierAddr2}
+		ac.removeDataCap(rt, clientAddr, amount, verifiers, nil)
+
+		// Signatures are checked over a proposal carrying the next proposal ID, so a replay of the
+		// signatures over the consumed ID fails verification.
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			ac.removeDataCap(rt, clientAddr, amount, verifiers, xerrors.New("bad signature"))
+		})
+		assert.Equal(t, uint64(1), ac.getRemovalProposalID(rt, verifierAddr, clientAddr))
+
+		// Fresh signatures over the next proposal IDs succeed.
+		ac.removeDataCap(rt, clientAddr, amount, verifiers, nil)
+		assert.Equal(t, uint64(2), ac.getRemovalProposalID(rt, verifierAddr, clientAddr))
+		ac.checkState(rt)
+	})
+
+	t.Run("fails when caller is not the root key", func(t *testing.T) {
+		rt, ac := setup(t)
+		rt.ExpectValidateCallerAddr(ac.rootkey)
+		rt.SetCaller(verifierAddr, builtin.AccountActorCodeID)
+		rt.ExpectAbort(exitcode.SysErrForbidden, func() {
+			rt.Call(ac.RemoveVerifiedClientDataCap, mkRemoveDataCapParams(clientAddr, verifreg.MinVerifiedDealSize, verifierAddr, verifierAddr2))
+		})
+		ac.checkState(rt)
+	})
+
+	t.Run("fails with fewer than the threshold of verifiers", func(t *testing.T) {
+		rt, ac := setup(t)
+		rt.ExpectValidateCallerAddr(ac.rootkey)
+		rt.SetCaller(ac.rootkey, builtin.MultisigActorCodeID)
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			rt.Call(ac.RemoveVerifiedClientDataCap, mkRemoveDataCapParams(clientAddr, verifreg.MinVerifiedDealSize, verifierAddr))
+		})
+		ac.checkState(rt)
+	})
+
+	t.Run("fails with a duplicate verifier", func(t *testing.T) {
+		rt, ac := setup(t)
+		rt.ExpectValidateCallerAddr(ac.rootkey)
+		rt.SetCaller(ac.rootkey, builtin.MultisigActorCodeID)
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			rt.Call(ac.RemoveVerifiedClientDataCap, mkRemoveDataCapParams(clientAddr, verifreg.MinVerifiedDealSize, verifierAddr, verifierAddr))
+		})
+		ac.checkState(rt)
+	})
+
+	t.Run("fails when a signer is not a verifier", func(t *testing.T) {
+		rt, ac := setup(t)
+		rt.ExpectValidateCallerAddr(ac.rootkey)
+		rt.SetCaller(ac.rootkey, builtin.MultisigActorCodeID)
+		rt.ExpectAbort(exitcode.ErrNotFound, func() {
+			rt.Call(ac.RemoveVerifiedClientDataCap, mkRemoveDataCapParams(clientAddr, verifreg.MinVerifiedDealSize, verifierAddr, tutil.NewIDAddr(t, 399)))
+		})
+		ac.checkState(rt)
+	})
+
+	t.Run("fails when the client is not verified", func(t *testing.T) {
+		rt, ac := setup(t)
+		rt.ExpectValidateCallerAddr(ac.rootkey)
+		rt.SetCaller(ac.rootkey, builtin.MultisigActorCodeID)
+		rt.ExpectAbort(exitcode.ErrNotFound, func() {
+			rt.Call(ac.RemoveVerifiedClientDataCap, mkRemoveDataCapParams(tutil.NewIDAddr(t, 299), verifreg.MinVerifiedDealSize, verifierAddr, verifierAddr2))
+		})
+		ac.checkState(rt)
+	})
+}
+
+func TestAllocationsAndClaims(t *testing.T) {
+	root := tutil.NewIDAddr(t, 101)
+	clientAddr := tutil.NewIDAddr(t, 201)
+	verifierAddr := tutil.NewIDAddr(t, 301)
+	provider := tutil.NewIDAddr(t, 401)
+	otherProvider := tutil.NewIDAddr(t, 402)
+	clientCap := big.Mul(verifreg.MinVerifiedDealSize, big.NewInt(5))
+	size := abi.PaddedPieceSize(verifreg.MinVerifiedDealSize.Uint64())
+	data := tutil.MakeCID("piece", &market.PieceCIDPrefix)
+	startEpoch := abi.ChainEpoch(1000)
+
+	setup := func(t *testing.T) (*mock.Runtime, *verifRegActorTestHarness) {
+		rt, ac := basicVerifRegSetup(t, root)
+		rt.SetEpoch(startEpoch)
+		rt.SetAddressActorType(provider, builtin.StorageMinerActorCodeID)
+		rt.SetAddressActorType(otherProvider, builtin.StorageMinerActorCodeID)
+		ac.generateAndAddVerifierAndVerifiedClient(rt, verifierAddr, clientAddr, clientCap, clientCap)
+		return rt, ac
+	}
+	request := func() verifreg.AllocationRequest {
+		return verifreg.AllocationRequest{
+			Provider:   provider,
+			Data:       data,
+			Size:       size,
+			TermMin:    verifreg.MinimumVerifiedAllocationTerm,
+			TermMax:    verifreg.MaximumVerifiedAllocationTerm,
+			Expiration: startEpoch + verifreg.MaximumVerifiedAllocationExpiration,
+		}
+	}
+	claimOf := func(id verifreg.AllocationId, expiry abi.ChainEpoch) verifreg.SectorAllocationClaim {
+		return verifreg.SectorAllocationClaim{AllocationId: id, Data: data, Size: size, Sector: 7, SectorExpiry: expiry}
+	}
+
+	t.Run("create allocations deducts datacap from the client", func(t *testing.T) {
+		rt, ac := setup(t)
+		ids := ac.createAllocations(rt, clientAddr, request(), request())
+		assert.Equal(t, []verifreg.AllocationId{0, 1}, ids)
+		assert.EqualValues(t, big.Sub(clientCap, big.Mul(verifreg.MinVerifiedDealSize, big.NewInt(2))), ac.getClientCap(rt, clientAddr))
+
+		alloc := ac.getAllocation(rt, 1)
+		assert.Equal(t, clientAddr, alloc.Client)
+		assert.Equal(t, provider, alloc.Provider)
+		assert.Equal(t, verifreg.AllocationId(2), ac.state(rt).NextAllocationId)
+		ac.checkState(rt)
+	})
+
+	t.Run("create allocation fails with invalid terms", func(t *testing.T) {
+		rt, ac := setup(t)
+		for _, mutate := range []func(r *verifreg.AllocationRequest){
+			func(r *verifreg.AllocationRequest) { r.TermMin = verifreg.MinimumVerifiedAllocationTerm - 1 },
+			func(r *verifreg.AllocationRequest) { r.TermMax = verifreg.MaximumVerifiedAllocationTerm + 1 },
+			func(r *verifreg.AllocationRequest) { r.TermMax = r.TermMin - 1 },
+			func(r *verifreg.AllocationRequest) { r.Expiration = startEpoch },
+			func(r *verifreg.AllocationRequest) {
+				r.Expiration = startEpoch + verifreg.MaximumVerifiedAllocationExpiration + 1
+			},
+			func(r *verifreg.AllocationRequest) { r.Size = size / 2 },
+			func(r *verifreg.AllocationRequest) { r.Provider = verifierAddr },
+			func(r *verifreg.AllocationRequest) { r.Data = tutil.MakeCID("not-a-piece", nil) },
+		} {
+			req := request()
+			mutate(&req)
+			rt.SetCaller(clientAddr, builtin.AccountActorCodeID)
+			rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+			rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+				rt.Call(ac.CreateAllocations, &verifreg.CreateAllocationsParams{Allocations: []verifreg.AllocationRequest{req}})
+			})
+			rt.Verify()
+		}
+		assert.EqualValues(t, clientCap, ac.getClientCap(rt, clientAddr))
+	})
+
+	t.Run("create allocation fails when exceeding client datacap", func(t *testing.T) {
+		rt, ac := setup(t)
+		req := request()
+		req.Size = abi.PaddedPieceSize(big.Mul(clientCap, big.NewInt(2)).Uint64())
+		rt.SetCaller(clientAddr, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			rt.Call(ac.CreateAllocations, &verifreg.CreateAllocationsParams{Allocations: []verifreg.AllocationRequest{req}})
+		})
+		rt.Verify()
+	})
+
+	t.Run("provider claims allocation within its terms", func(t *testing.T) {
+		rt, ac := setup(t)
+		ids := ac.createAllocations(rt, clientAddr, request())
+		expiry := startEpoch + verifreg.MinimumVerifiedAllocationTerm
+		claimed := ac.claimAllocations(rt, provider, claimOf(ids[0], expiry))
+		assert.EqualValues(t, verifreg.MinVerifiedDealSize, claimed)
+
+		st := ac.state(rt)
+		allocations, err := adt.AsMap(rt.AdtStore(), st.Allocations, builtin.DefaultHamtBitwidth)
+		require.NoError(t, err)
+		found, err := allocations.Get(ids[0], nil)
+		require.NoError(t, err)
+		assert.False(t, found)
+
+		claim := ac.getClaim(rt, verifreg.ClaimId(ids[0]))
+		assert.Equal(t, provider, claim.Provider)
+		assert.Equal(t, clientAddr, claim.Client)
+		assert.Equal(t, startEpoch, claim.TermStart)
+		assert.Equal(t, abi.SectorNumber(7), claim.Sector)
+		ac.checkState(rt)
+	})
+
+	t.Run("claim fails for another provider, mismatched data, invalid sector, or sector outside terms", func(t *testing.T) {
+		rt, ac := setup(t)
+		ids := ac.createAllocations(rt, clientAddr, request())
+		expiry := startEpoch + verifreg.MinimumVerifiedAllocationTerm
+
+		rt.SetCaller(otherProvider, builtin.StorageMinerActorCodeID)
+		rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
+		rt.ExpectAbort(exitcode.ErrForbidden, func() {
+			rt.Call(ac.ClaimAllocations, &verifreg.ClaimAllocationsParams{Sectors: []verifreg.SectorAllocationClaim{claimOf(ids[0], expiry)}})
+		})
+		rt.Verify()
+
+		for _, sc := range []verifreg.SectorAllocationClaim{
+			{AllocationId: ids[0], Data: tutil.MakeCID("other", &market.PieceCIDPrefix), Size: size, SectorExpiry: expiry},
+			{AllocationId: ids[0], Data: data, Size: size * 2, SectorExpiry: expiry},
+			{AllocationId: ids[0], Data: data, Size: size, Sector: abi.MaxSectorNumber + 1, SectorExpiry: expiry},
+			claimOf(ids[0], startEpoch),
+			claimOf(ids[0], expiry-1),
+			claimOf(ids[0], startEpoch+verifreg.MaximumVerifiedAllocationTerm+1),
+		} {
+			rt.SetCaller(provider, builtin.StorageMinerActorCodeID)
+			rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
+			rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+				rt.Call(ac.ClaimAllocations, &verifreg.ClaimAllocationsParams{Sectors: []verifreg.SectorAllocationClaim{sc}})
+			})
+			rt.Verify()
+		}
+		ac.checkState(rt)
+	})
+
+	t.Run("expired allocation cannot be claimed and returns datacap on removal", func(t *testing.T) {
+		rt, ac := setup(t)
+		ids := ac.createAllocations(rt, clientAddr, request(), request())
+		expiration := startEpoch + verifreg.MaximumVerifiedAllocationExpiration
+
+		rt.SetEpoch(expiration + 1)
+		rt.SetCaller(provider, builtin.StorageMinerActorCodeID)
+		rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			rt.Call(ac.ClaimAllocations, &verifreg.ClaimAllocationsParams{Sectors: []verifreg.SectorAllocationClaim{
+				claimOf(ids[0], rt.Epoch()+verifreg.MinimumVerifiedAllocationTerm),
+			}})
+		})
+		rt.Verify()
+
+		// Unknown IDs are skipped.
+		ret := ac.removeExpiredAllocations(rt, ids[0], ids[1], 99)
+		assert.Equal(t, ids, ret.Removed)
+		assert.EqualValues(t, big.Mul(verifreg.MinVerifiedDealSize, big.NewInt(2)), ret.DataCapRecovered)
+		assert.EqualValues(t, clientCap, ac.getClientCap(rt, clientAddr))
+		ac.checkState(rt)
+	})
+
+	t.Run("unexpired allocation is not removed", func(t *testing.T) {
+		rt, ac := setup(t)
+		ids := ac.createAllocations(rt, clientAddr, request())
+		rt.SetEpoch(startEpoch + verifreg.MaximumVerifiedAllocationExpiration)
+		ret := ac.removeExpiredAllocations(rt, ids...)
+		assert.Empty(t, ret.Removed)
+		assert.EqualValues(t, big.Zero(), ret.DataCapRecovered)
+		ac.getAllocation(rt, ids[0])
+	})
+
+	t.Run("client extends claim term, which then expires", func(t *testing.T) {
+		rt, ac := setup(t)
+		req := request()
+		req.TermMax = req.TermMin
+		ids := ac.createAllocations(rt, clientAddr, req)
+		ac.claimAllocations(rt, provider, claimOf(ids[0], startEpoch+req.TermMin))
+		claimID := verifreg.ClaimId(ids[0])
+
+		newTermMax := req.TermMin + builtin.EpochsInDay
+		rt.SetCaller(clientAddr, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+		rt.Call(ac.ExtendClaimTerms, &verifreg.ExtendClaimTermsParams{Terms: []verifreg.ClaimTerm{{ClaimId: claimID, TermMax: newTermMax}}})
+		rt.Verify()
+		assert.Equal(t, newTermMax, ac.getClaim(rt, claimID).TermMax)
+
+		// The claim is retained until the end of its extended term.
+		rt.SetEpoch(startEpoch + newTermMax)
+		assert.Empty(t, ac.removeExpiredClaims(rt, claimID).Removed)
+		rt.SetEpoch(startEpoch + newTermMax + 1)
+		assert.Equal(t, []verifreg.ClaimId{claimID}, ac.removeExpiredClaims(rt, claimID).Removed)
+
+		// An expired claim can't be extended.
+		rt.SetCaller(clientAddr, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+		rt.ExpectAbort(exitcode.ErrNotFound, func() {
+			rt.Call(ac.ExtendClaimTerms, &verifreg.ExtendClaimTermsParams{Terms: []verifreg.ClaimTerm{{ClaimId: claimID, TermMax: newTermMax}}})
+		})
+		rt.Verify()
+		ac.checkState(rt)
+	})
+
+	t.Run("claim term extension fails for another client or beyond limits", func(t *testing.T) {
+		rt, ac := setup(t)
+		ids := ac.createAllocations(rt, clientAddr, request())
+		ac.claimAllocations(rt, provider, claimOf(ids[0], startEpoch+verifreg.MinimumVerifiedAllocationTerm))
+		claimID := verifreg.ClaimId(ids[0])
+
+		rt.SetCaller(verifierAddr, builtin.AccountActorCodeID)
+		rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+		rt.ExpectAbort(exitcode.ErrForbidden, func() {
+			rt.Call(ac.ExtendClaimTerms, &verifreg.ExtendClaimTermsParams{Terms: []verifreg.ClaimTerm{{ClaimId: claimID, TermMax: verifreg.MaximumVerifiedAllocationTerm}}})
+		})
+		rt.Verify()
+
+		for _, termMax := range []abi.ChainEpoch{verifreg.MaximumVerifiedAllocationTerm + 1, verifreg.MaximumVerifiedAllocationTerm - 1} {
+			rt.SetCaller(clientAddr, builtin.AccountActorCodeID)
+			rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+			rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+				rt.Call(ac.ExtendClaimTerms, &verifreg.ExtendClaimTermsParams{Terms: []verifreg.ClaimTerm{{ClaimId: claimID, TermMax: termMax}}})
+			})
+			rt.Verify()
+		}
+	})
+}
+
 type verifRegActorTestHarness struct {
 	rootkey address.Address
 	verifreg.Actor
@@ -853,6 +1281,22 @@ func (h *verifRegActorTestHarness) addVerifier(rt *mock.Runtime, verifier addres
 	assert.EqualValues(h.t, datacap, h.getVerifierCap(rt, verifierIdAddr))
 }
 
+func (h *verifRegActorTestHarness) addVerifierAllowance(rt *mock.Runtime, verifier address.Address, increase verifreg.DataCap) {
+	rt.ExpectValidateCallerAddr(h.rootkey)
+	rt.SetCaller(h.rootkey, builtin.VerifiedRegistryActorCodeID)
+	ret := rt.Call(h.AddVerifierAllowance, &verifreg.AddVerifierAllowanceParams{Address: verifier, Increase: increase})
+	rt.Verify()
+	assert.Nil(h.t, ret)
+}
+
+func (h *verifRegActorTestHarness) reduceVerifierAllowance(rt *mock.Runtime, verifier address.Address, reduction verifreg.DataCap) {
+	rt.ExpectValidateCallerAddr(h.rootkey)
+	rt.SetCaller(h.rootkey, builtin.VerifiedRegistryActorCodeID)
+	ret := rt.Call(h.ReduceVerifierAllowance, &verifreg.ReduceVerifierAllowanceParams{Address: verifier, Reduction: reduction})
+	rt.Verify()
+	assert.Nil(h.t, ret)
+}
+
 func (h *verifRegActorTestHarness) removeVerifier(rt *mock.Runtime, verifier address.Address) {
 	rt.ExpectValidateCallerAddr(h.rootkey)
 
@@ -864,6 +1308,48 @@ func (h *verifRegActorTestHarness) removeVerifier(rt *mock.Runtime, verifier add
 	h.assertVerifierRemoved(rt, verifier)
 }
 
+// Removes datacap from a client with the signatures of the given verifiers, each signing its current proposal ID.
+// Signature verification for the last verifier returns sigErr.
+func (h *verifRegActorTestHarness) removeDataCap(rt *mock.Runtime, client address.Address, amount verifreg.DataCap,
+	verifiers []address.Address, sigErr error) *verifreg.RemoveDataCapReturn {
+	rt.ExpectValidateCallerAddr(h.rootkey)
+	rt.SetCaller(h.rootkey, builtin.MultisigActorCodeID)
+
+	params := mkRemoveDataCapParams(client, amount, verifiers...)
+	for i, v := range verifiers {
+		proposal := verifreg.RemoveDataCapProposal{
+			VerifiedClient:    client,
+			DataCapAmount:     amount,
+			RemovalProposalID: verifreg.RmDcProposalID{ProposalID: h.getRemovalProposalID(rt, v, client)},
+		}
+		buf := bytes.Buffer{}
+		buf.WriteString(verifreg.SignatureDomainSeparation_RemoveDataCap)
+		require.NoError(h.t, proposal.MarshalCBOR(&buf))
+		var err error
+		if i == len(verifiers)-1 {
+			err = sigErr
+		}
+		rt.ExpectVerifySignature(params.VerifierRequests[i].VerifierSignature, v, buf.Bytes(), err)
+	}
+
+	ret := rt.Call(h.RemoveVerifiedClientDataCap, params).(*verifreg.RemoveDataCapReturn)
+	rt.Verify()
+	return ret
+}
+
+func (h *verifRegActorTestHarness) getRemovalProposalID(rt *mock.Runtime, verifier, client address.Address) uint64 {
+	var st verifreg.State
+	rt.GetState(&st)
+
+	m, err := adt.AsMap(adt.AsStore(rt), st.RemoveDataCapProposalIDs, builtin.DefaultHamtBitwidth)
+	require.NoError(h.t, err)
+
+	var id verifreg.RmDcProposalID
+	_, err = m.Get(verifreg.NewAddrPairKey(verifier, client), &id)
+	require.NoError(h.t, err)
+	return id.ProposalID
+}
+
 type capExpectation struct {
 	expectedCap verifreg.DataCap
 	removed     bool
@@ -961,6 +1447,59 @@ func (h *verifRegActorTestHarness) assertClientRemoved(rt *mock.Runtime, a addre
 	assert.False(h.t, found)
 }
 
+func (h *verifRegActorTestHarness) createAllocations(rt *mock.Runtime, client address.Address, reqs ...verifreg.AllocationRequest) []verifreg.AllocationId {
+	rt.SetCaller(client, builtin.AccountActorCodeID)
+	rt.ExpectValidateCallerType(builtin.CallerTypesSignable...)
+	ret := rt.Call(h.CreateAllocations, &verifreg.CreateAllocationsParams{Allocations: reqs}).(*verifreg.CreateAllocationsReturn)
+	rt.Verify()
+	require.Len(h.t, ret.AllocationIds, len(reqs))
+	return ret.AllocationIds
+}
+
+func (h *verifRegActorTestHarness) claimAllocations(rt *mock.Runtime, provider address.Address, claims ...verifreg.SectorAllocationClaim) abi.StoragePower {
+	rt.SetCaller(provider, builtin.StorageMinerActorCodeID)
+	rt.ExpectValidateCallerType(builtin.StorageMinerActorCodeID)
+	ret := rt.Call(h.ClaimAllocations, &verifreg.ClaimAllocationsParams{Sectors: claims}).(*verifreg.ClaimAllocationsReturn)
+	rt.Verify()
+	return ret.ClaimedSpace
+}
+
+func (h *verifRegActorTestHarness) removeExpiredAllocations(rt *mock.Runtime, ids ...verifreg.AllocationId) *verifreg.RemoveExpiredAllocationsReturn {
+	rt.SetCaller(tutil.NewIDAddr(h.t, 999), builtin.AccountActorCodeID)
+	rt.ExpectValidateCallerAny()
+	ret := rt.Call(h.RemoveExpiredAllocations, &verifreg.RemoveExpiredAllocationsParams{AllocationIds: ids}).(*verifreg.RemoveExpiredAllocationsReturn)
+	rt.Verify()
+	return ret
+}
+
+func (h *verifRegActorTestHarness) removeExpiredClaims(rt *mock.Runtime, ids ...verifreg.ClaimId) *verifreg.RemoveExpiredClaimsReturn {
+	rt.SetCaller(tutil.NewIDAddr(h.t, 999), builtin.AccountActorCodeID)
+	rt.ExpectValidateCallerAny()
+	ret := rt.Call(h.RemoveExpiredClaims, &verifreg.RemoveExpiredClaimsParams{ClaimIds: ids}).(*verifreg.RemoveExpiredClaimsReturn)
+	rt.Verify()
+	return ret
+}
+
+func (h *verifRegActorTestHarness) getAllocation(rt *mock.Runtime, id verifreg.AllocationId) verifreg.Allocation {
+	allocations, err := adt.AsMap(rt.AdtStore(), h.state(rt).Allocations, builtin.DefaultHamtBitwidth)
+	require.NoError(h.t, err)
+	var alloc verifreg.Allocation
+	found, err := allocations.Get(id, &alloc)
+	require.NoError(h.t, err)
+	require.True(h.t, found, "allocation %d not found", id)
+	return alloc
+}
+
+func (h *verifRegActorTestHarness) getClaim(rt *mock.Runtime, id verifreg.ClaimId) verifreg.Claim {
+	claims, err := adt.AsMap(rt.AdtStore(), h.state(rt).Claims, builtin.DefaultHamtBitwidth)
+	require.NoError(h.t, err)
+	var claim verifreg.Claim
+	found, err := claims.Get(id, &claim)
+	require.NoError(h.t, err)
+	require.True(h.t, found, "claim %d not found", id)
+	return claim
+}
+
 func mkVerifierParams(a address.Address, allowance verifreg.DataCap) *verifreg.AddVerifierParams {
 	return &verifreg.AddVerifierParams{Address: a, Allowance: allowance}
 }
@@ -968,3 +1507,14 @@ func mkVerifierParams(a address.Address, allowance verifreg.DataCap) *verifreg.A
 func mkClientParams(a address.Address, cap verifreg.DataCap) *verifreg.AddVerifiedClientParams {
 	return &verifreg.AddVerifiedClientParams{Address: a, Allowance: cap}
 }
+
+func mkRemoveDataCapParams(client address.Address, amount verifreg.DataCap, verifiers ...address.Address) *verifreg.RemoveDataCapParams {
+	params := &verifreg.RemoveDataCapParams{VerifiedClientToRemove: client, DataCapAmountToRemove: amount}
+	for _, v := range verifiers {
+		params.VerifierRequests = append(params.VerifierRequests, verifreg.RemoveDataCapRequest{
+			Verifier:          v,
+			VerifierSignature: crypto.Signature{Type: crypto.SigTypeBLS, Data: []byte("sig from " + v.String())},
+		})
+	}
+	return params
+}
@@ -3,12 +3,16 @@ package nv10
 import (
 	"context"
 
+	addr "github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
 	init2 "github.com/filecoin-project/specs-actors/v2/actors/builtin/init"
 	cid "github.com/ipfs/go-cid"
 	cbor "github.com/ipfs/go-ipld-cbor"
+	cbg "github.com/whyrusleeping/cbor-gen"
 
 	builtin3 "github.com/filecoin-project/specs-actors/v3/actors/builtin"
 	init3 "github.com/filecoin-project/specs-actors/v3/actors/builtin/init"
+	adt3 "github.com/filecoin-project/specs-actors/v3/actors/util/adt"
 )
 
 type initMigrator struct{}
@@ -24,10 +28,41 @@ func (m initMigrator) migrateState(ctx context.Context, store cbor.IpldStore, in
 		return nil, err
 	}
 
+	// The reverse map is built once from the migrated address map; no actor has a tombstone yet.
+	adtStore := adt3.WrapStore(ctx, store)
+	addressMap, err := adt3.AsMap(adtStore, addressMapOut, builtin3.DefaultHamtBitwidth)
+	if err != nil {
+		return nil, err
+	}
+	reverseMap, err := adt3.MakeEmptyMap(adtStore, builtin3.DefaultHamtBitwidth)
+	if err != nil {
+		return nil, err
+	}
+	var actorID cbg.CborInt
+	if err = addressMap.ForEach(&actorID, func(key string) error {
+		robust, err := addr.NewFromBytes([]byte(key))
+		if err != nil {
+			return err
+		}
+		return reverseMap.Put(abi.UIntKey(uint64(actorID)), &robust)
+	}); err != nil {
+		return nil, err
+	}
+	reverseMapOut, err := reverseMap.Root()
+	if err != nil {
+		return nil, err
+	}
+	tombstonesOut, err := adt3.StoreEmptyMap(adtStore, builtin3.DefaultHamtBitwidth)
+	if err != nil {
+		return nil, err
+	}
+
 	outState := init3.State{
 		AddressMap:  addressMapOut,
 		NextID:      inState.NextID,
 		NetworkName: inState.NetworkName,
+		ReverseMap:  reverseMapOut,
+		Tombstones:  tombstonesOut,
 	}
 	newHead, err := store.Put(ctx, &outState)
 	return &actorMigrationResult{
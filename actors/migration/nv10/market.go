@@ -3,6 +3,7 @@ package nv10
 import (
 	"context"
 
+	"github.com/filecoin-project/go-state-types/big"
 	cid "github.com/ipfs/go-cid"
 	cbor "github.com/ipfs/go-ipld-cbor"
 
@@ -47,6 +48,29 @@ func (m marketMigrator) migrateState(ctx context.Context, store cbor.IpldStore,
 		return nil, err
 	}
 
+	contributionsCidOut, err := adt3.StoreEmptyMap(adt3.WrapStore(ctx, store), market3.EscrowContributionsHamtBitwidth)
+	if err != nil {
+		return nil, err
+	}
+	reservationsCidOut, err := adt3.StoreEmptyMap(adt3.WrapStore(ctx, store), market3.CollateralReservationsHamtBitwidth)
+	if err != nil {
+		return nil, err
+	}
+
+	// Totals maintained incrementally by the market are computed once from the migrated tables.
+	escrowTable, err := adt3.AsBalanceTable(adt3.WrapStore(ctx, store), escrowTableCidOut)
+	if err != nil {
+		return nil, err
+	}
+	totalEscrow, err := escrowTable.Total()
+	if err != nil {
+		return nil, err
+	}
+	dealStates, err := market3.AsDealStateArray(adt3.WrapStore(ctx, store), statesCidOut)
+	if err != nil {
+		return nil, err
+	}
+
 	outState := market3.State{
 		Proposals:                     proposalsCidOut,
 		States:                        statesCidOut,
@@ -59,6 +83,12 @@ func (m marketMigrator) migrateState(ctx context.Context, store cbor.IpldStore,
 		TotalClientLockedCollateral:   inState.TotalClientLockedCollateral,
 		TotalProviderLockedCollateral: inState.TotalProviderLockedCollateral,
 		TotalClientStorageFee:         inState.TotalClientStorageFee,
+		EscrowContributions:           contributionsCidOut,
+		TotalEscrow:                   totalEscrow,
+		ActiveDeals:                   dealStates.Length(),
+
+		CollateralReservations:          reservationsCidOut,
+		TotalProviderReservedCollateral: big.Zero(),
 	}
 
 	newHead, err := store.Put(ctx, &outState)
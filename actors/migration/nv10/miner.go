@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/filecoin-project/go-bitfield"
+	"github.com/filecoin-project/go-state-types/big"
 	cid "github.com/ipfs/go-cid"
 	cbor "github.com/ipfs/go-ipld-cbor"
 	"golang.org/x/xerrors"
@@ -28,7 +29,7 @@ func (m minerMigrator) migrateState(ctx context.Context, store cbor.IpldStore, i
 	if err != nil {
 		return nil, err
 	}
-	preCommittedSectorsOut, err := migrateHAMTRaw(ctx, store, inState.PreCommittedSectors, builtin3.DefaultHamtBitwidth)
+	preCommittedSectorsOut, err := m.migratePreCommittedSectors(ctx, store, inState.PreCommittedSectors)
 	if err != nil {
 		return nil, err
 	}
@@ -108,10 +109,57 @@ func (m *minerMigrator) migrateInfo(ctx context.Context, store cbor.IpldStore, c
 		WindowPoStPartitionSectors: oldInfo.WindowPoStPartitionSectors,
 		ConsensusFaultElapsed:      oldInfo.ConsensusFaultElapsed,
 		PendingOwnerAddress:        oldInfo.PendingOwnerAddress,
+		Beneficiary:                oldInfo.Owner,
+		BeneficiaryTerm: miner3.BeneficiaryTerm{
+			Quota:      big.Zero(),
+			UsedQuota:  big.Zero(),
+			Expiration: 0,
+		},
+		PendingBeneficiaryTerm: nil,
 	}
 	return store.Put(ctx, &newInfo)
 }
 
+func (m *minerMigrator) migratePreCommittedSectors(ctx context.Context, store cbor.IpldStore, root cid.Cid) (cid.Cid, error) {
+	// HAMT[SectorNumber]SectorPreCommitOnChainInfo
+	inMap, err := adt2.AsMap(adt2.WrapStore(ctx, store), root)
+	if err != nil {
+		return cid.Undef, err
+	}
+	outMap, err := adt3.MakeEmptyMap(adt3.WrapStore(ctx, store), builtin3.DefaultHamtBitwidth)
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	var inPrecommit miner2.SectorPreCommitOnChainInfo
+	if err = inMap.ForEach(&inPrecommit, func(key string) error {
+		outPrecommit := miner3.SectorPreCommitOnChainInfo{
+			Info: miner3.SectorPreCommitInfo{
+				SealProof:              inPrecommit.Info.SealProof,
+				SectorNumber:           inPrecommit.Info.SectorNumber,
+				SealedCID:              inPrecommit.Info.SealedCID,
+				SealRandEpoch:          inPrecommit.Info.SealRandEpoch,
+				DealIDs:                inPrecommit.Info.DealIDs,
+				Expiration:             inPrecommit.Info.Expiration,
+				ReplaceCapacity:        inPrecommit.Info.ReplaceCapacity,
+				ReplaceSectorDeadline:  inPrecommit.Info.ReplaceSectorDeadline,
+				ReplaceSectorPartition: inPrecommit.Info.ReplaceSectorPartition,
+				ReplaceSectorNumber:    inPrecommit.Info.ReplaceSectorNumber,
+				AllocationClaims:       nil,
+				CollateralReservations: nil,
+			},
+			PreCommitDeposit:   inPrecommit.PreCommitDeposit,
+			PreCommitEpoch:     inPrecommit.PreCommitEpoch,
+			DealWeight:         inPrecommit.DealWeight,
+			VerifiedDealWeight: inPrecommit.VerifiedDealWeight,
+		}
+		return outMap.Put(StringKey(key), &outPrecommit)
+	}); err != nil {
+		return cid.Undef, err
+	}
+	return outMap.Root()
+}
+
 func (m *minerMigrator) migrateDeadlines(ctx context.Context, store cbor.IpldStore, cache MigrationCache, deadlines cid.Cid) (cid.Cid, error) {
 	var inDeadlines miner2.Deadlines
 	err := store.Get(ctx, deadlines, &inDeadlines)
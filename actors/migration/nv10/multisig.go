@@ -3,16 +3,47 @@ package nv10
 import (
 	"context"
 
+	address "github.com/filecoin-project/go-address"
+	init2 "github.com/filecoin-project/specs-actors/v2/actors/builtin/init"
 	multisig2 "github.com/filecoin-project/specs-actors/v2/actors/builtin/multisig"
+	states2 "github.com/filecoin-project/specs-actors/v2/actors/states"
 	cid "github.com/ipfs/go-cid"
 	cbor "github.com/ipfs/go-ipld-cbor"
+	"golang.org/x/xerrors"
 
 	builtin3 "github.com/filecoin-project/specs-actors/v3/actors/builtin"
 	multisig3 "github.com/filecoin-project/specs-actors/v3/actors/builtin/multisig"
+	adt3 "github.com/filecoin-project/specs-actors/v3/actors/util/adt"
 )
 
-type multisigMigrator struct{}
+// Resolves an address to the ID address of its actor, reporting whether the actor exists.
+type addressResolver func(a address.Address) (address.Address, bool, error)
 
+// Returns a resolver of addresses through the address map of the init actor in a state tree.
+func newAddressResolver(ctx context.Context, store adt3.Store, actorsIn *states2.Tree) (addressResolver, error) {
+	initActor, found, err := actorsIn.GetActor(builtin3.InitActorAddr)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, xerrors.Errorf("no init actor in state tree")
+	}
+	var initState init2.State
+	if err := store.Get(ctx, initActor.Head, &initState); err != nil {
+		return nil, err
+	}
+	return func(a address.Address) (address.Address, bool, error) {
+		return initState.ResolveAddress(store, a)
+	}, nil
+}
+
+type multisigMigrator struct {
+	resolve addressResolver
+}
+
+// Signers and approvals recorded by robust address are normalized to ID addresses, so that a signer cannot
+// be listed twice in different forms. Duplicates revealed by normalization are removed, and the approval
+// threshold reduced if necessary so it can still be met. An address without an actor is left unchanged.
 func (m multisigMigrator) migrateState(ctx context.Context, store cbor.IpldStore, in actorMigrationInput) (*actorMigrationResult, error) {
 	var inState multisig2.State
 	if err := store.Get(ctx, in.head, &inState); err != nil {
@@ -24,9 +55,22 @@ func (m multisigMigrator) migrateState(ctx context.Context, store cbor.IpldStore
 		return nil, err
 	}
 
+	signers, changed, err := m.normalize(inState.Signers)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to normalize signers of %s: %w", in.address, err)
+	}
+	threshold := inState.NumApprovalsThreshold
+	if changed && threshold > uint64(len(signers)) {
+		threshold = uint64(len(signers))
+	}
+
+	if pendingTxnsOut, err = m.normalizeApprovals(ctx, store, pendingTxnsOut); err != nil {
+		return nil, xerrors.Errorf("failed to normalize approvals of %s: %w", in.address, err)
+	}
+
 	outState := multisig3.State{
-		Signers:               inState.Signers,
-		NumApprovalsThreshold: inState.NumApprovalsThreshold,
+		Signers:               signers,
+		NumApprovalsThreshold: threshold,
 		NextTxnID:             inState.NextTxnID,
 		InitialBalance:        inState.InitialBalance,
 		StartEpoch:            inState.StartEpoch,
@@ -43,3 +87,69 @@ func (m multisigMigrator) migrateState(ctx context.Context, store cbor.IpldStore
 func (m multisigMigrator) migratedCodeCID() cid.Cid {
 	return builtin3.MultisigActorCodeID
 }
+
+// Resolves addresses to ID addresses where possible, removing any duplicates while preserving order.
+// Reports whether the list changed.
+func (m multisigMigrator) normalize(addrs []address.Address) ([]address.Address, bool, error) {
+	out := make([]address.Address, 0, len(addrs))
+	seen := make(map[address.Address]struct{}, len(addrs))
+	changed := false
+	for _, a := range addrs {
+		resolved := a
+		if a.Protocol() != address.ID {
+			idAddr, found, err := m.resolve(a)
+			if err != nil {
+				return nil, false, err
+			}
+			if found {
+				resolved = idAddr
+				changed = true
+			}
+		}
+		if _, ok := seen[resolved]; ok {
+			changed = true
+			continue
+		}
+		seen[resolved] = struct{}{}
+		out = append(out, resolved)
+	}
+	return out, changed, nil
+}
+
+// Normalizes the approvals of each pending transaction, returning the new root of the transactions.
+func (m multisigMigrator) normalizeApprovals(ctx context.Context, store cbor.IpldStore, root cid.Cid) (cid.Cid, error) {
+	txns, err := adt3.AsMap(adt3.WrapStore(ctx, store), root, builtin3.DefaultHamtBitwidth)
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	var updatedIDs []string // For stable iteration
+	updated := map[string]multisig3.Transaction{}
+	var txn multisig3.Transaction
+	if err = txns.ForEach(&txn, func(txid string) error {
+		approved, changed, err := m.normalize(txn.Approved)
+		if err != nil {
+			return err
+		}
+		if changed {
+			updatedTxn := txn
+			updatedTxn.Approved = approved
+			updatedIDs = append(updatedIDs, txid)
+			updated[txid] = updatedTxn
+		}
+		return nil
+	}); err != nil {
+		return cid.Undef, err
+	}
+	if len(updatedIDs) == 0 {
+		return root, nil
+	}
+
+	for _, txid := range updatedIDs {
+		txn := updated[txid]
+		if err := txns.Put(multisig3.StringKey(txid), &txn); err != nil {
+			return cid.Undef, err
+		}
+	}
+	return txns.Root()
+}
@@ -30,6 +30,7 @@ func (m paychMigrator) migrateState(ctx context.Context, store cbor.IpldStore, i
 		ToSend:          inState.ToSend,
 		SettlingAt:      inState.SettlingAt,
 		MinSettleHeight: inState.MinSettleHeight,
+		SettleDelay:     paych3.SettleDelay,
 		LaneStates:      laneStatesOut,
 	}
 	newHead, err := store.Put(ctx, &outState)
@@ -36,7 +36,8 @@ func (m powerMigrator) migrateState(ctx context.Context, store cbor.IpldStore, i
 		return nil, err
 	}
 
-	cronEventQueueOut, err := migrateHAMTAMTRaw(ctx, store, inState.CronEventQueue, power3.CronQueueHamtBitwidth, power3.CronQueueAmtBitwidth)
+	// The cron event queue changes from a HAMT keyed by epoch to an AMT keyed by epoch.
+	cronEventQueueOut, err := migrateIntHAMTAMTToAMTRaw(ctx, store, inState.CronEventQueue, power3.CronQueueEpochAmtBitwidth, power3.CronQueueAmtBitwidth)
 	if err != nil {
 		return nil, err
 	}
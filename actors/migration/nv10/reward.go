@@ -0,0 +1,47 @@
+package nv10
+
+import (
+	"context"
+
+	reward2 "github.com/filecoin-project/specs-actors/v2/actors/builtin/reward"
+	cid "github.com/ipfs/go-cid"
+	cbor "github.com/ipfs/go-ipld-cbor"
+
+	builtin3 "github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	reward3 "github.com/filecoin-project/specs-actors/v3/actors/builtin/reward"
+	smoothing3 "github.com/filecoin-project/specs-actors/v3/actors/util/smoothing"
+)
+
+type rewardMigrator struct{}
+
+func (m rewardMigrator) migrateState(ctx context.Context, store cbor.IpldStore, in actorMigrationInput) (*actorMigrationResult, error) {
+	var inState reward2.State
+	if err := store.Get(ctx, in.head, &inState); err != nil {
+		return nil, err
+	}
+
+	// Burns before this upgrade were not attributed, so cumulative burns start empty.
+	outState := reward3.State{
+		CumsumBaseline:          inState.CumsumBaseline,
+		CumsumRealized:          inState.CumsumRealized,
+		EffectiveNetworkTime:    inState.EffectiveNetworkTime,
+		EffectiveBaselinePower:  inState.EffectiveBaselinePower,
+		ThisEpochReward:         inState.ThisEpochReward,
+		ThisEpochRewardSmoothed: smoothing3.FilterEstimate(inState.ThisEpochRewardSmoothed),
+		ThisEpochBaselinePower:  inState.ThisEpochBaselinePower,
+		Epoch:                   inState.Epoch,
+		TotalStoragePowerReward: inState.TotalStoragePowerReward,
+		SimpleTotal:             inState.SimpleTotal,
+		BaselineTotal:           inState.BaselineTotal,
+		CumulativeBurns:         nil,
+	}
+	newHead, err := store.Put(ctx, &outState)
+	return &actorMigrationResult{
+		newCodeCID: m.migratedCodeCID(),
+		newHead:    newHead,
+	}, err
+}
+
+func (m rewardMigrator) migratedCodeCID() cid.Cid {
+	return builtin3.RewardActorCodeID
+}
@@ -90,8 +90,9 @@ func TestUpdatePendingDealsMigration(t *testing.T) {
 		v3, err = v3.WithEpoch(v.GetEpoch() + 1)
 		require.NoError(t, err)
 
-		deals = append(deals, publishV3Deal(t, v3, worker, addrs[1+i%9], minerAddrs.IDAddress, fmt.Sprintf("deal1%d", i),
-			1<<26, false, dealStart, 210*builtin3.EpochsInDay))
+		published := publishV3Deal(t, v3, worker, addrs[1+i%9], minerAddrs.IDAddress, fmt.Sprintf("deal1%d", i),
+			1<<26, false, dealStart, 210*builtin3.EpochsInDay)
+		deals = append(deals, &market2.PublishStorageDealsReturn{IDs: published.IDs})
 	}
 
 	// add even deals to a sector we will commit (to let the odd deals expire)
@@ -191,7 +192,7 @@ func publishDeal(t *testing.T, v *vm2.VM, provider, dealClient, minerID addr.Add
 
 func publishV3Deal(t *testing.T, v *vm3.VM, provider, dealClient, minerID addr.Address, dealLabel string,
 	pieceSize abi.PaddedPieceSize, verifiedDeal bool, dealStart abi.ChainEpoch, dealLifetime abi.ChainEpoch,
-) *market2.PublishStorageDealsReturn {
+) *market3.PublishStorageDealsReturn {
 	deal := market3.DealProposal{
 		PieceCID:             tutil.MakeCID(dealLabel, &market2.PieceCIDPrefix),
 		PieceSize:            pieceSize,
@@ -227,5 +228,5 @@ func publishV3Deal(t *testing.T, v *vm3.VM, provider, dealClient, minerID addr.A
 		SubInvocations: expectedPublishSubinvocations,
 	}.Matches(t, v.LastInvocation())
 
-	return ret.(*market2.PublishStorageDealsReturn)
+	return ret.(*market3.PublishStorageDealsReturn)
 }
@@ -0,0 +1,104 @@
+package test_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	addr "github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	builtin2 "github.com/filecoin-project/specs-actors/v2/actors/builtin"
+	init2 "github.com/filecoin-project/specs-actors/v2/actors/builtin/init"
+	multisig2 "github.com/filecoin-project/specs-actors/v2/actors/builtin/multisig"
+	adt2 "github.com/filecoin-project/specs-actors/v2/actors/util/adt"
+	ipld2 "github.com/filecoin-project/specs-actors/v2/support/ipld"
+	vm2 "github.com/filecoin-project/specs-actors/v2/support/vm"
+
+	builtin3 "github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	multisig3 "github.com/filecoin-project/specs-actors/v3/actors/builtin/multisig"
+	"github.com/filecoin-project/specs-actors/v3/actors/migration/nv10"
+	states3 "github.com/filecoin-project/specs-actors/v3/actors/states"
+	"github.com/filecoin-project/specs-actors/v3/actors/util/adt"
+	tutil "github.com/filecoin-project/specs-actors/v3/support/testing"
+)
+
+func TestMultisigSignerNormalizationMigration(t *testing.T) {
+	ctx := context.Background()
+	log := nv10.TestLogger{TB: t}
+	v := vm2.NewVMWithSingletons(ctx, t, ipld2.NewSyncBlockStoreInMemory())
+	addrs := vm2.CreateAccounts(ctx, t, v, 3, big.Mul(big.NewInt(100), vm2.FIL), 93837778)
+	id0, found := v.NormalizeAddress(addrs[0])
+	require.True(t, found)
+	id1, found := v.NormalizeAddress(addrs[1])
+	require.True(t, found)
+	// An address with no actor can't be resolved.
+	unknown := tutil.NewBLSAddr(t, 1)
+
+	// Create a multisig, then replace its state with signers and approvals recorded in mixed address forms,
+	// as could be left by earlier versions of the actor.
+	var ctorParams bytes.Buffer
+	require.NoError(t, (&multisig2.ConstructorParams{
+		Signers:               []addr.Address{addrs[0], addrs[1]},
+		NumApprovalsThreshold: 2,
+	}).MarshalCBOR(&ctorParams))
+	ret := vm2.ApplyOk(t, v, addrs[0], builtin2.InitActorAddr, big.Zero(), builtin2.MethodsInit.Exec, &init2.ExecParams{
+		CodeCID:           builtin2.MultisigActorCodeID,
+		ConstructorParams: ctorParams.Bytes(),
+	})
+	msigAddr := ret.(*init2.ExecReturn).IDAddress
+
+	store := v.Store()
+	txns := adt2.MakeEmptyMap(store)
+	require.NoError(t, txns.Put(multisig2.TxnID(0), &multisig2.Transaction{
+		To:       addrs[2],
+		Value:    big.Zero(),
+		Approved: []addr.Address{addrs[0], id0},
+	}))
+	require.NoError(t, txns.Put(multisig2.TxnID(1), &multisig2.Transaction{
+		To:       addrs[2],
+		Value:    big.Zero(),
+		Approved: []addr.Address{id1},
+	}))
+	txnsRoot, err := txns.Root()
+	require.NoError(t, err)
+	require.NoError(t, v.SetActorState(ctx, msigAddr, &multisig2.State{
+		Signers:               []addr.Address{addrs[0], id0, id1, unknown},
+		NumApprovalsThreshold: 4,
+		NextTxnID:             2,
+		InitialBalance:        big.Zero(),
+		PendingTxns:           txnsRoot,
+	}))
+	// Commit the state tree so the state root reflects the replaced state.
+	_, err = v.GetStateTree()
+	require.NoError(t, err)
+
+	nextRoot, err := nv10.MigrateStateTree(ctx, store, v.StateRoot(), v.GetEpoch(), nv10.Config{MaxWorkers: 1}, log, nv10.NewMemMigrationCache())
+	require.NoError(t, err)
+
+	tree, err := states3.LoadTree(store, nextRoot)
+	require.NoError(t, err)
+	act, found, err := tree.GetActor(msigAddr)
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, builtin3.MultisigActorCodeID, act.Code)
+
+	var st multisig3.State
+	require.NoError(t, store.Get(ctx, act.Head, &st))
+	assert.Equal(t, []addr.Address{id0, id1, unknown}, st.Signers)
+	assert.Equal(t, uint64(3), st.NumApprovalsThreshold)
+
+	migratedTxns, err := adt.AsMap(store, st.PendingTxns, builtin3.DefaultHamtBitwidth)
+	require.NoError(t, err)
+	var txn multisig3.Transaction
+	found, err = migratedTxns.Get(multisig3.TxnID(0), &txn)
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, []addr.Address{id0}, txn.Approved)
+	found, err = migratedTxns.Get(multisig3.TxnID(1), &txn)
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, []addr.Address{id1}, txn.Approved)
+}
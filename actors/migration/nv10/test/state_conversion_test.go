@@ -0,0 +1,220 @@
+package test_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	addr "github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	builtin2 "github.com/filecoin-project/specs-actors/v2/actors/builtin"
+	market2 "github.com/filecoin-project/specs-actors/v2/actors/builtin/market"
+	power2 "github.com/filecoin-project/specs-actors/v2/actors/builtin/power"
+	states2 "github.com/filecoin-project/specs-actors/v2/actors/states"
+	adt2 "github.com/filecoin-project/specs-actors/v2/actors/util/adt"
+	ipld2 "github.com/filecoin-project/specs-actors/v2/support/ipld"
+	vm2 "github.com/filecoin-project/specs-actors/v2/support/vm"
+
+	builtin3 "github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	market3 "github.com/filecoin-project/specs-actors/v3/actors/builtin/market"
+	miner3 "github.com/filecoin-project/specs-actors/v3/actors/builtin/miner"
+	power3 "github.com/filecoin-project/specs-actors/v3/actors/builtin/power"
+	"github.com/filecoin-project/specs-actors/v3/actors/migration/nv10"
+	states3 "github.com/filecoin-project/specs-actors/v3/actors/states"
+	"github.com/filecoin-project/specs-actors/v3/actors/util/adt"
+)
+
+// Converts the state of a network with a miner and published deals from v2 to v3 and checks that
+// the v3 state carries the same actors, balances and accounting as the state it was converted from.
+func TestStateConversion(t *testing.T) {
+	ctx := context.Background()
+	log := nv10.TestLogger{TB: t}
+	v := vm2.NewVMWithSingletons(ctx, t, ipld2.NewSyncBlockStoreInMemory())
+	addrs := vm2.CreateAccounts(ctx, t, v, 4, big.Mul(big.NewInt(100_000), vm2.FIL), 93837778)
+	worker := addrs[0]
+
+	ret := vm2.ApplyOk(t, v, worker, builtin2.StoragePowerActorAddr, big.Mul(big.NewInt(10_000), vm2.FIL), builtin2.MethodsPower.CreateMiner, &power2.CreateMinerParams{
+		Owner:         worker,
+		Worker:        worker,
+		SealProofType: abi.RegisteredSealProof_StackedDrg32GiBV1_1,
+		Peer:          abi.PeerID("not really a peer id"),
+	})
+	minerAddrs, ok := ret.(*power2.CreateMinerReturn)
+	require.True(t, ok)
+
+	clients := addrs[1:]
+	for _, client := range clients {
+		client := client
+		vm2.ApplyOk(t, v, client, builtin2.StorageMarketActorAddr, big.Mul(big.NewInt(30), vm2.FIL), builtin2.MethodsMarket.AddBalance, &client)
+	}
+	vm2.ApplyOk(t, v, worker, builtin2.StorageMarketActorAddr, big.Mul(big.NewInt(64), vm2.FIL), builtin2.MethodsMarket.AddBalance, &minerAddrs.IDAddress)
+
+	dealStart := abi.ChainEpoch(252)
+	var dealIDs []abi.DealID
+	for i, client := range clients {
+		deal := publishDeal(t, v, worker, client, minerAddrs.IDAddress, fmt.Sprintf("deal%d", i),
+			1<<26, false, dealStart, 210*builtin2.EpochsInDay)
+		dealIDs = append(dealIDs, deal.IDs...)
+	}
+
+	// Run cron for the epoch so the reward actor's state is current, as on a live network at an upgrade.
+	vm2.ApplyOk(t, v, builtin2.SystemActorAddr, builtin2.CronActorAddr, big.Zero(), builtin2.MethodsCron.EpochTick, nil)
+
+	// Record the prior version's actors and state.
+	tree2, err := v.GetStateTree()
+	require.NoError(t, err)
+	actors2 := map[addr.Address]states2.Actor{}
+	totalBalance := big.Zero()
+	require.NoError(t, tree2.ForEach(func(a addr.Address, act *states2.Actor) error {
+		actors2[a] = *act
+		totalBalance = big.Add(totalBalance, act.Balance)
+		return nil
+	}))
+
+	var power2St power2.State
+	require.NoError(t, v.GetState(builtin2.StoragePowerActorAddr, &power2St))
+	var market2St market2.State
+	require.NoError(t, v.GetState(builtin2.StorageMarketActorAddr, &market2St))
+
+	// Convert the state.
+	store := v.Store()
+	nextRoot, err := nv10.MigrateStateTree(ctx, store, v.StateRoot(), v.GetEpoch(), nv10.Config{MaxWorkers: 1}, log, nv10.NewMemMigrationCache())
+	require.NoError(t, err)
+	tree3, err := states3.LoadTree(store, nextRoot)
+	require.NoError(t, err)
+
+	t.Run("actors keep their addresses, balances and roles", func(t *testing.T) {
+		nextCodes := map[cid.Cid]cid.Cid{
+			builtin2.AccountActorCodeID:          builtin3.AccountActorCodeID,
+			builtin2.CronActorCodeID:             builtin3.CronActorCodeID,
+			builtin2.InitActorCodeID:             builtin3.InitActorCodeID,
+			builtin2.MultisigActorCodeID:         builtin3.MultisigActorCodeID,
+			builtin2.PaymentChannelActorCodeID:   builtin3.PaymentChannelActorCodeID,
+			builtin2.RewardActorCodeID:           builtin3.RewardActorCodeID,
+			builtin2.StorageMarketActorCodeID:    builtin3.StorageMarketActorCodeID,
+			builtin2.StorageMinerActorCodeID:     builtin3.StorageMinerActorCodeID,
+			builtin2.StoragePowerActorCodeID:     builtin3.StoragePowerActorCodeID,
+			builtin2.SystemActorCodeID:           builtin3.SystemActorCodeID,
+			builtin2.VerifiedRegistryActorCodeID: builtin3.VerifiedRegistryActorCodeID,
+		}
+		count := 0
+		require.NoError(t, tree3.ForEach(func(a addr.Address, act *states3.Actor) error {
+			count++
+			prior, found := actors2[a]
+			require.True(t, found, "actor %v not present before conversion", a)
+			assert.Equal(t, nextCodes[prior.Code], act.Code, "actor %v", a)
+			assert.Equal(t, prior.Balance, act.Balance, "actor %v", a)
+			return nil
+		}))
+		assert.Equal(t, len(actors2), count)
+	})
+
+	t.Run("power totals and claims are converted", func(t *testing.T) {
+		var st power3.State
+		found, err := tree3.GetActorState(builtin3.StoragePowerActorAddr, &st)
+		require.NoError(t, err)
+		require.True(t, found)
+
+		assert.Equal(t, power2St.TotalRawBytePower, st.TotalRawBytePower)
+		assert.Equal(t, power2St.TotalQualityAdjPower, st.TotalQualityAdjPower)
+		assert.Equal(t, power2St.TotalPledgeCollateral, st.TotalPledgeCollateral)
+		assert.Equal(t, power2St.ThisEpochQAPowerSmoothed.PositionEstimate, st.ThisEpochQAPowerSmoothed.PositionEstimate)
+		assert.Equal(t, power2St.MinerCount, st.MinerCount)
+		assert.Equal(t, power2St.FirstCronEpoch, st.FirstCronEpoch)
+
+		claim2, found, err := power2St.GetClaim(store, minerAddrs.IDAddress)
+		require.NoError(t, err)
+		require.True(t, found)
+		claim3, found, err := st.GetClaim(store, minerAddrs.IDAddress)
+		require.NoError(t, err)
+		require.True(t, found)
+		// Claims record the miner's window PoSt proof type in place of its seal proof type.
+		assert.Equal(t, abi.RegisteredPoStProof_StackedDrgWindow32GiBV1, claim3.WindowPoStProofType)
+		assert.Equal(t, claim2.RawBytePower, claim3.RawBytePower)
+		assert.Equal(t, claim2.QualityAdjPower, claim3.QualityAdjPower)
+
+		var minerSt miner3.State
+		found, err = tree3.GetActorState(minerAddrs.IDAddress, &minerSt)
+		require.NoError(t, err)
+		require.True(t, found)
+		info, err := minerSt.GetInfo(store)
+		require.NoError(t, err)
+		assert.Equal(t, claim3.WindowPoStProofType, info.WindowPoStProofType)
+	})
+
+	t.Run("market balances and deals are converted", func(t *testing.T) {
+		var st market3.State
+		found, err := tree3.GetActorState(builtin3.StorageMarketActorAddr, &st)
+		require.NoError(t, err)
+		require.True(t, found)
+
+		assert.Equal(t, market2St.NextID, st.NextID)
+		assert.Equal(t, market2St.TotalClientLockedCollateral, st.TotalClientLockedCollateral)
+		assert.Equal(t, market2St.TotalProviderLockedCollateral, st.TotalProviderLockedCollateral)
+		assert.Equal(t, market2St.TotalClientStorageFee, st.TotalClientStorageFee)
+
+		escrow2, err := adt2.AsBalanceTable(store, market2St.EscrowTable)
+		require.NoError(t, err)
+		locked2, err := adt2.AsBalanceTable(store, market2St.LockedTable)
+		require.NoError(t, err)
+		escrow3, err := adt.AsBalanceTable(store, st.EscrowTable)
+		require.NoError(t, err)
+		locked3, err := adt.AsBalanceTable(store, st.LockedTable)
+		require.NoError(t, err)
+		for _, a := range append([]addr.Address{minerAddrs.IDAddress}, clients...) {
+			id, found := v.NormalizeAddress(a)
+			require.True(t, found)
+			prior, err := escrow2.Get(id)
+			require.NoError(t, err)
+			converted, err := escrow3.Get(id)
+			require.NoError(t, err)
+			assert.Equal(t, prior, converted, "escrow of %v", id)
+
+			prior, err = locked2.Get(id)
+			require.NoError(t, err)
+			converted, err = locked3.Get(id)
+			require.NoError(t, err)
+			assert.Equal(t, prior, converted, "locked balance of %v", id)
+		}
+
+		proposals2, err := market2.AsDealProposalArray(store, market2St.Proposals)
+		require.NoError(t, err)
+		proposals3, err := market3.AsDealProposalArray(store, st.Proposals)
+		require.NoError(t, err)
+		pending3, err := adt.AsSet(store, st.PendingProposals, builtin3.DefaultHamtBitwidth)
+		require.NoError(t, err)
+		for _, dealID := range dealIDs {
+			prior, found, err := proposals2.Get(dealID)
+			require.NoError(t, err)
+			require.True(t, found)
+			converted, found, err := proposals3.Get(dealID)
+			require.NoError(t, err)
+			require.True(t, found, "deal %d", dealID)
+			assert.Equal(t, prior.PieceCID, converted.PieceCID)
+			assert.Equal(t, prior.Client, converted.Client)
+			assert.Equal(t, prior.Provider, converted.Provider)
+			assert.Equal(t, prior.StartEpoch, converted.StartEpoch)
+			assert.Equal(t, prior.EndEpoch, converted.EndEpoch)
+			assert.Equal(t, prior.StoragePricePerEpoch, converted.StoragePricePerEpoch)
+
+			// Pending proposals keep their keys.
+			proposalCid, err := prior.Cid()
+			require.NoError(t, err)
+			pending, err := pending3.Has(abi.CidKey(proposalCid))
+			require.NoError(t, err)
+			assert.True(t, pending, "deal %d is not pending", dealID)
+		}
+	})
+
+	t.Run("converted state is consistent", func(t *testing.T) {
+		msgs, err := states3.CheckStateInvariants(tree3, totalBalance, v.GetEpoch())
+		require.NoError(t, err)
+		assert.Equal(t, 0, len(msgs.Messages()), strings.Join(msgs.Messages(), "\n"))
+	})
+}
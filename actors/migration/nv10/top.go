@@ -70,14 +70,31 @@ func MigrateStateTree(ctx context.Context, store cbor.IpldStore, actorsRootIn ci
 		return cid.Undef, xerrors.Errorf("invalid migration config with %d workers", cfg.MaxWorkers)
 	}
 
+	// Load input and output state trees
+	adtStore := adt3.WrapStore(ctx, store)
+	actorsIn, err := states2.LoadTree(adtStore, actorsRootIn)
+	if err != nil {
+		return cid.Undef, err
+	}
+	actorsOut, err := states3.NewTree(adtStore)
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	// Multisig signers are normalized to ID addresses, as resolved by the prior init actor.
+	resolve, err := newAddressResolver(ctx, adtStore, actorsIn)
+	if err != nil {
+		return cid.Undef, err
+	}
+
 	// Maps prior version code CIDs to migration functions.
 	var migrations = map[cid.Cid]actorMigration{
 		builtin2.AccountActorCodeID:          nilMigrator{builtin3.AccountActorCodeID},
 		builtin2.CronActorCodeID:             nilMigrator{builtin3.CronActorCodeID},
 		builtin2.InitActorCodeID:             cachedMigration(cache, initMigrator{}),
-		builtin2.MultisigActorCodeID:         cachedMigration(cache, multisigMigrator{}),
+		builtin2.MultisigActorCodeID:         cachedMigration(cache, multisigMigrator{resolve}),
 		builtin2.PaymentChannelActorCodeID:   cachedMigration(cache, paychMigrator{}),
-		builtin2.RewardActorCodeID:           nilMigrator{builtin3.RewardActorCodeID},
+		builtin2.RewardActorCodeID:           rewardMigrator{},
 		builtin2.StorageMarketActorCodeID:    cachedMigration(cache, marketMigrator{}),
 		builtin2.StorageMinerActorCodeID:     cachedMigration(cache, minerMigrator{}),
 		builtin2.StoragePowerActorCodeID:     cachedMigration(cache, powerMigrator{}),
@@ -93,17 +110,6 @@ func MigrateStateTree(ctx context.Context, store cbor.IpldStore, actorsRootIn ci
 	}
 	startTime := time.Now()
 
-	// Load input and output state trees
-	adtStore := adt3.WrapStore(ctx, store)
-	actorsIn, err := states2.LoadTree(adtStore, actorsRootIn)
-	if err != nil {
-		return cid.Undef, err
-	}
-	actorsOut, err := states3.NewTree(adtStore)
-	if err != nil {
-		return cid.Undef, err
-	}
-
 	// Setup synchronization
 	grp, ctx := errgroup.WithContext(ctx)
 	// Input and output queues for workers.
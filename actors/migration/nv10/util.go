@@ -10,6 +10,7 @@ import (
 	amt3 "github.com/filecoin-project/go-amt-ipld/v3"
 	hamt2 "github.com/filecoin-project/go-hamt-ipld/v2"
 	hamt3 "github.com/filecoin-project/go-hamt-ipld/v3"
+	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/rt"
 	adt2 "github.com/filecoin-project/specs-actors/v2/actors/util/adt"
 	cid "github.com/ipfs/go-cid"
@@ -135,6 +136,44 @@ func migrateHAMTAMTRaw(ctx context.Context, store cbor.IpldStore, root cid.Cid,
 	return store.Put(ctx, outRootNodeOuter)
 }
 
+// Migrates a HAMT of AMTs keyed by non-negative integers from v2 to a v3 AMT of AMTs with the same keys,
+// without re-encoding leaf values.
+func migrateIntHAMTAMTToAMTRaw(ctx context.Context, store cbor.IpldStore, root cid.Cid, newOuterBitwidth, newInnerBitwidth int) (cid.Cid, error) {
+	inRootNodeOuter, err := hamt2.LoadNode(ctx, store, root, adt2.HamtOptions...)
+	if err != nil {
+		return cid.Undef, err
+	}
+	newOptsOuter := append(adt3.DefaultAmtOptions, amt3.UseTreeBitWidth(uint(newOuterBitwidth)))
+	outRootNodeOuter, err := amt3.NewAMT(store, newOptsOuter...)
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	if err = inRootNodeOuter.ForEach(ctx, func(k string, val interface{}) error {
+		key, err := abi.ParseIntKey(k)
+		if err != nil {
+			return err
+		}
+		if key < 0 {
+			return xerrors.Errorf("negative key %d cannot index an AMT", key)
+		}
+		var inInner cbg.CborCid
+		if err := inInner.UnmarshalCBOR(bytes.NewReader(val.(*cbg.Deferred).Raw)); err != nil {
+			return err
+		}
+		outInner, err := migrateAMTRaw(ctx, store, cid.Cid(inInner), newInnerBitwidth)
+		if err != nil {
+			return err
+		}
+		c := cbg.CborCid(outInner)
+		return outRootNodeOuter.Set(ctx, uint64(key), &c)
+	}); err != nil {
+		return cid.Undef, err
+	}
+
+	return outRootNodeOuter.Flush(ctx)
+}
+
 type MemMigrationCache struct {
 	MigrationMap sync.Map
 }
@@ -9,6 +9,7 @@ import (
 
 	builtin3 "github.com/filecoin-project/specs-actors/v3/actors/builtin"
 	verifreg3 "github.com/filecoin-project/specs-actors/v3/actors/builtin/verifreg"
+	adt3 "github.com/filecoin-project/specs-actors/v3/actors/util/adt"
 )
 
 type verifregMigrator struct{}
@@ -28,10 +29,19 @@ func (m verifregMigrator) migrateState(ctx context.Context, store cbor.IpldStore
 		return nil, err
 	}
 
+	emptyMapCIDOut, err := adt3.StoreEmptyMap(adt3.WrapStore(ctx, store), builtin3.DefaultHamtBitwidth)
+	if err != nil {
+		return nil, err
+	}
+
 	outState := verifreg3.State{
-		RootKey:         inState.RootKey,
-		Verifiers:       verifiersCIDOut,
-		VerifiedClients: verifiedClientsCIDOut,
+		RootKey:                  inState.RootKey,
+		Verifiers:                verifiersCIDOut,
+		VerifiedClients:          verifiedClientsCIDOut,
+		RemoveDataCapProposalIDs: emptyMapCIDOut,
+		Allocations:              emptyMapCIDOut,
+		NextAllocationId:         0,
+		Claims:                   emptyMapCIDOut,
 	}
 
 	newHead, err := store.Put(ctx, &outState)
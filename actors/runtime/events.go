@@ -0,0 +1,22 @@
+package runtime
+
+// Flags describing how an event entry should be indexed by clients observing the chain.
+type EventFlags uint64
+
+const (
+	// The entry's key should be indexed.
+	EventFlagIndexedKey EventFlags = 1 << iota
+	// The entry's value should be indexed.
+	EventFlagIndexedValue
+
+	EventFlagIndexedAll = EventFlagIndexedKey | EventFlagIndexedValue
+)
+
+// A single key/value entry of an actor event.
+type EventEntry struct {
+	Flags EventFlags
+	// A human-readable key identifying the entry.
+	Key string
+	// The CBOR-encoded value of the entry.
+	Value []byte
+}
@@ -1,7 +1,9 @@
 package proof
 
 import (
+	"github.com/filecoin-project/go-state-types/abi"
 	proof0 "github.com/filecoin-project/specs-actors/actors/runtime/proof"
+	"github.com/ipfs/go-cid"
 )
 
 ///
@@ -59,3 +61,49 @@ type WinningPoStVerifyInfo = proof0.WinningPoStVerifyInfo
 //	Prover            abi.ActorID // used to derive 32-byte prover ID
 //}
 type WindowPoStVerifyInfo = proof0.WindowPoStVerifyInfo
+
+///
+/// Replica updates
+///
+
+// Information needed to verify a replica update proof, which proves that the replica of a sector
+// has been re-encoded to hold new data, without re-sealing.
+type ReplicaUpdateInfo struct {
+	SealProof            abi.RegisteredSealProof // Seal proof type of the sector being updated
+	OldSealedSectorCID   cid.Cid                 // CommR of the sector before the update
+	NewSealedSectorCID   cid.Cid                 // CommR of the updated sector
+	NewUnsealedSectorCID cid.Cid                 // CommD of the new data
+	Proof                []byte
+}
+
+///
+/// Aggregation
+///
+
+// Identifies a scheme for aggregating many seal proofs into a single proof.
+type RegisteredAggregationProof int64
+
+const (
+	RegisteredAggregationProof_SnarkPackV1 = RegisteredAggregationProof(0)
+)
+
+// The inputs to the verification of one sector's seal within an aggregate proof.
+type AggregateSealVerifyInfo struct {
+	Number                abi.SectorNumber
+	Randomness            abi.SealRandomness
+	InteractiveRandomness abi.InteractiveSealRandomness
+
+	// Safe because we get those from the miner actor
+	SealedCID   cid.Cid `checked:"true"` // CommR
+	UnsealedCID cid.Cid `checked:"true"` // CommD
+}
+
+// Information needed to verify a single proof aggregating the seals of many sectors of one miner.
+// All sectors in the aggregate must share the same seal proof type.
+type AggregateSealVerifyProofAndInfos struct {
+	Miner          abi.ActorID
+	SealProof      abi.RegisteredSealProof
+	AggregateProof RegisteredAggregationProof
+	Proof          []byte
+	Infos          []AggregateSealVerifyInfo
+}
@@ -89,6 +89,18 @@ type Runtime interface {
 	// will be rolled back.
 	Send(toAddr addr.Address, methodNum abi.MethodNum, params cbor.Marshaler, value abi.TokenAmount, out cbor.Er) exitcode.ExitCode
 
+	// Sends a message as Send, but limits the gas available to the invoked method (and any messages it sends in turn).
+	// If the limit is exhausted, the callee's state changes are rolled back and exitcode.SysErrOutOfGas is returned,
+	// while the caller continues.
+	// The gas limit must be positive.
+	SendWithGasLimit(toAddr addr.Address, methodNum abi.MethodNum, params cbor.Marshaler, value abi.TokenAmount, gasLimit int64, out cbor.Er) exitcode.ExitCode
+
+	// Sends a message carrying no value, invoking the receiver read-only.
+	// The invoked method, and any message it sends in turn, aborts with exitcode.SysErrForbidden if it attempts
+	// to modify state, create or delete actors, or transfer value.
+	// Intended for queries of other actors, such as a miner's control addresses.
+	StaticSend(toAddr addr.Address, methodNum abi.MethodNum, params cbor.Marshaler, out cbor.Er) exitcode.ExitCode
+
 	// Halts execution upon an error from which the receiver cannot recover. The caller will receive the exitcode and
 	// an empty return value. State changes made within this call will be rolled back.
 	// This method does not return.
@@ -113,6 +125,12 @@ type Runtime interface {
 	// May only be called by the actor itself.
 	DeleteActor(beneficiary addr.Address)
 
+	// Replaces the code of every actor in the state tree whose code is a key of `mapping` with the corresponding
+	// value, leaving the actors' state and balance untouched. Used to upgrade actors to a new version of their code.
+	// May only be called by the System actor.
+	// Aborts if any replacement code is not known to the VM.
+	ReplaceActorCodes(mapping map[cid.Cid]cid.Cid)
+
 	// Returns the total token supply in circulation at the beginning of the current epoch.
 	// The circulating supply is the sum of:
 	// - rewards emitted by the reward actor,
@@ -123,6 +141,10 @@ type Runtime interface {
 	// - deal collateral locked by the storage market actor
 	TotalFilCircSupply() abi.TokenAmount
 
+	// Returns the base fee of the tipset in which the current message is included.
+	// Network fees that scale with chain congestion are priced from it.
+	BaseFee() abi.TokenAmount
+
 	// Provides a Go context for use by HAMT, etc.
 	// The VM is intended to provide an idealised machine abstraction, with infinite storage etc, so this context
 	// should not be used by actor code directly.
@@ -140,6 +162,11 @@ type Runtime interface {
 
 	// Note events that may make debugging easier
 	Log(level rt.LogLevel, msg string, args ...interface{})
+
+	// Emits an event, attributed to the receiver, for observation by clients outside the chain.
+	// Events are recorded alongside the message receipt and have no effect on state. An event emitted
+	// by an invocation that subsequently aborts is discarded along with the invocation's state changes.
+	EmitEvent(entries []EventEntry)
 }
 
 // Store defines the storage module exposed to actors.
@@ -175,6 +202,9 @@ type Syscalls interface {
 	VerifySignature(signature crypto.Signature, signer addr.Address, plaintext []byte) error
 	// Hashes input data using blake2b with 256 bit output.
 	HashBlake2b(data []byte) [32]byte
+	// Computes the CIDv1 of data encoded with an IPLD codec, such as cid.DagCBOR, using a blake2b-256 hash
+	// as for state objects.
+	ComputeCID(codec uint64, data []byte) (cid.Cid, error)
 	// Computes an unsealed sector CID (CommD) from its constituent piece CIDs (CommPs) and sizes.
 	ComputeUnsealedSectorCID(reg abi.RegisteredSealProof, pieces []abi.PieceInfo) (cid.Cid, error)
 	// Verifies a sector seal proof.
@@ -183,8 +213,13 @@ type Syscalls interface {
 
 	BatchVerifySeals(vis map[addr.Address][]proof.SealVerifyInfo) (map[addr.Address][]bool, error)
 
+	// Verifies a single proof aggregating the seal proofs of many sectors.
+	VerifyAggregateSeals(aggregate proof.AggregateSealVerifyProofAndInfos) error
+
 	// Verifies a proof of spacetime.
 	VerifyPoSt(vi proof.WindowPoStVerifyInfo) error
+	// Verifies a proof that a sector's replica has been updated to encode new data.
+	VerifyReplicaUpdate(update proof.ReplicaUpdateInfo) error
 	// Verifies that two block headers provide proof of a consensus fault:
 	// - both headers mined by the same actor
 	// - headers are different
@@ -214,6 +249,7 @@ type StateHandle interface {
 	// The second argument is a function which allows the caller to mutate the state.
 	//
 	// If the state is modified after this function returns, execution will abort.
+	// Transactions may not be nested: calling StateTransaction from within f will abort.
 	//
 	// The gas cost of this method is that of a Store.Put of the mutated state object.
 	//
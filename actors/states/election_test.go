@@ -157,6 +157,7 @@ func constructMinerState(ctx context.Context, t *testing.T, store adt.Store, own
 		SectorSize:                 ssize,
 		WindowPoStPartitionSectors: psize,
 		ConsensusFaultElapsed:      0,
+		Beneficiary:                owner,
 	}
 	infoCid, err := store.Put(ctx, &info)
 	require.NoError(t, err)
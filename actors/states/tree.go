@@ -3,6 +3,7 @@ package states
 import (
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/cbor"
 	states0 "github.com/filecoin-project/specs-actors/actors/states"
 	"github.com/ipfs/go-cid"
 	"golang.org/x/xerrors"
@@ -74,6 +75,54 @@ func (t *Tree) SetActor(addr address.Address, actor *Actor) error {
 	return t.Map.Put(abi.AddrKey(addr), actor)
 }
 
+// Removes the actor at an address, reporting whether it was present.
+func (t *Tree) DeleteActor(addr address.Address) (bool, error) {
+	if addr.Protocol() != address.ID {
+		return false, xerrors.Errorf("non-ID address %v invalid as actor key", addr)
+	}
+	return t.Map.TryDelete(abi.AddrKey(addr))
+}
+
+// Loads an actor, applies a function to it, and stores the result.
+// The actor must be present. If the function returns an error, the tree is not modified.
+func (t *Tree) MutateActor(addr address.Address, fn func(actor *Actor) error) error {
+	actor, found, err := t.GetActor(addr)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return xerrors.Errorf("actor %v not found", addr)
+	}
+	if err := fn(actor); err != nil {
+		return err
+	}
+	return t.SetActor(addr, actor)
+}
+
+// Loads the head state of the actor at an address, reporting whether the actor is present.
+func (t *Tree) GetActorState(addr address.Address, out cbor.Unmarshaler) (bool, error) {
+	actor, found, err := t.GetActor(addr)
+	if err != nil || !found {
+		return false, err
+	}
+	if err := t.Store.Get(t.Store.Context(), actor.Head, out); err != nil {
+		return false, xerrors.Errorf("failed to load state of actor %v: %w", addr, err)
+	}
+	return true, nil
+}
+
+// Stores a new head state for the actor at an address, which must be present.
+func (t *Tree) SetActorState(addr address.Address, state cbor.Marshaler) error {
+	head, err := t.Store.Put(t.Store.Context(), state)
+	if err != nil {
+		return xerrors.Errorf("failed to store state of actor %v: %w", addr, err)
+	}
+	return t.MutateActor(addr, func(actor *Actor) error {
+		actor.Head = head
+		return nil
+	})
+}
+
 // Traverses all entries in the tree.
 func (t *Tree) ForEach(fn func(addr address.Address, actor *Actor) error) error {
 	var val Actor
@@ -9,8 +9,10 @@ import (
 	"github.com/filecoin-project/go-state-types/big"
 	"github.com/ipfs/go-cid"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin/cron"
 	"github.com/filecoin-project/specs-actors/v3/actors/states"
 	"github.com/filecoin-project/specs-actors/v3/support/ipld"
 )
@@ -146,6 +148,74 @@ func TestSetCache(t *testing.T) {
 	}
 }
 
+func TestMutateActor(t *testing.T) {
+	store := ipld.NewADTStore(context.Background())
+	st, err := states.NewTree(store)
+	require.NoError(t, err)
+	a, err := address.NewIDAddress(uint64(222))
+	require.NoError(t, err)
+
+	cronState := cron.ConstructState(cron.BuiltInEntries())
+	head, err := store.Put(store.Context(), cronState)
+	require.NoError(t, err)
+	require.NoError(t, st.SetActor(a, &states.Actor{Code: builtin.CronActorCodeID, Head: head, Balance: big.Zero()}))
+
+	t.Run("mutates balance", func(t *testing.T) {
+		require.NoError(t, st.MutateActor(a, func(act *states.Actor) error {
+			act.Balance = big.NewInt(100)
+			return nil
+		}))
+		act, found, err := st.GetActor(a)
+		require.NoError(t, err)
+		require.True(t, found)
+		require.Equal(t, big.NewInt(100), act.Balance)
+	})
+
+	t.Run("error leaves actor unchanged", func(t *testing.T) {
+		require.Error(t, st.MutateActor(a, func(act *states.Actor) error {
+			act.Balance = big.NewInt(200)
+			return xerrors.New("failed")
+		}))
+		act, _, err := st.GetActor(a)
+		require.NoError(t, err)
+		require.Equal(t, big.NewInt(100), act.Balance)
+	})
+
+	t.Run("missing actor", func(t *testing.T) {
+		missing, err := address.NewIDAddress(uint64(333))
+		require.NoError(t, err)
+		require.Error(t, st.MutateActor(missing, func(act *states.Actor) error { return nil }))
+		var out cron.State
+		found, err := st.GetActorState(missing, &out)
+		require.NoError(t, err)
+		require.False(t, found)
+	})
+
+	t.Run("gets and sets state", func(t *testing.T) {
+		var out cron.State
+		found, err := st.GetActorState(a, &out)
+		require.NoError(t, err)
+		require.True(t, found)
+		require.Equal(t, cronState.Entries, out.Entries)
+
+		require.NoError(t, st.SetActorState(a, cron.ConstructState(nil)))
+		var updated cron.State
+		found, err = st.GetActorState(a, &updated)
+		require.NoError(t, err)
+		require.True(t, found)
+		require.Empty(t, updated.Entries)
+	})
+
+	t.Run("deletes actor", func(t *testing.T) {
+		found, err := st.DeleteActor(a)
+		require.NoError(t, err)
+		require.True(t, found)
+		found, err = st.DeleteActor(a)
+		require.NoError(t, err)
+		require.False(t, found)
+	})
+}
+
 func TestStateTreeConsistency(t *testing.T) {
 	store := ipld.NewADTStore(context.Background())
 	st, err := states.NewTree(store)
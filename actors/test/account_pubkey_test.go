@@ -0,0 +1,36 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v3/support/ipld"
+	vm "github.com/filecoin-project/specs-actors/v3/support/vm"
+)
+
+func TestAccountPubkeyAddressQuery(t *testing.T) {
+	ctx := context.Background()
+	v := vm.NewVMWithSingletons(ctx, t, ipld.NewBlockStoreInMemory())
+	addrs := vm.CreateAccounts(ctx, t, v, 2, big.Mul(big.NewInt(10_000), big.NewInt(1e18)), 93837778)
+	id0, found := v.NormalizeAddress(addrs[0])
+	require.True(t, found)
+
+	t.Run("account returns its pubkey address to any caller", func(t *testing.T) {
+		ret := vm.ApplyOk(t, v, addrs[1], id0, big.Zero(), builtin.MethodsAccount.PubkeyAddress, nil)
+		pubkey, ok := ret.(*address.Address)
+		require.True(t, ok)
+		assert.Equal(t, addrs[0], *pubkey)
+		assert.NotEqual(t, address.ID, pubkey.Protocol())
+	})
+
+	t.Run("query by robust address", func(t *testing.T) {
+		ret := vm.ApplyOk(t, v, addrs[1], addrs[0], big.Zero(), builtin.MethodsAccount.PubkeyAddress, nil)
+		assert.Equal(t, addrs[0], *ret.(*address.Address))
+	})
+}
@@ -112,8 +112,8 @@ func TestCommitPoStFlow(t *testing.T) {
 						{To: builtin.RewardActorAddr, Method: builtin.MethodsReward.ThisEpochReward},
 						{To: builtin.StoragePowerActorAddr, Method: builtin.MethodsPower.CurrentTotalPower},
 
-						// The call to burnt funds indicates the overdue precommit has been penalized
-						{To: builtin.BurntFundsActorAddr, Method: builtin.MethodSend, Value: vm.ExpectAttoFil(precommit.PreCommitDeposit)},
+						// The burn indicates the overdue precommit has been penalized
+						{To: builtin.RewardActorAddr, Method: builtin.MethodsReward.BurnFunds, Value: vm.ExpectAttoFil(precommit.PreCommitDeposit)},
 						{To: builtin.StoragePowerActorAddr, Method: builtin.MethodsPower.EnrollCronEvent},
 					}},
 					//{To: minerAddrs.IDAddress, Method: builtin.MethodsMiner.ConfirmSectorProofsValid},
@@ -316,6 +316,8 @@ func TestCommitPoStFlow(t *testing.T) {
 					{To: minerAddrs.IDAddress, Method: builtin.MethodsMiner.OnDeferredCronEvent, SubInvocations: []vm.ExpectInvocation{
 						{To: builtin.RewardActorAddr, Method: builtin.MethodsReward.ThisEpochReward},
 						{To: builtin.StoragePowerActorAddr, Method: builtin.MethodsPower.CurrentTotalPower},
+						// the missed PoSt is penalised as a detected fault
+						{To: builtin.RewardActorAddr, Method: builtin.MethodsReward.BurnFunds},
 						{To: builtin.StoragePowerActorAddr, Method: builtin.MethodsPower.EnrollCronEvent},
 					}},
 					{To: builtin.RewardActorAddr, Method: builtin.MethodsReward.UpdateNetworkKPI},
@@ -4,14 +4,18 @@ import (
 	"context"
 	"testing"
 
+	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin/miner"
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin/power"
 	"github.com/filecoin-project/specs-actors/v3/support/ipld"
+	tutil "github.com/filecoin-project/specs-actors/v3/support/testing"
 	vm "github.com/filecoin-project/specs-actors/v3/support/vm"
 )
 
@@ -136,3 +140,153 @@ func TestOnEpochTickEnd(t *testing.T) {
 		}},
 	}.Matches(t, v.Invocations()[0])
 }
+
+func TestCronDefersDeadlineEventBeyondBudget(t *testing.T) {
+	ctx := context.Background()
+	v := vm.NewVMWithSingletons(ctx, t, ipld.NewBlockStoreInMemory())
+	addrs := vm.CreateAccounts(ctx, t, v, 1, big.Mul(big.NewInt(10_000), big.NewInt(1e18)), 93837778)
+
+	params := power.CreateMinerParams{Owner: addrs[0], Worker: addrs[0],
+		WindowPoStProofType: abi.RegisteredPoStProof_StackedDrgWindow32GiBV1,
+		Peer:                abi.PeerID("pid")}
+	ret := vm.ApplyOk(t, v, addrs[0], builtin.StoragePowerActorAddr, big.NewInt(1e10), builtin.MethodsPower.CreateMiner, &params)
+	minerAddrs, ok := ret.(*power.CreateMinerReturn)
+	require.True(t, ok)
+
+	cronConfig, ok := vm.ParamsForInvocation(t, v, 0, 0, 0, 0).(*power.EnrollCronEventParams)
+	require.True(t, ok)
+	deadlineEpoch := cronConfig.EventEpoch
+
+	var minerSt miner.State
+	require.NoError(t, v.GetState(minerAddrs.IDAddress, &minerSt))
+	dlIdx := minerSt.CurrentDeadline
+
+	// Fill the deadline epoch with a full budget of events for an unknown miner, enrolled ahead of the
+	// miner's own deadline event, so the deadline event is deferred to the following epoch.
+	var powerSt power.State
+	require.NoError(t, v.GetState(builtin.StoragePowerActorAddr, &powerSt))
+	queue, err := power.LoadCronQueue(v.Store(), powerSt.CronEventQueue)
+	require.NoError(t, err)
+	minerEvents, err := queue.LoadEpoch(deadlineEpoch)
+	require.NoError(t, err)
+	require.Len(t, minerEvents, 1)
+
+	unknownMiner := tutil.NewIDAddr(t, 9999)
+	var events []power.CronEvent
+	for i := 0; i < power.MaxCronEventsPerEpoch; i++ {
+		events = append(events, power.CronEvent{MinerAddr: unknownMiner, CallbackPayload: []byte{}})
+	}
+	require.NoError(t, queue.SetEpoch(deadlineEpoch, append(events, minerEvents...)))
+	powerSt.CronEventQueue, err = queue.Root()
+	require.NoError(t, err)
+	require.NoError(t, v.SetActorState(ctx, builtin.StoragePowerActorAddr, &powerSt))
+
+	// The budget is exhausted before the miner's deadline event is reached.
+	v, err = v.WithEpoch(deadlineEpoch)
+	require.NoError(t, err)
+	vm.ApplyOk(t, v, builtin.CronActorAddr, builtin.StoragePowerActorAddr, big.Zero(), builtin.MethodsPower.OnEpochTickEnd, abi.Empty)
+	vm.ExpectInvocation{
+		To:     builtin.StoragePowerActorAddr,
+		Method: builtin.MethodsPower.OnEpochTickEnd,
+		SubInvocations: []vm.ExpectInvocation{
+			{To: builtin.RewardActorAddr, Method: builtin.MethodsReward.UpdateNetworkKPI},
+		},
+	}.Matches(t, v.Invocations()[0])
+
+	// The deferred deadline event runs one epoch late.
+	v, err = v.WithEpoch(deadlineEpoch + 1)
+	require.NoError(t, err)
+	vm.ApplyOk(t, v, builtin.CronActorAddr, builtin.StoragePowerActorAddr, big.Zero(), builtin.MethodsPower.OnEpochTickEnd, abi.Empty)
+	vm.ExpectInvocation{
+		To:     builtin.StoragePowerActorAddr,
+		Method: builtin.MethodsPower.OnEpochTickEnd,
+		SubInvocations: []vm.ExpectInvocation{
+			{To: minerAddrs.IDAddress, Method: builtin.MethodsMiner.OnDeferredCronEvent, Params: vm.ExpectBytes(cronConfig.Payload)},
+			{To: builtin.RewardActorAddr, Method: builtin.MethodsReward.UpdateNetworkKPI},
+		},
+	}.Matches(t, v.Invocations()[0])
+
+	// The miner processed the deadline it had recorded, and enrolled for the end of the next one.
+	require.NoError(t, v.GetState(minerAddrs.IDAddress, &minerSt))
+	assert.Equal(t, (dlIdx+1)%miner.WPoStPeriodDeadlines, minerSt.CurrentDeadline)
+
+	require.NoError(t, v.GetState(builtin.StoragePowerActorAddr, &powerSt))
+	queue, err = power.LoadCronQueue(v.Store(), powerSt.CronEventQueue)
+	require.NoError(t, err)
+	nextEvents, err := queue.LoadEpoch(deadlineEpoch + miner.WPoStChallengeWindow)
+	require.NoError(t, err)
+	require.Len(t, nextEvents, 1)
+	assert.Equal(t, minerAddrs.IDAddress, nextEvents[0].MinerAddr)
+}
+
+func TestCronCalleeAbortDoesNotBlockOtherMiners(t *testing.T) {
+	ctx := context.Background()
+	v := vm.NewVMWithSingletons(ctx, t, ipld.NewBlockStoreInMemory())
+	addrs := vm.CreateAccounts(ctx, t, v, 2, big.Mul(big.NewInt(10_000), big.NewInt(1e18)), 93837778)
+
+	var minerIDs []address.Address
+	var cronConfigs []*power.EnrollCronEventParams
+	for i, a := range addrs {
+		params := power.CreateMinerParams{Owner: a, Worker: a,
+			WindowPoStProofType: abi.RegisteredPoStProof_StackedDrgWindow32GiBV1,
+			Peer:                abi.PeerID("pid")}
+		ret := vm.ApplyOk(t, v, a, builtin.StoragePowerActorAddr, big.NewInt(1e10), builtin.MethodsPower.CreateMiner, &params)
+		minerAddrs, ok := ret.(*power.CreateMinerReturn)
+		require.True(t, ok)
+		minerIDs = append(minerIDs, minerAddrs.IDAddress)
+
+		cronConfig, ok := vm.ParamsForInvocation(t, v, i, 0, 0, 0).(*power.EnrollCronEventParams)
+		require.True(t, ok)
+		cronConfigs = append(cronConfigs, cronConfig)
+	}
+
+	// Break the first miner's state so that its deadline callback aborts when it reads its info.
+	v.SkipConsistencyCheck()
+	var brokenSt miner.State
+	require.NoError(t, v.GetState(minerIDs[0], &brokenSt))
+	brokenSt.Info = tutil.MakeCID("missing miner info", nil)
+	require.NoError(t, v.SetActorState(ctx, minerIDs[0], &brokenSt))
+
+	// Move both miners' deadline events to the same epoch, with the broken miner's first.
+	var powerSt power.State
+	require.NoError(t, v.GetState(builtin.StoragePowerActorAddr, &powerSt))
+	queue, err := power.LoadCronQueue(v.Store(), powerSt.CronEventQueue)
+	require.NoError(t, err)
+	cronEpoch := cronConfigs[0].EventEpoch
+	var events []power.CronEvent
+	for i, cfg := range cronConfigs {
+		if cfg.EventEpoch > cronEpoch {
+			cronEpoch = cfg.EventEpoch
+		}
+		require.NoError(t, queue.SetEpoch(cfg.EventEpoch, nil))
+		events = append(events, power.CronEvent{MinerAddr: minerIDs[i], CallbackPayload: cfg.Payload})
+	}
+	require.NoError(t, queue.SetEpoch(cronEpoch, events))
+	powerSt.CronEventQueue, err = queue.Root()
+	require.NoError(t, err)
+	require.NoError(t, v.SetActorState(ctx, builtin.StoragePowerActorAddr, &powerSt))
+
+	// The first callee aborts, but cron completes and still invokes the second miner.
+	v, err = v.WithEpoch(cronEpoch)
+	require.NoError(t, err)
+	vm.ApplyOk(t, v, builtin.CronActorAddr, builtin.StoragePowerActorAddr, big.Zero(), builtin.MethodsPower.OnEpochTickEnd, abi.Empty)
+	vm.ExpectInvocation{
+		To:     builtin.StoragePowerActorAddr,
+		Method: builtin.MethodsPower.OnEpochTickEnd,
+		SubInvocations: []vm.ExpectInvocation{
+			{To: minerIDs[0], Method: builtin.MethodsMiner.OnDeferredCronEvent, Exitcode: exitcode.ErrNotFound},
+			{To: minerIDs[1], Method: builtin.MethodsMiner.OnDeferredCronEvent},
+			{To: builtin.RewardActorAddr, Method: builtin.MethodsReward.UpdateNetworkKPI},
+		},
+	}.Matches(t, v.Invocations()[0])
+
+	// Only the failing miner is flagged by removal of its claim.
+	require.NoError(t, v.GetState(builtin.StoragePowerActorAddr, &powerSt))
+	_, found, err := powerSt.GetClaim(v.Store(), minerIDs[0])
+	require.NoError(t, err)
+	assert.False(t, found)
+	_, found, err = powerSt.GetClaim(v.Store(), minerIDs[1])
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, int64(1), powerSt.MinerCount)
+}
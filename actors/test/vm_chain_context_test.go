@@ -0,0 +1,131 @@
+package test_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/cbor"
+	"github.com/filecoin-project/go-state-types/crypto"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	cid "github.com/ipfs/go-cid"
+	mh "github.com/multiformats/go-multihash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cbg "github.com/whyrusleeping/cbor-gen"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v3/actors/runtime"
+	"github.com/filecoin-project/specs-actors/v3/support/ipld"
+	tutil "github.com/filecoin-project/specs-actors/v3/support/testing"
+	vm "github.com/filecoin-project/specs-actors/v3/support/vm"
+)
+
+func TestVMChainContext(t *testing.T) {
+	ctx := context.Background()
+	puppetAddr := tutil.NewIDAddr(t, 1000)
+	setup := func(t *testing.T) (*vm.VM, func(method abi.MethodNum, params cbor.Marshaler) cbor.Marshaler) {
+		v := vm.NewVMWithSingletons(ctx, t, ipld.NewBlockStoreInMemory())
+		var st cbg.CborInt
+		vm.InstallActor(ctx, t, v, contextPuppet{}, &st, puppetAddr, big.Zero())
+		sender := vm.CreateAccounts(ctx, t, v, 1, vm.FIL, 1)[0]
+		call := func(method abi.MethodNum, params cbor.Marshaler) cbor.Marshaler {
+			ret, code := v.ApplyMessage(sender, puppetAddr, big.Zero(), method, params)
+			require.Equal(t, exitcode.Ok, code)
+			return ret
+		}
+		return v, call
+	}
+	drawBeacon := func(call func(abi.MethodNum, cbor.Marshaler) cbor.Marshaler, epoch int64) []byte {
+		e := cbg.CborInt(epoch)
+		return *call(2, &e).(*builtin.CBORBytes)
+	}
+
+	t.Run("fixed randomness by default", func(t *testing.T) {
+		_, call := setup(t)
+		assert.Equal(t, drawBeacon(call, 1), drawBeacon(call, 2))
+	})
+
+	t.Run("deterministic randomness", func(t *testing.T) {
+		v, call := setup(t)
+		v.SetRandomness(vm.DeterministicRandomness([]byte("beacon")), vm.DeterministicRandomness([]byte("tickets")))
+		r1 := drawBeacon(call, 1)
+		assert.Len(t, r1, 32)
+		assert.Equal(t, r1, drawBeacon(call, 1))
+		assert.NotEqual(t, r1, drawBeacon(call, 2))
+
+		source := vm.DeterministicRandomness([]byte("beacon"))
+		assert.Equal(t, abi.Randomness(r1), source(crypto.DomainSeparationTag_WindowedPoStChallengeSeed, 1, []byte("entropy")))
+		assert.NotEqual(t, abi.Randomness(r1), source(crypto.DomainSeparationTag_SealRandomness, 1, []byte("entropy")))
+		assert.NotEqual(t, abi.Randomness(r1), source(crypto.DomainSeparationTag_WindowedPoStChallengeSeed, 1, []byte("other")))
+	})
+
+	t.Run("tipset context supplies circulating supply and base fee by epoch", func(t *testing.T) {
+		v, _ := setup(t)
+		v.SetCirculatingSupply(big.NewInt(5))
+		assert.Equal(t, big.Zero(), v.BaseFee())
+
+		v.SetTipsetContext(vm.TipsetContext{
+			BaseFee:           func(epoch abi.ChainEpoch) abi.TokenAmount { return big.NewInt(int64(epoch) * 10) },
+			CirculatingSupply: func(epoch abi.ChainEpoch) abi.TokenAmount { return big.NewInt(int64(epoch) * 100) },
+		})
+		v, err := v.WithEpoch(7)
+		require.NoError(t, err)
+		assert.Equal(t, big.NewInt(70), v.BaseFee())
+		assert.Equal(t, big.NewInt(700), v.GetCirculatingSupply())
+
+		sender := vm.CreateAccounts(ctx, t, v, 1, vm.FIL, 2)[0]
+		ret, code := v.ApplyMessage(sender, puppetAddr, big.Zero(), 3, nil)
+		require.Equal(t, exitcode.Ok, code)
+		assert.Equal(t, big.NewInt(700), *ret.(*abi.TokenAmount))
+
+		ret, code = v.ApplyMessage(sender, puppetAddr, big.Zero(), 4, nil)
+		require.Equal(t, exitcode.Ok, code)
+		assert.Equal(t, big.NewInt(70), *ret.(*abi.TokenAmount))
+	})
+}
+
+// An actor exposing the runtime's randomness, circulating supply and base fee.
+type contextPuppet struct{}
+
+func (a contextPuppet) Exports() []interface{} {
+	return []interface{}{
+		builtin.MethodConstructor: nil,
+		2:                         a.DrawBeacon,
+		3:                         a.CirculatingSupply,
+		4:                         a.BaseFee,
+	}
+}
+
+func (a contextPuppet) Code() cid.Cid {
+	c, err := cid.V1Builder{Codec: cid.Raw, MhType: mh.IDENTITY}.Sum([]byte("fil/test/contextpuppet"))
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func (a contextPuppet) State() cbor.Er {
+	return new(cbg.CborInt)
+}
+
+var _ runtime.VMActor = contextPuppet{}
+
+func (a contextPuppet) DrawBeacon(rt runtime.Runtime, epoch *cbg.CborInt) *builtin.CBORBytes {
+	rt.ValidateImmediateCallerAcceptAny()
+	r := builtin.CBORBytes(rt.GetRandomnessFromBeacon(crypto.DomainSeparationTag_WindowedPoStChallengeSeed, abi.ChainEpoch(*epoch), []byte("entropy")))
+	return &r
+}
+
+func (a contextPuppet) CirculatingSupply(rt runtime.Runtime, _ *abi.EmptyValue) *abi.TokenAmount {
+	rt.ValidateImmediateCallerAcceptAny()
+	supply := rt.TotalFilCircSupply()
+	return &supply
+}
+
+func (a contextPuppet) BaseFee(rt runtime.Runtime, _ *abi.EmptyValue) *abi.TokenAmount {
+	rt.ValidateImmediateCallerAcceptAny()
+	fee := rt.BaseFee()
+	return &fee
+}
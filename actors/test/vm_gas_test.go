@@ -0,0 +1,85 @@
+package test_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin/market"
+	"github.com/filecoin-project/specs-actors/v3/actors/util/adt"
+	"github.com/filecoin-project/specs-actors/v3/support/ipld"
+	vm "github.com/filecoin-project/specs-actors/v3/support/vm"
+)
+
+func TestVMGasMetering(t *testing.T) {
+	ctx := context.Background()
+	setup := func(t *testing.T) (*vm.VM, address.Address) {
+		v := vm.NewVMWithSingletons(ctx, t, ipld.NewBlockStoreInMemory())
+		caller := vm.CreateAccounts(ctx, t, v, 1, big.Mul(big.NewInt(6), vm.FIL), 93837778)[0]
+		return v, caller
+	}
+
+	t.Run("messages are not metered by default", func(t *testing.T) {
+		v, caller := setup(t)
+		vm.ApplyOk(t, v, caller, builtin.StorageMarketActorAddr, vm.FIL, builtin.MethodsMarket.AddBalance, &caller)
+		assert.Equal(t, int64(0), v.GasUsed())
+	})
+
+	t.Run("value transfer is charged for the invocation", func(t *testing.T) {
+		v, caller := setup(t)
+		v.SetPriceList(vm.ScheduledPriceList{ComputeGasMulti: 1, SendBase: 1000, SendTransferFunds: 100, SendTransferOnlyPremium: 10})
+		vm.ApplyOk(t, v, caller, builtin.BurntFundsActorAddr, big.NewInt(1), builtin.MethodSend, nil)
+		assert.Equal(t, int64(1110), v.GasUsed())
+	})
+
+	t.Run("state reads and writes deep in a call are charged", func(t *testing.T) {
+		v, caller := setup(t)
+		v.SetPriceList(vm.ScheduledPriceList{ComputeGasMulti: 1, IpldGetBase: 1})
+		vm.ApplyOk(t, v, caller, builtin.StorageMarketActorAddr, vm.FIL, builtin.MethodsMarket.AddBalance, &caller)
+		assert.Greater(t, v.GasUsed(), int64(0))
+
+		v.SetPriceList(vm.ScheduledPriceList{ComputeGasMulti: 1, IpldPutBase: 1})
+		vm.ApplyOk(t, v, caller, builtin.StorageMarketActorAddr, vm.FIL, builtin.MethodsMarket.AddBalance, &caller)
+		assert.Greater(t, v.GasUsed(), int64(0))
+	})
+
+	t.Run("repeated message uses consistent gas", func(t *testing.T) {
+		v, caller := setup(t)
+		v.SetPriceList(vm.DefaultPriceList)
+		vm.ApplyOk(t, v, caller, builtin.StorageMarketActorAddr, vm.FIL, builtin.MethodsMarket.AddBalance, &caller)
+		first := v.GasUsed()
+		assert.Greater(t, first, int64(0))
+		assert.Less(t, first, vm.BlockGasLimit)
+
+		vm.ApplyOk(t, v, caller, builtin.StorageMarketActorAddr, vm.FIL, builtin.MethodsMarket.AddBalance, &caller)
+		vm.RequireGasWithin(t, v, first, 0.1)
+	})
+
+	t.Run("message runs out of gas and is rolled back", func(t *testing.T) {
+		v, caller := setup(t)
+		v.SetPriceList(vm.DefaultPriceList)
+		vm.ApplyOk(t, v, caller, builtin.StorageMarketActorAddr, vm.FIL, builtin.MethodsMarket.AddBalance, &caller)
+		needed := v.GasUsed()
+
+		v.SetGasLimit(needed / 2)
+		_, code := v.ApplyMessage(caller, builtin.StorageMarketActorAddr, vm.FIL, builtin.MethodsMarket.AddBalance, &caller)
+		assert.Equal(t, exitcode.SysErrOutOfGas, code)
+		assert.Equal(t, needed/2, v.GasUsed())
+
+		callerID, found := v.NormalizeAddress(caller)
+		require.True(t, found)
+		var st market.State
+		require.NoError(t, v.GetState(builtin.StorageMarketActorAddr, &st))
+		escrow, err := adt.AsBalanceTable(v.Store(), st.EscrowTable)
+		require.NoError(t, err)
+		balance, err := escrow.Get(callerID)
+		require.NoError(t, err)
+		assert.Equal(t, vm.FIL, balance)
+	})
+}
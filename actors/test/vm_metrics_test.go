@@ -0,0 +1,79 @@
+package test_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	cid "github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin/market"
+	"github.com/filecoin-project/specs-actors/v3/support/ipld"
+	vm "github.com/filecoin-project/specs-actors/v3/support/vm"
+)
+
+func TestVMMetrics(t *testing.T) {
+	ctx := context.Background()
+	v := vm.NewVMWithSingletons(ctx, t, ipld.NewBlockStoreInMemory())
+	addrs := vm.CreateAccounts(ctx, t, v, 1, big.Mul(big.NewInt(6), vm.FIL), 93837778)
+	caller := addrs[0]
+
+	metrics := newCountingMetrics()
+	v.SetMetrics(metrics)
+
+	addBalance := methodMetric{builtin.StorageMarketActorCodeID, builtin.MethodsMarket.AddBalance}
+	withdraw := methodMetric{builtin.StorageMarketActorCodeID, builtin.MethodsMarket.WithdrawBalance}
+
+	vm.ApplyOk(t, v, caller, builtin.StorageMarketActorAddr, vm.FIL, builtin.MethodsMarket.AddBalance, &caller)
+	assert.Equal(t, 1, metrics.invoked[addBalance])
+	assert.Empty(t, metrics.aborted)
+	assert.Greater(t, metrics.stateBytes[builtin.StorageMarketActorCodeID], uint64(0))
+
+	_, code := v.ApplyMessage(caller, builtin.StorageMarketActorAddr, big.Zero(), builtin.MethodsMarket.WithdrawBalance, &market.WithdrawBalanceParams{
+		ProviderOrClientAddress: caller,
+		Amount:                  big.NewInt(-1),
+	})
+	require.Equal(t, exitcode.ErrIllegalArgument, code)
+	assert.Equal(t, 1, metrics.invoked[withdraw])
+	assert.Equal(t, map[methodMetric]exitcode.ExitCode{withdraw: exitcode.ErrIllegalArgument}, metrics.aborted)
+
+	// Plain value transfers are not method invocations.
+	vm.ApplyOk(t, v, caller, builtin.BurntFundsActorAddr, big.NewInt(1), builtin.MethodSend, nil)
+	assert.Len(t, metrics.invoked, 2)
+}
+
+type methodMetric struct {
+	code   cid.Cid
+	method abi.MethodNum
+}
+
+type countingMetrics struct {
+	invoked    map[methodMetric]int
+	aborted    map[methodMetric]exitcode.ExitCode
+	stateBytes map[cid.Cid]uint64
+}
+
+func newCountingMetrics() *countingMetrics {
+	return &countingMetrics{
+		invoked:    map[methodMetric]int{},
+		aborted:    map[methodMetric]exitcode.ExitCode{},
+		stateBytes: map[cid.Cid]uint64{},
+	}
+}
+
+func (m *countingMetrics) MethodInvoked(code cid.Cid, method abi.MethodNum) {
+	m.invoked[methodMetric{code, method}]++
+}
+
+func (m *countingMetrics) MethodAborted(code cid.Cid, method abi.MethodNum, exitCode exitcode.ExitCode) {
+	m.aborted[methodMetric{code, method}] = exitCode
+}
+
+func (m *countingMetrics) StateBytesWritten(code cid.Cid, size uint64) {
+	m.stateBytes[code] += size
+}
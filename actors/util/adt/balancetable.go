@@ -103,3 +103,15 @@ func (t *BalanceTable) Total() (abi.TokenAmount, error) {
 	})
 	return total, err
 }
+
+// Iterates the non-zero balances in the table.
+func (t *BalanceTable) ForEach(cb func(key addr.Address, balance abi.TokenAmount) error) error {
+	var cur abi.TokenAmount
+	return (*Map)(t).ForEach(&cur, func(k string) error {
+		key, err := addr.NewFromBytes([]byte(k))
+		if err != nil {
+			return xerrors.Errorf("invalid balance table key: %w", err)
+		}
+		return cb(key, cur.Copy())
+	})
+}
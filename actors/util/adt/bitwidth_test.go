@@ -0,0 +1,121 @@
+package adt_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	cbg "github.com/whyrusleeping/cbor-gen"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/util/adt"
+	"github.com/filecoin-project/specs-actors/v3/support/ipld"
+)
+
+// These benchmarks report the block store IO incurred by a single mutation (flushed) and a single read
+// of a collection loaded from its root, for a range of bitwidths and collection sizes.
+// Store reads and writes are what is charged gas on chain, so the per-op metrics are the interesting output:
+// narrow nodes make small collections cheaper, while large collections need more fan-out to stay shallow.
+
+var benchBitwidths = []int{2, 3, 4, 5, 6, 7, 8}
+var benchSizes = []int{10, 1_000, 100_000}
+
+func BenchmarkMapBitwidth(b *testing.B) {
+	for _, size := range benchSizes {
+		for _, bw := range benchBitwidths {
+			b.Run(fmt.Sprintf("size=%d/bitwidth=%d", size, bw), func(b *testing.B) {
+				bs := ipld.NewMetricsBlockStore(ipld.NewBlockStoreInMemory())
+				store := adt.WrapBlockStore(context.Background(), bs)
+				m, err := adt.MakeEmptyMap(store, bw)
+				requireNoErr(b, err)
+				for i := 0; i < size; i++ {
+					requireNoErr(b, m.Put(abi.UIntKey(uint64(i)), cborInt(i)))
+				}
+				root, err := m.Root()
+				requireNoErr(b, err)
+
+				benchIO(b, bs, func(i int) {
+					m, err := adt.AsMap(store, root, bw)
+					requireNoErr(b, err)
+					var out cbg.CborInt
+					found, err := m.Get(abi.UIntKey(uint64(i%size)), &out)
+					requireNoErr(b, err)
+					if !found {
+						b.Fatalf("key %d not found", i%size)
+					}
+				}, func(i int) {
+					m, err := adt.AsMap(store, root, bw)
+					requireNoErr(b, err)
+					requireNoErr(b, m.Put(abi.UIntKey(uint64(i%size)), cborInt(-i)))
+					_, err = m.Root()
+					requireNoErr(b, err)
+				})
+			})
+		}
+	}
+}
+
+func BenchmarkArrayBitwidth(b *testing.B) {
+	for _, size := range benchSizes {
+		for _, bw := range benchBitwidths {
+			b.Run(fmt.Sprintf("size=%d/bitwidth=%d", size, bw), func(b *testing.B) {
+				bs := ipld.NewMetricsBlockStore(ipld.NewBlockStoreInMemory())
+				store := adt.WrapBlockStore(context.Background(), bs)
+				arr, err := adt.MakeEmptyArray(store, bw)
+				requireNoErr(b, err)
+				for i := 0; i < size; i++ {
+					requireNoErr(b, arr.Set(uint64(i), cborInt(i)))
+				}
+				root, err := arr.Root()
+				requireNoErr(b, err)
+
+				benchIO(b, bs, func(i int) {
+					arr, err := adt.AsArray(store, root, bw)
+					requireNoErr(b, err)
+					var out cbg.CborInt
+					found, err := arr.Get(uint64(i%size), &out)
+					requireNoErr(b, err)
+					if !found {
+						b.Fatalf("index %d not found", i%size)
+					}
+				}, func(i int) {
+					arr, err := adt.AsArray(store, root, bw)
+					requireNoErr(b, err)
+					requireNoErr(b, arr.Set(uint64(i%size), cborInt(-i)))
+					_, err = arr.Root()
+					requireNoErr(b, err)
+				})
+			})
+		}
+	}
+}
+
+// Runs read and write b.N times each, reporting the average block store operations and bytes per op.
+func benchIO(b *testing.B, bs *ipld.MetricsBlockStore, read, write func(i int)) {
+	reads, readBytes := bs.ReadCount(), bs.ReadSize()
+	writes, writeBytes := bs.WriteCount(), bs.WriteSize()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		read(i)
+		write(i)
+	}
+	b.StopTimer()
+
+	n := float64(b.N)
+	b.ReportMetric(float64(bs.ReadCount()-reads)/n, "reads/op")
+	b.ReportMetric(float64(bs.ReadSize()-readBytes)/n, "readbytes/op")
+	b.ReportMetric(float64(bs.WriteCount()-writes)/n, "writes/op")
+	b.ReportMetric(float64(bs.WriteSize()-writeBytes)/n, "writebytes/op")
+}
+
+func cborInt(i int) *cbg.CborInt {
+	v := cbg.CborInt(i)
+	return &v
+}
+
+func requireNoErr(b *testing.B, err error) {
+	if err != nil {
+		b.Fatal(err)
+	}
+}
@@ -0,0 +1,124 @@
+package adt
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/cbor"
+	cid "github.com/ipfs/go-cid"
+	cbg "github.com/whyrusleeping/cbor-gen"
+	"golang.org/x/xerrors"
+
+	vmr "github.com/filecoin-project/specs-actors/v3/actors/runtime"
+)
+
+// CachedStore buffers the blocks put during an actor call in memory, deferring writes to the underlying store
+// until Flush. Blocks are keyed by CID, so identical blocks are buffered once, and only blocks reachable from
+// the flushed roots are written, so intermediate HAMT and AMT nodes superseded within the call are never stored.
+// Reads are served from the buffer before falling back to the underlying store.
+type CachedStore struct {
+	Store
+	blocks map[cid.Cid][]byte
+}
+
+var _ Store = &CachedStore{}
+
+// Wraps a store with a write buffer.
+func NewCachedStore(store Store) *CachedStore {
+	return &CachedStore{
+		Store:  store,
+		blocks: make(map[cid.Cid][]byte),
+	}
+}
+
+// Adapts a Runtime as a buffered ADT store.
+func AsCachedStore(rt vmr.Runtime) *CachedStore {
+	return NewCachedStore(AsStore(rt))
+}
+
+func (s *CachedStore) Get(ctx context.Context, c cid.Cid, out interface{}) error {
+	data, ok := s.blocks[c]
+	if !ok {
+		return s.Store.Get(ctx, c, out)
+	}
+	um, ok := out.(cbor.Unmarshaler)
+	if !ok {
+		return xerrors.Errorf("object %T does not implement UnmarshalCBOR", out)
+	}
+	return um.UnmarshalCBOR(bytes.NewReader(data))
+}
+
+func (s *CachedStore) Put(_ context.Context, v interface{}) (cid.Cid, error) {
+	m, ok := v.(cbor.Marshaler)
+	if !ok {
+		return cid.Undef, xerrors.Errorf("object %T does not implement MarshalCBOR", v)
+	}
+	var buf bytes.Buffer
+	if err := m.MarshalCBOR(&buf); err != nil {
+		return cid.Undef, err
+	}
+	c, err := abi.CidBuilder.Sum(buf.Bytes())
+	if err != nil {
+		return cid.Undef, err
+	}
+	s.blocks[c] = buf.Bytes()
+	return c, nil
+}
+
+// Flush writes the buffered blocks reachable from the given roots to the underlying store, and clears the buffer.
+// Links to blocks that are not buffered are assumed to be present in the underlying store already.
+func (s *CachedStore) Flush(roots ...cid.Cid) error {
+	ctx := s.Context()
+	pending := roots
+	for len(pending) > 0 {
+		c := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		data, ok := s.blocks[c]
+		if !ok {
+			continue
+		}
+		// Remove the block before descending so that shared sub-trees are written only once.
+		delete(s.blocks, c)
+
+		if err := cbg.ScanForLinks(bytes.NewReader(data), func(l cid.Cid) {
+			pending = append(pending, l)
+		}); err != nil {
+			return xerrors.Errorf("failed to scan links of block %v: %w", c, err)
+		}
+		written, err := s.Store.Put(ctx, rawBlock(data))
+		if err != nil {
+			return xerrors.Errorf("failed to write block %v: %w", c, err)
+		}
+		if written != c {
+			return xerrors.Errorf("block %v written with mismatched CID %v", c, written)
+		}
+	}
+	s.blocks = make(map[cid.Cid][]byte)
+	return nil
+}
+
+// FlushLinks flushes the blocks reachable from the links of an object, typically an actor state
+// about to be committed.
+func (s *CachedStore) FlushLinks(o cbor.Marshaler) error {
+	var buf bytes.Buffer
+	if err := o.MarshalCBOR(&buf); err != nil {
+		return err
+	}
+	var roots []cid.Cid
+	if err := cbg.ScanForLinks(&buf, func(l cid.Cid) {
+		roots = append(roots, l)
+	}); err != nil {
+		return xerrors.Errorf("failed to scan links: %w", err)
+	}
+	return s.Flush(roots...)
+}
+
+// Serialized block data, written verbatim.
+type rawBlock []byte
+
+func (b rawBlock) MarshalCBOR(w io.Writer) error {
+	_, err := w.Write(b)
+	return err
+}
@@ -0,0 +1,93 @@
+package adt_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cbg "github.com/whyrusleeping/cbor-gen"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v3/actors/util/adt"
+	"github.com/filecoin-project/specs-actors/v3/support/ipld"
+)
+
+func TestCachedStore(t *testing.T) {
+	ctx := context.Background()
+	setup := func() (*ipld.MetricsBlockStore, adt.Store, *adt.CachedStore) {
+		bs := ipld.NewMetricsBlockStore(ipld.NewBlockStoreInMemory())
+		underlying := adt.WrapBlockStore(ctx, bs)
+		return bs, underlying, adt.NewCachedStore(underlying)
+	}
+
+	t.Run("puts are buffered until flush", func(t *testing.T) {
+		bs, underlying, store := setup()
+		c, err := store.Put(ctx, cborInt(7))
+		require.NoError(t, err)
+		assert.Equal(t, uint64(0), bs.WriteCount())
+
+		var out cbg.CborInt
+		require.NoError(t, store.Get(ctx, c, &out))
+		assert.Equal(t, cbg.CborInt(7), out)
+		assert.Error(t, underlying.Get(ctx, c, &out))
+
+		require.NoError(t, store.Flush(c))
+		assert.Equal(t, uint64(1), bs.WriteCount())
+		require.NoError(t, underlying.Get(ctx, c, &out))
+		assert.Equal(t, cbg.CborInt(7), out)
+	})
+
+	t.Run("identical blocks are written once", func(t *testing.T) {
+		bs, _, store := setup()
+		c1, err := store.Put(ctx, cborInt(7))
+		require.NoError(t, err)
+		c2, err := store.Put(ctx, cborInt(7))
+		require.NoError(t, err)
+		assert.Equal(t, c1, c2)
+
+		require.NoError(t, store.Flush(c1, c2))
+		assert.Equal(t, uint64(1), bs.WriteCount())
+	})
+
+	t.Run("only blocks reachable from roots are written", func(t *testing.T) {
+		bs, underlying, store := setup()
+		m, err := adt.MakeEmptyMap(store, builtin.DefaultHamtBitwidth)
+		require.NoError(t, err)
+		for i := 0; i < 100; i++ {
+			require.NoError(t, m.Put(abi.UIntKey(uint64(i)), cborInt(i)))
+			// Flush the HAMT repeatedly, leaving superseded nodes in the buffer.
+			_, err := m.Root()
+			require.NoError(t, err)
+		}
+		root, err := m.Root()
+		require.NoError(t, err)
+		unreachable, err := store.Put(ctx, cborInt(-1))
+		require.NoError(t, err)
+
+		require.NoError(t, store.Flush(root))
+
+		// The map is complete in the underlying store.
+		loaded, err := adt.AsMap(underlying, root, builtin.DefaultHamtBitwidth)
+		require.NoError(t, err)
+		keys, err := loaded.CollectKeys()
+		require.NoError(t, err)
+		assert.Len(t, keys, 100)
+
+		// Only the final nodes were written.
+		written := bs.WriteCount()
+		fresh := ipld.NewMetricsBlockStore(ipld.NewBlockStoreInMemory())
+		copied, err := adt.MakeEmptyMap(adt.WrapBlockStore(ctx, fresh), builtin.DefaultHamtBitwidth)
+		require.NoError(t, err)
+		for i := 0; i < 100; i++ {
+			require.NoError(t, copied.Put(abi.UIntKey(uint64(i)), cborInt(i)))
+		}
+		_, err = copied.Root()
+		require.NoError(t, err)
+		assert.Equal(t, fresh.WriteCount(), written)
+
+		var out cbg.CborInt
+		assert.Error(t, underlying.Get(ctx, unreachable, &out))
+	})
+}
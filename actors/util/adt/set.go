@@ -3,6 +3,7 @@ package adt
 import (
 	"github.com/filecoin-project/go-state-types/abi"
 	cid "github.com/ipfs/go-cid"
+	"golang.org/x/xerrors"
 )
 
 // Set interprets a Map as a set, storing keys (with empty values) in a HAMT.
@@ -69,3 +70,66 @@ func (h *Set) ForEach(cb func(k string) error) error {
 func (h *Set) CollectKeys() (out []string, err error) {
 	return h.m.CollectKeys()
 }
+
+// AddMany adds each of `keys` to the set.
+func (h *Set) AddMany(keys ...abi.Keyer) error {
+	for _, k := range keys {
+		if err := h.m.Put(k, nil); err != nil {
+			return xerrors.Errorf("failed to add key %v to set: %w", k.Key(), err)
+		}
+	}
+	return nil
+}
+
+// Union adds every key of `other` to the set.
+func (h *Set) Union(other *Set) error {
+	keys, err := other.CollectKeys()
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if err := h.m.Put(rawKey(k), nil); err != nil {
+			return xerrors.Errorf("failed to add key %v to set: %w", k, err)
+		}
+	}
+	return nil
+}
+
+// Intersect removes from the set every key that is not in `other`.
+func (h *Set) Intersect(other *Set) error {
+	keys, err := h.CollectKeys()
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if found, err := other.Has(rawKey(k)); err != nil {
+			return err
+		} else if !found {
+			if err := h.m.Delete(rawKey(k)); err != nil {
+				return xerrors.Errorf("failed to remove key %v from set: %w", k, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Difference removes from the set every key that is in `other`.
+func (h *Set) Difference(other *Set) error {
+	keys, err := other.CollectKeys()
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if _, err := h.m.TryDelete(rawKey(k)); err != nil {
+			return xerrors.Errorf("failed to remove key %v from set: %w", k, err)
+		}
+	}
+	return nil
+}
+
+// A key already in the encoded form yielded by iteration.
+type rawKey string
+
+func (k rawKey) Key() string {
+	return string(k)
+}
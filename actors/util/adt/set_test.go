@@ -0,0 +1,78 @@
+package adt_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v3/actors/util/adt"
+	"github.com/filecoin-project/specs-actors/v3/support/ipld"
+)
+
+func TestSetBulkOperations(t *testing.T) {
+	ctx := context.Background()
+	store := ipld.NewADTStore(ctx)
+
+	makeSet := func(keys ...uint64) *adt.Set {
+		s, err := adt.MakeEmptySet(store, builtin.DefaultHamtBitwidth)
+		require.NoError(t, err)
+		var keyers []abi.Keyer
+		for _, k := range keys {
+			keyers = append(keyers, abi.UIntKey(k))
+		}
+		require.NoError(t, s.AddMany(keyers...))
+		return s
+	}
+	members := func(s *adt.Set) []uint64 {
+		var out []uint64
+		require.NoError(t, s.ForEach(func(k string) error {
+			v, err := abi.ParseUIntKey(k)
+			out = append(out, v)
+			return err
+		}))
+		sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+		return out
+	}
+
+	t.Run("add many", func(t *testing.T) {
+		s := makeSet(3, 1, 2, 1)
+		assert.Equal(t, []uint64{1, 2, 3}, members(s))
+	})
+
+	t.Run("union", func(t *testing.T) {
+		s := makeSet(1, 2, 3)
+		require.NoError(t, s.Union(makeSet(3, 4, 5)))
+		assert.Equal(t, []uint64{1, 2, 3, 4, 5}, members(s))
+	})
+
+	t.Run("intersect", func(t *testing.T) {
+		s := makeSet(1, 2, 3)
+		require.NoError(t, s.Intersect(makeSet(2, 3, 4)))
+		assert.Equal(t, []uint64{2, 3}, members(s))
+
+		require.NoError(t, s.Intersect(makeSet()))
+		assert.Empty(t, members(s))
+	})
+
+	t.Run("difference", func(t *testing.T) {
+		s := makeSet(1, 2, 3)
+		require.NoError(t, s.Difference(makeSet(2, 4)))
+		assert.Equal(t, []uint64{1, 3}, members(s))
+	})
+
+	t.Run("result is persisted", func(t *testing.T) {
+		s := makeSet(1, 2)
+		require.NoError(t, s.Union(makeSet(7)))
+		root, err := s.Root()
+		require.NoError(t, err)
+
+		reloaded, err := adt.AsSet(store, root, builtin.DefaultHamtBitwidth)
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{1, 2, 7}, members(reloaded))
+	})
+}
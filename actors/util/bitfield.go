@@ -3,6 +3,7 @@ package util
 import (
 	"github.com/filecoin-project/go-bitfield"
 	"github.com/filecoin-project/go-bitfield/rle"
+	"golang.org/x/xerrors"
 )
 
 type BitField = bitfield.BitField
@@ -67,3 +68,107 @@ func BitFieldContainsAll(a, b BitField) (bool, error) {
 	}
 	return isEmpty(combined)
 }
+
+// Maximum size, in bytes, of the RLE+ encoding of a bitfield supplied in message parameters.
+const MaxBitFieldEncodedSize = 32 << 10
+
+// Maximum number of runs in a bitfield supplied in message parameters.
+// Every bitfield operation is linear in the number of runs of its operands, so this bounds the cost of
+// operating on a bitfield independently of the number of bits it sets.
+const MaxBitFieldRuns = 1 << 15
+
+// Counts the runs in a bitfield, stopping with an error once the count exceeds max.
+func BitFieldRunCount(bf BitField, max uint64) (uint64, error) {
+	iter, err := bf.RunIterator()
+	if err != nil {
+		return 0, err
+	}
+	var count uint64
+	for iter.HasNext() {
+		if _, err := iter.NextRun(); err != nil {
+			return 0, err
+		}
+		count++
+		if count > max {
+			return 0, xerrors.Errorf("bitfield has more than %d runs", max)
+		}
+	}
+	return count, nil
+}
+
+// Computes the size of the RLE+ encoding of a bitfield.
+func BitFieldEncodedSize(bf BitField) (int, error) {
+	iter, err := bf.RunIterator()
+	if err != nil {
+		return 0, err
+	}
+	buf, err := rlepluslazy.EncodeRuns(iter, nil)
+	if err != nil {
+		return 0, err
+	}
+	return len(buf), nil
+}
+
+// Checks that a bitfield supplied in message parameters decodes and is within MaxBitFieldRuns and
+// MaxBitFieldEncodedSize. The run count is checked first, so the cost of the check is bounded even for
+// a bitfield crafted to expand enormously when decoded.
+func IsValidForChain(bf BitField) error {
+	if _, err := BitFieldRunCount(bf, MaxBitFieldRuns); err != nil {
+		return xerrors.Errorf("invalid bitfield: %w", err)
+	}
+	size, err := BitFieldEncodedSize(bf)
+	if err != nil {
+		return xerrors.Errorf("invalid bitfield: %w", err)
+	}
+	if size > MaxBitFieldEncodedSize {
+		return xerrors.Errorf("bitfield encoding of %d bytes exceeds maximum %d", size, MaxBitFieldEncodedSize)
+	}
+	return nil
+}
+
+// Counts the bits set in a bitfield, stopping once the count reaches max.
+// The result is exact if less than max, and otherwise only indicates that at least max bits are set.
+// This bounds the cost of checking a bitfield's count against a limit.
+func CountApprox(bf BitField, max uint64) (uint64, error) {
+	iter, err := bf.RunIterator()
+	if err != nil {
+		return 0, err
+	}
+	var count uint64
+	for iter.HasNext() && count < max {
+		r, err := iter.NextRun()
+		if err != nil {
+			return 0, err
+		}
+		if r.Val {
+			if r.Len >= max-count {
+				return max, nil
+			}
+			count += r.Len
+		}
+	}
+	return count, nil
+}
+
+// Returns the bits of a bitfield with values in the range [start, end).
+// Unlike BitField.Slice, which selects by the index of set bits, the cost of this is bounded by the runs of
+// the bitfield rather than the number of bits skipped.
+func SliceRange(bf BitField, start, end uint64) (BitField, error) {
+	if end <= start {
+		return bitfield.New(), nil
+	}
+	iter, err := bf.RunIterator()
+	if err != nil {
+		return BitField{}, err
+	}
+	var runs []rlepluslazy.Run
+	if start > 0 {
+		runs = append(runs, rlepluslazy.Run{Val: false, Len: start})
+	}
+	runs = append(runs, rlepluslazy.Run{Val: true, Len: end - start})
+	selected, err := rlepluslazy.And(iter, &rlepluslazy.RunSliceIterator{Runs: runs})
+	if err != nil {
+		return BitField{}, err
+	}
+	return bitfield.NewFromIter(selected)
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/filecoin-project/go-bitfield"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/filecoin-project/specs-actors/v3/actors/util"
 )
@@ -94,3 +95,77 @@ func TestBitFieldContains(t *testing.T) {
 	assertContainsAll(b, c, false)
 	assertContainsAll(c, b, false)
 }
+
+func TestBitFieldValidForChain(t *testing.T) {
+	t.Run("accepts a small bitfield", func(t *testing.T) {
+		require.NoError(t, util.IsValidForChain(bitfield.NewFromSet([]uint64{1, 2, 3, 100})))
+		require.NoError(t, util.IsValidForChain(bitfield.New()))
+	})
+
+	t.Run("rejects too many runs", func(t *testing.T) {
+		// Every other bit set yields two runs per bit.
+		bits := make([]uint64, util.MaxBitFieldRuns/2+1)
+		for i := range bits {
+			bits[i] = uint64(2 * i)
+		}
+		err := util.IsValidForChain(bitfield.NewFromSet(bits))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "runs")
+	})
+
+	t.Run("run count", func(t *testing.T) {
+		count, err := util.BitFieldRunCount(bitfield.NewFromSet([]uint64{1, 2, 5}), 10)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(4), count) // 0, 1-2, 3-4, 5
+
+		_, err = util.BitFieldRunCount(bitfield.NewFromSet([]uint64{1, 2, 5}), 3)
+		require.Error(t, err)
+	})
+}
+
+func TestCountApprox(t *testing.T) {
+	bf := bitfield.NewFromSet([]uint64{1, 2, 3, 10, 11, 20})
+
+	count, err := util.CountApprox(bf, 100)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(6), count)
+
+	count, err = util.CountApprox(bf, 4)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), count)
+
+	count, err = util.CountApprox(bf, 3)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), count)
+
+	count, err = util.CountApprox(bitfield.New(), 3)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestSliceRange(t *testing.T) {
+	bf := bitfield.NewFromSet([]uint64{0, 1, 2, 3, 10, 11, 20})
+
+	for _, tc := range []struct {
+		start, end uint64
+		expected   []uint64
+	}{
+		{0, 100, []uint64{0, 1, 2, 3, 10, 11, 20}},
+		{0, 2, []uint64{0, 1}},
+		{2, 11, []uint64{2, 3, 10}},
+		{12, 20, nil},
+		{20, 21, []uint64{20}},
+		{5, 5, nil},
+		{9, 3, nil},
+	} {
+		sliced, err := util.SliceRange(bf, tc.start, tc.end)
+		require.NoError(t, err)
+		all, err := sliced.All(100)
+		require.NoError(t, err)
+		if tc.expected == nil {
+			assert.Empty(t, all, "[%d, %d)", tc.start, tc.end)
+		} else {
+			assert.Equal(t, tc.expected, all, "[%d, %d)", tc.start, tc.end)
+		}
+	}
+}
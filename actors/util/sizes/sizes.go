@@ -0,0 +1,113 @@
+package sizes
+
+import (
+	"crypto/sha256"
+	"math/bits"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	cid "github.com/ipfs/go-cid"
+	mh "github.com/multiformats/go-multihash"
+	"golang.org/x/xerrors"
+)
+
+// Size of a node in a piece commitment tree, the unit of padding.
+const NodeSize = 32
+
+// The smallest valid padded piece size.
+const MinPaddedPieceSize = abi.PaddedPieceSize(128)
+
+// Returns the smallest valid padded piece size no smaller than n bytes.
+func PaddedCeil(n uint64) abi.PaddedPieceSize {
+	if n <= uint64(MinPaddedPieceSize) {
+		return MinPaddedPieceSize
+	}
+	return abi.PaddedPieceSize(1) << bits.Len64(n-1)
+}
+
+// Returns the size of the smallest valid piece able to hold n bytes of unpadded data.
+func PaddedForData(n uint64) abi.PaddedPieceSize {
+	// Every 127 bytes of data occupy 128 bytes once padded.
+	return PaddedCeil((n + 126) / 127 * 128)
+}
+
+// Converts a padded piece size to its unpadded size, checking that it is valid.
+func ToUnpadded(p abi.PaddedPieceSize) (abi.UnpaddedPieceSize, error) {
+	if err := p.Validate(); err != nil {
+		return 0, err
+	}
+	return p.Unpadded(), nil
+}
+
+// Converts an unpadded piece size to its padded size, checking that it is valid.
+func ToPadded(u abi.UnpaddedPieceSize) (abi.PaddedPieceSize, error) {
+	if err := u.Validate(); err != nil {
+		return 0, err
+	}
+	return u.Padded(), nil
+}
+
+// Computes the offsets at which pieces are placed when aggregated in order, each aligned to a multiple of its
+// own size, and the total size they occupy including alignment padding.
+func AggregateLayout(pieces []abi.PaddedPieceSize) (offsets []uint64, total uint64, err error) {
+	offsets = make([]uint64, len(pieces))
+	for i, p := range pieces {
+		if err := p.Validate(); err != nil {
+			return nil, 0, xerrors.Errorf("piece %d: %w", i, err)
+		}
+		size := uint64(p)
+		// Sizes are powers of two, so round up to the next multiple.
+		offset := (total + size - 1) &^ (size - 1)
+		offsets[i] = offset
+		total = offset + size
+	}
+	return offsets, total, nil
+}
+
+// Checks whether pieces, aggregated in order, fit within a sector.
+func FitsInSector(sectorSize abi.SectorSize, pieces []abi.PaddedPieceSize) (bool, error) {
+	_, total, err := AggregateLayout(pieces)
+	if err != nil {
+		return false, err
+	}
+	return total <= uint64(sectorSize), nil
+}
+
+// Returns the filler pieces, smallest first, that pad aggregated pieces occupying the first `from` bytes
+// up to the alignment required by a following piece of size next.
+// Each filler, placed in order, is aligned to its own size.
+func FillerPieces(from uint64, next abi.PaddedPieceSize) ([]abi.PaddedPieceSize, error) {
+	if err := next.Validate(); err != nil {
+		return nil, err
+	}
+	if from%uint64(MinPaddedPieceSize) != 0 {
+		return nil, xerrors.Errorf("offset %d is not a multiple of %d", from, MinPaddedPieceSize)
+	}
+	toFill := -from % uint64(next)
+	fillers := make([]abi.PaddedPieceSize, 0, bits.OnesCount64(toFill))
+	for toFill > 0 {
+		filler := abi.PaddedPieceSize(1) << bits.TrailingZeros64(toFill)
+		fillers = append(fillers, filler)
+		toFill -= uint64(filler)
+	}
+	return fillers, nil
+}
+
+// Computes the commitment (piece CID) of a piece of zeros with the given padded size.
+// The commitment of an empty sector is that of a zero piece of the sector's size.
+func ZeroPieceCommitment(size abi.PaddedPieceSize) (cid.Cid, error) {
+	if err := size.Validate(); err != nil {
+		return cid.Undef, err
+	}
+	node := make([]byte, NodeSize)
+	for n := abi.PaddedPieceSize(NodeSize); n < size; n *= 2 {
+		digest := sha256.Sum256(append(node, node...))
+		// Truncate to 254 bits so the digest is a valid field element.
+		digest[NodeSize-1] &= 0x3f
+		node = digest[:]
+	}
+	hash, err := mh.Encode(node, mh.SHA2_256_TRUNC254_PADDED)
+	if err != nil {
+		return cid.Undef, err
+	}
+	return cid.NewCidV1(cid.FilCommitmentUnsealed, hash), nil
+}
@@ -0,0 +1,126 @@
+package sizes_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	mh "github.com/multiformats/go-multihash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin/market"
+	"github.com/filecoin-project/specs-actors/v3/actors/util/sizes"
+)
+
+func TestPaddedCeil(t *testing.T) {
+	assert.Equal(t, abi.PaddedPieceSize(128), sizes.PaddedCeil(0))
+	assert.Equal(t, abi.PaddedPieceSize(128), sizes.PaddedCeil(128))
+	assert.Equal(t, abi.PaddedPieceSize(256), sizes.PaddedCeil(129))
+	assert.Equal(t, abi.PaddedPieceSize(2048), sizes.PaddedCeil(2048))
+	assert.Equal(t, abi.PaddedPieceSize(32<<30), sizes.PaddedCeil(32<<30-1))
+}
+
+func TestPaddedForData(t *testing.T) {
+	assert.Equal(t, abi.PaddedPieceSize(128), sizes.PaddedForData(0))
+	assert.Equal(t, abi.PaddedPieceSize(128), sizes.PaddedForData(127))
+	assert.Equal(t, abi.PaddedPieceSize(256), sizes.PaddedForData(128))
+	assert.Equal(t, abi.PaddedPieceSize(1024), sizes.PaddedForData(1000))
+	assert.Equal(t, abi.PaddedPieceSize(1024), sizes.PaddedForData(1016))
+	assert.Equal(t, abi.PaddedPieceSize(2048), sizes.PaddedForData(1017))
+}
+
+func TestConversion(t *testing.T) {
+	u, err := sizes.ToUnpadded(2048)
+	require.NoError(t, err)
+	assert.Equal(t, abi.UnpaddedPieceSize(2032), u)
+
+	p, err := sizes.ToPadded(2032)
+	require.NoError(t, err)
+	assert.Equal(t, abi.PaddedPieceSize(2048), p)
+
+	_, err = sizes.ToUnpadded(100)
+	assert.Error(t, err)
+	_, err = sizes.ToUnpadded(384)
+	assert.Error(t, err)
+	_, err = sizes.ToPadded(128)
+	assert.Error(t, err)
+}
+
+func TestAggregateLayout(t *testing.T) {
+	t.Run("pieces are aligned to their own size", func(t *testing.T) {
+		offsets, total, err := sizes.AggregateLayout([]abi.PaddedPieceSize{128, 256, 128, 1024})
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{0, 256, 512, 1024}, offsets)
+		assert.Equal(t, uint64(2048), total)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		offsets, total, err := sizes.AggregateLayout(nil)
+		require.NoError(t, err)
+		assert.Empty(t, offsets)
+		assert.Equal(t, uint64(0), total)
+	})
+
+	t.Run("invalid piece", func(t *testing.T) {
+		_, _, err := sizes.AggregateLayout([]abi.PaddedPieceSize{128, 300})
+		assert.Error(t, err)
+	})
+
+	t.Run("fits in sector", func(t *testing.T) {
+		fits, err := sizes.FitsInSector(2048, []abi.PaddedPieceSize{128, 512, 1024})
+		require.NoError(t, err)
+		assert.True(t, fits)
+
+		// Alignment of the second piece pushes the last past the end.
+		fits, err = sizes.FitsInSector(2048, []abi.PaddedPieceSize{128, 1024, 1024})
+		require.NoError(t, err)
+		assert.False(t, fits)
+	})
+}
+
+func TestFillerPieces(t *testing.T) {
+	fillers, err := sizes.FillerPieces(128, 1024)
+	require.NoError(t, err)
+	assert.Equal(t, []abi.PaddedPieceSize{128, 256, 512}, fillers)
+
+	// Fillers placed in order are aligned, and bring the next piece to its aligned offset.
+	offsets, total, err := sizes.AggregateLayout(append([]abi.PaddedPieceSize{128}, fillers...))
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{0, 128, 256, 512}, offsets)
+	assert.Equal(t, uint64(1024), total)
+
+	fillers, err = sizes.FillerPieces(1024, 512)
+	require.NoError(t, err)
+	assert.Empty(t, fillers)
+
+	_, err = sizes.FillerPieces(100, 512)
+	assert.Error(t, err)
+	_, err = sizes.FillerPieces(128, 300)
+	assert.Error(t, err)
+}
+
+func TestZeroPieceCommitment(t *testing.T) {
+	c, err := sizes.ZeroPieceCommitment(128)
+	require.NoError(t, err)
+	assert.Equal(t, market.PieceCIDPrefix, c.Prefix())
+	assert.Equal(t, "3731bb99ac689f66eef5973e4a94da188f4ddcae580724fc6f3fd60dfd488333", hex.EncodeToString(digest(t, c.Hash())))
+
+	// The commitment of a piece is the truncated hash of the commitments of its halves.
+	half := digest(t, c.Hash())
+	c, err = sizes.ZeroPieceCommitment(256)
+	require.NoError(t, err)
+	expected := sha256.Sum256(append(append([]byte{}, half...), half...))
+	expected[31] &= 0x3f
+	assert.Equal(t, expected[:], digest(t, c.Hash()))
+
+	_, err = sizes.ZeroPieceCommitment(100)
+	assert.Error(t, err)
+}
+
+func digest(t *testing.T, h mh.Multihash) []byte {
+	decoded, err := mh.Decode(h)
+	require.NoError(t, err)
+	return decoded.Digest
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin/account"
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin/cron"
 	init_ "github.com/filecoin-project/specs-actors/v3/actors/builtin/init"
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin/manifest"
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin/market"
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin/miner"
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin/multisig"
@@ -16,6 +17,7 @@ import (
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin/system"
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin/verifreg"
 	"github.com/filecoin-project/specs-actors/v3/actors/util/smoothing"
+	"github.com/filecoin-project/specs-actors/v3/support/chaos"
 )
 
 func main() {
@@ -32,6 +34,8 @@ func main() {
 
 	if err := gen.WriteTupleEncodersToFile("./actors/builtin/cbor_gen.go", "builtin",
 		builtin.MinerAddrs{},
+		builtin.UniversalReceiverParams{},
+		builtin.BurnFundsParams{},
 		//builtin.ConfirmSectorProofsParams{},  // Aliased from v0
 		// builtin.ApplyRewardParams{}, // Aliased from v2
 	); err != nil {
@@ -48,6 +52,16 @@ func main() {
 	if err := gen.WriteTupleEncodersToFile("./actors/builtin/system/cbor_gen.go", "system",
 		// actor state
 		system.State{},
+		// method params
+		system.UpgradeActorsParams{},
+	); err != nil {
+		panic(err)
+	}
+
+	if err := gen.WriteTupleEncodersToFile("./actors/builtin/manifest/cbor_gen.go", "manifest",
+		manifest.Manifest{},
+		manifest.ManifestData{},
+		manifest.ManifestEntry{},
 	); err != nil {
 		panic(err)
 	}
@@ -66,6 +80,7 @@ func main() {
 		//init_.ConstructorParams{}, // Aliased from v0
 		//init_.ExecParams{}, // Aliased from v0
 		//init_.ExecReturn{}, // Aliased from v0
+		init_.LookupActorStatusReturn{},
 	); err != nil {
 		panic(err)
 	}
@@ -114,8 +129,9 @@ func main() {
 		// actor state
 		paych.State{},
 		paych.LaneState{},
+		paych.Settler{},
 		// method params and returns
-		//paych.ConstructorParams{}, // Aliased from v0
+		paych.ConstructorParams{},
 		// paych.UpdateChannelStateParams{}, // Aliased from v2
 		//paych.SignedVoucher{}, // Aliased from v0
 		//paych.ModVerifyParams{}, // Aliased from v0
@@ -136,6 +152,8 @@ func main() {
 		//power.EnrollCronEventParams{}, // Aliased from v0
 		//power.UpdateClaimedPowerParams{}, // Aliased from v0
 		power.CurrentTotalPowerReturn{},
+		power.NetworkPowerStatsReturn{},
+		power.UpdateClaimedPowerBatchParams{},
 		// other types
 		power.MinerConstructorParams{},
 	); err != nil {
@@ -148,17 +166,28 @@ func main() {
 		// method params and returns
 		//market.WithdrawBalanceParams{}, // Aliased from v0
 		//market.PublishStorageDealsParams{}, // Aliased from v0
-		//market.PublishStorageDealsReturn{}, // Aliased from v0
+		market.PublishStorageDealsReturn{},
 		//market.ActivateDealsParams{}, // Aliased from v0
 		market.VerifyDealsForActivationParams{},
 		market.VerifyDealsForActivationReturn{},
 		//market.ComputeDataCommitmentParams{}, // Aliased from v0
 		//market.OnMinerSectorsTerminateParams{}, // Aliased from v0
+		market.SettleDealPaymentsParams{},
+		market.SettleDealPaymentsReturn{},
+		market.ReserveCollateralParams{},
+		market.ReleaseCollateralParams{},
 		// other types
 		//market.DealProposal{}, // Aliased from v0
 		//market.ClientDealProposal{}, // Aliased from v0
 		market.SectorDeals{},
 		market.SectorWeights{},
+		market.BatchActivateDealsParams{},
+		market.BatchActivateDealsReturn{},
+		market.SectorDealActivation{},
+		market.UnverifiedPiece{},
+		market.CollateralReservation{},
+		market.DealTerminationNotification{},
+		market.MarketStats{},
 		market.DealState{},
 	); err != nil {
 		panic(err)
@@ -175,8 +204,11 @@ func main() {
 		miner.PowerPair{},
 		miner.SectorPreCommitOnChainInfo{},
 		miner.SectorPreCommitInfo{},
+		miner.AllocationClaim{},
 		miner.SectorOnChainInfo{},
 		miner.WorkerKeyChange{},
+		miner.BeneficiaryTerm{},
+		miner.PendingBeneficiaryChange{},
 		miner.VestingFunds{},
 		miner.VestingFund{},
 		miner.WindowedPoSt{},
@@ -200,6 +232,18 @@ func main() {
 		//miner.CompactSectorNumbersParams{}, // Aliased from v0
 		//miner.CronEventPayload{}, // Aliased from v0
 		miner.DisputeWindowedPoStParams{},
+		miner.ProveReplicaUpdatesParams{},
+		miner.ReplicaUpdate{},
+		miner.ChangeBeneficiaryParams{},
+		miner.ActiveBeneficiary{},
+		miner.GetBeneficiaryReturn{},
+		miner.ProveCommitAggregateParams{},
+		miner.GetVestingFundsReturn{},
+		miner.DeadlineInfoParams{},
+		miner.DeadlineStatus{},
+		miner.EstimateFaultFeeReturn{},
+		miner.GetSectorInfoParams{},
+		miner.SectorStatus{},
 		// other types
 		//miner.FaultDeclaration{}, // Aliased from v0
 		//miner.RecoveryDeclaration{}, // Aliased from v0
@@ -218,7 +262,28 @@ func main() {
 		//verifreg.AddVerifiedClientParams{}, // Aliased from v0
 		//verifreg.UseBytesParams{}, // Aliased from v0
 		//verifreg.RestoreBytesParams{}, // Aliased from v0
+		verifreg.RemoveDataCapParams{},
+		verifreg.RemoveDataCapReturn{},
 		// other types
+		verifreg.RmDcProposalID{},
+		verifreg.RemoveDataCapProposal{},
+		verifreg.RemoveDataCapRequest{},
+		verifreg.Allocation{},
+		verifreg.Claim{},
+		verifreg.AllocationRequest{},
+		verifreg.CreateAllocationsParams{},
+		verifreg.CreateAllocationsReturn{},
+		verifreg.SectorAllocationClaim{},
+		verifreg.ClaimAllocationsParams{},
+		verifreg.ClaimAllocationsReturn{},
+		verifreg.ClaimTerm{},
+		verifreg.ExtendClaimTermsParams{},
+		verifreg.RemoveExpiredAllocationsParams{},
+		verifreg.RemoveExpiredAllocationsReturn{},
+		verifreg.RemoveExpiredClaimsParams{},
+		verifreg.RemoveExpiredClaimsReturn{},
+		verifreg.AddVerifierAllowanceParams{},
+		verifreg.ReduceVerifierAllowanceParams{},
 	); err != nil {
 		panic(err)
 	}
@@ -229,4 +294,15 @@ func main() {
 		panic(err)
 	}
 
+	// Test support
+	if err := gen.WriteTupleEncodersToFile("./support/chaos/cbor_gen.go", "chaos",
+		chaos.State{},
+		chaos.AbortWithArgs{},
+		chaos.MutateStateArgs{},
+		chaos.SendArgs{},
+		chaos.SendReturn{},
+		chaos.ConsumeResourcesArgs{},
+	); err != nil {
+		panic(err)
+	}
 }
@@ -3,7 +3,6 @@ package agent
 import (
 	"crypto/sha256"
 	mh "github.com/multiformats/go-multihash"
-	"math/bits"
 	"math/rand"
 	"strconv"
 
@@ -16,6 +15,7 @@ import (
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin/market"
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin/power"
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin/reward"
+	"github.com/filecoin-project/specs-actors/v3/actors/util/sizes"
 	"github.com/ipfs/go-cid"
 )
 
@@ -115,15 +115,10 @@ func (dca *DealClientAgent) createDeal(s SimState, provider DealProvider) error
 		return err
 	}
 
-	pieceSize := dca.config.MinPieceSize +
-		uint64(dca.rnd.Int63n(int64(dca.config.MaxPieceSize-dca.config.MinPieceSize)))
+	pieceSize := sizes.PaddedCeil(dca.config.MinPieceSize +
+		uint64(dca.rnd.Int63n(int64(dca.config.MaxPieceSize-dca.config.MinPieceSize))))
 
-	// round to next power of two
-	if bits.OnesCount64(pieceSize) > 1 {
-		pieceSize = 1 << bits.Len64(pieceSize)
-	}
-
-	providerCollateral, err := calculateProviderCollateral(s, abi.PaddedPieceSize(pieceSize))
+	providerCollateral, err := calculateProviderCollateral(s, pieceSize)
 	if err != nil {
 		return err
 	}
@@ -156,7 +151,7 @@ func (dca *DealClientAgent) createDeal(s SimState, provider DealProvider) error
 
 	proposal := market.DealProposal{
 		PieceCID:             pieceCid,
-		PieceSize:            abi.PaddedPieceSize(pieceSize),
+		PieceSize:            pieceSize,
 		VerifiedDeal:         false,
 		Client:               dca.account,
 		Provider:             provider.Address(),
@@ -441,24 +441,34 @@ func (ma *MinerAgent) submitPoStForDeadline(v SimState, dlIdx uint64) ([]message
 		return nil, err
 	}
 
-	params := miner.SubmitWindowedPoStParams{
-		Deadline:   dlIdx,
-		Partitions: partitions,
-		Proofs: []proof.PoStProof{{
-			PoStProof:  postProofType,
-			ProofBytes: []byte{},
-		}},
-		ChainCommitEpoch: v.GetEpoch() - 1,
-		ChainCommitRand:  []byte("not really random"),
+	// prove the partitions in as few submissions as the per-submission limit allows
+	var messages []message
+	for len(partitions) > 0 {
+		batch := partitions
+		if len(batch) > miner.PoStedPartitionsMax {
+			batch = batch[:miner.PoStedPartitionsMax]
+		}
+		partitions = partitions[len(batch):]
+
+		params := miner.SubmitWindowedPoStParams{
+			Deadline:   dlIdx,
+			Partitions: batch,
+			Proofs: []proof.PoStProof{{
+				PoStProof:  postProofType,
+				ProofBytes: []byte{},
+			}},
+			ChainCommitEpoch: v.GetEpoch() - 1,
+			ChainCommitRand:  []byte("not really random"),
+		}
+		messages = append(messages, message{
+			From:   ma.Worker,
+			To:     ma.IDAddress,
+			Value:  big.Zero(),
+			Method: builtin.MethodsMiner.SubmitWindowedPoSt,
+			Params: &params,
+		})
 	}
-
-	return []message{{
-		From:   ma.Worker,
-		To:     ma.IDAddress,
-		Value:  big.Zero(),
-		Method: builtin.MethodsMiner.SubmitWindowedPoSt,
-		Params: &params,
-	}}, nil
+	return messages, nil
 }
 
 // create a deal proposal message and notify provider of deal
@@ -485,7 +495,17 @@ func (ma *MinerAgent) publishStorageDeals() []message {
 				return errors.Errorf("create miner return has wrong type: %v", ret)
 			}
 
-			for idx, dealId := range publishReturn.IDs {
+			// invalid deals are dropped, so map returned ids back to the input deals that were accepted
+			validIdxs, err := publishReturn.ValidDeals.All(uint64(len(params.Deals)))
+			if err != nil {
+				return err
+			}
+			if len(validIdxs) != len(publishReturn.IDs) {
+				return errors.Errorf("publish returned %d deal ids for %d valid deals", len(publishReturn.IDs), len(validIdxs))
+			}
+
+			for i, dealId := range publishReturn.IDs {
+				idx := validIdxs[i]
 				ma.dealsPendingInclusion = append(ma.dealsPendingInclusion, pendingDeal{
 					id:   dealId,
 					size: params.Deals[idx].Proposal.PieceSize,
@@ -0,0 +1,151 @@
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	goruntime "runtime"
+	"testing"
+
+	vm "github.com/filecoin-project/specs-actors/v3/support/vm"
+)
+
+// Result records the IPLD store operations and Go heap allocations performed while executing
+// a benchmarked call. Values are totals; use Report to publish per-op averages.
+type Result struct {
+	Name       string
+	Reads      uint64
+	Writes     uint64
+	ReadBytes  uint64
+	WriteBytes uint64
+	Allocs     uint64
+	AllocBytes uint64
+}
+
+// Measure executes f once and returns the store operations observed through stats and the
+// heap allocations made while it ran.
+func Measure(name string, stats vm.StatsSource, f func()) Result {
+	var before, after goruntime.MemStats
+	reads, writes := stats.ReadCount(), stats.WriteCount()
+	readBytes, writeBytes := stats.ReadSize(), stats.WriteSize()
+	goruntime.ReadMemStats(&before)
+
+	f()
+
+	goruntime.ReadMemStats(&after)
+	return Result{
+		Name:       name,
+		Reads:      stats.ReadCount() - reads,
+		Writes:     stats.WriteCount() - writes,
+		ReadBytes:  stats.ReadSize() - readBytes,
+		WriteBytes: stats.WriteSize() - writeBytes,
+		Allocs:     after.Mallocs - before.Mallocs,
+		AllocBytes: after.TotalAlloc - before.TotalAlloc,
+	}
+}
+
+// Add returns the sum of two results, keeping the receiver's name.
+func (r Result) Add(o Result) Result {
+	return Result{
+		Name:       r.Name,
+		Reads:      r.Reads + o.Reads,
+		Writes:     r.Writes + o.Writes,
+		ReadBytes:  r.ReadBytes + o.ReadBytes,
+		WriteBytes: r.WriteBytes + o.WriteBytes,
+		Allocs:     r.Allocs + o.Allocs,
+		AllocBytes: r.AllocBytes + o.AllocBytes,
+	}
+}
+
+// Report publishes the result as custom benchmark metrics averaged over b.N iterations and
+// returns the averaged result.
+func (r Result) Report(b *testing.B) Result {
+	n := uint64(b.N)
+	avg := Result{
+		Name:       r.Name,
+		Reads:      r.Reads / n,
+		Writes:     r.Writes / n,
+		ReadBytes:  r.ReadBytes / n,
+		WriteBytes: r.WriteBytes / n,
+		Allocs:     r.Allocs / n,
+		AllocBytes: r.AllocBytes / n,
+	}
+	b.ReportMetric(float64(avg.Reads), "reads/op")
+	b.ReportMetric(float64(avg.Writes), "writes/op")
+	b.ReportMetric(float64(avg.ReadBytes), "read-bytes/op")
+	b.ReportMetric(float64(avg.WriteBytes), "write-bytes/op")
+	return avg
+}
+
+func (r Result) metrics() map[string]uint64 {
+	return map[string]uint64{
+		"reads":       r.Reads,
+		"writes":      r.Writes,
+		"read-bytes":  r.ReadBytes,
+		"write-bytes": r.WriteBytes,
+		"allocs":      r.Allocs,
+		"alloc-bytes": r.AllocBytes,
+	}
+}
+
+// Regression describes a metric of a benchmark that exceeded its baseline by more than the
+// allowed tolerance.
+type Regression struct {
+	Name     string
+	Metric   string
+	Baseline uint64
+	Current  uint64
+}
+
+func (r Regression) String() string {
+	return fmt.Sprintf("%s: %s increased from %d to %d", r.Name, r.Metric, r.Baseline, r.Current)
+}
+
+// Compare returns the metrics of current results that exceed the baseline result of the same
+// name by more than tolerance, expressed as a fraction of the baseline (e.g. 0.05 for 5%).
+// Results with no baseline are ignored. Regressions are ordered by result, then metric name.
+func Compare(baseline, current []Result, tolerance float64) []Regression {
+	byName := make(map[string]Result, len(baseline))
+	for _, r := range baseline {
+		byName[r.Name] = r
+	}
+
+	var regressions []Regression
+	for _, cur := range current {
+		base, ok := byName[cur.Name]
+		if !ok {
+			continue
+		}
+		baseMetrics, curMetrics := base.metrics(), cur.metrics()
+		for _, metric := range metricNames {
+			limit := float64(baseMetrics[metric]) * (1 + tolerance)
+			if float64(curMetrics[metric]) > limit {
+				regressions = append(regressions, Regression{
+					Name:     cur.Name,
+					Metric:   metric,
+					Baseline: baseMetrics[metric],
+					Current:  curMetrics[metric],
+				})
+			}
+		}
+	}
+	return regressions
+}
+
+var metricNames = []string{"alloc-bytes", "allocs", "read-bytes", "reads", "write-bytes", "writes"}
+
+// WriteResults serializes results as JSON, suitable for storing as a baseline.
+func WriteResults(w io.Writer, results []Result) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(results)
+}
+
+// ReadResults deserializes results written by WriteResults.
+func ReadResults(r io.Reader) ([]Result, error) {
+	var results []Result
+	if err := json.NewDecoder(r).Decode(&results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,257 @@
+package bench_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	addr "github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-bitfield"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin/market"
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin/miner"
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin/power"
+	"github.com/filecoin-project/specs-actors/v3/actors/runtime/proof"
+	"github.com/filecoin-project/specs-actors/v3/support/bench"
+	"github.com/filecoin-project/specs-actors/v3/support/ipld"
+	tutil "github.com/filecoin-project/specs-actors/v3/support/testing"
+	vm "github.com/filecoin-project/specs-actors/v3/support/vm"
+)
+
+const dealsPerMessage = 100
+
+func BenchmarkPublishStorageDeals(b *testing.B) {
+	v, stats, env := setupDealEnv(b)
+	start := v.GetEpoch() + 2*market.DealUpdatesInterval
+	params := dealBatch(env, 0, start)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	total := bench.Result{Name: "PublishStorageDeals/100"}
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		tv, err := v.WithEpoch(v.GetEpoch())
+		require.NoError(b, err)
+		b.StartTimer()
+
+		total = total.Add(bench.Measure(total.Name, stats, func() {
+			vm.ApplyOk(b, tv, env.worker, builtin.StorageMarketActorAddr, big.Zero(), builtin.MethodsMarket.PublishStorageDeals, params)
+		}))
+	}
+	total.Report(b)
+}
+
+// Measures a market cron tick processing 10k deals. The deals are never activated, so they
+// all reach their start epoch unactivated and are timed out by the measured tick.
+func BenchmarkMarketCronExpiringDeals(b *testing.B) {
+	v, stats, env := setupDealEnv(b)
+	start := v.GetEpoch() + 2*market.DealUpdatesInterval
+	for batch := 0; batch < 10000/dealsPerMessage; batch++ {
+		vm.ApplyOk(b, v, env.worker, builtin.StorageMarketActorAddr, big.Zero(), builtin.MethodsMarket.PublishStorageDeals, dealBatch(env, batch, start))
+	}
+
+	// Run cron up to just before the deals start so the measured tick covers only their update epochs.
+	v, err := v.WithEpoch(start - 1)
+	require.NoError(b, err)
+	vm.ApplyOk(b, v, builtin.SystemActorAddr, builtin.CronActorAddr, big.Zero(), builtin.MethodsCron.EpochTick, nil)
+	v, err = v.WithEpoch(start + market.DealUpdatesInterval)
+	require.NoError(b, err)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	total := bench.Result{Name: "CronTick/10000-deals"}
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		tv, err := v.WithEpoch(v.GetEpoch())
+		require.NoError(b, err)
+		b.StartTimer()
+
+		total = total.Add(bench.Measure(total.Name, stats, func() {
+			vm.ApplyOk(b, tv, builtin.SystemActorAddr, builtin.CronActorAddr, big.Zero(), builtin.MethodsCron.EpochTick, nil)
+		}))
+	}
+	total.Report(b)
+}
+
+// Measures a Window PoSt for a single full partition of 32GiB sectors. The sectors are written
+// directly into the miner's current deadline as already proven, so the submission takes the
+// steady-state (optimistically accepted) path. Power actor claims are not updated to match.
+func BenchmarkSubmitWindowedPoStFullPartition(b *testing.B) {
+	ctx := context.Background()
+	metrics := ipld.NewMetricsBlockStore(ipld.NewBlockStoreInMemory())
+	v := vm.NewVMWithSingletons(ctx, b, metrics)
+	v.SetStatsSource(metrics)
+	v.SkipConsistencyCheck()
+	v, err := v.WithEpoch(200)
+	require.NoError(b, err)
+
+	worker := vm.CreateAccounts(ctx, b, v, 1, big.Mul(big.NewInt(10000), vm.FIL), 93837778)[0]
+	minerAddrs := createMiner(b, v, worker)
+
+	var st miner.State
+	require.NoError(b, v.GetState(minerAddrs.IDAddress, &st))
+	info, err := st.GetInfo(v.Store())
+	require.NoError(b, err)
+	dlInfo := st.DeadlineInfo(v.GetEpoch())
+	require.True(b, dlInfo.IsOpen())
+
+	sectors := make([]*miner.SectorOnChainInfo, info.WindowPoStPartitionSectors)
+	for i := range sectors {
+		sectors[i] = &miner.SectorOnChainInfo{
+			SectorNumber:          abi.SectorNumber(i),
+			SealProof:             abi.RegisteredSealProof_StackedDrg32GiBV1_1,
+			SealedCID:             tutil.MakeCID(fmt.Sprintf("sector-%d", i), &miner.SealedCIDPrefix),
+			Activation:            v.GetEpoch(),
+			Expiration:            v.GetEpoch() + 200*builtin.EpochsInDay,
+			DealWeight:            big.Zero(),
+			VerifiedDealWeight:    big.Zero(),
+			InitialPledge:         big.Zero(),
+			ExpectedDayReward:     big.Zero(),
+			ExpectedStoragePledge: big.Zero(),
+			ReplacedDayReward:     big.Zero(),
+		}
+	}
+	require.NoError(b, st.PutSectors(v.Store(), sectors...))
+	deadlines, err := st.LoadDeadlines(v.Store())
+	require.NoError(b, err)
+	dl, err := deadlines.LoadDeadline(v.Store(), dlInfo.Index)
+	require.NoError(b, err)
+	_, err = dl.AddSectors(v.Store(), info.WindowPoStPartitionSectors, true, sectors, info.SectorSize, st.QuantSpecForDeadline(dlInfo.Index))
+	require.NoError(b, err)
+	require.NoError(b, deadlines.UpdateDeadline(v.Store(), dlInfo.Index, dl))
+	require.NoError(b, st.SaveDeadlines(v.Store(), deadlines))
+	require.NoError(b, v.SetActorState(ctx, minerAddrs.IDAddress, &st))
+	v, err = v.WithEpoch(v.GetEpoch())
+	require.NoError(b, err)
+
+	params := miner.SubmitWindowedPoStParams{
+		Deadline:   dlInfo.Index,
+		Partitions: []miner.PoStPartition{{Index: 0, Skipped: bitfield.New()}},
+		Proofs: []proof.PoStProof{{
+			PoStProof: abi.RegisteredPoStProof_StackedDrgWindow32GiBV1,
+		}},
+		ChainCommitEpoch: dlInfo.Challenge,
+		ChainCommitRand:  []byte("not really random"),
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	total := bench.Result{Name: fmt.Sprintf("SubmitWindowedPoSt/%d-sectors", len(sectors))}
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		tv, err := v.WithEpoch(v.GetEpoch())
+		require.NoError(b, err)
+		b.StartTimer()
+
+		total = total.Add(bench.Measure(total.Name, metrics, func() {
+			vm.ApplyOk(b, tv, worker, minerAddrs.RobustAddress, big.Zero(), builtin.MethodsMiner.SubmitWindowedPoSt, &params)
+		}))
+	}
+	total.Report(b)
+}
+
+func TestCompare(t *testing.T) {
+	baseline := []bench.Result{
+		{Name: "a", Reads: 100, Writes: 10, Allocs: 1000},
+		{Name: "b", Reads: 50},
+	}
+
+	t.Run("within tolerance", func(t *testing.T) {
+		current := []bench.Result{{Name: "a", Reads: 104, Writes: 10, Allocs: 900}}
+		assert.Empty(t, bench.Compare(baseline, current, 0.05))
+	})
+
+	t.Run("reports metrics beyond tolerance", func(t *testing.T) {
+		current := []bench.Result{
+			{Name: "a", Reads: 106, Writes: 11, Allocs: 1000},
+			{Name: "b", Reads: 50},
+		}
+		assert.Equal(t, []bench.Regression{
+			{Name: "a", Metric: "reads", Baseline: 100, Current: 106},
+			{Name: "a", Metric: "writes", Baseline: 10, Current: 11},
+		}, bench.Compare(baseline, current, 0.05))
+	})
+
+	t.Run("ignores results without baseline", func(t *testing.T) {
+		current := []bench.Result{{Name: "c", Reads: 1 << 20}}
+		assert.Empty(t, bench.Compare(baseline, current, 0))
+	})
+
+	t.Run("round trips through json", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, bench.WriteResults(&buf, baseline))
+		read, err := bench.ReadResults(&buf)
+		require.NoError(t, err)
+		assert.Equal(t, baseline, read)
+	})
+}
+
+//
+// Setup
+//
+
+type dealEnv struct {
+	worker addr.Address
+	client addr.Address
+	miner  addr.Address
+}
+
+// Creates a VM with a miner and a client each holding enough market escrow for 10k deals.
+func setupDealEnv(b *testing.B) (*vm.VM, vm.StatsSource, dealEnv) {
+	ctx := context.Background()
+	metrics := ipld.NewMetricsBlockStore(ipld.NewBlockStoreInMemory())
+	v := vm.NewVMWithSingletons(ctx, b, metrics)
+	v.SetStatsSource(metrics)
+	v, err := v.WithEpoch(200)
+	require.NoError(b, err)
+
+	balance := big.Mul(big.NewInt(100000), vm.FIL)
+	addrs := vm.CreateAccounts(ctx, b, v, 2, balance, 93837778)
+	env := dealEnv{worker: addrs[0], client: addrs[1]}
+	env.miner = createMiner(b, v, env.worker).IDAddress
+
+	escrow := big.Mul(big.NewInt(30000), vm.FIL)
+	vm.ApplyOk(b, v, env.client, builtin.StorageMarketActorAddr, escrow, builtin.MethodsMarket.AddBalance, &env.client)
+	vm.ApplyOk(b, v, env.worker, builtin.StorageMarketActorAddr, escrow, builtin.MethodsMarket.AddBalance, &env.miner)
+	return v, metrics, env
+}
+
+func createMiner(b *testing.B, v *vm.VM, worker addr.Address) *power.CreateMinerReturn {
+	params := power.CreateMinerParams{
+		Owner:               worker,
+		Worker:              worker,
+		WindowPoStProofType: abi.RegisteredPoStProof_StackedDrgWindow32GiBV1,
+		Peer:                abi.PeerID("not really a peer id"),
+	}
+	ret := vm.ApplyOk(b, v, worker, builtin.StoragePowerActorAddr, big.Mul(big.NewInt(1000), vm.FIL), builtin.MethodsPower.CreateMiner, &params)
+	minerAddrs, ok := ret.(*power.CreateMinerReturn)
+	require.True(b, ok)
+	return minerAddrs
+}
+
+// Returns publish params for a batch of distinct deals, all starting at the given epoch.
+func dealBatch(env dealEnv, batch int, start abi.ChainEpoch) *market.PublishStorageDealsParams {
+	params := market.PublishStorageDealsParams{Deals: make([]market.ClientDealProposal, dealsPerMessage)}
+	for i := range params.Deals {
+		label := fmt.Sprintf("deal-%d-%d", batch, i)
+		params.Deals[i].Proposal = market.DealProposal{
+			PieceCID:             tutil.MakeCID(label, &market.PieceCIDPrefix),
+			PieceSize:            abi.PaddedPieceSize(1 << 30),
+			Client:               env.client,
+			Provider:             env.miner,
+			Label:                label,
+			StartEpoch:           start,
+			EndEpoch:             start + market.DealMinDuration,
+			StoragePricePerEpoch: abi.NewTokenAmount(1 << 20),
+			ProviderCollateral:   big.Mul(big.NewInt(2), vm.FIL),
+			ClientCollateral:     big.Mul(big.NewInt(1), vm.FIL),
+		}
+	}
+	return &params
+}
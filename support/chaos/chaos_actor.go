@@ -0,0 +1,174 @@
+package chaos
+
+import (
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/cbor"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/ipfs/go-cid"
+	mh "github.com/multiformats/go-multihash"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v3/actors/runtime"
+)
+
+// The chaos actor is a puppet for testing how builtin actors and the runtime respond to an adversarial or
+// misbehaving actor. Each method does exactly what its parameters instruct: abort, mutate state legally or
+// illegally, send arbitrary (possibly reentrant) messages, or consume resources.
+// It is not a builtin actor and must never be deployed to a real network.
+type Actor struct{}
+
+// The code CID of the chaos actor.
+var ChaosActorCodeID = func() cid.Cid {
+	builder := cid.V1Builder{Codec: cid.Raw, MhType: mh.IDENTITY}
+	c, err := builder.Sum([]byte("fil/3/chaos"))
+	if err != nil {
+		panic(err)
+	}
+	return c
+}()
+
+// The conventional address at which the chaos actor is installed.
+var ChaosActorAddr = func() address.Address {
+	a, err := address.NewIDAddress(98)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}()
+
+var MethodsChaos = struct {
+	Constructor      abi.MethodNum
+	AbortWith        abi.MethodNum
+	MutateState      abi.MethodNum
+	Send             abi.MethodNum
+	ConsumeResources abi.MethodNum
+}{builtin.MethodConstructor, 2, 3, 4, 5}
+
+func (a Actor) Exports() []interface{} {
+	return []interface{}{
+		builtin.MethodConstructor: a.Constructor,
+		2:                         a.AbortWith,
+		3:                         a.MutateState,
+		4:                         a.Send,
+		5:                         a.ConsumeResources,
+	}
+}
+
+func (a Actor) Code() cid.Cid {
+	return ChaosActorCodeID
+}
+
+func (a Actor) IsSingleton() bool {
+	return true
+}
+
+func (a Actor) State() cbor.Er {
+	return new(State)
+}
+
+var _ runtime.VMActor = Actor{}
+
+type State struct {
+	// A value that is written by MutateState.
+	Value int64
+}
+
+// The chaos actor is installed directly into the state tree rather than constructed by a message.
+func (a Actor) Constructor(rt runtime.Runtime, _ *abi.EmptyValue) *abi.EmptyValue {
+	rt.Abortf(exitcode.SysErrorIllegalActor, "chaos actor is a singleton and cannot be constructed")
+	return nil
+}
+
+type AbortWithArgs struct {
+	Code exitcode.ExitCode
+}
+
+// Aborts with the exit code given in the parameters.
+func (a Actor) AbortWith(rt runtime.Runtime, args *AbortWithArgs) *abi.EmptyValue {
+	rt.ValidateImmediateCallerAcceptAny()
+	rt.Abortf(args.Code, "chaos actor aborting as instructed")
+	return nil
+}
+
+// Selects the manner in which MutateState writes the state's value.
+type MutateStateBranch int64
+
+const (
+	// Writes the value within a state transaction, which is legal.
+	MutateInTransaction MutateStateBranch = iota
+	// Writes the value to a read-only copy of the state, which the runtime must reject.
+	MutateReadonly
+	// Writes the value to the state object after its transaction has completed, which the runtime must reject.
+	MutateAfterTransaction
+)
+
+type MutateStateArgs struct {
+	Value  int64
+	Branch MutateStateBranch
+}
+
+// Writes a value to the actor's state, legally or otherwise.
+func (a Actor) MutateState(rt runtime.Runtime, args *MutateStateArgs) *abi.EmptyValue {
+	rt.ValidateImmediateCallerAcceptAny()
+	var st State
+	switch args.Branch {
+	case MutateInTransaction:
+		rt.StateTransaction(&st, func() {
+			st.Value = args.Value
+		})
+	case MutateReadonly:
+		rt.StateReadonly(&st)
+		st.Value = args.Value
+	case MutateAfterTransaction:
+		rt.StateTransaction(&st, func() {
+			st.Value = args.Value + 1
+		})
+		st.Value = args.Value
+	default:
+		rt.Abortf(exitcode.ErrIllegalArgument, "unknown mutation branch %d", args.Branch)
+	}
+	return nil
+}
+
+type SendArgs struct {
+	To     address.Address
+	Value  abi.TokenAmount
+	Method abi.MethodNum
+	Params []byte
+}
+
+type SendReturn struct {
+	Return []byte
+	Code   exitcode.ExitCode
+}
+
+// Sends a message with arbitrary recipient, method, value and raw parameters, returning the callee's
+// exit code and return value without interpretation. The recipient may be the chaos actor itself,
+// or the actor that called it, to exercise reentrancy.
+func (a Actor) Send(rt runtime.Runtime, args *SendArgs) *SendReturn {
+	rt.ValidateImmediateCallerAcceptAny()
+	var ret builtin.CBORBytes
+	code := rt.Send(args.To, args.Method, builtin.CBORBytes(args.Params), args.Value, &ret)
+	return &SendReturn{Return: ret, Code: code}
+}
+
+type ConsumeResourcesArgs struct {
+	// Number of distinct blocks to write to the store.
+	Blocks uint64
+	// Gas to charge explicitly.
+	Gas int64
+}
+
+// Writes blocks to the store and charges gas, as an actor with expensive or runaway computation might.
+func (a Actor) ConsumeResources(rt runtime.Runtime, args *ConsumeResourcesArgs) *abi.EmptyValue {
+	rt.ValidateImmediateCallerAcceptAny()
+	for i := uint64(0); i < args.Blocks; i++ {
+		block := State{Value: int64(i)}
+		rt.StorePut(&block)
+	}
+	if args.Gas > 0 {
+		rt.ChargeGas("chaos", args.Gas, 0)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package chaos_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	address "github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/cbor"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/specs-actors/v3/support/chaos"
+	"github.com/filecoin-project/specs-actors/v3/support/ipld"
+	vm "github.com/filecoin-project/specs-actors/v3/support/vm"
+)
+
+func TestChaosActor(t *testing.T) {
+	ctx := context.Background()
+	zero := big.Zero()
+	setup := func(t *testing.T) (*vm.VM, address.Address) {
+		v := vm.NewVMWithSingletons(ctx, t, ipld.NewBlockStoreInMemory())
+		vm.InstallActor(ctx, t, v, chaos.Actor{}, &chaos.State{}, chaos.ChaosActorAddr, zero)
+		return v, vm.CreateAccounts(ctx, t, v, 1, vm.FIL, 1)[0]
+	}
+	value := func(t *testing.T, v *vm.VM) int64 {
+		var st chaos.State
+		require.NoError(t, v.GetState(chaos.ChaosActorAddr, &st))
+		return st.Value
+	}
+
+	t.Run("aborts with the instructed code", func(t *testing.T) {
+		v, sender := setup(t)
+		_, code := v.ApplyMessage(sender, chaos.ChaosActorAddr, zero, chaos.MethodsChaos.AbortWith,
+			&chaos.AbortWithArgs{Code: exitcode.ErrForbidden})
+		assert.Equal(t, exitcode.ErrForbidden, code)
+	})
+
+	t.Run("cannot be constructed", func(t *testing.T) {
+		v, sender := setup(t)
+		_, code := v.ApplyMessage(sender, chaos.ChaosActorAddr, zero, chaos.MethodsChaos.Constructor, nil)
+		assert.Equal(t, exitcode.SysErrorIllegalActor, code)
+	})
+
+	t.Run("state mutation", func(t *testing.T) {
+		v, sender := setup(t)
+
+		vm.ApplyOk(t, v, sender, chaos.ChaosActorAddr, zero, chaos.MethodsChaos.MutateState,
+			&chaos.MutateStateArgs{Value: 7, Branch: chaos.MutateInTransaction})
+		assert.Equal(t, int64(7), value(t, v))
+
+		for _, branch := range []chaos.MutateStateBranch{chaos.MutateReadonly, chaos.MutateAfterTransaction} {
+			_, code := v.ApplyMessage(sender, chaos.ChaosActorAddr, zero, chaos.MethodsChaos.MutateState,
+				&chaos.MutateStateArgs{Value: 8, Branch: branch})
+			assert.Equal(t, exitcode.SysErrorIllegalActor, code, "branch %d", branch)
+			assert.Equal(t, int64(7), value(t, v), "branch %d", branch)
+		}
+	})
+
+	t.Run("reentrant send", func(t *testing.T) {
+		v, sender := setup(t)
+
+		// The chaos actor calls itself to mutate its own state.
+		ret := vm.ApplyOk(t, v, sender, chaos.ChaosActorAddr, zero, chaos.MethodsChaos.Send, &chaos.SendArgs{
+			To:     chaos.ChaosActorAddr,
+			Value:  zero,
+			Method: chaos.MethodsChaos.MutateState,
+			Params: mustSerialize(t, &chaos.MutateStateArgs{Value: 3, Branch: chaos.MutateInTransaction}),
+		})
+		assert.Equal(t, exitcode.Ok, ret.(*chaos.SendReturn).Code)
+		assert.Equal(t, int64(3), value(t, v))
+
+		// An aborting callee's exit code is returned to the chaos actor rather than aborting it.
+		ret = vm.ApplyOk(t, v, sender, chaos.ChaosActorAddr, zero, chaos.MethodsChaos.Send, &chaos.SendArgs{
+			To:     chaos.ChaosActorAddr,
+			Value:  zero,
+			Method: chaos.MethodsChaos.AbortWith,
+			Params: mustSerialize(t, &chaos.AbortWithArgs{Code: exitcode.ErrIllegalState}),
+		})
+		assert.Equal(t, exitcode.ErrIllegalState, ret.(*chaos.SendReturn).Code)
+	})
+
+	t.Run("consumes resources", func(t *testing.T) {
+		v, sender := setup(t)
+		vm.ApplyOk(t, v, sender, chaos.ChaosActorAddr, zero, chaos.MethodsChaos.ConsumeResources,
+			&chaos.ConsumeResourcesArgs{Blocks: 10, Gas: 1000})
+	})
+}
+
+func mustSerialize(t *testing.T, o cbor.Marshaler) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, o.MarshalCBOR(&buf))
+	return buf.Bytes()
+}
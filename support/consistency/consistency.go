@@ -0,0 +1,115 @@
+package consistency
+
+import (
+	addr "github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin/market"
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin/miner"
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin/power"
+	"github.com/filecoin-project/specs-actors/v3/actors/states"
+	"github.com/filecoin-project/specs-actors/v3/actors/util/adt"
+)
+
+// Check asserts invariants relating token balances and power across the actors in a state tree.
+// The storage market actor's balance must cover the total of its escrow table.
+// Each miner's balance must cover its locked funds, pre-commit deposits and initial pledge,
+// and its power claim must equal the power of its active sectors.
+// The balances of all actors must sum to expectedTotal, i.e. no tokens were created or destroyed.
+// Unlike states.CheckStateInvariants, actors with unrecognised code are ignored, and no actor's internal
+// invariants are checked, so this may be applied to any scenario including those installing test actors.
+// Violations are reported as messages; an error is returned only if state cannot be loaded.
+func Check(tree *states.Tree, expectedTotal abi.TokenAmount) (*builtin.MessageAccumulator, error) {
+	acc := &builtin.MessageAccumulator{}
+	total := big.Zero()
+	var marketActor, powerActor *states.Actor
+	miners := map[addr.Address]*states.Actor{}
+	if err := tree.ForEach(func(key addr.Address, actor *states.Actor) error {
+		total = big.Add(total, actor.Balance)
+		a := *actor
+		switch actor.Code {
+		case builtin.StorageMarketActorCodeID:
+			marketActor = &a
+		case builtin.StoragePowerActorCodeID:
+			powerActor = &a
+		case builtin.StorageMinerActorCodeID:
+			miners[key] = &a
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if !total.Equals(expectedTotal) {
+		acc.Addf("total token balance is %v, expected %v", total, expectedTotal)
+	}
+
+	if marketActor != nil {
+		if err := checkMarketEscrow(acc.WithPrefix("market: "), tree.Store, marketActor); err != nil {
+			return nil, err
+		}
+	}
+
+	var powerSt *power.State
+	if powerActor != nil {
+		powerSt = new(power.State)
+		if err := tree.Store.Get(tree.Store.Context(), powerActor.Head, powerSt); err != nil {
+			return nil, xerrors.Errorf("failed to load power state: %w", err)
+		}
+	}
+	for key, actor := range miners { //nolint:nomaprange
+		if err := checkMiner(acc.WithPrefix("miner %v: ", key), tree.Store, key, actor, powerSt); err != nil {
+			return nil, err
+		}
+	}
+	return acc, nil
+}
+
+func checkMarketEscrow(acc *builtin.MessageAccumulator, store adt.Store, actor *states.Actor) error {
+	var st market.State
+	if err := store.Get(store.Context(), actor.Head, &st); err != nil {
+		return xerrors.Errorf("failed to load market state: %w", err)
+	}
+	escrow, err := adt.AsBalanceTable(store, st.EscrowTable)
+	if err != nil {
+		return xerrors.Errorf("failed to load escrow table: %w", err)
+	}
+	escrowTotal, err := escrow.Total()
+	if err != nil {
+		return xerrors.Errorf("failed to total escrow table: %w", err)
+	}
+	acc.Require(actor.Balance.GreaterThanEqual(escrowTotal), "escrow total %v exceeds actor balance %v", escrowTotal, actor.Balance)
+	return nil
+}
+
+func checkMiner(acc *builtin.MessageAccumulator, store adt.Store, key addr.Address, actor *states.Actor, powerSt *power.State) error {
+	var st miner.State
+	if err := store.Get(store.Context(), actor.Head, &st); err != nil {
+		return xerrors.Errorf("failed to load state of miner %v: %w", key, err)
+	}
+
+	required := big.Sum(st.LockedFunds, st.PreCommitDeposits, st.InitialPledge)
+	acc.Require(required.LessThanEqual(actor.Balance),
+		"locked funds %v, pre-commit deposits %v and initial pledge %v exceed balance %v",
+		st.LockedFunds, st.PreCommitDeposits, st.InitialPledge, actor.Balance)
+
+	if powerSt == nil {
+		return nil
+	}
+	// The summary accounts sector power; the miner's internal invariants are not asserted here.
+	summary, _ := miner.CheckStateInvariants(&st, store, actor.Balance)
+	claim, found, err := powerSt.GetClaim(store, key)
+	if err != nil {
+		return xerrors.Errorf("failed to load claim of miner %v: %w", key, err)
+	}
+	if !found {
+		acc.Require(summary.ActivePower.IsZero(), "active power %v has no power claim", summary.ActivePower)
+		return nil
+	}
+	claimed := miner.NewPowerPair(claim.RawBytePower, claim.QualityAdjPower)
+	acc.Require(summary.ActivePower.Equals(claimed), "active power %v does not equal claim %v", summary.ActivePower, claimed)
+	return nil
+}
@@ -0,0 +1,78 @@
+package consistency_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin/market"
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin/power"
+	"github.com/filecoin-project/specs-actors/v3/actors/util/adt"
+	"github.com/filecoin-project/specs-actors/v3/support/consistency"
+	"github.com/filecoin-project/specs-actors/v3/support/ipld"
+	vm "github.com/filecoin-project/specs-actors/v3/support/vm"
+)
+
+func TestCheck(t *testing.T) {
+	ctx := context.Background()
+	setup := func(t *testing.T) (*vm.VM, abi.TokenAmount) {
+		v := vm.NewVMWithSingletons(ctx, t, ipld.NewBlockStoreInMemory())
+		addrs := vm.CreateAccounts(ctx, t, v, 1, big.Mul(big.NewInt(10_000), vm.FIL), 93837778)
+		owner := addrs[0]
+		vm.ApplyOk(t, v, owner, builtin.StoragePowerActorAddr, big.Mul(big.NewInt(100), vm.FIL), builtin.MethodsPower.CreateMiner,
+			&power.CreateMinerParams{
+				Owner:               owner,
+				Worker:              owner,
+				WindowPoStProofType: abi.RegisteredPoStProof_StackedDrgWindow32GiBV1,
+				Peer:                abi.PeerID("pid"),
+			})
+		vm.ApplyOk(t, v, owner, builtin.StorageMarketActorAddr, big.Mul(big.NewInt(10), vm.FIL), builtin.MethodsMarket.AddBalance, &owner)
+
+		total, err := v.GetTotalActorBalance()
+		require.NoError(t, err)
+		return v, total
+	}
+	check := func(t *testing.T, v *vm.VM, total abi.TokenAmount) []string {
+		tree, err := v.GetStateTree()
+		require.NoError(t, err)
+		acc, err := consistency.Check(tree, total)
+		require.NoError(t, err)
+		return acc.Messages()
+	}
+
+	t.Run("consistent scenario", func(t *testing.T) {
+		v, total := setup(t)
+		assert.Empty(t, check(t, v, total))
+	})
+
+	t.Run("supply not conserved", func(t *testing.T) {
+		v, total := setup(t)
+		msgs := check(t, v, big.Add(total, big.NewInt(1)))
+		require.Len(t, msgs, 1)
+		assert.Contains(t, msgs[0], "total token balance")
+	})
+
+	t.Run("escrow exceeds market balance", func(t *testing.T) {
+		v, total := setup(t)
+		v.SkipConsistencyCheck()
+
+		var st market.State
+		require.NoError(t, v.GetState(builtin.StorageMarketActorAddr, &st))
+		escrow, err := adt.AsBalanceTable(v.Store(), st.EscrowTable)
+		require.NoError(t, err)
+		require.NoError(t, escrow.Add(builtin.BurntFundsActorAddr, big.NewInt(1)))
+		st.EscrowTable, err = escrow.Root()
+		require.NoError(t, err)
+		require.NoError(t, v.SetActorState(ctx, builtin.StorageMarketActorAddr, &st))
+
+		msgs := check(t, v, total)
+		require.Len(t, msgs, 1)
+		assert.True(t, strings.HasPrefix(msgs[0], "market: escrow total"), msgs[0])
+	})
+}
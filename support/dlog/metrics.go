@@ -0,0 +1,31 @@
+// Package dlog provides diagnostic hooks for observing actor execution in the test VM and simulations.
+package dlog
+
+import (
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	cid "github.com/ipfs/go-cid"
+)
+
+// Metrics receives events emitted during actor execution.
+// Implementations must tolerate being called from nested invocations, and should be cheap,
+// since they are called on every method invocation and state write.
+type Metrics interface {
+	// Reports the invocation of a method on an actor with the given code.
+	// Plain value transfers (method 0) are not reported.
+	MethodInvoked(code cid.Cid, method abi.MethodNum)
+	// Reports that a method invocation aborted with a non-zero exit code.
+	// An abort is also reported for each enclosing invocation that aborts as a result.
+	MethodAborted(code cid.Cid, method abi.MethodNum, exitCode exitcode.ExitCode)
+	// Reports the size of an object written to the store by an actor, including its state root.
+	StateBytesWritten(code cid.Cid, size uint64)
+}
+
+// Noop is the default Metrics, which discards all events.
+var Noop Metrics = noopMetrics{}
+
+type noopMetrics struct{}
+
+func (noopMetrics) MethodInvoked(cid.Cid, abi.MethodNum)                    {}
+func (noopMetrics) MethodAborted(cid.Cid, abi.MethodNum, exitcode.ExitCode) {}
+func (noopMetrics) StateBytesWritten(cid.Cid, uint64)                       {}
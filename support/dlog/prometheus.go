@@ -0,0 +1,168 @@
+//go:build prometheus
+// +build prometheus
+
+package dlog
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	cid "github.com/ipfs/go-cid"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+)
+
+// Upper bounds of the histogram buckets for state write sizes, in bytes.
+var StateBytesBuckets = []uint64{64, 256, 1024, 4096, 16384, 65536, 262144}
+
+// Collector is a Metrics that accumulates counters and histograms in memory and renders them in the
+// Prometheus text exposition format, labelled by actor name, method number and exit code.
+// It is safe for concurrent use.
+type Collector struct {
+	lk          sync.Mutex
+	invocations map[methodKey]uint64
+	aborts      map[abortKey]uint64
+	stateBytes  map[string]*histogram
+}
+
+type methodKey struct {
+	actor  string
+	method abi.MethodNum
+}
+
+type abortKey struct {
+	methodKey
+	exitCode exitcode.ExitCode
+}
+
+type histogram struct {
+	buckets []uint64 // Cumulative counts, parallel to StateBytesBuckets.
+	count   uint64
+	sum     uint64
+}
+
+var _ Metrics = (*Collector)(nil)
+
+func NewCollector() *Collector {
+	return &Collector{
+		invocations: map[methodKey]uint64{},
+		aborts:      map[abortKey]uint64{},
+		stateBytes:  map[string]*histogram{},
+	}
+}
+
+func (c *Collector) MethodInvoked(code cid.Cid, method abi.MethodNum) {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+	c.invocations[methodKey{builtin.ActorNameByCode(code), method}]++
+}
+
+func (c *Collector) MethodAborted(code cid.Cid, method abi.MethodNum, exitCode exitcode.ExitCode) {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+	c.aborts[abortKey{methodKey{builtin.ActorNameByCode(code), method}, exitCode}]++
+}
+
+func (c *Collector) StateBytesWritten(code cid.Cid, size uint64) {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+	name := builtin.ActorNameByCode(code)
+	h, ok := c.stateBytes[name]
+	if !ok {
+		h = &histogram{buckets: make([]uint64, len(StateBytesBuckets))}
+		c.stateBytes[name] = h
+	}
+	for i, le := range StateBytesBuckets {
+		if size <= le {
+			h.buckets[i]++
+		}
+	}
+	h.count++
+	h.sum += size
+}
+
+// Writes the collected metrics in the Prometheus text exposition format, in a deterministic order.
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+
+	fmt.Fprintln(cw, "# HELP specs_actors_method_invocations_total Actor method invocations.")
+	fmt.Fprintln(cw, "# TYPE specs_actors_method_invocations_total counter")
+	invocations := make([]methodKey, 0, len(c.invocations))
+	for k := range c.invocations {
+		invocations = append(invocations, k)
+	}
+	sort.Slice(invocations, func(i, j int) bool { return invocations[i].less(invocations[j]) })
+	for _, k := range invocations {
+		fmt.Fprintf(cw, "specs_actors_method_invocations_total{actor=%q,method=\"%d\"} %d\n", k.actor, k.method, c.invocations[k])
+	}
+
+	fmt.Fprintln(cw, "# HELP specs_actors_method_aborts_total Actor method invocations aborting with a non-zero exit code.")
+	fmt.Fprintln(cw, "# TYPE specs_actors_method_aborts_total counter")
+	aborts := make([]abortKey, 0, len(c.aborts))
+	for k := range c.aborts {
+		aborts = append(aborts, k)
+	}
+	sort.Slice(aborts, func(i, j int) bool {
+		if aborts[i].methodKey != aborts[j].methodKey {
+			return aborts[i].methodKey.less(aborts[j].methodKey)
+		}
+		return aborts[i].exitCode < aborts[j].exitCode
+	})
+	for _, k := range aborts {
+		fmt.Fprintf(cw, "specs_actors_method_aborts_total{actor=%q,method=\"%d\",exit_code=\"%d\"} %d\n",
+			k.actor, k.method, k.exitCode, c.aborts[k])
+	}
+
+	fmt.Fprintln(cw, "# HELP specs_actors_state_bytes_written Size of objects written to the store by actors.")
+	fmt.Fprintln(cw, "# TYPE specs_actors_state_bytes_written histogram")
+	names := make([]string, 0, len(c.stateBytes))
+	for name := range c.stateBytes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		h := c.stateBytes[name]
+		for i, le := range StateBytesBuckets {
+			fmt.Fprintf(cw, "specs_actors_state_bytes_written_bucket{actor=%q,le=\"%d\"} %d\n", name, le, h.buckets[i])
+		}
+		fmt.Fprintf(cw, "specs_actors_state_bytes_written_bucket{actor=%q,le=\"+Inf\"} %d\n", name, h.count)
+		fmt.Fprintf(cw, "specs_actors_state_bytes_written_sum{actor=%q} %d\n", name, h.sum)
+		fmt.Fprintf(cw, "specs_actors_state_bytes_written_count{actor=%q} %d\n", name, h.count)
+	}
+
+	if cw.err == nil {
+		cw.err = cw.w.Flush()
+	}
+	return cw.n, cw.err
+}
+
+func (k methodKey) less(o methodKey) bool {
+	if k.actor != o.actor {
+		return k.actor < o.actor
+	}
+	return k.method < o.method
+}
+
+// Counts bytes written and retains the first error, so that rendering need not check each write.
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	if cw.err != nil {
+		return 0, cw.err
+	}
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	cw.err = err
+	return n, err
+}
@@ -0,0 +1,42 @@
+//go:build prometheus
+// +build prometheus
+
+package dlog_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v3/support/dlog"
+)
+
+func TestCollector(t *testing.T) {
+	c := dlog.NewCollector()
+	c.MethodInvoked(builtin.StorageMinerActorCodeID, builtin.MethodsMiner.SubmitWindowedPoSt)
+	c.MethodInvoked(builtin.StorageMinerActorCodeID, builtin.MethodsMiner.SubmitWindowedPoSt)
+	c.MethodInvoked(builtin.StoragePowerActorCodeID, builtin.MethodsPower.OnEpochTickEnd)
+	c.MethodAborted(builtin.StorageMinerActorCodeID, builtin.MethodsMiner.SubmitWindowedPoSt, exitcode.ErrIllegalArgument)
+	c.StateBytesWritten(builtin.StorageMinerActorCodeID, 100)
+	c.StateBytesWritten(builtin.StorageMinerActorCodeID, 5000)
+
+	var buf bytes.Buffer
+	n, err := c.WriteTo(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, int64(buf.Len()), n)
+
+	out := buf.String()
+	assert.Contains(t, out, `specs_actors_method_invocations_total{actor="fil/3/storageminer",method="5"} 2`)
+	assert.Contains(t, out, `specs_actors_method_invocations_total{actor="fil/3/storagepower",method="5"} 1`)
+	assert.Contains(t, out, `specs_actors_method_aborts_total{actor="fil/3/storageminer",method="5",exit_code="16"} 1`)
+	assert.Contains(t, out, `specs_actors_state_bytes_written_bucket{actor="fil/3/storageminer",le="64"} 0`)
+	assert.Contains(t, out, `specs_actors_state_bytes_written_bucket{actor="fil/3/storageminer",le="256"} 1`)
+	assert.Contains(t, out, `specs_actors_state_bytes_written_bucket{actor="fil/3/storageminer",le="16384"} 2`)
+	assert.Contains(t, out, `specs_actors_state_bytes_written_bucket{actor="fil/3/storageminer",le="+Inf"} 2`)
+	assert.Contains(t, out, `specs_actors_state_bytes_written_sum{actor="fil/3/storageminer"} 5100`)
+	assert.Contains(t, out, `specs_actors_state_bytes_written_count{actor="fil/3/storageminer"} 2`)
+}
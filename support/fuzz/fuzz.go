@@ -0,0 +1,100 @@
+// Package fuzz invokes actor methods with randomly generated parameters in a scenario VM, checking that
+// the only failure mode of an invalid message is a clean abort: no panics and no state that violates invariants.
+package fuzz
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	addr "github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/cbor"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v3/actors/states"
+	vm "github.com/filecoin-project/specs-actors/v3/support/vm"
+)
+
+// Invokes each exported method of the actor at target iterations times with generated parameters and values,
+// sent from senders chosen at random.
+// Fails the test if any invocation panics rather than aborting, or if the state tree violates invariants
+// after a method's invocations.
+// Successful invocations are retained in the VM state, so later invocations see the effects of earlier ones.
+func FuzzActor(t *testing.T, v *vm.VM, gen *Generator, target addr.Address, senders []addr.Address, iterations int) {
+	act, found, err := v.GetActor(target)
+	require.NoError(t, err)
+	require.True(t, found, "no actor at %v", target)
+	impl, ok := v.GetActorImpls()[act.Code]
+	require.True(t, ok, "no implementation for actor code %v", act.Code)
+
+	for method, export := range impl.Exports() {
+		if method == int(builtin.MethodSend) || export == nil {
+			continue
+		}
+		paramsType := reflect.TypeOf(export).In(1)
+
+		codes := map[exitcode.ExitCode]int{}
+		for i := 0; i < iterations; i++ {
+			params, err := encodeParams(gen.Params(paramsType))
+			require.NoError(t, err, "failed to encode generated params for method %d", method)
+
+			from := senders[gen.rnd.Intn(len(senders))]
+			value := big.Zero()
+			if gen.rnd.Intn(4) == 0 {
+				value = gen.TokenAmount()
+				if value.LessThan(big.Zero()) {
+					value = value.Neg()
+				}
+			}
+			code := applyMessage(t, v, from, target, value, abi.MethodNum(method), params)
+			codes[code]++
+		}
+		t.Logf("method %d: exit codes %v", method, codes)
+
+		checkInvariants(t, v, fmt.Sprintf("method %d", method))
+	}
+}
+
+// Applies a message, converting any panic that escapes the VM into a test failure.
+func applyMessage(t *testing.T, v *vm.VM, from, to addr.Address, value abi.TokenAmount, method abi.MethodNum,
+	params builtin.CBORBytes) (code exitcode.ExitCode) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Fatalf("method %d on %v panicked with params %x from %v: %v", method, to, []byte(params), from, r)
+		}
+	}()
+	_, code = v.ApplyMessage(from, to, value, method, params)
+	return code
+}
+
+// Encodes a generated parameter value, so that it is decoded by the VM exactly as a message on chain would be.
+func encodeParams(params reflect.Value) (builtin.CBORBytes, error) {
+	if params.Kind() == reflect.Ptr && params.IsNil() {
+		return nil, nil // no parameters
+	}
+	m, ok := params.Interface().(cbor.Marshaler)
+	if !ok {
+		return nil, fmt.Errorf("params type %v is not a cbor marshaler", params.Type())
+	}
+	var buf bytes.Buffer
+	if err := m.MarshalCBOR(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func checkInvariants(t *testing.T, v *vm.VM, context string) {
+	stateTree, err := v.GetStateTree()
+	require.NoError(t, err)
+	totalBalance, err := v.GetTotalActorBalance()
+	require.NoError(t, err)
+	acc, err := states.CheckStateInvariants(stateTree, totalBalance, v.GetEpoch())
+	require.NoError(t, err, "failed to check state invariants after %s", context)
+	require.True(t, acc.IsEmpty(), "state invariants violated after %s:\n%s", context, strings.Join(acc.Messages(), "\n"))
+}
@@ -0,0 +1,249 @@
+package fuzz
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+
+	addr "github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-bitfield"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/crypto"
+	"github.com/ipfs/go-cid"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin/market"
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin/miner"
+	tutil "github.com/filecoin-project/specs-actors/v3/support/testing"
+)
+
+// Generator produces random values of actor method parameter types.
+// Generated values are always encodable, i.e. they are values that could have been decoded from a message on chain:
+// big integers are never nil and CIDs are always defined.
+type Generator struct {
+	rnd *rand.Rand
+	// Addresses from which generated addresses are usually drawn, so that parameters refer to actors that exist.
+	addrs []addr.Address
+	// Maximum length of generated slices, strings and bitfields.
+	maxLen int
+}
+
+var (
+	typeAddress  = reflect.TypeOf(addr.Address{})
+	typeCid      = reflect.TypeOf(cid.Cid{})
+	typeBigInt   = reflect.TypeOf(big.Int{})
+	typeBitField = reflect.TypeOf(bitfield.BitField{})
+	typeSig      = reflect.TypeOf(crypto.Signature{})
+
+	typeEmptyParams = reflect.TypeOf((*abi.EmptyValue)(nil))
+)
+
+// CID prefixes checked by actors, so that generated CIDs sometimes pass prefix validation.
+var cidPrefixes = []*cid.Prefix{nil, &miner.SealedCIDPrefix, &market.PieceCIDPrefix}
+
+// Creates a generator with a deterministic seed. Generated addresses are usually drawn from addrs.
+func NewGenerator(seed int64, addrs []addr.Address) *Generator {
+	return &Generator{
+		rnd:    rand.New(rand.NewSource(seed)),
+		addrs:  addrs,
+		maxLen: 8,
+	}
+}
+
+// Returns a new random value of type typ.
+func (g *Generator) Value(typ reflect.Type) reflect.Value {
+	v := reflect.New(typ).Elem()
+	g.fill(v)
+	return v
+}
+
+// Returns a new random value of a method's parameter type.
+// Unlike optional values nested within them, a method's parameters are always present,
+// except for methods taking no parameters, for which the value is nil.
+func (g *Generator) Params(typ reflect.Type) reflect.Value {
+	if typ == typeEmptyParams {
+		return reflect.Zero(typ)
+	}
+	if typ.Kind() == reflect.Ptr {
+		return g.Value(typ.Elem()).Addr()
+	}
+	return g.Value(typ)
+}
+
+// Returns a random token amount, biased towards small values.
+func (g *Generator) TokenAmount() abi.TokenAmount {
+	return g.bigInt()
+}
+
+// Chooses one of the generator's known addresses.
+func (g *Generator) KnownAddress() addr.Address {
+	return g.addrs[g.rnd.Intn(len(g.addrs))]
+}
+
+func (g *Generator) fill(v reflect.Value) {
+	switch v.Type() {
+	case typeAddress:
+		v.Set(reflect.ValueOf(g.address()))
+		return
+	case typeCid:
+		v.Set(reflect.ValueOf(g.cid()))
+		return
+	case typeBigInt:
+		v.Set(reflect.ValueOf(g.bigInt()))
+		return
+	case typeBitField:
+		v.Set(reflect.ValueOf(g.bitField()))
+		return
+	case typeSig:
+		v.Set(reflect.ValueOf(g.signature()))
+		return
+	}
+	if v.Kind() == reflect.Struct && v.Type().ConvertibleTo(typeCid) {
+		// Named CID types, e.g. cbg.CborCid.
+		v.Set(reflect.ValueOf(g.cid()).Convert(v.Type()))
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(g.rnd.Intn(2) == 0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(g.int64(v.Type().Bits()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(g.uint64(v.Type().Bits()))
+	case reflect.String:
+		buf := make([]byte, g.rnd.Intn(g.maxLen+1))
+		for i := range buf {
+			buf[i] = byte('a' + g.rnd.Intn(26))
+		}
+		v.SetString(string(buf))
+	case reflect.Slice:
+		n := g.rnd.Intn(g.maxLen + 1)
+		s := reflect.MakeSlice(v.Type(), n, n)
+		for i := 0; i < n; i++ {
+			g.fill(s.Index(i))
+		}
+		v.Set(s)
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			g.fill(v.Index(i))
+		}
+	case reflect.Ptr:
+		// Optional values are encoded as pointers, and may be absent.
+		if g.rnd.Intn(4) == 0 {
+			v.Set(reflect.Zero(v.Type()))
+			return
+		}
+		p := reflect.New(v.Type().Elem())
+		g.fill(p.Elem())
+		v.Set(p)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath != "" {
+				continue // unexported
+			}
+			g.fill(v.Field(i))
+		}
+	default:
+		panic("fuzz: cannot generate value of type " + v.Type().String())
+	}
+}
+
+// Returns a signed integer of at most the given width, usually small and sometimes extreme.
+func (g *Generator) int64(bits int) int64 {
+	switch g.rnd.Intn(8) {
+	case 0:
+		return 0
+	case 1:
+		return -1
+	case 2:
+		return math.MaxInt64 >> (64 - bits)
+	case 3:
+		return math.MinInt64 >> (64 - bits)
+	case 4:
+		return g.rnd.Int63() >> (64 - bits)
+	default:
+		return int64(g.rnd.Intn(1000))
+	}
+}
+
+// Returns an unsigned integer of at most the given width, usually small and sometimes extreme.
+func (g *Generator) uint64(bits int) uint64 {
+	switch g.rnd.Intn(6) {
+	case 0:
+		return 0
+	case 1:
+		return math.MaxUint64 >> (64 - bits)
+	case 2:
+		return g.rnd.Uint64() >> (64 - bits)
+	default:
+		return uint64(g.rnd.Intn(100)) & (math.MaxUint64 >> (64 - bits))
+	}
+}
+
+func (g *Generator) bigInt() big.Int {
+	switch g.rnd.Intn(6) {
+	case 0:
+		return big.Zero()
+	case 1:
+		return big.NewInt(-g.rnd.Int63())
+	case 2:
+		return big.Mul(big.NewInt(g.rnd.Int63()), big.NewInt(g.rnd.Int63()))
+	default:
+		return big.NewInt(g.rnd.Int63n(1_000_000))
+	}
+}
+
+func (g *Generator) address() addr.Address {
+	if len(g.addrs) > 0 && g.rnd.Intn(4) != 0 {
+		return g.KnownAddress()
+	}
+	var a addr.Address
+	var err error
+	switch g.rnd.Intn(4) {
+	case 0:
+		a, err = addr.NewIDAddress(g.rnd.Uint64() >> 1)
+	case 1:
+		buf := make([]byte, addr.BlsPublicKeyBytes)
+		g.rnd.Read(buf)
+		a, err = addr.NewBLSAddress(buf)
+	case 2:
+		buf := make([]byte, 65)
+		g.rnd.Read(buf)
+		a, err = addr.NewSecp256k1Address(buf)
+	default:
+		buf := make([]byte, 32)
+		g.rnd.Read(buf)
+		a, err = addr.NewActorAddress(buf)
+	}
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+func (g *Generator) cid() cid.Cid {
+	buf := make([]byte, 16)
+	g.rnd.Read(buf)
+	return tutil.MakeCID(string(buf), cidPrefixes[g.rnd.Intn(len(cidPrefixes))])
+}
+
+func (g *Generator) bitField() bitfield.BitField {
+	n := g.rnd.Intn(g.maxLen + 1)
+	bits := make([]uint64, n)
+	for i := range bits {
+		bits[i] = g.uint64(63)
+	}
+	return bitfield.NewFromSet(bits)
+}
+
+// Returns a signature of a valid type, which decoding requires, though the signature itself is not valid.
+func (g *Generator) signature() crypto.Signature {
+	sigType := crypto.SigTypeSecp256k1
+	if g.rnd.Intn(2) == 0 {
+		sigType = crypto.SigTypeBLS
+	}
+	data := make([]byte, g.rnd.Intn(g.maxLen+1))
+	g.rnd.Read(data)
+	return crypto.Signature{Type: sigType, Data: data}
+}
@@ -0,0 +1,158 @@
+package harness
+
+import (
+	"reflect"
+	goruntime "runtime"
+	"strings"
+	"testing"
+
+	addr "github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/cbor"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v3/actors/runtime"
+	"github.com/filecoin-project/specs-actors/v3/support/mock"
+)
+
+// Harness drives an actor's methods in a mock runtime, providing the helpers common to every actor's tests.
+// An actor's test harness embeds a Harness and adds helpers for the actor's own methods, e.g.
+//
+//	type cronHarness struct {
+//		cron.Actor
+//		*harness.Harness
+//	}
+type Harness struct {
+	T        testing.TB
+	Actor    runtime.VMActor
+	Receiver addr.Address
+
+	// The caller of the actor's constructor, which the constructor is expected to validate.
+	// Defaults to the system actor.
+	ConstructorCaller     addr.Address
+	ConstructorCallerCode cid.Cid
+	// Sets the caller validation expected of the constructor, if other than validation of ConstructorCaller.
+	ExpectConstructorValidation func(rt *mock.Runtime)
+
+	// Checks the invariants of the actor's state in a runtime. If nil, CheckState checks nothing.
+	CheckInvariants func(rt *mock.Runtime) *builtin.MessageAccumulator
+}
+
+// Returns a harness for an actor at a receiver address, constructed by the system actor.
+func New(t testing.TB, actor runtime.VMActor, receiver addr.Address) *Harness {
+	return &Harness{
+		T:                     t,
+		Actor:                 actor,
+		Receiver:              receiver,
+		ConstructorCaller:     builtin.SystemActorAddr,
+		ConstructorCallerCode: builtin.SystemActorCodeID,
+	}
+}
+
+// Returns a builder for runtimes of the actor, with the constructor's caller as the initial caller.
+func (h *Harness) Builder() mock.RuntimeBuilder {
+	return mock.NewBuilder(h.Receiver).WithCaller(h.ConstructorCaller, h.ConstructorCallerCode)
+}
+
+// Checks the types of the actor's exported methods, and that each method numbered in a methods table, such as
+// builtin.MethodsCron, is exported with the name of the table's field.
+// The methods table may be nil to check only the exported types.
+func (h *Harness) CheckExports(t *testing.T, methods interface{}) {
+	mock.CheckActorExports(t, h.Actor)
+	if methods == nil {
+		return
+	}
+
+	exports := h.Actor.Exports()
+	v := reflect.ValueOf(methods)
+	for i := 0; i < v.NumField(); i++ {
+		name := v.Type().Field(i).Name
+		num, ok := v.Field(i).Interface().(abi.MethodNum)
+		require.True(t, ok, "method %s is not numbered with a MethodNum", name)
+		if !assert.Less(t, uint64(num), uint64(len(exports)), "method %s number %d not exported", name, num) {
+			continue
+		}
+		if exports[num] == nil {
+			// A retired method number remains in the table but has no export.
+			continue
+		}
+		assert.Equal(t, name, methodName(exports[num]), "method number %d", num)
+	}
+}
+
+// Invokes the actor's constructor with the expected caller and validation, and verifies the runtime's expectations.
+func (h *Harness) ConstructAndVerify(rt *mock.Runtime, params interface{}) {
+	rt.SetCaller(h.ConstructorCaller, h.ConstructorCallerCode)
+	if h.ExpectConstructorValidation != nil {
+		h.ExpectConstructorValidation(rt)
+	} else {
+		rt.ExpectValidateCallerAddr(h.ConstructorCaller)
+	}
+	ret := h.Call(rt, builtin.MethodConstructor, params)
+	assert.Nil(h.T, ret)
+	rt.Verify()
+}
+
+// Invokes an exported method by number, returning its return value.
+// The caller and any expectations must be set beforehand.
+func (h *Harness) Call(rt *mock.Runtime, method abi.MethodNum, params interface{}) interface{} {
+	exports := h.Actor.Exports()
+	require.True(h.T, uint64(method) < uint64(len(exports)) && exports[method] != nil, "no method %d exported", method)
+	return rt.Call(exports[method], params)
+}
+
+// A method invocation by a caller that the method must reject.
+type CallerValidationCase struct {
+	Name   string
+	Method abi.MethodNum
+	Params interface{}
+	// The caller, which the method is expected not to permit.
+	Caller     addr.Address
+	CallerCode cid.Cid
+	// Sets the caller validation the method is expected to perform.
+	Expect func(rt *mock.Runtime)
+}
+
+// Runs a subtest for each case, checking that the method performs the expected caller validation and aborts
+// when invoked by the case's caller. Each case runs in a fresh runtime from newRuntime, which may set up state.
+func (h *Harness) CheckCallerValidation(t *testing.T, newRuntime func(t *testing.T) *mock.Runtime, cases ...CallerValidationCase) {
+	for _, c := range cases {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			rt := newRuntime(t)
+			rt.SetCaller(c.Caller, c.CallerCode)
+			c.Expect(rt)
+			rt.ExpectAbort(exitcode.SysErrForbidden, func() {
+				h.Call(rt, c.Method, c.Params)
+			})
+			rt.Verify()
+		})
+	}
+}
+
+// Loads the actor's state from a runtime, into a new object of the type returned by the actor's State method.
+func (h *Harness) LoadState(rt *mock.Runtime) cbor.Er {
+	st := h.Actor.State()
+	rt.GetState(st)
+	return st
+}
+
+// Checks the invariants of the actor's state, failing the test with any violations.
+func (h *Harness) CheckState(rt *mock.Runtime) {
+	if h.CheckInvariants == nil {
+		return
+	}
+	msgs := h.CheckInvariants(rt)
+	assert.True(h.T, msgs.IsEmpty(), strings.Join(msgs.Messages(), "\n"))
+}
+
+// Returns the name of the method of which a function is a method value.
+func methodName(m interface{}) string {
+	name := goruntime.FuncForPC(reflect.ValueOf(m).Pointer()).Name()
+	name = strings.TrimSuffix(name, "-fm")
+	return name[strings.LastIndex(name, ".")+1:]
+}
@@ -18,6 +18,9 @@ func NewADTStore(ctx context.Context) adt.Store {
 	return adt.WrapBlockStore(ctx, NewBlockStoreInMemory())
 }
 
+// Returned by the in-memory block store for a block it does not hold.
+var ErrNotFound = fmt.Errorf("not found")
+
 //
 // A basic in-memory block store.
 //
@@ -35,7 +38,7 @@ func (mb *BlockStoreInMemory) Get(c cid.Cid) (block.Block, error) {
 	if ok {
 		return d, nil
 	}
-	return nil, fmt.Errorf("not found")
+	return nil, ErrNotFound
 }
 
 func (mb *BlockStoreInMemory) Put(b block.Block) error {
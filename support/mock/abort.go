@@ -0,0 +1,61 @@
+package mock
+
+import (
+	"strings"
+
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"golang.org/x/xerrors"
+)
+
+// An abort raised through Runtime.Abortf, as caught by an ExpectAbort variant.
+type Abort struct {
+	Code    exitcode.ExitCode
+	Message string
+	// The error formatted into the message, such as that passed to builtin.RequireNoErr, or nil.
+	Err error
+	// The contexts in which the abort was raised, outermost first: the message's own context, then the
+	// context added by each wrapping of the error, and finally the error's innermost cause.
+	// E.g. the message "failed to load state: failed to get root: not found" produced by wrapping errors has
+	// contexts ["failed to load state", "failed to get root", "not found"].
+	Contexts []string
+}
+
+func newAbort(a abort) *Abort {
+	return &Abort{
+		Code:     a.code,
+		Message:  a.msg,
+		Err:      a.err,
+		Contexts: abortContexts(a.msg, a.err),
+	}
+}
+
+// Returns the last error among the arguments to an abort's message, which by convention is the abort's cause.
+func abortCause(args []interface{}) error {
+	for i := len(args) - 1; i >= 0; i-- {
+		if err, ok := args[i].(error); ok {
+			return err
+		}
+	}
+	return nil
+}
+
+// Splits an abort message into the contexts added by each wrapping of its cause.
+// The splitting stops at an error whose message doesn't end with that of the error it wraps, or which can't be
+// unwrapped (such as an error carrying an exit code), taking its whole message as the innermost context.
+func abortContexts(msg string, err error) []string {
+	var contexts []string
+	outer := msg
+	for err != nil {
+		inner := err.Error()
+		ctx := strings.TrimSuffix(outer, inner)
+		if ctx == outer {
+			break
+		}
+		if ctx = strings.TrimSuffix(strings.TrimSpace(ctx), ":"); ctx != "" {
+			contexts = append(contexts, ctx)
+		}
+		outer = inner
+		err = xerrors.Unwrap(err)
+	}
+	return append(contexts, outer)
+}
@@ -0,0 +1,91 @@
+package mock
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cbg "github.com/whyrusleeping/cbor-gen"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v3/actors/runtime"
+	tutil "github.com/filecoin-project/specs-actors/v3/support/testing"
+)
+
+var errFakeNotFound = xerrors.New("not found")
+
+// Aborts with an error wrapped a number of times.
+func (a FakeActor) Fail(rt runtime.Runtime, depth *cbg.CborInt) *abi.EmptyValue {
+	rt.ValidateImmediateCallerAcceptAny()
+	err := errFakeNotFound
+	for i := 0; i < int(*depth); i++ {
+		err = xerrors.Errorf("failed at depth %d: %w", i, err)
+	}
+	builtin.RequireNoErr(rt, err, exitcode.ErrNotFound, "failed to load state")
+	return nil
+}
+
+func TestExpectAbort(t *testing.T) {
+	actor := FakeActor{}
+	receiver := tutil.NewIDAddr(t, 100)
+	depth := cbg.CborInt(2)
+	fullMsg := "failed to load state: failed at depth 1: failed at depth 0: not found"
+
+	t.Run("records the abort", func(t *testing.T) {
+		rt := NewBuilder(receiver).Build(t)
+		require.Nil(t, rt.LastAbort())
+		rt.ExpectValidateCallerAny()
+		rt.ExpectAbort(exitcode.ErrNotFound, func() {
+			rt.Call(actor.Fail, &depth)
+		})
+		rt.Verify()
+
+		a := rt.LastAbort()
+		require.NotNil(t, a)
+		assert.Equal(t, exitcode.ErrNotFound, a.Code)
+		assert.Equal(t, fullMsg, a.Message)
+		assert.True(t, xerrors.Is(a.Err, errFakeNotFound))
+		assert.Equal(t, []string{"failed to load state", "failed at depth 1", "failed at depth 0", "not found"}, a.Contexts)
+	})
+
+	t.Run("matches message exactly", func(t *testing.T) {
+		rt := NewBuilder(receiver).Build(t)
+		rt.ExpectValidateCallerAny()
+		rt.ExpectAbortWithMessage(exitcode.ErrNotFound, fullMsg, func() {
+			rt.Call(actor.Fail, &depth)
+		})
+		rt.Verify()
+	})
+
+	t.Run("matches wrapped error", func(t *testing.T) {
+		rt := NewBuilder(receiver).Build(t)
+		rt.ExpectValidateCallerAny()
+		rt.ExpectAbortWithError(exitcode.ErrNotFound, errFakeNotFound, func() {
+			rt.Call(actor.Fail, &depth)
+		})
+		rt.Verify()
+	})
+
+	t.Run("mismatches fail the test", func(t *testing.T) {
+		rt := NewBuilder(receiver).Build(t)
+		rec := &recordingTB{TB: t}
+		rt.t = rec
+		rt.ExpectValidateCallerAny()
+		rt.ExpectAbortWithMessage(exitcode.ErrNotFound, "failed to load state", func() {
+			rt.Call(actor.Fail, &depth)
+		})
+		require.True(t, rec.failed)
+
+		rec.failed = false
+		rt.ExpectValidateCallerAny()
+		rt.ExpectAbortWithError(exitcode.ErrNotFound, xerrors.New("other"), func() {
+			rt.Call(actor.Fail, &depth)
+		})
+		require.True(t, rec.failed)
+		assert.Contains(t, strings.Join(rec.logs, "\n"), "abort expected error wrapping 'other'")
+	})
+}
@@ -2,6 +2,7 @@ package mock
 
 import (
 	"context"
+	"sync"
 	"testing"
 
 	addr "github.com/filecoin-project/go-address"
@@ -9,6 +10,8 @@ import (
 	"github.com/filecoin-project/go-state-types/network"
 	"github.com/ipfs/go-cid"
 	"github.com/minio/blake2b-simd"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/util/adt"
 )
 
 // Build for fluent initialization of a mock runtime.
@@ -39,10 +42,12 @@ func (b RuntimeBuilder) Build(t testing.TB) *Runtime {
 		miner:             addr.Address{},
 		idAddresses:       make(map[addr.Address]addr.Address),
 		circulatingSupply: abi.NewTokenAmount(0),
+		baseFee:           abi.NewTokenAmount(0),
 
-		state:    cid.Undef,
-		store:    make(map[cid.Cid][]byte),
-		hashfunc: blake2b.Sum256,
+		state:     cid.Undef,
+		store:     make(map[cid.Cid][]byte),
+		storeLock: new(sync.RWMutex),
+		hashfunc:  blake2b.Sum256,
 
 		balance:       abi.NewTokenAmount(0),
 		valueReceived: abi.NewTokenAmount(0),
@@ -62,11 +67,16 @@ func (b RuntimeBuilder) Build(t testing.TB) *Runtime {
 	for _, opt := range b.options {
 		opt(m)
 	}
+	t.Cleanup(m.failPendingFailures)
 	return m
 }
 
 func (b *RuntimeBuilder) add(cb func(*Runtime)) {
-	b.options = append(b.options, cb)
+	// Copy the options rather than appending in place, so that builders derived from a common base
+	// (possibly in parallel subtests) never share, and overwrite, each other's options.
+	options := make([]func(*Runtime), len(b.options), len(b.options)+1)
+	copy(options, b.options)
+	b.options = append(options, cb)
 }
 
 func (b RuntimeBuilder) WithEpoch(epoch abi.ChainEpoch) RuntimeBuilder {
@@ -119,3 +129,12 @@ func (b RuntimeBuilder) WithHasher(f func(data []byte) [32]byte) RuntimeBuilder
 	})
 	return b
 }
+
+// Backs the runtime's IPLD storage with the provided store rather than an internal map.
+// This allows tests to inject an instrumented store, e.g. to observe the blocks written by a method.
+func (b RuntimeBuilder) WithStore(store adt.Store) RuntimeBuilder {
+	b.add(func(rt *Runtime) {
+		rt.ipldStore = store
+	})
+	return b
+}
@@ -8,6 +8,7 @@ import (
 	goruntime "runtime"
 	"runtime/debug"
 	"strings"
+	"sync"
 	"testing"
 
 	addr "github.com/filecoin-project/go-address"
@@ -20,6 +21,8 @@ import (
 	"github.com/filecoin-project/go-state-types/rt"
 	cid "github.com/ipfs/go-cid"
 	mh "github.com/multiformats/go-multihash"
+	cbg "github.com/whyrusleeping/cbor-gen"
+	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin/exported"
@@ -46,18 +49,26 @@ type Runtime struct {
 	actorCodeCIDs     map[addr.Address]cid.Cid
 	newActorAddr      addr.Address
 	circulatingSupply abi.TokenAmount
+	baseFee           abi.TokenAmount
 
 	// Actor state
 	state   cid.Cid
 	balance abi.TokenAmount
 
 	// VM implementation
-	store         map[cid.Cid][]byte
+	store map[cid.Cid][]byte
+	// Guards store, which may be read by concurrent clones of this runtime.
+	storeLock *sync.RWMutex
+	// Optional store injected by the test, used in place of the in-memory map above.
+	ipldStore     adt.Store
 	inCall        bool
 	inTransaction bool
 	// Maps (references to) loaded state objs to their expected cid.
 	// Used for detecting modifications to state outside of transactions.
 	stateUsedObjs map[cbor.Marshaler]cid.Cid
+	// Counts of blocks read and written through the Store interface during the current call.
+	storeGets int
+	storePuts int
 	// Syscalls
 	hashfunc func(data []byte) [32]byte
 
@@ -74,13 +85,32 @@ type Runtime struct {
 	expectVerifySeal               *expectVerifySeal
 	expectComputeUnsealedSectorCID *expectComputeUnsealedSectorCID
 	expectVerifyPoSt               *expectVerifyPoSt
+	expectVerifyReplicaUpdate      *expectVerifyReplicaUpdate
 	expectVerifyConsensusFault     *expectVerifyConsensusFault
 	expectDeleteActor              *addr.Address
+	expectReplaceActorCodes        map[cid.Cid]cid.Cid
 	expectBatchVerifySeals         *expectBatchVerifySeals
+	expectAggregateVerifySeals     *expectAggregateVerifySeals
+	expectStoreOps                 *expectStoreOps
+	// Events expected to be emitted, in order. Emitted events are checked only once an event has been expected.
+	expectEvents [][]runtime.EventEntry
+	checkEvents  bool
+	// CIDs expected to be computed, in order. Computations are checked only once a computation has been expected.
+	expectComputeCIDs []*expectComputeCID
+	checkComputeCIDs  bool
+	// Mismatches observed during calls, recorded for reporting at verification.
+	failures []string
 
 	logs []string
+	// Events emitted by calls since the last reset, excluding those emitted by aborted calls.
+	events [][]runtime.EventEntry
 	// Gas charged explicitly through rt.ChargeGas. Note: most charges are implicit
 	gasCharged int64
+	// Transcript of calls, if recording, and the call currently being recorded.
+	transcript     *Transcript
+	transcriptCall *TranscriptCall
+	// The abort most recently caught by an ExpectAbort variant.
+	lastAbort *Abort
 }
 
 type expectBatchVerifySeals struct {
@@ -89,6 +119,16 @@ type expectBatchVerifySeals struct {
 	err error
 }
 
+type expectAggregateVerifySeals struct {
+	in  proof.AggregateSealVerifyProofAndInfos
+	err error
+}
+
+type expectStoreOps struct {
+	maxGets int
+	maxPuts int
+}
+
 type expectRandomness struct {
 	// Expected parameters.
 	tag     crypto.DomainSeparationTag
@@ -104,6 +144,9 @@ type expectedMessage struct {
 	method abi.MethodNum
 	params cbor.Marshaler
 	value  abi.TokenAmount
+	// flavor of send: static, or with a gas limit (zero for none)
+	static   bool
+	gasLimit int64
 
 	// returns from applying expectedMessage
 	sendReturn cbor.Er
@@ -113,6 +156,7 @@ type expectedMessage struct {
 type expectVerifySig struct {
 	// Expected arguments
 	sig       crypto.Signature
+	anyData   bool // Whether to match any signature of the expected signature's type.
 	signer    addr.Address
 	plaintext []byte
 	// Result
@@ -131,11 +175,25 @@ type expectComputeUnsealedSectorCID struct {
 	resultErr error
 }
 
+type expectComputeCID struct {
+	// Expected arguments
+	codec uint64
+	data  []byte
+	// Result
+	cid       cid.Cid
+	resultErr error
+}
+
 type expectVerifyPoSt struct {
 	post   proof.WindowPoStVerifyInfo
 	result error
 }
 
+type expectVerifyReplicaUpdate struct {
+	update proof.ReplicaUpdateInfo
+	result error
+}
+
 func (m *expectedMessage) Equal(to addr.Address, method abi.MethodNum, params cbor.Marshaler, value abi.TokenAmount) bool {
 	// avoid nil vs. zero/empty discrepancies that would disappear in serialization
 	paramBuf1 := new(bytes.Buffer)
@@ -150,14 +208,21 @@ func (m *expectedMessage) Equal(to addr.Address, method abi.MethodNum, params cb
 	return m.to == to && m.method == method && m.value.Equals(value) && bytes.Equal(paramBuf1.Bytes(), paramBuf2.Bytes())
 }
 
+func (m *expectedMessage) SameFlavor(static bool, gasLimit int64) bool {
+	return m.static == static && m.gasLimit == gasLimit
+}
+
 func (m *expectedMessage) String() string {
-	return fmt.Sprintf("to: %v method: %v value: %v params: %v sendReturn: %v exitCode: %v", m.to, m.method, m.value, m.params, m.sendReturn, m.exitCode)
+	return fmt.Sprintf("%s to: %v method: %v value: %v params: %v sendReturn: %v exitCode: %v",
+		sendFlavor(m.static, m.gasLimit), m.to, m.method, m.value, m.params, m.sendReturn, m.exitCode)
 }
 
 type expectCreateActor struct {
 	// Expected parameters
 	codeId  cid.Cid
 	address addr.Address
+	// If non-nil, any address is accepted and written here.
+	capture *addr.Address
 }
 
 type expectVerifyConsensusFault struct {
@@ -193,11 +258,13 @@ func (rt *Runtime) ValidateImmediateCallerAcceptAny() {
 		rt.failTest("unexpected validate-caller-any")
 	}
 	rt.expectValidateCallerAny = false
+	rt.recordValidation(TranscriptValidation{Any: true})
 }
 
 func (rt *Runtime) ValidateImmediateCallerIs(addrs ...addr.Address) {
 	rt.requireInCall()
 	rt.checkArgument(len(addrs) > 0, "addrs must be non-empty")
+	rt.recordValidation(TranscriptValidation{Addrs: addrs})
 	// Check and clear expectations.
 	if len(rt.expectValidateCallerAddr) == 0 {
 		rt.failTest("unexpected validate caller addrs")
@@ -223,6 +290,7 @@ func (rt *Runtime) ValidateImmediateCallerIs(addrs ...addr.Address) {
 func (rt *Runtime) ValidateImmediateCallerType(types ...cid.Cid) {
 	rt.requireInCall()
 	rt.checkArgument(len(types) > 0, "types must be non-empty")
+	rt.recordValidation(TranscriptValidation{Types: types})
 
 	// Check and clear expectations.
 	if len(rt.expectValidateCallerType) == 0 {
@@ -319,33 +387,47 @@ func (rt *Runtime) GetRandomnessFromTickets(tag crypto.DomainSeparationTag, epoc
 }
 
 func (rt *Runtime) Send(toAddr addr.Address, methodNum abi.MethodNum, params cbor.Marshaler, value abi.TokenAmount, out cbor.Er) exitcode.ExitCode {
+	return rt.send(toAddr, methodNum, params, value, false, 0, out)
+}
+
+func (rt *Runtime) SendWithGasLimit(toAddr addr.Address, methodNum abi.MethodNum, params cbor.Marshaler, value abi.TokenAmount, gasLimit int64, out cbor.Er) exitcode.ExitCode {
+	rt.requireInCall()
+	if gasLimit <= 0 {
+		rt.Abortf(exitcode.SysErrorIllegalArgument, "gas limit %d must be positive", gasLimit)
+	}
+	return rt.send(toAddr, methodNum, params, value, false, gasLimit, out)
+}
+
+func (rt *Runtime) StaticSend(toAddr addr.Address, methodNum abi.MethodNum, params cbor.Marshaler, out cbor.Er) exitcode.ExitCode {
+	return rt.send(toAddr, methodNum, params, big.Zero(), true, 0, out)
+}
+
+func (rt *Runtime) send(toAddr addr.Address, methodNum abi.MethodNum, params cbor.Marshaler, value abi.TokenAmount, static bool, gasLimit int64, out cbor.Er) exitcode.ExitCode {
 	rt.requireInCall()
 	if rt.inTransaction {
 		rt.Abortf(exitcode.SysErrorIllegalActor, "side-effect within transaction")
 	}
+	sent := &TranscriptSend{To: toAddr, Method: methodNum, Params: encodeCBOR(params), Value: value, Static: static, GasLimit: gasLimit}
+	rt.recordSend(sent)
 	if len(rt.expectSends) == 0 {
-		rt.failTestNow("unexpected send to: %v method: %v, value: %v, params: %v", toAddr, methodNum, value, params)
+		sent.ExitCode = exitcode.SysErrInvalidReceiver
+		// Record the send and fail it, so that Verify can report it alongside any other mismatches.
+		rt.failures = append(rt.failures, fmt.Sprintf("unexpected %s %s params: %s",
+			sendFlavor(static, gasLimit), rt.describeSend(toAddr, methodNum, value), cborToJSON(params)))
+		return exitcode.SysErrInvalidReceiver
 	}
 	exp := rt.expectSends[0]
 
 	if !exp.Equal(toAddr, methodNum, params, value) {
-		toName := "unknown"
-		toMeth := "unknown"
-		expToName := "unknown"
-		expToMeth := "unknown"
-		if code, ok := rt.GetActorCodeCID(toAddr); ok && builtin.IsBuiltinActor(code) {
-			toName = builtin.ActorNameByCode(code)
-			toMeth = getMethodName(code, methodNum)
-		}
-		if code, ok := rt.GetActorCodeCID(exp.to); ok && builtin.IsBuiltinActor(code) {
-			expToName = builtin.ActorNameByCode(code)
-			expToMeth = getMethodName(code, exp.method)
-		}
-
-		rt.failTestNow("unexpected send\n"+
-			"          to: %s (%s) method: %d (%s) value: %v params: %v\n"+
-			"Expected  to: %s (%s) method: %d (%s) value: %v params: %v",
-			toAddr, toName, methodNum, toMeth, value, params, exp.to, expToName, exp.method, expToMeth, exp.value, exp.params)
+		// Record the mismatch but consume the expectation, so the remainder of the call proceeds as scripted.
+		rt.failures = append(rt.failures, describeSendMismatch(
+			rt.describeSend(exp.to, exp.method, exp.value),
+			rt.describeSend(toAddr, methodNum, value),
+			exp.params, params,
+		))
+	} else if !exp.SameFlavor(static, gasLimit) {
+		rt.failures = append(rt.failures, fmt.Sprintf("unexpected %s %s, expected %s",
+			sendFlavor(static, gasLimit), rt.describeSend(toAddr, methodNum, value), sendFlavor(exp.static, exp.gasLimit)))
 	}
 
 	if value.GreaterThan(rt.balance) {
@@ -368,6 +450,8 @@ func (rt *Runtime) Send(toAddr addr.Address, methodNum abi.MethodNum, params cbo
 	if err != nil {
 		rt.failTestNow("error deserializing send return bytes to output param: %v", err)
 	}
+	sent.ExitCode = exp.exitCode
+	sent.Return = encodeCBOR(exp.sendReturn)
 
 	return exp.exitCode
 }
@@ -388,7 +472,13 @@ func (rt *Runtime) CreateActor(codeId cid.Cid, address addr.Address) {
 	}
 	exp := rt.expectCreateActor
 	if exp != nil {
-		if !exp.codeId.Equals(codeId) || exp.address != address {
+		if exp.capture != nil {
+			if !exp.codeId.Equals(codeId) {
+				rt.failTest("unexpected create actor, code: %s, address: %s; expected code: %s",
+					codeId, address, exp.codeId)
+			}
+			*exp.capture = address
+		} else if !exp.codeId.Equals(codeId) || exp.address != address {
 			rt.failTest("unexpected create actor, code: %s, address: %s; expected code: %s, address: %s",
 				codeId, address, exp.codeId, exp.address)
 		}
@@ -415,14 +505,32 @@ func (rt *Runtime) DeleteActor(addr addr.Address) {
 	rt.expectDeleteActor = nil
 }
 
+func (rt *Runtime) ReplaceActorCodes(mapping map[cid.Cid]cid.Cid) {
+	rt.requireInCall()
+	if rt.inTransaction {
+		rt.Abortf(exitcode.SysErrorIllegalActor, "side-effect within transaction")
+	}
+	if rt.expectReplaceActorCodes == nil {
+		rt.failTestNow("unexpected call to replace actor codes %v", mapping)
+	}
+	if !reflect.DeepEqual(rt.expectReplaceActorCodes, mapping) {
+		rt.failTestNow("unexpected actor code replacement %v, expected %v", mapping, rt.expectReplaceActorCodes)
+	}
+	rt.expectReplaceActorCodes = nil
+}
+
 func (rt *Runtime) TotalFilCircSupply() abi.TokenAmount {
 	return rt.circulatingSupply
 }
 
+func (rt *Runtime) BaseFee() abi.TokenAmount {
+	return rt.baseFee
+}
+
 func (rt *Runtime) Abortf(errExitCode exitcode.ExitCode, msg string, args ...interface{}) {
 	rt.requireInCall()
 	rt.t.Logf("Mock Runtime Abort ExitCode: %v Reason: %s", errExitCode, fmt.Sprintf(msg, args...))
-	panic(abort{errExitCode, fmt.Sprintf(msg, args...)})
+	panic(abort{errExitCode, fmt.Sprintf(msg, args...), abortCause(args)})
 }
 
 func (rt *Runtime) Context() context.Context {
@@ -458,23 +566,51 @@ func (rt *Runtime) get(c cid.Cid) ([]byte, bool) {
 			rt.Abortf(exitcode.ErrSerialization, "failed to parse identity cid %s: %s", c, err)
 		}
 		data = decoded.Digest
-	} else if stored, found := rt.store[c]; found {
-		data = stored
+	} else if rt.ipldStore != nil {
+		var raw cbg.Deferred
+		if err := rt.ipldStore.Get(rt.ctx, c, &raw); err != nil {
+			// A missing block is not found, as for the internal map, but any other failure fails the test.
+			if xerrors.Is(err, ipld.ErrNotFound) {
+				return nil, false
+			}
+			rt.failTestNow("failed to get block %s from injected store: %v", c, err)
+		}
+		data = raw.Raw
 	} else {
-		return nil, false
+		rt.storeLock.RLock()
+		stored, found := rt.store[c]
+		rt.storeLock.RUnlock()
+		if !found {
+			return nil, false
+		}
+		data = stored
 	}
 	return data, true
 }
 
 // Puts raw data into the state, but only if it's not "inlined" into the CID.
 func (rt *Runtime) put(c cid.Cid, data []byte) {
-	if c.Prefix().MhType != mh.IDENTITY {
-		rt.store[c] = data
+	if c.Prefix().MhType == mh.IDENTITY {
+		return
+	}
+	if rt.ipldStore != nil {
+		stored, err := rt.ipldStore.Put(rt.ctx, &cbg.Deferred{Raw: data})
+		if err != nil {
+			rt.failTestNow("failed to put block %s in injected store: %v", c, err)
+		}
+		if !stored.Equals(c) {
+			rt.failTestNow("injected store computed cid %s for block, expected %s", stored, c)
+		}
+		return
 	}
+	rt.storeLock.Lock()
+	rt.store[c] = data
+	rt.storeLock.Unlock()
 }
 
 func (rt *Runtime) StoreGet(c cid.Cid, o cbor.Unmarshaler) bool {
 	// requireInCall omitted because it makes using this mock runtime as a store awkward.
+	rt.storeGets++
 	data, found := rt.get(c)
 	if found {
 		err := o.UnmarshalCBOR(bytes.NewReader(data))
@@ -492,6 +628,7 @@ func (rt *Runtime) StorePut(o cbor.Marshaler) cid.Cid {
 	if err != nil {
 		rt.Abortf(exitcode.ErrSerialization, err.Error())
 	}
+	rt.storePuts++
 	rt.put(key, data)
 	return key
 }
@@ -557,7 +694,11 @@ func (rt *Runtime) VerifySignature(sig crypto.Signature, signer addr.Address, pl
 
 	exp := rt.expectVerifySigs[0]
 	if exp != nil {
-		if !exp.sig.Equals(&sig) || exp.signer != signer || !bytes.Equal(exp.plaintext, plaintext) {
+		sigMatches := exp.sig.Equals(&sig)
+		if exp.anyData {
+			sigMatches = exp.sig.Type == sig.Type
+		}
+		if !sigMatches || exp.signer != signer || !bytes.Equal(exp.plaintext, plaintext) {
 			rt.failTest("unexpected signature verification\n"+
 				"         sig: %v, signer: %s, plaintext: %v\n"+
 				"expected sig: %v, signer: %s, plaintext: %v",
@@ -576,6 +717,28 @@ func (rt *Runtime) HashBlake2b(data []byte) [32]byte {
 	return rt.hashfunc(data)
 }
 
+// Computes the CID with the same BLAKE2b-256 derivation as abi.CidBuilder, whatever the runtime's hasher.
+// CIDs computed by an actor thus match those computed outside a runtime, e.g. by DealProposal.Cid when
+// checking state invariants.
+// Once a computation has been expected, each call must match the next expectation, and returns its result.
+func (rt *Runtime) ComputeCID(codec uint64, data []byte) (cid.Cid, error) {
+	if !rt.checkComputeCIDs {
+		return cid.V1Builder{Codec: codec, MhType: mh.BLAKE2B_MIN + 31}.Sum(data)
+	}
+	if len(rt.expectComputeCIDs) == 0 {
+		rt.failTestNow("unexpected syscall to ComputeCID codec: %d, data: %x", codec, data)
+	}
+	exp := rt.expectComputeCIDs[0]
+	rt.expectComputeCIDs = rt.expectComputeCIDs[1:]
+	if exp.codec != codec || !bytes.Equal(exp.data, data) {
+		rt.failTest("unexpected ComputeCID\n"+
+			"         codec: %d, data: %x\n"+
+			"expected codec: %d, data: %x",
+			codec, data, exp.codec, exp.data)
+	}
+	return exp.cid, exp.resultErr
+}
+
 func (rt *Runtime) ComputeUnsealedSectorCID(reg abi.RegisteredSealProof, pieces []abi.PieceInfo) (cid.Cid, error) {
 	exp := rt.expectComputeUnsealedSectorCID
 	if exp != nil {
@@ -678,6 +841,42 @@ func (rt *Runtime) VerifyPoSt(vi proof.WindowPoStVerifyInfo) error {
 	return nil
 }
 
+func (rt *Runtime) VerifyAggregateSeals(aggregate proof.AggregateSealVerifyProofAndInfos) error {
+	exp := rt.expectAggregateVerifySeals
+	if exp != nil {
+		if !reflect.DeepEqual(exp.in, aggregate) {
+			rt.failTest("unexpected aggregate seal verification\n"+
+				"        : %v\n"+
+				"expected: %v",
+				aggregate, exp.in)
+		}
+		defer func() {
+			rt.expectAggregateVerifySeals = nil
+		}()
+		return exp.err
+	}
+	rt.failTestNow("unexpected syscall to verify aggregate seals %v", aggregate)
+	return nil
+}
+
+func (rt *Runtime) VerifyReplicaUpdate(update proof.ReplicaUpdateInfo) error {
+	exp := rt.expectVerifyReplicaUpdate
+	if exp != nil {
+		if !reflect.DeepEqual(exp.update, update) {
+			rt.failTest("unexpected replica update verification\n"+
+				"        : %v\n"+
+				"expected: %v",
+				update, exp.update)
+		}
+		defer func() {
+			rt.expectVerifyReplicaUpdate = nil
+		}()
+		return exp.result
+	}
+	rt.failTestNow("unexpected syscall to verify replica update %v", update)
+	return nil
+}
+
 func (rt *Runtime) VerifyConsensusFault(h1, h2, extra []byte) (*runtime.ConsensusFault, error) {
 	if rt.expectVerifyConsensusFault == nil {
 		rt.failTestNow("Unexpected syscall VerifyConsensusFault")
@@ -706,6 +905,24 @@ func (rt *Runtime) Log(level rt.LogLevel, msg string, args ...interface{}) {
 	rt.logs = append(rt.logs, fmt.Sprintf(msg, args...))
 }
 
+func (rt *Runtime) EmitEvent(entries []runtime.EventEntry) {
+	rt.requireInCall()
+	rt.events = append(rt.events, entries)
+	if !rt.checkEvents {
+		return
+	}
+	if len(rt.expectEvents) == 0 {
+		rt.failures = append(rt.failures, fmt.Sprintf("unexpected event %s", describeEvent(entries)))
+		return
+	}
+	exp := rt.expectEvents[0]
+	rt.expectEvents = rt.expectEvents[1:]
+	if !reflect.DeepEqual(exp, entries) {
+		rt.failures = append(rt.failures, fmt.Sprintf("event mismatch:\nexpected: %s\nactual:   %s",
+			describeEvent(exp), describeEvent(entries)))
+	}
+}
+
 ///// Trace span implementation /////
 
 type TraceSpan struct {
@@ -718,6 +935,8 @@ func (t TraceSpan) End() {
 type abort struct {
 	code exitcode.ExitCode
 	msg  string
+	// The error formatted into the message, if any.
+	err error
 }
 
 func (a abort) String() string {
@@ -789,6 +1008,10 @@ func (rt *Runtime) SetCirculatingSupply(amt abi.TokenAmount) {
 	rt.circulatingSupply = amt
 }
 
+func (rt *Runtime) SetBaseFee(fee abi.TokenAmount) {
+	rt.baseFee = fee
+}
+
 func (rt *Runtime) AddIDAddress(src addr.Address, target addr.Address) {
 	rt.require(target.Protocol() == addr.ID, "target must use ID address protocol")
 	rt.idAddresses[src] = target
@@ -846,6 +1069,18 @@ func (rt *Runtime) ExpectSend(toAddr addr.Address, methodNum abi.MethodNum, para
 	})
 }
 
+// Expects a call to SendWithGasLimit with the given gas limit.
+func (rt *Runtime) ExpectSendWithGasLimit(toAddr addr.Address, methodNum abi.MethodNum, params cbor.Marshaler, value abi.TokenAmount, gasLimit int64, ret cbor.Er, exitCode exitcode.ExitCode) {
+	rt.ExpectSend(toAddr, methodNum, params, value, ret, exitCode)
+	rt.expectSends[len(rt.expectSends)-1].gasLimit = gasLimit
+}
+
+// Expects a call to StaticSend.
+func (rt *Runtime) ExpectStaticSend(toAddr addr.Address, methodNum abi.MethodNum, params cbor.Marshaler, ret cbor.Er, exitCode exitcode.ExitCode) {
+	rt.ExpectSend(toAddr, methodNum, params, big.Zero(), ret, exitCode)
+	rt.expectSends[len(rt.expectSends)-1].static = true
+}
+
 func (rt *Runtime) ExpectVerifySignature(sig crypto.Signature, signer addr.Address, plaintext []byte, result error) {
 	rt.expectVerifySigs = append(rt.expectVerifySigs, &expectVerifySig{
 		sig:       sig,
@@ -855,6 +1090,34 @@ func (rt *Runtime) ExpectVerifySignature(sig crypto.Signature, signer addr.Addre
 	})
 }
 
+// Expects a signature verification like ExpectVerifySignature, but matching any signature of a type.
+// The type must be registered with builtin.RegisterSignatureScheme.
+func (rt *Runtime) ExpectVerifySignatureOfType(sigType crypto.SigType, signer addr.Address, plaintext []byte, result error) {
+	if _, ok := builtin.SignatureSchemeOf(sigType); !ok {
+		rt.failTestNow("no signature scheme registered for signature type %d", sigType)
+	}
+	rt.expectVerifySigs = append(rt.expectVerifySigs, &expectVerifySig{
+		sig:       crypto.Signature{Type: sigType},
+		anyData:   true,
+		signer:    signer,
+		plaintext: plaintext,
+		result:    result,
+	})
+}
+
+// Expects a call to emit an event with exactly the specified entries.
+// Once any event is expected, every event emitted up to the next Verify or Reset must match an expectation, in order.
+// Events emitted without any expectation having been set are recorded but not checked.
+func (rt *Runtime) ExpectEmittedEvent(entries []runtime.EventEntry) {
+	rt.expectEvents = append(rt.expectEvents, entries)
+	rt.checkEvents = true
+}
+
+// Returns the events emitted since the last reset, in order.
+func (rt *Runtime) EmittedEvents() [][]runtime.EventEntry {
+	return rt.events
+}
+
 func (rt *Runtime) ExpectCreateActor(codeId cid.Cid, address addr.Address) {
 	rt.expectCreateActor = &expectCreateActor{
 		codeId:  codeId,
@@ -862,10 +1125,37 @@ func (rt *Runtime) ExpectCreateActor(codeId cid.Cid, address addr.Address) {
 	}
 }
 
+// Expects the creation of an actor with the given code at any address, and records the address at which
+// the actor is created in capture, for assertions once the call returns.
+func (rt *Runtime) ExpectCreateActorCapture(codeId cid.Cid, capture *addr.Address) {
+	rt.expectCreateActor = &expectCreateActor{
+		codeId:  codeId,
+		capture: capture,
+	}
+}
+
 func (rt *Runtime) ExpectDeleteActor(beneficiary addr.Address) {
 	rt.expectDeleteActor = &beneficiary
 }
 
+func (rt *Runtime) ExpectReplaceActorCodes(mapping map[cid.Cid]cid.Cid) {
+	rt.expectReplaceActorCodes = mapping
+}
+
+// Expects the next call to read and write at most the specified number of blocks through the store.
+// The limits are checked when that call returns, and any excess is reported by Verify.
+func (rt *Runtime) ExpectStoreOps(maxGets, maxPuts int) {
+	rt.expectStoreOps = &expectStoreOps{
+		maxGets: maxGets,
+		maxPuts: maxPuts,
+	}
+}
+
+// Returns the number of blocks read and written through the store since the most recent call began.
+func (rt *Runtime) StoreOps() (gets, puts int) {
+	return rt.storeGets, rt.storePuts
+}
+
 func (rt *Runtime) SetHasher(f func(data []byte) [32]byte) {
 	rt.hashfunc = f
 }
@@ -883,6 +1173,25 @@ func (rt *Runtime) ExpectComputeUnsealedSectorCID(reg abi.RegisteredSealProof, p
 	}
 }
 
+// Expects a call to ComputeCID with the given codec and data, returning the given CID and error.
+// Once any computation is expected, every computation up to the next Verify or Reset must match an expectation, in order.
+func (rt *Runtime) ExpectComputeCID(codec uint64, data []byte, c cid.Cid, err error) {
+	rt.expectComputeCIDs = append(rt.expectComputeCIDs, &expectComputeCID{
+		codec:     codec,
+		data:      data,
+		cid:       c,
+		resultErr: err,
+	})
+	rt.checkComputeCIDs = true
+}
+
+func (rt *Runtime) ExpectAggregateVerifySeals(aggregate proof.AggregateSealVerifyProofAndInfos, err error) {
+	rt.expectAggregateVerifySeals = &expectAggregateVerifySeals{
+		in:  aggregate,
+		err: err,
+	}
+}
+
 func (rt *Runtime) ExpectVerifyPoSt(post proof.WindowPoStVerifyInfo, result error) {
 	rt.expectVerifyPoSt = &expectVerifyPoSt{
 		post:   post,
@@ -890,6 +1199,13 @@ func (rt *Runtime) ExpectVerifyPoSt(post proof.WindowPoStVerifyInfo, result erro
 	}
 }
 
+func (rt *Runtime) ExpectVerifyReplicaUpdate(update proof.ReplicaUpdateInfo, result error) {
+	rt.expectVerifyReplicaUpdate = &expectVerifyReplicaUpdate{
+		update: update,
+		result: result,
+	}
+}
+
 func (rt *Runtime) ExpectVerifyConsensusFault(h1, h2, extra []byte, result *runtime.ConsensusFault, resultErr error) {
 	rt.expectVerifyConsensusFault = &expectVerifyConsensusFault{
 		requireCorrectInput: true,
@@ -904,60 +1220,22 @@ func (rt *Runtime) ExpectVerifyConsensusFault(h1, h2, extra []byte, result *runt
 // Verifies that expected calls were received, and resets all expectations.
 func (rt *Runtime) Verify() {
 	rt.t.Helper()
-	if rt.expectValidateCallerAny {
-		rt.failTest("expected ValidateCallerAny, not received")
-	}
-	if len(rt.expectValidateCallerAddr) > 0 {
-		rt.failTest("missing expected ValidateCallerAddr %v", rt.expectValidateCallerAddr)
-	}
-	if len(rt.expectValidateCallerType) > 0 {
-		rt.failTest("missing expected ValidateCallerType %v", rt.expectValidateCallerType)
-	}
-	if len(rt.expectRandomnessBeacon) > 0 {
-		rt.failTest("missing expected beacon randomness %v", rt.expectRandomnessBeacon)
-	}
-	if len(rt.expectRandomnessTickets) > 0 {
-		rt.failTest("missing expected ticket randomness %v", rt.expectRandomnessTickets)
-	}
-	if len(rt.expectSends) > 0 {
-		rt.failTest("missing expected send %v", rt.expectSends)
-	}
-	if len(rt.expectVerifySigs) > 0 {
-		rt.failTest("missing expected verify signature %v", rt.expectVerifySigs)
-	}
-	if rt.expectCreateActor != nil {
-		rt.failTest("missing expected create actor with code %s, address %s",
-			rt.expectCreateActor.codeId, rt.expectCreateActor.address)
-	}
-
-	if rt.expectVerifySeal != nil {
-		rt.failTest("missing expected verify seal with %v", rt.expectVerifySeal.seal)
-	}
-
-	if rt.expectBatchVerifySeals != nil {
-		rt.failTest("missing expected batch verify seals with %v", rt.expectBatchVerifySeals)
-	}
-
-	if rt.expectComputeUnsealedSectorCID != nil {
-		rt.failTest("missing expected ComputeUnsealedSectorCID with %v", rt.expectComputeUnsealedSectorCID)
-	}
-
-	if rt.expectVerifyPoSt != nil {
-		rt.failTest("missing expected PoSt verification with %v", rt.expectVerifyPoSt)
-	}
-
-	if rt.expectVerifyConsensusFault != nil {
-		rt.failTest("missing expected verify consensus fault")
-	}
-	if rt.expectDeleteActor != nil {
-		rt.failTest("missing expected delete actor with address %s", rt.expectDeleteActor.String())
+	problems := append(rt.failures, rt.unmetExpectations()...)
+	rt.failures = nil
+	if len(problems) > 0 {
+		rt.failTest("%s", formatReport("unmet expectations", problems))
 	}
 
 	rt.Reset()
 }
 
-// Resets expectations
+// Resets expectations. Any failures recorded since the last verification fail the test.
 func (rt *Runtime) Reset() {
+	rt.t.Helper()
+	if len(rt.failures) > 0 {
+		rt.failTest("%s", formatReport("failed expectations", rt.failures))
+		rt.failures = nil
+	}
 	rt.expectValidateCallerAny = false
 	rt.expectValidateCallerAddr = nil
 	rt.expectValidateCallerType = nil
@@ -965,21 +1243,138 @@ func (rt *Runtime) Reset() {
 	rt.expectRandomnessTickets = nil
 	rt.expectSends = nil
 	rt.expectCreateActor = nil
+	rt.expectReplaceActorCodes = nil
 	rt.expectVerifySigs = nil
 	rt.expectVerifySeal = nil
 	rt.expectBatchVerifySeals = nil
+	rt.expectAggregateVerifySeals = nil
 	rt.expectComputeUnsealedSectorCID = nil
+	rt.expectComputeCIDs = nil
+	rt.checkComputeCIDs = false
+	rt.expectStoreOps = nil
+	rt.expectEvents = nil
+	rt.checkEvents = false
+	rt.events = nil
+}
+
+// Fails the test with any failures recorded since the runtime was last verified or reset.
+// Registered as a cleanup of the test, so that a mismatched or unexpected send, which the actor may treat
+// as a recoverable failure, is reported even by a test that never verifies the runtime.
+func (rt *Runtime) failPendingFailures() {
+	if len(rt.failures) > 0 {
+		rt.failTest("%s", formatReport("failed expectations", rt.failures))
+		rt.failures = nil
+	}
+}
+
+// Clone returns an independent copy of the runtime for use by another test, such as a parallel subtest.
+// The copy has the same execution context, actor state, stored blocks, and pending expectations, but shares
+// no mutable data with the original, so either may be used while the other is in use concurrently.
+// A store injected with WithStore is shared rather than copied, so must itself be safe for concurrent use.
+func (rt *Runtime) Clone(t testing.TB) *Runtime {
+	if rt.inCall {
+		rt.failTestNow("cannot clone a runtime during a call")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	c := new(Runtime)
+	t.Cleanup(c.failPendingFailures)
+	*c = *rt
+	c.ctx = ctx
+	c.t = t
+	c.storeLock = new(sync.RWMutex)
+	// A clone doesn't record to the original's transcript.
+	c.transcript = nil
+	c.transcriptCall = nil
+
+	rt.storeLock.RLock()
+	c.store = make(map[cid.Cid][]byte, len(rt.store))
+	for k, v := range rt.store { //nolint:nomaprange
+		c.store[k] = v // Block data is never mutated, so needn't be copied.
+	}
+	rt.storeLock.RUnlock()
+
+	c.idAddresses = make(map[addr.Address]addr.Address, len(rt.idAddresses))
+	for k, v := range rt.idAddresses { //nolint:nomaprange
+		c.idAddresses[k] = v
+	}
+	c.actorCodeCIDs = make(map[addr.Address]cid.Cid, len(rt.actorCodeCIDs))
+	for k, v := range rt.actorCodeCIDs { //nolint:nomaprange
+		c.actorCodeCIDs[k] = v
+	}
+	c.stateUsedObjs = make(map[cbor.Marshaler]cid.Cid)
+	if rt.expectReplaceActorCodes != nil {
+		c.expectReplaceActorCodes = make(map[cid.Cid]cid.Cid, len(rt.expectReplaceActorCodes))
+		for k, v := range rt.expectReplaceActorCodes { //nolint:nomaprange
+			c.expectReplaceActorCodes[k] = v
+		}
+	}
+
+	// Expectations are consumed by removal from these slices, so each clone needs its own.
+	// The expectations themselves are not modified.
+	c.expectValidateCallerAddr = append([]addr.Address(nil), rt.expectValidateCallerAddr...)
+	c.expectValidateCallerType = append([]cid.Cid(nil), rt.expectValidateCallerType...)
+	c.expectRandomnessBeacon = append([]*expectRandomness(nil), rt.expectRandomnessBeacon...)
+	c.expectRandomnessTickets = append([]*expectRandomness(nil), rt.expectRandomnessTickets...)
+	c.expectSends = append([]*expectedMessage(nil), rt.expectSends...)
+	c.expectVerifySigs = append([]*expectVerifySig(nil), rt.expectVerifySigs...)
+	c.expectEvents = append([][]runtime.EventEntry(nil), rt.expectEvents...)
+	c.expectComputeCIDs = append([]*expectComputeCID(nil), rt.expectComputeCIDs...)
+	c.failures = append([]string(nil), rt.failures...)
+	c.logs = append([]string(nil), rt.logs...)
+	c.events = append([][]runtime.EventEntry(nil), rt.events...)
+	return c
 }
 
 // Calls f() expecting it to invoke Runtime.Abortf() with a specified exit code.
 func (rt *Runtime) ExpectAbort(expected exitcode.ExitCode, f func()) {
-	rt.ExpectAbortContainsMessage(expected, "", f)
+	rt.t.Helper()
+	rt.expectAbort(expected, f, nil)
 }
 
 // Calls f() expecting it to invoke Runtime.Abortf() with a specified exit code and message.
 func (rt *Runtime) ExpectAbortContainsMessage(expected exitcode.ExitCode, substr string, f func()) {
+	rt.t.Helper()
+	rt.expectAbort(expected, f, func(a *Abort) {
+		if substr != "" && !strings.Contains(a.Message, substr) {
+			rt.failTest("abort expected message\n'%s'\nto contain\n'%s'\n", a.Message, substr)
+		}
+	})
+}
+
+// Calls f() expecting it to invoke Runtime.Abortf() with a specified exit code and exactly a specified message.
+func (rt *Runtime) ExpectAbortWithMessage(expected exitcode.ExitCode, msg string, f func()) {
+	rt.t.Helper()
+	rt.expectAbort(expected, f, func(a *Abort) {
+		if a.Message != msg {
+			rt.failTest("abort expected message\n'%s'\nto be\n'%s'\n", a.Message, msg)
+		}
+	})
+}
+
+// Calls f() expecting it to invoke Runtime.Abortf() with a specified exit code, formatting into the message
+// an error which wraps (or is) a target error, as with errors.Is.
+func (rt *Runtime) ExpectAbortWithError(expected exitcode.ExitCode, target error, f func()) {
+	rt.t.Helper()
+	rt.expectAbort(expected, f, func(a *Abort) {
+		if !xerrors.Is(a.Err, target) {
+			rt.failTest("abort expected error wrapping '%v', got '%s'", target, a.Message)
+		}
+	})
+}
+
+// Returns the abort most recently caught by ExpectAbort or one of its variants, or nil if there was none.
+// The abort remains available after verification, for assertions on the reason for an expected failure.
+func (rt *Runtime) LastAbort() *Abort {
+	return rt.lastAbort
+}
+
+// Calls f() expecting an abort with a specified exit code, which is then recorded and passed to check, if not nil.
+func (rt *Runtime) expectAbort(expected exitcode.ExitCode, f func(), check func(a *Abort)) {
 	rt.t.Helper()
 	prevState := rt.state
+	prevEvents := len(rt.events)
 
 	defer func() {
 		rt.t.Helper()
@@ -992,16 +1387,16 @@ func (rt *Runtime) ExpectAbortContainsMessage(expected exitcode.ExitCode, substr
 		if !ok {
 			panic(r)
 		}
+		rt.lastAbort = newAbort(a)
 		if a.code != expected {
 			rt.failTest("abort expected code %v, got %v %s", expected, a.code, a.msg)
 		}
-		if substr != "" {
-			if !strings.Contains(a.msg, substr) {
-				rt.failTest("abort expected message\n'%s'\nto contain\n'%s'\n", a.msg, substr)
-			}
+		if check != nil {
+			check(rt.lastAbort)
 		}
-		// Roll back state change.
+		// Roll back state change, and discard events emitted by the aborted call.
 		rt.state = prevState
+		rt.events = rt.events[:prevEvents]
 	}()
 	f()
 }
@@ -1034,9 +1429,33 @@ func (rt *Runtime) Call(method interface{}, params interface{}) interface{} {
 
 	rt.inCall = true
 	rt.stateUsedObjs = map[cbor.Marshaler]cid.Cid{}
+	rt.storeGets = 0
+	rt.storePuts = 0
+	rt.beginTranscriptCall(meth, params)
+	storeOps := rt.expectStoreOps
+	rt.expectStoreOps = nil
 	defer func() {
 		rt.inCall = false
 		rt.stateUsedObjs = nil
+		if storeOps != nil {
+			if rt.storeGets > storeOps.maxGets {
+				rt.failures = append(rt.failures, fmt.Sprintf("expected at most %d store gets, got %d", storeOps.maxGets, rt.storeGets))
+			}
+			if rt.storePuts > storeOps.maxPuts {
+				rt.failures = append(rt.failures, fmt.Sprintf("expected at most %d store puts, got %d", storeOps.maxPuts, rt.storePuts))
+			}
+		}
+	}()
+	defer func() {
+		// An abort escaping the call may have been caused by an unexpected send, which would otherwise
+		// only be reported on verification. Log them before propagating the panic.
+		if r := recover(); r != nil {
+			rt.endTranscriptCall(nil, r)
+			if len(rt.failures) > 0 {
+				rt.t.Logf("%s", formatReport("failed expectations", rt.failures))
+			}
+			panic(r)
+		}
 	}()
 	var arg reflect.Value
 	if params != nil {
@@ -1044,8 +1463,15 @@ func (rt *Runtime) Call(method interface{}, params interface{}) interface{} {
 	} else {
 		arg = reflect.ValueOf(abi.Empty)
 	}
+	// Check parameters of builtin actor methods as the VM does before dispatch.
+	if actor, num, ok := lookupBuiltinMethod(meth); ok {
+		if err := builtin.ValidateParams(actor, num, arg.Interface()); err != nil {
+			rt.Abortf(exitcode.Unwrap(err, exitcode.ErrIllegalArgument), "invalid parameters: %s", err)
+		}
+	}
 	ret := meth.Call([]reflect.Value{reflect.ValueOf(rt), arg})
 	rt.checkStateObjectsUnmodified()
+	rt.endTranscriptCall(ret[0].Interface(), nil)
 	return ret[0].Interface()
 }
 
@@ -1091,13 +1517,22 @@ func (rt *Runtime) require(predicate bool, msg string, args ...interface{}) {
 func (rt *Runtime) failTest(msg string, args ...interface{}) {
 	rt.t.Helper()
 	rt.t.Logf(msg, args...)
+	rt.logTranscript()
 	rt.t.Logf("%s", debug.Stack())
 	rt.t.Fail()
 }
 
+// Fails the test immediately, also reporting any expectations that remain unmet at the point of failure.
 func (rt *Runtime) failTestNow(msg string, args ...interface{}) {
 	rt.t.Helper()
 	rt.t.Logf(msg, args...)
+	if len(rt.failures) > 0 {
+		rt.t.Logf("%s", formatReport("failed expectations", rt.failures))
+	}
+	if unmet := rt.unmetExpectations(); len(unmet) > 0 {
+		rt.t.Logf("%s", formatReport("unmet expectations", unmet))
+	}
+	rt.logTranscript()
 	rt.t.Logf("%s", debug.Stack())
 	rt.t.FailNow()
 }
@@ -1106,6 +1541,28 @@ func (rt *Runtime) ChargeGas(_ string, gas, _ int64) {
 	rt.gasCharged += gas
 }
 
+// Describes a send's recipient, method and value, naming the actor and method where the recipient is a built-in actor.
+func (rt *Runtime) describeSend(to addr.Address, method abi.MethodNum, value abi.TokenAmount) string {
+	toName := "unknown"
+	methName := "unknown"
+	if code, ok := rt.GetActorCodeCID(to); ok && builtin.IsBuiltinActor(code) {
+		toName = builtin.ActorNameByCode(code)
+		methName = getMethodName(code, method)
+	}
+	return fmt.Sprintf("to: %s (%s) method: %d (%s) value: %v", to, toName, method, methName, value)
+}
+
+// Names the flavor of a send, for failure reports.
+func sendFlavor(static bool, gasLimit int64) string {
+	if static {
+		return "static send"
+	}
+	if gasLimit != 0 {
+		return fmt.Sprintf("send with gas limit %d", gasLimit)
+	}
+	return "send"
+}
+
 func getMethodName(code cid.Cid, num abi.MethodNum) string {
 	for _, actor := range exported.BuiltinActors() {
 		if actor.Code().Equals(code) {
@@ -1117,12 +1574,29 @@ func getMethodName(code cid.Cid, num abi.MethodNum) string {
 			if meth == nil {
 				return "<invalid>"
 			}
-			name := goruntime.FuncForPC(reflect.ValueOf(meth).Pointer()).Name()
-			name = strings.TrimSuffix(name, "-fm")
-			lastDot := strings.LastIndexByte(name, '.')
-			name = name[lastDot+1:]
-			return name
+			return methodName(meth)
 		}
 	}
 	return "<unknown actor>"
 }
+
+// Finds the builtin actor exporting a method, and the method's number.
+func lookupBuiltinMethod(meth reflect.Value) (runtime.VMActor, abi.MethodNum, bool) {
+	name := goruntime.FuncForPC(meth.Pointer()).Name()
+	for _, actor := range exported.BuiltinActors() {
+		for num, m := range actor.Exports() {
+			if m != nil && goruntime.FuncForPC(reflect.ValueOf(m).Pointer()).Name() == name {
+				return actor, abi.MethodNum(num), true
+			}
+		}
+	}
+	return nil, 0, false
+}
+
+// Returns the name of an actor method, e.g. "PublishStorageDeals".
+func methodName(meth interface{}) string {
+	name := goruntime.FuncForPC(reflect.ValueOf(meth).Pointer()).Name()
+	name = strings.TrimSuffix(name, "-fm")
+	lastDot := strings.LastIndexByte(name, '.')
+	return name[lastDot+1:]
+}
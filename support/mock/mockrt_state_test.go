@@ -1,18 +1,28 @@
 package mock
 
 import (
+	"context"
+	"fmt"
 	"io"
+	goruntime "runtime"
+	"strings"
 	"testing"
 
+	addr "github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/cbor"
+	"github.com/filecoin-project/go-state-types/crypto"
 	"github.com/filecoin-project/go-state-types/exitcode"
+	block "github.com/ipfs/go-block-format"
 	"github.com/ipfs/go-cid"
 	mh "github.com/multiformats/go-multihash"
+	"github.com/stretchr/testify/require"
 	cbg "github.com/whyrusleeping/cbor-gen"
 
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
 	"github.com/filecoin-project/specs-actors/v3/actors/runtime"
+	"github.com/filecoin-project/specs-actors/v3/actors/util/adt"
+	"github.com/filecoin-project/specs-actors/v3/support/ipld"
 	tutil "github.com/filecoin-project/specs-actors/v3/support/testing"
 )
 
@@ -24,6 +34,10 @@ func (a FakeActor) Exports() []interface{} {
 		2: a.ReadOnlyState,
 		3: a.TransactionState,
 		4: a.TransactionStateTwice,
+		5: a.NestedTransaction,
+		6: a.EmitValue,
+		7: a.CreateChild,
+		8: a.Fail,
 	}
 }
 
@@ -109,6 +123,18 @@ func (a FakeActor) TransactionStateTwice(rt runtime.Runtime, mutate *cbg.CborBoo
 	return nil
 }
 
+func (a FakeActor) NestedTransaction(rt runtime.Runtime, _ *abi.EmptyValue) *abi.EmptyValue {
+	rt.ValidateImmediateCallerAcceptAny()
+	var st State
+	rt.StateTransaction(&st, func() {
+		var inner State
+		rt.StateTransaction(&inner, func() {
+			panic("Can't get here")
+		})
+	})
+	return nil
+}
+
 func TestIllegalStateModifications(t *testing.T) {
 	actor := FakeActor{}
 	receiver := tutil.NewIDAddr(t, 100)
@@ -172,4 +198,405 @@ func TestIllegalStateModifications(t *testing.T) {
 			rt.Call(actor.TransactionStateTwice, &mutate)
 		})
 	})
+
+	t.Run("nested transaction forbidden", func(t *testing.T) {
+		rt := builder.Build(t)
+		mutate := cbg.CborBool(false)
+		rt.Call(actor.Constructor, &mutate)
+
+		rt.ExpectValidateCallerAny()
+		rt.ExpectAbortContainsMessage(exitcode.SysErrorIllegalActor, "nested transaction", func() {
+			rt.Call(actor.NestedTransaction, nil)
+		})
+	})
+}
+
+// Emits an event carrying the parameter, aborting afterwards if it is negative.
+func (a FakeActor) EmitValue(rt runtime.Runtime, value *cbg.CborInt) *abi.EmptyValue {
+	rt.ValidateImmediateCallerAcceptAny()
+	builtin.NewEventBuilder("fake").Field("value", value).Emit(rt)
+	if *value < 0 {
+		rt.Abortf(exitcode.ErrIllegalArgument, "negative value")
+	}
+	return nil
+}
+
+// Creates an actor with the same code at the given ID, then deletes the receiver in favour of the caller.
+func (a FakeActor) CreateChild(rt runtime.Runtime, id *cbg.CborInt) *abi.EmptyValue {
+	rt.ValidateImmediateCallerAcceptAny()
+	child, err := addr.NewIDAddress(uint64(*id))
+	builtin.RequireNoErr(rt, err, exitcode.ErrIllegalArgument, "invalid id")
+	rt.CreateActor(a.Code(), child)
+	rt.DeleteActor(rt.Caller())
+	return nil
+}
+
+func TestCreateAndDeleteActor(t *testing.T) {
+	actor := FakeActor{}
+	receiver := tutil.NewIDAddr(t, 100)
+	caller := tutil.NewIDAddr(t, 101)
+	id := cbg.CborInt(1234)
+
+	t.Run("expected address", func(t *testing.T) {
+		rt := NewBuilder(receiver).WithCaller(caller, builtin.AccountActorCodeID).Build(t)
+		rt.ExpectValidateCallerAny()
+		rt.ExpectCreateActor(actor.Code(), tutil.NewIDAddr(t, 1234))
+		rt.ExpectDeleteActor(caller)
+		rt.Call(actor.CreateChild, &id)
+		rt.Verify()
+	})
+
+	t.Run("captured address", func(t *testing.T) {
+		rt := NewBuilder(receiver).WithCaller(caller, builtin.AccountActorCodeID).Build(t)
+		var created addr.Address
+		rt.ExpectValidateCallerAny()
+		rt.ExpectCreateActorCapture(actor.Code(), &created)
+		rt.ExpectDeleteActor(caller)
+		rt.Call(actor.CreateChild, &id)
+		rt.Verify()
+		require.Equal(t, tutil.NewIDAddr(t, 1234), created)
+	})
+
+	t.Run("missing creation fails verification", func(t *testing.T) {
+		rt := NewBuilder(receiver).Build(t)
+		rec := &recordingTB{TB: t}
+		rt.t = rec
+		var created addr.Address
+		rt.ExpectCreateActorCapture(actor.Code(), &created)
+		rt.ExpectDeleteActor(caller)
+		rt.Verify()
+		require.True(t, rec.failed)
+		logs := strings.Join(rec.logs, "\n")
+		require.Contains(t, logs, "missing expected create actor")
+		require.Contains(t, logs, "missing expected delete actor")
+	})
+}
+
+func TestEmitEvent(t *testing.T) {
+	actor := FakeActor{}
+	receiver := tutil.NewIDAddr(t, 100)
+	fakeEvent := func(value int64) []runtime.EventEntry {
+		v := cbg.CborInt(value)
+		entries, err := builtin.NewEventBuilder("fake").Field("value", &v).Entries()
+		require.NoError(t, err)
+		return entries
+	}
+
+	t.Run("events are recorded without expectations", func(t *testing.T) {
+		rt := NewBuilder(receiver).Build(t)
+		rt.ExpectValidateCallerAny()
+		value := cbg.CborInt(1)
+		rt.Call(actor.EmitValue, &value)
+		require.Equal(t, [][]runtime.EventEntry{fakeEvent(1)}, rt.EmittedEvents())
+		rt.Verify()
+		require.Empty(t, rt.EmittedEvents())
+	})
+
+	t.Run("expected event", func(t *testing.T) {
+		rt := NewBuilder(receiver).Build(t)
+		rt.ExpectValidateCallerAny()
+		rt.ExpectEmittedEvent(fakeEvent(1))
+		value := cbg.CborInt(1)
+		rt.Call(actor.EmitValue, &value)
+		rt.Verify()
+	})
+
+	t.Run("mismatched event fails verification", func(t *testing.T) {
+		rt := NewBuilder(receiver).Build(t)
+		rec := &recordingTB{TB: t}
+		rt.t = rec
+		rt.ExpectValidateCallerAny()
+		rt.ExpectEmittedEvent(fakeEvent(2))
+		value := cbg.CborInt(1)
+		rt.Call(actor.EmitValue, &value)
+		require.False(t, rec.failed, "mismatch should be reported on verification")
+
+		rt.Verify()
+		require.True(t, rec.failed)
+		require.Contains(t, strings.Join(rec.logs, "\n"), "event mismatch")
+	})
+
+	t.Run("missing event fails verification", func(t *testing.T) {
+		rt := NewBuilder(receiver).Build(t)
+		rec := &recordingTB{TB: t}
+		rt.t = rec
+		rt.ExpectEmittedEvent(fakeEvent(1))
+		rt.Verify()
+		require.True(t, rec.failed)
+		require.Contains(t, strings.Join(rec.logs, "\n"), "missing expected event")
+	})
+
+	t.Run("events of an aborted call are discarded", func(t *testing.T) {
+		rt := NewBuilder(receiver).Build(t)
+		rt.ExpectValidateCallerAny()
+		value := cbg.CborInt(-1)
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			rt.Call(actor.EmitValue, &value)
+		})
+		require.Empty(t, rt.EmittedEvents())
+		rt.Verify()
+	})
+}
+
+func TestStoreOps(t *testing.T) {
+	actor := FakeActor{}
+	receiver := tutil.NewIDAddr(t, 100)
+
+	t.Run("injected store receives blocks", func(t *testing.T) {
+		bs := ipld.NewMetricsBlockStore(ipld.NewBlockStoreInMemory())
+		store := adt.WrapBlockStore(context.Background(), bs)
+		rt := NewBuilder(receiver).WithCaller(builtin.InitActorAddr, builtin.InitActorCodeID).WithStore(store).Build(t)
+
+		mutate := cbg.CborBool(false)
+		rt.Call(actor.Constructor, &mutate)
+		writes := bs.WriteCount()
+		require.NotZero(t, writes, "expected constructor to write state to injected store")
+
+		rt.ExpectValidateCallerAny()
+		rt.Call(actor.TransactionState, &mutate)
+		rt.Verify()
+		require.NotZero(t, bs.ReadCount(), "expected transaction to read state through injected store")
+		require.Greater(t, bs.WriteCount(), writes, "expected transaction to write state through injected store")
+	})
+
+	t.Run("missing block in injected store is not found", func(t *testing.T) {
+		store := adt.WrapBlockStore(context.Background(), ipld.NewBlockStoreInMemory())
+		rt := NewBuilder(receiver).WithStore(store).Build(t)
+
+		var st State
+		require.False(t, rt.StoreGet(tutil.MakeCID("missing", nil), &st))
+	})
+
+	t.Run("injected store errors fail the test", func(t *testing.T) {
+		store := adt.WrapBlockStore(context.Background(), failingBlockStore{})
+		rt := NewBuilder(receiver).WithStore(store).Build(t)
+		rec := &recordingTB{TB: t}
+		rt.t = rec
+
+		// FailNow exits the goroutine, so the lookup runs on its own.
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			var st State
+			rt.StoreGet(tutil.MakeCID("missing", nil), &st)
+		}()
+		<-done
+		require.True(t, rec.failed)
+		require.Contains(t, strings.Join(rec.logs, "\n"), "failed to get block")
+	})
+
+	t.Run("store ops within limits", func(t *testing.T) {
+		rt := NewBuilder(receiver).WithCaller(builtin.InitActorAddr, builtin.InitActorCodeID).Build(t)
+		mutate := cbg.CborBool(false)
+		rt.Call(actor.Constructor, &mutate)
+
+		rt.ExpectValidateCallerAny()
+		rt.ExpectStoreOps(1, 1)
+		rt.Call(actor.TransactionState, &mutate)
+		gets, puts := rt.StoreOps()
+		require.Equal(t, 1, gets)
+		require.Equal(t, 1, puts)
+		rt.Verify()
+	})
+
+	t.Run("store ops exceeding limits fail verification", func(t *testing.T) {
+		rt := NewBuilder(receiver).WithCaller(builtin.InitActorAddr, builtin.InitActorCodeID).Build(t)
+		mutate := cbg.CborBool(false)
+		rt.Call(actor.Constructor, &mutate)
+
+		// Verify against a recording test so the expected failure does not fail this test.
+		rec := &recordingTB{TB: t}
+		rt.t = rec
+		rt.ExpectValidateCallerAny()
+		rt.ExpectStoreOps(1, 1)
+		rt.Call(actor.TransactionStateTwice, &mutate)
+		require.False(t, rec.failed, "limits should be reported on verification")
+
+		rt.Verify()
+		require.True(t, rec.failed)
+		require.Contains(t, strings.Join(rec.logs, "\n"), "store puts")
+	})
+
+	t.Run("limits apply only to the next call", func(t *testing.T) {
+		rt := NewBuilder(receiver).WithCaller(builtin.InitActorAddr, builtin.InitActorCodeID).Build(t)
+		mutate := cbg.CborBool(false)
+		rt.Call(actor.Constructor, &mutate)
+
+		rt.ExpectValidateCallerAny()
+		rt.ExpectStoreOps(1, 1)
+		rt.Call(actor.TransactionState, &mutate)
+		rt.ExpectValidateCallerAny()
+		rt.Call(actor.TransactionStateTwice, &mutate)
+		rt.Verify()
+	})
+
+	t.Run("limits without a call fail verification", func(t *testing.T) {
+		rt := NewBuilder(receiver).Build(t)
+		rec := &recordingTB{TB: t}
+		rt.t = rec
+
+		rt.ExpectStoreOps(1, 1)
+		rt.Verify()
+		require.True(t, rec.failed)
+	})
+}
+
+// A testing.TB that records failures and logs rather than reporting them.
+type recordingTB struct {
+	testing.TB
+	failed bool
+	logs   []string
+}
+
+func (r *recordingTB) Fail() {
+	r.failed = true
+}
+
+func (r *recordingTB) FailNow() {
+	r.failed = true
+	goruntime.Goexit()
+}
+
+func (r *recordingTB) Logf(format string, args ...interface{}) {
+	r.logs = append(r.logs, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingTB) Helper() {}
+
+// A block store whose reads always fail.
+type failingBlockStore struct{}
+
+func (failingBlockStore) Get(c cid.Cid) (block.Block, error) {
+	return nil, fmt.Errorf("failed to read %s", c)
+}
+
+func (failingBlockStore) Put(block.Block) error {
+	return nil
+}
+
+func TestClone(t *testing.T) {
+	receiver := tutil.NewIDAddr(t, 100)
+	other := tutil.NewIDAddr(t, 101)
+	rt := NewBuilder(receiver).WithActorType(other, builtin.AccountActorCodeID).Build(t)
+	v1 := cbg.CborInt(1)
+	c1 := rt.StorePut(&v1)
+
+	t.Run("clone shares no mutable data", func(t *testing.T) {
+		clone := rt.Clone(t)
+
+		var out cbg.CborInt
+		require.True(t, clone.StoreGet(c1, &out))
+		require.Equal(t, v1, out)
+
+		v2 := cbg.CborInt(2)
+		c2 := clone.StorePut(&v2)
+		require.False(t, rt.StoreGet(c2, &out))
+
+		clone.SetAddressActorType(tutil.NewIDAddr(t, 102), builtin.MultisigActorCodeID)
+		_, found := rt.actorCodeCIDs[tutil.NewIDAddr(t, 102)]
+		require.False(t, found)
+		require.Equal(t, builtin.AccountActorCodeID, clone.actorCodeCIDs[other])
+	})
+
+	t.Run("clones are independent when used in parallel", func(t *testing.T) {
+		for i := 0; i < 4; i++ {
+			i := i
+			t.Run(fmt.Sprintf("clone%d", i), func(t *testing.T) {
+				t.Parallel()
+				clone := rt.Clone(t)
+				for j := 0; j < 100; j++ {
+					v := cbg.CborInt(i*1000 + j)
+					c := clone.StorePut(&v)
+					var out cbg.CborInt
+					require.True(t, clone.StoreGet(c, &out))
+					require.Equal(t, v, out)
+				}
+			})
+		}
+	})
+
+	t.Run("derived builders do not share options", func(t *testing.T) {
+		base := NewBuilder(receiver).WithEpoch(1).WithEpoch(2).WithEpoch(3)
+		b1 := base.WithEpoch(10)
+		b2 := base.WithEpoch(20)
+		require.Equal(t, abi.ChainEpoch(10), b1.Build(t).Epoch())
+		require.Equal(t, abi.ChainEpoch(20), b2.Build(t).Epoch())
+	})
+}
+
+func TestExpectVerifySignatureOfType(t *testing.T) {
+	receiver := tutil.NewIDAddr(t, 100)
+	signer := tutil.NewIDAddr(t, 101)
+	plaintext := []byte("plaintext")
+
+	t.Run("matches any signature of the type", func(t *testing.T) {
+		rt := NewBuilder(receiver).Build(t)
+		rt.ExpectVerifySignatureOfType(crypto.SigTypeBLS, signer, plaintext, nil)
+		rt.ExpectVerifySignatureOfType(crypto.SigTypeSecp256k1, signer, plaintext, fmt.Errorf("bad signature"))
+
+		require.NoError(t, rt.VerifySignature(crypto.Signature{Type: crypto.SigTypeBLS, Data: []byte{1, 2}}, signer, plaintext))
+		require.Error(t, rt.VerifySignature(crypto.Signature{Type: crypto.SigTypeSecp256k1, Data: []byte{3}}, signer, plaintext))
+		rt.Verify()
+	})
+
+	t.Run("fails on a signature of another type", func(t *testing.T) {
+		rec := &recordingTB{TB: t}
+		rt := NewBuilder(receiver).Build(rec)
+		rt.ExpectVerifySignatureOfType(crypto.SigTypeBLS, signer, plaintext, nil)
+		_ = rt.VerifySignature(crypto.Signature{Type: crypto.SigTypeSecp256k1}, signer, plaintext)
+		require.True(t, rec.failed)
+	})
+}
+
+func TestComputeCID(t *testing.T) {
+	receiver := tutil.NewIDAddr(t, 100)
+	data := []byte("data")
+
+	t.Run("matches CIDs computed outside a runtime", func(t *testing.T) {
+		rt := NewBuilder(receiver).Build(t)
+		c, err := rt.ComputeCID(cid.DagCBOR, data)
+		require.NoError(t, err)
+		expected, err := abi.CidBuilder.Sum(data)
+		require.NoError(t, err)
+		require.Equal(t, expected, c)
+	})
+
+	t.Run("ignores the runtime's hasher", func(t *testing.T) {
+		rt := NewBuilder(receiver).WithHasher(func(data []byte) [32]byte {
+			return [32]byte{1}
+		}).Build(t)
+		c, err := rt.ComputeCID(cid.Raw, data)
+		require.NoError(t, err)
+		require.Equal(t, uint64(cid.Raw), c.Prefix().Codec)
+		expected, err := cid.V1Builder{Codec: cid.Raw, MhType: mh.BLAKE2B_MIN + 31}.Sum(data)
+		require.NoError(t, err)
+		require.Equal(t, expected, c)
+	})
+	t.Run("expected computation returns the expected CID", func(t *testing.T) {
+		rt := NewBuilder(receiver).Build(t)
+		expected := tutil.MakeCID("expected", nil)
+		rt.ExpectComputeCID(cid.DagCBOR, data, expected, nil)
+		c, err := rt.ComputeCID(cid.DagCBOR, data)
+		require.NoError(t, err)
+		require.Equal(t, expected, c)
+		rt.Verify()
+	})
+
+	t.Run("mismatched computation fails", func(t *testing.T) {
+		rec := &recordingTB{TB: t}
+		rt := NewBuilder(receiver).Build(rec)
+		rt.ExpectComputeCID(cid.DagCBOR, []byte("other"), tutil.MakeCID("expected", nil), nil)
+		_, _ = rt.ComputeCID(cid.DagCBOR, data)
+		require.True(t, rec.failed)
+	})
+
+	t.Run("missing computation fails verification", func(t *testing.T) {
+		rt := NewBuilder(receiver).Build(t)
+		rec := &recordingTB{TB: t}
+		rt.t = rec
+		rt.ExpectComputeCID(cid.DagCBOR, data, tutil.MakeCID("expected", nil), nil)
+		rt.Verify()
+		require.True(t, rec.failed)
+		require.Contains(t, strings.Join(rec.logs, "\n"), "missing expected ComputeCID")
+	})
 }
@@ -0,0 +1,283 @@
+package mock
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/filecoin-project/go-state-types/cbor"
+	cid "github.com/ipfs/go-cid"
+	cbg "github.com/whyrusleeping/cbor-gen"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v3/actors/runtime"
+)
+
+// Lists every expectation that has been set but not yet consumed, one description per entry.
+func (rt *Runtime) unmetExpectations() []string {
+	var unmet []string
+	if rt.expectValidateCallerAny {
+		unmet = append(unmet, "expected ValidateCallerAny, not received")
+	}
+	if len(rt.expectValidateCallerAddr) > 0 {
+		unmet = append(unmet, fmt.Sprintf("missing expected ValidateCallerAddr %v", rt.expectValidateCallerAddr))
+	}
+	if len(rt.expectValidateCallerType) > 0 {
+		unmet = append(unmet, fmt.Sprintf("missing expected ValidateCallerType %v", rt.expectValidateCallerType))
+	}
+	for _, r := range rt.expectRandomnessBeacon {
+		unmet = append(unmet, fmt.Sprintf("missing expected beacon randomness %v", r))
+	}
+	for _, r := range rt.expectRandomnessTickets {
+		unmet = append(unmet, fmt.Sprintf("missing expected ticket randomness %v", r))
+	}
+	for _, s := range rt.expectSends {
+		unmet = append(unmet, fmt.Sprintf("missing expected %s to: %s method: %d value: %v params: %s",
+			sendFlavor(s.static, s.gasLimit), s.to, s.method, s.value, cborToJSON(s.params)))
+	}
+	for _, s := range rt.expectVerifySigs {
+		unmet = append(unmet, fmt.Sprintf("missing expected verify signature by %s", s.signer))
+	}
+	if rt.expectCreateActor != nil {
+		unmet = append(unmet, fmt.Sprintf("missing expected create actor with code %s, address %s",
+			rt.expectCreateActor.codeId, rt.expectCreateActor.address))
+	}
+	if rt.expectVerifySeal != nil {
+		unmet = append(unmet, fmt.Sprintf("missing expected verify seal with %v", rt.expectVerifySeal.seal))
+	}
+	if rt.expectBatchVerifySeals != nil {
+		unmet = append(unmet, fmt.Sprintf("missing expected batch verify seals with %v", rt.expectBatchVerifySeals))
+	}
+	if rt.expectAggregateVerifySeals != nil {
+		unmet = append(unmet, fmt.Sprintf("missing expected aggregate verify seals with %v", rt.expectAggregateVerifySeals.in))
+	}
+	if rt.expectComputeUnsealedSectorCID != nil {
+		unmet = append(unmet, fmt.Sprintf("missing expected ComputeUnsealedSectorCID with %v", rt.expectComputeUnsealedSectorCID))
+	}
+	for _, c := range rt.expectComputeCIDs {
+		unmet = append(unmet, fmt.Sprintf("missing expected ComputeCID with codec %d, data %x", c.codec, c.data))
+	}
+	if rt.expectVerifyPoSt != nil {
+		unmet = append(unmet, fmt.Sprintf("missing expected PoSt verification with %v", rt.expectVerifyPoSt))
+	}
+	if rt.expectVerifyReplicaUpdate != nil {
+		unmet = append(unmet, fmt.Sprintf("missing expected replica update verification with %v", rt.expectVerifyReplicaUpdate))
+	}
+	if rt.expectVerifyConsensusFault != nil {
+		unmet = append(unmet, "missing expected verify consensus fault")
+	}
+	if rt.expectDeleteActor != nil {
+		unmet = append(unmet, fmt.Sprintf("missing expected delete actor with address %s", rt.expectDeleteActor.String()))
+	}
+	if rt.expectReplaceActorCodes != nil {
+		unmet = append(unmet, fmt.Sprintf("missing expected actor code replacement %v", rt.expectReplaceActorCodes))
+	}
+	for _, e := range rt.expectEvents {
+		unmet = append(unmet, fmt.Sprintf("missing expected event %s", describeEvent(e)))
+	}
+	if rt.expectStoreOps != nil {
+		unmet = append(unmet, fmt.Sprintf("missing expected call limited to %d store gets and %d store puts",
+			rt.expectStoreOps.maxGets, rt.expectStoreOps.maxPuts))
+	}
+	return unmet
+}
+
+// Formats a list of problems as a single numbered report.
+func formatReport(title string, problems []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (%d):", title, len(problems))
+	for i, p := range problems {
+		fmt.Fprintf(&b, "\n%3d. %s", i+1, strings.ReplaceAll(p, "\n", "\n     "))
+	}
+	return b.String()
+}
+
+// Describes the difference between an expected and actual send, with parameters rendered as JSON.
+func describeSendMismatch(expected, actual string, expParams, params cbor.Marshaler) string {
+	msg := "unexpected send\n" +
+		"      got: " + actual + "\n" +
+		" expected: " + expected
+	expJSON, actJSON := cborToJSON(expParams), cborToJSON(params)
+	if expJSON != actJSON {
+		msg += "\nparams (- expected, + actual):\n" + lineDiff(expJSON, actJSON)
+	}
+	return msg
+}
+
+//
+// CBOR to JSON rendering
+//
+
+// Renders the entries of an event, decoding each value from CBOR.
+func describeEvent(entries []runtime.EventEntry) string {
+	parts := make([]string, len(entries))
+	for i, e := range entries {
+		parts[i] = fmt.Sprintf("%s(%d)=%s", e.Key, e.Flags, cborToJSON(builtin.CBORBytes(e.Value)))
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+// Renders a CBOR-marshalable value as indented JSON, for human inspection of parameters.
+// CIDs are rendered as {"/": "<cid>"} and byte strings as hex.
+// Returns a description of the failure if the value cannot be rendered.
+func cborToJSON(m cbor.Marshaler) string {
+	if m == nil {
+		return "null"
+	}
+	var buf bytes.Buffer
+	if err := m.MarshalCBOR(&buf); err != nil {
+		return fmt.Sprintf("<failed to marshal %T: %s>", m, err)
+	}
+	if buf.Len() == 0 {
+		return "null"
+	}
+	v, err := decodeCBOR(cbg.GetPeeker(&buf))
+	if err != nil {
+		return fmt.Sprintf("<failed to decode %T: %s>", m, err)
+	}
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("<failed to render %T: %s>", m, err)
+	}
+	return string(out)
+}
+
+// Decodes a single CBOR data item into values that encoding/json can render.
+func decodeCBOR(r io.Reader) (interface{}, error) {
+	scratch := make([]byte, 9)
+	maj, extra, err := cbg.CborReadHeaderBuf(r, scratch)
+	if err != nil {
+		return nil, err
+	}
+	switch maj {
+	case cbg.MajUnsignedInt:
+		return extra, nil
+	case cbg.MajNegativeInt:
+		if extra > math.MaxInt64 {
+			return fmt.Sprintf("-1-%d", extra), nil
+		}
+		return -1 - int64(extra), nil
+	case cbg.MajByteString:
+		if extra > cbg.ByteArrayMaxLen {
+			return nil, xerrors.Errorf("byte string too long (%d)", extra)
+		}
+		b := make([]byte, extra)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return nil, err
+		}
+		return "0x" + hex.EncodeToString(b), nil
+	case cbg.MajTextString:
+		if extra > cbg.MaxLength {
+			return nil, xerrors.Errorf("text string too long (%d)", extra)
+		}
+		b := make([]byte, extra)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return nil, err
+		}
+		return string(b), nil
+	case cbg.MajArray:
+		// Arrays aren't bounded by cbg.MaxLength: params may legitimately exceed it, and items are
+		// appended as they're decoded, so a corrupt length fails on reading rather than allocation.
+		var arr []interface{}
+		for i := uint64(0); i < extra; i++ {
+			item, err := decodeCBOR(r)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, item)
+		}
+		if arr == nil {
+			arr = []interface{}{}
+		}
+		return arr, nil
+	case cbg.MajMap:
+		m := make(map[string]interface{})
+		for i := uint64(0); i < extra; i++ {
+			k, err := decodeCBOR(r)
+			if err != nil {
+				return nil, err
+			}
+			v, err := decodeCBOR(r)
+			if err != nil {
+				return nil, err
+			}
+			m[fmt.Sprint(k)] = v
+		}
+		return m, nil
+	case cbg.MajTag:
+		v, err := decodeCBOR(r)
+		if err != nil {
+			return nil, err
+		}
+		if extra == 42 {
+			// A CID, encoded as a byte string with a leading multibase identity prefix.
+			if s, ok := v.(string); ok && strings.HasPrefix(s, "0x00") {
+				raw, err := hex.DecodeString(s[4:])
+				if err != nil {
+					return nil, err
+				}
+				c, err := cid.Cast(raw)
+				if err != nil {
+					return nil, err
+				}
+				return map[string]string{"/": c.String()}, nil
+			}
+		}
+		return map[string]interface{}{"tag": extra, "value": v}, nil
+	case cbg.MajOther:
+		switch extra {
+		case 20:
+			return false, nil
+		case 21:
+			return true, nil
+		case 22, 23:
+			return nil, nil
+		}
+		return fmt.Sprintf("<simple value %d>", extra), nil
+	}
+	return nil, xerrors.Errorf("unknown cbor major type %d", maj)
+}
+
+// Renders a line-by-line diff of two texts, prefixing lines only in a with "-" and lines only in b with "+".
+func lineDiff(a, b string) string {
+	al, bl := strings.Split(a, "\n"), strings.Split(b, "\n")
+	// lcs[i][j] is the length of the longest common subsequence of al[i:] and bl[j:].
+	lcs := make([][]int, len(al)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(bl)+1)
+	}
+	for i := len(al) - 1; i >= 0; i-- {
+		for j := len(bl) - 1; j >= 0; j-- {
+			if al[i] == bl[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var out strings.Builder
+	i, j := 0, 0
+	for i < len(al) || j < len(bl) {
+		switch {
+		case i < len(al) && j < len(bl) && al[i] == bl[j]:
+			out.WriteString("  " + al[i] + "\n")
+			i++
+			j++
+		case i < len(al) && (j == len(bl) || lcs[i+1][j] >= lcs[i][j+1]):
+			out.WriteString("- " + al[i] + "\n")
+			i++
+		default:
+			out.WriteString("+ " + bl[j] + "\n")
+			j++
+		}
+	}
+	return strings.TrimSuffix(out.String(), "\n")
+}
@@ -0,0 +1,182 @@
+package mock
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cbg "github.com/whyrusleeping/cbor-gen"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin/market"
+	"github.com/filecoin-project/specs-actors/v3/actors/runtime"
+	tutil "github.com/filecoin-project/specs-actors/v3/support/testing"
+)
+
+func TestCBORToJSON(t *testing.T) {
+	t.Run("nil params", func(t *testing.T) {
+		assert.Equal(t, "null", cborToJSON(nil))
+	})
+
+	t.Run("structs with cids, ints and bytes", func(t *testing.T) {
+		params := &market.ActivateDealsParams{
+			DealIDs:      []abi.DealID{1, 2},
+			SectorExpiry: -1,
+		}
+		assert.Equal(t, "[\n  [\n    1,\n    2\n  ],\n  -1\n]", cborToJSON(params))
+
+		cdcParams := &market.ComputeDataCommitmentParams{DealIDs: nil, SectorType: 1}
+		assert.Equal(t, "[\n  [],\n  1\n]", cborToJSON(cdcParams))
+
+		c := tutil.MakeCID("data", &market.PieceCIDPrefix)
+
+		deal := &market.DealProposal{
+			PieceCID:             c,
+			Client:               tutil.NewIDAddr(t, 100),
+			Provider:             tutil.NewIDAddr(t, 101),
+			StoragePricePerEpoch: big.NewInt(256),
+			ProviderCollateral:   big.Zero(),
+			ClientCollateral:     big.Zero(),
+		}
+		assert.Contains(t, cborToJSON(deal), `"/": "`+c.String()+`"`)
+		assert.Contains(t, cborToJSON(deal), `"0x000100"`) // big int 256
+	})
+
+	t.Run("arrays longer than the cbor-gen length limit", func(t *testing.T) {
+		n := cbg.MaxLength + 1
+		out := cborToJSON(uintArray(n))
+		require.False(t, strings.HasPrefix(out, "<"), out)
+		assert.Equal(t, n+2, strings.Count(out, "\n")+1) // one line per item plus brackets
+	})
+}
+
+// Marshals as a CBOR array of n zeros, bypassing the length limit enforced by generated encoders.
+type uintArray int
+
+func (a uintArray) MarshalCBOR(w io.Writer) error {
+	if err := cbg.WriteMajorTypeHeader(w, cbg.MajArray, uint64(a)); err != nil {
+		return err
+	}
+	for i := 0; i < int(a); i++ {
+		if err := cbg.WriteMajorTypeHeader(w, cbg.MajUnsignedInt, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestSendMismatchReport(t *testing.T) {
+	receiver := tutil.NewIDAddr(t, 100)
+
+	sendTwice := func(rt runtime.Runtime, _ *abi.EmptyValue) *abi.EmptyValue {
+		params := cbg.CborInt(2)
+		code := rt.Send(builtin.RewardActorAddr, builtin.MethodsReward.AwardBlockReward, &params, big.Zero(), &builtin.Discard{})
+		builtin.RequireSuccess(rt, code, "first send failed")
+		code = rt.Send(builtin.BurntFundsActorAddr, builtin.MethodSend, nil, big.Zero(), &builtin.Discard{})
+		builtin.RequireSuccess(rt, code, "second send failed")
+		return nil
+	}
+
+	t.Run("mismatched and unexpected sends are reported together on verification", func(t *testing.T) {
+		rec := &recordingTB{TB: t}
+		rt := NewBuilder(receiver).Build(t)
+		rt.t = rec
+
+		expected := cbg.CborInt(1)
+		rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.AwardBlockReward, &expected, big.Zero(), nil, exitcode.Ok)
+		rt.ExpectAbort(exitcode.SysErrInvalidReceiver, func() {
+			rt.Call(sendTwice, nil)
+		})
+		require.False(t, rec.failed, "mismatches should not fail the test before verification")
+
+		rt.Verify()
+		require.True(t, rec.failed)
+		report := strings.Join(rec.logs, "\n")
+		assert.Contains(t, report, "unmet expectations (2)")
+		assert.Contains(t, report, "- 1\n")
+		assert.Contains(t, report, "+ 2")
+		assert.Contains(t, report, "unexpected send to: "+builtin.BurntFundsActorAddr.String())
+	})
+
+	t.Run("send of a different flavor is reported", func(t *testing.T) {
+		rec := &recordingTB{TB: t}
+		rt := NewBuilder(receiver).Build(t)
+		rt.t = rec
+
+		rt.ExpectSend(builtin.RewardActorAddr, builtin.MethodsReward.ThisEpochReward, nil, big.Zero(), nil, exitcode.Ok)
+		rt.Call(func(rt runtime.Runtime, _ *abi.EmptyValue) *abi.EmptyValue {
+			rt.StaticSend(builtin.RewardActorAddr, builtin.MethodsReward.ThisEpochReward, nil, &builtin.Discard{})
+			return nil
+		}, nil)
+		rt.Verify()
+		require.True(t, rec.failed)
+		assert.Contains(t, strings.Join(rec.logs, "\n"), "unexpected static send to: "+builtin.RewardActorAddr.String())
+	})
+
+	t.Run("unverified sends fail the test on cleanup", func(t *testing.T) {
+		tb := &cleanupTB{recordingTB: &recordingTB{TB: t}}
+		rt := NewBuilder(receiver).Build(tb)
+
+		rt.ExpectAbort(exitcode.SysErrInvalidReceiver, func() {
+			rt.Call(sendTwice, nil)
+		})
+		require.False(t, tb.failed)
+
+		tb.runCleanups()
+		require.True(t, tb.failed)
+		assert.Contains(t, strings.Join(tb.logs, "\n"), "unexpected send to: "+builtin.RewardActorAddr.String())
+	})
+
+	t.Run("unverified sends of a clone fail the clone's test on cleanup", func(t *testing.T) {
+		rt := NewBuilder(receiver).Build(t)
+		tb := &cleanupTB{recordingTB: &recordingTB{TB: t}}
+		clone := rt.Clone(tb)
+
+		clone.ExpectAbort(exitcode.SysErrInvalidReceiver, func() {
+			clone.Call(sendTwice, nil)
+		})
+		tb.runCleanups()
+		require.True(t, tb.failed)
+	})
+
+	t.Run("recorded sends are reported when expectations are reset", func(t *testing.T) {
+		rec := &recordingTB{TB: t}
+		rt := NewBuilder(receiver).Build(t)
+		rt.t = rec
+
+		rt.ExpectAbort(exitcode.SysErrInvalidReceiver, func() {
+			rt.Call(sendTwice, nil)
+		})
+		rt.Reset()
+		require.True(t, rec.failed)
+		assert.Contains(t, strings.Join(rec.logs, "\n"), "failed expectations (1)")
+	})
+}
+
+func TestLineDiff(t *testing.T) {
+	assert.Equal(t, "  a\n- b\n+ x\n  c", lineDiff("a\nb\nc", "a\nx\nc"))
+	assert.Equal(t, "  a\n+ b", lineDiff("a", "a\nb"))
+	assert.Equal(t, "- a\n  b", lineDiff("a\nb", "b"))
+}
+
+// Records the cleanup functions registered by a runtime, so a test can run them before it ends.
+type cleanupTB struct {
+	*recordingTB
+	cleanups []func()
+}
+
+func (c *cleanupTB) Cleanup(f func()) {
+	c.cleanups = append(c.cleanups, f)
+}
+
+func (c *cleanupTB) runCleanups() {
+	for i := len(c.cleanups) - 1; i >= 0; i-- {
+		c.cleanups[i]()
+	}
+	c.cleanups = nil
+}
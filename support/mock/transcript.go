@@ -0,0 +1,253 @@
+package mock
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+
+	addr "github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/cbor"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	cid "github.com/ipfs/go-cid"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+)
+
+// A Transcript records the calls made through a mock runtime while recording is enabled, with each call's
+// context, parameters, caller validation, sends, outcome, and the state root before and after.
+// Parameters and return values are recorded as CBOR, so a transcript may be serialized (e.g. as JSON) and
+// later replayed against the actor with Runtime.Replay.
+type Transcript struct {
+	Calls []*TranscriptCall
+}
+
+type TranscriptCall struct {
+	Method     string // Name of the actor method, e.g. "PublishStorageDeals"
+	Caller     addr.Address
+	CallerType cid.Cid
+	Epoch      abi.ChainEpoch
+	Value      abi.TokenAmount
+	Params     builtin.CBORBytes // Nil for a method without parameters.
+	// The caller validation performed by the method, if any.
+	Validation *TranscriptValidation
+	Sends      []*TranscriptSend
+	// The method's return value, or nil if it returned nothing or aborted.
+	Return   builtin.CBORBytes
+	ExitCode exitcode.ExitCode
+	// The abort message, if the call aborted.
+	AbortMessage string
+	// The actor's state root before the call, and after it (equal to that before if the call aborted).
+	StateBefore cid.Cid
+	StateAfter  cid.Cid
+}
+
+type TranscriptValidation struct {
+	Any   bool
+	Addrs []addr.Address
+	Types []cid.Cid
+}
+
+type TranscriptSend struct {
+	To       addr.Address
+	Method   abi.MethodNum
+	Params   builtin.CBORBytes
+	Value    abi.TokenAmount
+	Static   bool
+	GasLimit int64
+	// The scripted result of the send.
+	ExitCode exitcode.ExitCode
+	Return   builtin.CBORBytes
+}
+
+// Starts recording a new transcript of the calls made through this runtime, discarding any previous transcript.
+func (rt *Runtime) RecordTranscript() {
+	rt.transcript = &Transcript{}
+}
+
+// Returns the transcript recorded since RecordTranscript, or nil if recording is not enabled.
+// While recording, tests failed by the runtime also log the transcript.
+func (rt *Runtime) Transcript() *Transcript {
+	return rt.transcript
+}
+
+// Replays the calls of a transcript against an actor, one call at a time.
+// Each call is made from its recorded state root with its recorded caller, epoch, value and parameters.
+// Its caller validation and sends are expected as recorded, sends returning their recorded results.
+// The test fails if a call's outcome, return value or resulting state root differs from that recorded.
+//
+// The runtime's store must hold the recorded states, as does the runtime that recorded the transcript, or a clone
+// of it. Syscalls other than sends (such as randomness or signature verification) are not recorded, so a call
+// making them must be scripted by the test before replay, or will fail as unexpected.
+func (rt *Runtime) Replay(actor interface{ Exports() []interface{} }, transcript *Transcript) {
+	rt.t.Helper()
+	methods := map[string]interface{}{}
+	for _, m := range actor.Exports() {
+		if m != nil {
+			methods[methodName(m)] = m
+		}
+	}
+
+	for i, call := range transcript.Calls {
+		method, ok := methods[call.Method]
+		if !ok {
+			rt.failTestNow("replayed call %d: actor has no method %s", i, call.Method)
+		}
+
+		rt.state = call.StateBefore
+		rt.SetCaller(call.Caller, call.CallerType)
+		rt.SetEpoch(call.Epoch)
+		rt.SetReceived(call.Value)
+		if v := call.Validation; v != nil {
+			if v.Any {
+				rt.ExpectValidateCallerAny()
+			}
+			if len(v.Addrs) > 0 {
+				rt.ExpectValidateCallerAddr(v.Addrs...)
+			}
+			if len(v.Types) > 0 {
+				rt.ExpectValidateCallerType(v.Types...)
+			}
+		}
+		for _, s := range call.Sends {
+			var params cbor.Marshaler
+			if s.Params != nil {
+				params = s.Params
+			}
+			var ret cbor.Er
+			if s.Return != nil {
+				r := s.Return
+				ret = &r
+			}
+			rt.ExpectSend(s.To, s.Method, params, s.Value, ret, s.ExitCode)
+			rt.expectSends[len(rt.expectSends)-1].static = s.Static
+			rt.expectSends[len(rt.expectSends)-1].gasLimit = s.GasLimit
+		}
+
+		var params interface{}
+		if call.Params != nil {
+			p := reflect.New(reflect.TypeOf(method).In(1).Elem()).Interface().(cbor.Unmarshaler)
+			if err := p.UnmarshalCBOR(bytes.NewReader(call.Params)); err != nil {
+				rt.failTestNow("replayed call %d (%s): failed to decode params: %v", i, call.Method, err)
+			}
+			params = p
+		}
+
+		var ret interface{}
+		if call.ExitCode.IsSuccess() {
+			ret = rt.Call(method, params)
+		} else {
+			rt.ExpectAbortContainsMessage(call.ExitCode, call.AbortMessage, func() {
+				rt.Call(method, params)
+			})
+		}
+		rt.Verify()
+
+		if retBytes := encodeCBOR(ret); !bytes.Equal(retBytes, call.Return) {
+			rt.failTest("replayed call %d (%s) returned %s, recorded %s", i, call.Method,
+				cborToJSON(builtin.CBORBytes(retBytes)), cborToJSON(call.Return))
+		}
+		if !rt.state.Equals(call.StateAfter) {
+			rt.failTest("replayed call %d (%s) left state %s, recorded %s", i, call.Method, rt.state, call.StateAfter)
+		}
+	}
+}
+
+// Renders the transcript for reading, with parameters and return values as JSON.
+func (t *Transcript) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "transcript of %d call(s):", len(t.Calls))
+	for i, call := range t.Calls {
+		fmt.Fprintf(&b, "\n%d: %s at epoch %d from %s value %v\n   params: %s", i, call.Method, call.Epoch,
+			call.Caller, call.Value, indent(cborToJSON(call.Params)))
+		for _, s := range call.Sends {
+			fmt.Fprintf(&b, "\n   %s to %s method %d value %v params: %s\n     => exit %v return: %s",
+				sendFlavor(s.Static, s.GasLimit), s.To, s.Method, s.Value, indent(cborToJSON(s.Params)),
+				s.ExitCode, indent(cborToJSON(s.Return)))
+		}
+		if call.ExitCode.IsSuccess() {
+			fmt.Fprintf(&b, "\n   => return: %s", indent(cborToJSON(call.Return)))
+		} else {
+			fmt.Fprintf(&b, "\n   => abort(%v): %s", call.ExitCode, call.AbortMessage)
+		}
+		fmt.Fprintf(&b, "\n   state %s -> %s", call.StateBefore, call.StateAfter)
+	}
+	return b.String()
+}
+
+// Begins recording a call, if recording is enabled.
+func (rt *Runtime) beginTranscriptCall(method reflect.Value, params interface{}) {
+	if rt.transcript == nil {
+		return
+	}
+	call := &TranscriptCall{
+		Method:      methodName(method.Interface()),
+		Caller:      rt.caller,
+		CallerType:  rt.callerType,
+		Epoch:       rt.epoch,
+		Value:       rt.valueReceived,
+		ExitCode:    exitcode.Ok,
+		StateBefore: rt.state,
+	}
+	call.Params = encodeCBOR(params)
+	rt.transcript.Calls = append(rt.transcript.Calls, call)
+	rt.transcriptCall = call
+}
+
+// Completes the call being recorded with its outcome, being either a return value or a recovered abort.
+func (rt *Runtime) endTranscriptCall(ret interface{}, r interface{}) {
+	call := rt.transcriptCall
+	if call == nil {
+		return
+	}
+	rt.transcriptCall = nil
+	if a, ok := r.(abort); ok {
+		call.ExitCode = a.code
+		call.AbortMessage = a.msg
+		call.StateAfter = call.StateBefore
+		return
+	}
+	call.Return = encodeCBOR(ret)
+	call.StateAfter = rt.state
+}
+
+// Logs the transcript recorded so far, if recording, to show how a failing test reached the failure.
+func (rt *Runtime) logTranscript() {
+	if rt.transcript != nil {
+		rt.t.Logf("%s", rt.transcript)
+	}
+}
+
+func (rt *Runtime) recordValidation(v TranscriptValidation) {
+	if rt.transcriptCall != nil {
+		rt.transcriptCall.Validation = &v
+	}
+}
+
+func (rt *Runtime) recordSend(s *TranscriptSend) {
+	if rt.transcriptCall != nil {
+		rt.transcriptCall.Sends = append(rt.transcriptCall.Sends, s)
+	}
+}
+
+// Encodes a parameter or return value, which may be nil or a nil pointer, as CBOR.
+// Returns nil for a value encoding as nothing, such as abi.Empty.
+func encodeCBOR(v interface{}) builtin.CBORBytes {
+	m, ok := v.(cbor.Marshaler)
+	if !ok {
+		return nil
+	}
+	if rv := reflect.ValueOf(m); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := m.MarshalCBOR(&buf); err != nil || buf.Len() == 0 {
+		return nil
+	}
+	return buf.Bytes()
+}
+
+func indent(s string) string {
+	return strings.ReplaceAll(s, "\n", "\n   ")
+}
@@ -0,0 +1,122 @@
+package mock
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	cid "github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cbg "github.com/whyrusleeping/cbor-gen"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v3/actors/runtime"
+	tutil "github.com/filecoin-project/specs-actors/v3/support/testing"
+)
+
+func TestTranscript(t *testing.T) {
+	actor := FakeActor{}
+	receiver := tutil.NewIDAddr(t, 100)
+	caller := tutil.NewIDAddr(t, 101)
+
+	record := func(t *testing.T) *Runtime {
+		rt := NewBuilder(receiver).WithCaller(caller, builtin.AccountActorCodeID).Build(t)
+		rt.RecordTranscript()
+
+		f := cbg.CborBool(false)
+		rt.Call(actor.Constructor, &f)
+		rt.Verify()
+
+		rt.SetEpoch(5)
+		rt.ExpectValidateCallerAny()
+		rt.Call(actor.TransactionState, &f)
+		rt.Verify()
+
+		neg := cbg.CborInt(-1)
+		rt.ExpectValidateCallerAny()
+		rt.ExpectAbort(exitcode.ErrIllegalArgument, func() {
+			rt.Call(actor.EmitValue, &neg)
+		})
+		rt.Verify()
+		return rt
+	}
+
+	t.Run("records calls and outcomes", func(t *testing.T) {
+		rt := record(t)
+		calls := rt.Transcript().Calls
+		require.Len(t, calls, 3)
+
+		assert.Equal(t, "Constructor", calls[0].Method)
+		assert.Equal(t, caller, calls[0].Caller)
+		assert.Equal(t, builtin.AccountActorCodeID, calls[0].CallerType)
+		assert.Nil(t, calls[0].Validation)
+		assert.Equal(t, cid.Undef, calls[0].StateBefore)
+		assert.True(t, calls[0].StateAfter.Defined())
+
+		assert.Equal(t, "TransactionState", calls[1].Method)
+		assert.Equal(t, abi.ChainEpoch(5), calls[1].Epoch)
+		assert.Equal(t, &TranscriptValidation{Any: true}, calls[1].Validation)
+		assert.Equal(t, calls[0].StateAfter, calls[1].StateBefore)
+		assert.NotEqual(t, calls[1].StateBefore, calls[1].StateAfter)
+		assert.Equal(t, exitcode.Ok, calls[1].ExitCode)
+
+		assert.Equal(t, "EmitValue", calls[2].Method)
+		assert.Equal(t, exitcode.ErrIllegalArgument, calls[2].ExitCode)
+		assert.Equal(t, "negative value", calls[2].AbortMessage)
+		assert.Equal(t, calls[2].StateBefore, calls[2].StateAfter)
+
+		assert.Contains(t, rt.Transcript().String(), "2: EmitValue at epoch 5")
+	})
+
+	t.Run("records sends with their scripted results", func(t *testing.T) {
+		rt := NewBuilder(receiver).WithBalance(big.NewInt(10), big.Zero()).Build(t)
+		rt.RecordTranscript()
+		ret := cbg.CborInt(7)
+		rt.ExpectSend(caller, builtin.MethodSend, nil, big.NewInt(3), &ret, exitcode.Ok)
+		rt.Call(func(rt runtime.Runtime, _ *abi.EmptyValue) *abi.EmptyValue {
+			code := rt.Send(caller, builtin.MethodSend, nil, big.NewInt(3), &builtin.Discard{})
+			builtin.RequireSuccess(rt, code, "send failed")
+			return nil
+		}, nil)
+		rt.Verify()
+
+		calls := rt.Transcript().Calls
+		require.Len(t, calls, 1)
+		assert.Nil(t, calls[0].Params)
+		assert.Nil(t, calls[0].Return)
+		require.Len(t, calls[0].Sends, 1)
+		send := calls[0].Sends[0]
+		assert.Equal(t, caller, send.To)
+		assert.Equal(t, big.NewInt(3), send.Value)
+		assert.Equal(t, exitcode.Ok, send.ExitCode)
+		assert.Equal(t, builtin.CBORBytes{0x07}, send.Return)
+	})
+
+	t.Run("replays a serialized transcript", func(t *testing.T) {
+		rt := record(t)
+		serialized, err := json.Marshal(rt.Transcript())
+		require.NoError(t, err)
+
+		var transcript Transcript
+		require.NoError(t, json.Unmarshal(serialized, &transcript))
+
+		rec := &recordingTB{TB: t}
+		replay := rt.Clone(rec)
+		replay.Replay(actor, &transcript)
+		assert.False(t, rec.failed, rec.logs)
+	})
+
+	t.Run("replay fails on a different outcome", func(t *testing.T) {
+		rt := record(t)
+		transcript := rt.Transcript()
+		transcript.Calls[1].StateAfter = transcript.Calls[1].StateBefore
+
+		rec := &recordingTB{TB: t}
+		replay := rt.Clone(rec)
+		replay.Replay(actor, transcript)
+		assert.True(t, rec.failed)
+	})
+}
@@ -0,0 +1,239 @@
+package profile
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	cid "github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+	cbg "github.com/whyrusleeping/cbor-gen"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/util/adt"
+)
+
+// Name of the collection accounting for the state root block itself.
+const RootCollection = "(root)"
+
+// Shape summarises the blocks reachable from a single link: a collection such as a HAMT or AMT,
+// or the state root block itself.
+type Shape struct {
+	Name  string
+	Root  cid.Cid
+	Nodes uint64
+	Bytes uint64
+	// Number of nodes at each depth, the collection root being at depth zero.
+	// A node reachable along several paths is counted once, at the first depth at which it is found.
+	Depths []uint64
+}
+
+// MaxDepth returns the depth of the deepest node in the collection.
+func (s Shape) MaxDepth() int {
+	return len(s.Depths) - 1
+}
+
+// Block describes a single block reachable from a state root.
+type Block struct {
+	Cid        cid.Cid
+	Size       uint64
+	Depth      int
+	Collection string
+}
+
+// Profile describes the shape of the state tree beneath an actor state root.
+type Profile struct {
+	Root cid.Cid
+	// One entry for the root block followed by one per distinct link from the root, in link order.
+	Collections []Shape
+	// Distinct blocks and bytes reachable from the root. Collections may share blocks (e.g. several
+	// empty maps), so this may be less than the sum of the collections.
+	TotalNodes uint64
+	TotalBytes uint64
+	// The largest distinct blocks, largest first.
+	Largest []Block
+}
+
+// ProfileState walks every block reachable from root and summarises it by collection.
+// If state is non-nil, root is decoded into it and collections are named after the state's exported
+// cid.Cid fields; links not matched to a field are named by their position in the root block.
+// The topN largest blocks are retained.
+func ProfileState(store adt.Store, root cid.Cid, state cbg.CBORUnmarshaler, topN int) (*Profile, error) {
+	names := map[cid.Cid]string{}
+	if state != nil {
+		if err := store.Get(store.Context(), root, state); err != nil {
+			return nil, xerrors.Errorf("failed to load state %v: %w", root, err)
+		}
+		names = fieldNames(state)
+	}
+
+	w := walker{store: store, seen: map[cid.Cid]struct{}{}}
+	rootShape := Shape{Name: RootCollection, Root: root}
+	links, err := w.visit(root, 0, &rootShape, map[cid.Cid]struct{}{})
+	if err != nil {
+		return nil, err
+	}
+	p := &Profile{Root: root, Collections: []Shape{rootShape}}
+
+	walked := map[cid.Cid]struct{}{}
+	for i, link := range links {
+		if _, ok := walked[link]; ok {
+			continue
+		}
+		walked[link] = struct{}{}
+		name, ok := names[link]
+		if !ok {
+			name = fmt.Sprintf("link%d", i)
+		}
+		shape := Shape{Name: name, Root: link}
+		if err := w.walk(link, &shape); err != nil {
+			return nil, xerrors.Errorf("failed to walk %s: %w", name, err)
+		}
+		p.Collections = append(p.Collections, shape)
+	}
+
+	for _, b := range w.blocks {
+		p.TotalNodes++
+		p.TotalBytes += b.Size
+	}
+	sort.SliceStable(w.blocks, func(i, j int) bool {
+		return w.blocks[i].Size > w.blocks[j].Size
+	})
+	if topN > len(w.blocks) {
+		topN = len(w.blocks)
+	}
+	if topN > 0 {
+		p.Largest = w.blocks[:topN]
+	}
+	return p, nil
+}
+
+// Collection returns the shape of the named collection, if present.
+func (p *Profile) Collection(name string) (Shape, bool) {
+	for _, s := range p.Collections {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Shape{}, false
+}
+
+func (p *Profile) String() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "state %v: %d nodes, %d bytes\n", p.Root, p.TotalNodes, p.TotalBytes)
+	tw := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "collection\tnodes\tbytes\tdepths")
+	for _, s := range p.Collections {
+		depths := make([]string, len(s.Depths))
+		for i, n := range s.Depths {
+			depths[i] = fmt.Sprint(n)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t[%s]\n", s.Name, s.Nodes, s.Bytes, strings.Join(depths, " "))
+	}
+	_ = tw.Flush()
+	if len(p.Largest) > 0 {
+		fmt.Fprintln(&buf, "largest blocks:")
+		tw = tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+		for _, b := range p.Largest {
+			fmt.Fprintf(tw, "%v\t%d\t%s\tdepth %d\n", b.Cid, b.Size, b.Collection, b.Depth)
+		}
+		_ = tw.Flush()
+	}
+	return buf.String()
+}
+
+// RequireStateSmallerThan fails the test if the blocks reachable from root total maxBytes or more,
+// reporting the state's profile.
+func RequireStateSmallerThan(t require.TestingT, store adt.Store, root cid.Cid, maxBytes uint64) {
+	p, err := ProfileState(store, root, nil, 5)
+	require.NoError(t, err)
+	require.Less(t, p.TotalBytes, maxBytes, "state exceeds %d bytes\n%s", maxBytes, p)
+}
+
+type walker struct {
+	store adt.Store
+	// Blocks seen anywhere in the walk, for totals.
+	seen   map[cid.Cid]struct{}
+	blocks []Block
+}
+
+// Walks the collection rooted at c breadth first, accumulating into shape.
+func (w *walker) walk(c cid.Cid, shape *Shape) error {
+	visited := map[cid.Cid]struct{}{}
+	frontier := []cid.Cid{c}
+	for depth := 0; len(frontier) > 0; depth++ {
+		var next []cid.Cid
+		for _, c := range frontier {
+			if _, ok := visited[c]; ok {
+				continue
+			}
+			links, err := w.visit(c, depth, shape, visited)
+			if err != nil {
+				return err
+			}
+			next = append(next, links...)
+		}
+		frontier = next
+	}
+	return nil
+}
+
+// Loads a single block, records it in shape and returns its traversable links.
+func (w *walker) visit(c cid.Cid, depth int, shape *Shape, visited map[cid.Cid]struct{}) ([]cid.Cid, error) {
+	visited[c] = struct{}{}
+	var raw cbg.Deferred
+	if err := w.store.Get(w.store.Context(), c, &raw); err != nil {
+		return nil, xerrors.Errorf("failed to load block %v: %w", c, err)
+	}
+	size := uint64(len(raw.Raw))
+
+	shape.Nodes++
+	shape.Bytes += size
+	for len(shape.Depths) <= depth {
+		shape.Depths = append(shape.Depths, 0)
+	}
+	shape.Depths[depth]++
+	if _, ok := w.seen[c]; !ok {
+		w.seen[c] = struct{}{}
+		w.blocks = append(w.blocks, Block{Cid: c, Size: size, Depth: depth, Collection: shape.Name})
+	}
+
+	var links []cid.Cid
+	err := cbg.ScanForLinks(bytes.NewReader(raw.Raw), func(link cid.Cid) {
+		// Only dag-cbor blocks are stored; other links (e.g. piece commitments) are opaque.
+		if link.Prefix().Codec == cid.DagCBOR {
+			links = append(links, link)
+		}
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("failed to scan links of %v: %w", c, err)
+	}
+	return links, nil
+}
+
+// Maps the values of a state's exported cid.Cid fields to the field names.
+func fieldNames(state interface{}) map[cid.Cid]string {
+	names := map[cid.Cid]string{}
+	v := reflect.Indirect(reflect.ValueOf(state))
+	if v.Kind() != reflect.Struct {
+		return names
+	}
+	cidType := reflect.TypeOf(cid.Undef)
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		if f.PkgPath != "" || f.Type != cidType {
+			continue
+		}
+		c := v.Field(i).Interface().(cid.Cid)
+		if existing, ok := names[c]; ok {
+			// Distinct fields may hold the same cid, e.g. empty collections.
+			names[c] = existing + "," + f.Name
+		} else {
+			names[c] = f.Name
+		}
+	}
+	return names
+}
@@ -0,0 +1,97 @@
+package profile_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin/market"
+	"github.com/filecoin-project/specs-actors/v3/actors/util/adt"
+	"github.com/filecoin-project/specs-actors/v3/support/ipld"
+	"github.com/filecoin-project/specs-actors/v3/support/profile"
+	tutil "github.com/filecoin-project/specs-actors/v3/support/testing"
+)
+
+func TestProfileState(t *testing.T) {
+	store := ipld.NewADTStore(context.Background())
+	st, err := market.ConstructState(store)
+	require.NoError(t, err)
+
+	escrow, err := adt.AsBalanceTable(store, st.EscrowTable)
+	require.NoError(t, err)
+	for i := 0; i < 1000; i++ {
+		require.NoError(t, escrow.Add(tutil.NewIDAddr(t, uint64(1000+i)), abi.NewTokenAmount(1)))
+	}
+	st.EscrowTable, err = escrow.Root()
+	require.NoError(t, err)
+	root, err := store.Put(context.Background(), st)
+	require.NoError(t, err)
+
+	p, err := profile.ProfileState(store, root, &market.State{}, 3)
+	require.NoError(t, err)
+	assert.Equal(t, root, p.Root)
+
+	rootShape, ok := p.Collection(profile.RootCollection)
+	require.True(t, ok)
+	assert.Equal(t, uint64(1), rootShape.Nodes)
+	assert.Equal(t, 0, rootShape.MaxDepth())
+
+	escrowShape, ok := p.Collection("EscrowTable")
+	require.True(t, ok)
+	assert.Equal(t, st.EscrowTable, escrowShape.Root)
+	assert.Greater(t, escrowShape.Nodes, uint64(1))
+	assert.Greater(t, escrowShape.MaxDepth(), 0)
+	assert.Equal(t, uint64(1), escrowShape.Depths[0])
+	var sum uint64
+	for _, n := range escrowShape.Depths {
+		sum += n
+	}
+	assert.Equal(t, escrowShape.Nodes, sum)
+
+	// The empty locked table shares its cid with other empty maps.
+	_, ok = p.Collection("LockedTable")
+	assert.False(t, ok)
+
+	require.Len(t, p.Largest, 3)
+	assert.GreaterOrEqual(t, p.Largest[0].Size, p.Largest[1].Size)
+	assert.GreaterOrEqual(t, p.Largest[1].Size, p.Largest[2].Size)
+	assert.Greater(t, p.TotalBytes, escrowShape.Bytes)
+	assert.Contains(t, p.String(), "EscrowTable")
+}
+
+func TestRequireStateSmallerThan(t *testing.T) {
+	store := ipld.NewADTStore(context.Background())
+	st, err := market.ConstructState(store)
+	require.NoError(t, err)
+	root, err := store.Put(context.Background(), st)
+	require.NoError(t, err)
+
+	p, err := profile.ProfileState(store, root, nil, 0)
+	require.NoError(t, err)
+	assert.Empty(t, p.Largest)
+	assert.Equal(t, "link0", p.Collections[1].Name)
+
+	profile.RequireStateSmallerThan(t, store, root, p.TotalBytes+1)
+
+	ft := &failureRecorder{}
+	profile.RequireStateSmallerThan(ft, store, root, p.TotalBytes)
+	assert.True(t, ft.failed)
+	assert.Contains(t, ft.msg, "state exceeds")
+}
+
+type failureRecorder struct {
+	failed bool
+	msg    string
+}
+
+func (f *failureRecorder) Errorf(format string, args ...interface{}) {
+	f.msg += fmt.Sprintf(format, args...)
+}
+
+func (f *failureRecorder) FailNow() {
+	f.failed = true
+}
@@ -0,0 +1,120 @@
+package sim
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin/power"
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin/reward"
+	"github.com/filecoin-project/specs-actors/v3/support/agent"
+)
+
+// EpochMetrics records the economic state of a simulated network at the end of an epoch.
+// Token amounts are cumulative from the network's genesis.
+type EpochMetrics struct {
+	Epoch    abi.ChainEpoch
+	Miners   int
+	RawPower abi.StoragePower
+	QAPower  abi.StoragePower
+	// Block rewards minted for storage miners.
+	Minted abi.TokenAmount
+	// Reward to be minted in the next epoch, shared among its winning blocks.
+	EpochReward abi.TokenAmount
+	// Initial pledge locked by miners for their sectors.
+	PledgeLocked abi.TokenAmount
+	// Balance of the burnt funds actor: penalties, forfeited pre-commit deposits and slashed collateral.
+	Burned abi.TokenAmount
+	// Circulating supply, computed as by the simulation from the amounts above.
+	CirculatingSupply abi.TokenAmount
+}
+
+// Measures the metrics of the current state of a simulation, attributing them to an epoch.
+func MeasureEpoch(s *agent.Sim, epoch abi.ChainEpoch) (EpochMetrics, error) {
+	var rewardSt reward.State
+	if err := s.GetState(builtin.RewardActorAddr, &rewardSt); err != nil {
+		return EpochMetrics{}, xerrors.Errorf("failed to load reward state: %w", err)
+	}
+	var powerSt power.State
+	if err := s.GetState(builtin.StoragePowerActorAddr, &powerSt); err != nil {
+		return EpochMetrics{}, xerrors.Errorf("failed to load power state: %w", err)
+	}
+	burnt, found, err := s.GetVM().GetActor(builtin.BurntFundsActorAddr)
+	if err != nil {
+		return EpochMetrics{}, err
+	}
+	if !found {
+		return EpochMetrics{}, xerrors.Errorf("burnt funds actor not found at %v", builtin.BurntFundsActorAddr)
+	}
+
+	miners := 0
+	for _, a := range s.Agents {
+		if _, ok := a.(*agent.MinerAgent); ok {
+			miners++
+		}
+	}
+
+	return EpochMetrics{
+		Epoch:        epoch,
+		Miners:       miners,
+		RawPower:     powerSt.TotalRawBytePower,
+		QAPower:      powerSt.TotalQualityAdjPower,
+		Minted:       rewardSt.TotalStoragePowerReward,
+		EpochReward:  rewardSt.ThisEpochReward,
+		PledgeLocked: powerSt.TotalPledgeCollateral,
+		Burned:       burnt.Balance,
+		CirculatingSupply: big.Sum(agent.DisbursedAmount, rewardSt.TotalStoragePowerReward,
+			powerSt.TotalPledgeCollateral.Neg(), burnt.Balance.Neg()),
+	}, nil
+}
+
+var csvHeader = []string{
+	"epoch", "miners", "raw_power", "qa_power", "minted", "epoch_reward", "pledge_locked", "burned", "circulating_supply",
+}
+
+// WriteCSV writes metrics as CSV, with a header row and one row per epoch. Token amounts are in attoFIL.
+func WriteCSV(w io.Writer, metrics []EpochMetrics) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, m := range metrics {
+		if err := cw.Write([]string{
+			strconv.FormatInt(int64(m.Epoch), 10),
+			strconv.Itoa(m.Miners),
+			m.RawPower.String(),
+			m.QAPower.String(),
+			m.Minted.String(),
+			m.EpochReward.String(),
+			m.PledgeLocked.String(),
+			m.Burned.String(),
+			m.CirculatingSupply.String(),
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON serializes metrics as a JSON array, one object per epoch.
+func WriteJSON(w io.Writer, metrics []EpochMetrics) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(metrics)
+}
+
+// ReadJSON deserializes metrics written by WriteJSON.
+func ReadJSON(r io.Reader) ([]EpochMetrics, error) {
+	var metrics []EpochMetrics
+	if err := json.NewDecoder(r).Decode(&metrics); err != nil {
+		return nil, err
+	}
+	return metrics, nil
+}
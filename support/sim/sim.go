@@ -0,0 +1,81 @@
+package sim
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	ipldcbor "github.com/ipfs/go-ipld-cbor"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/specs-actors/v3/support/agent"
+	"github.com/filecoin-project/specs-actors/v3/support/ipld"
+	vm "github.com/filecoin-project/specs-actors/v3/support/vm"
+)
+
+// Cohort is a group of miners that join the network at a common rate and share an onboarding and fault profile.
+// The miners' sector onboarding rate, fault and recovery rates and starting balance are set by the agent
+// configuration.
+type Cohort struct {
+	Miners          int     // Number of miners in the cohort.
+	CreateMinerRate float64 // Average number of the cohort's miners created per epoch, until all are created.
+	// Initial balance of each miner's owner account, which must cover the miner's starting balance and
+	// any market balance.
+	AccountBalance abi.TokenAmount
+	Miner          agent.MinerAgentConfig
+}
+
+type Config struct {
+	Seed    int64
+	Cohorts []Cohort
+	// Interval in epochs at which the state is copied to a fresh blockstore, releasing unreachable blocks.
+	// Zero never copies the state.
+	CheckpointEpochs uint64
+}
+
+// Simulation runs the reward, power and miner actors forward over simulated epochs, driven by agents
+// onboarding and faulting sectors, and records the network's economic metrics at each epoch.
+// This keeps models of token supply, pledge and penalties in step with the actor code rather than
+// re-deriving the formulas elsewhere.
+type Simulation struct {
+	Sim     *agent.Sim
+	Metrics []EpochMetrics
+}
+
+// Creates a simulation of a network with the singleton actors, and owner accounts for the miners of each cohort.
+func NewSimulation(ctx context.Context, t testing.TB, config Config) *Simulation {
+	rnd := rand.New(rand.NewSource(config.Seed))
+	s := agent.NewSim(ctx, t, newBlockStore, agent.SimConfig{
+		Seed:             rnd.Int63(),
+		CheckpointEpochs: config.CheckpointEpochs,
+	})
+	v, ok := s.GetVM().(*vm.VM)
+	require.True(t, ok, "simulation VM is not a v3 VM")
+
+	for _, c := range config.Cohorts {
+		accounts := vm.CreateAccounts(ctx, t, v, c.Miners, c.AccountBalance, rnd.Int63())
+		s.AddAgent(agent.NewMinerGenerator(accounts, c.Miner, c.CreateMinerRate, rnd.Int63()))
+	}
+	return &Simulation{Sim: s}
+}
+
+// Advances the simulation by a number of epochs, recording the metrics at the end of each.
+func (s *Simulation) Run(epochs int) error {
+	for i := 0; i < epochs; i++ {
+		if err := s.Sim.Tick(); err != nil {
+			return err
+		}
+		// The tick leaves the simulation at the next epoch, with the state resulting from the epoch just run.
+		m, err := MeasureEpoch(s.Sim, s.Sim.GetEpoch()-1)
+		if err != nil {
+			return err
+		}
+		s.Metrics = append(s.Metrics, m)
+	}
+	return nil
+}
+
+func newBlockStore() ipldcbor.IpldBlockstore {
+	return ipld.NewBlockStoreInMemory()
+}
@@ -0,0 +1,91 @@
+package sim_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin/miner"
+	"github.com/filecoin-project/specs-actors/v3/support/agent"
+	"github.com/filecoin-project/specs-actors/v3/support/sim"
+)
+
+func TestSimulationMetrics(t *testing.T) {
+	ctx := context.Background()
+	balance := big.Mul(big.NewInt(1e6), big.NewInt(1e18))
+	s := sim.NewSimulation(ctx, t, sim.Config{
+		Seed: 42,
+		Cohorts: []sim.Cohort{{
+			Miners:          3,
+			CreateMinerRate: 1.0,
+			AccountBalance:  balance,
+			Miner: agent.MinerAgentConfig{
+				PrecommitRate:    1.0,
+				FaultRate:        0.0001,
+				RecoveryRate:     0.0001,
+				ProofType:        abi.RegisteredSealProof_StackedDrg32GiBV1_1,
+				StartingBalance:  balance,
+				MinMarketBalance: big.Zero(),
+				MaxMarketBalance: big.Zero(),
+			},
+		}},
+	})
+
+	// Long enough for sectors to be proven and then pass their first Window PoSt to gain power,
+	// so that blocks are won and rewarded.
+	epochs := int(miner.PreCommitChallengeDelay+miner.WPoStProvingPeriod) + 200
+	require.NoError(t, s.Run(epochs))
+	require.Len(t, s.Metrics, epochs)
+
+	start := s.Metrics[0].Epoch
+	for i, m := range s.Metrics {
+		assert.Equal(t, start+abi.ChainEpoch(i), m.Epoch)
+		if i > 0 {
+			prev := s.Metrics[i-1]
+			assert.True(t, m.Minted.GreaterThanEqual(prev.Minted), "minted decreased at epoch %d", m.Epoch)
+			assert.True(t, m.Burned.GreaterThanEqual(prev.Burned), "burned decreased at epoch %d", m.Epoch)
+		}
+		assert.Equal(t, big.Sum(agent.DisbursedAmount, m.Minted, m.PledgeLocked.Neg(), m.Burned.Neg()), m.CirculatingSupply)
+	}
+
+	last := s.Metrics[epochs-1]
+	assert.Equal(t, 3, last.Miners)
+	assert.True(t, last.RawPower.GreaterThan(big.Zero()))
+	assert.True(t, last.QAPower.GreaterThan(big.Zero()))
+	assert.True(t, last.Minted.GreaterThan(big.Zero()))
+	assert.True(t, last.PledgeLocked.GreaterThan(big.Zero()))
+
+	t.Run("write csv", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, sim.WriteCSV(&buf, s.Metrics))
+		rows, err := csv.NewReader(&buf).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, rows, epochs+1)
+		assert.Equal(t, []string{
+			"epoch", "miners", "raw_power", "qa_power", "minted", "epoch_reward", "pledge_locked", "burned", "circulating_supply",
+		}, rows[0])
+		assert.Equal(t, last.Minted.String(), rows[epochs][4])
+		assert.Equal(t, last.PledgeLocked.String(), rows[epochs][6])
+	})
+
+	t.Run("json round trip", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, sim.WriteJSON(&buf, s.Metrics))
+		written := buf.String()
+		metrics, err := sim.ReadJSON(&buf)
+		require.NoError(t, err)
+		require.Len(t, metrics, epochs)
+		assert.True(t, last.Minted.Equals(metrics[epochs-1].Minted))
+
+		// Big integers may decode with a different internal representation, so compare encodings.
+		var rewritten bytes.Buffer
+		require.NoError(t, sim.WriteJSON(&rewritten, metrics))
+		assert.Equal(t, written, rewritten.String())
+	})
+}
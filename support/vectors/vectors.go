@@ -0,0 +1,172 @@
+// Package vectors produces golden CBOR encodings of the builtin actors' states and method parameters and return
+// values, as fixtures against which other implementations of the actors can check their encodings.
+package vectors
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"reflect"
+	goruntime "runtime"
+	"strings"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/cbor"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin/exported"
+	"github.com/filecoin-project/specs-actors/v3/support/fuzz"
+)
+
+// Kinds of value encoded by a vector.
+const (
+	KindState  = "state"
+	KindParams = "params"
+	KindReturn = "return"
+)
+
+// A Vector is the CBOR encoding of a value of one actor type.
+type Vector struct {
+	Actor  string          // Name of the actor, as builtin.ActorNameByCode
+	Method string          // Name of the method taking or returning the value, empty for the actor state
+	Kind   string          // KindState, KindParams or KindReturn
+	Type   string          // Go type of the value
+	Value  json.RawMessage // The value, rendered as JSON for inspection
+	CBOR   string          // Hex encoding of the value's CBOR, empty for a method taking or returning no value
+}
+
+var typeEmptyValue = reflect.TypeOf(abi.EmptyValue{})
+
+// Generates a vector for the state of each builtin actor, and for the parameters and return value of each of its
+// exported methods, in actor and method order.
+// The value of each vector is generated deterministically from its actor, method and type, so vectors are stable
+// unless the type or its encoding changes.
+// Each encoding is checked to decode to a value which re-encodes identically.
+func Generate() ([]Vector, error) {
+	var vectors []Vector
+	for _, actor := range exported.BuiltinActors() {
+		name := builtin.ActorNameByCode(actor.Code())
+
+		v, err := makeVector(name, "", KindState, reflect.TypeOf(actor.State()))
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, v)
+
+		for num, export := range actor.Exports() {
+			if num == int(builtin.MethodSend) || export == nil {
+				continue
+			}
+			method := methodName(export)
+			fn := reflect.TypeOf(export)
+			for _, side := range []struct {
+				kind string
+				typ  reflect.Type
+			}{{KindParams, fn.In(1)}, {KindReturn, fn.Out(0)}} {
+				v, err := makeVector(name, method, side.kind, side.typ)
+				if err != nil {
+					return nil, err
+				}
+				vectors = append(vectors, v)
+			}
+		}
+	}
+	return vectors, nil
+}
+
+// Writes vectors as an indented JSON array.
+func WriteJSON(w io.Writer, vectors []Vector) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(vectors)
+}
+
+// Generates and encodes a value of typ, which is a pointer to the type of the value.
+// A method taking or returning abi.EmptyValue is passed nil, which is encoded as no bytes at all.
+func makeVector(actor, method, kind string, typ reflect.Type) (Vector, error) {
+	if typ.Kind() != reflect.Ptr {
+		return Vector{}, fmt.Errorf("%s %s %s type %v is not a pointer", actor, method, kind, typ)
+	}
+	typeName := typ.Elem().String()
+	if typ.Elem() == typeEmptyValue {
+		return Vector{
+			Actor:  actor,
+			Method: method,
+			Kind:   kind,
+			Type:   typeName,
+			Value:  json.RawMessage("null"),
+			CBOR:   "",
+		}, nil
+	}
+
+	// Seed the generator from the vector's identity so that each value is independent of the others.
+	h := fnv.New64a()
+	_, _ = fmt.Fprintf(h, "%s/%s/%s/%s", actor, method, kind, typeName)
+	gen := fuzz.NewGenerator(int64(h.Sum64()), nil)
+
+	value := reflect.New(typ.Elem())
+	value.Elem().Set(gen.Value(typ.Elem()))
+
+	encoded, err := encode(value.Interface())
+	if err != nil {
+		return Vector{}, fmt.Errorf("failed to encode %s %s %s %s: %w", actor, method, kind, typeName, err)
+	}
+	if err := checkRoundTrip(typ.Elem(), encoded); err != nil {
+		return Vector{}, fmt.Errorf("%s %s %s %s: %w", actor, method, kind, typeName, err)
+	}
+	rendered, err := json.Marshal(value.Interface())
+	if err != nil {
+		return Vector{}, fmt.Errorf("failed to render %s %s %s %s: %w", actor, method, kind, typeName, err)
+	}
+
+	return Vector{
+		Actor:  actor,
+		Method: method,
+		Kind:   kind,
+		Type:   typeName,
+		Value:  rendered,
+		CBOR:   hex.EncodeToString(encoded),
+	}, nil
+}
+
+func encode(v interface{}) ([]byte, error) {
+	m, ok := v.(cbor.Marshaler)
+	if !ok {
+		return nil, fmt.Errorf("type %T is not a cbor marshaler", v)
+	}
+	var buf bytes.Buffer
+	if err := m.MarshalCBOR(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Checks that an encoding decodes to a value of typ that re-encodes to the same bytes.
+func checkRoundTrip(typ reflect.Type, encoded []byte) error {
+	decoded := reflect.New(typ).Interface()
+	um, ok := decoded.(cbor.Unmarshaler)
+	if !ok {
+		return fmt.Errorf("type %T is not a cbor unmarshaler", decoded)
+	}
+	if err := um.UnmarshalCBOR(bytes.NewReader(encoded)); err != nil {
+		return fmt.Errorf("failed to decode: %w", err)
+	}
+	reencoded, err := encode(decoded)
+	if err != nil {
+		return fmt.Errorf("failed to re-encode: %w", err)
+	}
+	if !bytes.Equal(encoded, reencoded) {
+		return fmt.Errorf("re-encoding %x differs from encoding %x", reencoded, encoded)
+	}
+	return nil
+}
+
+// Returns the name of an exported actor method, e.g. "SubmitWindowedPoSt".
+func methodName(export interface{}) string {
+	name := goruntime.FuncForPC(reflect.ValueOf(export).Pointer()).Name()
+	name = strings.TrimSuffix(name, "-fm")
+	return name[strings.LastIndex(name, ".")+1:]
+}
@@ -0,0 +1,39 @@
+package vectors_test
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xorcare/golden"
+
+	"github.com/filecoin-project/specs-actors/v3/support/vectors"
+)
+
+const goldenFile = "testdata/TestVectors.golden"
+
+// Fails if any encoding differs from the golden vectors.
+// After an intentional change to an encoding, regenerate the vectors with `make vectors`.
+func TestVectors(t *testing.T) {
+	vs, err := vectors.Generate()
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, vectors.WriteJSON(&buf, vs))
+
+	if update := flag.Lookup("update"); update == nil || update.Value.String() != "true" {
+		_, err := os.Stat(goldenFile)
+		require.NoError(t, err, "no golden vectors at %s; generate them with `make vectors`", goldenFile)
+	}
+	golden.Assert(t, buf.Bytes())
+}
+
+func TestVectorsAreDeterministic(t *testing.T) {
+	first, err := vectors.Generate()
+	require.NoError(t, err)
+	second, err := vectors.Generate()
+	require.NoError(t, err)
+	require.Equal(t, first, second)
+}
@@ -0,0 +1,77 @@
+package vm_test
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/crypto"
+	"github.com/minio/blake2b-simd"
+)
+
+// A RandomnessSource supplies the randomness drawn by actors for a domain separation tag, epoch and entropy.
+type RandomnessSource func(tag crypto.DomainSeparationTag, epoch abi.ChainEpoch, entropy []byte) abi.Randomness
+
+// Returns a source drawing the same randomness for every tag, epoch and entropy.
+// This is the VM's default source, for both beacon and ticket randomness.
+func FixedRandomness(r abi.Randomness) RandomnessSource {
+	return func(_ crypto.DomainSeparationTag, _ abi.ChainEpoch, _ []byte) abi.Randomness {
+		return r
+	}
+}
+
+// Returns a source deriving 32 bytes of pseudo-randomness from a seed and the tag, epoch and entropy of each draw.
+// Draws with the same arguments agree, while draws differing in any argument (almost certainly) differ.
+func DeterministicRandomness(seed []byte) RandomnessSource {
+	return func(tag crypto.DomainSeparationTag, epoch abi.ChainEpoch, entropy []byte) abi.Randomness {
+		var buf bytes.Buffer
+		buf.Write(seed)
+		_ = binary.Write(&buf, binary.BigEndian, int64(tag))
+		_ = binary.Write(&buf, binary.BigEndian, int64(epoch))
+		buf.Write(entropy)
+		digest := blake2b.Sum256(buf.Bytes())
+		return digest[:]
+	}
+}
+
+// TipsetContext supplies values that the chain determines per tipset, evaluated at the epoch of each top-level
+// message. A nil function leaves the VM's default in place.
+type TipsetContext struct {
+	BaseFee           func(epoch abi.ChainEpoch) abi.TokenAmount
+	CirculatingSupply func(epoch abi.ChainEpoch) abi.TokenAmount
+}
+
+// The randomness and tipset context of a VM, shared by the VMs derived from it.
+type chainContext struct {
+	beaconRandomness RandomnessSource
+	ticketRandomness RandomnessSource
+	tipset           TipsetContext
+}
+
+func defaultChainContext() chainContext {
+	notRandom := FixedRandomness([]byte("not really random"))
+	return chainContext{
+		beaconRandomness: notRandom,
+		ticketRandomness: notRandom,
+	}
+}
+
+// Sets the sources of beacon and ticket randomness drawn by actors.
+func (vm *VM) SetRandomness(beacon, tickets RandomnessSource) {
+	vm.chain.beaconRandomness = beacon
+	vm.chain.ticketRandomness = tickets
+}
+
+// Sets the tipset context, overriding the circulating supply set with SetCirculatingSupply if it supplies one.
+func (vm *VM) SetTipsetContext(tipset TipsetContext) {
+	vm.chain.tipset = tipset
+}
+
+// Returns the base fee at the VM's current epoch, which is zero unless supplied by the tipset context.
+func (vm *VM) BaseFee() abi.TokenAmount {
+	if vm.chain.tipset.BaseFee != nil {
+		return vm.chain.tipset.BaseFee(vm.currentEpoch)
+	}
+	return big.Zero()
+}
@@ -0,0 +1,233 @@
+package vm_test
+
+import (
+	"bytes"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/cbor"
+	"github.com/filecoin-project/go-state-types/crypto"
+	"github.com/filecoin-project/go-state-types/exitcode"
+
+	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
+	"github.com/filecoin-project/specs-actors/v3/actors/runtime/proof"
+)
+
+// The gas limit of a message when metering, unless set otherwise: that of an entire block.
+const BlockGasLimit = int64(10_000_000_000)
+
+// Approximate encoded size of the fields of a message other than its parameters, which are counted exactly.
+// The VM doesn't construct chain messages, so this stands in for the addresses, nonce, value and gas fields.
+const messageHeaderSize = 100
+
+// PriceList prices the operations performed while executing a message, in units of gas.
+// An implementation mirrors the gas schedule of some network version, so that tests may assert the gas used
+// by messages and detect changes in the sends, state reads and writes made deep within call chains.
+type PriceList interface {
+	// Gas charged for including a message of the given encoded size in a block.
+	OnChainMessage(msgSize int) int64
+	// Gas charged for the encoded return value of a top-level message.
+	OnChainReturnValue(dataSize int) int64
+	// Gas charged for each invocation, including that of a top-level message, which may transfer value.
+	OnMethodInvocation(value abi.TokenAmount, methodNum abi.MethodNum) int64
+
+	OnIpldGet() int64
+	OnIpldPut(dataSize int) int64
+	OnCreateActor() int64
+	OnDeleteActor() int64
+
+	OnVerifySignature(sigType crypto.SigType, planTextSize int) int64
+	OnHashing(dataSize int) int64
+	OnComputeUnsealedSectorCid(proofType abi.RegisteredSealProof, pieces []abi.PieceInfo) int64
+	OnVerifySeal(info proof.SealVerifyInfo) int64
+	OnVerifyPost(info proof.WindowPoStVerifyInfo) int64
+	OnVerifyConsensusFault() int64
+}
+
+// A PriceList computed from a table of base prices, in the form of the on-chain gas schedule.
+// Compute prices are scaled by ComputeGasMulti, and storage prices by StorageGasMulti.
+// Unset prices are free.
+type ScheduledPriceList struct {
+	ComputeGasMulti int64
+	StorageGasMulti int64
+
+	OnChainMessageComputeBase    int64
+	OnChainMessageStorageBase    int64
+	OnChainMessageStoragePerByte int64
+	OnChainReturnValuePerByte    int64
+
+	SendBase                int64
+	SendTransferFunds       int64
+	SendTransferOnlyPremium int64
+	SendInvokeMethod        int64
+
+	IpldGetBase    int64
+	IpldPutBase    int64
+	IpldPutPerByte int64
+
+	CreateActorCompute int64
+	CreateActorStorage int64
+	DeleteActor        int64
+
+	VerifySignature              map[crypto.SigType]int64
+	HashingBase                  int64
+	ComputeUnsealedSectorCidBase int64
+	VerifySealBase               int64
+	VerifyPostFlat               int64
+	VerifyPostPerSector          int64
+	VerifyConsensusFault         int64
+}
+
+var _ PriceList = ScheduledPriceList{}
+
+// Prices approximating the on-chain gas schedule in effect for this version of the actors.
+// Window PoSt verification is priced for 32GiB sectors, whatever the proof type.
+var DefaultPriceList = ScheduledPriceList{
+	ComputeGasMulti: 1,
+	StorageGasMulti: 1300,
+
+	OnChainMessageComputeBase:    38863,
+	OnChainMessageStorageBase:    36,
+	OnChainMessageStoragePerByte: 1,
+	OnChainReturnValuePerByte:    1,
+
+	SendBase:                29233,
+	SendTransferFunds:       27500,
+	SendTransferOnlyPremium: 159672,
+	SendInvokeMethod:        -5377,
+
+	IpldGetBase:    114617,
+	IpldPutBase:    353640,
+	IpldPutPerByte: 1,
+
+	CreateActorCompute: 1108454,
+	CreateActorStorage: 36 + 40,
+	DeleteActor:        -(36 + 40),
+
+	VerifySignature: map[crypto.SigType]int64{
+		crypto.SigTypeBLS:       16598605,
+		crypto.SigTypeSecp256k1: 1637292,
+	},
+	HashingBase:                  31355,
+	ComputeUnsealedSectorCidBase: 98647,
+	VerifySealBase:               2000,
+	VerifyPostFlat:               123861062,
+	VerifyPostPerSector:          9226981,
+	VerifyConsensusFault:         495422,
+}
+
+func (pl ScheduledPriceList) compute(gas int64) int64 {
+	return gas * pl.ComputeGasMulti
+}
+
+func (pl ScheduledPriceList) storage(gas int64) int64 {
+	return gas * pl.StorageGasMulti
+}
+
+func (pl ScheduledPriceList) OnChainMessage(msgSize int) int64 {
+	return pl.compute(pl.OnChainMessageComputeBase) +
+		pl.storage(pl.OnChainMessageStorageBase+pl.OnChainMessageStoragePerByte*int64(msgSize))
+}
+
+func (pl ScheduledPriceList) OnChainReturnValue(dataSize int) int64 {
+	return pl.storage(int64(dataSize) * pl.OnChainReturnValuePerByte)
+}
+
+func (pl ScheduledPriceList) OnMethodInvocation(value abi.TokenAmount, methodNum abi.MethodNum) int64 {
+	gas := pl.SendBase
+	if !value.NilOrZero() {
+		gas += pl.SendTransferFunds
+		if methodNum == builtin.MethodSend {
+			gas += pl.SendTransferOnlyPremium
+		}
+	}
+	if methodNum != builtin.MethodSend {
+		gas += pl.SendInvokeMethod
+	}
+	return pl.compute(gas)
+}
+
+func (pl ScheduledPriceList) OnIpldGet() int64 {
+	return pl.compute(pl.IpldGetBase)
+}
+
+func (pl ScheduledPriceList) OnIpldPut(dataSize int) int64 {
+	return pl.compute(pl.IpldPutBase) + pl.storage(int64(dataSize)*pl.IpldPutPerByte)
+}
+
+func (pl ScheduledPriceList) OnCreateActor() int64 {
+	return pl.compute(pl.CreateActorCompute) + pl.storage(pl.CreateActorStorage)
+}
+
+func (pl ScheduledPriceList) OnDeleteActor() int64 {
+	return pl.storage(pl.DeleteActor)
+}
+
+func (pl ScheduledPriceList) OnVerifySignature(sigType crypto.SigType, _ int) int64 {
+	return pl.compute(pl.VerifySignature[sigType])
+}
+
+func (pl ScheduledPriceList) OnHashing(_ int) int64 {
+	return pl.compute(pl.HashingBase)
+}
+
+func (pl ScheduledPriceList) OnComputeUnsealedSectorCid(_ abi.RegisteredSealProof, _ []abi.PieceInfo) int64 {
+	return pl.compute(pl.ComputeUnsealedSectorCidBase)
+}
+
+func (pl ScheduledPriceList) OnVerifySeal(_ proof.SealVerifyInfo) int64 {
+	return pl.compute(pl.VerifySealBase)
+}
+
+func (pl ScheduledPriceList) OnVerifyPost(info proof.WindowPoStVerifyInfo) int64 {
+	return pl.compute(pl.VerifyPostFlat + pl.VerifyPostPerSector*int64(len(info.ChallengedSectors)))
+}
+
+func (pl ScheduledPriceList) OnVerifyConsensusFault() int64 {
+	return pl.compute(pl.VerifyConsensusFault)
+}
+
+// Tracks the gas used by a top-level message and its internal sends.
+// A nil tracker meters nothing.
+type gasTracker struct {
+	prices    PriceList
+	limit     int64
+	used      int64
+	exhausted bool
+}
+
+// Charges gas, aborting with SysErrOutOfGas if the limit is exceeded.
+// Once exhausted, the tracker's gas remains used up so that any further positive charge also aborts.
+func (g *gasTracker) charge(vm *VM, name string, amount int64) {
+	if g == nil {
+		return
+	}
+	if !g.tryCharge(amount) {
+		vm.Abortf(exitcode.SysErrOutOfGas, "not enough gas for %s: used %d, limit %d", name, g.used, g.limit)
+	}
+}
+
+func (g *gasTracker) tryCharge(amount int64) bool {
+	if g.used+amount > g.limit {
+		g.used = g.limit
+		g.exhausted = true
+		return false
+	}
+	g.used += amount
+	return true
+}
+
+// Returns the size of an object's encoding, or zero if it can't be encoded.
+func encodedSize(obj interface{}) int {
+	if raw, ok := obj.([]byte); ok {
+		return len(raw)
+	}
+	m, ok := obj.(cbor.Marshaler)
+	if !ok {
+		return 0
+	}
+	var buf bytes.Buffer
+	if err := m.MarshalCBOR(&buf); err != nil {
+		return 0
+	}
+	return buf.Len()
+}
@@ -19,6 +19,7 @@ import (
 	vm2 "github.com/filecoin-project/specs-actors/v2/support/vm"
 	"github.com/ipfs/go-cid"
 	"github.com/minio/blake2b-simd"
+	mh "github.com/multiformats/go-multihash"
 	"github.com/pkg/errors"
 
 	"github.com/filecoin-project/specs-actors/v3/actors/builtin"
@@ -27,6 +28,7 @@ import (
 	"github.com/filecoin-project/specs-actors/v3/actors/runtime/proof"
 	"github.com/filecoin-project/specs-actors/v3/actors/states"
 	"github.com/filecoin-project/specs-actors/v3/actors/util/adt"
+	"github.com/filecoin-project/specs-actors/v3/support/dlog"
 	"github.com/filecoin-project/specs-actors/v3/support/ipld"
 	"github.com/filecoin-project/specs-actors/v3/support/testing"
 )
@@ -43,6 +45,7 @@ type invocationContext struct {
 	emptyObject      cid.Cid
 	allowSideEffects bool
 	callerValidated  bool
+	readOnly         bool // Set for the invocation of a static send, and inherited by any messages it sends
 	// Maps (references to) loaded state objs to their expected cid.
 	// Used for detecting modifications to state outside of transactions.
 	stateUsedObjs map[cbor.Marshaler]cid.Cid
@@ -56,6 +59,8 @@ type topLevelContext struct {
 	newActorAddressCount    uint64          // Count of calls to NewActorAddress (mutable).
 	statsSource             StatsSource     // optional source of external statistics that can be used to profile calls
 	circSupply              abi.TokenAmount // default or externally specified circulating FIL supply
+	baseFee                 abi.TokenAmount // base fee of the tipset including the top-level message
+	gas                     *gasTracker     // gas used by the top-level message, or nil if not metered
 }
 
 func newInvocationContext(rt *VM, topLevel *topLevelContext, msg InternalMessage, fromActor *states.Actor, emptyObject cid.Cid) invocationContext {
@@ -84,6 +89,7 @@ func (ic *invocationContext) loadState(obj cbor.Unmarshaler) cid.Cid {
 	if !c.Defined() {
 		ic.Abortf(exitcode.SysErrorIllegalActor, "failed to load undefined state, must construct first")
 	}
+	ic.chargeGas("OnIpldGet", func(p PriceList) int64 { return p.OnIpldGet() })
 	err := ic.rt.store.Get(ic.rt.ctx, c, obj)
 	if err != nil {
 		panic(errors.Wrapf(err, "failed to load state for actor %s, CID %s", ic.msg.to, c))
@@ -117,12 +123,15 @@ var _ runtime.Runtime = (*invocationContext)(nil)
 
 // Store implements runtime.Runtime.
 func (ic *invocationContext) StoreGet(c cid.Cid, o cbor.Unmarshaler) bool {
+	ic.chargeGas("OnIpldGet", func(p PriceList) int64 { return p.OnIpldGet() })
 	sw := &storeWrapper{s: ic.rt.store, rt: ic.rt}
 	return sw.StoreGet(c, o)
 }
 
 func (ic *invocationContext) StorePut(x cbor.Marshaler) cid.Cid {
+	ic.chargeGas("OnIpldPut", func(p PriceList) int64 { return p.OnIpldPut(encodedSize(x)) })
 	sw := &storeWrapper{s: ic.rt.store, rt: ic.rt}
+	ic.recordStateWrite(x)
 	return sw.StorePut(x)
 }
 
@@ -147,6 +156,9 @@ func (ic *invocationContext) StateCreate(obj cbor.Marshaler) {
 	if actr.Head.Defined() && !ic.emptyObject.Equals(actr.Head) {
 		ic.Abortf(exitcode.SysErrorIllegalActor, "failed to construct actor state: already initialized")
 	}
+	ic.requireWritable("create state")
+	ic.chargeGas("OnIpldPut", func(p PriceList) int64 { return p.OnIpldPut(encodedSize(obj)) })
+	ic.recordStateWrite(obj)
 	c, err := ic.rt.store.Put(ic.rt.ctx, obj)
 	if err != nil {
 		ic.Abortf(exitcode.ErrIllegalState, "failed to create actor state")
@@ -168,6 +180,10 @@ func (ic *invocationContext) StateTransaction(obj cbor.Er, f func()) {
 	if obj == nil {
 		ic.Abortf(exitcode.SysErrorIllegalActor, "Must not pass nil to Transaction()")
 	}
+	if !ic.allowSideEffects {
+		ic.Abortf(exitcode.SysErrorIllegalActor, "nested transaction")
+	}
+	ic.requireWritable("transaction")
 	ic.checkStateObjectsUnmodified()
 
 	// Load state to obj.
@@ -183,18 +199,27 @@ func (ic *invocationContext) StateTransaction(obj cbor.Er, f func()) {
 }
 
 func (ic *invocationContext) VerifySignature(signature crypto.Signature, signer address.Address, plaintext []byte) error {
+	ic.chargeGas("OnVerifySignature", func(p PriceList) int64 { return p.OnVerifySignature(signature.Type, len(plaintext)) })
 	return ic.Syscalls().VerifySignature(signature, signer, plaintext)
 }
 
 func (ic *invocationContext) HashBlake2b(data []byte) [32]byte {
+	ic.chargeGas("OnHashing", func(p PriceList) int64 { return p.OnHashing(len(data)) })
 	return ic.Syscalls().HashBlake2b(data)
 }
 
+func (ic *invocationContext) ComputeCID(codec uint64, data []byte) (cid.Cid, error) {
+	ic.chargeGas("OnHashing", func(p PriceList) int64 { return p.OnHashing(len(data)) })
+	return ic.Syscalls().ComputeCID(codec, data)
+}
+
 func (ic *invocationContext) ComputeUnsealedSectorCID(reg abi.RegisteredSealProof, pieces []abi.PieceInfo) (cid.Cid, error) {
+	ic.chargeGas("OnComputeUnsealedSectorCid", func(p PriceList) int64 { return p.OnComputeUnsealedSectorCid(reg, pieces) })
 	return ic.Syscalls().ComputeUnsealedSectorCID(reg, pieces)
 }
 
 func (ic *invocationContext) VerifySeal(vi proof.SealVerifyInfo) error {
+	ic.chargeGas("OnVerifySeal", func(p PriceList) int64 { return p.OnVerifySeal(vi) })
 	return ic.Syscalls().VerifySeal(vi)
 }
 
@@ -202,11 +227,21 @@ func (ic *invocationContext) BatchVerifySeals(vis map[address.Address][]proof.Se
 	return ic.Syscalls().BatchVerifySeals(vis)
 }
 
+func (ic *invocationContext) VerifyAggregateSeals(aggregate proof.AggregateSealVerifyProofAndInfos) error {
+	return ic.Syscalls().VerifyAggregateSeals(aggregate)
+}
+
 func (ic *invocationContext) VerifyPoSt(vi proof.WindowPoStVerifyInfo) error {
+	ic.chargeGas("OnVerifyPost", func(p PriceList) int64 { return p.OnVerifyPost(vi) })
 	return ic.Syscalls().VerifyPoSt(vi)
 }
 
+func (ic *invocationContext) VerifyReplicaUpdate(update proof.ReplicaUpdateInfo) error {
+	return ic.Syscalls().VerifyReplicaUpdate(update)
+}
+
 func (ic *invocationContext) VerifyConsensusFault(h1, h2, extra []byte) (*runtime.ConsensusFault, error) {
+	ic.chargeGas("OnVerifyConsensusFault", func(p PriceList) int64 { return p.OnVerifyConsensusFault() })
 	return ic.Syscalls().VerifyConsensusFault(h1, h2, extra)
 }
 
@@ -240,12 +275,12 @@ func (ic *invocationContext) GetActorCodeCID(a address.Address) (cid.Cid, bool)
 	return entry.Code, true
 }
 
-func (ic *invocationContext) GetRandomnessFromBeacon(_ crypto.DomainSeparationTag, _ abi.ChainEpoch, _ []byte) abi.Randomness {
-	return []byte("not really random")
+func (ic *invocationContext) GetRandomnessFromBeacon(tag crypto.DomainSeparationTag, epoch abi.ChainEpoch, entropy []byte) abi.Randomness {
+	return ic.rt.chain.beaconRandomness(tag, epoch, entropy)
 }
 
-func (ic *invocationContext) GetRandomnessFromTickets(_ crypto.DomainSeparationTag, _ abi.ChainEpoch, _ []byte) abi.Randomness {
-	return []byte("not really random")
+func (ic *invocationContext) GetRandomnessFromTickets(tag crypto.DomainSeparationTag, epoch abi.ChainEpoch, entropy []byte) abi.Randomness {
+	return ic.rt.chain.ticketRandomness(tag, epoch, entropy)
 }
 
 func (ic *invocationContext) ValidateImmediateCallerAcceptAny() {
@@ -320,10 +355,31 @@ func (ic *invocationContext) NewActorAddress() address.Address {
 
 // Send implements runtime.InvocationContext.
 func (ic *invocationContext) Send(toAddr address.Address, methodNum abi.MethodNum, params cbor.Marshaler, value abi.TokenAmount, out cbor.Er) (errcode exitcode.ExitCode) {
+	return ic.send(toAddr, methodNum, params, value, ic.readOnly, out)
+}
+
+// SendWithGasLimit implements runtime.Runtime.
+// The limit is checked for validity, but only the top-level message's gas limit is enforced when metering.
+func (ic *invocationContext) SendWithGasLimit(toAddr address.Address, methodNum abi.MethodNum, params cbor.Marshaler, value abi.TokenAmount, gasLimit int64, out cbor.Er) exitcode.ExitCode {
+	if gasLimit <= 0 {
+		ic.Abortf(exitcode.SysErrorIllegalArgument, "gas limit %d must be positive", gasLimit)
+	}
+	return ic.send(toAddr, methodNum, params, value, ic.readOnly, out)
+}
+
+// StaticSend implements runtime.Runtime.
+func (ic *invocationContext) StaticSend(toAddr address.Address, methodNum abi.MethodNum, params cbor.Marshaler, out cbor.Er) exitcode.ExitCode {
+	return ic.send(toAddr, methodNum, params, big.Zero(), true, out)
+}
+
+func (ic *invocationContext) send(toAddr address.Address, methodNum abi.MethodNum, params cbor.Marshaler, value abi.TokenAmount, readOnly bool, out cbor.Er) exitcode.ExitCode {
 	// check if side-effects are allowed
 	if !ic.allowSideEffects {
 		ic.Abortf(exitcode.SysErrorIllegalActor, "Calling Send() is not allowed during side-effect lock")
 	}
+	if readOnly && !value.NilOrZero() {
+		ic.Abortf(exitcode.SysErrForbidden, "cannot transfer value %v from a read-only invocation", value)
+	}
 	from := ic.msg.to
 	fromActor, found, err := ic.rt.GetActor(from)
 	if err != nil {
@@ -341,6 +397,7 @@ func (ic *invocationContext) Send(toAddr address.Address, methodNum abi.MethodNu
 	}
 
 	newCtx := newInvocationContext(ic.rt, ic.topLevel, newMsg, fromActor, ic.emptyObject)
+	newCtx.readOnly = readOnly
 	ret, code := newCtx.invoke()
 
 	ic.stats.MergeSubStat(newCtx.toActor.Code, newMsg.method, newCtx.stats)
@@ -354,6 +411,8 @@ func (ic *invocationContext) Send(toAddr address.Address, methodNum abi.MethodNu
 
 // CreateActor implements runtime.ExtendedInvocationContext.
 func (ic *invocationContext) CreateActor(codeID cid.Cid, addr address.Address) {
+	ic.requireWritable("create actor")
+	ic.chargeGas("OnCreateActor", func(p PriceList) int64 { return p.OnCreateActor() })
 	act, ok := ic.rt.ActorImpls[codeID]
 	if !ok {
 		ic.Abortf(exitcode.SysErrorIllegalArgument, "Can only create built-in actors.")
@@ -388,6 +447,8 @@ func (ic *invocationContext) CreateActor(codeID cid.Cid, addr address.Address) {
 
 // deleteActor implements runtime.ExtendedInvocationContext.
 func (ic *invocationContext) DeleteActor(beneficiary address.Address) {
+	ic.requireWritable("delete actor")
+	ic.chargeGas("OnDeleteActor", func(p PriceList) int64 { return p.OnDeleteActor() })
 	receiver := ic.msg.to
 	receiverActor, found, err := ic.rt.GetActor(receiver)
 	if err != nil {
@@ -409,16 +470,51 @@ func (ic *invocationContext) DeleteActor(beneficiary address.Address) {
 	}
 }
 
+func (ic *invocationContext) ReplaceActorCodes(mapping map[cid.Cid]cid.Cid) {
+	if ic.msg.to != builtin.SystemActorAddr {
+		ic.Abortf(exitcode.SysErrForbidden, "only the system actor may replace actor codes")
+	}
+	ic.requireWritable("replace actor codes")
+	for _, newCode := range mapping { //nolint:nomaprange
+		if _, ok := ic.rt.ActorImpls[newCode]; !ok {
+			ic.Abortf(exitcode.SysErrorIllegalArgument, "cannot replace actor code with unknown code %v", newCode)
+		}
+	}
+	if err := ic.rt.replaceActorCodes(ic.rt.ctx, mapping); err != nil {
+		panic(err)
+	}
+}
+
 func (ic *invocationContext) TotalFilCircSupply() abi.TokenAmount {
 	return ic.topLevel.circSupply
 }
 
+func (ic *invocationContext) BaseFee() abi.TokenAmount {
+	return ic.topLevel.baseFee
+}
+
 func (ic *invocationContext) Context() context.Context {
 	return ic.rt.ctx
 }
 
-func (ic *invocationContext) ChargeGas(_ string, _ int64, _ int64) {
-	// no-op
+// Aborts if the invocation is read-only, as for a static send.
+func (ic *invocationContext) requireWritable(op string) {
+	if ic.readOnly {
+		ic.Abortf(exitcode.SysErrForbidden, "cannot %s in a read-only invocation", op)
+	}
+}
+
+// Charges compute gas explicitly requested by an actor, if metering. Virtual compute gas is not charged.
+func (ic *invocationContext) ChargeGas(name string, compute int64, _ int64) {
+	ic.chargeGas(name, func(PriceList) int64 { return compute })
+}
+
+// Charges the gas priced by the price list, if the message is metered.
+// The price is computed only when metering, since it may require encoding an object.
+func (ic *invocationContext) chargeGas(name string, price func(PriceList) int64) {
+	if g := ic.topLevel.gas; g != nil {
+		g.charge(ic.rt, name, price(g.prices))
+	}
 }
 
 // Starts a new tracing span. The span must be End()ed explicitly, typically with a deferred invocation.
@@ -436,6 +532,11 @@ func (ic *invocationContext) Log(level rt.LogLevel, msg string, args ...interfac
 	ic.rt.Log(level, msg, args...)
 }
 
+func (ic *invocationContext) EmitEvent(entries []runtime.EventEntry) {
+	ic.requireWritable("emit event")
+	ic.rt.emitEvent(entries)
+}
+
 type returnWrapper struct {
 	inner cbor.Marshaler
 }
@@ -468,6 +569,10 @@ func (s fakeSyscalls) HashBlake2b(b []byte) [32]byte {
 	return blake2b.Sum256(b)
 }
 
+func (s fakeSyscalls) ComputeCID(codec uint64, data []byte) (cid.Cid, error) {
+	return cid.V1Builder{Codec: codec, MhType: mh.BLAKE2B_MIN + 31}.Sum(data)
+}
+
 func (s fakeSyscalls) ComputeUnsealedSectorCID(_ abi.RegisteredSealProof, _ []abi.PieceInfo) (cid.Cid, error) {
 	return testing.MakeCID("presealedSectorCID", nil), nil
 }
@@ -493,6 +598,14 @@ func (s fakeSyscalls) VerifyPoSt(_ proof.WindowPoStVerifyInfo) error {
 	return nil
 }
 
+func (s fakeSyscalls) VerifyReplicaUpdate(_ proof.ReplicaUpdateInfo) error {
+	return nil
+}
+
+func (s fakeSyscalls) VerifyAggregateSeals(_ proof.AggregateSealVerifyProofAndInfos) error {
+	return nil
+}
+
 func (s fakeSyscalls) VerifyConsensusFault(_, _, _ []byte) (*runtime.ConsensusFault, error) {
 	return &runtime.ConsensusFault{
 		Target: s.receiver,
@@ -545,6 +658,7 @@ func (ic *invocationContext) invoke() (ret returnWrapper, errcode exitcode.ExitC
 	}
 
 	ic.rt.startInvocation(&ic.msg)
+	dispatched := false
 
 	// Install handler for abort, which rolls back all state changes from this and any nested invocations.
 	// This is the only path by which a non-OK exit code may be returned.
@@ -560,6 +674,9 @@ func (ic *invocationContext) invoke() (ret returnWrapper, errcode exitcode.ExitC
 				ic.rt.Log(rt.WARN, "Abort during actor execution. errMsg: %v exitCode: %d sender: %v receiver; %v method: %d value %v",
 					r, r.code, ic.msg.from, ic.msg.to, ic.msg.method, ic.msg.value)
 				ic.rt.endInvocation(r.code, abi.Empty)
+				if dispatched {
+					ic.rt.metrics.MethodAborted(ic.toActor.Code, ic.msg.method, r.code)
+				}
 				ret = returnWrapper{abi.Empty} // The Empty here should never be used, but slightly safer than zero value.
 				errcode = r.code
 				return
@@ -586,6 +703,9 @@ func (ic *invocationContext) invoke() (ret returnWrapper, errcode exitcode.ExitC
 	// Note: we replace the "to" address with the normalized version
 	ic.toActor, ic.msg.to = ic.resolveTarget(ic.msg.to)
 
+	// charge for the invocation, including any transfer of funds
+	ic.chargeGas("OnMethodInvocation", func(p PriceList) int64 { return p.OnMethodInvocation(ic.msg.value, ic.msg.method) })
+
 	// 3. transfer funds carried by the msg
 	if !ic.msg.value.NilOrZero() {
 		if ic.msg.value.LessThan(big.Zero()) {
@@ -609,6 +729,8 @@ func (ic *invocationContext) invoke() (ret returnWrapper, errcode exitcode.ExitC
 	actorImpl := ic.rt.getActorImpl(ic.toActor.Code)
 
 	// dispatch
+	ic.rt.metrics.MethodInvoked(ic.toActor.Code, ic.msg.method)
+	dispatched = true
 	out, err := ic.dispatch(actorImpl, ic.msg.method, ic.msg.params)
 	if err != nil {
 		ic.Abortf(exitcode.SysErrInvalidMethod, "could not dispatch method")
@@ -673,6 +795,11 @@ func (ic *invocationContext) dispatch(actor runtime.VMActor, method abi.MethodNu
 		args = append(args, reflect.ValueOf(arg))
 	}
 
+	// check parameters before invoking the method
+	if err := builtin.ValidateParams(actor, method, args[1].Interface()); err != nil {
+		ic.Abortf(exitcode.Unwrap(err, exitcode.ErrIllegalArgument), "invalid parameters: %s", err)
+	}
+
 	// invoke the method
 	out := ventry.Call(args)
 
@@ -726,6 +853,7 @@ func (ic *invocationContext) resolveTarget(target address.Address) (*states.Acto
 			// Don't implicitly create an account actor for an address without an associated key.
 			ic.Abortf(exitcode.SysErrInvalidReceiver, "cannot create account for address type")
 		}
+		ic.requireWritable("create account actor")
 
 		targetIDAddr, err = state.MapAddressToNewID(ic.rt.store, target)
 		if err != nil {
@@ -791,6 +919,8 @@ func (ic *invocationContext) replace(obj cbor.Marshaler) cid.Cid {
 	if !found {
 		ic.rt.Abortf(exitcode.ErrIllegalState, "failed to find actor %s for state", ic.msg.to)
 	}
+	ic.chargeGas("OnIpldPut", func(p PriceList) int64 { return p.OnIpldPut(encodedSize(obj)) })
+	ic.recordStateWrite(obj)
 	c, err := ic.rt.store.Put(ic.rt.ctx, obj)
 	if err != nil {
 		ic.rt.Abortf(exitcode.ErrIllegalState, "could not save new state")
@@ -803,6 +933,19 @@ func (ic *invocationContext) replace(obj cbor.Marshaler) cid.Cid {
 	return c
 }
 
+// Reports the encoded size of an object written by the actor to the VM's metrics.
+// The object is encoded again just to measure it, so this is skipped when metrics are not collected.
+func (ic *invocationContext) recordStateWrite(obj cbor.Marshaler) {
+	if ic.rt.metrics == dlog.Noop {
+		return
+	}
+	var buf bytes.Buffer
+	if err := obj.MarshalCBOR(&buf); err != nil {
+		return // The store put will fail on the same error.
+	}
+	ic.rt.metrics.StateBytesWritten(ic.toActor.Code, uint64(buf.Len()))
+}
+
 // Checks that state objects weren't modified outside of transaction.
 func (ic *invocationContext) checkStateObjectsUnmodified() {
 	for obj, expectedKey := range ic.stateUsedObjs { // nolint:nomaprange
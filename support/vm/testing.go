@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/filecoin-project/go-address"
@@ -32,6 +33,7 @@ import (
 	"github.com/filecoin-project/specs-actors/v3/actors/states"
 	"github.com/filecoin-project/specs-actors/v3/actors/util/adt"
 	"github.com/filecoin-project/specs-actors/v3/actors/util/smoothing"
+	"github.com/filecoin-project/specs-actors/v3/support/consistency"
 	actor_testing "github.com/filecoin-project/specs-actors/v3/support/testing"
 )
 
@@ -51,6 +53,8 @@ func init() {
 //
 
 // Creates a new VM and initializes all singleton actors plus a root verifier account.
+// When the test completes, cross-actor consistency invariants are asserted over the state of
+// the most recently modified VM derived from this one (see SkipConsistencyCheck).
 func NewVMWithSingletons(ctx context.Context, t testing.TB, bs ipldcbor.IpldBlockstore) *VM {
 	lookup := map[cid.Cid]runtime.VMActor{}
 	for _, ba := range exported.BuiltinActors() {
@@ -59,6 +63,8 @@ func NewVMWithSingletons(ctx context.Context, t testing.TB, bs ipldcbor.IpldBloc
 
 	store := adt.WrapBlockStore(ctx, bs)
 	vm := NewVM(ctx, lookup, store)
+	vm.scenario = &scenario{latest: vm, supply: big.Zero()}
+	t.Cleanup(func() { vm.scenario.check(t) })
 
 	initializeActor(ctx, t, vm, &system.State{}, builtin.SystemActorCodeID, builtin.SystemActorAddr, big.Zero())
 
@@ -95,6 +101,15 @@ func NewVMWithSingletons(ctx context.Context, t testing.TB, bs ipldcbor.IpldBloc
 	return vm
 }
 
+// Installs an actor implementation that is not among the builtin actors, such as a test puppet,
+// and creates an instance of it at an address with the given state and balance.
+func InstallActor(ctx context.Context, t testing.TB, vm *VM, impl runtime.VMActor, state cbor.Marshaler, a address.Address, balance abi.TokenAmount) {
+	vm.ActorImpls[impl.Code()] = impl
+	initializeActor(ctx, t, vm, state, impl.Code(), a, balance)
+	_, err := vm.checkpoint()
+	require.NoError(t, err)
+}
+
 // Creates n account actors in the VM with the given balance
 func CreateAccounts(ctx context.Context, t testing.TB, vm *VM, n int, balance abi.TokenAmount, seed int64) []address.Address {
 	var initState initactor.State
@@ -124,6 +139,45 @@ func CreateAccounts(ctx context.Context, t testing.TB, vm *VM, n int, balance ab
 	return pubAddrs
 }
 
+//
+// Scenario consistency
+//
+
+// The VMs derived from a single VM created with NewVMWithSingletons.
+type scenario struct {
+	// The VM whose state was most recently modified.
+	latest *VM
+	// The total balance with which actors have been initialized, outside of message execution.
+	supply abi.TokenAmount
+	skip   bool
+}
+
+func (s *scenario) touch(vm *VM) {
+	if s != nil {
+		s.latest = vm
+	}
+}
+
+// Asserts consistency of the latest state, unless the test has already failed.
+func (s *scenario) check(t testing.TB) {
+	if s.skip || t.Failed() {
+		return
+	}
+	tree, err := s.latest.GetStateTree()
+	require.NoError(t, err)
+	acc, err := consistency.Check(tree, s.supply)
+	require.NoError(t, err)
+	assert.True(t, acc.IsEmpty(), "inconsistent state at end of scenario:\n%s", strings.Join(acc.Messages(), "\n"))
+}
+
+// Disables the consistency check at the end of the scenario to which this VM belongs,
+// for tests that deliberately construct inconsistent state.
+func (vm *VM) SkipConsistencyCheck() {
+	if vm.scenario != nil {
+		vm.scenario.skip = true
+	}
+}
+
 //
 // Invocation expectations
 //
@@ -471,12 +525,18 @@ func GetDealState(t *testing.T, vm *VM, dealID abi.DealID) (*market.DealState, b
 // Misc. helpers
 //
 
-func ApplyOk(t *testing.T, v *VM, from, to address.Address, value abi.TokenAmount, method abi.MethodNum, params interface{}) cbor.Marshaler {
+func ApplyOk(t testing.TB, v *VM, from, to address.Address, value abi.TokenAmount, method abi.MethodNum, params interface{}) cbor.Marshaler {
 	ret, code := v.ApplyMessage(from, to, value, method, params)
 	require.Equal(t, exitcode.Ok, code)
 	return ret
 }
 
+// Requires the gas used by the last message applied to the VM to be within a fraction tolerance of expected.
+func RequireGasWithin(t testing.TB, v *VM, expected int64, tolerance float64) {
+	used := v.GasUsed()
+	require.InEpsilon(t, expected, used, tolerance, "gas used %d not within %v of expected %d", used, tolerance, expected)
+}
+
 //
 //  internal stuff
 //
@@ -491,6 +551,9 @@ func initializeActor(ctx context.Context, t testing.TB, vm *VM, state cbor.Marsh
 	}
 	err = vm.setActor(ctx, a, actor)
 	require.NoError(t, err)
+	if vm.scenario != nil {
+		vm.scenario.supply = big.Add(vm.scenario.supply, balance)
+	}
 }
 
 type addrPair struct {
@@ -20,12 +20,13 @@ import (
 	"github.com/filecoin-project/specs-actors/v3/actors/runtime"
 	"github.com/filecoin-project/specs-actors/v3/actors/states"
 	"github.com/filecoin-project/specs-actors/v3/actors/util/adt"
+	"github.com/filecoin-project/specs-actors/v3/support/dlog"
 )
 
 // VM is a simplified message execution framework for the purposes of testing inter-actor communication.
 // The VM maintains actor state and can be used to simulate message validation for a single block or tipset.
-// The VM does not track gas charges, provide working syscalls, validate message nonces and many other things
-// that a compliant VM needs to do.
+// The VM does not provide working syscalls, validate message nonces and many other things that a compliant VM
+// needs to do. Gas is tracked only if a price list is set.
 type VM struct {
 	ctx   context.Context
 	store adt.Store
@@ -34,8 +35,8 @@ type VM struct {
 	networkVersion network.Version
 
 	ActorImpls  ActorImplLookup
-	stateRoot   cid.Cid  // The last committed root.
-	actors      *adt.Map // The current (not necessarily committed) root node.
+	stateRoot   cid.Cid      // The last committed root.
+	actors      *states.Tree // The current (not necessarily committed) root node.
 	actorsDirty bool
 
 	emptyObject  cid.Cid
@@ -47,8 +48,17 @@ type VM struct {
 
 	statsSource   StatsSource
 	statsByMethod StatsByCall
+	metrics       dlog.Metrics
 
 	circSupply abi.TokenAmount
+	chain      chainContext
+
+	priceList PriceList // Prices with which messages are metered, or nil if gas is not tracked.
+	gasLimit  int64
+	gasUsed   int64 // Gas used by the last message, if metered.
+
+	// Shared by all VMs derived from one created by NewVMWithSingletons, or nil.
+	scenario *scenario
 }
 
 // VM types
@@ -69,15 +79,24 @@ type Invocation struct {
 	Exitcode       exitcode.ExitCode
 	Ret            cbor.Marshaler
 	SubInvocations []*Invocation
+	// Events emitted by the invoked actor, in order, excluding those of sub-invocations.
+	Events [][]runtime.EventEntry
+}
+
+// An event emitted by an actor during message execution.
+type Event struct {
+	// The receiver of the emitting invocation, as addressed by its message.
+	Emitter address.Address
+	Entries []runtime.EventEntry
 }
 
 // NewVM creates a new runtime for executing messages.
 func NewVM(ctx context.Context, actorImpls ActorImplLookup, store adt.Store) *VM {
-	actors, err := adt.MakeEmptyMap(store, builtin.DefaultHamtBitwidth)
+	actors, err := states.NewTree(store)
 	if err != nil {
 		panic(err)
 	}
-	actorRoot, err := actors.Root()
+	actorRoot, err := actors.Flush()
 	if err != nil {
 		panic(err)
 	}
@@ -97,13 +116,16 @@ func NewVM(ctx context.Context, actorImpls ActorImplLookup, store adt.Store) *VM
 		emptyObject:    emptyObject,
 		networkVersion: network.VersionMax,
 		statsByMethod:  make(StatsByCall),
+		metrics:        dlog.Noop,
 		circSupply:     big.Mul(big.NewInt(1e9), big.NewInt(1e18)),
+		chain:          defaultChainContext(),
+		gasLimit:       BlockGasLimit,
 	}
 }
 
 // NewVM creates a new runtime for executing messages.
 func NewVMAtEpoch(ctx context.Context, actorImpls ActorImplLookup, store adt.Store, stateRoot cid.Cid, epoch abi.ChainEpoch) (*VM, error) {
-	actors, err := adt.AsMap(store, stateRoot, builtin.DefaultHamtBitwidth)
+	actors, err := states.LoadTree(store, stateRoot)
 	if err != nil {
 		return nil, err
 	}
@@ -124,7 +146,10 @@ func NewVMAtEpoch(ctx context.Context, actorImpls ActorImplLookup, store adt.Sto
 		emptyObject:    emptyObject,
 		networkVersion: network.VersionMax,
 		statsByMethod:  make(StatsByCall),
+		metrics:        dlog.Noop,
 		circSupply:     big.Mul(big.NewInt(1e9), big.NewInt(1e18)),
+		chain:          defaultChainContext(),
+		gasLimit:       BlockGasLimit,
 	}, nil
 }
 
@@ -134,7 +159,7 @@ func (vm *VM) WithEpoch(epoch abi.ChainEpoch) (*VM, error) {
 		return nil, err
 	}
 
-	actors, err := adt.AsMap(vm.store, vm.stateRoot, builtin.DefaultHamtBitwidth)
+	actors, err := states.LoadTree(vm.store, vm.stateRoot)
 	if err != nil {
 		return nil, err
 	}
@@ -151,7 +176,12 @@ func (vm *VM) WithEpoch(epoch abi.ChainEpoch) (*VM, error) {
 		networkVersion: vm.networkVersion,
 		statsSource:    vm.statsSource,
 		statsByMethod:  make(StatsByCall),
+		metrics:        vm.metrics,
 		circSupply:     vm.circSupply,
+		chain:          vm.chain,
+		scenario:       vm.scenario,
+		priceList:      vm.priceList,
+		gasLimit:       vm.gasLimit,
 	}, nil
 }
 
@@ -161,7 +191,7 @@ func (vm *VM) WithNetworkVersion(nv network.Version) (*VM, error) {
 		return nil, err
 	}
 
-	actors, err := adt.AsMap(vm.store, vm.stateRoot, builtin.DefaultHamtBitwidth)
+	actors, err := states.LoadTree(vm.store, vm.stateRoot)
 	if err != nil {
 		return nil, err
 	}
@@ -178,13 +208,18 @@ func (vm *VM) WithNetworkVersion(nv network.Version) (*VM, error) {
 		networkVersion: nv,
 		statsSource:    vm.statsSource,
 		statsByMethod:  make(StatsByCall),
+		metrics:        vm.metrics,
 		circSupply:     vm.circSupply,
+		chain:          vm.chain,
+		scenario:       vm.scenario,
+		priceList:      vm.priceList,
+		gasLimit:       vm.gasLimit,
 	}, nil
 }
 
 func (vm *VM) rollback(root cid.Cid) error {
 	var err error
-	vm.actors, err = adt.AsMap(vm.store, root, builtin.DefaultHamtBitwidth)
+	vm.actors, err = states.LoadTree(vm.store, root)
 	if err != nil {
 		return errors.Wrapf(err, "failed to load node for %s", root)
 	}
@@ -200,19 +235,18 @@ func (vm *VM) GetActor(a address.Address) (*states.Actor, bool, error) {
 	if !found {
 		return nil, false, nil
 	}
-	var act states.Actor
-	found, err := vm.actors.Get(abi.AddrKey(na), &act)
-	return &act, found, err
+	return vm.actors.GetActor(na)
 }
 
 // SetActor sets the the actor to the given value whether it previously existed or not.
 //
 // This method will not check if the actor previously existed, it will blindly overwrite it.
 func (vm *VM) setActor(_ context.Context, key address.Address, a *states.Actor) error {
-	if err := vm.actors.Put(abi.AddrKey(key), a); err != nil {
+	if err := vm.actors.SetActor(key, a); err != nil {
 		return errors.Wrap(err, "setting actor in state tree failed")
 	}
 	vm.actorsDirty = true
+	vm.scenario.touch(vm)
 	return nil
 }
 
@@ -239,19 +273,45 @@ func (vm *VM) SetActorState(ctx context.Context, key address.Address, state cbor
 // This behaviour is based on a principle that some store implementations might not be able to determine
 // whether something exists before deleting it.
 func (vm *VM) deleteActor(_ context.Context, key address.Address) error {
-	found, err := vm.actors.TryDelete(abi.AddrKey(key))
+	found, err := vm.actors.DeleteActor(key)
 	vm.actorsDirty = found
 	return err
 }
 
+// replaceActorCodes replaces the code of each actor whose code is a key of the mapping.
+func (vm *VM) replaceActorCodes(ctx context.Context, mapping map[cid.Cid]cid.Cid) error {
+	// Collect the actors first, since the map may not be modified while iterating it.
+	replaced := map[address.Address]*states.Actor{}
+	if err := vm.actors.ForEach(func(a address.Address, act *states.Actor) error {
+		newCode, ok := mapping[act.Code]
+		if !ok {
+			return nil
+		}
+		updated := *act
+		updated.Code = newCode
+		replaced[a] = &updated
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "iterating state tree failed")
+	}
+
+	for a, updated := range replaced { //nolint:nomaprange
+		if err := vm.setActor(ctx, a, updated); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (vm *VM) checkpoint() (cid.Cid, error) {
 	// commit the vm state
-	root, err := vm.actors.Root()
+	root, err := vm.actors.Flush()
 	if err != nil {
 		return cid.Undef, err
 	}
 	vm.stateRoot = root
 	vm.actorsDirty = false
+	vm.scenario.touch(vm)
 
 	return root, nil
 }
@@ -305,6 +365,18 @@ func (vm *VM) ApplyMessage(from, to address.Address, value abi.TokenAmount, meth
 		return nil, exitcode.SysErrSenderInvalid
 	}
 
+	// Charge for inclusion of the message before executing it.
+	var gas *gasTracker
+	vm.gasUsed = 0
+	if vm.priceList != nil {
+		gas = &gasTracker{prices: vm.priceList, limit: vm.gasLimit}
+		msgSize := messageHeaderSize + encodedSize(params)
+		if !gas.tryCharge(vm.priceList.OnChainMessage(msgSize)) {
+			vm.gasUsed = gas.used
+			return nil, exitcode.SysErrOutOfGas
+		}
+	}
+
 	// checkpoint state
 	// Even if the message fails, the following accumulated changes will be applied:
 	// - CallSeqNumber increment
@@ -325,7 +397,9 @@ func (vm *VM) ApplyMessage(from, to address.Address, value abi.TokenAmount, meth
 		originatorCallSeq:    vm.callSequence,
 		newActorAddressCount: 0,
 		statsSource:          vm.statsSource,
-		circSupply:           vm.circSupply,
+		circSupply:           vm.GetCirculatingSupply(),
+		baseFee:              vm.BaseFee(),
+		gas:                  gas,
 	}
 	vm.callSequence++
 
@@ -347,6 +421,17 @@ func (vm *VM) ApplyMessage(from, to address.Address, value abi.TokenAmount, meth
 	// record stats
 	vm.statsByMethod.MergeStats(ctx.toActor.Code, imsg.method, ctx.stats)
 
+	if gas != nil {
+		// An internal send which ran out of gas may have been trapped by its caller, but the message still fails.
+		if exitCode == exitcode.Ok && !gas.tryCharge(vm.priceList.OnChainReturnValue(encodedSize(ret.inner))) {
+			exitCode = exitcode.SysErrOutOfGas
+		}
+		if gas.exhausted {
+			exitCode = exitcode.SysErrOutOfGas
+		}
+		vm.gasUsed = gas.used
+	}
+
 	// Roll back all state if the receipt's exit code is not ok.
 	// This is required in addition to rollback within the invocation context since top level messages can fail for
 	// more reasons than internal ones. Invocation context still needs its own rollback so actors can recover and
@@ -420,13 +505,31 @@ func (vm *VM) GetCallStats() map[MethodKey]*CallStats {
 	return vm.statsByMethod
 }
 
+// Enables gas metering of subsequent messages with the given prices, or disables it if nil.
+func (vm *VM) SetPriceList(prices PriceList) {
+	vm.priceList = prices
+}
+
+// Sets the gas limit of subsequent metered messages, by default BlockGasLimit.
+func (vm *VM) SetGasLimit(limit int64) {
+	vm.gasLimit = limit
+}
+
+// Returns the gas used by the last message applied, or zero if it was not metered.
+func (vm *VM) GasUsed() int64 {
+	return vm.gasUsed
+}
+
 // Set the FIL circulating supply passed to actors through runtime
 func (vm *VM) SetCirculatingSupply(supply abi.TokenAmount) {
 	vm.circSupply = supply
 }
 
-// Set the FIL circulating supply passed to actors through runtime
+// Get the FIL circulating supply passed to actors through runtime at the current epoch
 func (vm *VM) GetCirculatingSupply() abi.TokenAmount {
+	if vm.chain.tipset.CirculatingSupply != nil {
+		return vm.chain.tipset.CirculatingSupply(vm.currentEpoch)
+	}
 	return vm.circSupply
 }
 
@@ -505,6 +608,14 @@ func (vm *VM) GetStatsSource() StatsSource {
 	return vm.statsSource
 }
 
+// Sets the metrics receiving method invocation, abort and state write events, or the no-op metrics if nil.
+func (vm *VM) SetMetrics(m dlog.Metrics) {
+	if m == nil {
+		m = dlog.Noop
+	}
+	vm.metrics = m
+}
+
 func (vm *VM) StoreReads() uint64 {
 	if vm.statsSource != nil {
 		return vm.statsSource.ReadCount()
@@ -565,6 +676,30 @@ func (vm *VM) LastInvocation() *Invocation {
 	return vm.invocations[len(vm.invocations)-1]
 }
 
+func (vm *VM) emitEvent(entries []runtime.EventEntry) {
+	current := vm.invocationStack[len(vm.invocationStack)-1]
+	current.Events = append(current.Events, entries)
+}
+
+// Returns the events emitted by an invocation and its sub-invocations.
+// Events emitted by an invocation that aborted, or by any invocation nested within it, are omitted
+// as they would not be included in a receipt.
+func (inv *Invocation) EmittedEvents() []Event {
+	if inv.Exitcode != exitcode.Ok {
+		return nil
+	}
+	// The relative order of an invocation's own events and those of its sub-invocations is not tracked,
+	// so an invocation's events are listed before those of its sub-invocations.
+	var events []Event
+	for _, e := range inv.Events {
+		events = append(events, Event{Emitter: inv.Msg.to, Entries: e})
+	}
+	for _, sub := range inv.SubInvocations {
+		events = append(events, sub.EmittedEvents()...)
+	}
+	return events
+}
+
 //
 // implement runtime.Runtime for VM
 //